@@ -0,0 +1,72 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// laneAging promotes the jobs of normal and low priority of a pool once
+// urgent jobs have kept the oldest of them waiting for TopicConfig.Aging.
+// Each job ages from the time it was queued, so a backlog of old jobs is
+// promoted one after the other. A nil laneAging never promotes.
+type laneAging struct {
+	delay time.Duration
+	mutex sync.Mutex
+	// waiting holds the times the jobs with tickets first onwards were
+	// queued, in Unix nanoseconds, or 0 for those that left the queue.
+	waiting []int64
+	first   uint64
+}
+
+// newLaneAging returns the aging of a pool, or nil if delay is not
+// positive.
+func newLaneAging(delay time.Duration) *laneAging {
+	if delay <= 0 {
+		return nil
+	}
+	return &laneAging{delay: delay, first: 1}
+}
+
+// add records a job of normal or low priority being queued and returns
+// its ticket, or 0 if a is nil.
+func (a *laneAging) add() uint64 {
+	if a == nil {
+		return 0
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.waiting = append(a.waiting, time.Now().UnixNano())
+	return a.first + uint64(len(a.waiting)) - 1
+}
+
+// remove records the job with ticket leaving the queue, whether it was
+// taken or dropped. It does nothing for ticket 0.
+func (a *laneAging) remove(ticket uint64) {
+	if a == nil || ticket == 0 {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.waiting[ticket-a.first] = 0
+	for len(a.waiting) > 0 && a.waiting[0] == 0 {
+		a.waiting = a.waiting[1:]
+		a.first++
+	}
+}
+
+// due reports whether the oldest waiting job of normal or low priority
+// has been queued for the delay.
+func (a *laneAging) due() bool {
+	if a == nil {
+		return false
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return len(a.waiting) > 0 && time.Since(time.Unix(0, a.waiting[0])) >= a.delay
+}
@@ -0,0 +1,378 @@
+package eventbus
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// BlobStore stores archived segment files. Implementations exist for the
+// local filesystem (FileBlobStore) and S3-compatible object storage
+// (package s3blob).
+type BlobStore interface {
+	// Put stores the content of r under name, replacing any existing blob.
+	Put(ctx context.Context, name string, r io.Reader) error
+
+	// Get opens the blob stored under name.
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// List returns the names of all blobs starting with prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// FileBlobStore is a BlobStore that keeps blobs as files in a directory.
+type FileBlobStore struct {
+	Dir string
+}
+
+// Put writes r to a file named name inside the directory.
+// The file is written to a temporary name first and then renamed,
+// so readers never observe a partially written segment.
+func (fs FileBlobStore) Put(ctx context.Context, name string, r io.Reader) error {
+	if err := os.MkdirAll(fs.Dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(fs.Dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(fs.Dir, name))
+}
+
+// Get opens the file named name inside the directory.
+func (fs FileBlobStore) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(fs.Dir, name))
+}
+
+// List returns the names of files in the directory starting with prefix.
+func (fs FileBlobStore) List(ctx context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(fs.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// segmentSuffix is the file extension of archived segments.
+const segmentSuffix = ".jsonl.gz"
+
+// segmentName returns the blob name of a segment covering first..last.
+// Sequences are zero-padded so that names sort in stream order.
+func segmentName(prefix string, first, last uint64) string {
+	return fmt.Sprintf("%s%020d-%020d%s", prefix, first, last, segmentSuffix)
+}
+
+// parseSegmentName extracts the sequence range from a segment name.
+func parseSegmentName(prefix, name string) (first, last uint64, ok bool) {
+	rangePart, found := strings.CutSuffix(strings.TrimPrefix(name, prefix), segmentSuffix)
+	if !found {
+		return 0, 0, false
+	}
+	_, err := fmt.Sscanf(rangePart, "%d-%d", &first, &last)
+	return first, last, err == nil && first <= last
+}
+
+// Archiver moves aged events from an EventStore into compressed segment
+// files in a BlobStore, keeping the store small while preserving the full
+// history for replays. The store must implement Truncater.
+//
+// Each segment is a gzip-compressed JSON Lines file in the format written by
+// History.Export.
+//
+// Example:
+//
+//	archiver := eventbus.NewArchiver(store, eventbus.FileBlobStore{Dir: "archive"}, 24*time.Hour)
+//	archiver.OnError = func(err error) { log.Printf("archiving: %v", err) }
+//	bus.AddHealthCheck("archiver", archiver.Healthy)
+//	go archiver.Run(ctx, time.Hour)
+//
+//	// Replays read archived segments lazily, then the live store
+//	full := archiver.Store()
+type Archiver struct {
+	// Prefix is prepended to every segment name. Defaults to "segment-".
+	Prefix string
+	// BatchSize is the maximum number of events per segment. Defaults to 10000.
+	BatchSize int
+	// OnError is called with the error of every failed Archive call,
+	// including those made by Run.
+	OnError func(error)
+
+	store  EventStore
+	blobs  BlobStore
+	maxAge time.Duration
+	now    func() time.Time
+	// failure is the error of the last Archive call, nil if it succeeded.
+	failure atomic.Pointer[error]
+}
+
+// NewArchiver creates an archiver moving events older than maxAge
+// from store into blobs.
+func NewArchiver(store EventStore, blobs BlobStore, maxAge time.Duration) *Archiver {
+	return &Archiver{
+		Prefix:    "segment-",
+		BatchSize: 10000,
+		store:     store,
+		blobs:     blobs,
+		maxAge:    maxAge,
+		now:       time.Now,
+	}
+}
+
+// Archive uploads all events older than the configured age as one or more
+// segments and truncates them from the store. It returns the number of
+// archived events.
+func (a *Archiver) Archive(ctx context.Context) (int, error) {
+	archived, err := a.archive(ctx)
+	if err == nil {
+		a.failure.Store(nil)
+		return archived, nil
+	}
+	a.failure.Store(&err)
+	if a.OnError != nil {
+		a.OnError(err)
+	}
+	return archived, err
+}
+
+// Healthy returns nil if the last Archive call succeeded, and otherwise
+// its error, so a failing archiver can be added to the checks of a bus
+// with EventBus.AddHealthCheck instead of letting the store grow
+// unnoticed.
+func (a *Archiver) Healthy() error {
+	if failure := a.failure.Load(); failure != nil {
+		return *failure
+	}
+	return nil
+}
+
+// archive moves the aged events for Archive.
+func (a *Archiver) archive(ctx context.Context) (int, error) {
+	truncater, ok := a.store.(Truncater)
+	if !ok {
+		return 0, errors.New("eventbus: archiving requires a store implementing Truncater")
+	}
+
+	cutoff := a.now().Add(-a.maxAge)
+	archived := 0
+	for {
+		batch, err := a.agedBatch(cutoff)
+		if err != nil || len(batch) == 0 {
+			return archived, err
+		}
+
+		if err := a.upload(ctx, batch); err != nil {
+			return archived, err
+		}
+		if err := truncater.TruncateBefore(batch[len(batch)-1].Sequence + 1); err != nil {
+			return archived, err
+		}
+		archived += len(batch)
+	}
+}
+
+// Run archives aged events every interval until ctx is cancelled.
+// Errors are passed to OnError, reported by Healthy, and retried on the
+// next tick.
+func (a *Archiver) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		a.Archive(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Store returns a read view over the archived segments followed by the live
+// store. Segments are only downloaded when a read reaches them. Appends go
+// to the live store.
+func (a *Archiver) Store() EventStore {
+	return &archivedStore{archiver: a}
+}
+
+// agedBatch collects up to BatchSize events recorded at or before cutoff
+// from the start of the store.
+func (a *Archiver) agedBatch(cutoff time.Time) ([]Envelope, error) {
+	var batch []Envelope
+	err := a.store.Read(1, func(envelope Envelope) error {
+		if envelope.Time.After(cutoff) || len(batch) >= a.BatchSize {
+			return errStopQuery
+		}
+		batch = append(batch, envelope)
+		return nil
+	})
+	if errors.Is(err, errStopQuery) {
+		err = nil
+	}
+	return batch, err
+}
+
+// upload writes batch as a single compressed segment.
+func (a *Archiver) upload(ctx context.Context, batch []Envelope) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for _, envelope := range batch {
+		// The segment is compressed as a whole.
+		if err := writeEnvelope(gz, envelope, 0); err != nil {
+			return err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	name := segmentName(a.Prefix, batch[0].Sequence, batch[len(batch)-1].Sequence)
+	if err := a.blobs.Put(ctx, name, &buf); err != nil {
+		return fmt.Errorf("eventbus: uploading %s: %w", name, err)
+	}
+	return nil
+}
+
+// archivedStore reads archived segments before falling back to the live store.
+type archivedStore struct {
+	archiver *Archiver
+}
+
+// Append records event in the live store.
+func (s *archivedStore) Append(event Event) (Envelope, error) {
+	return s.archiver.store.Append(event)
+}
+
+// AppendMetadata records event with metadata in the live store. The
+// metadata is dropped if the live store does not implement MetadataAppender.
+func (s *archivedStore) AppendMetadata(event Event, metadata map[string]string) (Envelope, error) {
+	if appender, ok := s.archiver.store.(MetadataAppender); ok {
+		return appender.AppendMetadata(event, metadata)
+	}
+	return s.archiver.store.Append(event)
+}
+
+// AppendEnvelope records envelope in the live store, falling back to
+// AppendMetadata if the live store does not implement EnvelopeAppender.
+func (s *archivedStore) AppendEnvelope(envelope Envelope) (Envelope, error) {
+	if appender, ok := s.archiver.store.(EnvelopeAppender); ok {
+		return appender.AppendEnvelope(envelope)
+	}
+	return s.AppendMetadata(envelope.Event, envelope.Metadata)
+}
+
+// Read calls fn for every envelope from the archive and then the live store.
+// Archived events are decoded as RawEvent values.
+func (s *archivedStore) Read(from uint64, fn func(Envelope) error) error {
+	next := max(from, 1)
+	for {
+		progress, err := s.readSegments(next, func(envelope Envelope) error {
+			next = envelope.Sequence + 1
+			return fn(envelope)
+		})
+		if err != nil {
+			return err
+		}
+
+		// Events archived after the segments were listed leave a gap at the
+		// start of the live store; list the segments again to fill it.
+		// Later holes, such as those of a store with a memory budget, are
+		// not in the archive.
+		gap, first := false, true
+		err = s.archiver.store.Read(next, func(envelope Envelope) error {
+			if first && envelope.Sequence > next {
+				gap = true
+				return errStopQuery
+			}
+			first = false
+			next = envelope.Sequence + 1
+			return fn(envelope)
+		})
+		if !gap {
+			return err
+		}
+		if !progress {
+			return fmt.Errorf("eventbus: archive is missing events from sequence %d", next)
+		}
+	}
+}
+
+// readSegments calls fn for archived envelopes starting at from.
+// It reports whether any envelope was read.
+func (s *archivedStore) readSegments(from uint64, fn func(Envelope) error) (bool, error) {
+	ctx := context.Background()
+	prefix := s.archiver.Prefix
+
+	names, err := s.archiver.blobs.List(ctx, prefix)
+	if err != nil {
+		return false, err
+	}
+	slices.Sort(names)
+
+	progress := false
+	for _, name := range names {
+		_, last, ok := parseSegmentName(prefix, name)
+		if !ok || last < from {
+			continue
+		}
+
+		err := s.readSegment(ctx, name, func(envelope Envelope) error {
+			if envelope.Sequence < from {
+				return nil
+			}
+			progress = true
+			from = envelope.Sequence + 1
+			return fn(envelope)
+		})
+		if err != nil {
+			return progress, err
+		}
+	}
+	return progress, nil
+}
+
+// readSegment downloads and decodes a single segment.
+func (s *archivedStore) readSegment(ctx context.Context, name string, fn func(Envelope) error) error {
+	blob, err := s.archiver.blobs.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("eventbus: downloading %s: %w", name, err)
+	}
+	defer blob.Close()
+
+	gz, err := gzip.NewReader(blob)
+	if err != nil {
+		return fmt.Errorf("eventbus: reading %s: %w", name, err)
+	}
+	defer gz.Close()
+
+	return readEnvelopes(gz, fn)
+}
@@ -0,0 +1,138 @@
+package eventbus
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestArchiver verifies that aged events move to segments and remain readable
+func TestArchiver(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		store.Append(scoreEvent{Player: "alice", Points: i})
+	}
+
+	blobs := FileBlobStore{Dir: t.TempDir()}
+	archiver := NewArchiver(store, blobs, time.Hour)
+	archiver.BatchSize = 2
+	archiver.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	archived, err := archiver.Archive(context.Background())
+	if err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if archived != 5 {
+		t.Errorf("Expected 5 archived events, got %d", archived)
+	}
+
+	names, _ := blobs.List(context.Background(), "segment-")
+	if len(names) != 3 {
+		t.Errorf("Expected 3 segments, got %v", names)
+	}
+
+	remaining := 0
+	store.Read(1, func(Envelope) error {
+		remaining++
+		return nil
+	})
+	if remaining != 0 {
+		t.Errorf("Expected the live store to be empty, got %d envelopes", remaining)
+	}
+
+	store.Append(scoreEvent{Player: "bob", Points: 100})
+
+	var sequences []uint64
+	err = archiver.Store().Read(3, func(envelope Envelope) error {
+		sequences = append(sequences, envelope.Sequence)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(sequences) != 4 || sequences[0] != 3 || sequences[3] != 6 {
+		t.Errorf("Expected sequences 3 through 6, got %v", sequences)
+	}
+}
+
+// TestArchiverKeepsRecentEvents verifies that events younger than the age stay live
+func TestArchiverKeepsRecentEvents(t *testing.T) {
+	store := NewMemoryStore()
+	store.Append(scoreEvent{Player: "alice"})
+
+	archiver := NewArchiver(store, FileBlobStore{Dir: t.TempDir()}, time.Hour)
+	archived, err := archiver.Archive(context.Background())
+	if err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if archived != 0 {
+		t.Errorf("Expected nothing archived, got %d", archived)
+	}
+}
+
+// TestArchiverArchivedEventsDecode verifies that archived payloads can be decoded
+func TestArchiverArchivedEventsDecode(t *testing.T) {
+	store := NewMemoryStore()
+	store.Append(scoreEvent{Player: "alice", Points: 42})
+
+	archiver := NewArchiver(store, FileBlobStore{Dir: t.TempDir()}, 0)
+	archiver.now = func() time.Time { return time.Now().Add(time.Minute) }
+	archiver.Archive(context.Background())
+
+	var score scoreEvent
+	archiver.Store().Read(1, func(envelope Envelope) error {
+		return envelope.Event.(RawEvent).Decode(&score)
+	})
+	if score.Player != "alice" || score.Points != 42 {
+		t.Errorf("Unexpected archived payload: %+v", score)
+	}
+}
+
+// TestArchiverStoreLiveHoles verifies that holes of the live store after its first envelope are read through
+func TestArchiverStoreLiveHoles(t *testing.T) {
+	budget := NewMemoryBudget(0)
+	budget.LimitTopic("noise:*", 1)
+	store := NewMemoryStoreWithBudget(budget)
+	for i := 0; i < 3; i++ {
+		store.Append(scoreEvent{Player: "alice", Points: i})
+		store.Append(testEvent{eventType: "noise:tick"})
+	}
+
+	archiver := NewArchiver(store, FileBlobStore{Dir: t.TempDir()}, time.Hour)
+	var sequences []uint64
+	err := archiver.Store().Read(1, func(envelope Envelope) error {
+		sequences = append(sequences, envelope.Sequence)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !slices.Equal(sequences, []uint64{1, 3, 5}) {
+		t.Errorf("Expected sequences 1, 3 and 5, got %v", sequences)
+	}
+}
+
+// TestArchiverReportsErrors verifies that failed archiving is passed to OnError and reported by Healthy until it succeeds
+func TestArchiverReportsErrors(t *testing.T) {
+	type appendOnly struct{ EventStore }
+	store := NewMemoryStore()
+	archiver := NewArchiver(appendOnly{store}, FileBlobStore{Dir: t.TempDir()}, time.Hour)
+	var reported []error
+	archiver.OnError = func(err error) { reported = append(reported, err) }
+
+	if _, err := archiver.Archive(context.Background()); err == nil {
+		t.Fatal("Expected an error for a store without TruncateBefore")
+	}
+	if len(reported) != 1 || archiver.Healthy() == nil {
+		t.Errorf("Expected the error reported, got %v and %v", reported, archiver.Healthy())
+	}
+
+	archiver.store = store
+	if _, err := archiver.Archive(context.Background()); err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if err := archiver.Healthy(); err != nil {
+		t.Errorf("Expected a healthy archiver, got %v", err)
+	}
+}
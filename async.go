@@ -0,0 +1,402 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// asyncJob is a unit of work queued for a worker: either a single listener
+// invocation, or all listeners of an event on a serial topic or with stages.
+type asyncJob struct {
+	event     Event
+	listeners []*Subscription
+	// ctx is passed to the listeners.
+	ctx context.Context
+	// waiter is set when the publisher waits for the delivery to finish.
+	waiter *deliveryWaiter
+	// slot is the position of the first delivery in the waiter's receipt;
+	// the deliveries of one job occupy consecutive slots.
+	slot int
+	// serial and ticket order the jobs of a serial topic.
+	serial *serialTopic
+	ticket uint64
+	// priority selects the queue lane on asynchronous topics.
+	priority Priority
+	// aged is the ticket of a job of normal or low priority in the
+	// laneAging of its pool, or 0.
+	aged uint64
+	// drops counts the deliveries discarded by drop.
+	drops *dropCounter
+	// watermark watches the queue the job is placed in.
+	watermark *watermark
+}
+
+// run invokes the job's listeners in order, running the listeners of
+// a stage in parallel.
+func (job asyncJob) run() {
+	job.watermark.fall()
+	if job.serial != nil {
+		job.serial.await(job.ticket)
+		defer job.serial.finish()
+	}
+
+	for start := 0; start < len(job.listeners); {
+		end := stageEnd(job.listeners, start)
+		runParallel(start, end, func(i int) {
+			if job.waiter == nil {
+				invoke(job.ctx, job.listeners[i], job.event)
+				return
+			}
+			begin := time.Now()
+			err := invoke(job.ctx, job.listeners[i], job.event)
+			job.waiter.report(job.slot+i, time.Since(begin), err)
+		})
+		start = end
+	}
+}
+
+// contextError returns the error of a done context, marking expired
+// deadlines with ErrTimeout.
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+// executor runs queued jobs on worker goroutines.
+type executor interface {
+	// start launches the worker goroutines, tracking them and the queue
+	// in audit under the name of the topic pattern.
+	start(audit *leakAudit, pattern EventType)
+	// stop lets the workers drain the queue and waits for them to exit.
+	// No jobs may be queued after stop is called.
+	stop()
+	// enqueue queues job, applying the overflow policy when the queue is
+	// full. It returns early if ctx is done while blocked.
+	enqueue(ctx context.Context, job asyncJob) error
+	// queued returns the number of jobs waiting for a worker.
+	queued() int
+}
+
+// newExecutor creates the executor for an asynchronous topic configuration.
+func newExecutor(config TopicConfig) executor {
+	if config.Dispatcher != nil {
+		return &dispatcherExecutor{dispatcher: config.Dispatcher}
+	}
+	if config.WorkStealing {
+		return newStealingPool(config)
+	}
+	return newWorkerPool(config)
+}
+
+// workerPool runs queued jobs on a fixed set of goroutines sharing
+// a single queue, with a second lane for urgent jobs.
+type workerPool struct {
+	queue chan asyncJob
+	// urgent holds the jobs that workers take before any in queue.
+	urgent   chan asyncJob
+	workers  int
+	overflow OverflowPolicy
+	running  sync.WaitGroup
+	// release marks the queue as closed in the leak audit.
+	release func()
+	// scaler adds and retires workers; nil for a fixed pool.
+	scaler *autoscaler
+	// aging promotes queue over urgent; see TopicConfig.Aging.
+	aging *laneAging
+	// audit and pattern track the workers spawned after start.
+	audit   *leakAudit
+	pattern EventType
+	spawned int
+}
+
+// newWorkerPool creates a pool for an asynchronous topic configuration.
+// Workers and queue size are raised to at least 1.
+func newWorkerPool(config TopicConfig) *workerPool {
+	return &workerPool{
+		queue:    make(chan asyncJob, max(config.QueueSize, 1)),
+		urgent:   make(chan asyncJob, max(config.QueueSize, 1)),
+		workers:  max(config.Workers, 1),
+		overflow: config.Overflow,
+		scaler:   newAutoscaler(config),
+		aging:    newLaneAging(config.Aging),
+	}
+}
+
+// start launches the worker goroutines, and the autoscaler if the pool
+// has one.
+func (pool *workerPool) start(audit *leakAudit, pattern EventType) {
+	pool.release = audit.track("channel", fmt.Sprintf("queue for %s", pattern))
+	pool.audit, pool.pattern = audit, pattern
+	for range pool.workers {
+		pool.spawn()
+	}
+	if pool.scaler != nil {
+		pool.scaler.wait.Add(1)
+		go pool.scaler.run(pool, pattern)
+	}
+}
+
+// spawn launches one more worker goroutine. It is only called by start
+// and the autoscaler, never concurrently.
+func (pool *workerPool) spawn() {
+	release := pool.audit.track("goroutine", fmt.Sprintf("worker %d for %s", pool.spawned, pool.pattern))
+	pool.spawned++
+	if pool.scaler != nil {
+		pool.scaler.workers.Add(1)
+	}
+	pool.running.Add(1)
+	go func() {
+		defer pool.running.Done()
+		defer release()
+		pool.work()
+	}()
+}
+
+// work runs jobs, preferring urgent ones unless the others are due for
+// aging, until both lanes are closed and drained, or until the autoscaler
+// retires the worker while it is idle.
+func (pool *workerPool) work() {
+	urgent, queue := pool.urgent, pool.queue
+	var retire chan struct{}
+	if pool.scaler != nil {
+		retire = pool.scaler.retire
+	}
+	for urgent != nil || queue != nil {
+		var job asyncJob
+		var ok bool
+		if queue != nil && len(queue) > 0 && len(urgent) > 0 && pool.aging.due() {
+			select {
+			case job, ok = <-queue:
+			default:
+			}
+		}
+		// A nil lane blocks, so a closed urgent lane falls through.
+		if !ok {
+			select {
+			case job, ok = <-urgent:
+				if !ok {
+					urgent = nil
+					continue
+				}
+			default:
+				select {
+				case job, ok = <-urgent:
+					if !ok {
+						urgent = nil
+						continue
+					}
+				case job, ok = <-queue:
+					if !ok {
+						queue = nil
+						continue
+					}
+				case <-retire:
+					return
+				}
+			}
+		}
+		pool.aging.remove(job.aged)
+		if pool.scaler == nil {
+			job.run()
+			continue
+		}
+		pool.scaler.busy.Add(1)
+		begin := time.Now()
+		job.run()
+		pool.scaler.observe(time.Since(begin))
+		pool.scaler.busy.Add(-1)
+	}
+}
+
+// stop lets the workers drain the queue and waits for them to exit.
+// No jobs may be submitted after stop is called.
+func (pool *workerPool) stop() {
+	if pool.scaler != nil {
+		pool.scaler.stop()
+	}
+	close(pool.queue)
+	close(pool.urgent)
+	pool.release()
+	pool.running.Wait()
+}
+
+// queued returns the number of jobs waiting in the queue.
+func (pool *workerPool) queued() int {
+	return len(pool.queue) + len(pool.urgent)
+}
+
+// lane returns the queue for job: the urgent lane for jobs above normal
+// priority, except on serial topics, where jobs must stay in order.
+// Each lane holds up to the configured queue size.
+func (pool *workerPool) lane(job asyncJob) chan asyncJob {
+	if job.urgent() {
+		return pool.urgent
+	}
+	return pool.queue
+}
+
+// submit queues one job per listener of job, or job itself running all
+// listeners in order if it belongs to a serial topic or has stages. It
+// returns early if ctx is done while blocked on a full queue.
+func submit(ctx context.Context, pool executor, job asyncJob) error {
+	if job.serial != nil || hasStages(job.listeners) {
+		for i := range job.listeners {
+			slot := job.waiter.add(i, job.listeners[i])
+			if i == 0 {
+				job.slot = slot
+			}
+		}
+		if job.serial == nil {
+			defer job.watermark.rise()
+			return pool.enqueue(ctx, job)
+		}
+
+		// Tickets must enter the queue in order, otherwise a worker could
+		// wait for a ticket that is stuck behind it in a full queue.
+		job.serial.submitting.Lock()
+		defer job.serial.submitting.Unlock()
+
+		job.ticket = job.serial.take()
+		defer job.watermark.rise()
+		return pool.enqueue(ctx, job)
+	}
+
+	listeners := job.listeners
+	for i := range listeners {
+		job.listeners = listeners[i : i+1]
+		job.slot = job.waiter.add(i, listeners[i])
+		if err := pool.enqueue(ctx, job); err != nil {
+			return err
+		}
+		job.watermark.rise()
+	}
+	return nil
+}
+
+// enqueue queues job, applying the overflow policy when the queue is full.
+func (pool *workerPool) enqueue(ctx context.Context, job asyncJob) error {
+	queue := pool.lane(job)
+	if queue == pool.queue {
+		job.aged = pool.aging.add()
+	}
+	switch pool.overflow {
+	case OverflowDropNewest:
+		select {
+		case queue <- job:
+		default:
+			pool.aging.remove(job.aged)
+			job.drop(DropQueueFull, ErrQueueFull)
+		}
+
+	case OverflowDropOldest:
+		for queued := false; !queued; {
+			select {
+			case queue <- job:
+				queued = true
+			default:
+				select {
+				case oldest := <-queue:
+					pool.aging.remove(oldest.aged)
+					oldest.drop(DropQueueFull, ErrQueueFull)
+				default:
+				}
+			}
+		}
+
+	default:
+		select {
+		case queue <- job:
+		case <-ctx.Done():
+			err := contextError(ctx)
+			pool.aging.remove(job.aged)
+			job.drop(DropCancelled, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// drop discards a job that could not be queued for reason, reporting err
+// for each of its deliveries: ErrQueueFull for a full queue, and the
+// publisher's context error for a cancelled one.
+func (job asyncJob) drop(reason DropReason, err error) {
+	if job.serial != nil {
+		job.serial.skip(job.ticket)
+	}
+	job.drops.record(reason, job.event, uint64(len(job.listeners)))
+	for i := range job.listeners {
+		job.waiter.report(job.slot+i, 0, &HandlerError{
+			EventType: job.event.GetType(),
+			Handler:   job.listeners[i].name,
+			Err:       err,
+		})
+	}
+}
+
+// invoke calls listener and converts a panic into a HandlerError
+// wrapping a PanicError. Deliveries to cancelled subscriptions fail
+// with context.Canceled.
+func invoke(ctx context.Context, listener *Subscription, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := newPanicError(r)
+			listener.bus.reportPanic(ctx, listener, event, panicErr)
+			listener.bus.reportError(ctx, event, listener.name, panicErr)
+			err = &HandlerError{EventType: event.GetType(), Handler: listener.name, Err: panicErr}
+		}
+	}()
+
+	if err := listener.call(ctx, event); err != nil {
+		if listener.ctx.Err() == nil {
+			// Deliveries skipped after Cancel are not failures.
+			listener.bus.reportError(ctx, event, listener.name, err)
+		}
+		return &HandlerError{EventType: event.GetType(), Handler: listener.name, Err: err}
+	}
+	return nil
+}
+
+// WithAsync delivers events on a pool of worker goroutines instead of the
+// publishing goroutine. Publish returns as soon as every delivery has been
+// queued, blocking only while the queue is full. Each listener invocation is
+// a separate job, so listeners of the same event may run in parallel and
+// are not guaranteed to run in registration order.
+//
+// WithAsync sets the default for all topics; WithTopicConfig overrides it
+// for individual topics.
+//
+// Listener panics are recovered by the workers. They are reported by
+// PublishAndWait as errors matching ErrHandlerPanic and otherwise
+// discarded. Call Close to stop the workers.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithAsync(4, 1024))
+//	defer bus.Close()
+func WithAsync(workers, queueSize int) Option {
+	return func(bus *eventBusImpl) {
+		bus.dispatch.fallback.config = TopicConfig{
+			Async:     true,
+			Workers:   workers,
+			QueueSize: queueSize,
+		}
+	}
+}
+
+// PublishAndWait sends an event and waits for every listener to complete.
+func (bus *eventBusImpl) PublishAndWait(ctx context.Context, event Event) error {
+	receipt, err := bus.PublishDetailed(ctx, event)
+	if err != nil {
+		return err
+	}
+	if len(receipt.Deliveries) == 0 {
+		return ErrNoSubscribers
+	}
+	return receipt.Err()
+}
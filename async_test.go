@@ -0,0 +1,113 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestAsyncPublish verifies that events are delivered by worker goroutines
+func TestAsyncPublish(t *testing.T) {
+	bus := New(WithAsync(4, 16))
+	var count atomic.Int32
+
+	for i := 0; i < 3; i++ {
+		bus.Subscribe("async:test", func(event Event) {
+			count.Add(1)
+		})
+	}
+
+	for i := 0; i < 10; i++ {
+		bus.Publish(testEvent{eventType: "async:test"})
+	}
+	bus.Close()
+
+	if count.Load() != 30 {
+		t.Errorf("Expected 30 deliveries after Close, got %d", count.Load())
+	}
+}
+
+// TestPublishAndWait verifies that PublishAndWait waits for slow listeners
+func TestPublishAndWait(t *testing.T) {
+	bus := New(WithAsync(2, 4))
+	defer bus.Close()
+	var done atomic.Int32
+
+	for i := 0; i < 2; i++ {
+		bus.Subscribe("save:game", func(event Event) {
+			time.Sleep(20 * time.Millisecond)
+			done.Add(1)
+		})
+	}
+
+	if err := bus.PublishAndWait(context.Background(), testEvent{eventType: "save:game"}); err != nil {
+		t.Fatalf("PublishAndWait failed: %v", err)
+	}
+	if done.Load() != 2 {
+		t.Errorf("Expected both listeners to finish, got %d", done.Load())
+	}
+}
+
+// TestPublishAndWaitErrors verifies that listener panics are aggregated
+func TestPublishAndWaitErrors(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithAsync(2, 4)}} {
+		bus := New(opts...)
+
+		bus.Subscribe("save:game", func(event Event) { panic("disk full") })
+		bus.Subscribe("save:game", func(event Event) {})
+		bus.Subscribe("save:game", func(event Event) { panic("quota exceeded") })
+
+		err := bus.PublishAndWait(context.Background(), testEvent{eventType: "save:game"})
+		if err == nil {
+			t.Fatal("Expected an error from panicking listeners")
+		}
+		if !strings.Contains(err.Error(), "disk full") || !strings.Contains(err.Error(), "quota exceeded") {
+			t.Errorf("Expected both panics in the error, got %v", err)
+		}
+		bus.Close()
+	}
+}
+
+// TestPublishAndWaitTimeout verifies that the context bounds the wait
+func TestPublishAndWaitTimeout(t *testing.T) {
+	bus := New(WithAsync(1, 1))
+	defer bus.Close()
+
+	release := make(chan struct{})
+	bus.Subscribe("slow:event", func(event Event) {
+		<-release
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := bus.PublishAndWait(ctx, testEvent{eventType: "slow:event"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+// TestPublishAfterClose verifies that a closed bus drops events
+func TestPublishAfterClose(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithAsync(1, 1)}} {
+		bus := New(opts...)
+		received := false
+		bus.Subscribe("closed:test", func(event Event) {
+			received = true
+		})
+
+		bus.Close()
+		bus.Publish(testEvent{eventType: "closed:test"})
+
+		if received {
+			t.Error("Event delivered after Close")
+		}
+		if err := bus.PublishAndWait(context.Background(), testEvent{eventType: "closed:test"}); err == nil {
+			t.Error("Expected PublishAndWait to fail after Close")
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package eventbus
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+)
+
+// AuditResource describes a goroutine, timer, or channel created by the bus
+// that was still alive after Close.
+type AuditResource struct {
+	// Kind is "goroutine", "timer", or "channel".
+	Kind string
+	// Name describes the resource, such as the topic pattern it serves.
+	Name string
+	// Created is when the resource was created.
+	Created time.Time
+	// Stack is the stack trace of the goroutine that created the resource.
+	Stack []byte
+}
+
+// String returns the kind, name, and creation stack of the resource.
+func (r AuditResource) String() string {
+	return fmt.Sprintf("%s %s created at %s:\n%s", r.Kind, r.Name, r.Created.Format(time.RFC3339Nano), r.Stack)
+}
+
+// WithLeakAudit tracks every goroutine, timer, and channel created by the
+// bus and reports those still alive after Close. It is a diagnostics mode
+// for finding listeners that never return, for example because they block
+// on a channel nobody reads anymore.
+//
+// Close waits at most grace for the bus to shut down. If resources remain,
+// report is called with them, oldest first, and Close returns without
+// waiting further. If report is nil, leaks are written to the standard
+// logger.
+//
+// Example:
+//
+//	bus := eventbus.New(
+//	    eventbus.WithAsync(4, 256),
+//	    eventbus.WithLeakAudit(time.Second, func(leaks []eventbus.AuditResource) {
+//	        for _, leak := range leaks {
+//	            log.Println("leaked", leak)
+//	        }
+//	    }),
+//	)
+func WithLeakAudit(grace time.Duration, report func([]AuditResource)) Option {
+	if report == nil {
+		report = func(leaks []AuditResource) {
+			for _, leak := range leaks {
+				log.Printf("eventbus: leaked %s", leak)
+			}
+		}
+	}
+
+	return func(bus *eventBusImpl) {
+		bus.audit = &leakAudit{
+			grace:  grace,
+			report: report,
+			live:   make(map[uint64]AuditResource),
+		}
+	}
+}
+
+// leakAudit records the live resources of a bus. A nil *leakAudit tracks
+// nothing, so call sites don't need to check whether auditing is enabled.
+type leakAudit struct {
+	grace  time.Duration
+	report func([]AuditResource)
+
+	next  uint64
+	live  map[uint64]AuditResource
+	mutex sync.Mutex
+}
+
+// track records a new resource and returns the function marking it released.
+func (a *leakAudit) track(kind, name string) (release func()) {
+	if a == nil {
+		return func() {}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.next++
+	id := a.next
+	a.live[id] = AuditResource{Kind: kind, Name: name, Created: time.Now(), Stack: debug.Stack()}
+
+	return func() {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+
+		delete(a.live, id)
+	}
+}
+
+// alive returns the unreleased resources in creation order.
+func (a *leakAudit) alive() []AuditResource {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	ids := make([]uint64, 0, len(a.live))
+	for id := range a.live {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	resources := make([]AuditResource, len(ids))
+	for i, id := range ids {
+		resources[i] = a.live[id]
+	}
+	return resources
+}
+
+// check waits up to the grace period for stopped to be closed and
+// reports the resources still alive.
+func (a *leakAudit) check(stopped <-chan struct{}) {
+	timer := time.NewTimer(a.grace)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+	case <-timer.C:
+	}
+
+	if leaks := a.alive(); len(leaks) > 0 {
+		a.report(leaks)
+	}
+}
@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLeakAuditReportsBlockedWorker verifies that a stuck listener is reported after Close
+func TestLeakAuditReportsBlockedWorker(t *testing.T) {
+	var leaks []AuditResource
+	bus := New(
+		WithAsync(1, 4),
+		WithLeakAudit(20*time.Millisecond, func(resources []AuditResource) {
+			leaks = resources
+		}),
+	)
+
+	block := make(chan struct{})
+	defer close(block)
+	bus.Subscribe("audit:test", func(event Event) {
+		<-block
+	})
+	bus.Publish(testEvent{eventType: "audit:test"})
+
+	bus.Close()
+
+	if len(leaks) != 1 {
+		t.Fatalf("Expected 1 leaked resource, got %d: %v", len(leaks), leaks)
+	}
+	if leaks[0].Kind != "goroutine" || leaks[0].Name != "worker 0 for *" {
+		t.Errorf("Unexpected leaked resource %s %s", leaks[0].Kind, leaks[0].Name)
+	}
+	if !strings.Contains(string(leaks[0].Stack), "TestLeakAuditReportsBlockedWorker") {
+		t.Errorf("Expected the creation stack to include the test, got:\n%s", leaks[0].Stack)
+	}
+}
+
+// TestLeakAuditCleanClose verifies that nothing is reported when the bus shuts down cleanly
+func TestLeakAuditCleanClose(t *testing.T) {
+	reported := false
+	bus := New(
+		WithAsync(2, 4),
+		WithTopicConfig("stealing:*", TopicConfig{Async: true, Workers: 2, QueueSize: 4, WorkStealing: true}),
+		WithLeakAudit(time.Second, func(resources []AuditResource) {
+			reported = true
+		}),
+	)
+	bus.Subscribe("stealing:test", func(event Event) {})
+	bus.Publish(testEvent{eventType: "stealing:test"})
+
+	bus.Close()
+
+	if reported {
+		t.Error("Expected no leaks to be reported")
+	}
+}
+
+// TestLeakAuditTracksAllResources verifies that workers and queues are tracked while running
+func TestLeakAuditTracksAllResources(t *testing.T) {
+	bus := New(
+		WithAsync(2, 4),
+		WithTopicConfig("stealing:*", TopicConfig{Async: true, Workers: 3, QueueSize: 4, WorkStealing: true}),
+		WithLeakAudit(time.Second, nil),
+	)
+	defer bus.Close()
+
+	kinds := map[string]int{}
+	for _, resource := range bus.(*eventBusImpl).audit.alive() {
+		kinds[resource.Kind]++
+	}
+
+	if kinds["goroutine"] != 5 || kinds["channel"] != 2 {
+		t.Errorf("Expected 5 goroutines and 2 channels, got %v", kinds)
+	}
+}
@@ -0,0 +1,150 @@
+package eventbus
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WorkersScaledType is the event type of the events published when an
+// autoscaling worker pool changes its number of workers.
+const WorkersScaledType EventType = "eventbus:workers_scaled"
+
+// DefaultScaleInterval is the interval at which autoscaling pools check
+// their load when TopicConfig.ScaleInterval is zero.
+const DefaultScaleInterval = 100 * time.Millisecond
+
+// WorkersScaled is published when an autoscaling worker pool adds or
+// retires workers. See TopicConfig.MaxWorkers.
+type WorkersScaled struct {
+	// Topic is the pattern of the topic configuration owning the pool,
+	// or "*" for the WithAsync default.
+	Topic EventType
+	// From and To are the number of workers before and after scaling.
+	From, To int
+	// Queued is the number of jobs waiting when the pool scaled.
+	Queued int
+	// HandlerTime is the recent average time of a job.
+	HandlerTime time.Duration
+}
+
+// GetType returns WorkersScaledType.
+func (e WorkersScaled) GetType() EventType {
+	return WorkersScaledType
+}
+
+// autoscaler adds workers to a pool while its queue would take longer than
+// one interval to drain, and retires them while the pool is idle.
+type autoscaler struct {
+	min, max int
+	interval time.Duration
+	onScale  func(WorkersScaled)
+
+	// workers is the current number of workers, busy the number running
+	// a job. jobTime is the moving average of the job durations.
+	workers atomic.Int32
+	busy    atomic.Int32
+	jobTime atomic.Int64
+
+	// retire takes one worker out of the pool per value.
+	retire chan struct{}
+	done   chan struct{}
+	wait   sync.WaitGroup
+}
+
+// newAutoscaler returns the autoscaler for config, or nil if config does
+// not autoscale.
+func newAutoscaler(config TopicConfig) *autoscaler {
+	minWorkers := max(config.Workers, 1)
+	if config.MaxWorkers <= minWorkers {
+		return nil
+	}
+	interval := config.ScaleInterval
+	if interval <= 0 {
+		interval = DefaultScaleInterval
+	}
+	return &autoscaler{
+		min:      minWorkers,
+		max:      config.MaxWorkers,
+		interval: interval,
+		retire:   make(chan struct{}, config.MaxWorkers),
+		done:     make(chan struct{}),
+	}
+}
+
+// observe records the duration of a job in the moving average.
+func (s *autoscaler) observe(elapsed time.Duration) {
+	previous := time.Duration(s.jobTime.Load())
+	if previous == 0 {
+		s.jobTime.Store(int64(elapsed))
+		return
+	}
+	s.jobTime.Store(int64(previous + (elapsed-previous)/8))
+}
+
+// target returns the number of workers for the current load: enough to
+// drain the queue within one interval, and one fewer than the current
+// number while some are idle and nothing is queued.
+func (s *autoscaler) target(queued int) int {
+	workers := int(s.workers.Load())
+	if queued == 0 {
+		if int(s.busy.Load()) < workers {
+			return max(workers-1, s.min)
+		}
+		return workers
+	}
+
+	jobTime := time.Duration(s.jobTime.Load())
+	if jobTime <= 0 {
+		// No job has finished yet; every worker is stuck on its first.
+		return min(workers+1, s.max)
+	}
+	needed := int(math.Ceil(float64(queued) * float64(jobTime) / float64(s.interval)))
+	return min(max(needed, workers), s.max)
+}
+
+// run checks the load of pool every interval until stop.
+func (s *autoscaler) run(pool *workerPool, pattern EventType) {
+	defer s.wait.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		queued := pool.queued()
+		from := int(s.workers.Load())
+		to := s.target(queued)
+		switch {
+		case to > from:
+			for range to - from {
+				pool.spawn()
+			}
+		case to < from:
+			s.workers.Add(-1)
+			s.retire <- struct{}{}
+		default:
+			continue
+		}
+		if s.onScale != nil {
+			s.onScale(WorkersScaled{
+				Topic:       pattern,
+				From:        from,
+				To:          to,
+				Queued:      queued,
+				HandlerTime: time.Duration(s.jobTime.Load()),
+			})
+		}
+	}
+}
+
+// stop ends the checks and waits for them, so no workers are added after.
+func (s *autoscaler) stop() {
+	close(s.done)
+	s.wait.Wait()
+}
@@ -0,0 +1,90 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestAutoscaling verifies that a pool grows under a backlog and shrinks back when idle
+func TestAutoscaling(t *testing.T) {
+	bus := New(WithTopicConfig("work:*", TopicConfig{
+		Async:         true,
+		Workers:       1,
+		MaxWorkers:    4,
+		QueueSize:     100,
+		ScaleInterval: 10 * time.Millisecond,
+	}))
+	defer bus.Close()
+
+	var mutex sync.Mutex
+	var scaled []WorkersScaled
+	bus.Subscribe(WorkersScaledType, func(event Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		scaled = append(scaled, event.(WorkersScaled))
+	})
+	var done atomic.Int32
+	bus.Subscribe("work:item", func(event Event) {
+		time.Sleep(5 * time.Millisecond)
+		done.Add(1)
+	})
+
+	for i := range 40 {
+		bus.Publish(Of("work:item", i))
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mutex.Lock()
+		finished := len(scaled) > 0 && scaled[len(scaled)-1].To == 1 && done.Load() == 40
+		mutex.Unlock()
+		if finished {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if done.Load() != 40 {
+		t.Fatalf("Expected 40 jobs done, got %d", done.Load())
+	}
+	peak := 0
+	for _, event := range scaled {
+		if event.Topic != "work:*" || event.To < 1 || event.To > 4 {
+			t.Errorf("Expected scaling of work:* within 1 to 4 workers, got %+v", event)
+		}
+		peak = max(peak, event.To)
+	}
+	if peak < 2 {
+		t.Errorf("Expected the pool to grow, got %+v", scaled)
+	}
+	if len(scaled) == 0 || scaled[len(scaled)-1].To != 1 {
+		t.Errorf("Expected the pool to shrink back to 1 worker, got %+v", scaled)
+	}
+}
+
+// TestAutoscalerTarget verifies the worker count chosen for a load
+func TestAutoscalerTarget(t *testing.T) {
+	s := newAutoscaler(TopicConfig{Workers: 2, MaxWorkers: 8, ScaleInterval: 100 * time.Millisecond})
+	s.workers.Store(2)
+
+	if got := s.target(5); got != 3 {
+		t.Errorf("Expected one more worker before any job finished, got %d", got)
+	}
+	s.observe(50 * time.Millisecond)
+	if got := s.target(10); got != 5 {
+		t.Errorf("Expected 5 workers to drain 10 jobs of 50ms in 100ms, got %d", got)
+	}
+	if got := s.target(100); got != 8 {
+		t.Errorf("Expected the maximum of 8 workers, got %d", got)
+	}
+	if got := s.target(0); got != 2 {
+		t.Errorf("Expected the minimum of 2 workers while idle, got %d", got)
+	}
+	if newAutoscaler(TopicConfig{Workers: 4, MaxWorkers: 4}) != nil {
+		t.Error("Expected no autoscaler without a higher maximum")
+	}
+}
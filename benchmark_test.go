@@ -0,0 +1,129 @@
+package eventbus
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// workload describes a realistic benchmark scenario. Sub-benchmarks are
+// named key=value per dimension, so benchstat can compare and group runs:
+//
+//	go test -run '^$' -bench Workload -count 10 > new.txt
+//	benchstat old.txt new.txt
+type workload struct {
+	// topics is the number of topics events are spread over.
+	topics int
+	// fanout is the number of listeners per topic.
+	fanout int
+	// publishers is the number of concurrent publishing goroutines.
+	publishers int
+	// payload is the event payload size in bytes.
+	payload int
+	// work is the number of loop iterations each listener spends per event.
+	work int
+	// dispatch is "sync", "async", or "stealing".
+	dispatch string
+}
+
+// workloads covers typical shapes: game loops with few hot topics, telemetry
+// with many cold ones, wide fan-out notifications, and contended publishers.
+var workloads = []workload{
+	{topics: 1, fanout: 1, publishers: 1, payload: 64, work: 0, dispatch: "sync"},
+	{topics: 1, fanout: 10, publishers: 1, payload: 64, work: 100, dispatch: "sync"},
+	{topics: 100, fanout: 1, publishers: 1, payload: 64, work: 100, dispatch: "sync"},
+	{topics: 10, fanout: 3, publishers: 8, payload: 256, work: 100, dispatch: "sync"},
+	{topics: 1, fanout: 1, publishers: 1, payload: 4096, work: 0, dispatch: "sync"},
+	{topics: 1, fanout: 10, publishers: 1, payload: 64, work: 1000, dispatch: "async"},
+	{topics: 10, fanout: 3, publishers: 8, payload: 256, work: 100, dispatch: "async"},
+	{topics: 100, fanout: 2, publishers: 8, payload: 1024, work: 1000, dispatch: "async"},
+	{topics: 10, fanout: 3, publishers: 8, payload: 256, work: 100, dispatch: "stealing"},
+	{topics: 100, fanout: 2, publishers: 8, payload: 1024, work: 1000, dispatch: "stealing"},
+}
+
+// name returns the benchstat-friendly sub-benchmark name of w.
+func (w workload) name() string {
+	return fmt.Sprintf("topics=%d/fanout=%d/publishers=%d/payload=%d/work=%d/dispatch=%s",
+		w.topics, w.fanout, w.publishers, w.payload, w.work, w.dispatch)
+}
+
+// options returns the bus options for the dispatch mode of w.
+func (w workload) options() []Option {
+	switch w.dispatch {
+	case "async":
+		return []Option{WithAsync(8, 1024)}
+	case "stealing":
+		return []Option{WithTopicConfig("*", TopicConfig{Async: true, Workers: 8, QueueSize: 1024, WorkStealing: true})}
+	}
+	return nil
+}
+
+// benchEvent carries a payload of the workload's size.
+type benchEvent struct {
+	topic   EventType
+	payload []byte
+}
+
+func (e benchEvent) GetType() EventType {
+	return e.topic
+}
+
+// spin simulates listener work without sleeping.
+func spin(iterations int) int {
+	sum := 0
+	for i := 0; i < iterations; i++ {
+		sum += i
+	}
+	return sum
+}
+
+// BenchmarkWorkload benchmarks publishing under realistic workloads. Besides
+// the time per published event it reports deliveries per second; -benchmem
+// adds allocations.
+func BenchmarkWorkload(b *testing.B) {
+	for _, w := range workloads {
+		b.Run(w.name(), func(b *testing.B) {
+			benchmarkWorkload(b, w)
+		})
+	}
+}
+
+// benchmarkWorkload publishes b.N events split over the workload's
+// publishers and waits until every delivery has completed.
+func benchmarkWorkload(b *testing.B, w workload) {
+	bus := New(w.options()...)
+
+	events := make([]benchEvent, w.topics)
+	for i := range events {
+		events[i] = benchEvent{topic: EventType(fmt.Sprintf("bench:%d", i)), payload: make([]byte, w.payload)}
+		for j := 0; j < w.fanout; j++ {
+			bus.Subscribe(events[i].topic, func(event Event) {
+				spin(w.work)
+			})
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var publishers sync.WaitGroup
+	for p := 0; p < w.publishers; p++ {
+		count := b.N / w.publishers
+		if p < b.N%w.publishers {
+			count++
+		}
+		publishers.Add(1)
+		go func() {
+			defer publishers.Done()
+			for i := 0; i < count; i++ {
+				bus.Publish(events[(p+i*w.publishers)%len(events)])
+			}
+		}()
+	}
+	publishers.Wait()
+	// Close drains the asynchronous queues.
+	bus.Close()
+
+	b.StopTimer()
+	b.ReportMetric(float64(b.N*w.fanout)/b.Elapsed().Seconds(), "deliveries/s")
+}
@@ -0,0 +1,410 @@
+// Package awsbridge implements bridge.CloudTransport on top of AWS SNS
+// and SQS, so a bus can be bridged in AWS deployments without a
+// self-hosted broker. It talks to the services directly over their Query
+// APIs with AWS Signature Version 4, adding no dependencies beyond the Go
+// standard library.
+//
+// Messages are published to an SNS topic given by its ARN, or directly
+// to an SQS queue given by its URL, and received from SQS queues given by
+// their URL. Queues subscribed to an SNS topic must have raw message
+// delivery enabled, so they receive the published data and attributes
+// rather than an SNS notification document.
+//
+// Ordering keys become message group IDs, which SNS and SQS only accept
+// for FIFO topics and queues; these must also have content-based
+// deduplication enabled. SNS and SQS accept at most 10 attributes per
+// message, so metadata attributes beyond the envelope's type, correlation
+// ID, and time are dropped in key order.
+//
+// Example:
+//
+//	transport := awsbridge.New("eu-central-1", os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"))
+//	b, err := bridge.NewRemote(bus, transport, bridge.Config{
+//	    Mappings: []bridge.Mapping{
+//	        {Local: "order:placed", Remote: "arn:aws:sns:eu-central-1:123456789012:orders", Direction: bridge.Out},
+//	        {Local: "payment:settled", Remote: "https://sqs.eu-central-1.amazonaws.com/123456789012/payments", Direction: bridge.In},
+//	    },
+//	})
+package awsbridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Papiermond/eventbus/bridge"
+	"github.com/Papiermond/eventbus/internal/sigv4"
+)
+
+// AttributeDataEncoding is set to "base64" on messages whose data is not
+// valid UTF-8, since SNS and SQS only carry text.
+const AttributeDataEncoding = "eventbus-data-encoding"
+
+// MaxAttributes is the number of message attributes SNS and SQS accept.
+const MaxAttributes = 10
+
+// Transport publishes to SNS topics and SQS queues and receives from SQS
+// queues. Create one with New.
+type Transport struct {
+	Region      string
+	Credentials sigv4.Credentials
+	// SNSEndpoint overrides the SNS endpoint, for example to use
+	// LocalStack. It defaults to the regional endpoint. SQS requests go
+	// to the queue URLs.
+	SNSEndpoint string
+	// WaitTime is the long-polling duration of receive requests, at most
+	// 20 seconds. Defaults to 20 seconds.
+	WaitTime time.Duration
+	// RetryDelay is the pause after a failed receive request. Defaults
+	// to one second.
+	RetryDelay time.Duration
+	// OnError is called with the errors of receive and delete requests,
+	// which are otherwise retried silently.
+	OnError func(error)
+	// HTTPClient is used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Queues are the URLs of the SQS queues Receive polls. Bridges poll
+	// the queues of their mappings instead.
+	Queues []string
+
+	now   func() time.Time
+	mutex sync.Mutex
+	// stops ends the polls started by Receive.
+	stops []func() error
+}
+
+// New creates a transport for region using static credentials.
+func New(region, accessKey, secretKey string) *Transport {
+	return &Transport{
+		Region:      region,
+		Credentials: sigv4.Credentials{AccessKey: accessKey, SecretKey: secretKey},
+	}
+}
+
+// PublishMessage publishes message to the SNS topic with ARN topic, or
+// sends it to the SQS queue if topic is a queue URL.
+func (t *Transport) PublishMessage(ctx context.Context, topic string, message bridge.Message) error {
+	body, attributes := t.encode(message)
+	form := url.Values{}
+
+	if isQueueURL(topic) {
+		form.Set("Action", "SendMessage")
+		form.Set("Version", "2012-11-05")
+		form.Set("MessageBody", body)
+		addAttributes(form, "MessageAttribute", attributes)
+		if message.OrderingKey != "" {
+			form.Set("MessageGroupId", message.OrderingKey)
+		}
+		_, err := t.do(ctx, "sqs", topic, form)
+		return err
+	}
+
+	form.Set("Action", "Publish")
+	form.Set("Version", "2010-03-31")
+	form.Set("TopicArn", topic)
+	form.Set("Message", body)
+	addAttributes(form, "MessageAttributes.entry", attributes)
+	if message.OrderingKey != "" {
+		form.Set("MessageGroupId", message.OrderingKey)
+	}
+	_, err := t.do(ctx, "sns", t.snsEndpoint(), form)
+	return err
+}
+
+// SubscribeMessages receives messages from the SQS queue with URL
+// subscription on a new goroutine until the returned function is called.
+// Each message is deleted from the queue once handler returns. queue is
+// ignored; competing consumers share an SQS queue by polling the same URL.
+func (t *Transport) SubscribeMessages(subscription, queue string, handler func(message bridge.Message)) (func() error, error) {
+	if !isQueueURL(subscription) {
+		return nil, fmt.Errorf("awsbridge: %q is not an SQS queue URL", subscription)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var polling sync.WaitGroup
+	polling.Add(1)
+	go func() {
+		defer polling.Done()
+		t.poll(ctx, subscription, handler)
+	}()
+	return func() error {
+		cancel()
+		polling.Wait()
+		return nil
+	}, nil
+}
+
+// Send publishes data to the SNS topic with ARN topic, or sends it to the
+// SQS queue if topic is a queue URL, without attributes.
+func (t *Transport) Send(topic string, data []byte) error {
+	return t.PublishMessage(context.Background(), topic, bridge.Message{Data: data})
+}
+
+// Receive polls every queue in Queues until Close, passing the data of
+// each message to handler with the queue URL as its topic. It fails
+// without polling any queue if one of them is not a queue URL.
+func (t *Transport) Receive(handler func(topic string, data []byte)) error {
+	for _, queueURL := range t.Queues {
+		if !isQueueURL(queueURL) {
+			return fmt.Errorf("awsbridge: %q is not an SQS queue URL", queueURL)
+		}
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, queueURL := range t.Queues {
+		stop, _ := t.SubscribeMessages(queueURL, "", func(message bridge.Message) {
+			handler(queueURL, message.Data)
+		})
+		t.stops = append(t.stops, stop)
+	}
+	return nil
+}
+
+// Close stops the polls started by Receive and waits for them to end.
+func (t *Transport) Close() error {
+	t.mutex.Lock()
+	stops := t.stops
+	t.stops = nil
+	t.mutex.Unlock()
+	for _, stop := range stops {
+		stop()
+	}
+	return nil
+}
+
+// poll receives and deletes messages until ctx is done.
+func (t *Transport) poll(ctx context.Context, queueURL string, handler func(message bridge.Message)) {
+	for ctx.Err() == nil {
+		messages, err := t.receive(ctx, queueURL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			t.fail(err)
+			select {
+			case <-time.After(orDefault(t.RetryDelay, time.Second)):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		for _, message := range messages {
+			decoded, err := decode(message)
+			if err != nil {
+				t.fail(fmt.Errorf("awsbridge: decoding message %s: %w", message.MessageID, err))
+			} else {
+				handler(decoded)
+			}
+			form := url.Values{
+				"Action":        {"DeleteMessage"},
+				"Version":       {"2012-11-05"},
+				"ReceiptHandle": {message.ReceiptHandle},
+			}
+			// Deletion must not be cut short by unsubscribing, or the
+			// handled message would be delivered again.
+			if _, err := t.do(context.WithoutCancel(ctx), "sqs", queueURL, form); err != nil {
+				t.fail(err)
+			}
+		}
+	}
+}
+
+// sqsMessage is a message in a ReceiveMessage response.
+type sqsMessage struct {
+	MessageID     string `xml:"MessageId"`
+	ReceiptHandle string `xml:"ReceiptHandle"`
+	Body          string `xml:"Body"`
+	Attributes    []struct {
+		Name  string `xml:"Name"`
+		Value string `xml:"Value"`
+	} `xml:"Attribute"`
+	MessageAttributes []struct {
+		Name  string `xml:"Name"`
+		Value struct {
+			StringValue string `xml:"StringValue"`
+		} `xml:"Value"`
+	} `xml:"MessageAttribute"`
+}
+
+// receive long-polls queueURL for up to 10 messages.
+func (t *Transport) receive(ctx context.Context, queueURL string) ([]sqsMessage, error) {
+	wait := min(orDefault(t.WaitTime, 20*time.Second), 20*time.Second)
+	form := url.Values{
+		"Action":                 {"ReceiveMessage"},
+		"Version":                {"2012-11-05"},
+		"MaxNumberOfMessages":    {"10"},
+		"WaitTimeSeconds":        {strconv.Itoa(int(wait / time.Second))},
+		"MessageAttributeName.1": {"All"},
+		"AttributeName.1":        {"MessageGroupId"},
+	}
+	response, err := t.do(ctx, "sqs", queueURL, form)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Messages []sqsMessage `xml:"ReceiveMessageResult>Message"`
+	}
+	if err := xml.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("awsbridge: decoding ReceiveMessage response: %w", err)
+	}
+	return result.Messages, nil
+}
+
+// encode returns the text body and the attributes of message, adding
+// AttributeDataEncoding for binary data and dropping attributes beyond
+// MaxAttributes.
+func (t *Transport) encode(message bridge.Message) (string, [][2]string) {
+	body := string(message.Data)
+	var attributes [][2]string
+	if !utf8.Valid(message.Data) {
+		body = base64.StdEncoding.EncodeToString(message.Data)
+		attributes = append(attributes, [2]string{AttributeDataEncoding, "base64"})
+	}
+
+	// The envelope attributes come first, then metadata in key order.
+	keys := make([]string, 0, len(message.Attributes))
+	for key := range message.Attributes {
+		keys = append(keys, key)
+	}
+	rank := func(key string) int {
+		switch key {
+		case bridge.AttributeType:
+			return 0
+		case bridge.AttributeCorrelationID:
+			return 1
+		case bridge.AttributeTime:
+			return 2
+		}
+		return 3
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra - rb
+		}
+		return strings.Compare(a, b)
+	})
+	for _, key := range keys {
+		if len(attributes) == MaxAttributes {
+			break
+		}
+		attributes = append(attributes, [2]string{key, message.Attributes[key]})
+	}
+	return body, attributes
+}
+
+// decode converts a received SQS message.
+func decode(message sqsMessage) (bridge.Message, error) {
+	decoded := bridge.Message{Data: []byte(message.Body)}
+	for _, attribute := range message.Attributes {
+		if attribute.Name == "MessageGroupId" {
+			decoded.OrderingKey = attribute.Value
+		}
+	}
+	for _, attribute := range message.MessageAttributes {
+		if attribute.Name == AttributeDataEncoding {
+			if attribute.Value.StringValue != "base64" {
+				return bridge.Message{}, fmt.Errorf("unknown data encoding %q", attribute.Value.StringValue)
+			}
+			data, err := base64.StdEncoding.DecodeString(message.Body)
+			if err != nil {
+				return bridge.Message{}, err
+			}
+			decoded.Data = data
+			continue
+		}
+		if decoded.Attributes == nil {
+			decoded.Attributes = make(map[string]string)
+		}
+		decoded.Attributes[attribute.Name] = attribute.Value.StringValue
+	}
+	return decoded, nil
+}
+
+// addAttributes adds string message attributes to form under prefix.
+func addAttributes(form url.Values, prefix string, attributes [][2]string) {
+	for i, attribute := range attributes {
+		key := fmt.Sprintf("%s.%d.", prefix, i+1)
+		form.Set(key+"Name", attribute[0])
+		form.Set(key+"Value.DataType", "String")
+		form.Set(key+"Value.StringValue", attribute[1])
+	}
+}
+
+// do sends a signed Query API request for service to target and returns
+// the response body if it succeeded.
+func (t *Transport) do(ctx context.Context, service, target string, form url.Values) ([]byte, error) {
+	body := []byte(form.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	now := time.Now
+	if t.now != nil {
+		now = t.now
+	}
+	sigv4.Sign(req, body, now(), t.Region, service, t.Credentials)
+
+	client := t.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	response, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		if len(response) > 1024 {
+			response = response[:1024]
+		}
+		return nil, fmt.Errorf("awsbridge: %s %s: %s: %s", service, form.Get("Action"), resp.Status, bytes.TrimSpace(response))
+	}
+	return response, nil
+}
+
+// snsEndpoint returns the SNS endpoint.
+func (t *Transport) snsEndpoint() string {
+	if t.SNSEndpoint != "" {
+		return t.SNSEndpoint
+	}
+	return fmt.Sprintf("https://sns.%s.amazonaws.com/", t.Region)
+}
+
+// fail reports err to the configured error handler.
+func (t *Transport) fail(err error) {
+	if t.OnError != nil {
+		t.OnError(err)
+	}
+}
+
+// isQueueURL reports whether target is an SQS queue URL rather than an ARN.
+func isQueueURL(target string) bool {
+	return strings.HasPrefix(target, "https://") || strings.HasPrefix(target, "http://")
+}
+
+// orDefault returns value, or fallback if value is not positive.
+func orDefault(value, fallback time.Duration) time.Duration {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,265 @@
+package awsbridge
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+	"github.com/Papiermond/eventbus/bridge"
+)
+
+// fakeAWS is a minimal SNS and SQS server with a single queue.
+type fakeAWS struct {
+	mutex     sync.Mutex
+	published []http.Header
+	forms     []map[string]string
+	queue     []string
+	byHandle  map[string]map[string]string
+	deleted   []string
+	next      int
+}
+
+func (f *fakeAWS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		http.Error(w, "unsigned", http.StatusForbidden)
+		return
+	}
+	r.ParseForm()
+	form := make(map[string]string)
+	for key := range r.PostForm {
+		form[key] = r.PostForm.Get(key)
+	}
+
+	switch form["Action"] {
+	case "Publish", "SendMessage":
+		f.published = append(f.published, r.Header)
+		f.forms = append(f.forms, form)
+		f.next++
+		handle := fmt.Sprint("handle-", f.next)
+		f.byHandle[handle] = form
+		f.queue = append(f.queue, handle)
+		fmt.Fprint(w, "<SendMessageResponse/>")
+
+	case "ReceiveMessage":
+		type attribute struct {
+			Name        string
+			StringValue string `xml:"Value>StringValue"`
+		}
+		type message struct {
+			MessageId        string
+			ReceiptHandle    string
+			Body             string
+			Attribute        []struct{ Name, Value string }
+			MessageAttribute []attribute
+		}
+		var response struct {
+			XMLName  xml.Name  `xml:"ReceiveMessageResponse"`
+			Messages []message `xml:"ReceiveMessageResult>Message"`
+		}
+		for _, handle := range f.queue {
+			form := f.byHandle[handle]
+			m := message{MessageId: handle, ReceiptHandle: handle, Body: form["MessageBody"]}
+			if group := form["MessageGroupId"]; group != "" {
+				m.Attribute = append(m.Attribute, struct{ Name, Value string }{"MessageGroupId", group})
+			}
+			for i := 1; form[fmt.Sprintf("MessageAttribute.%d.Name", i)] != ""; i++ {
+				m.MessageAttribute = append(m.MessageAttribute, attribute{
+					Name:        form[fmt.Sprintf("MessageAttribute.%d.Name", i)],
+					StringValue: form[fmt.Sprintf("MessageAttribute.%d.Value.StringValue", i)],
+				})
+			}
+			response.Messages = append(response.Messages, m)
+		}
+		f.queue = nil
+		xml.NewEncoder(w).Encode(response)
+
+	case "DeleteMessage":
+		f.deleted = append(f.deleted, form["ReceiptHandle"])
+		fmt.Fprint(w, "<DeleteMessageResponse/>")
+
+	default:
+		http.Error(w, "unknown action", http.StatusBadRequest)
+	}
+}
+
+// orderPlaced is an event carrying a correlation ID.
+type orderPlaced struct {
+	ID string
+}
+
+func (e orderPlaced) GetType() eventbus.EventType {
+	return "order:placed"
+}
+
+func (e orderPlaced) CorrelationID() string {
+	return e.ID
+}
+
+// TestSQSRoundTrip verifies that events reach another bus through a queue with attributes and ordering keys
+func TestSQSRoundTrip(t *testing.T) {
+	fake := &fakeAWS{byHandle: make(map[string]map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	queueURL := server.URL + "/123456789012/orders"
+
+	transport := New("eu-central-1", "AKID", "SECRET")
+	transport.WaitTime = time.Millisecond
+	remote := New("eu-central-1", "AKID", "SECRET")
+
+	sender, receiver := eventbus.New(), eventbus.New()
+	defer sender.Close()
+	defer receiver.Close()
+	received := make(chan eventbus.Event, 1)
+	receiver.Subscribe("order:placed", func(event eventbus.Event) { received <- event })
+
+	in, err := bridge.NewRemote(receiver, transport, bridge.Config{
+		Mappings: []bridge.Mapping{{Local: "order:placed", Remote: queueURL, Direction: bridge.In}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer in.Close()
+	out, err := bridge.NewRemote(sender, remote, bridge.Config{
+		Mappings: []bridge.Mapping{{
+			Local: "order:placed", Remote: queueURL, Direction: bridge.Out,
+			OrderingKey: func(envelope eventbus.Envelope) string { return envelope.CorrelationID },
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sender.Publish(orderPlaced{ID: "o-1"})
+	out.Close()
+
+	select {
+	case event := <-received:
+		var order orderPlaced
+		if err := event.(eventbus.RawEvent).Decode(&order); err != nil || order.ID != "o-1" {
+			t.Errorf("Expected order o-1, got %+v (%v)", order, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the event to arrive")
+	}
+
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	form := fake.forms[0]
+	if form["Action"] != "SendMessage" || form["MessageGroupId"] != "o-1" {
+		t.Errorf("Expected SendMessage with group o-1, got %v", form)
+	}
+	if form["MessageAttribute.1.Name"] != bridge.AttributeType || form["MessageAttribute.1.Value.StringValue"] != "order:placed" {
+		t.Errorf("Expected the event type as first attribute, got %v", form)
+	}
+	if form["MessageAttribute.2.Name"] != bridge.AttributeCorrelationID {
+		t.Errorf("Expected the correlation ID as second attribute, got %v", form)
+	}
+	if !strings.Contains(fake.published[0].Get("Authorization"), "/eu-central-1/sqs/aws4_request") {
+		t.Errorf("Expected an SQS signature, got %q", fake.published[0].Get("Authorization"))
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(fake.deleted) == 0 && time.Now().Before(deadline) {
+		fake.mutex.Unlock()
+		time.Sleep(time.Millisecond)
+		fake.mutex.Lock()
+	}
+	if len(fake.deleted) != 1 {
+		t.Errorf("Expected the message to be deleted, got %v", fake.deleted)
+	}
+}
+
+// TestSNSPublish verifies that topic ARNs are published to SNS
+func TestSNSPublish(t *testing.T) {
+	fake := &fakeAWS{byHandle: make(map[string]map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	transport := New("us-east-1", "AKID", "SECRET")
+	transport.SNSEndpoint = server.URL
+	err := transport.PublishMessage(context.Background(), "arn:aws:sns:us-east-1:123456789012:orders", bridge.Message{
+		Data:       []byte{0xff, 0x00},
+		Attributes: map[string]string{bridge.AttributeType: "order:placed"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	form := fake.forms[0]
+	if form["Action"] != "Publish" || form["TopicArn"] != "arn:aws:sns:us-east-1:123456789012:orders" {
+		t.Errorf("Expected Publish to the topic, got %v", form)
+	}
+	if form["Message"] != "/wA=" || form["MessageAttributes.entry.1.Name"] != AttributeDataEncoding {
+		t.Errorf("Expected base64 data with its encoding attribute, got %v", form)
+	}
+	if !strings.Contains(fake.published[0].Get("Authorization"), "/us-east-1/sns/aws4_request") {
+		t.Errorf("Expected an SNS signature, got %q", fake.published[0].Get("Authorization"))
+	}
+}
+
+// TestEncodeAttributeLimit verifies that metadata beyond the attribute limit is dropped
+func TestEncodeAttributeLimit(t *testing.T) {
+	attributes := map[string]string{bridge.AttributeType: "t", bridge.AttributeTime: "now"}
+	for i := range 12 {
+		attributes[fmt.Sprintf("%sk%02d", bridge.AttributeMetadataPrefix, i)] = "v"
+	}
+
+	_, encoded := (&Transport{}).encode(bridge.Message{Data: []byte("{}"), Attributes: attributes})
+	if len(encoded) != MaxAttributes {
+		t.Fatalf("Expected %d attributes, got %d", MaxAttributes, len(encoded))
+	}
+	if encoded[0][0] != bridge.AttributeType || encoded[1][0] != bridge.AttributeTime || encoded[2][0] != bridge.AttributeMetadataPrefix+"k00" {
+		t.Errorf("Expected envelope attributes first, got %v", encoded)
+	}
+}
+
+// TestSubscribeRequiresQueueURL verifies that subscribing to an ARN fails
+func TestSubscribeRequiresQueueURL(t *testing.T) {
+	_, err := New("us-east-1", "AKID", "SECRET").SubscribeMessages("arn:aws:sns:us-east-1:1:orders", "", func(bridge.Message) {})
+	if err == nil {
+		t.Error("Expected an error for a topic ARN")
+	}
+}
+
+// TestRemoteTransport verifies that the transport sends and receives as a RemoteTransport
+func TestRemoteTransport(t *testing.T) {
+	fake := &fakeAWS{byHandle: make(map[string]map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	queueURL := server.URL + "/123456789012/orders"
+
+	transport := New("eu-central-1", "AKID", "SECRET")
+	transport.WaitTime = time.Millisecond
+	transport.Queues = []string{queueURL}
+	received := make(chan string, 1)
+	if err := transport.Receive(func(topic string, data []byte) { received <- string(data) }); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer transport.Close()
+	if err := transport.Send(queueURL, []byte(`{"ID":"o-1"}`)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	select {
+	case data := <-received:
+		if data != `{"ID":"o-1"}` {
+			t.Errorf("Expected the order, got %s", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the message to arrive")
+	}
+
+	invalid := New("us-east-1", "AKID", "SECRET")
+	invalid.Queues = []string{"arn:aws:sns:us-east-1:1:orders"}
+	if err := invalid.Receive(func(string, []byte) {}); err == nil {
+		t.Error("Expected an error for a topic ARN")
+	}
+}
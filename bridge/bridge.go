@@ -0,0 +1,828 @@
+// Package bridge connects a bus to a remote broker such as NATS, Kafka,
+// or a message queue through declarative topic mappings, so wiring many
+// topics does not take one hand-written adapter each.
+//
+// The broker is reached through a Transport, a small interface most client
+// libraries can satisfy in a few lines. Channels without a client library,
+// such as sockets or shared memory, can instead implement RemoteTransport
+// and be bridged with NewRemote. Each Mapping pairs a local event
+// type with a remote subject and says which way events flow, how they are
+// transformed, and which codec encodes them.
+//
+// Example:
+//
+//	b, err := bridge.New(bus, natsTransport{conn}, bridge.Config{
+//	    Name: "nats",
+//	    Mappings: []bridge.Mapping{
+//	        {Local: "order:placed", Remote: "orders.placed", Direction: bridge.Out},
+//	        {Local: "payment:settled", Remote: "payments.settled", Direction: bridge.In, Queue: "shop"},
+//	        {Local: "chat:message", Remote: "chat", Direction: bridge.Both},
+//	    },
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer b.Close()
+//
+// # Loop Prevention
+//
+// When two buses are bridged both ways, an event forwarded from one to the
+// other would be forwarded back forever. Giving each bridge an Origin
+// stamps outgoing messages with the list of origins they have passed
+// through, and a bridge drops every message already carrying its origin,
+// whether it arrives from the broker or from the local bus. The hop list
+// reaches the bridge's listeners through the bus context, so the bus must
+// be created with eventbus.WithContextFields(bridge.HopsField()):
+//
+//	bus := eventbus.New(eventbus.WithContextFields(bridge.HopsField()))
+//	b, err := bridge.New(bus, transport, bridge.Config{Origin: "eu-1", Mappings: mappings})
+//
+// # Managed Brokers
+//
+// Managed services carry message attributes and ordering keys besides the
+// data. RemoteTransports that also implement CloudTransport receive the
+// event type, correlation ID, time, and metadata as attributes and the key
+// returned by Mapping.OrderingKey. The gcpbridge and awsbridge packages
+// implement it for Google Cloud Pub/Sub and for AWS SNS and SQS.
+//
+// # Replication
+//
+// Replicator and Replica copy the committed log of an event store to
+// another region over a Transport, detecting lost messages and resuming
+// from the last envelope the replica holds, for disaster recovery.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// ErrInvalidConfig is matched by the errors New returns for an invalid
+// configuration.
+var ErrInvalidConfig = errors.New("bridge: invalid configuration")
+
+// ErrClosed is returned by Healthy once the bridge is closed.
+var ErrClosed = errors.New("bridge: closed")
+
+// MetadataHops is the metadata key under which HopsField records the
+// origins an event has passed through, separated by commas.
+const MetadataHops = "bridge-hops"
+
+// DefaultMaxHops is the number of bridges a message may pass through when
+// Config.MaxHops is zero.
+const DefaultMaxHops = 8
+
+// hopsKey is the context key of the hop list.
+type hopsKey struct{}
+
+// HopsField returns a context field propagating the hop list of bridged
+// events to listeners, for use with eventbus.WithContextFields. Bridges with
+// an Origin need it to recognize the events they published.
+func HopsField() eventbus.ContextField {
+	return eventbus.ContextValue(MetadataHops, hopsKey{})
+}
+
+// hopsOf returns the hop list carried by ctx.
+func hopsOf(ctx context.Context) []string {
+	hops, _ := ctx.Value(hopsKey{}).(string)
+	if hops == "" {
+		return nil
+	}
+	return strings.Split(hops, ",")
+}
+
+// frame is the wire format of messages sent by a bridge with an Origin,
+// compression, or timestamps.
+type frame struct {
+	Hops []string `json:"hops"`
+	// Time is the original publish time of the event, if timestamps are
+	// enabled.
+	Time *time.Time `json:"time,omitempty"`
+	// Encoding is eventbus.EncodingGzip if Data is compressed.
+	Encoding string `json:"encoding,omitempty"`
+	Data     []byte `json:"data"`
+}
+
+// Transport publishes to and subscribes on the remote broker.
+//
+// Example with NATS:
+//
+//	type natsTransport struct{ conn *nats.Conn }
+//
+//	func (t natsTransport) Publish(subject string, data []byte) error {
+//	    return t.conn.Publish(subject, data)
+//	}
+//
+//	func (t natsTransport) Subscribe(subject, queue string, handler func([]byte)) (func() error, error) {
+//	    sub, err := t.conn.QueueSubscribe(subject, queue, func(m *nats.Msg) { handler(m.Data) })
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return sub.Unsubscribe, nil
+//	}
+type Transport interface {
+	// Publish sends data to subject.
+	Publish(subject string, data []byte) error
+	// Subscribe calls handler with the data received on subject until the
+	// returned function is called. A non-empty queue joins a queue group,
+	// so each message is handled by one member of the group.
+	Subscribe(subject, queue string, handler func(data []byte)) (unsubscribe func() error, err error)
+}
+
+// KeyedTransport is implemented by Transports whose broker partitions
+// messages by key, such as Kafka. Bridges publish messages with an
+// ordering key through PublishKeyed, so the events of one entity stay on
+// one partition and in order.
+//
+// Example with Kafka:
+//
+//	func (t kafkaTransport) PublishKeyed(topic, key string, data []byte) error {
+//	    return t.writer.WriteMessages(context.Background(), kafka.Message{Topic: topic, Key: []byte(key), Value: data})
+//	}
+type KeyedTransport interface {
+	Transport
+	// PublishKeyed sends data to subject with the message key key.
+	PublishKeyed(subject, key string, data []byte) error
+}
+
+// Attribute names set on the messages of a CloudTransport.
+const (
+	// AttributeType carries the local event type.
+	AttributeType = "eventbus-type"
+	// AttributeCorrelationID carries the correlation ID of the envelope,
+	// if it has one.
+	AttributeCorrelationID = "eventbus-correlation-id"
+	// AttributeTime carries the original publish time of the envelope in
+	// RFC 3339 format with nanoseconds. Bridges over a CloudTransport
+	// publish received messages with it as eventbus.Envelope.OriginTime.
+	AttributeTime = "eventbus-time"
+	// AttributeMetadataPrefix prefixes the envelope metadata recorded with
+	// eventbus.WithContextFields.
+	AttributeMetadataPrefix = "eventbus-meta-"
+)
+
+// Message is a message exchanged with a CloudTransport.
+type Message struct {
+	Data []byte
+	// Attributes are key-value pairs the service stores next to the data,
+	// which subscribers can filter on without decoding the data.
+	Attributes map[string]string
+	// OrderingKey groups messages the service must deliver in order,
+	// such as the Pub/Sub ordering key or the SQS FIFO message group.
+	// Messages without one may be reordered.
+	OrderingKey string
+}
+
+// CloudTransport is a RemoteTransport of a cloud messaging service whose
+// messages carry attributes and an ordering key, such as Google Cloud
+// Pub/Sub or AWS SNS and SQS. NewRemote bridges it through these methods
+// instead of Send and Receive, filling the attributes from the envelope of
+// each event. See the gcpbridge and awsbridge packages.
+type CloudTransport interface {
+	RemoteTransport
+	// PublishMessage sends message to topic.
+	PublishMessage(ctx context.Context, topic string, message Message) error
+	// SubscribeMessages calls handler with the messages received from
+	// subscription until the returned function is called. queue is the
+	// Mapping.Queue of the subscribing mapping.
+	SubscribeMessages(subscription, queue string, handler func(message Message)) (unsubscribe func() error, err error)
+}
+
+// messageTransport is the transport a Bridge runs on: the message methods
+// of CloudTransport.
+type messageTransport interface {
+	PublishMessage(ctx context.Context, topic string, message Message) error
+	SubscribeMessages(subscription, queue string, handler func(message Message)) (unsubscribe func() error, err error)
+}
+
+// plainTransport adapts a Transport to a messageTransport that drops
+// attributes and ordering keys.
+type plainTransport struct {
+	Transport
+}
+
+// PublishMessage publishes the data of message, with its ordering key if
+// the transport is a KeyedTransport.
+func (t plainTransport) PublishMessage(ctx context.Context, topic string, message Message) error {
+	if keyed, ok := t.Transport.(KeyedTransport); ok && message.OrderingKey != "" {
+		return keyed.PublishKeyed(topic, message.OrderingKey, message.Data)
+	}
+	return t.Publish(topic, message.Data)
+}
+
+// SubscribeMessages subscribes to subscription and wraps the received data.
+func (t plainTransport) SubscribeMessages(subscription, queue string, handler func(message Message)) (func() error, error) {
+	return t.Subscribe(subscription, queue, func(data []byte) {
+		handler(Message{Data: data})
+	})
+}
+
+// RemoteTransport is the smallest transport a bridge can run on: it sends
+// data to topics and hands every received message to a single handler.
+// Implementing it once for a custom channel, such as ZeroMQ or shared
+// memory, gives that channel all the bridging logic of NewRemote.
+//
+// Example with a UDP socket:
+//
+//	type udpTransport struct{ conn *net.UDPConn }
+//
+//	func (t udpTransport) Send(topic string, data []byte) error {
+//	    _, err := t.conn.Write(append([]byte(topic+"\n"), data...))
+//	    return err
+//	}
+//
+//	func (t udpTransport) Receive(handler func(topic string, data []byte)) error {
+//	    go func() {
+//	        buf := make([]byte, 65536)
+//	        for {
+//	            n, err := t.conn.Read(buf)
+//	            if err != nil {
+//	                return
+//	            }
+//	            topic, data, _ := bytes.Cut(buf[:n], []byte("\n"))
+//	            handler(string(topic), bytes.Clone(data))
+//	        }
+//	    }()
+//	    return nil
+//	}
+//
+//	func (t udpTransport) Close() error {
+//	    return t.conn.Close()
+//	}
+type RemoteTransport interface {
+	// Send delivers data to topic.
+	Send(topic string, data []byte) error
+	// Receive starts calling handler with the topic and data of every
+	// received message, until Close. It must not block. Bridges call it
+	// at most once, before any Send.
+	Receive(handler func(topic string, data []byte)) error
+	// Close stops receiving and releases the transport.
+	Close() error
+}
+
+// remoteTransport adapts a RemoteTransport to a messageTransport, routing
+// received messages to the handlers subscribed to their topic.
+type remoteTransport struct {
+	RemoteTransport
+
+	mutex     sync.RWMutex
+	handlers  map[string]map[*func(Message)]struct{}
+	receiving bool
+}
+
+// PublishMessage sends the data of message.
+func (t *remoteTransport) PublishMessage(ctx context.Context, topic string, message Message) error {
+	return t.Send(topic, message.Data)
+}
+
+// SubscribeMessages registers handler for subscription, starting to
+// receive on the first call. queue is ignored.
+func (t *remoteTransport) SubscribeMessages(subscription, queue string, handler func(message Message)) (func() error, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !t.receiving {
+		if err := t.Receive(t.dispatch); err != nil {
+			return nil, err
+		}
+		t.receiving = true
+		t.handlers = make(map[string]map[*func(Message)]struct{})
+	}
+	if t.handlers[subscription] == nil {
+		t.handlers[subscription] = make(map[*func(Message)]struct{})
+	}
+	key := &handler
+	t.handlers[subscription][key] = struct{}{}
+
+	return func() error {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		delete(t.handlers[subscription], key)
+		return nil
+	}, nil
+}
+
+// dispatch passes a received message to the handlers of its topic.
+// Messages on topics without handlers are dropped.
+func (t *remoteTransport) dispatch(topic string, data []byte) {
+	t.mutex.RLock()
+	handlers := make([]func(Message), 0, len(t.handlers[topic]))
+	for handler := range t.handlers[topic] {
+		handlers = append(handlers, *handler)
+	}
+	t.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(Message{Data: data})
+	}
+}
+
+// Direction says which way events flow through a mapping.
+type Direction int
+
+const (
+	// Out forwards local events to the remote subject.
+	Out Direction = 1 << iota
+	// In publishes remote messages on the local bus.
+	In
+	// Both forwards events both ways.
+	Both = Out | In
+)
+
+// String returns "out", "in", or "both".
+func (d Direction) String() string {
+	switch d {
+	case Out:
+		return "out"
+	case In:
+		return "in"
+	case Both:
+		return "both"
+	}
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+// Transform rewrites an event crossing the bridge. Returning false drops
+// the event.
+type Transform func(event eventbus.Event) (eventbus.Event, bool)
+
+// Mapping pairs a local event type with a remote subject.
+type Mapping struct {
+	// Local is the event type on the local bus.
+	Local eventbus.EventType
+	// Remote is the subject, topic, or queue name on the broker.
+	Remote string
+	// Direction says which way events flow.
+	Direction Direction
+	// Queue is the queue group joined when subscribing to Remote.
+	Queue string
+	// Outgoing transforms local events before they are encoded.
+	Outgoing Transform
+	// Incoming transforms remote events after they are decoded, before
+	// they are published locally.
+	Incoming Transform
+	// Codec encodes and decodes the events of this mapping. If nil, the
+	// codec registered for Local in Config.Codecs is used.
+	Codec eventbus.Codec
+	// OrderingKey returns the ordering key of an outgoing event, which is
+	// the message key of KeyedTransports and the ordering key of
+	// CloudTransports. Events with the same key are delivered in order by
+	// brokers supporting it. If nil, the key is the partition key of
+	// events implementing eventbus.Partitioned.
+	OrderingKey func(envelope eventbus.Envelope) string
+}
+
+// Config describes a bridge.
+type Config struct {
+	// Name identifies the bridge in BridgeLagging events and errors.
+	Name string
+	// Origin identifies the bus among the bridged buses and enables loop
+	// prevention. Messages are then sent as JSON frames carrying the hop
+	// list, which every bridge of the topology must understand.
+	Origin string
+	// MaxHops is the number of bridges a message may pass through before
+	// it is dropped, when Origin is set. DefaultMaxHops if zero.
+	MaxHops int
+	// CompressAbove enables compression of encoded events longer than
+	// this many bytes, which are sent gzipped with the encoding recorded
+	// in the frame. Messages are then sent as JSON frames, like with an
+	// Origin, so every bridge of the topology must set CompressAbove,
+	// Origin, or Timestamps. Zero disables compression.
+	CompressAbove int
+	// Timestamps sends the original publish time of each event, which
+	// receiving bridges pass on as eventbus.Envelope.OriginTime, so
+	// listeners subscribed with eventbus.WithMaxAge skip events delayed
+	// by redelivery. Messages are then sent as JSON frames, like with an
+	// Origin. Bridges over a CloudTransport always send the time as the
+	// AttributeTime attribute.
+	Timestamps bool
+	// Mappings lists the bridged topics.
+	Mappings []Mapping
+	// Codecs encodes events of mappings without a Codec. JSON by default.
+	Codecs *eventbus.CodecRegistry
+	// Buffer configures the buffer of outgoing events.
+	Buffer eventbus.BufferConfig
+	// OnError is called with the errors encoding, decoding, or publishing
+	// events, which are otherwise dropped.
+	OnError func(error)
+}
+
+// ConfigFrom returns the Config described by settings, for bridges set up
+// from an eventbus.Config. Transforms, codecs, and OnError can be added to
+// the result before passing it to New.
+//
+// Example:
+//
+//	settings, _ := busConfig.Bridge("nats")
+//	config, err := bridge.ConfigFrom(settings)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	b, err := bridge.New(bus, natsTransport{conn}, config)
+func ConfigFrom(settings eventbus.BridgeSettings) (Config, error) {
+	config := Config{
+		Name:          settings.Name,
+		Origin:        settings.Origin,
+		MaxHops:       settings.MaxHops,
+		CompressAbove: settings.CompressAbove,
+		Timestamps:    settings.Timestamps,
+		Buffer:        settings.Buffer.BufferConfig(),
+	}
+	for _, mapping := range settings.Mappings {
+		var direction Direction
+		switch mapping.Direction {
+		case "out":
+			direction = Out
+		case "in":
+			direction = In
+		case "both":
+			direction = Both
+		default:
+			return Config{}, fmt.Errorf("%w: mapping %s has unknown direction %q", ErrInvalidConfig, mapping.Local, mapping.Direction)
+		}
+		config.Mappings = append(config.Mappings, Mapping{
+			Local:     mapping.Local,
+			Remote:    mapping.Remote,
+			Direction: direction,
+			Queue:     mapping.Queue,
+		})
+	}
+	return config, nil
+}
+
+// Bridge forwards events between a bus and a broker. Create one with New.
+type Bridge struct {
+	bus       eventbus.EventBus
+	transport messageTransport
+	// attributes is set for CloudTransports.
+	attributes bool
+	// closeTransport is set for RemoteTransports, which the bridge owns.
+	closeTransport func() error
+	config         Config
+	buffer         *eventbus.SendBuffer
+	// latency records the age of received messages with a publish time.
+	latency *eventbus.LatencyRecorder
+
+	subscriptions []*eventbus.Subscription
+	unsubscribers []func() error
+	closeOnce     sync.Once
+	closed        atomic.Bool
+
+	// checker reports the connection state of transports implementing
+	// eventbus.HealthChecker.
+	checker eventbus.HealthChecker
+	// failure is the error of the last failed publish to the broker,
+	// cleared by the next successful one.
+	failure     atomic.Pointer[error]
+	removeCheck func()
+}
+
+// outgoing is a local event waiting in the buffer with its mapping and
+// the origins it has passed through.
+type outgoing struct {
+	eventbus.Event
+	mapping  *Mapping
+	hops     []string
+	envelope eventbus.Envelope
+}
+
+// New validates config and starts forwarding events. Every mapping needs a
+// local event type, a remote subject, and a direction, and no two mappings
+// may forward the same pair the same way.
+func New(bus eventbus.EventBus, transport Transport, config Config) (*Bridge, error) {
+	return newBridge(bus, plainTransport{transport}, false, config)
+}
+
+// NewRemote creates a bridge over a RemoteTransport, like New. The bridge
+// owns the transport and closes it when it is closed or fails to start.
+// Mappings setting Queue are rejected with ErrInvalidConfig, since a
+// RemoteTransport has no queue groups.
+//
+// If the transport is a CloudTransport, such as those of gcpbridge and
+// awsbridge, outgoing messages carry the event type, correlation ID,
+// publish time, and metadata of their envelope as attributes, and the
+// ordering key chosen by Mapping.OrderingKey.
+//
+// Example:
+//
+//	b, err := bridge.NewRemote(bus, udpTransport{conn}, bridge.Config{
+//	    Mappings: []bridge.Mapping{{Local: "player:moved", Remote: "moves", Direction: bridge.Both}},
+//	})
+//
+// Example with Pub/Sub:
+//
+//	transport := gcpbridge.New("my-project", tokenSource)
+//	b, err := bridge.NewRemote(bus, transport, bridge.Config{
+//	    Name: "pubsub",
+//	    Mappings: []bridge.Mapping{{
+//	        Local: "order:placed", Remote: "orders", Direction: bridge.Out,
+//	        OrderingKey: func(envelope eventbus.Envelope) string { return envelope.CorrelationID },
+//	    }},
+//	})
+func NewRemote(bus eventbus.EventBus, transport RemoteTransport, config Config) (*Bridge, error) {
+	for i, mapping := range config.Mappings {
+		if mapping.Queue != "" {
+			transport.Close()
+			return nil, fmt.Errorf("%w: mapping %d sets queue %q, which a RemoteTransport cannot join", ErrInvalidConfig, i, mapping.Queue)
+		}
+	}
+	var adapted messageTransport = &remoteTransport{RemoteTransport: transport}
+	attributes := false
+	if cloud, ok := transport.(CloudTransport); ok {
+		adapted, attributes = cloud, true
+	}
+	b, err := newBridge(bus, adapted, attributes, config)
+	if err != nil {
+		transport.Close()
+		return nil, err
+	}
+	b.closeTransport = transport.Close
+	return b, nil
+}
+
+// newBridge validates config and starts forwarding events.
+func newBridge(bus eventbus.EventBus, transport messageTransport, attributes bool, config Config) (*Bridge, error) {
+	if err := validate(config.Mappings); err != nil {
+		return nil, err
+	}
+	if strings.Contains(config.Origin, ",") {
+		return nil, fmt.Errorf("%w: origin %q contains a comma", ErrInvalidConfig, config.Origin)
+	}
+	if config.Codecs == nil {
+		config.Codecs = eventbus.NewCodecRegistry(nil)
+	}
+	if config.MaxHops <= 0 {
+		config.MaxHops = DefaultMaxHops
+	}
+
+	b := &Bridge{bus: bus, transport: transport, attributes: attributes, config: config, latency: eventbus.NewLatencyRecorder()}
+	switch t := transport.(type) {
+	case plainTransport:
+		b.checker, _ = t.Transport.(eventbus.HealthChecker)
+	case *remoteTransport:
+		b.checker, _ = t.RemoteTransport.(eventbus.HealthChecker)
+	default:
+		b.checker, _ = transport.(eventbus.HealthChecker)
+	}
+	b.buffer = eventbus.NewSendBuffer(bus, config.Name, config.Buffer, b.send)
+
+	for i := range b.config.Mappings {
+		mapping := &b.config.Mappings[i]
+		if mapping.Direction&Out != 0 {
+			b.subscriptions = append(b.subscriptions, bus.SubscribeContext(mapping.Local, func(ctx context.Context, event eventbus.Event) {
+				hops := hopsOf(ctx)
+				if b.config.Origin != "" && (slices.Contains(hops, b.config.Origin) || len(hops) >= b.config.MaxHops) {
+					// The event came through this bridge or has gone too far.
+					return
+				}
+				envelope, _ := eventbus.EnvelopeFromContext(ctx)
+				b.buffer.Push(context.Background(), outgoing{Event: event, mapping: mapping, hops: hops, envelope: envelope})
+			}))
+		}
+		if mapping.Direction&In != 0 {
+			unsubscribe, err := transport.SubscribeMessages(mapping.Remote, mapping.Queue, func(message Message) {
+				b.receive(mapping, message)
+			})
+			if err != nil {
+				b.Close()
+				return nil, fmt.Errorf("bridge %s: subscribing to %s: %w", config.Name, mapping.Remote, err)
+			}
+			b.unsubscribers = append(b.unsubscribers, unsubscribe)
+		}
+	}
+	name := "bridge"
+	if config.Name != "" {
+		name += " " + config.Name
+	}
+	b.removeCheck = bus.AddHealthCheck(name, b.Healthy)
+	return b, nil
+}
+
+// validate checks the mappings of a configuration.
+func validate(mappings []Mapping) error {
+	type route struct {
+		local     eventbus.EventType
+		remote    string
+		direction Direction
+	}
+	seen := make(map[route]bool)
+
+	for i, mapping := range mappings {
+		switch {
+		case mapping.Local == "":
+			return fmt.Errorf("%w: mapping %d has no local event type", ErrInvalidConfig, i)
+		case mapping.Remote == "":
+			return fmt.Errorf("%w: mapping %d has no remote subject", ErrInvalidConfig, i)
+		case mapping.Direction&^Both != 0 || mapping.Direction == 0:
+			return fmt.Errorf("%w: mapping %d has invalid direction %v", ErrInvalidConfig, i, mapping.Direction)
+		}
+		for _, direction := range []Direction{Out, In} {
+			if mapping.Direction&direction == 0 {
+				continue
+			}
+			key := route{mapping.Local, mapping.Remote, direction}
+			if seen[key] {
+				return fmt.Errorf("%w: %s and %s mapped %v twice", ErrInvalidConfig, mapping.Local, mapping.Remote, direction)
+			}
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
+// send transforms, encodes, and publishes a buffered local event.
+func (b *Bridge) send(event eventbus.Event) {
+	out := event.(outgoing)
+	mapping := out.mapping
+
+	local := out.Event
+	if mapping.Outgoing != nil {
+		var ok bool
+		if local, ok = mapping.Outgoing(local); !ok {
+			return
+		}
+	}
+	data, err := b.codec(mapping).Encode(local)
+	if err == nil && b.framed() {
+		message := frame{Hops: out.hops, Data: data}
+		if b.config.Timestamps && !out.envelope.OriginTime.IsZero() {
+			message.Time = &out.envelope.OriginTime
+		}
+		if b.config.Origin != "" {
+			message.Hops = append(slices.Clip(out.hops), b.config.Origin)
+		}
+		message.Data, message.Encoding, err = eventbus.CompressPayload(data, b.config.CompressAbove)
+		if err == nil {
+			data, err = json.Marshal(message)
+		}
+	}
+	if err != nil {
+		b.fail(fmt.Errorf("bridge %s: encoding %s: %w", b.config.Name, mapping.Local, err))
+		return
+	}
+	message := Message{Data: data, OrderingKey: eventbus.PartitionKey(local)}
+	if mapping.OrderingKey != nil {
+		message.OrderingKey = mapping.OrderingKey(out.envelope)
+	}
+	if b.attributes {
+		message.Attributes = attributes(out.envelope, local.GetType())
+	}
+	if err := b.transport.PublishMessage(context.Background(), mapping.Remote, message); err != nil {
+		b.failure.Store(&err)
+		b.fail(fmt.Errorf("bridge %s: publishing to %s: %w", b.config.Name, mapping.Remote, err))
+		return
+	}
+	if b.failure.Load() != nil {
+		b.failure.Store(nil)
+	}
+}
+
+// attributes maps an envelope to message attributes.
+func attributes(envelope eventbus.Envelope, eventType eventbus.EventType) map[string]string {
+	attributes := map[string]string{AttributeType: string(eventType)}
+	if envelope.CorrelationID != "" {
+		attributes[AttributeCorrelationID] = envelope.CorrelationID
+	}
+	if !envelope.OriginTime.IsZero() {
+		attributes[AttributeTime] = envelope.OriginTime.Format(time.RFC3339Nano)
+	}
+	for key, value := range envelope.Metadata {
+		attributes[AttributeMetadataPrefix+key] = value
+	}
+	return attributes
+}
+
+// receive decodes, transforms, and publishes a remote message locally.
+// With an Origin, messages this bridge has already forwarded are dropped.
+func (b *Bridge) receive(mapping *Mapping, message Message) {
+	ctx := context.Background()
+	data := message.Data
+	if published, err := time.Parse(time.RFC3339Nano, message.Attributes[AttributeTime]); err == nil {
+		ctx = eventbus.WithOriginTime(ctx, published)
+		b.latency.Observe(time.Since(published))
+	}
+	if b.framed() {
+		var received frame
+		if err := json.Unmarshal(data, &received); err != nil {
+			b.fail(fmt.Errorf("bridge %s: decoding frame from %s: %w", b.config.Name, mapping.Remote, err))
+			return
+		}
+		if b.config.Origin != "" && slices.Contains(received.Hops, b.config.Origin) {
+			return
+		}
+		payload, err := eventbus.DecompressPayload(received.Data, received.Encoding)
+		if err != nil {
+			b.fail(fmt.Errorf("bridge %s: decoding frame from %s: %w", b.config.Name, mapping.Remote, err))
+			return
+		}
+		data = payload
+		if received.Time != nil {
+			ctx = eventbus.WithOriginTime(ctx, *received.Time)
+			b.latency.Observe(time.Since(*received.Time))
+		}
+		if len(received.Hops) > 0 {
+			ctx = context.WithValue(ctx, hopsKey{}, strings.Join(received.Hops, ","))
+		}
+	}
+
+	event, err := b.codec(mapping).Decode(mapping.Local, data)
+	if err != nil {
+		b.fail(fmt.Errorf("bridge %s: decoding %s: %w", b.config.Name, mapping.Remote, err))
+		return
+	}
+	if mapping.Incoming != nil {
+		var ok bool
+		if event, ok = mapping.Incoming(event); !ok {
+			return
+		}
+	}
+	b.bus.PublishContext(ctx, event)
+}
+
+// Latency returns the time received messages took from being published
+// on the remote bus to arriving at the bridge, which includes the network
+// and the broker. Only messages carrying their publish time are counted,
+// so the bridge needs Timestamps or a CloudTransport, and the clocks of the hosts
+// must be synchronized.
+//
+// Example:
+//
+//	eventbus.WritePrometheusHistogram(w, "bridge_latency_seconds", "Bridge latency.",
+//	    map[string]string{"bridge": "nats"}, b.Latency())
+func (b *Bridge) Latency() eventbus.Histogram {
+	return b.latency.Histogram()
+}
+
+// framed reports whether messages are wrapped in frames.
+func (b *Bridge) framed() bool {
+	return b.config.Origin != "" || b.config.CompressAbove > 0 || b.config.Timestamps
+}
+
+// codec returns the codec of mapping.
+func (b *Bridge) codec(mapping *Mapping) eventbus.Codec {
+	if mapping.Codec != nil {
+		return mapping.Codec
+	}
+	return b.config.Codecs.Codec(mapping.Local)
+}
+
+// fail reports err to the configured error handler.
+func (b *Bridge) fail(err error) {
+	if b.config.OnError != nil {
+		b.config.OnError(err)
+	}
+}
+
+// Healthy returns nil if the bridge is connected to the broker, and
+// otherwise an error describing why it is not: the bridge is closed, the
+// last publish to the broker failed, or the transport implements
+// eventbus.HealthChecker and reports a problem. New adds it to the checks
+// of eventbus.EventBus.Healthy until the bridge is closed.
+func (b *Bridge) Healthy() error {
+	if b.closed.Load() {
+		return ErrClosed
+	}
+	if failure := b.failure.Load(); failure != nil {
+		return fmt.Errorf("last publish failed: %w", *failure)
+	}
+	if b.checker != nil {
+		return b.checker.Healthy()
+	}
+	return nil
+}
+
+// Close stops forwarding, sends the buffered local events, and unsubscribes
+// from the broker, closing the transport of bridges created with NewRemote.
+// It returns the first unsubscribe or close error.
+func (b *Bridge) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		b.closed.Store(true)
+		if b.removeCheck != nil {
+			b.removeCheck()
+		}
+		for _, sub := range b.subscriptions {
+			sub.Cancel()
+		}
+		b.buffer.Close()
+		for _, unsubscribe := range b.unsubscribers {
+			if unsubscribeErr := unsubscribe(); err == nil {
+				err = unsubscribeErr
+			}
+		}
+		if b.closeTransport != nil {
+			if closeErr := b.closeTransport(); err == nil {
+				err = closeErr
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,648 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// broker is an in-memory Transport
+type broker struct {
+	mutex     sync.Mutex
+	handlers  map[string][]func([]byte)
+	published map[string][][]byte
+}
+
+func newBroker() *broker {
+	return &broker{handlers: make(map[string][]func([]byte)), published: make(map[string][][]byte)}
+}
+
+func (b *broker) Publish(subject string, data []byte) error {
+	b.mutex.Lock()
+	b.published[subject] = append(b.published[subject], data)
+	handlers := b.handlers[subject]
+	b.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(data)
+	}
+	return nil
+}
+
+func (b *broker) Subscribe(subject, queue string, handler func([]byte)) (func() error, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handlers[subject] = append(b.handlers[subject], handler)
+	return func() error {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		delete(b.handlers, subject)
+		return nil
+	}, nil
+}
+
+// sent returns the messages published on subject
+func (b *broker) sent(subject string) []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	var messages []string
+	for _, data := range b.published[subject] {
+		messages = append(messages, string(data))
+	}
+	return messages
+}
+
+// TestBridgeMappings verifies that events flow in the directions of their mappings
+func TestBridgeMappings(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	remote := newBroker()
+
+	b, err := New(bus, remote, Config{
+		Name: "test",
+		Mappings: []Mapping{
+			{Local: "order:placed", Remote: "orders.placed", Direction: Out},
+			{Local: "payment:settled", Remote: "payments.settled", Direction: In},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var ids []int
+	bus.Subscribe("payment:settled", func(event eventbus.Event) {
+		payload, _ := eventbus.Payload[map[string]int](event)
+		ids = append(ids, payload["id"])
+	})
+
+	bus.Publish(eventbus.Of("order:placed", map[string]int{"id": 7}))
+	bus.Publish(eventbus.Of("payment:settled", map[string]int{"id": 8}))
+	remote.Publish("payments.settled", []byte(`{"id":9}`))
+	b.Close()
+
+	if sent := remote.sent("orders.placed"); len(sent) != 1 || sent[0] != `{"id":7}` {
+		t.Errorf("Expected one order sent, got %v", sent)
+	}
+	if sent := remote.sent("payments.settled"); len(sent) != 1 {
+		t.Errorf("Expected local payments not to be forwarded, got %v", sent)
+	}
+
+	if len(ids) != 2 || ids[1] != 9 {
+		t.Errorf("Expected the remote payment 9 published locally, got %v", ids)
+	}
+}
+
+// TestBridgeTransforms verifies that transforms rewrite and filter events
+func TestBridgeTransforms(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	remote := newBroker()
+
+	b, err := New(bus, remote, Config{
+		Mappings: []Mapping{{
+			Local:     "chat:message",
+			Remote:    "chat",
+			Direction: Out,
+			Outgoing: func(event eventbus.Event) (eventbus.Event, bool) {
+				text, _ := eventbus.Payload[string](event)
+				if text == "secret" {
+					return nil, false
+				}
+				return eventbus.Of("chat:message", "re: "+text), true
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	bus.Publish(eventbus.Of("chat:message", "hello"))
+	bus.Publish(eventbus.Of("chat:message", "secret"))
+	b.Close()
+
+	if sent := remote.sent("chat"); len(sent) != 1 || sent[0] != `"re: hello"` {
+		t.Errorf("Expected only the transformed hello, got %v", sent)
+	}
+}
+
+// rawCodec encodes string payloads as raw bytes
+type rawCodec struct{}
+
+func (rawCodec) Encode(event eventbus.Event) ([]byte, error) {
+	text, _ := eventbus.Payload[string](event)
+	return []byte(text), nil
+}
+
+func (rawCodec) Decode(eventType eventbus.EventType, data []byte) (eventbus.Event, error) {
+	return eventbus.Of(eventType, string(data)), nil
+}
+
+// TestBridgeCodec verifies that a mapping's codec is used in both directions
+func TestBridgeCodec(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	remote := newBroker()
+
+	received := make(chan string, 2)
+	bus.Subscribe("log:line", func(event eventbus.Event) {
+		text, _ := eventbus.Payload[string](event)
+		received <- text
+	})
+
+	b, err := New(bus, remote, Config{
+		Mappings: []Mapping{
+			{Local: "log:line", Remote: "logs.in", Direction: In, Codec: rawCodec{}},
+			{Local: "log:line", Remote: "logs.out", Direction: Out, Codec: rawCodec{}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	remote.Publish("logs.in", []byte("started"))
+
+	select {
+	case text := <-received:
+		if text != "started" {
+			t.Errorf("Expected started, got %q", text)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the remote line to be published locally")
+	}
+	b.Close()
+	if sent := remote.sent("logs.out"); len(sent) != 1 || sent[0] != "started" {
+		t.Errorf("Expected the raw line forwarded, got %v", sent)
+	}
+}
+
+// TestBridgeInvalidConfig verifies that invalid mappings are rejected
+func TestBridgeInvalidConfig(t *testing.T) {
+	configs := map[string][]Mapping{
+		"no local":      {{Remote: "orders", Direction: Out}},
+		"no remote":     {{Local: "order:placed", Direction: Out}},
+		"no direction":  {{Local: "order:placed", Remote: "orders"}},
+		"bad direction": {{Local: "order:placed", Remote: "orders", Direction: 8}},
+		"duplicate": {
+			{Local: "order:placed", Remote: "orders", Direction: Both},
+			{Local: "order:placed", Remote: "orders", Direction: In},
+		},
+	}
+	for name, mappings := range configs {
+		if _, err := New(eventbus.New(), newBroker(), Config{Mappings: mappings}); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for %s, got %v", name, err)
+		}
+	}
+}
+
+// TestConfigFrom verifies that bridge settings of an eventbus.Config
+// become a bridge configuration
+func TestConfigFrom(t *testing.T) {
+	config, err := ConfigFrom(eventbus.BridgeSettings{
+		Name:   "nats",
+		Origin: "eu-1",
+		Buffer: eventbus.BufferSettings{Size: 128, Overflow: "drop_oldest"},
+		Mappings: []eventbus.MappingSettings{
+			{Local: "order:placed", Remote: "orders", Direction: "out"},
+			{Local: "payment:settled", Remote: "payments", Direction: "both", Queue: "shop"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.Name != "nats" || config.Origin != "eu-1" || config.Buffer.Overflow != eventbus.OverflowDropOldest {
+		t.Errorf("Expected the bridge settings, got %+v", config)
+	}
+	if len(config.Mappings) != 2 || config.Mappings[1].Direction != Both || config.Mappings[1].Queue != "shop" {
+		t.Errorf("Expected 2 mappings, got %+v", config.Mappings)
+	}
+
+	_, err = ConfigFrom(eventbus.BridgeSettings{Mappings: []eventbus.MappingSettings{{Local: "a", Remote: "b", Direction: "up"}}})
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// flakyBroker is a broker whose publishes fail while down is set, and
+// which reports its connection as its health
+type flakyBroker struct {
+	*broker
+	down         atomic.Bool
+	disconnected atomic.Bool
+}
+
+func (b *flakyBroker) Publish(subject string, data []byte) error {
+	if b.down.Load() {
+		return errors.New("broker unreachable")
+	}
+	return b.broker.Publish(subject, data)
+}
+
+func (b *flakyBroker) Healthy() error {
+	if b.disconnected.Load() {
+		return errors.New("connection lost")
+	}
+	return nil
+}
+
+// TestBridgeHealthy verifies that the bridge reports failed publishes and the transport health to the bus until closed
+func TestBridgeHealthy(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	remote := &flakyBroker{broker: newBroker()}
+	failed := make(chan error, 1)
+
+	b, err := New(bus, remote, Config{
+		Name:     "nats",
+		Mappings: []Mapping{{Local: "order:placed", Remote: "orders.placed", Direction: Out}},
+		OnError:  func(err error) { failed <- err },
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := bus.Healthy(); err != nil {
+		t.Errorf("Expected a healthy bus, got %v", err)
+	}
+
+	remote.down.Store(true)
+	bus.Publish(eventbus.Of("order:placed", 1))
+	<-failed
+	if err := bus.Healthy(); err == nil || !strings.Contains(err.Error(), "bridge nats: last publish failed: broker unreachable") {
+		t.Errorf("Expected the failed publish, got %v", err)
+	}
+
+	remote.down.Store(false)
+	// The buffer sends one event at a time, so the second has cleared
+	// the failure once the third is sent.
+	delivered := make(chan struct{})
+	remote.Subscribe("orders.placed", "", func(data []byte) {
+		if string(data) == "3" {
+			close(delivered)
+		}
+	})
+	bus.Publish(eventbus.Of("order:placed", 2))
+	bus.Publish(eventbus.Of("order:placed", 3))
+	<-delivered
+	if err := b.Healthy(); err != nil {
+		t.Errorf("Expected a healthy bridge after a successful publish, got %v", err)
+	}
+
+	remote.disconnected.Store(true)
+	if err := bus.Healthy(); err == nil || !strings.Contains(err.Error(), "bridge nats: connection lost") {
+		t.Errorf("Expected the transport health, got %v", err)
+	}
+
+	b.Close()
+	if err := b.Healthy(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	if err := bus.Healthy(); err != nil {
+		t.Errorf("Expected the closed bridge removed from the bus checks, got %v", err)
+	}
+}
+
+// TestBridgeLoopPrevention verifies that buses bridged both ways do not echo events forever
+func TestBridgeLoopPrevention(t *testing.T) {
+	remote := newBroker()
+	var buses []eventbus.EventBus
+	var counts [2]atomic.Int32
+	for i, origin := range []string{"a", "b"} {
+		bus := eventbus.New(eventbus.WithContextFields(HopsField()))
+		defer bus.Close()
+		bus.Subscribe("chat:message", func(event eventbus.Event) {
+			counts[i].Add(1)
+		})
+		b, err := New(bus, remote, Config{
+			Origin:   origin,
+			Mappings: []Mapping{{Local: "chat:message", Remote: "chat", Direction: Both}},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer b.Close()
+		buses = append(buses, bus)
+	}
+
+	buses[0].Publish(eventbus.Of("chat:message", "hello"))
+
+	deadline := time.Now().Add(time.Second)
+	for len(remote.sent("chat")) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if sent := remote.sent("chat"); len(sent) != 2 {
+		t.Errorf("Expected the message forwarded once by each bus, got %v", sent)
+	}
+	if counts[0].Load() != 1 || counts[1].Load() != 1 {
+		t.Errorf("Expected each bus to see the message once, got %d and %d", counts[0].Load(), counts[1].Load())
+	}
+}
+
+// TestBridgeMaxHops verifies that messages that have passed through too many bridges are not forwarded
+func TestBridgeMaxHops(t *testing.T) {
+	bus := eventbus.New(eventbus.WithContextFields(HopsField()))
+	defer bus.Close()
+	remote := newBroker()
+
+	received := 0
+	bus.Subscribe("chat:message", func(event eventbus.Event) {
+		received++
+	})
+	b, err := New(bus, remote, Config{
+		Origin:  "c",
+		MaxHops: 2,
+		Mappings: []Mapping{
+			{Local: "chat:message", Remote: "chat.in", Direction: In},
+			{Local: "chat:message", Remote: "chat.out", Direction: Out},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	remote.Publish("chat.in", []byte(`{"hops":["a"],"data":"ImhpIg=="}`))
+	remote.Publish("chat.in", []byte(`{"hops":["a","b"],"data":"ImhpIg=="}`))
+	b.Close()
+
+	if received != 2 {
+		t.Errorf("Expected both messages published locally, got %d", received)
+	}
+	sent := remote.sent("chat.out")
+	if len(sent) != 1 || sent[0] != `{"hops":["a","c"],"data":"ImhpIg=="}` {
+		t.Errorf("Expected only the first message forwarded with hops [a c], got %v", sent)
+	}
+}
+
+// TestBridgeCompression verifies that only large events are compressed and both arrive intact
+func TestBridgeCompression(t *testing.T) {
+	remote := newBroker()
+	sender, receiver := eventbus.New(), eventbus.New()
+	defer sender.Close()
+	defer receiver.Close()
+
+	var texts []string
+	receiver.Subscribe("chat:message", func(event eventbus.Event) {
+		text, _ := eventbus.Payload[string](event)
+		texts = append(texts, text)
+	})
+	in, err := New(receiver, remote, Config{
+		CompressAbove: 64,
+		Mappings:      []Mapping{{Local: "chat:message", Remote: "chat", Direction: In}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer in.Close()
+	out, err := New(sender, remote, Config{
+		CompressAbove: 64,
+		Mappings:      []Mapping{{Local: "chat:message", Remote: "chat", Direction: Out}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	long := strings.Repeat("hello ", 50)
+	sender.Publish(eventbus.Of("chat:message", "hi"))
+	sender.Publish(eventbus.Of("chat:message", long))
+	out.Close()
+
+	sent := remote.sent("chat")
+	if len(sent) != 2 || strings.Contains(sent[0], "encoding") || !strings.Contains(sent[1], `"encoding":"gzip"`) {
+		t.Errorf("Expected only the long message compressed, got %v", sent)
+	}
+	if len(texts) != 2 || texts[0] != "hi" || texts[1] != long {
+		t.Errorf("Expected both messages received intact, got %q", texts)
+	}
+}
+
+// TestBridgeTimestamps verifies that the original publish time crosses the bridge for WithMaxAge
+func TestBridgeTimestamps(t *testing.T) {
+	remote := newBroker()
+	sender, receiver := eventbus.New(), eventbus.New()
+	defer sender.Close()
+	defer receiver.Close()
+
+	var origins []time.Time
+	var fresh int
+	receiver.SubscribeContext("alert:raised", func(ctx context.Context, event eventbus.Event) {
+		envelope, _ := eventbus.EnvelopeFromContext(ctx)
+		origins = append(origins, envelope.OriginTime)
+	})
+	receiver.Subscribe("alert:raised", func(event eventbus.Event) { fresh++ }, eventbus.WithMaxAge(time.Minute))
+
+	in, err := New(receiver, remote, Config{
+		Timestamps: true,
+		Mappings:   []Mapping{{Local: "alert:raised", Remote: "alerts", Direction: In}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer in.Close()
+	out, err := New(sender, remote, Config{
+		Timestamps: true,
+		Mappings:   []Mapping{{Local: "alert:raised", Remote: "alerts", Direction: Out}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stale := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sender.PublishContext(eventbus.WithOriginTime(context.Background(), stale), eventbus.Of("alert:raised", "disk full"))
+	sender.Publish(eventbus.Of("alert:raised", "cpu hot"))
+	out.Close()
+
+	if len(origins) != 2 || !origins[0].Equal(stale) || time.Since(origins[1]) > time.Minute {
+		t.Errorf("Expected the original publish times, got %v", origins)
+	}
+	if fresh != 1 {
+		t.Errorf("Expected only the recent alert within the max age, got %d", fresh)
+	}
+	if latency := in.Latency(); latency.Count != 2 || latency.Quantile(1) != 10*time.Second {
+		t.Errorf("Expected the stale alert in the overflow bucket, got %+v", latency)
+	}
+}
+
+// keyedBroker is a broker recording message keys like Kafka
+type keyedBroker struct {
+	*broker
+	keys []string
+}
+
+func (b *keyedBroker) PublishKeyed(subject, key string, data []byte) error {
+	b.keys = append(b.keys, key)
+	return b.Publish(subject, data)
+}
+
+// playerMoved is an event partitioned by player
+type playerMoved struct {
+	PlayerID string
+}
+
+func (e playerMoved) GetType() eventbus.EventType {
+	return "player:moved"
+}
+
+func (e playerMoved) PartitionKey() string {
+	return e.PlayerID
+}
+
+// TestBridgePartitionKeys verifies that partition keys become message keys of keyed transports
+func TestBridgePartitionKeys(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	remote := &keyedBroker{broker: newBroker()}
+
+	b, err := New(bus, remote, Config{
+		Mappings: []Mapping{
+			{Local: "player:moved", Remote: "moves", Direction: Out},
+			{Local: "chat:message", Remote: "chat", Direction: Out},
+			{
+				Local: "match:ended", Remote: "matches", Direction: Out,
+				OrderingKey: func(envelope eventbus.Envelope) string { return "match" },
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	bus.Publish(playerMoved{PlayerID: "p1"})
+	bus.Publish(eventbus.Of("chat:message", "hi"))
+	bus.Publish(eventbus.Of("match:ended", 1))
+	bus.Publish(playerMoved{PlayerID: "p2"})
+	b.Close()
+
+	if strings.Join(remote.keys, ",") != "p1,match,p2" {
+		t.Errorf("Expected keys p1, match, and p2, got %q", remote.keys)
+	}
+	if len(remote.sent("chat")) != 1 {
+		t.Errorf("Expected the unkeyed message to be published, got %q", remote.sent("chat"))
+	}
+}
+
+// link is one end of an in-memory RemoteTransport pair
+type link struct {
+	mutex   sync.Mutex
+	peer    *link
+	handler func(topic string, data []byte)
+	closed  bool
+}
+
+func newLinks() (*link, *link) {
+	a, b := &link{}, &link{}
+	a.peer, b.peer = b, a
+	return a, b
+}
+
+func (l *link) Send(topic string, data []byte) error {
+	l.peer.mutex.Lock()
+	handler := l.peer.handler
+	l.peer.mutex.Unlock()
+	if handler != nil {
+		handler(topic, data)
+	}
+	return nil
+}
+
+func (l *link) Receive(handler func(topic string, data []byte)) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.handler = handler
+	return nil
+}
+
+func (l *link) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.handler, l.closed = nil, true
+	return nil
+}
+
+// TestBridgeRemoteTransport verifies that a RemoteTransport is routed by topic and closed with the bridge
+func TestBridgeRemoteTransport(t *testing.T) {
+	left, right := newLinks()
+	sender, receiver := eventbus.New(), eventbus.New()
+	defer sender.Close()
+	defer receiver.Close()
+
+	var texts []string
+	receiver.Subscribe("chat:message", func(event eventbus.Event) {
+		text, _ := eventbus.Payload[string](event)
+		texts = append(texts, text)
+	})
+	in, err := NewRemote(receiver, right, Config{
+		Mappings: []Mapping{{Local: "chat:message", Remote: "chat", Direction: In}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	out, err := NewRemote(sender, left, Config{
+		Mappings: []Mapping{
+			{Local: "chat:message", Remote: "chat", Direction: Out},
+			{Local: "chat:typing", Remote: "typing", Direction: Out},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sender.Publish(eventbus.Of("chat:message", "hi"))
+	sender.Publish(eventbus.Of("chat:typing", "..."))
+	out.Close()
+	in.Close()
+
+	if len(texts) != 1 || texts[0] != "hi" {
+		t.Errorf("Expected only the chat message, got %q", texts)
+	}
+	if !left.closed || !right.closed {
+		t.Error("Expected both transports to be closed with their bridges")
+	}
+
+	invalid := &link{}
+	if _, err := NewRemote(receiver, invalid, Config{Mappings: []Mapping{{Local: "x"}}}); !errors.Is(err, ErrInvalidConfig) || !invalid.closed {
+		t.Errorf("Expected an invalid configuration to close the transport, got %v", err)
+	}
+	queued := &link{}
+	_, err = NewRemote(receiver, queued, Config{Mappings: []Mapping{{Local: "x", Remote: "x", Direction: In, Queue: "shop"}}})
+	if !errors.Is(err, ErrInvalidConfig) || !queued.closed {
+		t.Errorf("Expected a queue group to be rejected, got %v", err)
+	}
+}
+
+// FuzzBridgeReceive verifies that arbitrary broker messages are either
+// published or reported to OnError, and never panic the bridge
+func FuzzBridgeReceive(f *testing.F) {
+	f.Add([]byte(`{"hops":["b"],"data":{"text":"hi"}}`))
+	f.Add([]byte(`{"hops":["a"],"data":null}`))
+	f.Add([]byte(`{"hops":"a"}`))
+	f.Add([]byte(`not json`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		remote := newBroker()
+		bus := eventbus.New(eventbus.WithContextFields(HopsField()))
+		defer bus.Close()
+
+		published, failed := 0, 0
+		bus.Subscribe("chat:message", func(event eventbus.Event) { published++ })
+		b, err := New(bus, remote, Config{
+			Origin:   "a",
+			Mappings: []Mapping{{Local: "chat:message", Remote: "chat", Direction: In}},
+			OnError:  func(err error) { failed++ },
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer b.Close()
+
+		remote.Publish("chat", data)
+		if published+failed > 1 {
+			t.Fatalf("Expected at most one outcome, got %d published and %d failed", published, failed)
+		}
+	})
+}
@@ -0,0 +1,256 @@
+// Package gcpbridge implements bridge.CloudTransport on top of Google
+// Cloud Pub/Sub, so a bus can be bridged in GCP deployments without a
+// self-hosted broker. It talks to the Pub/Sub REST API directly, adding
+// no dependencies beyond the Go standard library.
+//
+// Messages are published to topics and pulled from subscriptions of the
+// transport's project; both must already exist. Ordering keys are only
+// honored by subscriptions with message ordering enabled.
+//
+// Example:
+//
+//	// token returns an OAuth 2.0 access token, for example from the
+//	// metadata server or golang.org/x/oauth2/google.
+//	transport := gcpbridge.New("my-project", token)
+//	b, err := bridge.NewRemote(bus, transport, bridge.Config{
+//	    Mappings: []bridge.Mapping{
+//	        {Local: "order:placed", Remote: "orders", Direction: bridge.Out},
+//	        {Local: "payment:settled", Remote: "payments-shop", Direction: bridge.In},
+//	    },
+//	})
+package gcpbridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Papiermond/eventbus/bridge"
+)
+
+// DefaultEndpoint is the Pub/Sub REST endpoint.
+const DefaultEndpoint = "https://pubsub.googleapis.com"
+
+// TokenSource returns an OAuth 2.0 access token for Pub/Sub requests.
+type TokenSource func(ctx context.Context) (string, error)
+
+// Transport publishes to Pub/Sub topics and pulls from subscriptions.
+// Create one with New.
+type Transport struct {
+	Project string
+	// Token authorizes requests. If nil, requests are sent without
+	// authorization, as the Pub/Sub emulator expects.
+	Token TokenSource
+	// Endpoint overrides DefaultEndpoint, for example with the address
+	// of the emulator.
+	Endpoint string
+	// MaxMessages is the number of messages pulled per request.
+	// Defaults to 100.
+	MaxMessages int
+	// RetryDelay is the pause after a failed pull request. Defaults to
+	// one second.
+	RetryDelay time.Duration
+	// OnError is called with the errors of pull and acknowledge requests,
+	// which are otherwise retried silently.
+	OnError func(error)
+	// HTTPClient is used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Subscriptions are the subscription IDs Receive pulls from. Bridges
+	// pull from the subscriptions of their mappings instead.
+	Subscriptions []string
+
+	mutex sync.Mutex
+	// stops ends the pulls started by Receive.
+	stops []func() error
+}
+
+// New creates a transport for project authorized by token.
+func New(project string, token TokenSource) *Transport {
+	return &Transport{Project: project, Token: token}
+}
+
+// pubsubMessage is the JSON representation of a Pub/Sub message. Data is
+// base64 encoded by encoding/json.
+type pubsubMessage struct {
+	Data        []byte            `json:"data,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	OrderingKey string            `json:"orderingKey,omitempty"`
+}
+
+// PublishMessage publishes message to topic, a topic ID of the project.
+func (t *Transport) PublishMessage(ctx context.Context, topic string, message bridge.Message) error {
+	request := struct {
+		Messages []pubsubMessage `json:"messages"`
+	}{[]pubsubMessage{{Data: message.Data, Attributes: message.Attributes, OrderingKey: message.OrderingKey}}}
+	return t.do(ctx, "topics/"+topic+":publish", request, nil)
+}
+
+// SubscribeMessages pulls messages from subscription, a subscription ID
+// of the project, on a new goroutine until the returned function is
+// called. Each message is acknowledged once handler returns. queue is
+// ignored; competing consumers share a Pub/Sub subscription.
+func (t *Transport) SubscribeMessages(subscription, queue string, handler func(message bridge.Message)) (func() error, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var pulling sync.WaitGroup
+	pulling.Add(1)
+	go func() {
+		defer pulling.Done()
+		t.pull(ctx, subscription, handler)
+	}()
+	return func() error {
+		cancel()
+		pulling.Wait()
+		return nil
+	}, nil
+}
+
+// Send publishes data to topic, a topic ID of the project, without
+// attributes.
+func (t *Transport) Send(topic string, data []byte) error {
+	return t.PublishMessage(context.Background(), topic, bridge.Message{Data: data})
+}
+
+// Receive pulls from every subscription in Subscriptions until Close,
+// passing the data of each message to handler with the subscription ID as
+// its topic.
+func (t *Transport) Receive(handler func(topic string, data []byte)) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, subscription := range t.Subscriptions {
+		stop, _ := t.SubscribeMessages(subscription, "", func(message bridge.Message) {
+			handler(subscription, message.Data)
+		})
+		t.stops = append(t.stops, stop)
+	}
+	return nil
+}
+
+// Close stops the pulls started by Receive and waits for them to end.
+func (t *Transport) Close() error {
+	t.mutex.Lock()
+	stops := t.stops
+	t.stops = nil
+	t.mutex.Unlock()
+	for _, stop := range stops {
+		stop()
+	}
+	return nil
+}
+
+// pull receives and acknowledges messages until ctx is done.
+func (t *Transport) pull(ctx context.Context, subscription string, handler func(message bridge.Message)) {
+	request := struct {
+		MaxMessages int `json:"maxMessages"`
+	}{t.MaxMessages}
+	if request.MaxMessages <= 0 {
+		request.MaxMessages = 100
+	}
+
+	for ctx.Err() == nil {
+		var response struct {
+			ReceivedMessages []struct {
+				AckID   string        `json:"ackId"`
+				Message pubsubMessage `json:"message"`
+			} `json:"receivedMessages"`
+		}
+		if err := t.do(ctx, "subscriptions/"+subscription+":pull", request, &response); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			t.fail(err)
+			delay := t.RetryDelay
+			if delay <= 0 {
+				delay = time.Second
+			}
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		if len(response.ReceivedMessages) == 0 {
+			continue
+		}
+
+		ack := struct {
+			AckIDs []string `json:"ackIds"`
+		}{}
+		for _, received := range response.ReceivedMessages {
+			handler(bridge.Message{
+				Data:        received.Message.Data,
+				Attributes:  received.Message.Attributes,
+				OrderingKey: received.Message.OrderingKey,
+			})
+			ack.AckIDs = append(ack.AckIDs, received.AckID)
+		}
+		// Acknowledging must not be cut short by unsubscribing, or the
+		// handled messages would be delivered again.
+		if err := t.do(context.WithoutCancel(ctx), "subscriptions/"+subscription+":acknowledge", ack, nil); err != nil {
+			t.fail(err)
+		}
+	}
+}
+
+// do posts request as JSON to the project resource path and decodes the
+// response into response, if it is not nil.
+func (t *Transport) do(ctx context.Context, path string, request, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	endpoint := t.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	target := strings.TrimRight(endpoint, "/") + "/v1/projects/" + url.PathEscape(t.Project) + "/" + path
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.Token != nil {
+		token, err := t.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("gcpbridge: obtaining token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := t.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("gcpbridge: %s: %s: %s", path, resp.Status, bytes.TrimSpace(message))
+	}
+	if response == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("gcpbridge: decoding %s response: %w", path, err)
+	}
+	return nil
+}
+
+// fail reports err to the configured error handler.
+func (t *Transport) fail(err error) {
+	if t.OnError != nil {
+		t.OnError(err)
+	}
+}
@@ -0,0 +1,210 @@
+package gcpbridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+	"github.com/Papiermond/eventbus/bridge"
+)
+
+// fakePubSub is a minimal Pub/Sub server whose subscriptions receive every
+// message published to the topic of the same name.
+type fakePubSub struct {
+	mutex     sync.Mutex
+	published []pubsubMessage
+	pending   []pubsubMessage
+	acked     []string
+	tokens    []string
+}
+
+func (f *fakePubSub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	f.tokens = append(f.tokens, r.Header.Get("Authorization"))
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/projects/game/topics/") && strings.HasSuffix(r.URL.Path, ":publish"):
+		var request struct{ Messages []pubsubMessage }
+		json.NewDecoder(r.Body).Decode(&request)
+		f.published = append(f.published, request.Messages...)
+		f.pending = append(f.pending, request.Messages...)
+		f.mutex.Unlock()
+		w.Write([]byte(`{"messageIds":["1"]}`))
+
+	case strings.HasSuffix(r.URL.Path, ":pull"):
+		pending := f.pending
+		f.pending = nil
+		f.mutex.Unlock()
+		if len(pending) == 0 {
+			// Pub/Sub holds pull requests open while there are no messages.
+			time.Sleep(5 * time.Millisecond)
+		}
+		type received struct {
+			AckID   string        `json:"ackId"`
+			Message pubsubMessage `json:"message"`
+		}
+		var response struct {
+			ReceivedMessages []received `json:"receivedMessages"`
+		}
+		for _, message := range pending {
+			response.ReceivedMessages = append(response.ReceivedMessages, received{AckID: "ack-" + message.OrderingKey, Message: message})
+		}
+		json.NewEncoder(w).Encode(response)
+
+	case strings.HasSuffix(r.URL.Path, ":acknowledge"):
+		var request struct{ AckIDs []string }
+		json.NewDecoder(r.Body).Decode(&request)
+		f.acked = append(f.acked, request.AckIDs...)
+		f.mutex.Unlock()
+		w.Write([]byte(`{}`))
+
+	default:
+		f.mutex.Unlock()
+		http.NotFound(w, r)
+	}
+}
+
+// orderPlaced is an event carrying a correlation ID.
+type orderPlaced struct {
+	ID string
+}
+
+func (e orderPlaced) GetType() eventbus.EventType {
+	return "order:placed"
+}
+
+func (e orderPlaced) CorrelationID() string {
+	return e.ID
+}
+
+// TestPubSubRoundTrip verifies that events reach another bus through Pub/Sub with attributes and ordering keys
+func TestPubSubRoundTrip(t *testing.T) {
+	fake := &fakePubSub{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	transport := New("game", func(ctx context.Context) (string, error) { return "secret", nil })
+	transport.Endpoint = server.URL
+	remote := New("game", transport.Token)
+	remote.Endpoint = server.URL
+
+	sender, receiver := eventbus.New(), eventbus.New()
+	defer sender.Close()
+	defer receiver.Close()
+	received := make(chan eventbus.Event, 1)
+	receiver.Subscribe("order:placed", func(event eventbus.Event) { received <- event })
+
+	in, err := bridge.NewRemote(receiver, transport, bridge.Config{
+		Mappings: []bridge.Mapping{{Local: "order:placed", Remote: "orders", Direction: bridge.In}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer in.Close()
+	out, err := bridge.NewRemote(sender, remote, bridge.Config{
+		Mappings: []bridge.Mapping{{
+			Local: "order:placed", Remote: "orders", Direction: bridge.Out,
+			OrderingKey: func(envelope eventbus.Envelope) string { return envelope.CorrelationID },
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sender.Publish(orderPlaced{ID: "o-1"})
+	out.Close()
+
+	select {
+	case event := <-received:
+		var order orderPlaced
+		if err := event.(eventbus.RawEvent).Decode(&order); err != nil || order.ID != "o-1" {
+			t.Errorf("Expected order o-1, got %+v (%v)", order, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the event to arrive")
+	}
+	in.Close()
+
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	message := fake.published[0]
+	if message.OrderingKey != "o-1" || message.Attributes[bridge.AttributeType] != "order:placed" || message.Attributes[bridge.AttributeCorrelationID] != "o-1" {
+		t.Errorf("Expected the ordering key and envelope attributes, got %+v", message)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, message.Attributes[bridge.AttributeTime]); err != nil {
+		t.Errorf("Expected an RFC 3339 time attribute, got %v", err)
+	}
+	if len(fake.acked) != 1 || fake.acked[0] != "ack-o-1" {
+		t.Errorf("Expected the message to be acknowledged, got %v", fake.acked)
+	}
+	for _, token := range fake.tokens {
+		if token != "Bearer secret" {
+			t.Fatalf("Expected every request to carry the token, got %q", token)
+		}
+	}
+}
+
+// TestPublishError verifies that failed requests return the status
+func TestPublishError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "topic not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	transport := New("game", nil)
+	transport.Endpoint = server.URL
+	err := transport.PublishMessage(context.Background(), "missing", bridge.Message{Data: []byte("{}")})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+}
+
+// TestRemoteTransport verifies that the transport sends and receives as a RemoteTransport and keeps attributes under NewRemote
+func TestRemoteTransport(t *testing.T) {
+	fake := &fakePubSub{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	transport := New("game", nil)
+	transport.Endpoint = server.URL
+	transport.Subscriptions = []string{"orders"}
+	received := make(chan string, 1)
+	if err := transport.Receive(func(topic string, data []byte) { received <- topic + " " + string(data) }); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := transport.Send("orders", []byte(`{"ID":"o-1"}`)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	select {
+	case message := <-received:
+		if message != `orders {"ID":"o-1"}` {
+			t.Errorf("Expected the order on orders, got %s", message)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the message to arrive")
+	}
+	transport.Close()
+
+	bus := eventbus.New()
+	defer bus.Close()
+	out, err := bridge.NewRemote(bus, transport, bridge.Config{
+		Mappings: []bridge.Mapping{{Local: "order:placed", Remote: "orders", Direction: bridge.Out}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Publish(orderPlaced{ID: "o-2"})
+	out.Close()
+
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if last := fake.published[len(fake.published)-1]; last.Attributes[bridge.AttributeCorrelationID] != "o-2" {
+		t.Errorf("Expected the envelope attributes under NewRemote, got %+v", last)
+	}
+}
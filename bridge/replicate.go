@@ -0,0 +1,509 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// DefaultReplicationBatch is the number of envelopes a Replicator sends
+// per message when ReplicationConfig.BatchSize is zero.
+const DefaultReplicationBatch = 100
+
+// DefaultResyncInterval is how long a Replica waits before repeating an
+// unanswered sync request when ReplicationConfig.ResyncInterval is zero.
+const DefaultResyncInterval = 5 * time.Second
+
+// ErrReplicationGap is reported by Replica.Healthy while the replica is
+// missing envelopes it has requested from the source.
+var ErrReplicationGap = errors.New("bridge: replication gap")
+
+// errBatchFull stops reading the store once a batch is complete.
+var errBatchFull = errors.New("bridge: batch full")
+
+// ReplicationConfig describes both ends of a replication link.
+type ReplicationConfig struct {
+	// Name identifies the link in errors.
+	Name string
+	// Subject is the remote subject carrying the log.
+	Subject string
+	// SyncSubject is the remote subject replicas send sync requests on.
+	// Defaults to Subject with ".sync" appended.
+	SyncSubject string
+	// BatchSize is the maximum number of envelopes per message.
+	// DefaultReplicationBatch if zero.
+	BatchSize int
+	// CompressAbove compresses the payloads of envelopes longer than this
+	// many bytes. Zero disables compression.
+	CompressAbove int
+	// ResyncInterval is how long a replica waits for the missing
+	// envelopes before requesting them again. DefaultResyncInterval if
+	// zero.
+	ResyncInterval time.Duration
+	// OnError is called with the errors reading, encoding, sending, or
+	// restoring envelopes.
+	OnError func(error)
+}
+
+// withDefaults returns config with its zero fields defaulted.
+func (config ReplicationConfig) withDefaults() (ReplicationConfig, error) {
+	if config.Subject == "" {
+		return config, fmt.Errorf("%w: replication needs a subject", ErrInvalidConfig)
+	}
+	if config.SyncSubject == "" {
+		config.SyncSubject = config.Subject + ".sync"
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultReplicationBatch
+	}
+	if config.ResyncInterval <= 0 {
+		config.ResyncInterval = DefaultResyncInterval
+	}
+	return config, nil
+}
+
+// replicationBatch is a message of the log subject. Previous is the
+// sequence of the envelope sent before the first of the batch, which
+// lets replicas detect lost messages.
+type replicationBatch struct {
+	Previous  uint64            `json:"previous"`
+	Envelopes []json.RawMessage `json:"envelopes"`
+}
+
+// syncRequest asks the source to send the log from after After.
+type syncRequest struct {
+	After uint64 `json:"after"`
+}
+
+// Replicator forwards the committed log of a store to a remote region
+// over a Transport, so a Replica there keeps a copy for disaster
+// recovery. Replication is asynchronous: Sync or Run send the envelopes
+// appended since the last call, and publishing on the bus never waits for
+// the remote region.
+//
+// Each message carries the sequence of the envelope sent before it, so
+// the replica notices lost messages and asks for the log from its last
+// envelope on the sync subject; the replicator then resumes from there.
+// Replicas also ask when they start, so a replicator that restarted, or
+// a replica that was down, resumes where the replica stopped instead of
+// resending the whole log.
+//
+// The replicator keeps a single position. Several replicas sharing one
+// SyncSubject move it back and forth with their requests, so each one
+// receives envelopes it already has; give each replica its own Subject
+// and SyncSubject, and a replicator per replica.
+//
+// Example:
+//
+//	replicator, err := bridge.NewReplicator(store, natsTransport{conn}, bridge.ReplicationConfig{
+//	    Name: "eu-west", Subject: "orders.log",
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer replicator.Close()
+//	go replicator.Run(ctx, time.Second)
+type Replicator struct {
+	store     eventbus.EventStore
+	transport Transport
+	config    ReplicationConfig
+	// syncing serializes Sync.
+	syncing sync.Mutex
+
+	mutex sync.Mutex
+	// cursor is the sequence of the last envelope sent, or requested by
+	// a replica.
+	cursor      uint64
+	failure     error
+	closed      bool
+	unsubscribe func() error
+}
+
+// NewReplicator creates a replicator sending the log of store from its
+// start, and listens for the sync requests of replicas.
+func NewReplicator(store eventbus.EventStore, transport Transport, config ReplicationConfig) (*Replicator, error) {
+	config, err := config.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	r := &Replicator{store: store, transport: transport, config: config}
+	r.unsubscribe, err = transport.Subscribe(config.SyncSubject, "", r.request)
+	if err != nil {
+		return nil, fmt.Errorf("replication %s: subscribing to %s: %w", config.Name, config.SyncSubject, err)
+	}
+	return r, nil
+}
+
+// Sync sends every envelope appended after the last one sent and returns
+// the number sent. With nothing to send, it sends an empty message
+// announcing the position instead. It stops at the first error, and the
+// next call sends the remaining envelopes again.
+func (r *Replicator) Sync(ctx context.Context) (int, error) {
+	r.syncing.Lock()
+	defer r.syncing.Unlock()
+
+	sent := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+		r.mutex.Lock()
+		previous, closed := r.cursor, r.closed
+		r.mutex.Unlock()
+		if closed {
+			return sent, ErrClosed
+		}
+
+		batch, last, err := r.batch(previous)
+		if err == nil && (len(batch.Envelopes) > 0 || sent == 0) {
+			// An empty batch announces the position, so replicas notice
+			// when the last batches were lost.
+			err = r.send(batch)
+		}
+		r.mutex.Lock()
+		r.failure = err
+		if err == nil && r.cursor == previous {
+			// A sync request received meanwhile takes precedence.
+			r.cursor = last
+		}
+		r.mutex.Unlock()
+		if err != nil {
+			r.fail(err)
+			return sent, err
+		}
+		if len(batch.Envelopes) == 0 {
+			return sent, nil
+		}
+		sent += len(batch.Envelopes)
+	}
+}
+
+// Run syncs every interval until ctx is cancelled or the replicator is
+// closed. Errors are retried on the next tick.
+func (r *Replicator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Sync(ctx); errors.Is(err, ErrClosed) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Position returns the sequence of the last envelope sent.
+func (r *Replicator) Position() uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.cursor
+}
+
+// Healthy returns nil if the last sync succeeded, and otherwise its
+// error, or ErrClosed once the replicator is closed. It can be added to
+// the checks of a bus with eventbus.EventBus.AddHealthCheck.
+func (r *Replicator) Healthy() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	return r.failure
+}
+
+// Close stops listening for sync requests. Syncing afterwards returns
+// ErrClosed.
+func (r *Replicator) Close() error {
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mutex.Unlock()
+	return r.unsubscribe()
+}
+
+// batch reads up to BatchSize envelopes following previous and returns
+// them with the sequence of the last one.
+func (r *Replicator) batch(previous uint64) (replicationBatch, uint64, error) {
+	batch := replicationBatch{Previous: previous}
+	last := previous
+	err := r.store.Read(previous+1, func(envelope eventbus.Envelope) error {
+		if len(batch.Envelopes) >= r.config.BatchSize {
+			return errBatchFull
+		}
+		line, err := eventbus.MarshalEnvelope(envelope, r.config.CompressAbove)
+		if err != nil {
+			return err
+		}
+		batch.Envelopes = append(batch.Envelopes, line)
+		last = envelope.Sequence
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchFull) {
+		return batch, last, fmt.Errorf("replication %s: reading the log after %d: %w", r.config.Name, previous, err)
+	}
+	return batch, last, nil
+}
+
+// send publishes batch on the log subject.
+func (r *Replicator) send(batch replicationBatch) error {
+	data, err := json.Marshal(batch)
+	if err == nil {
+		err = r.transport.Publish(r.config.Subject, data)
+	}
+	if err != nil {
+		return fmt.Errorf("replication %s: publishing to %s: %w", r.config.Name, r.config.Subject, err)
+	}
+	return nil
+}
+
+// request moves the cursor to the position a replica asked for, so the
+// next sync resumes from there.
+func (r *Replicator) request(data []byte) {
+	var request syncRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		r.fail(fmt.Errorf("replication %s: decoding sync request: %w", r.config.Name, err))
+		return
+	}
+	r.mutex.Lock()
+	r.cursor = request.After
+	r.mutex.Unlock()
+}
+
+// fail reports err to the configured error handler.
+func (r *Replicator) fail(err error) {
+	if r.config.OnError != nil {
+		r.config.OnError(err)
+	}
+}
+
+// Replica restores the log sent by a Replicator into a local store. The
+// store must implement eventbus.SparseRestorer, like eventbus.MemoryStore,
+// or eventbus.Restorer, and events are restored as eventbus.RawEvent
+// values, as by eventbus.History.Import. A Restorer requires consecutive
+// sequences, so it cannot replicate a source store with holes, such as
+// one with a memory budget or truncated by an archiver. To fail over,
+// create a bus with the store and rebuild projections from it.
+//
+// Gaps are detected from the sequence each message says preceded it, not
+// from the sequences themselves, so holes of the source store are kept.
+// Envelopes already restored are skipped. A message that does not follow
+// the last envelope restored, because an earlier one was lost, is
+// dropped and the log is requested again from the last envelope. Requests
+// are sent at most once per ResyncInterval.
+//
+// Example:
+//
+//	standby := eventbus.NewMemoryStore()
+//	replica, err := bridge.NewReplica(standby, natsTransport{conn}, bridge.ReplicationConfig{
+//	    Name: "eu-west", Subject: "orders.log",
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer replica.Close()
+type Replica struct {
+	restore   func(envelope eventbus.Envelope) error
+	transport Transport
+	config    ReplicationConfig
+
+	mutex sync.Mutex
+	// last is the sequence of the last envelope restored.
+	last uint64
+	// gap is set while envelopes following last are known to be missing.
+	gap bool
+	// asked is when the log was last requested.
+	asked       time.Time
+	failure     error
+	closed      bool
+	unsubscribe func() error
+}
+
+// NewReplica creates a replica restoring into store, which must implement
+// eventbus.SparseRestorer or eventbus.Restorer, and asks the replicator
+// for the envelopes following the last one store holds.
+func NewReplica(store eventbus.EventStore, transport Transport, config ReplicationConfig) (*Replica, error) {
+	config, err := config.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	r := &Replica{transport: transport, config: config}
+	switch restorer := store.(type) {
+	case eventbus.SparseRestorer:
+		r.restore = restorer.RestoreSparse
+	case eventbus.Restorer:
+		r.restore = restorer.Restore
+	default:
+		return nil, fmt.Errorf("%w: replica store does not support restoring envelopes", ErrInvalidConfig)
+	}
+	err = store.Read(1, func(envelope eventbus.Envelope) error {
+		r.last = envelope.Sequence
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("replication %s: reading the replica store: %w", config.Name, err)
+	}
+
+	r.unsubscribe, err = transport.Subscribe(config.Subject, "", r.receive)
+	if err != nil {
+		return nil, fmt.Errorf("replication %s: subscribing to %s: %w", config.Name, config.Subject, err)
+	}
+	r.mutex.Lock()
+	r.asked = time.Now()
+	err = r.request()
+	r.mutex.Unlock()
+	if err != nil {
+		r.unsubscribe()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Position returns the sequence of the last envelope restored.
+func (r *Replica) Position() uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.last
+}
+
+// Healthy returns nil if the replica has restored every envelope it
+// received. It returns an error matching ErrReplicationGap while it waits
+// for lost envelopes, the last error restoring or requesting envelopes,
+// or ErrClosed once the replica is closed. It can be added to the checks
+// of a bus with eventbus.EventBus.AddHealthCheck.
+func (r *Replica) Healthy() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	switch {
+	case r.closed:
+		return ErrClosed
+	case r.gap:
+		return fmt.Errorf("%w: waiting for the envelopes after %d", ErrReplicationGap, r.last)
+	}
+	return r.failure
+}
+
+// Close stops receiving the log.
+func (r *Replica) Close() error {
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mutex.Unlock()
+	return r.unsubscribe()
+}
+
+// receive restores the envelopes of a batch that follow the last one
+// restored, and requests the log again if envelopes are missing or the
+// replicator is resending envelopes already restored.
+func (r *Replica) receive(data []byte) {
+	var batch replicationBatch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		r.fail(fmt.Errorf("replication %s: decoding batch: %w", r.config.Name, err))
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		return
+	}
+	if batch.Previous > r.last {
+		// An earlier batch was lost.
+		r.gap = true
+		r.ask()
+		return
+	}
+
+	restored := false
+	previous := batch.Previous
+	for _, line := range batch.Envelopes {
+		envelope, err := eventbus.UnmarshalEnvelope(line)
+		if err != nil {
+			r.report(fmt.Errorf("replication %s: decoding envelope after %d: %w", r.config.Name, r.last, err))
+			r.ask()
+			return
+		}
+		if envelope.Sequence <= previous {
+			r.report(fmt.Errorf("replication %s: envelope %d out of order after %d", r.config.Name, envelope.Sequence, previous))
+			r.ask()
+			return
+		}
+		previous = envelope.Sequence
+		if envelope.Sequence <= r.last {
+			continue
+		}
+		// The envelope follows the last one restored in the source log,
+		// whatever the sequences skipped in between.
+		if err := r.restore(envelope); err != nil {
+			r.report(fmt.Errorf("replication %s: restoring %d: %w", r.config.Name, envelope.Sequence, err))
+			r.ask()
+			return
+		}
+		r.last = envelope.Sequence
+		r.gap = false
+		restored = true
+	}
+	if !restored && len(batch.Envelopes) > 0 {
+		// The replicator is behind the replica, for example after a
+		// restart, so it is told to skip ahead.
+		r.ask()
+	}
+	r.failure = nil
+}
+
+// ask requests the log following the last envelope restored, unless it
+// was requested less than ResyncInterval ago. The caller must hold
+// r.mutex.
+func (r *Replica) ask() {
+	now := time.Now()
+	if now.Sub(r.asked) < r.config.ResyncInterval {
+		return
+	}
+	r.asked = now
+	r.report(r.request())
+}
+
+// request publishes a sync request for the log following the last
+// envelope restored. The caller must hold r.mutex.
+func (r *Replica) request() error {
+	data, err := json.Marshal(syncRequest{After: r.last})
+	if err == nil {
+		err = r.transport.Publish(r.config.SyncSubject, data)
+	}
+	if err != nil {
+		return fmt.Errorf("replication %s: requesting the log after %d: %w", r.config.Name, r.last, err)
+	}
+	return nil
+}
+
+// report records err for Healthy and passes it to the error handler. The
+// caller must hold r.mutex.
+func (r *Replica) report(err error) {
+	if err == nil {
+		return
+	}
+	r.failure = err
+	r.fail(err)
+}
+
+// fail reports err to the configured error handler.
+func (r *Replica) fail(err error) {
+	if r.config.OnError != nil {
+		r.config.OnError(err)
+	}
+}
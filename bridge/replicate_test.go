@@ -0,0 +1,222 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// lossyBroker is a broker that loses the next messages on a subject
+type lossyBroker struct {
+	*broker
+	subject string
+	lose    atomic.Int32
+}
+
+func (b *lossyBroker) Publish(subject string, data []byte) error {
+	if subject == b.subject && b.lose.Add(-1) >= 0 {
+		return nil
+	}
+	return b.broker.Publish(subject, data)
+}
+
+// sequences returns the sequences held by store
+func sequences(t *testing.T, store eventbus.EventStore) []uint64 {
+	t.Helper()
+	var sequences []uint64
+	err := store.Read(1, func(envelope eventbus.Envelope) error {
+		sequences = append(sequences, envelope.Sequence)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error reading the store, got %v", err)
+	}
+	return sequences
+}
+
+// TestReplication verifies that the committed log is restored in the replica with its sequences and payloads
+func TestReplication(t *testing.T) {
+	source := eventbus.NewMemoryStore()
+	bus := eventbus.New(eventbus.WithStore(source))
+	defer bus.Close()
+	remote := newBroker()
+	config := ReplicationConfig{Name: "dr", Subject: "orders.log", BatchSize: 2, CompressAbove: 8}
+
+	replicator, err := NewReplicator(source, remote, config)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer replicator.Close()
+	standby := eventbus.NewMemoryStore()
+	replica, err := NewReplica(standby, remote, config)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer replica.Close()
+
+	for i := 1; i <= 5; i++ {
+		bus.Publish(eventbus.Of("order:placed", map[string]string{"id": "order-with-a-long-id"}))
+	}
+	sent, err := replicator.Sync(context.Background())
+	if err != nil || sent != 5 {
+		t.Fatalf("Expected 5 envelopes sent, got %d and %v", sent, err)
+	}
+	if got := sequences(t, standby); len(got) != 5 || got[4] != 5 {
+		t.Errorf("Expected sequences 1 to 5 restored, got %v", got)
+	}
+	if replicator.Position() != 5 || replica.Position() != 5 {
+		t.Errorf("Expected both ends at 5, got %d and %d", replicator.Position(), replica.Position())
+	}
+
+	var restored eventbus.Envelope
+	standby.Read(3, func(envelope eventbus.Envelope) error {
+		restored = envelope
+		return errors.New("stop")
+	})
+	var payload map[string]string
+	if raw, ok := restored.Event.(eventbus.RawEvent); !ok || raw.Type != "order:placed" || raw.Decode(&payload) != nil || payload["id"] != "order-with-a-long-id" {
+		t.Errorf("Expected the raw order restored, got %#v", restored.Event)
+	}
+
+	if sent, err := replicator.Sync(context.Background()); err != nil || sent != 0 {
+		t.Errorf("Expected nothing left to send, got %d and %v", sent, err)
+	}
+	if err := replica.Healthy(); err != nil {
+		t.Errorf("Expected a healthy replica, got %v", err)
+	}
+}
+
+// TestReplicationGap verifies that a lost batch is detected and sent again on request
+func TestReplicationGap(t *testing.T) {
+	source := eventbus.NewMemoryStore()
+	bus := eventbus.New(eventbus.WithStore(source))
+	defer bus.Close()
+	remote := &lossyBroker{broker: newBroker(), subject: "orders.log"}
+	config := ReplicationConfig{Subject: "orders.log", ResyncInterval: 1}
+
+	replicator, _ := NewReplicator(source, remote, config)
+	defer replicator.Close()
+	standby := eventbus.NewMemoryStore()
+	replica, _ := NewReplica(standby, remote, config)
+	defer replica.Close()
+
+	bus.Publish(eventbus.Of("order:placed", 1))
+	replicator.Sync(context.Background())
+
+	remote.lose.Store(1)
+	bus.Publish(eventbus.Of("order:placed", 2))
+	replicator.Sync(context.Background())
+	if replica.Position() != 1 {
+		t.Fatalf("Expected the replica at 1 after losing a batch, got %d", replica.Position())
+	}
+
+	// The empty batch announcing position 2 reveals the gap.
+	replicator.Sync(context.Background())
+	if err := replica.Healthy(); !errors.Is(err, ErrReplicationGap) {
+		t.Errorf("Expected ErrReplicationGap, got %v", err)
+	}
+	if replicator.Position() != 1 {
+		t.Errorf("Expected the replicator rewound to 1, got %d", replicator.Position())
+	}
+
+	bus.Publish(eventbus.Of("order:placed", 3))
+	replicator.Sync(context.Background())
+	if got := sequences(t, standby); len(got) != 3 || got[2] != 3 {
+		t.Errorf("Expected sequences 1 to 3 restored, got %v", got)
+	}
+	if err := replica.Healthy(); err != nil {
+		t.Errorf("Expected a healthy replica after the resync, got %v", err)
+	}
+}
+
+// TestReplicationResume verifies that a replica resumes from its last envelope instead of receiving the whole log again
+func TestReplicationResume(t *testing.T) {
+	source := eventbus.NewMemoryStore()
+	bus := eventbus.New(eventbus.WithStore(source))
+	defer bus.Close()
+	for i := 1; i <= 4; i++ {
+		bus.Publish(eventbus.Of("order:placed", i))
+	}
+	standby := eventbus.NewMemoryStore()
+	source.Read(1, func(envelope eventbus.Envelope) error {
+		if envelope.Sequence <= 3 {
+			return standby.Restore(envelope)
+		}
+		return nil
+	})
+
+	remote := newBroker()
+	config := ReplicationConfig{Subject: "orders.log"}
+	replicator, _ := NewReplicator(source, remote, config)
+	defer replicator.Close()
+	replica, err := NewReplica(standby, remote, config)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer replica.Close()
+
+	sent, err := replicator.Sync(context.Background())
+	if err != nil || sent != 1 {
+		t.Errorf("Expected only envelope 4 sent, got %d and %v", sent, err)
+	}
+	if got := sequences(t, standby); len(got) != 4 {
+		t.Errorf("Expected sequences 1 to 4, got %v", got)
+	}
+
+	replica.Close()
+	if err := replica.Healthy(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+// TestReplicationInvalidConfig verifies that replication needs a subject and a restorable replica store
+func TestReplicationInvalidConfig(t *testing.T) {
+	remote := newBroker()
+	if _, err := NewReplicator(eventbus.NewMemoryStore(), remote, ReplicationConfig{}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig without a subject, got %v", err)
+	}
+	type appendOnly struct{ eventbus.EventStore }
+	store := appendOnly{eventbus.NewMemoryStore()}
+	if _, err := NewReplica(store, remote, ReplicationConfig{Subject: "log"}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for a store without Restore, got %v", err)
+	}
+}
+
+// TestReplicationBudgetedSource verifies that a source store with eviction holes is replicated with the same holes
+func TestReplicationBudgetedSource(t *testing.T) {
+	budget := eventbus.NewMemoryBudget(0)
+	budget.LimitTopic("noise:*", 1)
+	source := eventbus.NewMemoryStoreWithBudget(budget)
+	bus := eventbus.New(eventbus.WithStore(source))
+	defer bus.Close()
+	for i := 1; i <= 6; i++ {
+		bus.Publish(eventbus.Of("order:placed", i))
+		bus.Publish(eventbus.Of("noise:tick", i))
+	}
+	want := sequences(t, source)
+	if len(want) != 6 || want[1] != 3 {
+		t.Fatalf("Expected every noise event evicted, got %v", want)
+	}
+
+	remote := newBroker()
+	config := ReplicationConfig{Subject: "orders.log", BatchSize: 4}
+	replicator, _ := NewReplicator(source, remote, config)
+	defer replicator.Close()
+	standby := eventbus.NewMemoryStore()
+	replica, _ := NewReplica(standby, remote, config)
+	defer replica.Close()
+
+	if sent, err := replicator.Sync(context.Background()); err != nil || sent != 6 {
+		t.Fatalf("Expected 6 envelopes sent, got %d and %v", sent, err)
+	}
+	if got := sequences(t, standby); !slices.Equal(got, want) {
+		t.Errorf("Expected sequences %v, got %v", want, got)
+	}
+	if err := replica.Healthy(); err != nil {
+		t.Errorf("Expected a healthy replica, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+// Package shmbridge implements bridge.RemoteTransport over shared memory,
+// so two processes on the same host, such as a game client and a sidecar
+// recorder, exchange hundreds of thousands of events per second without
+// the overhead of a socket.
+//
+// The processes map the same file, which holds one ring buffer per
+// direction. One process creates the file with Create and the other opens
+// it with Open; each ring has a single writer and a single reader, so no
+// locks are shared between the processes. Readers poll the ring, sleeping
+// up to Transport.PollInterval while it is empty, and writers wait while
+// it is full.
+//
+// Example:
+//
+//	// In the game client
+//	transport, err := shmbridge.Create("/dev/shm/game-events", 4<<20)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	b, err := bridge.NewRemote(bus, transport, bridge.Config{
+//	    Mappings: []bridge.Mapping{{Local: "player:moved", Remote: "moves", Direction: bridge.Out}},
+//	})
+//
+//	// In the recorder
+//	transport, err := shmbridge.Open("/dev/shm/game-events")
+//
+// On Linux, files under /dev/shm live in memory only. The file is not
+// removed by Close. The package builds on Linux, macOS, and the BSDs.
+package shmbridge
@@ -0,0 +1,116 @@
+package shmbridge
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ErrMessageTooLarge is returned by Send for messages that do not fit in
+// the ring buffer.
+var ErrMessageTooLarge = errors.New("shmbridge: message too large")
+
+// ErrCorrupted is reported when a ring buffer holds an invalid record,
+// which happens if both processes write the same direction.
+var ErrCorrupted = errors.New("shmbridge: corrupted ring buffer")
+
+const (
+	// ringHeaderSize holds the write position and, one cache line later,
+	// the read position of a ring.
+	ringHeaderSize = 128
+	// recordHeaderSize holds the length of topic and data and the length
+	// of the topic.
+	recordHeaderSize = 8
+	// wrapMarker is the length of a record skipping the end of the ring.
+	wrapMarker = 0xFFFFFFFF
+)
+
+// ring is a single-producer, single-consumer ring buffer over shared
+// memory. Positions grow monotonically and are reduced modulo the size,
+// a power of two. Records are aligned to 8 bytes.
+type ring struct {
+	head *atomic.Uint64
+	tail *atomic.Uint64
+	data []byte
+	mask uint64
+}
+
+// newRing returns the ring stored in memory, which is ringHeaderSize
+// bytes followed by the data. memory must be 8-byte aligned.
+func newRing(memory []byte) *ring {
+	return &ring{
+		head: (*atomic.Uint64)(unsafe.Pointer(&memory[0])),
+		tail: (*atomic.Uint64)(unsafe.Pointer(&memory[64])),
+		data: memory[ringHeaderSize:],
+		mask: uint64(len(memory)-ringHeaderSize) - 1,
+	}
+}
+
+// recordSize returns the aligned size of a record.
+func recordSize(topic string, data []byte) uint64 {
+	return (recordHeaderSize + uint64(len(topic)+len(data)) + 7) &^ 7
+}
+
+// write appends a record and reports whether there was room for it.
+func (r *ring) write(topic string, data []byte) bool {
+	size := recordSize(topic, data)
+	for {
+		head, tail := r.head.Load(), r.tail.Load()
+		free := uint64(len(r.data)) - (head - tail)
+		offset := head & r.mask
+
+		if remaining := uint64(len(r.data)) - offset; size > remaining {
+			// Records are contiguous; skip to the start of the ring.
+			if free < remaining {
+				return false
+			}
+			binary.LittleEndian.PutUint32(r.data[offset:], wrapMarker)
+			r.head.Store(head + remaining)
+			continue
+		}
+		if free < size {
+			return false
+		}
+
+		record := r.data[offset : offset+size]
+		binary.LittleEndian.PutUint32(record, uint32(len(topic)+len(data)))
+		binary.LittleEndian.PutUint16(record[4:], uint16(len(topic)))
+		n := copy(record[recordHeaderSize:], topic)
+		copy(record[recordHeaderSize+n:], data)
+		r.head.Store(head + size)
+		return true
+	}
+}
+
+// read passes the available records to handler, with copies of their
+// data, and returns their number.
+func (r *ring) read(handler func(topic string, data []byte)) (int, error) {
+	count := 0
+	for {
+		head, tail := r.head.Load(), r.tail.Load()
+		if head == tail {
+			return count, nil
+		}
+		offset := tail & r.mask
+		remaining := uint64(len(r.data)) - offset
+
+		length := binary.LittleEndian.Uint32(r.data[offset:])
+		if length == wrapMarker {
+			r.tail.Store(tail + remaining)
+			continue
+		}
+		topicLength := uint64(binary.LittleEndian.Uint16(r.data[offset+4:]))
+		size := (recordHeaderSize + uint64(length) + 7) &^ 7
+		if size > remaining || size > head-tail || topicLength > uint64(length) {
+			return count, ErrCorrupted
+		}
+
+		record := r.data[offset+recordHeaderSize : offset+recordHeaderSize+uint64(length)]
+		topic := string(record[:topicLength])
+		data := append([]byte(nil), record[topicLength:]...)
+		r.tail.Store(tail + size)
+		handler(topic, data)
+		count++
+	}
+}
@@ -0,0 +1,65 @@
+package shmbridge
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"unsafe"
+)
+
+// newTestRing returns a ring with size bytes of data
+func newTestRing(size int) *ring {
+	memory := make([]uint64, (ringHeaderSize+size)/8)
+	return newRing(unsafe.Slice((*byte)(unsafe.Pointer(&memory[0])), ringHeaderSize+size))
+}
+
+// TestRingWraps verifies that records survive many passes around the ring
+func TestRingWraps(t *testing.T) {
+	r := newTestRing(256)
+
+	for i := range 100 {
+		data := []byte(fmt.Sprintf("message %d", i))
+		if !r.write("topic", data) {
+			t.Fatalf("Expected room for message %d", i)
+		}
+		var got []string
+		if _, err := r.read(func(topic string, data []byte) { got = append(got, topic+":"+string(data)) }); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(got) != 1 || got[0] != fmt.Sprintf("topic:message %d", i) {
+			t.Fatalf("Expected message %d, got %q", i, got)
+		}
+	}
+}
+
+// TestRingFull verifies that writes fail while the ring is full and succeed once it is read
+func TestRingFull(t *testing.T) {
+	r := newTestRing(256)
+	data := make([]byte, 50)
+
+	written := 0
+	for r.write("t", data) {
+		written++
+	}
+	if written != 4 {
+		t.Fatalf("Expected 4 records to fit, got %d", written)
+	}
+	n, err := r.read(func(string, []byte) {})
+	if n != written || err != nil {
+		t.Fatalf("Expected %d records, got %d (%v)", written, n, err)
+	}
+	if !r.write("t", data) {
+		t.Error("Expected room after reading")
+	}
+}
+
+// TestRingCorrupted verifies that invalid records are reported
+func TestRingCorrupted(t *testing.T) {
+	r := newTestRing(256)
+	r.write("topic", []byte("data"))
+	r.data[0] = 200
+
+	if _, err := r.read(func(string, []byte) {}); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("Expected ErrCorrupted, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package shmbridge
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// ErrClosed is returned by Send after Close.
+var ErrClosed = errors.New("shmbridge: transport closed")
+
+// DefaultPollInterval is the longest pause of an idle reader when
+// Transport.PollInterval is zero.
+const DefaultPollInterval = 200 * time.Microsecond
+
+// MinSize is the smallest ring buffer size accepted by Create.
+const MinSize = 4096
+
+const (
+	// fileHeaderSize holds the magic number, the version, and the ring size.
+	fileHeaderSize = 64
+	magic          = 0x4542534d // "EBSM"
+	version        = 1
+)
+
+// Transport is one end of a shared-memory channel. Create one with Create
+// or Open.
+type Transport struct {
+	// PollInterval is the longest pause of the reader while the ring is
+	// empty, trading latency against idle CPU use. Set it before Receive.
+	PollInterval time.Duration
+	// OnError is called if the incoming ring is found corrupted, after
+	// which nothing more is received.
+	OnError func(error)
+
+	file     *os.File
+	memory   []byte
+	outgoing *ring
+	incoming *ring
+
+	sending   sync.Mutex
+	closed    atomic.Bool
+	done      chan struct{}
+	receiving sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// Create creates the file at path, replacing any existing one, with two
+// ring buffers of size bytes each, and returns the creating end of the
+// channel. size is rounded up to a power of two of at least MinSize.
+func Create(path string, size int) (*Transport, error) {
+	if size < MinSize {
+		size = MinSize
+	}
+	if size > math.MaxInt32 {
+		return nil, fmt.Errorf("shmbridge: size %d exceeds %d", size, math.MaxInt32)
+	}
+	size = 1 << bits.Len(uint(size-1))
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Truncate(int64(fileSize(size))); err != nil {
+		file.Close()
+		return nil, err
+	}
+	t, err := mapFile(file, size, 0)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	binary.LittleEndian.PutUint32(t.memory[4:], version)
+	binary.LittleEndian.PutUint64(t.memory[8:], uint64(size))
+	// The magic number is written last, so Open never sees a partial header.
+	(*atomic.Uint32)(unsafe.Pointer(&t.memory[0])).Store(magic)
+	return t, nil
+}
+
+// Open opens a file created with Create and returns the other end of the
+// channel.
+func Open(path string) (*Transport, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, fileHeaderSize)
+	if _, err := file.ReadAt(header, 0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("shmbridge: reading header of %s: %w", path, err)
+	}
+	size := binary.LittleEndian.Uint64(header[8:])
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	switch {
+	case binary.LittleEndian.Uint32(header) != magic:
+		err = fmt.Errorf("shmbridge: %s is not a shared-memory channel", path)
+	case binary.LittleEndian.Uint32(header[4:]) != version:
+		err = fmt.Errorf("shmbridge: %s has unsupported version %d", path, binary.LittleEndian.Uint32(header[4:]))
+	case size < MinSize || size > math.MaxInt32 || size&(size-1) != 0 || info.Size() != int64(fileSize(int(size))):
+		err = fmt.Errorf("shmbridge: %s has invalid size %d", path, size)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	t, err := mapFile(file, int(size), 1)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// fileSize returns the size of a file with rings of size bytes.
+func fileSize(size int) int {
+	return fileHeaderSize + 2*(ringHeaderSize+size)
+}
+
+// mapFile maps file and returns the end writing to ring side.
+func mapFile(file *os.File, size, side int) (*Transport, error) {
+	memory, err := syscall.Mmap(int(file.Fd()), 0, fileSize(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("shmbridge: mapping %s: %w", file.Name(), err)
+	}
+	rings := [2]*ring{}
+	for i := range rings {
+		start := fileHeaderSize + i*(ringHeaderSize+size)
+		rings[i] = newRing(memory[start : start+ringHeaderSize+size])
+	}
+	return &Transport{
+		file:     file,
+		memory:   memory,
+		outgoing: rings[side],
+		incoming: rings[1-side],
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Send writes data for topic to the outgoing ring, waiting while it is
+// full. It returns ErrMessageTooLarge if the message can never fit.
+func (t *Transport) Send(topic string, data []byte) error {
+	if len(topic) > math.MaxUint16 || recordSize(topic, data) > uint64(len(t.outgoing.data)) {
+		return fmt.Errorf("%w: %d bytes for %s", ErrMessageTooLarge, len(data), topic)
+	}
+
+	t.sending.Lock()
+	defer t.sending.Unlock()
+	for wait := time.Duration(0); ; wait = t.backoff(wait) {
+		if t.closed.Load() {
+			return ErrClosed
+		}
+		if t.outgoing.write(topic, data) {
+			return nil
+		}
+		t.pause(wait)
+	}
+}
+
+// Receive reads the incoming ring on a new goroutine and calls handler
+// with each message until Close.
+func (t *Transport) Receive(handler func(topic string, data []byte)) error {
+	if t.closed.Load() {
+		return ErrClosed
+	}
+	t.receiving.Add(1)
+	go func() {
+		defer t.receiving.Done()
+		wait := time.Duration(0)
+		for {
+			select {
+			case <-t.done:
+				return
+			default:
+			}
+			n, err := t.incoming.read(handler)
+			if err != nil {
+				if t.OnError != nil {
+					t.OnError(err)
+				}
+				return
+			}
+			if n > 0 {
+				wait = 0
+				continue
+			}
+			t.pause(wait)
+			wait = t.backoff(wait)
+		}
+	}()
+	return nil
+}
+
+// pause yields for short waits and sleeps for longer ones.
+func (t *Transport) pause(wait time.Duration) {
+	if wait == 0 {
+		runtime.Gosched()
+		return
+	}
+	select {
+	case <-time.After(wait):
+	case <-t.done:
+	}
+}
+
+// backoff returns the wait following wait, doubling up to PollInterval.
+func (t *Transport) backoff(wait time.Duration) time.Duration {
+	limit := t.PollInterval
+	if limit <= 0 {
+		limit = DefaultPollInterval
+	}
+	if wait == 0 {
+		return min(time.Microsecond, limit)
+	}
+	return min(2*wait, limit)
+}
+
+// Close stops receiving and unmaps the file. Messages still in the
+// outgoing ring remain readable by the other process.
+func (t *Transport) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		t.closed.Store(true)
+		close(t.done)
+		t.receiving.Wait()
+		t.sending.Lock()
+		defer t.sending.Unlock()
+		err = syscall.Munmap(t.memory)
+		if closeErr := t.file.Close(); err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
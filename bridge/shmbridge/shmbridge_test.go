@@ -0,0 +1,142 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package shmbridge
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+	"github.com/Papiermond/eventbus/bridge"
+)
+
+// openPair creates a channel in a temporary directory and opens both ends
+func openPair(t testing.TB, size int) (*Transport, *Transport) {
+	path := filepath.Join(t.TempDir(), "events")
+	creator, err := Create(path, size)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	opener, err := Open(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() {
+		creator.Close()
+		opener.Close()
+	})
+	return creator, opener
+}
+
+// TestTransportBothWays verifies that both ends send and receive, including more data than fits in the ring
+func TestTransportBothWays(t *testing.T) {
+	creator, opener := openPair(t, MinSize)
+
+	received := make(chan string, 1)
+	creator.Receive(func(topic string, data []byte) { received <- topic + ":" + string(data) })
+	var total atomic.Int64
+	opener.Receive(func(topic string, data []byte) { total.Add(int64(len(data))) })
+
+	data := make([]byte, 1000)
+	for range 100 {
+		if err := creator.Send("bulk", data); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := opener.Send("reply", []byte("done")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	select {
+	case message := <-received:
+		if message != "reply:done" {
+			t.Errorf("Expected reply:done, got %q", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the reply to arrive")
+	}
+	deadline := time.Now().Add(time.Second)
+	for total.Load() < 100000 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if total.Load() != 100000 {
+		t.Errorf("Expected 100000 bytes, got %d", total.Load())
+	}
+}
+
+// TestTransportErrors verifies oversized messages, closed transports, and invalid files
+func TestTransportErrors(t *testing.T) {
+	creator, _ := openPair(t, MinSize)
+
+	if err := creator.Send("big", make([]byte, MinSize)); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("Expected ErrMessageTooLarge, got %v", err)
+	}
+	creator.Close()
+	if err := creator.Send("late", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "other")
+	os.WriteFile(path, make([]byte, 128), 0o600)
+	if _, err := Open(path); err == nil {
+		t.Error("Expected an error for a file that is not a channel")
+	}
+}
+
+// TestBridge verifies that events cross a shared-memory bridge
+func TestBridge(t *testing.T) {
+	creator, opener := openPair(t, 1<<16)
+	sender, receiver := eventbus.New(), eventbus.New()
+	defer sender.Close()
+	defer receiver.Close()
+
+	received := make(chan string, 1)
+	receiver.Subscribe("player:moved", func(event eventbus.Event) {
+		direction, _ := eventbus.Payload[string](event)
+		received <- direction
+	})
+	in, err := bridge.NewRemote(receiver, opener, bridge.Config{
+		Mappings: []bridge.Mapping{{Local: "player:moved", Remote: "moves", Direction: bridge.In}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer in.Close()
+	out, err := bridge.NewRemote(sender, creator, bridge.Config{
+		Mappings: []bridge.Mapping{{Local: "player:moved", Remote: "moves", Direction: bridge.Out}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer out.Close()
+
+	sender.Publish(eventbus.Of("player:moved", "north"))
+	select {
+	case direction := <-received:
+		if direction != "north" {
+			t.Errorf("Expected north, got %q", direction)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the event to arrive")
+	}
+}
+
+// BenchmarkSend measures the throughput of small messages between two ends
+func BenchmarkSend(b *testing.B) {
+	creator, opener := openPair(b, 4<<20)
+	var received atomic.Int64
+	opener.Receive(func(string, []byte) { received.Add(1) })
+
+	data := []byte(`{"x":1,"y":2}`)
+	b.ResetTimer()
+	for range b.N {
+		creator.Send("moves", data)
+	}
+	for received.Load() < int64(b.N) {
+		time.Sleep(10 * time.Microsecond)
+	}
+}
@@ -0,0 +1,94 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// OverBudgetType is the event type of the events published when a
+// synchronous delivery exceeds its latency budget.
+const OverBudgetType EventType = "eventbus:over_budget"
+
+// OverBudget is published when the listeners of a synchronous topic take
+// longer than the budget set with WithLatencyBudget.
+type OverBudget struct {
+	// EventType is the type of the event whose delivery ran over.
+	EventType EventType
+	// EventID is the bus sequence of the event, see Envelope.BusSequence.
+	EventID uint64
+	// Budget is the latency budget of the publish.
+	Budget time.Duration
+	// Elapsed is the time the listeners had taken when the budget check
+	// failed.
+	Elapsed time.Duration
+	// Deferred is the number of listeners moved off the publishing
+	// goroutine.
+	Deferred int
+}
+
+// GetType returns OverBudgetType.
+func (o OverBudget) GetType() EventType {
+	return OverBudgetType
+}
+
+// budgetKey is the context key of the budget set by WithLatencyBudget.
+type budgetKey struct{}
+
+// WithLatencyBudget returns a copy of ctx bounding the time PublishContext,
+// PublishAndWait, and PublishDetailed spend in the listeners of a
+// synchronous topic. Once the listeners called so far have taken longer
+// than budget, the remaining ones are run on a separate goroutine and an
+// OverBudget event is published, so a game loop can keep its frame time.
+// The budget is checked between listeners and stages; a single slow
+// listener is never interrupted.
+//
+// Deferred listeners may see the event after events published later.
+// PublishAndWait and PublishDetailed still wait for them.
+//
+// Example:
+//
+//	ctx := eventbus.WithLatencyBudget(ctx, 2*time.Millisecond)
+//	bus.PublishContext(ctx, FrameRendered{Frame: n})
+func WithLatencyBudget(ctx context.Context, budget time.Duration) context.Context {
+	return context.WithValue(ctx, budgetKey{}, budget)
+}
+
+// latencyBudget returns the budget of ctx, or 0 if it has none.
+func latencyBudget(ctx context.Context) time.Duration {
+	budget, _ := ctx.Value(budgetKey{}).(time.Duration)
+	return budget
+}
+
+// deferListeners runs the listeners of job from start on a new goroutine,
+// after publishing an OverBudget event. The caller must hold bus.mutex, so
+// Close waits for the goroutine.
+func (bus *eventBusImpl) deferListeners(job asyncJob, start int, budget, elapsed time.Duration) {
+	report := OverBudget{
+		EventType: job.event.GetType(),
+		Budget:    budget,
+		Elapsed:   elapsed,
+		Deferred:  len(job.listeners) - start,
+	}
+	if envelope, ok := job.ctx.Value(envelopeKey{}).(*Envelope); ok {
+		report.EventID = envelope.BusSequence
+	}
+
+	rest := job.listeners[start:]
+	for i, listener := range rest {
+		slot := job.waiter.add(start+i, listener)
+		if i == 0 {
+			job.slot = slot
+		}
+	}
+	job.listeners = rest
+	// Synchronous deliveries take no serial tickets.
+	job.serial = nil
+
+	bus.sending.Add(1)
+	go func() {
+		defer bus.sending.Done()
+		// Blocks until the publisher releases the bus lock.
+		bus.publish(context.Background(), report, nil)
+		job.run()
+	}()
+}
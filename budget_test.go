@@ -0,0 +1,93 @@
+package eventbus
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestLatencyBudget verifies that listeners past the budget are deferred and reported
+func TestLatencyBudget(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	reports := make(chan OverBudget, 1)
+	bus.Subscribe(OverBudgetType, func(event Event) { reports <- event.(OverBudget) })
+
+	release := make(chan struct{})
+	var deferred atomic.Int32
+	bus.Subscribe("frame:rendered", func(event Event) { time.Sleep(5 * time.Millisecond) })
+	bus.Subscribe("frame:rendered", func(event Event) {
+		<-release
+		deferred.Add(1)
+	})
+	bus.Subscribe("frame:rendered", func(event Event) { deferred.Add(1) })
+
+	ctx := WithLatencyBudget(context.Background(), time.Millisecond)
+	bus.PublishContext(ctx, testEvent{eventType: "frame:rendered"})
+	if deferred.Load() != 0 {
+		t.Errorf("Expected the listeners over budget to be deferred, got %d delivered", deferred.Load())
+	}
+	close(release)
+
+	select {
+	case report := <-reports:
+		if report.EventType != "frame:rendered" || report.Deferred != 2 || report.EventID != 1 {
+			t.Errorf("Expected 2 deferred listeners of event 1, got %+v", report)
+		}
+		if report.Elapsed < report.Budget {
+			t.Errorf("Expected elapsed time over the budget, got %+v", report)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an OverBudget event")
+	}
+
+	bus.Close()
+	if deferred.Load() != 2 {
+		t.Errorf("Expected the deferred listeners to run, got %d", deferred.Load())
+	}
+}
+
+// TestLatencyBudgetReceipt verifies that PublishDetailed waits for deferred listeners
+func TestLatencyBudgetReceipt(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	bus.Subscribe("frame:rendered", func(event Event) { time.Sleep(5 * time.Millisecond) }, WithName("slow"))
+	bus.Subscribe("frame:rendered", func(event Event) {}, WithName("deferred"))
+
+	ctx := WithLatencyBudget(context.Background(), time.Millisecond)
+	receipt, err := bus.PublishDetailed(ctx, testEvent{eventType: "frame:rendered"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(receipt.Deliveries) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", len(receipt.Deliveries))
+	}
+	for _, delivery := range receipt.Deliveries {
+		if !delivery.Completed {
+			t.Errorf("Expected %s to complete, got %+v", delivery.Handler, delivery)
+		}
+	}
+	if receipt.Deliveries[1].Handler != "deferred" || receipt.Deliveries[1].Index != 1 {
+		t.Errorf("Expected the deferred delivery at index 1, got %+v", receipt.Deliveries[1])
+	}
+}
+
+// TestLatencyBudgetWithin verifies that deliveries within the budget stay on the publisher
+func TestLatencyBudgetWithin(t *testing.T) {
+	bus := New()
+	reported := false
+	bus.Subscribe(OverBudgetType, func(event Event) { reported = true })
+	count := 0
+	bus.Subscribe("frame:rendered", func(event Event) { count++ })
+	bus.Subscribe("frame:rendered", func(event Event) { count++ })
+
+	bus.PublishContext(WithLatencyBudget(context.Background(), time.Second), testEvent{eventType: "frame:rendered"})
+	bus.Close()
+
+	if count != 2 || reported {
+		t.Errorf("Expected 2 synchronous deliveries without a report, got %d and %v", count, reported)
+	}
+}
@@ -0,0 +1,235 @@
+package busrpc
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/rpc"
+	"sync"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// Authentication errors returned to clients.
+var (
+	// ErrUnauthenticated is returned for calls made before a successful
+	// Authenticate, and for calls to a server with WithAuth that is not
+	// served with ServeConn or Serve.
+	ErrUnauthenticated = errors.New("busrpc: not authenticated")
+
+	// ErrForbidden is returned when a connection is not allowed to use an
+	// event type or subscription.
+	ErrForbidden = errors.New("busrpc: not allowed")
+)
+
+// Permissions lists the event types a connection may subscribe to and
+// publish, as patterns with the wildcard syntax of
+// eventbus.WithTopicConfig, such as "tenant-a:*".
+type Permissions struct {
+	Subscribe []eventbus.EventType
+	Publish   []eventbus.EventType
+}
+
+// CanSubscribe reports whether the permissions allow subscribing to
+// eventType.
+func (p Permissions) CanSubscribe(eventType eventbus.EventType) bool {
+	return allows(p.Subscribe, eventType)
+}
+
+// CanPublish reports whether the permissions allow publishing events of
+// eventType.
+func (p Permissions) CanPublish(eventType eventbus.EventType) bool {
+	return allows(p.Publish, eventType)
+}
+
+// allows reports whether eventType matches one of patterns.
+func allows(patterns []eventbus.EventType, eventType eventbus.EventType) bool {
+	for _, pattern := range patterns {
+		if eventbus.MatchPattern(pattern, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithAuth requires every connection to authenticate with a token before
+// other calls. authenticate returns the permissions of the token, or an
+// error to reject it. Connections must then be served with ServeConn or
+// Serve, which keep the permissions of each connection; the Server's own
+// methods reject every call.
+//
+// Tokens travel in clear text, so serve untrusted networks over TLS:
+//
+//	listener, _ := tls.Listen("tcp", ":7400", tlsConfig)
+//	go server.Serve(listener)
+func WithAuth(authenticate func(token string) (Permissions, error)) ServerOption {
+	return func(s *Server) {
+		s.authenticate = authenticate
+	}
+}
+
+// Serve accepts connections on listener and serves each with ServeConn. It
+// returns when Accept fails, for example because listener was closed.
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn serves one connection until the client hangs up, then cancels
+// the subscriptions it made. Unlike registering the Server with an
+// rpc.Server, it keeps the permissions of the connection when the server
+// was created with WithAuth.
+func (s *Server) ServeConn(conn io.ReadWriteCloser) {
+	c := &connection{server: s, subscriptions: make(map[uint64]bool)}
+	if s.authenticate == nil {
+		c.permissions = &Permissions{Subscribe: []eventbus.EventType{"*"}, Publish: []eventbus.EventType{"*"}}
+	}
+
+	server := rpc.NewServer()
+	server.RegisterName(ServiceName, c)
+	server.ServeConn(conn)
+	c.close()
+}
+
+// connection is the RPC receiver of one connection served with ServeConn.
+// It checks the connection's permissions and delegates to the Server.
+type connection struct {
+	server *Server
+
+	permissions   *Permissions
+	subscriptions map[uint64]bool
+	mutex         sync.Mutex
+}
+
+// Authenticate authenticates the connection with token.
+func (c *connection) Authenticate(token string, reply *struct{}) error {
+	if c.server.authenticate == nil {
+		return nil
+	}
+	permissions, err := c.server.authenticate(token)
+	if err != nil {
+		return ErrUnauthenticated
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.permissions = &permissions
+	return nil
+}
+
+// Publish publishes the event if the connection may publish its type.
+func (c *connection) Publish(message Message, reply *struct{}) error {
+	if err := c.check(message.Type, Permissions.CanPublish); err != nil {
+		return err
+	}
+	return c.server.publish(message)
+}
+
+// Subscribe subscribes if the connection may subscribe to eventType.
+func (c *connection) Subscribe(eventType eventbus.EventType, id *uint64) error {
+	if err := c.check(eventType, Permissions.CanSubscribe); err != nil {
+		return err
+	}
+	if err := c.server.subscribe(eventType, id); err != nil {
+		return err
+	}
+	c.own(*id)
+	return nil
+}
+
+// Resume resumes if the connection may subscribe to request.Type.
+func (c *connection) Resume(request ResumeRequest, id *uint64) error {
+	if err := c.check(request.Type, Permissions.CanSubscribe); err != nil {
+		return err
+	}
+	if err := c.server.resume(request, id); err != nil {
+		return err
+	}
+	c.own(*id)
+	return nil
+}
+
+// Next waits for the next event of a subscription of the connection.
+func (c *connection) Next(id uint64, message *Message) error {
+	if err := c.owns(id); err != nil {
+		return err
+	}
+	return c.server.receive(id, message)
+}
+
+// Cancel cancels a subscription of the connection.
+func (c *connection) Cancel(id uint64, reply *struct{}) error {
+	if err := c.owns(id); err != nil {
+		return err
+	}
+	c.server.cancel(id)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.subscriptions, id)
+	return nil
+}
+
+// check returns an error unless the connection is authenticated and its
+// permissions allow eventType.
+func (c *connection) check(eventType eventbus.EventType, allowed func(Permissions, eventbus.EventType) bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.permissions == nil {
+		return ErrUnauthenticated
+	}
+	if !allowed(*c.permissions, eventType) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// own records subscription id as made by the connection, or cancels it if
+// the connection has closed meanwhile.
+func (c *connection) own(id uint64) {
+	c.mutex.Lock()
+	closed := c.subscriptions == nil
+	if !closed {
+		c.subscriptions[id] = true
+	}
+	c.mutex.Unlock()
+
+	if closed {
+		c.server.cancel(id)
+	}
+}
+
+// owns returns an error unless subscription id was made by the connection.
+func (c *connection) owns(id uint64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.permissions == nil {
+		return ErrUnauthenticated
+	}
+	if !c.subscriptions[id] {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// close cancels the subscriptions of the connection.
+func (c *connection) close() {
+	c.mutex.Lock()
+	ids := make([]uint64, 0, len(c.subscriptions))
+	for id := range c.subscriptions {
+		ids = append(ids, id)
+	}
+	c.subscriptions = nil
+	c.mutex.Unlock()
+
+	for _, id := range ids {
+		c.server.cancel(id)
+	}
+}
@@ -0,0 +1,142 @@
+package busrpc
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"testing"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// tenantAuth grants each tenant token its own namespace
+func tenantAuth(token string) (Permissions, error) {
+	switch token {
+	case "token-a":
+		return Permissions{Subscribe: []eventbus.EventType{"tenant-a:*"}, Publish: []eventbus.EventType{"tenant-a:*"}}, nil
+	case "reader":
+		return Permissions{Subscribe: []eventbus.EventType{"*"}}, nil
+	}
+	return Permissions{}, errors.New("unknown token")
+}
+
+// serve serves server on an in-memory connection and returns a client for it
+func serve(t *testing.T, server *Server) *Client {
+	serverConn, clientConn := net.Pipe()
+	go server.ServeConn(serverConn)
+	client := NewClient(rpc.NewClient(clientConn), nil)
+	t.Cleanup(func() {
+		client.Close()
+	})
+	return client
+}
+
+// isError reports whether err is the server error target
+func isError(err, target error) bool {
+	return err != nil && err.Error() == target.Error()
+}
+
+// TestAuthRequired verifies that calls fail until the connection authenticates
+func TestAuthRequired(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	server := NewServer(bus, nil, WithAuth(tenantAuth))
+	client := serve(t, server)
+
+	if err := client.Publish(eventbus.Of("tenant-a:order", 1)); !isError(err, ErrUnauthenticated) {
+		t.Errorf("Expected ErrUnauthenticated before authenticating, got %v", err)
+	}
+	if err := client.Authenticate("stolen"); !isError(err, ErrUnauthenticated) {
+		t.Errorf("Expected ErrUnauthenticated for an unknown token, got %v", err)
+	}
+	if err := client.Authenticate("token-a"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := client.Publish(eventbus.Of("tenant-a:order", 1)); err != nil {
+		t.Errorf("Expected no error publishing in the namespace, got %v", err)
+	}
+
+	if err := server.Publish(Message{Type: "tenant-a:order"}, &struct{}{}); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Expected ErrUnauthenticated calling the server directly, got %v", err)
+	}
+}
+
+// TestAuthPermissions verifies that connections only use the event types they are allowed
+func TestAuthPermissions(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	server := NewServer(bus, nil, WithAuth(tenantAuth))
+
+	tenant := serve(t, server)
+	if err := tenant.Authenticate("token-a"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	reader := serve(t, server)
+	if err := reader.Authenticate("reader"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	received := make(chan eventbus.Event, 1)
+	if _, err := tenant.Subscribe("tenant-a:order", func(event eventbus.Event) {
+		received <- event
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := tenant.Subscribe("tenant-b:order", func(event eventbus.Event) {}); !isError(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden subscribing to another tenant, got %v", err)
+	}
+	if err := tenant.Publish(eventbus.Of("tenant-b:order", 1)); !isError(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden publishing to another tenant, got %v", err)
+	}
+	if err := reader.Publish(eventbus.Of("tenant-a:order", 1)); !isError(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden publishing without publish permissions, got %v", err)
+	}
+
+	// The tenant's subscription is 1; other connections cannot read it.
+	if err := reader.conn().Call(ServiceName+".Next", uint64(1), &Message{}); !isError(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden reading another connection's subscription, got %v", err)
+	}
+
+	bus.Publish(eventbus.Of("tenant-a:order", 7))
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the tenant to receive its event")
+	}
+}
+
+// TestServeConnCleanup verifies that subscriptions are cancelled when their connection closes
+func TestServeConnCleanup(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	server := NewServer(bus, nil)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer listener.Close()
+	go server.Serve(listener)
+
+	conn, err := rpc.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	client := NewClient(conn, nil)
+	if _, err := client.Subscribe("player:jumped", func(event eventbus.Event) {}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count := len(bus.Snapshot().Subscriptions()); count != 1 {
+		t.Fatalf("Expected 1 subscription, got %d", count)
+	}
+
+	client.Close()
+	deadline := time.Now().Add(time.Second)
+	for len(bus.Snapshot().Subscriptions()) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if count := len(bus.Snapshot().Subscriptions()); count != 0 {
+		t.Errorf("Expected the subscription cancelled with its connection, got %d", count)
+	}
+}
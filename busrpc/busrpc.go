@@ -0,0 +1,708 @@
+// Package busrpc exposes an event bus to other processes over net/rpc, so
+// out-of-process plugins can subscribe to and publish events without being
+// compiled into the main binary.
+//
+// The Server is registered on the process owning the bus; plugins talk to it
+// through a Client. Both sides exchange events encoded with an
+// eventbus.CodecRegistry, JSON by default.
+//
+// The package depends only on the standard library. It fits the net/rpc
+// protocol of HashiCorp's go-plugin: serve the Server on a MuxBroker
+// connection from the host and dial it with a Client from the plugin:
+//
+//	// Host
+//	id := broker.NextId()
+//	go broker.AcceptAndServe(id, busrpc.NewServer(bus, nil))
+//
+//	// Plugin
+//	conn, _ := broker.Dial(id)
+//	bus := busrpc.NewClient(rpc.NewClient(conn), nil)
+//	bus.Subscribe("player:jumped", func(event eventbus.Event) { ... })
+//
+// Example without go-plugin:
+//
+//	server := rpc.NewServer()
+//	server.RegisterName(busrpc.ServiceName, busrpc.NewServer(bus, nil))
+//	go server.Accept(listener)
+//
+//	client, _ := rpc.Dial("tcp", address)
+//	remote := busrpc.NewClient(client, nil)
+//	remote.Publish(eventbus.Of("mod:loaded", ModLoaded{Name: "weather"}))
+package busrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/rpc"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// ServiceName is the name under which Client expects the Server to be
+// registered.
+const ServiceName = "EventBus"
+
+// ErrClosed is returned for calls on a cancelled subscription or a closed
+// Server or Client.
+var ErrClosed = errors.New("busrpc: closed")
+
+// ErrNoStore is returned by Resume on a server without a store.
+var ErrNoStore = errors.New("busrpc: server has no store")
+
+// Message carries an encoded event.
+type Message struct {
+	Type eventbus.EventType
+	Data []byte
+	// Sequence is the store sequence of the event, or 0 if the server has
+	// no store.
+	Sequence uint64
+}
+
+// ResumeRequest asks for the events of Type, starting with the stored
+// event at sequence From.
+type ResumeRequest struct {
+	Type eventbus.EventType
+	From uint64
+}
+
+// Server is the net/rpc receiver exposing a bus. Its exported methods are
+// the RPC protocol used by Client.
+type Server struct {
+	bus          eventbus.EventBus
+	codecs       *eventbus.CodecRegistry
+	bufferConfig eventbus.BufferConfig
+	store        eventbus.EventStore
+	authenticate func(token string) (Permissions, error)
+
+	next          uint64
+	subscriptions map[uint64]*remoteSubscription
+	closed        bool
+	mutex         sync.Mutex
+}
+
+// remoteSubscription buffers the events of one subscription until the
+// client fetches them with Next.
+type remoteSubscription struct {
+	sub    *eventbus.Subscription
+	buffer *eventbus.SendBuffer
+	// events hands buffered events to Next.
+	events chan Message
+	// ready is closed once the stored events have been replayed. Live
+	// events up to sequence after were part of the replay and are skipped.
+	ready chan struct{}
+	after uint64
+	done  chan struct{}
+}
+
+// sequenced is a live event waiting in the buffer with its store sequence.
+type sequenced struct {
+	eventbus.Event
+	sequence uint64
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithBuffer configures the buffer of each subscription. By default up to
+// 1024 events are buffered, after which publishers block until the client
+// catches up or cancels the subscription.
+func WithBuffer(config eventbus.BufferConfig) ServerOption {
+	return func(s *Server) {
+		s.bufferConfig = config
+	}
+}
+
+// WithStore lets clients resume subscriptions from the store the bus
+// persists to with eventbus.WithStore. Events are then sent with their
+// store sequence, and a reconnecting Client resumes after the last event
+// its listener handled, so events published during an outage are not lost.
+func WithStore(store eventbus.EventStore) ServerOption {
+	return func(s *Server) {
+		s.store = store
+	}
+}
+
+// NewServer creates a server for bus, encoding events with codecs, or with
+// eventbus.JSONCodec if codecs is nil.
+//
+// Events are buffered for each subscription until the client fetches them.
+// If a client falls behind, an eventbus.BridgeLagging event is published.
+func NewServer(bus eventbus.EventBus, codecs *eventbus.CodecRegistry, opts ...ServerOption) *Server {
+	if codecs == nil {
+		codecs = eventbus.NewCodecRegistry(nil)
+	}
+	s := &Server{
+		bus:           bus,
+		codecs:        codecs,
+		subscriptions: make(map[uint64]*remoteSubscription),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Publish decodes message and publishes the event on the bus.
+func (s *Server) Publish(message Message, reply *struct{}) error {
+	if s.authenticate != nil {
+		return ErrUnauthenticated
+	}
+	return s.publish(message)
+}
+
+// publish decodes message and publishes the event on the bus.
+func (s *Server) publish(message Message) error {
+	event, err := s.codecs.Decode(message.Type, message.Data)
+	if err != nil {
+		return err
+	}
+	s.bus.Publish(event)
+	return nil
+}
+
+// Subscribe subscribes to eventType and sets id to the subscription ID
+// passed to Next and Cancel.
+func (s *Server) Subscribe(eventType eventbus.EventType, id *uint64) error {
+	if s.authenticate != nil {
+		return ErrUnauthenticated
+	}
+	return s.subscribe(eventType, id)
+}
+
+// subscribe subscribes to eventType and sets id to the subscription ID.
+func (s *Server) subscribe(eventType eventbus.EventType, id *uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	remote, err := s.register(eventType, id)
+	if err != nil {
+		return err
+	}
+	close(remote.ready)
+	return nil
+}
+
+// Resume subscribes to request.Type like Subscribe, but first replays the
+// stored events of that type from sequence request.From. Live events are
+// buffered during the replay and delivered after it without duplicates.
+// It returns ErrNoStore unless the server was created with WithStore. If
+// reading the store fails, the subscription is cancelled.
+func (s *Server) Resume(request ResumeRequest, id *uint64) error {
+	if s.authenticate != nil {
+		return ErrUnauthenticated
+	}
+	return s.resume(request, id)
+}
+
+// resume subscribes to request.Type after replaying the stored events.
+func (s *Server) resume(request ResumeRequest, id *uint64) error {
+	if s.store == nil {
+		return ErrNoStore
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	remote, err := s.register(request.Type, id)
+	if err != nil {
+		return err
+	}
+	go func(id uint64) {
+		if err := remote.replay(s.store, s.codecs, request); err != nil {
+			s.cancel(id)
+		}
+	}(*id)
+	return nil
+}
+
+// register registers a subscription to eventType and sets id to its ID.
+// Events are held back until the caller closes the subscription's ready
+// channel. The caller must hold s.mutex.
+func (s *Server) register(eventType eventbus.EventType, id *uint64) (*remoteSubscription, error) {
+	if s.closed {
+		return nil, ErrClosed
+	}
+
+	s.next++
+	remote := &remoteSubscription{
+		events: make(chan Message),
+		ready:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	name := fmt.Sprintf("busrpc subscription %d to %s", s.next, eventType)
+	remote.buffer = eventbus.NewSendBuffer(s.bus, name, s.bufferConfig, func(event eventbus.Event) {
+		select {
+		case <-remote.ready:
+		case <-remote.done:
+			return
+		}
+		live := event.(sequenced)
+		if live.sequence != 0 && live.sequence <= remote.after {
+			return
+		}
+		remote.send(s.codecs, live.Event, live.sequence)
+	})
+	remote.sub = s.bus.SubscribeContext(eventType, func(ctx context.Context, event eventbus.Event) {
+		sequence, _ := eventbus.SequenceFromContext(ctx)
+		remote.buffer.Push(context.Background(), sequenced{Event: event, sequence: sequence})
+	})
+
+	s.subscriptions[s.next] = remote
+	*id = s.next
+	return remote, nil
+}
+
+// Next waits for the next event of subscription id. It returns ErrClosed
+// once the subscription is cancelled.
+func (s *Server) Next(id uint64, message *Message) error {
+	if s.authenticate != nil {
+		return ErrUnauthenticated
+	}
+	return s.receive(id, message)
+}
+
+// receive waits for the next event of subscription id.
+func (s *Server) receive(id uint64, message *Message) error {
+	s.mutex.Lock()
+	remote, ok := s.subscriptions[id]
+	s.mutex.Unlock()
+	if !ok {
+		return ErrClosed
+	}
+
+	select {
+	case *message = <-remote.events:
+		return nil
+	case <-remote.done:
+		return ErrClosed
+	}
+}
+
+// Cancel cancels subscription id.
+func (s *Server) Cancel(id uint64, reply *struct{}) error {
+	if s.authenticate != nil {
+		return ErrUnauthenticated
+	}
+	s.cancel(id)
+	return nil
+}
+
+// cancel cancels subscription id, if it exists.
+func (s *Server) cancel(id uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if remote, ok := s.subscriptions[id]; ok {
+		remote.cancel()
+		delete(s.subscriptions, id)
+	}
+}
+
+// Close cancels every subscription, for example when the plugin process
+// exits. Later calls to Subscribe fail with ErrClosed.
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	for id, remote := range s.subscriptions {
+		remote.cancel()
+		delete(s.subscriptions, id)
+	}
+	return nil
+}
+
+// replay sends the stored events requested, then releases the live events.
+func (remote *remoteSubscription) replay(store eventbus.EventStore, codecs *eventbus.CodecRegistry, request ResumeRequest) error {
+	err := store.Read(request.From, func(envelope eventbus.Envelope) error {
+		if envelope.Event.GetType() != request.Type {
+			return nil
+		}
+		if !remote.send(codecs, envelope.Event, envelope.Sequence) {
+			return ErrClosed
+		}
+		remote.after = envelope.Sequence
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	close(remote.ready)
+	return nil
+}
+
+// send encodes event and hands it to Next. It reports false if the
+// subscription was cancelled first. Events that cannot be encoded are
+// skipped.
+func (remote *remoteSubscription) send(codecs *eventbus.CodecRegistry, event eventbus.Event, sequence uint64) bool {
+	data, err := codecs.Encode(event)
+	if err != nil {
+		return true
+	}
+	select {
+	case remote.events <- Message{Type: event.GetType(), Data: data, Sequence: sequence}:
+		return true
+	case <-remote.done:
+		return false
+	}
+}
+
+// cancel cancels the bus subscription, wakes the waiting calls, and
+// discards the buffered events.
+func (remote *remoteSubscription) cancel() {
+	remote.sub.Cancel()
+	close(remote.done)
+	remote.buffer.Close()
+}
+
+// Backoff configures the delays between reconnection attempts. Zero fields
+// use the defaults noted.
+type Backoff struct {
+	// Initial is the delay before the first attempt. Default 100ms.
+	Initial time.Duration
+	// Max caps the delay. Default 30s.
+	Max time.Duration
+	// Multiplier grows the delay after each failed attempt. Default 2.
+	Multiplier float64
+}
+
+// delay returns the delay before the given attempt, counting from 0.
+func (b Backoff) delay(attempt int) time.Duration {
+	initial, limit, multiplier := b.Initial, b.Max, b.Multiplier
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if limit <= 0 {
+		limit = 30 * time.Second
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	delay := float64(initial) * math.Pow(multiplier, float64(attempt))
+	return time.Duration(min(delay, float64(limit)))
+}
+
+// Client is a plugin's view of a bus exposed by a Server.
+type Client struct {
+	codecs  *eventbus.CodecRegistry
+	dial    func() (*rpc.Client, error)
+	backoff Backoff
+	token   string
+
+	client    *rpc.Client
+	mutex     sync.Mutex
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// ClientOption configures a Client created with Dial.
+type ClientOption func(*Client)
+
+// WithBackoff sets the delays between reconnection attempts.
+func WithBackoff(backoff Backoff) ClientOption {
+	return func(c *Client) {
+		c.backoff = backoff
+	}
+}
+
+// WithToken authenticates every connection of the client with token, for
+// servers created with WithAuth.
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// NewClient creates a client calling a Server registered as ServiceName
+// through client. Events are encoded and decoded with codecs, or with
+// eventbus.JSONCodec if codecs is nil, in which case listeners receive
+// eventbus.RawEvent values.
+func NewClient(client *rpc.Client, codecs *eventbus.CodecRegistry) *Client {
+	if codecs == nil {
+		codecs = eventbus.NewCodecRegistry(nil)
+	}
+	return &Client{client: client, codecs: codecs, closed: make(chan struct{})}
+}
+
+// Dial creates a client like NewClient, connecting with dial. When the
+// connection is lost, the client dials again with exponential backoff
+// until it succeeds or is closed, and then resubscribes. Subscriptions to
+// a server created with WithStore resume after the last event their
+// listener handled; other subscriptions miss the events published while
+// the connection was down.
+//
+// Calls in flight when the connection is lost are retried, so a publish
+// may reach the remote bus twice.
+//
+// Example:
+//
+//	remote, err := busrpc.Dial(func() (*rpc.Client, error) {
+//	    conn, err := tls.Dial("tcp", address, tlsConfig)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return rpc.NewClient(conn), nil
+//	}, nil, busrpc.WithToken(token), busrpc.WithBackoff(busrpc.Backoff{Max: 5 * time.Second}))
+func Dial(dial func() (*rpc.Client, error), codecs *eventbus.CodecRegistry, opts ...ClientOption) (*Client, error) {
+	client, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	c := NewClient(client, codecs)
+	c.dial = dial
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.login(client); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Authenticate authenticates the connection with token, for servers
+// created with WithAuth. Clients created with Dial use WithToken instead,
+// so they authenticate again after reconnecting.
+func (c *Client) Authenticate(token string) error {
+	return c.conn().Call(ServiceName+".Authenticate", token, &struct{}{})
+}
+
+// login authenticates client with the configured token, if any.
+func (c *Client) login(client *rpc.Client) error {
+	if c.token == "" {
+		return nil
+	}
+	return client.Call(ServiceName+".Authenticate", c.token, &struct{}{})
+}
+
+// Publish publishes event on the remote bus.
+func (c *Client) Publish(event eventbus.Event) error {
+	data, err := c.codecs.Encode(event)
+	if err != nil {
+		return err
+	}
+	return c.call(ServiceName+".Publish", Message{Type: event.GetType(), Data: data}, &struct{}{})
+}
+
+// Subscribe registers listener for events of eventType on the remote bus.
+// The listener is called on a goroutine of the client, one event at a time.
+func (c *Client) Subscribe(eventType eventbus.EventType, listener eventbus.EventListener) (*Subscription, error) {
+	var id uint64
+	if err := c.call(ServiceName+".Subscribe", eventType, &id); err != nil {
+		return nil, err
+	}
+	return c.start(eventType, id, 0, listener), nil
+}
+
+// Resume is like Subscribe, but the listener first receives the stored
+// events of eventType from sequence from, for example one past the
+// Sequence of a subscription saved before the plugin restarted. The server
+// must have been created with WithStore.
+func (c *Client) Resume(eventType eventbus.EventType, from uint64, listener eventbus.EventListener) (*Subscription, error) {
+	var id uint64
+	if err := c.call(ServiceName+".Resume", ResumeRequest{Type: eventType, From: from}, &id); err != nil {
+		return nil, err
+	}
+	return c.start(eventType, id, from, listener), nil
+}
+
+// start begins receiving the events of subscription id.
+func (c *Client) start(eventType eventbus.EventType, id, from uint64, listener eventbus.EventListener) *Subscription {
+	sub := &Subscription{client: c, eventType: eventType, id: id, from: from, done: make(chan struct{})}
+	go sub.receive(listener)
+	return sub
+}
+
+// Close closes the underlying RPC client and stops reconnecting.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	return c.conn().Close()
+}
+
+// conn returns the current RPC client.
+func (c *Client) conn() *rpc.Client {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.client
+}
+
+// call calls method, reconnecting and retrying if the connection is lost.
+func (c *Client) call(method string, args, reply any) error {
+	for {
+		conn := c.conn()
+		err := conn.Call(method, args, reply)
+		if err == nil || c.dial == nil || !disconnected(err) {
+			return err
+		}
+		if err := c.reconnect(conn); err != nil {
+			return err
+		}
+	}
+}
+
+// reconnect replaces the failed RPC client with a new connection, dialing
+// with backoff until it succeeds or the client is closed. If another
+// caller has already replaced it, reconnect returns at once.
+func (c *Client) reconnect(failed *rpc.Client) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.client != failed {
+		return nil
+	}
+	failed.Close()
+
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-c.closed:
+			return ErrClosed
+		case <-time.After(c.backoff.delay(attempt)):
+		}
+
+		client, err := c.dial()
+		if err != nil {
+			continue
+		}
+		if err := c.login(client); err != nil {
+			client.Close()
+			continue
+		}
+		select {
+		case <-c.closed:
+			client.Close()
+			return ErrClosed
+		default:
+		}
+		c.client = client
+		return nil
+	}
+}
+
+// disconnected reports whether err means the connection was lost, as
+// opposed to an error returned by the server.
+func disconnected(err error) bool {
+	var serverError rpc.ServerError
+	return !errors.As(err, &serverError)
+}
+
+// Subscription is a subscription made through a Client.
+type Subscription struct {
+	client    *Client
+	eventType eventbus.EventType
+	// from is the sequence the subscription was resumed from, if any.
+	from uint64
+	// last is the sequence of the last event the listener handled.
+	last atomic.Uint64
+
+	id        uint64
+	cancelled bool
+	mutex     sync.Mutex
+	done      chan struct{}
+	once      sync.Once
+}
+
+// Cancel cancels the subscription on the remote bus. Cancel is idempotent.
+func (s *Subscription) Cancel() error {
+	var err error
+	s.once.Do(func() {
+		s.mutex.Lock()
+		s.cancelled = true
+		id := s.id
+		s.mutex.Unlock()
+
+		err = s.client.conn().Call(ServiceName+".Cancel", id, &struct{}{})
+	})
+	return err
+}
+
+// Done returns a channel that is closed once the subscription stops
+// receiving events, because it was cancelled or the connection closed.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Sequence returns the store sequence of the last event the listener
+// handled, or 0 if the server has no store or no event was handled yet.
+func (s *Subscription) Sequence() uint64 {
+	return s.last.Load()
+}
+
+// receive fetches events and calls listener until the subscription ends,
+// resubscribing when a client created with Dial reconnects. Events that
+// cannot be decoded are skipped.
+func (s *Subscription) receive(listener eventbus.EventListener) {
+	defer close(s.done)
+
+	for {
+		s.mutex.Lock()
+		id, cancelled := s.id, s.cancelled
+		s.mutex.Unlock()
+		if cancelled {
+			return
+		}
+
+		conn := s.client.conn()
+		var message Message
+		if err := conn.Call(ServiceName+".Next", id, &message); err != nil {
+			if s.client.dial == nil || !disconnected(err) {
+				return
+			}
+			if err := s.client.reconnect(conn); err != nil {
+				return
+			}
+			if err := s.resubscribe(id); err != nil {
+				return
+			}
+			continue
+		}
+
+		event, err := s.client.codecs.Decode(message.Type, message.Data)
+		if err == nil {
+			listener(event)
+		}
+		if message.Sequence != 0 {
+			s.last.Store(message.Sequence)
+		}
+	}
+}
+
+// resubscribe replaces subscription previous after a reconnection,
+// resuming after the last handled event if its sequence is known.
+func (s *Subscription) resubscribe(previous uint64) error {
+	// The server keeps the previous subscription until it is cancelled.
+	s.client.call(ServiceName+".Cancel", previous, &struct{}{})
+
+	from := s.from
+	if last := s.last.Load(); last != 0 {
+		from = last + 1
+	}
+
+	var id uint64
+	var err error
+	if from != 0 {
+		err = s.client.call(ServiceName+".Resume", ResumeRequest{Type: s.eventType, From: from}, &id)
+	} else {
+		err = s.client.call(ServiceName+".Subscribe", s.eventType, &id)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.id = id
+	cancelled := s.cancelled
+	s.mutex.Unlock()
+	if cancelled {
+		// Cancel ran during the reconnection with the previous ID.
+		s.client.conn().Call(ServiceName+".Cancel", id, &struct{}{})
+		return ErrClosed
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package busrpc
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// connect serves bus over an in-memory connection and returns a client for it
+func connect(t *testing.T, bus eventbus.EventBus) (*Server, *Client) {
+	server := NewServer(bus, nil)
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName(ServiceName, server); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	serverConn, clientConn := net.Pipe()
+	go rpcServer.ServeConn(serverConn)
+	client := NewClient(rpc.NewClient(clientConn), nil)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return server, client
+}
+
+// TestClientPublish verifies that events published by a client reach the bus
+func TestClientPublish(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	_, client := connect(t, bus)
+
+	received := make(chan eventbus.Event, 1)
+	bus.Subscribe("mod:loaded", func(event eventbus.Event) {
+		received <- event
+	})
+
+	if err := client.Publish(eventbus.Of("mod:loaded", map[string]string{"name": "weather"})); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	select {
+	case event := <-received:
+		payload, ok := eventbus.Payload[map[string]string](event)
+		if !ok || payload["name"] != "weather" {
+			t.Errorf("Expected name weather, got %#v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the event to be published")
+	}
+}
+
+// TestClientSubscribe verifies that a client receives bus events until it cancels
+func TestClientSubscribe(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	_, client := connect(t, bus)
+
+	received := make(chan eventbus.Event, 10)
+	sub, err := client.Subscribe("player:jumped", func(event eventbus.Event) {
+		received <- event
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	bus.Publish(eventbus.Of("player:jumped", map[string]int{"height": 2}))
+
+	select {
+	case event := <-received:
+		payload, ok := eventbus.Payload[map[string]int](event)
+		if !ok || payload["height"] != 2 {
+			t.Errorf("Expected height 2, got %#v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the client to receive the event")
+	}
+
+	if err := sub.Cancel(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	select {
+	case <-sub.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the subscription to end after Cancel")
+	}
+	if count := len(bus.Snapshot().Subscriptions()); count != 0 {
+		t.Errorf("Expected the bus subscription to be cancelled, got %d", count)
+	}
+}
+
+// TestServerClose verifies that closing the server ends client subscriptions
+func TestServerClose(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	server, client := connect(t, bus)
+
+	sub, err := client.Subscribe("player:jumped", func(event eventbus.Event) {})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	server.Close()
+
+	select {
+	case <-sub.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the subscription to end after the server closed")
+	}
+	if _, err := client.Subscribe("player:jumped", func(event eventbus.Event) {}); err == nil {
+		t.Error("Expected an error subscribing on a closed server")
+	}
+}
+
+// flakyNetwork serves a server over in-memory connections that can be cut
+type flakyNetwork struct {
+	server *rpc.Server
+	mutex  sync.Mutex
+	conn   net.Conn
+	down   bool
+	dials  int
+}
+
+// dial connects a new client unless the network is down
+func (n *flakyNetwork) dial() (*rpc.Client, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.dials++
+	if n.down {
+		return nil, errors.New("connection refused")
+	}
+	serverConn, clientConn := net.Pipe()
+	go n.server.ServeConn(serverConn)
+	n.conn = clientConn
+	return rpc.NewClient(clientConn), nil
+}
+
+// cut closes the current connection and refuses new ones until restore
+func (n *flakyNetwork) cut() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.down = true
+	n.conn.Close()
+}
+
+func (n *flakyNetwork) restore() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.down = false
+}
+
+// receiveNames waits for count events and returns their names
+func receiveNames(t *testing.T, received <-chan eventbus.Event, count int) []string {
+	t.Helper()
+	var names []string
+	for len(names) < count {
+		select {
+		case event := <-received:
+			payload, _ := eventbus.Payload[map[string]string](event)
+			names = append(names, payload["name"])
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected %d events, got %v", count, names)
+		}
+	}
+	return names
+}
+
+// TestDialReconnectResume verifies that a dialed client reconnects and receives the events published during an outage
+func TestDialReconnectResume(t *testing.T) {
+	store := eventbus.NewMemoryStore()
+	bus := eventbus.New(eventbus.WithStore(store))
+	defer bus.Close()
+
+	server := NewServer(bus, nil, WithStore(store))
+	network := &flakyNetwork{server: rpc.NewServer()}
+	network.server.RegisterName(ServiceName, server)
+
+	client, err := Dial(network.dial, nil, WithBackoff(Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer client.Close()
+
+	received := make(chan eventbus.Event, 10)
+	sub, err := client.Subscribe("mod:loaded", func(event eventbus.Event) {
+		received <- event
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	bus.Publish(eventbus.Of("mod:loaded", map[string]string{"name": "one"}))
+	receiveNames(t, received, 1)
+
+	network.cut()
+	bus.Publish(eventbus.Of("mod:loaded", map[string]string{"name": "two"}))
+	bus.Publish(eventbus.Of("player:jumped", map[string]string{"name": "skipped"}))
+	bus.Publish(eventbus.Of("mod:loaded", map[string]string{"name": "three"}))
+	time.Sleep(20 * time.Millisecond)
+	network.restore()
+
+	names := receiveNames(t, received, 2)
+	if names[0] != "two" || names[1] != "three" {
+		t.Errorf("Expected [two three] after reconnecting, got %v", names)
+	}
+
+	bus.Publish(eventbus.Of("mod:loaded", map[string]string{"name": "four"}))
+	if names := receiveNames(t, received, 1); names[0] != "four" {
+		t.Errorf("Expected four after resuming, got %v", names)
+	}
+	select {
+	case event := <-received:
+		t.Errorf("Expected no duplicates, got %#v", event)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if sub.Sequence() != 5 {
+		t.Errorf("Expected sequence 5, got %d", sub.Sequence())
+	}
+	if network.dials < 3 {
+		t.Errorf("Expected failed dials during the outage, got %d dials", network.dials)
+	}
+	if count := len(bus.Snapshot().Subscriptions()); count != 1 {
+		t.Errorf("Expected the previous subscription to be cancelled, got %d", count)
+	}
+}
+
+// TestClientResume verifies that Resume replays stored events before live ones
+func TestClientResume(t *testing.T) {
+	store := eventbus.NewMemoryStore()
+	bus := eventbus.New(eventbus.WithStore(store))
+	defer bus.Close()
+
+	bus.Publish(eventbus.Of("mod:loaded", map[string]string{"name": "one"}))
+	bus.Publish(eventbus.Of("mod:loaded", map[string]string{"name": "two"}))
+
+	server := NewServer(bus, nil, WithStore(store))
+	rpcServer := rpc.NewServer()
+	rpcServer.RegisterName(ServiceName, server)
+	serverConn, clientConn := net.Pipe()
+	go rpcServer.ServeConn(serverConn)
+	client := NewClient(rpc.NewClient(clientConn), nil)
+	defer client.Close()
+
+	received := make(chan eventbus.Event, 10)
+	if _, err := client.Resume("mod:loaded", 2, func(event eventbus.Event) {
+		received <- event
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Publish(eventbus.Of("mod:loaded", map[string]string{"name": "three"}))
+
+	names := receiveNames(t, received, 2)
+	if names[0] != "two" || names[1] != "three" {
+		t.Errorf("Expected [two three], got %v", names)
+	}
+
+	_, plain := connect(t, bus)
+	if _, err := plain.Resume("mod:loaded", 1, func(event eventbus.Event) {}); err == nil || err.Error() != ErrNoStore.Error() {
+		t.Errorf("Expected ErrNoStore, got %v", err)
+	}
+}
+
+// TestBackoffDelay verifies that delays grow exponentially up to the maximum
+func TestBackoffDelay(t *testing.T) {
+	backoff := Backoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond}
+	expected := []time.Duration{10, 20, 40, 50, 50}
+	for attempt, delay := range expected {
+		if got := backoff.delay(attempt); got != delay*time.Millisecond {
+			t.Errorf("Expected delay %v for attempt %d, got %v", delay*time.Millisecond, attempt, got)
+		}
+	}
+	if got := (Backoff{}).delay(0); got != 100*time.Millisecond {
+		t.Errorf("Expected default initial delay 100ms, got %v", got)
+	}
+}
@@ -0,0 +1,118 @@
+package eventbus
+
+import "sync"
+
+// CanaryArmStats holds delivery counters for one side of a Canary.
+type CanaryArmStats struct {
+	// Delivered is the number of events routed to this listener.
+	Delivered uint64
+	// Failed is the number of deliveries that panicked.
+	Failed uint64
+}
+
+// ErrorRate returns the fraction of deliveries that failed, or 0 if
+// nothing has been delivered yet.
+func (s CanaryArmStats) ErrorRate() float64 {
+	if s.Delivered == 0 {
+		return 0
+	}
+	return float64(s.Failed) / float64(s.Delivered)
+}
+
+// CanaryStats compares the stable and canary listeners of a Canary.
+type CanaryStats struct {
+	Stable CanaryArmStats
+	Canary CanaryArmStats
+}
+
+// Canary routes a configurable percentage of a topic's events to a canary
+// listener while the rest go to the stable listener. It is used to roll out
+// a rewritten subscriber gradually and compare its error rate against the
+// implementation it replaces.
+//
+// The split is deterministic: with a percentage of 10, exactly one event in
+// every ten is routed to the canary. A listener that panics is counted as a
+// failure and the panic is propagated to the publisher unchanged.
+//
+// Example:
+//
+//	canary := eventbus.NewCanary(oldHandler, newHandler, 10)
+//	bus.Subscribe("player:jumped", canary.Handle)
+//
+//	// Later, once the canary looks healthy
+//	canary.SetPercent(50)
+type Canary struct {
+	stable  EventListener
+	canary  EventListener
+	mutex   sync.Mutex
+	percent float64
+	routed  uint64
+	picked  uint64
+	stats   CanaryStats
+}
+
+// NewCanary creates a Canary sending percent (0-100) of events to canary and
+// the remainder to stable.
+func NewCanary(stable, canary EventListener, percent float64) *Canary {
+	c := &Canary{stable: stable, canary: canary}
+	c.SetPercent(percent)
+	return c
+}
+
+// SetPercent changes the share of events routed to the canary listener.
+// Values outside 0-100 are clamped.
+func (c *Canary) SetPercent(percent float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.percent = min(max(percent, 0), 100)
+	c.routed = 0
+	c.picked = 0
+}
+
+// Stats returns a snapshot of the delivery counters for both listeners.
+func (c *Canary) Stats() CanaryStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.stats
+}
+
+// Handle is an EventListener that forwards the event to either the stable or
+// the canary listener. Pass it to Subscribe.
+func (c *Canary) Handle(event Event) {
+	useCanary := c.pick()
+
+	listener, arm := c.stable, &c.stats.Stable
+	if useCanary {
+		listener, arm = c.canary, &c.stats.Canary
+	}
+
+	failed := true
+	defer func() {
+		c.mutex.Lock()
+		arm.Delivered++
+		if failed {
+			arm.Failed++
+		}
+		c.mutex.Unlock()
+	}()
+
+	listener(event)
+	failed = false
+}
+
+// pick decides whether the next event goes to the canary listener.
+// It keeps the running share of canary deliveries as close as possible
+// to the configured percentage.
+func (c *Canary) pick() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.routed++
+	if float64(c.picked+1)*100 <= float64(c.routed)*c.percent {
+		c.picked++
+		return true
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package eventbus
+
+import "testing"
+
+// TestCanarySplit verifies that the configured percentage of events reaches the canary
+func TestCanarySplit(t *testing.T) {
+	bus := New()
+	stableCount := 0
+	canaryCount := 0
+
+	canary := NewCanary(
+		func(event Event) { stableCount++ },
+		func(event Event) { canaryCount++ },
+		10,
+	)
+	bus.Subscribe("canary:test", canary.Handle)
+
+	for i := 0; i < 100; i++ {
+		bus.Publish(testEvent{eventType: "canary:test", data: "test"})
+	}
+
+	if canaryCount != 10 {
+		t.Errorf("Expected 10 canary deliveries, got %d", canaryCount)
+	}
+	if stableCount != 90 {
+		t.Errorf("Expected 90 stable deliveries, got %d", stableCount)
+	}
+
+	stats := canary.Stats()
+	if stats.Canary.Delivered != 10 || stats.Stable.Delivered != 90 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+// TestCanaryErrorRate verifies that panicking deliveries are counted as failures
+func TestCanaryErrorRate(t *testing.T) {
+	canary := NewCanary(
+		func(event Event) {},
+		func(event Event) { panic("broken rewrite") },
+		50,
+	)
+
+	for i := 0; i < 4; i++ {
+		func() {
+			defer func() { recover() }()
+			canary.Handle(testEvent{eventType: "canary:test"})
+		}()
+	}
+
+	stats := canary.Stats()
+	if stats.Canary.ErrorRate() != 1 {
+		t.Errorf("Expected canary error rate 1, got %v", stats.Canary.ErrorRate())
+	}
+	if stats.Stable.ErrorRate() != 0 {
+		t.Errorf("Expected stable error rate 0, got %v", stats.Stable.ErrorRate())
+	}
+	if stats.Canary.Delivered != 2 {
+		t.Errorf("Expected 2 canary deliveries, got %d", stats.Canary.Delivered)
+	}
+}
+
+// TestCanarySetPercent verifies that the split can be changed and is clamped
+func TestCanarySetPercent(t *testing.T) {
+	canaryCount := 0
+	canary := NewCanary(func(event Event) {}, func(event Event) { canaryCount++ }, 0)
+
+	canary.Handle(testEvent{eventType: "canary:test"})
+	if canaryCount != 0 {
+		t.Fatalf("Expected no canary deliveries at 0%%, got %d", canaryCount)
+	}
+
+	canary.SetPercent(250)
+	for i := 0; i < 3; i++ {
+		canary.Handle(testEvent{eventType: "canary:test"})
+	}
+	if canaryCount != 3 {
+		t.Errorf("Expected all events at clamped 100%%, got %d", canaryCount)
+	}
+}
@@ -0,0 +1,328 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CloudEventsVersion is the CloudEvents specification version produced and
+// accepted by this package.
+const CloudEventsVersion = "1.0"
+
+// cloudEventsMediaType is the content type of structured-mode CloudEvents.
+const cloudEventsMediaType = "application/cloudevents+json"
+
+// correlationExtension is the CloudEvents extension attribute carrying the
+// envelope's correlation ID.
+const correlationExtension = "correlationid"
+
+// CloudEvent is a CloudEvents v1.0 envelope, used to exchange events with
+// CloudEvents-compatible systems such as Knative or Amazon EventBridge.
+//
+// It encodes to the JSON event format (structured mode) with
+// encoding/json, and to HTTP binary mode with HTTPRequest.
+type CloudEvent struct {
+	// ID identifies the event; ID and Source together must be unique.
+	ID string
+	// Source identifies the context in which the event happened,
+	// for example "/game-server/eu-1".
+	Source string
+	// Type is the event type.
+	Type EventType
+	// Time is when the event happened. It is omitted when zero.
+	Time time.Time
+	// DataContentType is the media type of Data. Defaults to
+	// application/json when Data is set.
+	DataContentType string
+	// Data is the encoded event payload.
+	Data []byte
+	// Extensions holds extension attributes. The envelope's correlation ID
+	// is carried as the "correlationid" extension. Names of the attributes
+	// above, such as "id" or "data", are rejected.
+	Extensions map[string]string
+}
+
+// reservedAttributes are the attribute names encoded from the fields of
+// CloudEvent, which extensions must not use.
+var reservedAttributes = map[string]bool{
+	"specversion":     true,
+	"id":              true,
+	"source":          true,
+	"type":            true,
+	"time":            true,
+	"datacontenttype": true,
+	"data":            true,
+	"data_base64":     true,
+}
+
+// NewCloudEvent converts a recorded envelope into a CloudEvent. The envelope
+// sequence becomes the ID, or the bus sequence for envelopes of a bus
+// without a store, and the event is encoded as JSON data.
+//
+// Example:
+//
+//	for envelope, err := range eventbus.Find(store, eventbus.Query{}) {
+//	    ce, _ := eventbus.NewCloudEvent(envelope, "/game-server")
+//	    line, _ := json.Marshal(ce)
+//	}
+func NewCloudEvent(envelope Envelope, source string) (CloudEvent, error) {
+	data, err := json.Marshal(envelope.Event)
+	if err != nil {
+		return CloudEvent{}, fmt.Errorf("eventbus: encoding event %d: %w", envelope.Sequence, err)
+	}
+
+	id := envelope.Sequence
+	if id == 0 {
+		id = envelope.BusSequence
+	}
+	ce := CloudEvent{
+		ID:              strconv.FormatUint(id, 10),
+		Source:          source,
+		Type:            envelope.Event.GetType(),
+		Time:            envelope.Time,
+		DataContentType: "application/json",
+		Data:            data,
+	}
+	if envelope.CorrelationID != "" {
+		ce.Extensions = map[string]string{correlationExtension: envelope.CorrelationID}
+	}
+	return ce, nil
+}
+
+// Envelope converts the CloudEvent into an envelope holding a RawEvent.
+// The sequence is taken from a numeric ID and is zero otherwise.
+func (ce CloudEvent) Envelope() Envelope {
+	sequence, _ := strconv.ParseUint(ce.ID, 10, 64)
+	return Envelope{
+		Sequence:      sequence,
+		Time:          ce.Time,
+		OriginTime:    ce.Time,
+		CorrelationID: ce.Extensions[correlationExtension],
+		Event:         RawEvent{Type: ce.Type, Payload: ce.Data},
+	}
+}
+
+// Event returns the payload as a RawEvent, ready to be published on a bus.
+func (ce CloudEvent) Event() RawEvent {
+	return RawEvent{Type: ce.Type, Payload: ce.Data}
+}
+
+// validate checks the required context attributes.
+func (ce CloudEvent) validate() error {
+	switch {
+	case ce.ID == "":
+		return errors.New("eventbus: cloudevent is missing id")
+	case ce.Source == "":
+		return errors.New("eventbus: cloudevent is missing source")
+	case ce.Type == "":
+		return errors.New("eventbus: cloudevent is missing type")
+	}
+	for name := range ce.Extensions {
+		if reservedAttributes[name] {
+			return fmt.Errorf("eventbus: cloudevent extension %q is a reserved attribute", name)
+		}
+	}
+	return nil
+}
+
+// contentType returns the data content type, defaulting to JSON.
+func (ce CloudEvent) contentType() string {
+	if ce.DataContentType == "" {
+		return "application/json"
+	}
+	return ce.DataContentType
+}
+
+// isJSON reports whether the data content type is a JSON media type.
+func (ce CloudEvent) isJSON() bool {
+	mediaType, _, _ := mime.ParseMediaType(ce.contentType())
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// MarshalJSON encodes the CloudEvent in the JSON event format. JSON data is
+// embedded as is; other data, including data that is not valid JSON
+// despite a JSON content type, is base64 encoded in data_base64.
+func (ce CloudEvent) MarshalJSON() ([]byte, error) {
+	if err := ce.validate(); err != nil {
+		return nil, err
+	}
+
+	attributes := map[string]any{
+		"specversion": CloudEventsVersion,
+		"id":          ce.ID,
+		"source":      ce.Source,
+		"type":        ce.Type,
+	}
+	for name, value := range ce.Extensions {
+		attributes[name] = value
+	}
+	if !ce.Time.IsZero() {
+		attributes["time"] = ce.Time.Format(time.RFC3339Nano)
+	}
+	if ce.Data != nil {
+		attributes["datacontenttype"] = ce.contentType()
+		if ce.isJSON() && json.Valid(ce.Data) {
+			attributes["data"] = json.RawMessage(ce.Data)
+		} else {
+			attributes["data_base64"] = base64.StdEncoding.EncodeToString(ce.Data)
+		}
+	}
+	return json.Marshal(attributes)
+}
+
+// UnmarshalJSON decodes a CloudEvent in the JSON event format.
+// Unknown attributes with string values are kept as extensions.
+func (ce *CloudEvent) UnmarshalJSON(data []byte) error {
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(data, &attributes); err != nil {
+		return err
+	}
+
+	var decoded CloudEvent
+	var specVersion string
+	for name, raw := range attributes {
+		var err error
+		switch name {
+		case "specversion":
+			err = json.Unmarshal(raw, &specVersion)
+		case "id":
+			err = json.Unmarshal(raw, &decoded.ID)
+		case "source":
+			err = json.Unmarshal(raw, &decoded.Source)
+		case "type":
+			err = json.Unmarshal(raw, &decoded.Type)
+		case "time":
+			err = json.Unmarshal(raw, &decoded.Time)
+		case "datacontenttype":
+			err = json.Unmarshal(raw, &decoded.DataContentType)
+		case "data":
+			if string(raw) != "null" {
+				decoded.Data = raw
+			}
+		case "data_base64":
+			var encoded string
+			if err = json.Unmarshal(raw, &encoded); err == nil {
+				decoded.Data, err = base64.StdEncoding.DecodeString(encoded)
+			}
+		default:
+			var value string
+			if json.Unmarshal(raw, &value) == nil {
+				if decoded.Extensions == nil {
+					decoded.Extensions = make(map[string]string)
+				}
+				decoded.Extensions[name] = value
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("eventbus: decoding cloudevent attribute %s: %w", name, err)
+		}
+	}
+
+	if specVersion != CloudEventsVersion {
+		return fmt.Errorf("eventbus: unsupported cloudevents specversion %q", specVersion)
+	}
+	if err := decoded.validate(); err != nil {
+		return err
+	}
+	*ce = decoded
+	return nil
+}
+
+// HTTPRequest creates a POST request delivering the CloudEvent to url in
+// HTTP binary mode: attributes travel as ce- headers and the data as the
+// request body.
+func (ce CloudEvent) HTTPRequest(ctx context.Context, url string) (*http.Request, error) {
+	if err := ce.validate(); err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(ce.Data))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("ce-specversion", CloudEventsVersion)
+	request.Header.Set("ce-id", ce.ID)
+	request.Header.Set("ce-source", ce.Source)
+	request.Header.Set("ce-type", string(ce.Type))
+	if !ce.Time.IsZero() {
+		request.Header.Set("ce-time", ce.Time.Format(time.RFC3339Nano))
+	}
+	for name, value := range ce.Extensions {
+		request.Header.Set("ce-"+name, value)
+	}
+	if ce.Data != nil {
+		request.Header.Set("Content-Type", ce.contentType())
+	}
+	return request, nil
+}
+
+// ReadCloudEvent decodes a CloudEvent from an HTTP request in either
+// structured or binary mode.
+//
+// Example:
+//
+//	http.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+//	    ce, err := eventbus.ReadCloudEvent(r)
+//	    if err != nil {
+//	        http.Error(w, err.Error(), http.StatusBadRequest)
+//	        return
+//	    }
+//	    bus.Publish(ce.Event())
+//	})
+func ReadCloudEvent(r *http.Request) (CloudEvent, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return CloudEvent{}, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == cloudEventsMediaType {
+		var ce CloudEvent
+		err := json.Unmarshal(body, &ce)
+		return ce, err
+	}
+
+	if version := r.Header.Get("ce-specversion"); version != CloudEventsVersion {
+		return CloudEvent{}, fmt.Errorf("eventbus: unsupported cloudevents specversion %q", version)
+	}
+
+	ce := CloudEvent{DataContentType: r.Header.Get("Content-Type")}
+	if len(body) > 0 {
+		ce.Data = body
+	}
+	for key, values := range r.Header {
+		name, ok := strings.CutPrefix(strings.ToLower(key), "ce-")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		switch name {
+		case "specversion":
+		case "id":
+			ce.ID = values[0]
+		case "source":
+			ce.Source = values[0]
+		case "type":
+			ce.Type = EventType(values[0])
+		case "time":
+			if ce.Time, err = time.Parse(time.RFC3339Nano, values[0]); err != nil {
+				return CloudEvent{}, fmt.Errorf("eventbus: decoding cloudevent time: %w", err)
+			}
+		default:
+			if ce.Extensions == nil {
+				ce.Extensions = make(map[string]string)
+			}
+			ce.Extensions[name] = values[0]
+		}
+	}
+	return ce, ce.validate()
+}
@@ -0,0 +1,205 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCloudEventRoundTrip verifies structured-mode encoding and decoding
+func TestCloudEventRoundTrip(t *testing.T) {
+	recorded := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ce, err := NewCloudEvent(Envelope{
+		Sequence:      7,
+		Time:          recorded,
+		CorrelationID: "match-1",
+		Event:         scoreEvent{Player: "alice", Points: 3},
+	}, "/game-server")
+	if err != nil {
+		t.Fatalf("NewCloudEvent failed: %v", err)
+	}
+
+	line, err := json.Marshal(ce)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var attributes map[string]any
+	json.Unmarshal(line, &attributes)
+	for name, want := range map[string]any{
+		"specversion":     "1.0",
+		"id":              "7",
+		"source":          "/game-server",
+		"type":            "score:changed",
+		"time":            "2024-05-01T12:00:00Z",
+		"datacontenttype": "application/json",
+		"correlationid":   "match-1",
+	} {
+		if attributes[name] != want {
+			t.Errorf("Expected %s %v, got %v", name, want, attributes[name])
+		}
+	}
+
+	var decoded CloudEvent
+	if err := json.Unmarshal(line, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	envelope := decoded.Envelope()
+	if envelope.Sequence != 7 || !envelope.Time.Equal(recorded) || envelope.CorrelationID != "match-1" {
+		t.Errorf("Unexpected envelope metadata: %+v", envelope)
+	}
+	score, ok := Payload[scoreEvent](envelope.Event)
+	if !ok || score.Player != "alice" || score.Points != 3 {
+		t.Errorf("Expected decoded payload, got %+v (ok=%v)", score, ok)
+	}
+}
+
+// TestCloudEventBinaryData verifies that non-JSON data uses data_base64
+func TestCloudEventBinaryData(t *testing.T) {
+	ce := CloudEvent{
+		ID:              "a1",
+		Source:          "/sensor",
+		Type:            "frame:captured",
+		DataContentType: "application/octet-stream",
+		Data:            []byte{0, 1, 2},
+	}
+
+	line, _ := json.Marshal(ce)
+	if !strings.Contains(string(line), `"data_base64":"AAEC"`) {
+		t.Errorf("Expected data_base64 attribute, got %s", line)
+	}
+
+	var decoded CloudEvent
+	if err := json.Unmarshal(line, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !bytes.Equal(decoded.Data, ce.Data) {
+		t.Errorf("Expected data %v, got %v", ce.Data, decoded.Data)
+	}
+}
+
+// TestCloudEventValidation verifies that required attributes are enforced
+func TestCloudEventValidation(t *testing.T) {
+	inputs := []string{
+		`{"specversion":"0.3","id":"1","source":"/s","type":"t"}`,
+		`{"specversion":"1.0","source":"/s","type":"t"}`,
+		`{"specversion":"1.0","id":"1","type":"t"}`,
+	}
+	for _, input := range inputs {
+		var ce CloudEvent
+		if err := json.Unmarshal([]byte(input), &ce); err == nil {
+			t.Errorf("Expected an error decoding %s", input)
+		}
+	}
+
+	if _, err := json.Marshal(CloudEvent{Source: "/s", Type: "t"}); err == nil {
+		t.Error("Expected an error encoding an event without id")
+	}
+	for _, name := range []string{"id", "type", "source", "specversion", "data"} {
+		ce := CloudEvent{ID: "1", Source: "/s", Type: "t", Extensions: map[string]string{name: "forged"}}
+		if _, err := json.Marshal(ce); err == nil {
+			t.Errorf("Expected an error encoding the %s extension", name)
+		}
+		if _, err := ce.HTTPRequest(context.Background(), "http://example.com"); err == nil {
+			t.Errorf("Expected an error sending the %s extension", name)
+		}
+	}
+}
+
+// TestNewCloudEventBusSequence verifies that envelopes without a store sequence use the bus sequence as ID
+func TestNewCloudEventBusSequence(t *testing.T) {
+	ce, err := NewCloudEvent(Envelope{BusSequence: 7, Event: testEvent{eventType: "t"}}, "/s")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ce.ID != "7" {
+		t.Errorf("Expected ID 7, got %q", ce.ID)
+	}
+}
+
+// TestCloudEventHTTP verifies binary and structured HTTP modes
+func TestCloudEventHTTP(t *testing.T) {
+	bus := New()
+	var received []Event
+	bus.Subscribe("score:changed", func(event Event) {
+		received = append(received, event)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ce, err := ReadCloudEvent(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if ce.Extensions["correlationid"] != "match-1" {
+			t.Errorf("Expected correlationid extension, got %v", ce.Extensions)
+		}
+		bus.Publish(ce.Event())
+	}))
+	defer server.Close()
+
+	ce, _ := NewCloudEvent(Envelope{
+		Sequence:      1,
+		Time:          time.Now(),
+		CorrelationID: "match-1",
+		Event:         scoreEvent{Player: "bob", Points: 5},
+	}, "/game-server")
+
+	request, err := ce.HTTPRequest(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("HTTPRequest failed: %v", err)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("Binary mode delivery failed: %v %v", err, response)
+	}
+
+	structured, _ := json.Marshal(ce)
+	response, err = http.Post(server.URL, "application/cloudevents+json", bytes.NewReader(structured))
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("Structured mode delivery failed: %v %v", err, response)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 published events, got %d", len(received))
+	}
+	for _, event := range received {
+		if score, ok := Payload[scoreEvent](event); !ok || score.Player != "bob" {
+			t.Errorf("Expected payload for bob, got %+v", score)
+		}
+	}
+}
+
+// FuzzCloudEventUnmarshal verifies that decoding arbitrary CloudEvents never
+// panics and that decoded events encode and decode again
+func FuzzCloudEventUnmarshal(f *testing.F) {
+	f.Add([]byte(`{"specversion":"1.0","id":"1","source":"/game","type":"player:jumped","data":{"height":2}}`))
+	f.Add([]byte(`{"specversion":"1.0","id":"1","source":"/s","type":"t","datacontenttype":"application/octet-stream","data_base64":"AAEC"}`))
+	f.Add([]byte(`{"specversion":"0.3"}`))
+	f.Add([]byte(`{"specversion":"1.0","id":"1","source":"/s","type":"t","time":"not a time"}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var ce CloudEvent
+		if err := json.Unmarshal(data, &ce); err != nil {
+			return
+		}
+
+		encoded, err := json.Marshal(ce)
+		if err != nil {
+			t.Fatalf("Expected decoded CloudEvent to encode, got %v", err)
+		}
+		var again CloudEvent
+		if err := json.Unmarshal(encoded, &again); err != nil {
+			t.Fatalf("Expected encoded CloudEvent to decode, got %v\n%s", err, encoded)
+		}
+		if again.ID != ce.ID || again.Type != ce.Type || again.Source != ce.Source {
+			t.Fatalf("Expected attributes to survive, got %+v and %+v", ce, again)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+// Command eventbus-topics generates EventType constants and a Markdown
+// overview of producers and consumers from a topics manifest, written as
+// JSON or, in a file ending in .go, as Go source.
+//
+// Usage:
+//
+//	//go:generate go run github.com/Papiermond/eventbus/cmd/eventbus-topics -manifest topics.json -out topics_gen.go -doc TOPICS.md
+//
+// See package topicgen for the manifest formats.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Papiermond/eventbus/topicgen"
+)
+
+func main() {
+	manifestPath := flag.String("manifest", "topics.json", "path of the JSON topics manifest, or of a Go source manifest ending in .go")
+	out := flag.String("out", "topics_gen.go", "path of the generated Go file")
+	doc := flag.String("doc", "", "path of the generated Markdown overview; skipped if empty")
+	pkg := flag.String("package", "", "name of the generated package, overriding the manifest")
+	flag.Parse()
+
+	if err := run(*manifestPath, *out, *doc, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "eventbus-topics:", err)
+		os.Exit(1)
+	}
+}
+
+// run reads the manifest and writes the generated files.
+func run(manifestPath, out, doc, pkg string) error {
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	parse := topicgen.Parse
+	if filepath.Ext(manifestPath) == ".go" {
+		parse = topicgen.ParseGo
+	}
+	manifest, err := parse(f)
+	if err != nil {
+		return err
+	}
+	if pkg != "" {
+		manifest.Package = pkg
+	}
+
+	source, err := manifest.Generate()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, source, 0o644); err != nil {
+		return err
+	}
+	if doc != "" {
+		return os.WriteFile(doc, manifest.Markdown(), 0o644)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Codec converts events to bytes and back, for transports that carry
+// events outside the process.
+type Codec interface {
+	// Encode returns the wire representation of event.
+	Encode(event Event) ([]byte, error)
+	// Decode returns the event of eventType represented by data.
+	Decode(eventType EventType, data []byte) (Event, error)
+}
+
+// JSONCodec encodes events with encoding/json and decodes them into
+// RawEvent values, which can be decoded further with Payload or
+// RawEvent.Decode.
+var JSONCodec Codec = jsonCodec{}
+
+// jsonCodec is the implementation of JSONCodec.
+type jsonCodec struct{}
+
+// Encode returns the JSON encoding of event.
+func (jsonCodec) Encode(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// Decode returns a RawEvent carrying data, which must be valid JSON.
+func (jsonCodec) Decode(eventType EventType, data []byte) (Event, error) {
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("eventbus: invalid JSON payload for %q", eventType)
+	}
+	return RawEvent{Type: eventType, Payload: json.RawMessage(data)}, nil
+}
+
+// CodecRegistry selects the codec of each event type, so transports can
+// carry topics with different wire formats. Event types without a
+// registered codec use the fallback codec.
+//
+// A CodecRegistry is safe for concurrent use.
+//
+// Example:
+//
+//	codecs := eventbus.NewCodecRegistry(nil)
+//	codecs.Register("audio:samples", samplesCodec{})
+type CodecRegistry struct {
+	fallback Codec
+	codecs   map[EventType]Codec
+	mutex    sync.RWMutex
+}
+
+// NewCodecRegistry creates a registry using fallback for event types
+// without a registered codec. A nil fallback selects JSONCodec.
+func NewCodecRegistry(fallback Codec) *CodecRegistry {
+	if fallback == nil {
+		fallback = JSONCodec
+	}
+	return &CodecRegistry{
+		fallback: fallback,
+		codecs:   make(map[EventType]Codec),
+	}
+}
+
+// Register sets the codec for eventType, replacing any previous one.
+func (r *CodecRegistry) Register(eventType EventType, codec Codec) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.codecs[eventType] = codec
+}
+
+// Codec returns the codec for eventType.
+func (r *CodecRegistry) Codec(eventType EventType) Codec {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if codec, ok := r.codecs[eventType]; ok {
+		return codec
+	}
+	return r.fallback
+}
+
+// Encode encodes event with the codec for its type.
+func (r *CodecRegistry) Encode(event Event) ([]byte, error) {
+	return r.Codec(event.GetType()).Encode(event)
+}
+
+// Decode decodes data with the codec for eventType.
+func (r *CodecRegistry) Decode(eventType EventType, data []byte) (Event, error) {
+	return r.Codec(eventType).Decode(eventType, data)
+}
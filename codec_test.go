@@ -0,0 +1,85 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// upperCodec encodes testEvent data in upper case
+type upperCodec struct{}
+
+func (upperCodec) Encode(event Event) ([]byte, error) {
+	return []byte(strings.ToUpper(event.(testEvent).data)), nil
+}
+
+func (upperCodec) Decode(eventType EventType, data []byte) (Event, error) {
+	return testEvent{eventType: eventType, data: strings.ToLower(string(data))}, nil
+}
+
+// TestCodecRegistry verifies that registered codecs take precedence over the fallback
+func TestCodecRegistry(t *testing.T) {
+	codecs := NewCodecRegistry(nil)
+	codecs.Register("chat:message", upperCodec{})
+
+	data, err := codecs.Encode(testEvent{eventType: "chat:message", data: "hello"})
+	if err != nil || string(data) != "HELLO" {
+		t.Errorf("Expected HELLO, got %q (%v)", data, err)
+	}
+	event, err := codecs.Decode("chat:message", data)
+	if err != nil || event.(testEvent).data != "hello" {
+		t.Errorf("Expected hello, got %v (%v)", event, err)
+	}
+
+	data, err = codecs.Encode(Of("player:scored", map[string]int{"points": 3}))
+	if err != nil || string(data) != `{"points":3}` {
+		t.Errorf("Expected JSON payload, got %q (%v)", data, err)
+	}
+	event, err = codecs.Decode("player:scored", data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if raw, ok := event.(RawEvent); !ok || raw.Type != "player:scored" {
+		t.Errorf("Expected a RawEvent of type player:scored, got %#v", event)
+	}
+	if points, ok := Payload[map[string]int](event); !ok || points["points"] != 3 {
+		t.Errorf("Expected 3 points, got %v", points)
+	}
+}
+
+// TestJSONCodecInvalid verifies that JSONCodec rejects invalid payloads
+func TestJSONCodecInvalid(t *testing.T) {
+	if _, err := JSONCodec.Decode("player:scored", []byte("{")); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+// FuzzJSONCodec verifies that decoding arbitrary payloads never panics and
+// that decoded events encode back to the same payload
+func FuzzJSONCodec(f *testing.F) {
+	f.Add("player:jumped", []byte(`{"height": 2.5}`))
+	f.Add("", []byte(`null`))
+	f.Add("x", []byte(`[1, "two", {"three": [3]}]`))
+	f.Add("x", []byte(`{"unterminated": `))
+
+	f.Fuzz(func(t *testing.T, eventType string, data []byte) {
+		event, err := JSONCodec.Decode(EventType(eventType), data)
+		if err != nil {
+			return
+		}
+		if event.GetType() != EventType(eventType) {
+			t.Fatalf("Expected type %q, got %q", eventType, event.GetType())
+		}
+
+		encoded, err := JSONCodec.Encode(event)
+		if err != nil {
+			t.Fatalf("Expected decoded event to encode, got %v", err)
+		}
+		var compact bytes.Buffer
+		json.Compact(&compact, data)
+		if !bytes.Equal(encoded, compact.Bytes()) {
+			t.Fatalf("Expected the payload %q unchanged, got %q", data, encoded)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package eventbus
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EncodingGzip marks a payload compressed with gzip. Uncompressed payloads
+// have the empty encoding.
+const EncodingGzip = "gzip"
+
+// MaxDecompressedSize is the largest payload DecompressPayload returns,
+// in bytes, so a small compressed payload cannot exhaust memory.
+const MaxDecompressedSize = 64 << 20
+
+// ErrUnknownEncoding is returned for payloads with an unsupported encoding.
+var ErrUnknownEncoding = errors.New("eventbus: unknown payload encoding")
+
+// CompressPayload compresses data with gzip if it is longer than threshold
+// bytes and compression makes it shorter. It returns the payload to send
+// and its encoding, EncodingGzip or "". A threshold of zero or less never
+// compresses, since most events are too small to benefit.
+func CompressPayload(data []byte, threshold int) ([]byte, string, error) {
+	if threshold <= 0 || len(data) <= threshold {
+		return data, "", nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, "", err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, "", err
+	}
+	if buf.Len() >= len(data) {
+		return data, "", nil
+	}
+	return buf.Bytes(), EncodingGzip, nil
+}
+
+// DecompressPayload reverses CompressPayload for a payload recorded with
+// encoding. It returns an error matching ErrUnknownEncoding for encodings
+// other than EncodingGzip and "".
+func DecompressPayload(data []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return data, nil
+	case EncodingGzip:
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownEncoding, encoding)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	decompressed, err := io.ReadAll(io.LimitReader(gz, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > MaxDecompressedSize {
+		return nil, fmt.Errorf("eventbus: decompressed payload exceeds %d bytes", MaxDecompressedSize)
+	}
+	return decompressed, nil
+}
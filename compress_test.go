@@ -0,0 +1,44 @@
+package eventbus
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestCompressPayload verifies that payloads are compressed only above the threshold
+func TestCompressPayload(t *testing.T) {
+	small := []byte(`{"x":1}`)
+	if data, encoding, _ := CompressPayload(small, 4); encoding != "" || !bytes.Equal(data, small) {
+		t.Errorf("Expected an incompressible payload to stay plain, got %q", encoding)
+	}
+	large := bytes.Repeat([]byte(`{"x":1},`), 100)
+	if _, encoding, _ := CompressPayload(large, 0); encoding != "" {
+		t.Errorf("Expected no compression without a threshold, got %q", encoding)
+	}
+	if _, encoding, _ := CompressPayload(large, len(large)); encoding != "" {
+		t.Errorf("Expected no compression at the threshold, got %q", encoding)
+	}
+
+	data, encoding, err := CompressPayload(large, 64)
+	if err != nil || encoding != EncodingGzip || len(data) >= len(large) {
+		t.Fatalf("Expected a shorter gzip payload, got %d bytes, %q, %v", len(data), encoding, err)
+	}
+	decompressed, err := DecompressPayload(data, encoding)
+	if err != nil || !bytes.Equal(decompressed, large) {
+		t.Errorf("Expected the original payload back, got %v", err)
+	}
+}
+
+// TestDecompressPayloadErrors verifies that unknown encodings and corrupt data are rejected
+func TestDecompressPayloadErrors(t *testing.T) {
+	if _, err := DecompressPayload([]byte("x"), "br"); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("Expected ErrUnknownEncoding, got %v", err)
+	}
+	if _, err := DecompressPayload([]byte("not gzip"), EncodingGzip); err == nil {
+		t.Error("Expected an error for corrupt data")
+	}
+	if data, err := DecompressPayload([]byte("plain"), ""); err != nil || string(data) != "plain" {
+		t.Errorf("Expected plain data unchanged, got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,43 @@
+package eventbus
+
+import "context"
+
+// WithMaxConcurrency limits the listener to n invocations in flight at a
+// time. It protects listeners that are not safe for concurrent use, such
+// as one holding a single database connection, without making the whole
+// topic serial. Deliveries beyond the limit wait, occupying their worker,
+// until an invocation returns or the subscription is cancelled. Values
+// below 1 are treated as 1.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithAsync(8, 1024))
+//	bus.Subscribe("order:placed", saveOrder, eventbus.WithMaxConcurrency(1))
+func WithMaxConcurrency(n int) SubscribeOption {
+	return func(config *subscribeConfig) {
+		config.maxConcurrency = max(n, 1)
+		config.options = append(config.options, "max-concurrency")
+	}
+}
+
+// limiting returns a listener that calls listener with at most n
+// invocations in flight. It returns listener unchanged if n is 0.
+// Deliveries waiting for a slot are dropped once the subscription is
+// cancelled.
+func (s *Subscription) limiting(listener ContextListener, n int) ContextListener {
+	if n == 0 {
+		return listener
+	}
+
+	slots := make(chan struct{}, n)
+	return func(ctx context.Context, event Event) {
+		select {
+		case slots <- struct{}{}:
+		case <-s.ctx.Done():
+			return
+		}
+		defer func() { <-slots }()
+
+		listener(ctx, event)
+	}
+}
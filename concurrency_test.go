@@ -0,0 +1,82 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestMaxConcurrency verifies that a subscription never exceeds its in-flight limit
+func TestMaxConcurrency(t *testing.T) {
+	bus := New(WithAsync(8, 64))
+
+	var inFlight, peak, limitedCalls, freeCalls, freePeak atomic.Int32
+	track := func(counter, peak *atomic.Int32) {
+		current := counter.Add(1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		counter.Add(-1)
+	}
+
+	bus.Subscribe("order:placed", func(event Event) {
+		track(&inFlight, &peak)
+		limitedCalls.Add(1)
+	}, WithMaxConcurrency(2))
+
+	var free atomic.Int32
+	bus.Subscribe("order:placed", func(event Event) {
+		track(&free, &freePeak)
+		freeCalls.Add(1)
+	})
+
+	for i := 0; i < 20; i++ {
+		bus.Publish(testEvent{eventType: "order:placed"})
+	}
+	bus.Close()
+
+	if limitedCalls.Load() != 20 || freeCalls.Load() != 20 {
+		t.Errorf("Expected 20 calls each, got %d and %d", limitedCalls.Load(), freeCalls.Load())
+	}
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 invocations in flight, got %d", peak.Load())
+	}
+	if freePeak.Load() <= 2 {
+		t.Errorf("Expected the unlimited listener to exceed 2 invocations in flight, got %d", freePeak.Load())
+	}
+}
+
+// TestMaxConcurrencyCancel verifies that deliveries waiting for a slot are dropped on cancel
+func TestMaxConcurrencyCancel(t *testing.T) {
+	bus := New(WithAsync(4, 64))
+	defer bus.Close()
+
+	running := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int32
+	var sub *Subscription
+	sub = bus.Subscribe("order:placed", func(event Event) {
+		if calls.Add(1) == 1 {
+			close(running)
+			<-release
+		}
+	}, WithMaxConcurrency(1))
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(testEvent{eventType: "order:placed"})
+	}
+	<-running
+	// Give the other workers time to wait for the slot.
+	time.Sleep(10 * time.Millisecond)
+	sub.Cancel()
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 call, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,435 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidConfig is matched by the errors Config.Validate returns.
+var ErrInvalidConfig = errors.New("eventbus: invalid configuration")
+
+// Dispatch modes of DispatchConfig.Mode.
+const (
+	// ModeSync delivers on the publishing goroutine.
+	ModeSync = "sync"
+	// ModeAsync delivers on a worker pool sharing one queue.
+	ModeAsync = "async"
+	// ModeWorkStealing delivers on a work-stealing pool.
+	ModeWorkStealing = "work_stealing"
+)
+
+// Overflow policies of DispatchConfig.Overflow.
+const (
+	OverflowNameBlock      = "block"
+	OverflowNameDropNewest = "drop_newest"
+	OverflowNameDropOldest = "drop_oldest"
+)
+
+// Config describes a bus declaratively, for services that configure it
+// from a file and the environment instead of long option chains. Load one
+// with LoadConfig, apply environment overrides with ApplyEnv, and create
+// the bus from Options. The zero value is a synchronous bus without a
+// store.
+//
+// Example:
+//
+//	config, err := eventbus.LoadConfig("eventbus.json", nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := config.ApplyEnv("EVENTBUS"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	opts, err := config.Options()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	bus := eventbus.New(opts...)
+type Config struct {
+	// Dispatch is the dispatch of every topic not matched by Topics.
+	Dispatch DispatchConfig `json:"dispatch" yaml:"dispatch"`
+	// Topics overrides Dispatch for the topics matching their patterns;
+	// the first matching pattern wins.
+	Topics []TopicDispatch `json:"topics" yaml:"topics"`
+	// Health configures subscription health and the circuit breakers
+	// retrying failing listeners after a cooldown; see WithHealth.
+	Health *HealthSettings `json:"health" yaml:"health"`
+	// Retry calls panicking listeners again; see WithRetry.
+	Retry RetrySettings `json:"retry" yaml:"retry"`
+	// Persistence configures the event store.
+	Persistence PersistenceConfig `json:"persistence" yaml:"persistence"`
+	// Bridges describes the bridges of the bus, created with the bridge
+	// package once their transports are connected.
+	Bridges []BridgeSettings `json:"bridges" yaml:"bridges"`
+	// Muted lists the muted topic patterns; see WithMuted.
+	Muted []EventType `json:"muted" yaml:"muted"`
+	// MaxPublishDepth limits nested publishing; see WithMaxPublishDepth.
+	MaxPublishDepth int `json:"max_publish_depth" yaml:"max_publish_depth"`
+}
+
+// DispatchConfig describes the dispatch of topics, like TopicConfig.
+type DispatchConfig struct {
+	// Mode is ModeSync, ModeAsync, or ModeWorkStealing. Empty means
+	// ModeSync.
+	Mode string `json:"mode" yaml:"mode"`
+	// Workers, QueueSize, and MaxWorkers size the pool of asynchronous
+	// modes.
+	Workers    int `json:"workers" yaml:"workers"`
+	QueueSize  int `json:"queue_size" yaml:"queue_size"`
+	MaxWorkers int `json:"max_workers" yaml:"max_workers"`
+	// Overflow is OverflowNameBlock, OverflowNameDropNewest, or
+	// OverflowNameDropOldest. Empty means OverflowNameBlock.
+	Overflow string `json:"overflow" yaml:"overflow"`
+	// Aging promotes events kept waiting by urgent ones; see
+	// TopicConfig.Aging.
+	Aging Duration `json:"aging" yaml:"aging"`
+}
+
+// TopicDispatch is the dispatch of the topics matching Pattern.
+type TopicDispatch struct {
+	Pattern        EventType `json:"pattern" yaml:"pattern"`
+	DispatchConfig `yaml:",inline"`
+}
+
+// HealthSettings describes HealthConfig in a Config.
+type HealthSettings struct {
+	Window          int      `json:"window" yaml:"window"`
+	SlowThreshold   Duration `json:"slow_threshold" yaml:"slow_threshold"`
+	ErrorRatio      float64  `json:"error_ratio" yaml:"error_ratio"`
+	BreakerFailures int      `json:"breaker_failures" yaml:"breaker_failures"`
+	BreakerCooldown Duration `json:"breaker_cooldown" yaml:"breaker_cooldown"`
+}
+
+// RetrySettings describes the RetryPolicy of a Config.
+type RetrySettings struct {
+	Attempts int      `json:"attempts" yaml:"attempts"`
+	Backoff  Duration `json:"backoff" yaml:"backoff"`
+}
+
+// Stores of PersistenceConfig.Store.
+const (
+	// StoreNone keeps no events.
+	StoreNone = ""
+	// StoreMemory keeps events in a MemoryStore.
+	StoreMemory = "memory"
+)
+
+// PersistenceConfig describes the event store of a Config.
+type PersistenceConfig struct {
+	// Store is StoreNone or StoreMemory.
+	Store string `json:"store" yaml:"store"`
+	// MemoryLimit bounds the store and the last-value caches in bytes
+	// with a MemoryBudget. Zero means unbounded.
+	MemoryLimit int64 `json:"memory_limit" yaml:"memory_limit"`
+}
+
+// BridgeSettings describes a bridge in a Config. The bridge package turns
+// it into a bridge.Config.
+type BridgeSettings struct {
+	Name          string            `json:"name" yaml:"name"`
+	Origin        string            `json:"origin" yaml:"origin"`
+	MaxHops       int               `json:"max_hops" yaml:"max_hops"`
+	CompressAbove int               `json:"compress_above" yaml:"compress_above"`
+	Timestamps    bool              `json:"timestamps" yaml:"timestamps"`
+	Buffer        BufferSettings    `json:"buffer" yaml:"buffer"`
+	Mappings      []MappingSettings `json:"mappings" yaml:"mappings"`
+}
+
+// BufferSettings describes the BufferConfig of a bridge.
+type BufferSettings struct {
+	Size         int    `json:"size" yaml:"size"`
+	Overflow     string `json:"overflow" yaml:"overflow"`
+	LagThreshold int    `json:"lag_threshold" yaml:"lag_threshold"`
+}
+
+// MappingSettings describes a bridged topic.
+type MappingSettings struct {
+	Local  EventType `json:"local" yaml:"local"`
+	Remote string    `json:"remote" yaml:"remote"`
+	// Direction is "out", "in", or "both".
+	Direction string `json:"direction" yaml:"direction"`
+	Queue     string `json:"queue" yaml:"queue"`
+}
+
+// Duration is a time.Duration written as a string such as "250ms" in
+// configuration files.
+type Duration time.Duration
+
+// MarshalText returns the duration as a string such as "250ms".
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText parses a duration such as "250ms".
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadConfig reads the configuration at path. Files are decoded with
+// decode, or as JSON if decode is nil. The bus has no YAML dependency:
+// pass the Unmarshal function of gopkg.in/yaml.v3 to read YAML, which
+// honors the yaml tags of Config.
+func LoadConfig(path string, decode func(data []byte, v any) error) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if decode == nil {
+		decode = json.Unmarshal
+	}
+	config := &Config{}
+	if err := decode(data, config); err != nil {
+		return nil, fmt.Errorf("eventbus: reading %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ApplyEnv overrides the configuration with the environment variables
+// named prefix followed by an underscore and one of:
+//
+//	DISPATCH_MODE, DISPATCH_WORKERS, DISPATCH_QUEUE_SIZE,
+//	DISPATCH_MAX_WORKERS, DISPATCH_OVERFLOW, DISPATCH_AGING,
+//	HEALTH_SLOW_THRESHOLD, HEALTH_BREAKER_FAILURES,
+//	HEALTH_BREAKER_COOLDOWN, RETRY_ATTEMPTS, RETRY_BACKOFF, STORE,
+//	MEMORY_LIMIT, MUTED (comma separated), MAX_PUBLISH_DEPTH
+//
+// For example, EVENTBUS_DISPATCH_WORKERS=8 with prefix "EVENTBUS". It
+// returns an error naming the first variable that cannot be parsed.
+func (c *Config) ApplyEnv(prefix string) error {
+	env := func(name string) (string, bool) {
+		return os.LookupEnv(prefix + "_" + name)
+	}
+	var err error
+	integer := func(name string, target *int) {
+		if value, ok := env(name); ok && err == nil {
+			if *target, err = strconv.Atoi(value); err != nil {
+				err = fmt.Errorf("eventbus: %s_%s: %w", prefix, name, err)
+			}
+		}
+	}
+	duration := func(name string, target *Duration) {
+		if value, ok := env(name); ok && err == nil {
+			if parseErr := target.UnmarshalText([]byte(value)); parseErr != nil {
+				err = fmt.Errorf("eventbus: %s_%s: %w", prefix, name, parseErr)
+			}
+		}
+	}
+	text := func(name string, target *string) {
+		if value, ok := env(name); ok {
+			*target = value
+		}
+	}
+
+	text("DISPATCH_MODE", &c.Dispatch.Mode)
+	integer("DISPATCH_WORKERS", &c.Dispatch.Workers)
+	integer("DISPATCH_QUEUE_SIZE", &c.Dispatch.QueueSize)
+	integer("DISPATCH_MAX_WORKERS", &c.Dispatch.MaxWorkers)
+	text("DISPATCH_OVERFLOW", &c.Dispatch.Overflow)
+	duration("DISPATCH_AGING", &c.Dispatch.Aging)
+	for _, name := range []string{"HEALTH_SLOW_THRESHOLD", "HEALTH_BREAKER_FAILURES", "HEALTH_BREAKER_COOLDOWN"} {
+		if _, ok := env(name); ok && c.Health == nil {
+			c.Health = &HealthSettings{}
+		}
+	}
+	if c.Health != nil {
+		duration("HEALTH_SLOW_THRESHOLD", &c.Health.SlowThreshold)
+		integer("HEALTH_BREAKER_FAILURES", &c.Health.BreakerFailures)
+		duration("HEALTH_BREAKER_COOLDOWN", &c.Health.BreakerCooldown)
+	}
+	integer("RETRY_ATTEMPTS", &c.Retry.Attempts)
+	duration("RETRY_BACKOFF", &c.Retry.Backoff)
+	text("STORE", &c.Persistence.Store)
+	if value, ok := env("MEMORY_LIMIT"); ok && err == nil {
+		if c.Persistence.MemoryLimit, err = strconv.ParseInt(value, 10, 64); err != nil {
+			err = fmt.Errorf("eventbus: %s_MEMORY_LIMIT: %w", prefix, err)
+		}
+	}
+	if value, ok := env("MUTED"); ok {
+		c.Muted = nil
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				c.Muted = append(c.Muted, EventType(pattern))
+			}
+		}
+	}
+	integer("MAX_PUBLISH_DEPTH", &c.MaxPublishDepth)
+	return err
+}
+
+// Validate reports every invalid setting, joined into one error matching
+// ErrInvalidConfig.
+func (c *Config) Validate() error {
+	var problems []error
+	invalid := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf("%w: "+format, append([]any{ErrInvalidConfig}, args...)...))
+	}
+
+	c.Dispatch.validate("dispatch", invalid)
+	for i, topic := range c.Topics {
+		if topic.Pattern == "" {
+			invalid("topics[%d]: missing pattern", i)
+		}
+		topic.validate(fmt.Sprintf("topics[%d]", i), invalid)
+	}
+	if c.Health != nil && (c.Health.Window < 0 || c.Health.ErrorRatio < 0 || c.Health.ErrorRatio > 1 || c.Health.BreakerFailures < 0) {
+		invalid("health: negative window or breaker failures, or error ratio outside [0, 1]")
+	}
+	if c.Retry.Attempts < 0 || c.Retry.Backoff < 0 {
+		invalid("retry: negative attempts or backoff")
+	}
+	switch c.Persistence.Store {
+	case StoreNone, StoreMemory:
+	default:
+		invalid("persistence: unknown store %q", c.Persistence.Store)
+	}
+	if c.Persistence.MemoryLimit < 0 {
+		invalid("persistence: negative memory limit")
+	}
+	names := make(map[string]bool)
+	for i, bridge := range c.Bridges {
+		switch {
+		case bridge.Name == "":
+			invalid("bridges[%d]: missing name", i)
+		case names[bridge.Name]:
+			invalid("bridges[%d]: duplicate name %q", i, bridge.Name)
+		}
+		names[bridge.Name] = true
+		if _, ok := overflowPolicy(bridge.Buffer.Overflow); !ok {
+			invalid("bridges[%d]: unknown buffer overflow %q", i, bridge.Buffer.Overflow)
+		}
+		for j, mapping := range bridge.Mappings {
+			if mapping.Local == "" || mapping.Remote == "" {
+				invalid("bridges[%d].mappings[%d]: missing local or remote topic", i, j)
+			}
+			switch mapping.Direction {
+			case "out", "in", "both":
+			default:
+				invalid("bridges[%d].mappings[%d]: unknown direction %q", i, j, mapping.Direction)
+			}
+		}
+	}
+	if c.MaxPublishDepth < 0 {
+		invalid("negative max publish depth")
+	}
+	return errors.Join(problems...)
+}
+
+// validate reports the invalid settings of d under name.
+func (d DispatchConfig) validate(name string, invalid func(format string, args ...any)) {
+	switch d.Mode {
+	case "", ModeSync, ModeAsync, ModeWorkStealing:
+	default:
+		invalid("%s: unknown mode %q", name, d.Mode)
+	}
+	if _, ok := overflowPolicy(d.Overflow); !ok {
+		invalid("%s: unknown overflow %q", name, d.Overflow)
+	}
+	if d.Workers < 0 || d.QueueSize < 0 || d.MaxWorkers < 0 || d.Aging < 0 {
+		invalid("%s: negative pool size or aging", name)
+	}
+}
+
+// topicConfig returns the TopicConfig described by d.
+func (d DispatchConfig) topicConfig() TopicConfig {
+	if d.Mode == "" || d.Mode == ModeSync {
+		return TopicConfig{}
+	}
+	overflow, _ := overflowPolicy(d.Overflow)
+	return TopicConfig{
+		Async:        true,
+		Workers:      d.Workers,
+		QueueSize:    d.QueueSize,
+		MaxWorkers:   d.MaxWorkers,
+		Overflow:     overflow,
+		WorkStealing: d.Mode == ModeWorkStealing,
+		Aging:        time.Duration(d.Aging),
+	}
+}
+
+// overflowPolicy returns the policy named name.
+func overflowPolicy(name string) (OverflowPolicy, bool) {
+	switch name {
+	case "", OverflowNameBlock:
+		return OverflowBlock, true
+	case OverflowNameDropNewest:
+		return OverflowDropNewest, true
+	case OverflowNameDropOldest:
+		return OverflowDropOldest, true
+	}
+	return OverflowBlock, false
+}
+
+// Options validates the configuration and returns the options creating
+// the bus it describes. Bridges are not created; see the bridge package.
+func (c *Config) Options() ([]Option, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var opts []Option
+	// Exact patterns take precedence and the first wildcard wins, so the
+	// overrides are registered before the default.
+	for _, topic := range c.Topics {
+		opts = append(opts, WithTopicConfig(topic.Pattern, topic.topicConfig()))
+	}
+	if c.Dispatch.Mode != "" && c.Dispatch.Mode != ModeSync {
+		opts = append(opts, WithTopicConfig("*", c.Dispatch.topicConfig()))
+	}
+	if h := c.Health; h != nil {
+		opts = append(opts, WithHealth(HealthConfig{
+			Window:          h.Window,
+			SlowThreshold:   time.Duration(h.SlowThreshold),
+			ErrorRatio:      h.ErrorRatio,
+			BreakerFailures: h.BreakerFailures,
+			BreakerCooldown: time.Duration(h.BreakerCooldown),
+		}))
+	}
+	if c.Retry.Attempts > 0 {
+		opts = append(opts, WithRetry(RetryPolicy{Attempts: c.Retry.Attempts, Backoff: time.Duration(c.Retry.Backoff)}))
+	}
+	var budget *MemoryBudget
+	if c.Persistence.MemoryLimit > 0 {
+		budget = NewMemoryBudget(c.Persistence.MemoryLimit)
+		opts = append(opts, WithMemoryBudget(budget))
+	}
+	switch {
+	case c.Persistence.Store == StoreMemory && budget != nil:
+		opts = append(opts, WithStore(NewMemoryStoreWithBudget(budget)))
+	case c.Persistence.Store == StoreMemory:
+		opts = append(opts, WithStore(NewMemoryStore()))
+	}
+	if len(c.Muted) > 0 {
+		opts = append(opts, WithMuted(c.Muted...))
+	}
+	if c.MaxPublishDepth > 0 {
+		opts = append(opts, WithMaxPublishDepth(c.MaxPublishDepth))
+	}
+	return opts, nil
+}
+
+// Bridge returns the settings of the bridge named name.
+func (c *Config) Bridge(name string) (BridgeSettings, bool) {
+	for _, bridge := range c.Bridges {
+		if bridge.Name == name {
+			return bridge, true
+		}
+	}
+	return BridgeSettings{}, false
+}
+
+// BufferConfig returns the BufferConfig described by s. An unknown
+// overflow policy blocks.
+func (s BufferSettings) BufferConfig() BufferConfig {
+	overflow, _ := overflowPolicy(s.Overflow)
+	return BufferConfig{Size: s.Size, Overflow: overflow, LagThreshold: s.LagThreshold}
+}
@@ -0,0 +1,137 @@
+package eventbus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestLoadConfig verifies that a JSON file with environment overrides
+// configures a bus
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eventbus.json")
+	err := os.WriteFile(path, []byte(`{
+		"dispatch": {"mode": "async", "workers": 2, "queue_size": 64, "aging": "50ms"},
+		"topics": [{"pattern": "input:*", "mode": "sync"}],
+		"health": {"slow_threshold": "10ms", "breaker_failures": 5},
+		"persistence": {"store": "memory"},
+		"bridges": [{"name": "nats", "mappings": [{"local": "order:placed", "remote": "orders", "direction": "out"}]}],
+		"muted": ["debug:*"]
+	}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EVENTBUS_DISPATCH_WORKERS", "4")
+	t.Setenv("EVENTBUS_MUTED", "physics:*, telemetry:*")
+
+	config, err := LoadConfig(path, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := config.ApplyEnv("EVENTBUS"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.Dispatch.Workers != 4 || time.Duration(config.Dispatch.Aging) != 50*time.Millisecond {
+		t.Errorf("Expected 4 workers and 50ms aging, got %+v", config.Dispatch)
+	}
+	if len(config.Muted) != 2 || config.Muted[1] != "telemetry:*" {
+		t.Errorf("Expected the muted patterns of the environment, got %v", config.Muted)
+	}
+	if settings, ok := config.Bridge("nats"); !ok || len(settings.Mappings) != 1 {
+		t.Errorf("Expected the nats bridge with 1 mapping, got %+v", settings)
+	}
+
+	opts, err := config.Options()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus := New(opts...)
+	release := make(chan struct{})
+	delivered := 0
+	bus.Subscribe("order:placed", func(event Event) { <-release })
+	bus.Subscribe("input:key", func(event Event) { delivered++ })
+
+	// Blocks unless order:placed is asynchronous.
+	bus.Publish(testEvent{eventType: "order:placed"})
+	bus.Publish(testEvent{eventType: "input:key"})
+	if delivered != 1 {
+		t.Errorf("Expected input:key to be delivered synchronously, got %d deliveries", delivered)
+	}
+	close(release)
+	bus.Close()
+	if _, ok := bus.(*eventBusImpl).store.(*MemoryStore); !ok {
+		t.Error("Expected a memory store")
+	}
+}
+
+// TestLoadConfigYAML verifies that YAML files decode through the yaml tags,
+// including the inline dispatch settings of topics
+func TestLoadConfigYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eventbus.yaml")
+	err := os.WriteFile(path, []byte(`
+dispatch:
+  mode: async
+  workers: 2
+  aging: 50ms
+topics:
+  - pattern: "input:*"
+    mode: sync
+    workers: 3
+retry:
+  attempts: 2
+  backoff: 10ms
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(path, yaml.Unmarshal)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.Dispatch.Mode != "async" || time.Duration(config.Dispatch.Aging) != 50*time.Millisecond {
+		t.Errorf("Expected async dispatch with 50ms aging, got %+v", config.Dispatch)
+	}
+	if len(config.Topics) != 1 {
+		t.Fatalf("Expected 1 topic, got %d", len(config.Topics))
+	}
+	if topic := config.Topics[0]; topic.Pattern != "input:*" || topic.Mode != "sync" || topic.Workers != 3 {
+		t.Errorf("Expected the inline settings of input:*, got %+v", topic)
+	}
+	if config.Retry.Attempts != 2 || time.Duration(config.Retry.Backoff) != 10*time.Millisecond {
+		t.Errorf("Expected 2 attempts with 10ms backoff, got %+v", config.Retry)
+	}
+	if _, err := config.Options(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+// TestConfigValidate verifies that every invalid setting is reported
+func TestConfigValidate(t *testing.T) {
+	config := Config{
+		Dispatch:    DispatchConfig{Mode: "threads", Overflow: "spill"},
+		Topics:      []TopicDispatch{{DispatchConfig: DispatchConfig{Workers: -1}}},
+		Persistence: PersistenceConfig{Store: "disk"},
+		Bridges:     []BridgeSettings{{Name: "nats"}, {Name: "nats", Mappings: []MappingSettings{{Local: "a", Remote: "b", Direction: "sideways"}}}},
+		Retry:       RetrySettings{Attempts: -1},
+	}
+	err := config.Validate()
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+	if problems := len(err.(interface{ Unwrap() []error }).Unwrap()); problems != 8 {
+		t.Errorf("Expected 8 problems, got %d: %v", problems, err)
+	}
+	if _, err := config.Options(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected Options to validate, got %v", err)
+	}
+
+	t.Setenv("EVENTBUS_DISPATCH_AGING", "soon")
+	if err := (&Config{}).ApplyEnv("EVENTBUS"); err == nil {
+		t.Error("Expected an error for an invalid duration")
+	}
+}
@@ -0,0 +1,347 @@
+// Package console provides a text command interface for inspecting and
+// steering a running event bus, for live troubleshooting of long-running
+// servers. Commands are executed with Exec, for example from an admin
+// page, or served line by line over a TCP connection with Serve.
+//
+// Commands:
+//
+//	topics                  list subscribed topics and their listener counts
+//	subs [topic]            list subscriptions with their index and health
+//	describe <type>         show the documentation and subscribers of a topic
+//	inject <type> <json>    publish an event decoded from JSON
+//	mute <pattern>          suppress delivery of matching topics
+//	unmute <pattern>        resume delivery of matching topics
+//	solo <pattern>          suppress delivery of all other topics
+//	unsolo <pattern>        undo solo
+//	detach <index|handler>  cancel a subscription
+//	costs [n]               list the n most expensive handlers
+//	graph [json|dot]        export the producer/consumer graph
+//	help                    list the commands
+//	quit                    close the connection
+//
+// Example:
+//
+//	listener, err := net.Listen("tcp", "127.0.0.1:7070")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	go console.New(bus, nil).Serve(listener)
+//
+//	// $ nc 127.0.0.1 7070
+//	// > inject player:died {"PlayerID":"p1"}
+package console
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// ErrUnknownCommand is returned by Exec for commands it does not know.
+var ErrUnknownCommand = errors.New("console: unknown command")
+
+// errQuit ends a connection served by ServeConn.
+var errQuit = errors.New("console: quit")
+
+// help describes the commands, one per line.
+const help = `topics                  list subscribed topics and their listener counts
+subs [topic]            list subscriptions with their index and health
+describe <type>         show the documentation and subscribers of a topic
+inject <type> <json>    publish an event decoded from JSON
+mute <pattern>          suppress delivery of matching topics
+unmute <pattern>        resume delivery of matching topics
+solo <pattern>          suppress delivery of all other topics
+unsolo <pattern>        undo solo
+detach <index|handler>  cancel a subscription
+costs [n]               list the n most expensive handlers
+graph [json|dot]        export the producer/consumer graph
+help                    list the commands
+quit                    close the connection`
+
+// Console executes debug commands against a bus.
+type Console struct {
+	bus   eventbus.EventBus
+	types *eventbus.TypeRegistry
+}
+
+// New creates a console for bus. Injected events are decoded into the Go
+// types registered in types, or eventbus.DefaultTypes if types is nil;
+// unregistered event types are published as eventbus.RawEvent values.
+func New(bus eventbus.EventBus, types *eventbus.TypeRegistry) *Console {
+	if types == nil {
+		types = eventbus.DefaultTypes
+	}
+	return &Console{bus: bus, types: types}
+}
+
+// Exec runs a single command line and returns its output. It returns an
+// error matching ErrUnknownCommand for unknown commands.
+func (c *Console) Exec(line string) (string, error) {
+	command, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	args = strings.TrimSpace(args)
+
+	switch command {
+	case "topics":
+		return c.topics(), nil
+	case "subs":
+		return c.subs(eventbus.EventType(args)), nil
+	case "describe":
+		return c.describe(eventbus.EventType(args))
+	case "inject":
+		return c.inject(args)
+	case "mute", "unmute", "solo", "unsolo":
+		return c.mute(command, eventbus.EventType(args))
+	case "detach":
+		return c.detach(args)
+	case "costs":
+		return c.costs(args)
+	case "graph":
+		return c.graph(args)
+	case "help":
+		return help, nil
+	case "quit":
+		return "", errQuit
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnknownCommand, command)
+	}
+}
+
+// Serve accepts connections on listener and serves each of them with
+// ServeConn on its own goroutine. It returns when Accept fails, for
+// example after listener is closed.
+func (c *Console) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			c.ServeConn(conn)
+		}()
+	}
+}
+
+// ServeConn reads commands from rw, one per line, and writes their output
+// followed by a "> " prompt. Failed commands are reported as "error: "
+// lines. It returns nil after the quit command or at the end of input.
+func (c *Console) ServeConn(rw io.ReadWriter) error {
+	scanner := bufio.NewScanner(rw)
+	if _, err := io.WriteString(rw, "> "); err != nil {
+		return err
+	}
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			if _, err := io.WriteString(rw, "> "); err != nil {
+				return err
+			}
+			continue
+		}
+
+		output, err := c.Exec(scanner.Text())
+		if errors.Is(err, errQuit) {
+			return nil
+		}
+		if err != nil {
+			output = "error: " + err.Error()
+		}
+		if output != "" {
+			output += "\n"
+		}
+		if _, err := io.WriteString(rw, output+"> "); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// topics lists the subscribed topics in order with their listener counts.
+func (c *Console) topics() string {
+	counts := make(map[eventbus.EventType]int)
+	for _, info := range c.bus.Snapshot().Subscriptions() {
+		counts[info.EventType]++
+	}
+
+	topics := make([]eventbus.EventType, 0, len(counts))
+	for topic := range counts {
+		topics = append(topics, topic)
+	}
+	slices.Sort(topics)
+
+	lines := make([]string, len(topics))
+	for i, topic := range topics {
+		lines[i] = fmt.Sprintf("%s\t%d", topic, counts[topic])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// subs lists the subscriptions to topic, or all of them if topic is empty.
+func (c *Console) subs(topic eventbus.EventType) string {
+	var lines []string
+	for i, info := range c.bus.Snapshot().Subscriptions() {
+		if topic != "" && info.EventType != topic {
+			continue
+		}
+		handler := info.Handler
+		if handler == "" {
+			handler = "-"
+		}
+		line := fmt.Sprintf("%d\t%s\t%s\t%s", i, info.EventType, handler, info.Health)
+		if len(info.Options) > 0 {
+			line += "\t" + strings.Join(info.Options, ",")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describe shows the documentation and subscribers of eventType, one
+// "key: value" line per known property.
+func (c *Console) describe(eventType eventbus.EventType) (string, error) {
+	if eventType == "" {
+		return "", errors.New("usage: describe <type>")
+	}
+	d := c.bus.Describe(eventType)
+
+	lines := []string{string(eventType)}
+	add := func(key, value string) {
+		if value != "" {
+			lines = append(lines, key+": "+value)
+		}
+	}
+	add("description", d.Description)
+	add("owner", d.Owner)
+	if d.GoType != nil {
+		add("go type", d.GoType.String())
+	}
+	add("producers", strings.Join(d.Producers, ", "))
+	add("publishers", strings.Join(d.Publishers, ", "))
+	add("consumers", strings.Join(d.Consumers, ", "))
+	for _, info := range d.Subscriptions {
+		handler := info.Handler
+		if handler == "" {
+			handler = "-"
+		}
+		add("subscriber", fmt.Sprintf("%s (%s)", handler, info.Health))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// inject decodes and publishes the event in args, "<type> <json>".
+func (c *Console) inject(args string) (string, error) {
+	eventType, data, _ := strings.Cut(args, " ")
+	data = strings.TrimSpace(data)
+	if eventType == "" || data == "" {
+		return "", errors.New("usage: inject <type> <json>")
+	}
+
+	event, err := c.types.Decode(eventbus.EventType(eventType), []byte(data))
+	if err != nil {
+		return "", fmt.Errorf("decoding %s: %w", eventType, err)
+	}
+	// A transaction reports publish errors instead of panicking.
+	tx := c.bus.Begin()
+	tx.Publish(event)
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return "published " + eventType, nil
+}
+
+// mute runs the mute, unmute, solo, or unsolo command for pattern.
+func (c *Console) mute(command string, pattern eventbus.EventType) (string, error) {
+	if pattern == "" {
+		return "", fmt.Errorf("usage: %s <pattern>", command)
+	}
+	switch command {
+	case "mute":
+		c.bus.Mute(pattern)
+	case "unmute":
+		c.bus.Unmute(pattern)
+	case "solo":
+		c.bus.Solo(pattern)
+	case "unsolo":
+		c.bus.Unsolo(pattern)
+	}
+	return command + " " + string(pattern), nil
+}
+
+// detach cancels the subscription with the index or handler name in args.
+// A handler name must identify a single subscription.
+func (c *Console) detach(args string) (string, error) {
+	if args == "" {
+		return "", errors.New("usage: detach <index|handler>")
+	}
+	snapshot := c.bus.Snapshot()
+
+	index := -1
+	if i, err := strconv.Atoi(args); err == nil {
+		index = i
+	} else {
+		for i, info := range snapshot.Subscriptions() {
+			if info.Handler != args {
+				continue
+			}
+			if index >= 0 {
+				return "", fmt.Errorf("handler %q is ambiguous; detach by index", args)
+			}
+			index = i
+		}
+	}
+
+	sub := snapshot.Subscription(index)
+	if sub == nil {
+		return "", fmt.Errorf("no subscription %s", args)
+	}
+	sub.Cancel()
+	return fmt.Sprintf("detached %d", index), nil
+}
+
+// costs lists the n most expensive handlers, all of them if args is
+// empty. It requires handler cost measurement; see
+// eventbus.WithHandlerCosts.
+func (c *Console) costs(args string) (string, error) {
+	n := 0
+	if args != "" {
+		var err error
+		if n, err = strconv.Atoi(args); err != nil || n <= 0 {
+			return "", errors.New("usage: costs [n]")
+		}
+	}
+	costs := c.bus.Stats().HandlerCosts
+	if costs == nil {
+		return "", errors.New("handler costs are not measured; use eventbus.WithHandlerCosts")
+	}
+	var report strings.Builder
+	if err := eventbus.WriteHandlerCosts(&report, costs, n); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(report.String(), "\n"), nil
+}
+
+// graph exports the producer/consumer graph of the bus as JSON, the
+// default, or in the Graphviz DOT language.
+func (c *Console) graph(format string) (string, error) {
+	graph := c.bus.Graph()
+	var output strings.Builder
+	var err error
+	switch format {
+	case "", "json":
+		err = graph.WriteJSON(&output)
+	case "dot":
+		err = graph.WriteDOT(&output)
+	default:
+		return "", errors.New("usage: graph [json|dot]")
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(output.String(), "\n"), nil
+}
@@ -0,0 +1,271 @@
+package console
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// playerDied is an event registered for decoding injected JSON.
+type playerDied struct {
+	PlayerID string
+}
+
+func (e playerDied) GetType() eventbus.EventType {
+	return "player:died"
+}
+
+// TestTopicsAndSubs verifies that topics and subscriptions are listed
+func TestTopicsAndSubs(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	bus.Subscribe("player:moved", func(event eventbus.Event) {}, eventbus.WithName("physics"))
+	bus.Subscribe("player:moved", func(event eventbus.Event) {})
+	bus.Subscribe("game:over", func(event eventbus.Event) {})
+
+	console := New(bus, nil)
+	output, err := console.Exec("topics")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if output != "game:over\t1\nplayer:moved\t2" {
+		t.Errorf("Expected sorted topics with counts, got %q", output)
+	}
+
+	output, _ = console.Exec("subs player:moved")
+	if output != "0\tplayer:moved\tphysics\tok\n1\tplayer:moved\t-\tok" {
+		t.Errorf("Expected two player:moved subscriptions, got %q", output)
+	}
+}
+
+// TestInject verifies that injected JSON is decoded into registered types
+func TestInject(t *testing.T) {
+	types := eventbus.NewTypeRegistry()
+	eventbus.RegisterIn[playerDied](types)
+	bus := eventbus.New()
+	defer bus.Close()
+
+	var received eventbus.Event
+	bus.Subscribe("player:died", func(event eventbus.Event) { received = event })
+	bus.Subscribe("player:moved", func(event eventbus.Event) { received = event })
+
+	console := New(bus, types)
+	if _, err := console.Exec(`inject player:died {"PlayerID":"p1"}`); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if e, ok := received.(playerDied); !ok || e.PlayerID != "p1" {
+		t.Errorf("Expected playerDied{p1}, got %#v", received)
+	}
+
+	if _, err := console.Exec(`inject player:moved {"x":1}`); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := received.(eventbus.RawEvent); !ok {
+		t.Errorf("Expected an unregistered type to be injected as RawEvent, got %T", received)
+	}
+
+	if _, err := console.Exec(`inject player:died {`); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+// TestInjectUnknownTopic verifies that publish errors are returned instead of panicking
+func TestInjectUnknownTopic(t *testing.T) {
+	bus := eventbus.New(eventbus.WithKnownTopics(true, "player:died"))
+	defer bus.Close()
+
+	_, err := New(bus, nil).Exec(`inject player:jumped {}`)
+	if !errors.Is(err, eventbus.ErrUnknownTopic) {
+		t.Errorf("Expected ErrUnknownTopic, got %v", err)
+	}
+}
+
+// TestDetach verifies that subscriptions are cancelled by index or handler name
+func TestDetach(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	bus.Subscribe("player:moved", func(event eventbus.Event) {}, eventbus.WithName("physics"))
+	bus.Subscribe("player:moved", func(event eventbus.Event) {}, eventbus.WithName("audio"))
+	bus.Subscribe("player:moved", func(event eventbus.Event) {}, eventbus.WithName("audio"))
+
+	console := New(bus, nil)
+	if _, err := console.Exec("detach physics"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := console.Exec("detach audio"); err == nil {
+		t.Error("Expected an error for an ambiguous handler name")
+	}
+	if _, err := console.Exec("detach 1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := console.Exec("detach 5"); err == nil {
+		t.Error("Expected an error for an unknown index")
+	}
+
+	if n := len(bus.Snapshot().Subscriptions()); n != 1 {
+		t.Errorf("Expected 1 subscription left, got %d", n)
+	}
+}
+
+// TestCosts verifies that the most expensive handlers are listed
+func TestCosts(t *testing.T) {
+	bus := eventbus.New(eventbus.WithHandlerCosts())
+	defer bus.Close()
+	bus.Subscribe("player:moved", func(event eventbus.Event) {}, eventbus.WithName("physics"))
+	bus.Subscribe("player:died", func(event eventbus.Event) {}, eventbus.WithName("scoreboard"))
+	bus.Publish(eventbus.Of("player:moved", 1))
+	bus.Publish(eventbus.Of("player:died", 1))
+
+	console := New(bus, nil)
+	output, err := console.Exec("costs 1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lines := strings.Split(output, "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], "TOPIC") {
+		t.Errorf("Expected a header and 1 handler, got %q", output)
+	}
+	if _, err := console.Exec("costs x"); err == nil {
+		t.Error("Expected an error for an invalid count")
+	}
+}
+
+// TestGraph verifies that the flow graph is exported as JSON and DOT
+func TestGraph(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	bus.DeclarePublisher("combat", "player:died")
+	bus.Subscribe("player:died", func(event eventbus.Event) {}, eventbus.WithName("scoreboard"))
+
+	console := New(bus, nil)
+	output, err := console.Exec("graph")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(output, `"publisher": "combat"`) {
+		t.Errorf("Expected a JSON edge from combat, got %q", output)
+	}
+	output, _ = console.Exec("graph dot")
+	if !strings.HasPrefix(output, "digraph events {") || !strings.HasSuffix(output, "}") {
+		t.Errorf("Expected a DOT graph, got %q", output)
+	}
+	if _, err := console.Exec("graph svg"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+// TestMute verifies that topics are muted and soloed through the console
+func TestMute(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	count := 0
+	bus.Subscribe("player:moved", func(event eventbus.Event) { count++ })
+	bus.Subscribe("audio:played", func(event eventbus.Event) { count++ })
+
+	console := New(bus, nil)
+	for _, line := range []string{"mute player:*", "solo audio:*"} {
+		if _, err := console.Exec(line); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	bus.Publish(eventbus.Of("player:moved", 1))
+	if count != 0 {
+		t.Errorf("Expected no deliveries, got %d", count)
+	}
+
+	console.Exec("unmute player:*")
+	console.Exec("unsolo audio:*")
+	bus.Publish(eventbus.Of("player:moved", 1))
+	if count != 1 {
+		t.Errorf("Expected 1 delivery after unmuting, got %d", count)
+	}
+
+	if _, err := console.Exec("mute"); err == nil {
+		t.Error("Expected an error without a pattern")
+	}
+}
+
+// TestUnknownCommand verifies that unknown commands match ErrUnknownCommand
+func TestUnknownCommand(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+
+	if _, err := New(bus, nil).Exec("explode"); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("Expected ErrUnknownCommand, got %v", err)
+	}
+}
+
+// TestServeConn verifies that commands are read per line until quit
+func TestServeConn(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	bus.Subscribe("game:over", func(event eventbus.Event) {})
+
+	var output bytes.Buffer
+	rw := struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader("topics\n\nexplode\nquit\ntopics\n"), &output}
+	if err := New(bus, nil).ServeConn(rw); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "> game:over\t1\n> > error: console: unknown command \"explode\"\n> "
+	if output.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, output.String())
+	}
+}
+
+// TestServe verifies that the console is served over TCP
+func TestServe(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+	bus.Subscribe("game:over", func(event eventbus.Event) {})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("Cannot listen:", err)
+	}
+	defer listener.Close()
+	go New(bus, nil).Serve(listener)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("topics\nquit\n"))
+
+	var output bytes.Buffer
+	output.ReadFrom(conn)
+	if output.String() != "> game:over\t1\n> " {
+		t.Errorf("Expected the topics output, got %q", output.String())
+	}
+}
+
+// TestDescribe verifies that topic documentation and subscribers are shown
+func TestDescribe(t *testing.T) {
+	types := eventbus.NewTypeRegistry()
+	eventbus.RegisterIn[playerDied](types)
+	types.Document("player:died", eventbus.TopicInfo{
+		Description: "A player's health reached zero.",
+		Owner:       "gameplay",
+		Producers:   []string{"combat"},
+	})
+	bus := eventbus.New(eventbus.WithTypeRegistry(types))
+	defer bus.Close()
+	bus.Subscribe("player:died", func(event eventbus.Event) {}, eventbus.WithName("scoreboard"))
+
+	output, err := New(bus, types).Exec("describe player:died")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := "player:died\ndescription: A player's health reached zero.\nowner: gameplay\ngo type: console.playerDied\nproducers: combat\nsubscriber: scoreboard (ok)"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
@@ -0,0 +1,114 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+)
+
+// ContextListener is a listener that also receives a context. The context
+// carries the values selected with WithContextFields, taken from the
+// publisher's context, so identifiers such as request IDs survive
+// asynchronous delivery. It is not cancelled when the publisher's context is.
+type ContextListener func(ctx context.Context, event Event)
+
+// ContextField selects a context value to propagate from publishers to
+// listeners. Values travel as strings in the envelope metadata, so they can
+// also be persisted with the event.
+type ContextField struct {
+	// Name is the metadata key under which the value is recorded.
+	Name string
+	// Extract reads the value from the publisher's context.
+	// It reports false if the context has no value.
+	Extract func(ctx context.Context) (string, bool)
+	// Inject returns a copy of ctx carrying value, for listeners.
+	Inject func(ctx context.Context, value string) context.Context
+}
+
+// ContextValue returns a ContextField for a string value stored in the
+// context under key with context.WithValue.
+//
+// Example:
+//
+//	type requestIDKey struct{}
+//	field := eventbus.ContextValue("request-id", requestIDKey{})
+func ContextValue(name string, key any) ContextField {
+	return ContextField{
+		Name: name,
+		Extract: func(ctx context.Context) (string, bool) {
+			value, ok := ctx.Value(key).(string)
+			return value, ok
+		},
+		Inject: func(ctx context.Context, value string) context.Context {
+			return context.WithValue(ctx, key, value)
+		},
+	}
+}
+
+// WithContextFields records the given context values of every event
+// published with a context, by PublishContext, PublishAndWait, or
+// PublishDetailed, in the envelope metadata, and passes them to listeners
+// subscribed with SubscribeContext.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithContextFields(
+//	    eventbus.ContextValue("request-id", requestIDKey{}),
+//	    eventbus.ContextValue("user-id", userIDKey{}),
+//	))
+//
+//	bus.SubscribeContext("order:placed", func(ctx context.Context, event eventbus.Event) {
+//	    log.Println("request", ctx.Value(requestIDKey{}))
+//	})
+//	bus.PublishContext(r.Context(), OrderPlaced{ID: "o-1"})
+func WithContextFields(fields ...ContextField) Option {
+	return func(bus *eventBusImpl) {
+		bus.contextFields = append(bus.contextFields, fields...)
+	}
+}
+
+// SubscribeContext registers a listener that receives the propagated
+// context along with the event.
+// Its context is cancelled when the subscription is.
+func (bus *eventBusImpl) SubscribeContext(eventType EventType, listener ContextListener, opts ...SubscribeOption) *Subscription {
+	sub, err := bus.subscribe(eventType, listener, opts, true)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// PublishContext sends an event like Publish, recording the context values
+// selected with WithContextFields. It returns early if ctx is done while
+// waiting for room in an asynchronous queue.
+func (bus *eventBusImpl) PublishContext(ctx context.Context, event Event) {
+	if err := bus.publish(ctx, event, nil); errors.Is(err, ErrMutableEvent) || errors.Is(err, ErrUnknownTopic) || errors.Is(err, ErrPublishDepth) || errors.Is(err, ErrStoreAppend) {
+		panic(err)
+	}
+}
+
+// extractMetadata collects the configured context values of ctx.
+// It returns nil if there are none.
+func (bus *eventBusImpl) extractMetadata(ctx context.Context) map[string]string {
+	var metadata map[string]string
+	for _, field := range bus.contextFields {
+		if value, ok := field.Extract(ctx); ok {
+			if metadata == nil {
+				metadata = make(map[string]string, len(bus.contextFields))
+			}
+			metadata[field.Name] = value
+		}
+	}
+	return metadata
+}
+
+// listenerContext reconstructs the context passed to listeners from the
+// envelope metadata.
+func (bus *eventBusImpl) listenerContext(metadata map[string]string) context.Context {
+	ctx := context.Background()
+	for _, field := range bus.contextFields {
+		if value, ok := metadata[field.Name]; ok {
+			ctx = field.Inject(ctx, value)
+		}
+	}
+	return ctx
+}
@@ -0,0 +1,118 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+type userIDKey struct{}
+
+type unrelatedKey struct{}
+
+// newContextBus creates a bus propagating request and user IDs
+func newContextBus(opts ...Option) EventBus {
+	return New(append(opts, WithContextFields(
+		ContextValue("request-id", requestIDKey{}),
+		ContextValue("user-id", userIDKey{}),
+	))...)
+}
+
+// TestContextPropagationSync verifies that selected values reach context listeners
+func TestContextPropagationSync(t *testing.T) {
+	bus := newContextBus()
+	var requestID any
+	var other any
+
+	bus.SubscribeContext("order:placed", func(ctx context.Context, event Event) {
+		requestID = ctx.Value(requestIDKey{})
+		other = ctx.Value(unrelatedKey{})
+	})
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	ctx = context.WithValue(ctx, unrelatedKey{}, "secret")
+	bus.PublishContext(ctx, orderEvent{orderID: "o1"})
+
+	if requestID != "req-1" {
+		t.Errorf("Expected request ID req-1, got %v", requestID)
+	}
+	if other != nil {
+		t.Errorf("Expected unselected values to be dropped, got %v", other)
+	}
+}
+
+// TestContextPropagationAsync verifies that values survive the async boundary without cancellation
+func TestContextPropagationAsync(t *testing.T) {
+	bus := newContextBus(WithAsync(1, 4))
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	var userID any
+	var err error
+	bus.SubscribeContext("order:placed", func(ctx context.Context, event Event) {
+		defer wg.Done()
+		userID = ctx.Value(userIDKey{})
+		err = ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), userIDKey{}, "u-42"))
+	bus.PublishContext(ctx, orderEvent{orderID: "o1"})
+	cancel()
+
+	wg.Wait()
+	bus.Close()
+
+	if userID != "u-42" {
+		t.Errorf("Expected user ID u-42, got %v", userID)
+	}
+	if err != nil {
+		t.Errorf("Expected the listener context not to be cancelled, got %v", err)
+	}
+}
+
+// TestContextMetadataPersisted verifies that values are recorded in the envelope and history
+func TestContextMetadataPersisted(t *testing.T) {
+	store := NewMemoryStore()
+	bus := newContextBus(WithStore(store))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-7")
+	bus.PublishAndWait(ctx, orderEvent{orderID: "o1"})
+	bus.Publish(orderEvent{orderID: "o2"})
+
+	var buf bytes.Buffer
+	bus.History().Export(&buf)
+
+	imported := NewMemoryStore()
+	New(WithStore(imported)).History().Import(&buf)
+
+	var metadata []map[string]string
+	imported.Read(1, func(envelope Envelope) error {
+		metadata = append(metadata, envelope.Metadata)
+		return nil
+	})
+
+	if len(metadata) != 2 || metadata[0]["request-id"] != "req-7" || len(metadata[0]) != 1 {
+		t.Fatalf("Expected request-id metadata on the first envelope, got %v", metadata)
+	}
+	if metadata[1] != nil {
+		t.Errorf("Expected no metadata without a context, got %v", metadata[1])
+	}
+}
+
+// TestSubscribeContextWithoutFields verifies that context listeners work on a plain bus
+func TestSubscribeContextWithoutFields(t *testing.T) {
+	bus := New()
+	called := false
+
+	bus.SubscribeContext("order:placed", func(ctx context.Context, event Event) {
+		called = ctx != nil
+	})
+	bus.Publish(orderEvent{orderID: "o1"})
+
+	if !called {
+		t.Error("Expected the context listener to be called with a context")
+	}
+}
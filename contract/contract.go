@@ -0,0 +1,244 @@
+// Package contract checks that the events services produce still satisfy
+// the services consuming them, so a breaking change fails a test instead
+// of a deployment.
+//
+// Producers declare the event types they emit with a schema and sample
+// events; consumers declare the event types they handle with the schema
+// they can read and an optional check of decoded events. Verify checks
+// every consumer against the producers of its event types: the consumer
+// schema must accept everything the producer schema allows, and every
+// sample must survive a round trip through the codecs and pass both
+// schemas and the consumer's check.
+//
+// Declarations usually live next to the services, so each module exports
+// its Producer or Consumer and a single test verifies them together:
+//
+//	func TestEventContracts(t *testing.T) {
+//	    suite := contract.New(codecs)
+//	    suite.Produce(orders.Contract())
+//	    suite.Consume(billing.Contract())
+//	    suite.Consume(shipping.Contract())
+//	    suite.Test(t)
+//	}
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+	"github.com/Papiermond/eventbus/schema"
+)
+
+// Errors matched by violations.
+var (
+	// ErrNoProducer is matched by violations for expected event types
+	// no producer emits.
+	ErrNoProducer = errors.New("contract: no producer")
+
+	// ErrRoundTrip is matched by violations for samples that cannot be
+	// encoded and decoded with the codecs.
+	ErrRoundTrip = errors.New("contract: codec round trip failed")
+
+	// ErrRejected is matched by violations for samples the consumer's
+	// check rejects.
+	ErrRejected = errors.New("contract: sample rejected by consumer")
+)
+
+// Producer declares the events a service emits.
+type Producer struct {
+	// Service names the producing service in violations.
+	Service string
+	Events  []Produced
+}
+
+// Produced declares an event type a producer emits.
+type Produced struct {
+	EventType eventbus.EventType
+	// Schema describes the payloads the producer may emit. It may be nil
+	// if the event type has no schema.
+	Schema schema.Schema
+	// Samples are representative events, which are round-tripped
+	// through the codecs and checked against the schemas.
+	Samples []eventbus.Event
+}
+
+// Consumer declares the events a service handles.
+type Consumer struct {
+	// Service names the consuming service in violations.
+	Service string
+	Expects []Expected
+}
+
+// Expected declares an event type a consumer handles.
+type Expected struct {
+	EventType eventbus.EventType
+	// Schema describes the payloads the consumer can read. It may be nil
+	// to skip the schema checks.
+	Schema schema.Schema
+	// Check inspects a decoded sample, for example by decoding it into
+	// the consumer's own struct. It may be nil.
+	Check func(eventbus.Event) error
+	// Optional expectations do not require a producer, for events that
+	// come from outside the verified services.
+	Optional bool
+}
+
+// Violation describes a broken contract between a producer and a
+// consumer.
+type Violation struct {
+	EventType eventbus.EventType
+	// Producer and Consumer name the services; Producer is empty for
+	// event types without a producer.
+	Producer string
+	Consumer string
+	// Sample is the index of the failing sample, or -1 if the violation
+	// is not about a sample.
+	Sample int
+	Err    error
+}
+
+// Error describes the violation.
+func (v *Violation) Error() string {
+	if v.Sample >= 0 {
+		return fmt.Sprintf("%s -> %s, %s sample %d: %v", v.Producer, v.Consumer, v.EventType, v.Sample, v.Err)
+	}
+	if v.Producer == "" {
+		return fmt.Sprintf("%s, %s: %v", v.Consumer, v.EventType, v.Err)
+	}
+	return fmt.Sprintf("%s -> %s, %s: %v", v.Producer, v.Consumer, v.EventType, v.Err)
+}
+
+// Unwrap returns the underlying error.
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// Suite collects producers and consumers and verifies their contracts.
+type Suite struct {
+	codecs    *eventbus.CodecRegistry
+	producers []Producer
+	consumers []Consumer
+}
+
+// New creates a suite round-tripping samples through codecs. A nil
+// registry uses eventbus.JSONCodec for every event type.
+func New(codecs *eventbus.CodecRegistry) *Suite {
+	if codecs == nil {
+		codecs = eventbus.NewCodecRegistry(nil)
+	}
+	return &Suite{codecs: codecs}
+}
+
+// Produce adds a producer.
+func (s *Suite) Produce(producer Producer) {
+	s.producers = append(s.producers, producer)
+}
+
+// Consume adds a consumer.
+func (s *Suite) Consume(consumer Consumer) {
+	s.consumers = append(s.consumers, consumer)
+}
+
+// Verify checks every consumer expectation against the producers of its
+// event type and returns the violations.
+func (s *Suite) Verify() []*Violation {
+	var violations []*Violation
+	for _, consumer := range s.consumers {
+		for _, expected := range consumer.Expects {
+			found := false
+			for _, producer := range s.producers {
+				for _, produced := range producer.Events {
+					if produced.EventType != expected.EventType {
+						continue
+					}
+					found = true
+					violations = append(violations, s.check(producer.Service, produced, consumer.Service, expected)...)
+				}
+			}
+			if !found && !expected.Optional {
+				violations = append(violations, &Violation{
+					EventType: expected.EventType,
+					Consumer:  consumer.Service,
+					Sample:    -1,
+					Err:       ErrNoProducer,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// Test reports every violation as a test error.
+func (s *Suite) Test(t testing.TB) {
+	t.Helper()
+	for _, violation := range s.Verify() {
+		t.Error(violation)
+	}
+}
+
+// check verifies one producer declaration against one consumer
+// expectation.
+func (s *Suite) check(producer string, produced Produced, consumer string, expected Expected) []*Violation {
+	var violations []*Violation
+	violation := func(sample int, err error) {
+		violations = append(violations, &Violation{
+			EventType: expected.EventType,
+			Producer:  producer,
+			Consumer:  consumer,
+			Sample:    sample,
+			Err:       err,
+		})
+	}
+
+	if expected.Schema != nil && produced.Schema != nil {
+		if produced.Schema.Format() != expected.Schema.Format() {
+			violation(-1, fmt.Errorf("%w: producer uses %s, consumer uses %s", schema.ErrIncompatible,
+				produced.Schema.Format(), expected.Schema.Format()))
+		} else if err := expected.Schema.CheckBackward(produced.Schema); err != nil {
+			violation(-1, err)
+		}
+	}
+
+	for i, sample := range produced.Samples {
+		if err := s.checkSample(sample, produced, expected); err != nil {
+			violation(i, err)
+		}
+	}
+	return violations
+}
+
+// checkSample round-trips sample through the codecs and checks it against
+// both schemas and the consumer's check.
+func (s *Suite) checkSample(sample eventbus.Event, produced Produced, expected Expected) error {
+	if sample.GetType() != produced.EventType {
+		return fmt.Errorf("sample has type %s", sample.GetType())
+	}
+
+	data, err := s.codecs.Encode(sample)
+	if err != nil {
+		return fmt.Errorf("%w: encoding: %w", ErrRoundTrip, err)
+	}
+	for _, sch := range []schema.Schema{produced.Schema, expected.Schema} {
+		if validator, ok := sch.(schema.Validator); ok {
+			if err := validator.Validate(data); err != nil {
+				return err
+			}
+		}
+	}
+
+	decoded, err := s.codecs.Decode(produced.EventType, data)
+	if err != nil {
+		return fmt.Errorf("%w: decoding: %w", ErrRoundTrip, err)
+	}
+	if decoded.GetType() != produced.EventType {
+		return fmt.Errorf("%w: decoded event has type %s", ErrRoundTrip, decoded.GetType())
+	}
+	if expected.Check != nil {
+		if err := expected.Check(decoded); err != nil {
+			return fmt.Errorf("%w: %w", ErrRejected, err)
+		}
+	}
+	return nil
+}
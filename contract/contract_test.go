@@ -0,0 +1,142 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+	"github.com/Papiermond/eventbus/schema"
+)
+
+type orderPlaced struct {
+	ID    string  `json:"id"`
+	Total float64 `json:"total"`
+}
+
+func (orderPlaced) GetType() eventbus.EventType { return "order:placed" }
+
+// mustSchema parses a JSON Schema or fails the test.
+func mustSchema(t *testing.T, doc string) schema.Schema {
+	t.Helper()
+	s, err := schema.ParseJSONSchema([]byte(doc))
+	if err != nil {
+		t.Fatalf("Expected a valid schema, got %v", err)
+	}
+	return s
+}
+
+// producer returns an orders producer emitting orderPlaced with sch.
+func producer(sch schema.Schema, samples ...eventbus.Event) Producer {
+	return Producer{Service: "orders", Events: []Produced{{
+		EventType: "order:placed",
+		Schema:    sch,
+		Samples:   samples,
+	}}}
+}
+
+// TestVerifyCompatible verifies that matching contracts produce no violations
+func TestVerifyCompatible(t *testing.T) {
+	suite := New(nil)
+	suite.Produce(producer(
+		mustSchema(t, `{"type": "object", "properties": {"id": {"type": "string"}, "total": {"type": "number"}}, "required": ["id", "total"]}`),
+		orderPlaced{ID: "o-1", Total: 9.5},
+	))
+	suite.Consume(Consumer{Service: "billing", Expects: []Expected{{
+		EventType: "order:placed",
+		Schema:    mustSchema(t, `{"type": "object", "properties": {"total": {"type": "number"}}, "required": ["total"]}`),
+		Check: func(event eventbus.Event) error {
+			var order orderPlaced
+			return event.(eventbus.RawEvent).Decode(&order)
+		},
+	}}})
+
+	if violations := suite.Verify(); len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+	suite.Test(t)
+}
+
+// TestVerifyIncompatibleSchema verifies that a consumer requiring an unproduced field is reported
+func TestVerifyIncompatibleSchema(t *testing.T) {
+	suite := New(nil)
+	suite.Produce(producer(mustSchema(t, `{"type": "object", "properties": {"id": {"type": "string"}}}`)))
+	suite.Consume(Consumer{Service: "shipping", Expects: []Expected{{
+		EventType: "order:placed",
+		Schema:    mustSchema(t, `{"type": "object", "required": ["address"]}`),
+	}}})
+
+	violations := suite.Verify()
+	if len(violations) != 1 || !errors.Is(violations[0], schema.ErrIncompatible) {
+		t.Fatalf("Expected an incompatible schema, got %v", violations)
+	}
+	if violations[0].Producer != "orders" || violations[0].Consumer != "shipping" {
+		t.Errorf("Expected orders -> shipping, got %s -> %s", violations[0].Producer, violations[0].Consumer)
+	}
+}
+
+// TestVerifySamples verifies that samples are checked against the schemas and the consumer
+func TestVerifySamples(t *testing.T) {
+	suite := New(nil)
+	suite.Produce(producer(
+		mustSchema(t, `{"type": "object", "properties": {"id": {"type": "string"}}}`),
+		orderPlaced{ID: "o-1", Total: 1},
+		orderPlaced{ID: "", Total: 2},
+	))
+	suite.Consume(Consumer{Service: "billing", Expects: []Expected{{
+		EventType: "order:placed",
+		Check: func(event eventbus.Event) error {
+			var order orderPlaced
+			if err := event.(eventbus.RawEvent).Decode(&order); err != nil {
+				return err
+			}
+			if order.ID == "" {
+				return errors.New("missing id")
+			}
+			return nil
+		},
+	}}})
+
+	violations := suite.Verify()
+	if len(violations) != 1 || violations[0].Sample != 1 || !errors.Is(violations[0], ErrRejected) {
+		t.Errorf("Expected sample 1 to be rejected, got %v", violations)
+	}
+}
+
+// TestVerifyNoProducer verifies that expectations without a producer are reported unless optional
+func TestVerifyNoProducer(t *testing.T) {
+	suite := New(nil)
+	suite.Consume(Consumer{Service: "audit", Expects: []Expected{
+		{EventType: "user:deleted"},
+		{EventType: "payment:settled", Optional: true},
+	}})
+
+	violations := suite.Verify()
+	if len(violations) != 1 || !errors.Is(violations[0], ErrNoProducer) || violations[0].EventType != "user:deleted" {
+		t.Errorf("Expected a missing producer for user:deleted, got %v", violations)
+	}
+}
+
+// failingCodec refuses to encode events.
+type failingCodec struct{}
+
+func (failingCodec) Encode(event eventbus.Event) ([]byte, error) {
+	return nil, errors.New("unsupported")
+}
+
+func (failingCodec) Decode(eventType eventbus.EventType, data []byte) (eventbus.Event, error) {
+	return nil, errors.New("unsupported")
+}
+
+// TestVerifyRoundTrip verifies that samples the codec cannot carry are reported
+func TestVerifyRoundTrip(t *testing.T) {
+	codecs := eventbus.NewCodecRegistry(nil)
+	codecs.Register("order:placed", failingCodec{})
+	suite := New(codecs)
+	suite.Produce(producer(nil, orderPlaced{ID: "o-1"}))
+	suite.Consume(Consumer{Service: "billing", Expects: []Expected{{EventType: "order:placed"}}})
+
+	violations := suite.Verify()
+	if len(violations) != 1 || !errors.Is(violations[0], ErrRoundTrip) {
+		t.Errorf("Expected a round trip failure, got %v", violations)
+	}
+}
@@ -0,0 +1,176 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// Cloner is implemented by events that know how to copy themselves.
+// WithCopyOnPublish uses it instead of the reflection-based deep copy.
+type Cloner interface {
+	// Clone returns a deep copy of the event.
+	Clone() Event
+}
+
+// WithCopyOnPublish gives every listener its own deep copy of the event,
+// so a listener mutating the event, or data it points to, cannot race with
+// other listeners or with the publisher. Events are also copied when
+// published, so the publisher may reuse them after Publish returns.
+//
+// Events implementing Cloner are copied with Clone. Other events are copied
+// by reflection, including unexported fields; channels, functions, and
+// unsafe pointers are shared. Events without pointers, slices, maps, or
+// interfaces are passed as is, since Go copies them anyway.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithCopyOnPublish())
+func WithCopyOnPublish() Option {
+	return func(bus *eventBusImpl) {
+		bus.copyOnPublish = true
+	}
+}
+
+// copying returns a listener that calls listener with a copy of the event.
+func copying(listener ContextListener) ContextListener {
+	return func(ctx context.Context, event Event) {
+		listener(ctx, deepCopy(event))
+	}
+}
+
+// deepCopy returns a deep copy of event.
+func deepCopy(event Event) Event {
+	if cloner, ok := event.(Cloner); ok {
+		return cloner.Clone()
+	}
+	if event == nil || !needsCopy(reflect.TypeOf(event)) {
+		return event
+	}
+
+	copier := deepCopier{seen: make(map[uintptr]reflect.Value)}
+	return copier.copy(reflect.ValueOf(event)).Interface().(Event)
+}
+
+// copyNeeded caches needsCopy results per type.
+var copyNeeded sync.Map
+
+// needsCopy reports whether values of t can share memory with their copies.
+func needsCopy(t reflect.Type) bool {
+	if needed, ok := copyNeeded.Load(t); ok {
+		return needed.(bool)
+	}
+	needed := referencesMemory(t, make(map[reflect.Type]bool))
+	copyNeeded.Store(t, needed)
+	return needed
+}
+
+// referencesMemory reports whether t contains pointers, slices, maps,
+// or interfaces. visiting guards against recursive types.
+func referencesMemory(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	case reflect.Array:
+		return referencesMemory(t.Elem(), visiting)
+	case reflect.Struct:
+		if visiting[t] {
+			return false
+		}
+		visiting[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			if referencesMemory(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deepCopier copies values recursively. seen maps the addresses of copied
+// pointers to their copies, which preserves shared pointers and cycles.
+type deepCopier struct {
+	seen map[uintptr]reflect.Value
+}
+
+// copy returns a deep copy of v.
+func (c *deepCopier) copy(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	c.copyInto(out, v)
+	return out
+}
+
+// copyInto deep copies src into the settable value dst.
+func (c *deepCopier) copyInto(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		if copied, ok := c.seen[src.Pointer()]; ok {
+			dst.Set(copied)
+			return
+		}
+		copied := reflect.New(src.Type().Elem())
+		c.seen[src.Pointer()] = copied
+		c.copyInto(copied.Elem(), src.Elem())
+		dst.Set(copied)
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(c.copy(src.Elem()))
+
+	case reflect.Struct:
+		dst.Set(src)
+		if !needsCopy(src.Type()) {
+			return
+		}
+		// Work on an addressable copy so unexported fields can be read.
+		addressable := reflect.New(src.Type()).Elem()
+		addressable.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			c.copyInto(settable(dst.Field(i)), settable(addressable.Field(i)))
+		}
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		copied := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			c.copyInto(copied.Index(i), src.Index(i))
+		}
+		dst.Set(copied)
+
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copyInto(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		copied := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(c.copy(iter.Key()), c.copy(iter.Value()))
+		}
+		dst.Set(copied)
+
+	default:
+		dst.Set(src)
+	}
+}
+
+// settable returns a settable view of the addressable field v, which may
+// be unexported.
+func settable(v reflect.Value) reflect.Value {
+	if v.CanSet() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
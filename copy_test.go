@@ -0,0 +1,131 @@
+package eventbus
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type inventoryEvent struct {
+	items  []string
+	counts map[string]int
+	owner  *inventoryOwner
+}
+
+type inventoryOwner struct {
+	Name string
+	Self *inventoryOwner
+}
+
+func (e inventoryEvent) GetType() EventType {
+	return "inventory:changed"
+}
+
+type clonedEvent struct {
+	clones *int
+}
+
+func (e clonedEvent) GetType() EventType {
+	return "cloned:event"
+}
+
+func (e clonedEvent) Clone() Event {
+	*e.clones++
+	return e
+}
+
+// TestCopyOnPublishIsolatesListeners verifies that listeners cannot see each other's mutations
+func TestCopyOnPublishIsolatesListeners(t *testing.T) {
+	bus := New(WithCopyOnPublish())
+	var seen []string
+
+	for i := 0; i < 2; i++ {
+		bus.Subscribe("inventory:changed", func(event Event) {
+			e := event.(inventoryEvent)
+			seen = append(seen, e.items[0], e.owner.Name)
+			e.items[0] = "mutated"
+			e.counts["sword"] = 99
+			e.owner.Name = "mutated"
+		})
+	}
+
+	owner := &inventoryOwner{Name: "alice"}
+	owner.Self = owner
+	event := inventoryEvent{items: []string{"sword"}, counts: map[string]int{"sword": 1}, owner: owner}
+	bus.Publish(event)
+
+	if len(seen) != 4 || seen[2] != "sword" || seen[3] != "alice" {
+		t.Errorf("Expected the second listener to see unmodified data, got %v", seen)
+	}
+	if event.items[0] != "sword" || event.counts["sword"] != 1 || owner.Name != "alice" {
+		t.Error("Expected the publisher's event to be unmodified")
+	}
+}
+
+// TestCopyOnPublishPreservesCycles verifies that shared and cyclic pointers are copied once
+func TestCopyOnPublishPreservesCycles(t *testing.T) {
+	owner := &inventoryOwner{Name: "alice"}
+	owner.Self = owner
+
+	copied := deepCopy(inventoryEvent{owner: owner}).(inventoryEvent)
+
+	if copied.owner == owner {
+		t.Fatal("Expected the owner to be copied")
+	}
+	if copied.owner.Self != copied.owner {
+		t.Error("Expected the cycle to point at the copy")
+	}
+}
+
+// TestCopyOnPublishUsesCloner verifies that Clone is preferred over reflection
+func TestCopyOnPublishUsesCloner(t *testing.T) {
+	bus := New(WithCopyOnPublish())
+	clones := 0
+	bus.Subscribe("cloned:event", func(event Event) {})
+	bus.Subscribe("cloned:event", func(event Event) {})
+
+	bus.Publish(clonedEvent{clones: &clones})
+
+	if clones != 3 {
+		t.Errorf("Expected 3 clones (publish plus one per listener), got %d", clones)
+	}
+}
+
+// TestCopyOnPublishAsync verifies that the publisher can reuse an event after Publish
+func TestCopyOnPublishAsync(t *testing.T) {
+	bus := New(WithAsync(1, 4), WithCopyOnPublish())
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	block := make(chan struct{})
+	var received string
+	bus.Subscribe("inventory:changed", func(event Event) {
+		<-block
+		received = event.(inventoryEvent).items[0]
+		wg.Done()
+	})
+
+	event := inventoryEvent{items: []string{"sword"}}
+	bus.Publish(event)
+	event.items[0] = "shield"
+	close(block)
+
+	wg.Wait()
+	bus.Close()
+
+	if received != "sword" {
+		t.Errorf("Expected the listener to see the published value, got %q", received)
+	}
+}
+
+// TestDeepCopyValueEvents verifies that pointer-free events are passed as is
+func TestDeepCopyValueEvents(t *testing.T) {
+	event := testEvent{eventType: "value:event", data: "unchanged"}
+
+	if deepCopy(event) != Event(event) {
+		t.Error("Expected a pointer-free event to be returned unchanged")
+	}
+	if needsCopy(reflect.TypeOf(event)) {
+		t.Error("Expected testEvent to need no copy")
+	}
+}
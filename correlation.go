@@ -0,0 +1,64 @@
+package eventbus
+
+import "context"
+
+// WaitForCorrelated waits for the next event of eventType whose envelope
+// correlation ID is correlationID, taken from events implementing
+// Correlated. It returns the context error if ctx is done first, or
+// ErrBusClosed if the bus is closed.
+//
+// Only events published after the call starts are considered. When the
+// response may be published before the request returns, for example by a
+// synchronous listener, register the wait first with ExpectCorrelated.
+//
+// Example:
+//
+//	// Forwarded to the pricing service, whose answer returns over a bridge
+//	bus.Publish(QuoteRequested{RequestID: "req-42", Items: items})
+//	response, err := bus.WaitForCorrelated(ctx, "req-42", "quote:calculated")
+func (bus *eventBusImpl) WaitForCorrelated(ctx context.Context, correlationID string, eventType EventType) (Event, error) {
+	bus.mutex.Lock()
+	closed := bus.closed
+	bus.mutex.Unlock()
+	if closed {
+		return nil, ErrBusClosed
+	}
+
+	return ExpectCorrelated(bus, correlationID, eventType)(ctx)
+}
+
+// ExpectCorrelated starts waiting for the next event of eventType whose
+// envelope correlation ID is correlationID and returns a function blocking
+// until it arrives or ctx is done. Events published between the two calls
+// are not missed. The wait ends once the function returns; it must be
+// called exactly once.
+//
+// Example:
+//
+//	wait := eventbus.ExpectCorrelated(bus, "req-42", "quote:calculated")
+//	bus.Publish(QuoteRequested{RequestID: "req-42", Items: items})
+//	response, err := wait(ctx)
+func ExpectCorrelated(bus EventBus, correlationID string, eventType EventType) func(ctx context.Context) (Event, error) {
+	matched := make(chan Event, 1)
+	sub := bus.SubscribeContext(eventType, func(ctx context.Context, event Event) {
+		envelope, _ := EnvelopeFromContext(ctx)
+		if envelope.CorrelationID != correlationID {
+			return
+		}
+		select {
+		case matched <- event:
+		default:
+		}
+	}, WithName("wait-for-correlated"))
+
+	return func(ctx context.Context) (Event, error) {
+		defer sub.Cancel()
+
+		select {
+		case event := <-matched:
+			return event, nil
+		case <-ctx.Done():
+			return nil, contextError(ctx)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestWaitForCorrelated verifies that the response matching the correlation ID is returned among interleaved ones
+func TestWaitForCorrelated(t *testing.T) {
+	bus := New(WithAsync(2, 16))
+	defer bus.Close()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		bus.Publish(orderEvent{orderID: "o2", amount: 1})
+		bus.Publish(testEvent{eventType: "order:placed"})
+		bus.Publish(orderEvent{orderID: "o1", amount: 2})
+		bus.Publish(orderEvent{orderID: "o1", amount: 3})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	event, err := bus.WaitForCorrelated(ctx, "o1", "order:placed")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if order, ok := event.(orderEvent); !ok || order.amount != 2 {
+		t.Errorf("Expected the first o1 order, got %#v", event)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if count := len(bus.Snapshot().Subscriptions()); count != 0 {
+		t.Errorf("Expected the wait to unsubscribe, got %d subscriptions", count)
+	}
+}
+
+// TestWaitForCorrelatedTimeout verifies that the wait ends with the context
+func TestWaitForCorrelatedTimeout(t *testing.T) {
+	bus := New()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	bus.Publish(orderEvent{orderID: "o1"})
+	if _, err := bus.WaitForCorrelated(ctx, "o1", "order:placed"); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout for an event published before waiting, got %v", err)
+	}
+
+	bus.Close()
+	if _, err := bus.WaitForCorrelated(context.Background(), "o1", "order:placed"); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Expected ErrBusClosed, got %v", err)
+	}
+}
+
+// TestExpectCorrelated verifies that a response published before waiting is not missed
+func TestExpectCorrelated(t *testing.T) {
+	bus := New(WithAsync(2, 16))
+	defer bus.Close()
+
+	bus.Subscribe("quote:requested", func(event Event) {
+		bus.Publish(orderEvent{orderID: "q1", amount: 99})
+	})
+
+	wait := ExpectCorrelated(bus, "q1", "order:placed")
+	bus.Publish(testEvent{eventType: "quote:requested"})
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	event, err := wait(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if order := event.(orderEvent); order.amount != 99 {
+		t.Errorf("Expected amount 99, got %d", order.amount)
+	}
+}
@@ -0,0 +1,195 @@
+package eventbus
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// HandlerCost is the resource usage of one handler, aggregated over its
+// invocations. See WithHandlerCosts.
+type HandlerCost struct {
+	// EventType is the topic or pattern the handler subscribed to.
+	EventType EventType
+	// Handler is the label given with WithName, or "" if there is none.
+	Handler string
+	// Calls is the number of invocations.
+	Calls uint64
+	// Time is the total wall-clock time of the invocations.
+	Time time.Duration
+	// CPUTime is the total CPU time of the invocations. It is zero on
+	// platforms without per-thread CPU clocks.
+	CPUTime time.Duration
+	// AllocBytes and Allocs are the heap bytes and objects allocated
+	// during the invocations.
+	AllocBytes uint64
+	Allocs     uint64
+}
+
+// WithHandlerCosts measures the CPU time and heap allocations of every
+// listener invocation and aggregates them per handler label and topic,
+// reported in Stats.HandlerCosts, to find the subscribers worth
+// optimizing. Builds with the eventbus_debug tag enable it on every bus.
+//
+// Measuring reads the runtime memory statistics, which briefly stops the
+// world, before and after each invocation, so it is meant for debugging
+// and profiling sessions rather than production. Allocations are counted
+// for the whole process: they are exact for handlers running alone, and
+// include the allocations of other goroutines otherwise. Costs include
+// the listeners of events published synchronously from within a handler.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithHandlerCosts())
+//	// ...
+//	eventbus.WriteHandlerCosts(os.Stderr, bus.Stats().HandlerCosts, 10)
+func WithHandlerCosts() Option {
+	return func(bus *eventBusImpl) {
+		bus.costs = newCostTracker()
+	}
+}
+
+// costKey identifies the handler a cost is aggregated for.
+type costKey struct {
+	eventType EventType
+	handler   string
+}
+
+// costTracker aggregates the costs of every handler invoked.
+type costTracker struct {
+	mutex    sync.Mutex
+	handlers map[costKey]*HandlerCost
+}
+
+// newCostTracker returns a tracker for WithHandlerCosts.
+func newCostTracker() *costTracker {
+	return &costTracker{handlers: make(map[costKey]*HandlerCost)}
+}
+
+// newDebugCostTracker returns the default tracker, which only measures in
+// debug builds.
+func newDebugCostTracker() *costTracker {
+	if !debugBuild {
+		return nil
+	}
+	return newCostTracker()
+}
+
+// costSample holds the counters read before an invocation.
+type costSample struct {
+	start  time.Time
+	cpu    time.Duration
+	bytes  uint64
+	allocs uint64
+}
+
+// begin pins the calling goroutine to its thread, so the thread CPU clock
+// measures it alone, and reads the counters.
+func (t *costTracker) begin() costSample {
+	runtime.LockOSThread()
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	cpu, _ := threadCPUTime()
+	return costSample{
+		start:  time.Now(),
+		cpu:    cpu,
+		bytes:  memory.TotalAlloc,
+		allocs: memory.Mallocs,
+	}
+}
+
+// end reads the counters after an invocation of s started at sample and
+// adds the difference to the cost of s.
+func (t *costTracker) end(s *Subscription, sample costSample) {
+	elapsed := time.Since(sample.start)
+	cpu, ok := threadCPUTime()
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	runtime.UnlockOSThread()
+
+	key := costKey{eventType: s.eventType, handler: s.name}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	cost, found := t.handlers[key]
+	if !found {
+		cost = &HandlerCost{EventType: key.eventType, Handler: key.handler}
+		t.handlers[key] = cost
+	}
+	cost.Calls++
+	cost.Time += elapsed
+	if ok {
+		cost.CPUTime += cpu - sample.cpu
+	}
+	cost.AllocBytes += memory.TotalAlloc - sample.bytes
+	cost.Allocs += memory.Mallocs - sample.allocs
+}
+
+// snapshot returns the costs of every handler, most expensive first.
+func (t *costTracker) snapshot() []HandlerCost {
+	t.mutex.Lock()
+	costs := make([]HandlerCost, 0, len(t.handlers))
+	for _, cost := range t.handlers {
+		costs = append(costs, *cost)
+	}
+	t.mutex.Unlock()
+
+	slices.SortFunc(costs, func(a, b HandlerCost) int {
+		return cmp.Or(
+			cmp.Compare(b.CPUTime, a.CPUTime),
+			cmp.Compare(b.Time, a.Time),
+			cmp.Compare(b.AllocBytes, a.AllocBytes),
+			cmp.Compare(a.EventType, b.EventType),
+			cmp.Compare(a.Handler, b.Handler),
+		)
+	})
+	return costs
+}
+
+// WriteHandlerCosts writes a table of the n most expensive handlers in
+// costs, or all of them if n is not positive, with their totals and
+// per-call averages. costs are expected in the order of
+// Stats.HandlerCosts.
+//
+// Example output:
+//
+//	TOPIC         HANDLER     CALLS  CPU   CPU/CALL   TIME   ALLOC    ALLOCS/CALL
+//	player:moved  physics     9000   1.2s  133.333µs  1.3s   48.0MiB  12
+//	player:died   scoreboard  40     3ms   75µs       3.1ms  12.0KiB  4
+func WriteHandlerCosts(w io.Writer, costs []HandlerCost, n int) error {
+	if n > 0 && n < len(costs) {
+		costs = costs[:n]
+	}
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TOPIC\tHANDLER\tCALLS\tCPU\tCPU/CALL\tTIME\tALLOC\tALLOCS/CALL")
+	for _, cost := range costs {
+		handler := cost.Handler
+		if handler == "" {
+			handler = "-"
+		}
+		calls := max(cost.Calls, 1)
+		fmt.Fprintf(table, "%s\t%s\t%d\t%v\t%v\t%v\t%s\t%d\n",
+			cost.EventType, handler, cost.Calls,
+			cost.CPUTime, cost.CPUTime/time.Duration(calls),
+			cost.Time, formatBytes(cost.AllocBytes), cost.Allocs/calls)
+	}
+	return table.Flush()
+}
+
+// formatBytes formats a byte count with a binary unit.
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	value, prefix := float64(bytes)/unit, 0
+	for value >= unit && prefix < 3 {
+		value /= unit
+		prefix++
+	}
+	return fmt.Sprintf("%.1f%ciB", value, "KMGT"[prefix])
+}
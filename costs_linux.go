@@ -0,0 +1,19 @@
+package eventbus
+
+import (
+	"syscall"
+	"time"
+)
+
+// rusageThread is RUSAGE_THREAD, which syscall does not define.
+const rusageThread = 1
+
+// threadCPUTime returns the user and system CPU time of the calling
+// thread.
+func threadCPUTime() (time.Duration, bool) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(rusageThread, &usage); err != nil {
+		return 0, false
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), true
+}
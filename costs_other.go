@@ -0,0 +1,10 @@
+//go:build !linux
+
+package eventbus
+
+import "time"
+
+// threadCPUTime reports that per-thread CPU time is not available.
+func threadCPUTime() (time.Duration, bool) {
+	return 0, false
+}
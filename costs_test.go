@@ -0,0 +1,109 @@
+package eventbus
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// costSink keeps test allocations on the heap.
+var costSink [][]byte
+
+// TestHandlerCosts verifies that calls, time and allocations are aggregated per handler
+func TestHandlerCosts(t *testing.T) {
+	bus := New(WithHandlerCosts())
+	defer bus.Close()
+
+	bus.Subscribe("asset:loaded", func(event Event) {
+		costSink = append(costSink[:0], make([]byte, 64*1024))
+	}, WithName("decoder"))
+	bus.Subscribe("asset:loaded", func(event Event) {}, WithName("logger"))
+	for range 3 {
+		bus.Publish(Of("asset:loaded", 1))
+	}
+
+	costs := bus.Stats().HandlerCosts
+	if len(costs) != 2 {
+		t.Fatalf("Expected costs for 2 handlers, got %+v", costs)
+	}
+	byName := map[string]HandlerCost{costs[0].Handler: costs[0], costs[1].Handler: costs[1]}
+	decoder, logger := byName["decoder"], byName["logger"]
+	if decoder.Calls != 3 || decoder.EventType != "asset:loaded" {
+		t.Errorf("Expected 3 decoder calls on asset:loaded, got %+v", decoder)
+	}
+	if decoder.AllocBytes < 3*64*1024 || decoder.Allocs < 3 {
+		t.Errorf("Expected at least 192KiB in 3 allocations, got %d bytes in %d", decoder.AllocBytes, decoder.Allocs)
+	}
+	if logger.AllocBytes != 0 {
+		t.Errorf("Expected the logger not to allocate, got %d bytes", logger.AllocBytes)
+	}
+}
+
+// TestHandlerCostsCPU verifies that busy handlers are reported first
+func TestHandlerCostsCPU(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("thread CPU time is only measured on Linux")
+	}
+	bus := New(WithHandlerCosts())
+	defer bus.Close()
+
+	bus.Subscribe("tick", func(event Event) {
+		for start := time.Now(); time.Since(start) < 20*time.Millisecond; {
+		}
+	}, WithName("spinner"))
+	bus.Subscribe("tick", func(event Event) {
+		time.Sleep(20 * time.Millisecond)
+	}, WithName("sleeper"))
+	bus.Publish(Of("tick", 1))
+
+	costs := bus.Stats().HandlerCosts
+	if len(costs) != 2 || costs[0].Handler != "spinner" {
+		t.Fatalf("Expected the spinner first, got %+v", costs)
+	}
+	if costs[0].CPUTime < 10*time.Millisecond {
+		t.Errorf("Expected the spinner to use CPU, got %v", costs[0].CPUTime)
+	}
+	if sleeper := costs[1]; sleeper.CPUTime > 10*time.Millisecond || sleeper.Time < 20*time.Millisecond {
+		t.Errorf("Expected the sleeper to take time without CPU, got %+v", sleeper)
+	}
+}
+
+// TestHandlerCostsDisabled verifies that Stats has no costs without WithHandlerCosts
+func TestHandlerCostsDisabled(t *testing.T) {
+	if debugBuild {
+		t.Skip("debug builds measure handler costs by default")
+	}
+	bus := New()
+	defer bus.Close()
+	bus.Subscribe("tick", func(event Event) {})
+	bus.Publish(Of("tick", 1))
+
+	if costs := bus.Stats().HandlerCosts; costs != nil {
+		t.Errorf("Expected no costs, got %v", costs)
+	}
+}
+
+// TestWriteHandlerCosts verifies the top offenders table
+func TestWriteHandlerCosts(t *testing.T) {
+	costs := []HandlerCost{
+		{EventType: "player:moved", Handler: "physics", Calls: 4, CPUTime: 4 * time.Millisecond, Time: 5 * time.Millisecond, AllocBytes: 3 << 20, Allocs: 40},
+		{EventType: "player:died", Calls: 1, CPUTime: time.Millisecond, Time: time.Millisecond, AllocBytes: 100, Allocs: 2},
+		{EventType: "game:over", Handler: "stats", Calls: 1},
+	}
+	var report strings.Builder
+	if err := WriteHandlerCosts(&report, costs, 2); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 handlers, got %q", report.String())
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "player:moved physics 4 4ms 1ms 5ms 3.0MiB 10" {
+		t.Errorf("Expected the physics totals and averages, got %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); fields[1] != "-" || fields[6] != "100B" {
+		t.Errorf("Expected an unnamed handler with 100B, got %q", lines[2])
+	}
+}
@@ -0,0 +1,7 @@
+//go:build eventbus_debug
+
+package eventbus
+
+// debugBuild reports whether the package was built with the eventbus_debug
+// tag, which enables additional checks on every bus.
+const debugBuild = true
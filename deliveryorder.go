@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+)
+
+// DeliveryOrder decides the order in which the listeners of a topic
+// receive an event. Select it per topic with TopicConfig.Order.
+type DeliveryOrder int
+
+const (
+	// OrderRegistration delivers to listeners in the order they
+	// subscribed.
+	OrderRegistration DeliveryOrder = iota
+	// OrderReverse delivers to the most recently subscribed listener
+	// first, as UI stacks need for overlays handling input before the
+	// views below them.
+	OrderReverse
+	// OrderPriority delivers to listeners by descending
+	// WithListenerPriority, and in registration order among equal
+	// priorities.
+	OrderPriority
+	// OrderRandom shuffles the listeners for every event, so none of them
+	// is consistently served first.
+	OrderRandom
+)
+
+// WithListenerPriority sets the priority of the listener on topics
+// delivered in OrderPriority. Listeners with higher priorities receive
+// events first; the default priority is 0.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithTopicConfig("input:*", eventbus.TopicConfig{Order: eventbus.OrderPriority}))
+//	bus.Subscribe("input:key", console.onKey, eventbus.WithListenerPriority(10))
+//	bus.Subscribe("input:key", game.onKey)
+func WithListenerPriority(priority int) SubscribeOption {
+	return func(config *subscribeConfig) {
+		config.priority = priority
+		config.options = append(config.options, "priority")
+	}
+}
+
+// orderDelivery returns listeners in the given order. Only the listeners
+// without a stage, which precede the staged ones, are reordered, and
+// listeners ordered with After or Before keep their order. The returned
+// slice is a copy unless the order is unchanged.
+func orderDelivery(order DeliveryOrder, listeners []*Subscription) []*Subscription {
+	if order == OrderRegistration || len(listeners) < 2 {
+		return listeners
+	}
+	unstaged := 0
+	for unstaged < len(listeners) && !listeners[unstaged].staged {
+		if listeners[unstaged].hasDependencies() {
+			return listeners
+		}
+		unstaged++
+	}
+	if unstaged < 2 {
+		return listeners
+	}
+
+	ordered := slices.Clone(listeners)
+	head := ordered[:unstaged]
+	switch order {
+	case OrderReverse:
+		slices.Reverse(head)
+	case OrderPriority:
+		slices.SortStableFunc(head, func(a, b *Subscription) int {
+			return cmp.Compare(b.priority, a.priority)
+		})
+	case OrderRandom:
+		rand.Shuffle(len(head), func(i, j int) {
+			head[i], head[j] = head[j], head[i]
+		})
+	}
+	return ordered
+}
@@ -0,0 +1,101 @@
+package eventbus
+
+import (
+	"slices"
+	"testing"
+)
+
+// orderedBus subscribes listeners named a, b, and c to "ui:click" on a bus
+// delivering ui topics in order, and returns the bus and the names in
+// delivery order of the last event.
+func orderedBus(order DeliveryOrder, opts ...[]SubscribeOption) (EventBus, *[]string) {
+	bus := New(WithTopicConfig("ui:*", TopicConfig{Order: order}))
+	received := &[]string{}
+	for i, name := range []string{"a", "b", "c"} {
+		subscribeOpts := []SubscribeOption{WithName(name)}
+		if i < len(opts) {
+			subscribeOpts = append(subscribeOpts, opts[i]...)
+		}
+		bus.Subscribe("ui:click", func(event Event) {
+			*received = append(*received, name)
+		}, subscribeOpts...)
+	}
+	return bus, received
+}
+
+// TestDeliveryOrderReverse verifies that the last registered listener is called first
+func TestDeliveryOrderReverse(t *testing.T) {
+	bus, received := orderedBus(OrderReverse)
+	defer bus.Close()
+
+	bus.Publish(Of("ui:click", 1))
+	if !slices.Equal(*received, []string{"c", "b", "a"}) {
+		t.Errorf("Expected c, b, a, got %v", *received)
+	}
+}
+
+// TestDeliveryOrderPriority verifies that listeners are called by descending priority
+func TestDeliveryOrderPriority(t *testing.T) {
+	bus, received := orderedBus(OrderPriority,
+		nil,
+		[]SubscribeOption{WithListenerPriority(-1)},
+		[]SubscribeOption{WithListenerPriority(5)},
+	)
+	defer bus.Close()
+
+	bus.Publish(Of("ui:click", 1))
+	if !slices.Equal(*received, []string{"c", "a", "b"}) {
+		t.Errorf("Expected c, a, b, got %v", *received)
+	}
+}
+
+// TestDeliveryOrderRandom verifies that every listener is eventually called first
+func TestDeliveryOrderRandom(t *testing.T) {
+	bus, received := orderedBus(OrderRandom)
+	defer bus.Close()
+
+	first := make(map[string]bool)
+	for range 200 {
+		*received = (*received)[:0]
+		bus.Publish(Of("ui:click", 1))
+		if len(*received) != 3 {
+			t.Fatalf("Expected 3 deliveries, got %v", *received)
+		}
+		first[(*received)[0]] = true
+	}
+	if len(first) != 3 {
+		t.Errorf("Expected every listener to be called first at times, got %v", first)
+	}
+}
+
+// TestDeliveryOrderKeepsStagesAndDependencies verifies that staged and dependent listeners keep their order
+func TestDeliveryOrderKeepsStagesAndDependencies(t *testing.T) {
+	staged, received := orderedBus(OrderReverse, nil, nil, []SubscribeOption{WithStage(0)})
+	defer staged.Close()
+	staged.Publish(Of("ui:click", 1))
+	if !slices.Equal(*received, []string{"b", "a", "c"}) {
+		t.Errorf("Expected the staged listener last, got %v", *received)
+	}
+
+	dependent, received := orderedBus(OrderReverse, nil, []SubscribeOption{After("a")})
+	defer dependent.Close()
+	dependent.Publish(Of("ui:click", 1))
+	if !slices.Equal(*received, []string{"a", "b", "c"}) {
+		t.Errorf("Expected registration order, got %v", *received)
+	}
+}
+
+// TestDeliveryOrderDefault verifies that other topics keep registration order
+func TestDeliveryOrderDefault(t *testing.T) {
+	bus := New(WithTopicConfig("ui:*", TopicConfig{Order: OrderReverse}))
+	defer bus.Close()
+
+	var received []int
+	for i := range 3 {
+		bus.Subscribe("game:tick", func(event Event) { received = append(received, i) })
+	}
+	bus.Publish(Of("game:tick", 1))
+	if !slices.Equal(received, []int{0, 1, 2}) {
+		t.Errorf("Expected registration order, got %v", received)
+	}
+}
@@ -0,0 +1,153 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrPublishDepth is matched by errors returned for events published
+// deeper than the limit set with WithMaxPublishDepth.
+var ErrPublishDepth = errors.New("eventbus: maximum publish depth exceeded")
+
+// DepthError describes an event rejected by WithMaxPublishDepth. It
+// matches ErrPublishDepth.
+type DepthError struct {
+	// Max is the configured maximum depth.
+	Max int
+	// Chain holds the event types of the causation chain, from the event
+	// published outside any listener to the rejected event.
+	Chain []EventType
+	// Reentrant is set if a listener of a synchronous topic published the
+	// event on the bus delivering it, which would deadlock at any depth.
+	Reentrant bool
+}
+
+// Error describes the causation chain, eliding the middle of long chains.
+func (e *DepthError) Error() string {
+	chain := make([]string, 0, len(e.Chain))
+	for i, eventType := range e.Chain {
+		if len(e.Chain) > 12 && i >= 4 && i < len(e.Chain)-6 {
+			if i == 4 {
+				chain = append(chain, fmt.Sprintf("(%d more)", len(e.Chain)-10))
+			}
+			continue
+		}
+		chain = append(chain, string(eventType))
+	}
+	if e.Reentrant {
+		return fmt.Sprintf("eventbus: publishing from a synchronous listener would deadlock: %s", strings.Join(chain, " -> "))
+	}
+	return fmt.Sprintf("eventbus: maximum publish depth %d exceeded: %s", e.Max, strings.Join(chain, " -> "))
+}
+
+// Is reports whether target is ErrPublishDepth.
+func (e *DepthError) Is(target error) bool {
+	return target == ErrPublishDepth
+}
+
+// WithMaxPublishDepth limits how deeply listeners may publish events from
+// within listeners, so an accidental event loop, such as two listeners
+// publishing each other's topics, fails with a DepthError naming the chain
+// of event types instead of overflowing the stack or flooding the queues.
+// Events published outside any listener have depth 0, and events published
+// from a listener of an event of depth d have depth d+1. Publish and
+// PublishContext panic with the error in the listener publishing too
+// deeply, where the panic is handled like any other listener panic;
+// PublishAndWait returns it.
+//
+// The depth travels in the listener context, like priority inheritance, so
+// listeners must publish with the context they received and be subscribed
+// with SubscribeContext. A limit of 0 or less disables the check.
+//
+// Default synchronous topics deliver while holding the bus lock, so their
+// listeners cannot publish on the same bus at any depth. With a limit set,
+// such a publish fails with a DepthError whose Reentrant field is set
+// instead of deadlocking. It is detected on the delivering goroutine, even
+// with Publish, and on other goroutines publishing with the listener
+// context while the delivery runs, such as the parallel listeners of a
+// stage.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithAsync(4, 1024), eventbus.WithMaxPublishDepth(32))
+//
+//	bus.SubscribeContext("stock:changed", func(ctx context.Context, event eventbus.Event) {
+//	    // Panics with "... stock:changed -> price:changed -> stock:changed -> ..."
+//	    // once the loop is 32 events deep.
+//	    bus.PublishContext(ctx, PriceChanged{})
+//	})
+func WithMaxPublishDepth(n int) Option {
+	return func(bus *eventBusImpl) {
+		bus.maxDepth = n
+		bus.maxDepthSet = true
+	}
+}
+
+// depthOf returns the depth of an event published from a listener
+// handling parent, or 0 outside listeners.
+func depthOf(parent *cause) int {
+	if parent == nil {
+		return 0
+	}
+	return parent.depth + 1
+}
+
+// checkDepth returns a DepthError if event, published from a listener
+// handling parent, is deeper than the limit of the bus.
+func (bus *eventBusImpl) checkDepth(parent *cause, event Event) error {
+	if bus.maxDepth <= 0 || depthOf(parent) <= bus.maxDepth {
+		return nil
+	}
+	return &DepthError{Max: bus.maxDepth, Chain: chainOf(parent, event)}
+}
+
+// chainOf returns the event types of the causation chain of event,
+// published from a listener handling parent.
+func chainOf(parent *cause, event Event) []EventType {
+	chain := []EventType{event.GetType()}
+	for c := parent; c != nil; c = c.parent {
+		chain = append(chain, c.eventType)
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+// lockHolder is the synchronous delivery holding the bus lock.
+type lockHolder struct {
+	goroutine uint64
+	// cause is the cause of the event being delivered.
+	cause *cause
+	max   int
+}
+
+// lock acquires the bus mutex to publish event with ctx. It returns a
+// reentrant DepthError instead if a listener of the synchronous delivery
+// holding the mutex publishes event, on its goroutine or with its context.
+func (bus *eventBusImpl) lock(ctx context.Context, event Event) error {
+	if bus.mutex.TryLock() {
+		return nil
+	}
+	if holder := bus.holder.Load(); holder != nil {
+		if causeOf(ctx) == holder.cause || holder.goroutine == goroutineID() {
+			return &DepthError{Max: holder.max, Chain: chainOf(holder.cause, event), Reentrant: true}
+		}
+	}
+	bus.mutex.Lock()
+	return nil
+}
+
+// goroutineID returns the ID of the calling goroutine, read from the
+// "goroutine N [running]:" header of its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	id, _ := strconv.ParseUint(string(header[:bytes.IndexByte(header, ' ')]), 10, 64)
+	return id
+}
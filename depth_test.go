@@ -0,0 +1,152 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMaxPublishDepth verifies that an event loop fails with its causation chain
+func TestMaxPublishDepth(t *testing.T) {
+	bus := New(
+		WithMaxPublishDepth(3),
+		WithTopicConfig("*", TopicConfig{Dispatcher: InlineDispatcher{}}),
+	)
+	defer bus.Close()
+
+	handled := 0
+	var recovered any
+	relay := func(next EventType) ContextListener {
+		return func(ctx context.Context, event Event) {
+			handled++
+			defer func() {
+				if r := recover(); r != nil {
+					recovered = r
+				}
+			}()
+			bus.PublishContext(ctx, Of(next, 1))
+		}
+	}
+	bus.SubscribeContext("ping", relay("pong"))
+	bus.SubscribeContext("pong", relay("ping"))
+	bus.Publish(Of("ping", 1))
+
+	var depthErr *DepthError
+	err, _ := recovered.(error)
+	if !errors.Is(err, ErrPublishDepth) || !errors.As(err, &depthErr) {
+		t.Fatalf("Expected a DepthError panic, got %v", recovered)
+	}
+	if chain := strings.Join(func() []string {
+		var s []string
+		for _, eventType := range depthErr.Chain {
+			s = append(s, string(eventType))
+		}
+		return s
+	}(), " "); chain != "ping pong ping pong ping" {
+		t.Errorf("Expected the chain ping pong ping pong ping, got %q", chain)
+	}
+	if !strings.Contains(err.Error(), "ping -> pong -> ping -> pong -> ping") {
+		t.Errorf("Expected the error to describe the chain, got %q", err)
+	}
+	if handled != 4 {
+		t.Errorf("Expected 4 events of depth 0 to 3 to be handled, got %d", handled)
+	}
+}
+
+// TestMaxPublishDepthAsync verifies that asynchronous loops stop at the limit
+func TestMaxPublishDepthAsync(t *testing.T) {
+	bus := New(WithAsync(2, 16), WithMaxPublishDepth(5))
+
+	errs := make(chan error, 1)
+	bus.SubscribeContext("tick", func(ctx context.Context, event Event) {
+		defer func() {
+			if err, ok := recover().(error); ok {
+				errs <- err
+			}
+		}()
+		bus.PublishContext(ctx, Of("tick", 1))
+	})
+	bus.Publish(Of("tick", 1))
+
+	err := <-errs
+	bus.Close()
+	var depthErr *DepthError
+	if !errors.As(err, &depthErr) || len(depthErr.Chain) != 7 || depthErr.Max != 5 {
+		t.Errorf("Expected a chain of 7 ticks past depth 5, got %v", err)
+	}
+	if published := bus.Stats().Published; published != 6 {
+		t.Errorf("Expected 6 published ticks, got %d", published)
+	}
+}
+
+// TestMaxPublishDepthSync verifies that a listener of a synchronous topic publishing on its bus fails instead of deadlocking
+func TestMaxPublishDepthSync(t *testing.T) {
+	bus := New(WithMaxPublishDepth(3))
+	defer bus.Close()
+
+	errs := make(chan error, 2)
+	bus.Subscribe("tick", func(event Event) {
+		defer func() {
+			if err, ok := recover().(error); ok {
+				errs <- err
+			}
+		}()
+		bus.Publish(Of("tock", 1))
+	})
+	bus.SubscribeContext("ping", func(ctx context.Context, event Event) {
+		errs <- bus.PublishAndWait(ctx, Of("pong", 1))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bus.Publish(Of("tick", 1))
+		bus.Publish(Of("ping", 1))
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the nested publishes to fail, but they deadlocked")
+	}
+
+	for _, want := range []string{"tick -> tock", "ping -> pong"} {
+		var depthErr *DepthError
+		err := <-errs
+		if !errors.Is(err, ErrPublishDepth) || !errors.As(err, &depthErr) || !depthErr.Reentrant {
+			t.Errorf("Expected a reentrant DepthError, got %v", err)
+		} else if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the chain %s, got %q", want, err)
+		}
+	}
+}
+
+// TestDepthErrorElidesLongChains verifies that long chains are shortened in the message
+func TestDepthErrorElidesLongChains(t *testing.T) {
+	err := &DepthError{Max: 99}
+	for i := range 101 {
+		err.Chain = append(err.Chain, EventType(string(rune('a'+i%26))))
+	}
+	expected := "eventbus: maximum publish depth 99 exceeded: a -> b -> c -> d -> (91 more) -> r -> s -> t -> u -> v -> w"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+// TestPublishDepthUnlimited verifies that nesting is not limited by default
+func TestPublishDepthUnlimited(t *testing.T) {
+	bus := New(WithTopicConfig("*", TopicConfig{Dispatcher: InlineDispatcher{}}))
+	defer bus.Close()
+
+	depth := 0
+	bus.SubscribeContext("step", func(ctx context.Context, event Event) {
+		if depth++; depth < 100 {
+			bus.PublishContext(ctx, Of("step", 1))
+		}
+	})
+	bus.Publish(Of("step", 1))
+	if depth != 100 {
+		t.Errorf("Expected 100 nested steps, got %d", depth)
+	}
+}
@@ -0,0 +1,112 @@
+package eventbus
+
+import (
+	"reflect"
+	"slices"
+)
+
+// TopicInfo documents an event type for developers new to a code base.
+type TopicInfo struct {
+	// Description says what the event means and when it is published.
+	Description string
+	// Owner is the team or component responsible for the event type.
+	Owner string
+	// Producers and Consumers name the components publishing and
+	// handling the event.
+	Producers []string
+	Consumers []string
+}
+
+// clone returns a copy of info that shares no slices with it.
+func (info TopicInfo) clone() TopicInfo {
+	info.Producers = slices.Clone(info.Producers)
+	info.Consumers = slices.Clone(info.Consumers)
+	return info
+}
+
+// Document records info for eventType in DefaultTypes. Documenting an
+// event type again replaces its documentation.
+//
+// Example:
+//
+//	func init() {
+//	    eventbus.Document("player:died", eventbus.TopicInfo{
+//	        Description: "A player's health reached zero.",
+//	        Owner:       "gameplay",
+//	        Producers:   []string{"combat"},
+//	        Consumers:   []string{"scoreboard", "respawn"},
+//	    })
+//	}
+func Document(eventType EventType, info TopicInfo) {
+	DefaultTypes.Document(eventType, info)
+}
+
+// Document records info for eventType. The event type does not have to
+// be registered with a Go type.
+func (r *TypeRegistry) Document(eventType EventType, info TopicInfo) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.docs == nil {
+		r.docs = make(map[EventType]TopicInfo)
+	}
+	r.docs[eventType] = info.clone()
+}
+
+// Documentation returns the documentation recorded for eventType.
+func (r *TypeRegistry) Documentation(eventType EventType) (TopicInfo, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	info, ok := r.docs[eventType]
+	return info.clone(), ok
+}
+
+// TopicDescription combines the documentation of an event type with what
+// the bus knows about it at runtime.
+type TopicDescription struct {
+	EventType EventType
+	TopicInfo
+	// GoType is the Go type registered for the event type, or nil.
+	GoType reflect.Type
+	// Publishers are the components declared with DeclarePublisher.
+	Publishers []string
+	// Subscriptions describes the subscriptions receiving the event type,
+	// in delivery order, including those subscribed to matching patterns
+	// with a WildcardRouter.
+	Subscriptions []SubscriptionInfo
+}
+
+// WithTypeRegistry sets the registry from which Describe reads event
+// documentation and Go types. The default is DefaultTypes.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithTypeRegistry(gameTypes))
+func WithTypeRegistry(registry *TypeRegistry) Option {
+	return func(bus *eventBusImpl) {
+		bus.types = registry
+	}
+}
+
+// Describe returns the documentation, Go type, and current subscriptions
+// of eventType.
+func (bus *eventBusImpl) Describe(eventType EventType) TopicDescription {
+	registry := bus.types
+	if registry == nil {
+		registry = DefaultTypes
+	}
+
+	description := TopicDescription{EventType: eventType}
+	description.TopicInfo, _ = registry.Documentation(eventType)
+	description.GoType, _ = registry.Lookup(eventType)
+
+	bus.subscribersMutex.RLock()
+	listeners := bus.matchListeners(eventType)
+	description.Publishers = slices.Clone(bus.publishers[eventType])
+	bus.subscribersMutex.RUnlock()
+	for _, sub := range listeners {
+		description.Subscriptions = append(description.Subscriptions, sub.info(sub.Health()))
+	}
+	return description
+}
@@ -0,0 +1,56 @@
+package eventbus
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDocument verifies that documentation is stored as a copy
+func TestDocument(t *testing.T) {
+	registry := NewTypeRegistry()
+	consumers := []string{"scoreboard"}
+	registry.Document("player:died", TopicInfo{Owner: "gameplay", Consumers: consumers})
+	consumers[0] = "changed"
+
+	info, ok := registry.Documentation("player:died")
+	if !ok || info.Owner != "gameplay" || info.Consumers[0] != "scoreboard" {
+		t.Errorf("Expected the recorded documentation, got %+v (%v)", info, ok)
+	}
+	info.Consumers[0] = "changed"
+	if info, _ := registry.Documentation("player:died"); info.Consumers[0] != "scoreboard" {
+		t.Error("Expected the registry to be unaffected by changes to returned documentation")
+	}
+	if _, ok := registry.Documentation("player:moved"); ok {
+		t.Error("Expected no documentation for an undocumented type")
+	}
+}
+
+// TestDescribe verifies that descriptions combine documentation, Go type, and subscribers
+func TestDescribe(t *testing.T) {
+	registry := NewTypeRegistry()
+	RegisterIn[playerDied](registry)
+	registry.Document("player:died", TopicInfo{
+		Description: "A player's health reached zero.",
+		Producers:   []string{"input"},
+	})
+
+	bus := New(WithTypeRegistry(registry), WithRouter(NewWildcardRouter()))
+	bus.Subscribe("player:died", func(event Event) {}, WithName("audio"))
+	bus.Subscribe("player:*", func(event Event) {}, WithName("replay"))
+	bus.Subscribe("player:moved", func(event Event) {})
+
+	d := bus.Describe("player:died")
+	if d.Description != "A player's health reached zero." || len(d.Producers) != 1 {
+		t.Errorf("Expected the documentation, got %+v", d.TopicInfo)
+	}
+	if d.GoType != reflect.TypeFor[playerDied]() {
+		t.Errorf("Expected playerDied, got %v", d.GoType)
+	}
+	if len(d.Subscriptions) != 2 || d.Subscriptions[0].Handler != "audio" || d.Subscriptions[1].Handler != "replay" {
+		t.Errorf("Expected audio and replay subscriptions, got %+v", d.Subscriptions)
+	}
+
+	if d := bus.Describe("game:over"); d.GoType != nil || d.Description != "" || len(d.Subscriptions) != 0 {
+		t.Errorf("Expected an empty description, got %+v", d)
+	}
+}
@@ -0,0 +1,332 @@
+package eventbus
+
+import (
+	"context"
+	"hash/maphash"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Dispatcher decides where and when deliveries run, so a topic can be
+// driven by an existing job system instead of the bus's worker pools.
+// Configure one with TopicConfig.Dispatcher. The bus ships
+// InlineDispatcher, FrameDispatcher, and KeyedDispatcher; TopicConfig.Async
+// selects the built-in worker pools.
+type Dispatcher interface {
+	// Dispatch arranges for deliver to be called exactly once, for a
+	// delivery of event. It is called without holding the bus lock, once
+	// per listener, or once per event on serial topics and for listeners
+	// with stages. If Dispatch returns an error, deliver must never be
+	// called; the delivery is then dropped and the error returned to the
+	// publisher.
+	Dispatch(ctx context.Context, event Event, deliver func()) error
+
+	// Close runs or waits for the pending deliveries. The bus calls it
+	// from Close, once for every route using the dispatcher.
+	Close()
+}
+
+// dispatcherExecutor adapts a Dispatcher to the executor of a route.
+type dispatcherExecutor struct {
+	dispatcher Dispatcher
+}
+
+// start does nothing; the dispatcher owns its goroutines.
+func (e *dispatcherExecutor) start(audit *leakAudit, pattern EventType) {}
+
+// stop closes the dispatcher.
+func (e *dispatcherExecutor) stop() {
+	e.dispatcher.Close()
+}
+
+// enqueue hands job to the dispatcher, dropping it if the dispatcher
+// refuses it.
+func (e *dispatcherExecutor) enqueue(ctx context.Context, job asyncJob) error {
+	err := e.dispatcher.Dispatch(ctx, job.event, job.run)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		job.drop(DropCancelled, contextError(ctx))
+	} else {
+		job.drop(DropQueueFull, ErrQueueFull)
+	}
+	return err
+}
+
+// queued returns the pending deliveries if the dispatcher reports them
+// with a Len method.
+func (e *dispatcherExecutor) queued() int {
+	if counter, ok := e.dispatcher.(interface{ Len() int }); ok {
+		return counter.Len()
+	}
+	return 0
+}
+
+// InlineDispatcher runs every delivery on the publishing goroutine, like
+// a synchronous topic, but without holding the bus lock, so listeners may
+// publish nested events and concurrent publishers run their deliveries in
+// parallel.
+type InlineDispatcher struct{}
+
+// Dispatch calls deliver.
+func (InlineDispatcher) Dispatch(ctx context.Context, event Event, deliver func()) error {
+	deliver()
+	return nil
+}
+
+// Close does nothing.
+func (InlineDispatcher) Close() {}
+
+// FrameDispatcher queues deliveries until Flush runs them, so a game loop
+// can handle the events of a frame at a fixed point on its main thread.
+// It is safe for concurrent use.
+//
+// Example:
+//
+//	frame := eventbus.NewFrameDispatcher()
+//	bus := eventbus.New(eventbus.WithTopicConfig("input:*", eventbus.TopicConfig{Dispatcher: frame}))
+//
+//	for running {
+//	    frame.Flush()
+//	    update()
+//	    render()
+//	}
+type FrameDispatcher struct {
+	pending []frameDelivery
+	// postponed counts, per topic, the FlushBudget calls in a row that
+	// left its deliveries queued without running any of them.
+	postponed map[EventType]int
+	closed    bool
+	mutex     sync.Mutex
+}
+
+// frameDelivery is a delivery queued by a FrameDispatcher.
+type frameDelivery struct {
+	topic   EventType
+	deliver func()
+}
+
+// frameLane is the queued deliveries of one topic during FlushBudget.
+type frameLane struct {
+	topic     EventType
+	postponed int
+	indices   []int
+	next      int
+}
+
+// NewFrameDispatcher creates a dispatcher with an empty queue.
+func NewFrameDispatcher() *FrameDispatcher {
+	return &FrameDispatcher{postponed: make(map[EventType]int)}
+}
+
+// Dispatch queues deliver for the next Flush. After Close, it runs
+// deliver immediately.
+func (d *FrameDispatcher) Dispatch(ctx context.Context, event Event, deliver func()) error {
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		deliver()
+		return nil
+	}
+	d.pending = append(d.pending, frameDelivery{topic: event.GetType(), deliver: deliver})
+	d.mutex.Unlock()
+	return nil
+}
+
+// Flush runs the queued deliveries in dispatch order and returns how many
+// ran. Deliveries dispatched while flushing wait for the next Flush.
+func (d *FrameDispatcher) Flush() int {
+	d.mutex.Lock()
+	pending := d.pending
+	d.pending = nil
+	clear(d.postponed)
+	d.mutex.Unlock()
+
+	for _, delivery := range pending {
+		delivery.deliver()
+	}
+	return len(pending)
+}
+
+// FlushBudget runs queued deliveries until budget is spent and returns how
+// many ran, leaving the rest for the next frame. At least one delivery
+// runs, and the budget is checked between deliveries, so a single slow
+// listener is never interrupted. Deliveries dispatched while flushing wait
+// for the next flush.
+//
+// Topics take turns, one delivery each, so a chatty topic cannot starve
+// the others within a frame, and each topic keeps its dispatch order.
+// Topics whose deliveries were all postponed by earlier calls go first,
+// the longest postponed first, so they are not put off frame after frame.
+//
+// Example:
+//
+//	for running {
+//	    frame.FlushBudget(2 * time.Millisecond)
+//	    update()
+//	    render()
+//	}
+func (d *FrameDispatcher) FlushBudget(budget time.Duration) int {
+	start := time.Now()
+	d.mutex.Lock()
+	pending := d.pending
+	d.pending = nil
+	lanes := d.lanes(pending)
+	d.mutex.Unlock()
+
+	ran := make([]bool, len(pending))
+	count := 0
+	for count < len(pending) && (count == 0 || time.Since(start) < budget) {
+		for _, lane := range lanes {
+			if lane.next == len(lane.indices) {
+				continue
+			}
+			if count > 0 && time.Since(start) >= budget {
+				break
+			}
+			i := lane.indices[lane.next]
+			lane.next++
+			pending[i].deliver()
+			ran[i] = true
+			count++
+		}
+	}
+
+	var rest []frameDelivery
+	for i, delivery := range pending {
+		if !ran[i] {
+			rest = append(rest, delivery)
+		}
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, lane := range lanes {
+		if lane.next == 0 {
+			d.postponed[lane.topic] = lane.postponed + 1
+		} else {
+			delete(d.postponed, lane.topic)
+		}
+	}
+	d.pending = append(rest, d.pending...)
+	return count
+}
+
+// lanes groups pending by topic, the longest postponed topics first and
+// the others in the order of their first delivery. The caller must hold
+// d.mutex.
+func (d *FrameDispatcher) lanes(pending []frameDelivery) []*frameLane {
+	var lanes []*frameLane
+	byTopic := make(map[EventType]*frameLane)
+	for i, delivery := range pending {
+		lane, ok := byTopic[delivery.topic]
+		if !ok {
+			lane = &frameLane{topic: delivery.topic, postponed: d.postponed[delivery.topic]}
+			byTopic[delivery.topic] = lane
+			lanes = append(lanes, lane)
+		}
+		lane.indices = append(lane.indices, i)
+	}
+	slices.SortStableFunc(lanes, func(a, b *frameLane) int {
+		return b.postponed - a.postponed
+	})
+	return lanes
+}
+
+// Len returns the number of queued deliveries.
+func (d *FrameDispatcher) Len() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return len(d.pending)
+}
+
+// Close runs the queued deliveries. Later deliveries run immediately.
+func (d *FrameDispatcher) Close() {
+	d.mutex.Lock()
+	d.closed = true
+	d.mutex.Unlock()
+
+	// Deliveries may dispatch nested events while flushing.
+	for {
+		if d.Flush() == 0 {
+			return
+		}
+	}
+}
+
+// KeyedDispatcher runs deliveries on a fixed set of goroutines, sending
+// all events with the same key to the same goroutine, so they are handled
+// one at a time and in dispatch order while different keys run in
+// parallel. It is useful for per-entity ordering, such as all events of
+// one player.
+//
+// Example:
+//
+//	byPlayer := eventbus.NewKeyedDispatcher(8, 256, func(event eventbus.Event) string {
+//	    return event.(PlayerEvent).PlayerID()
+//	})
+//	bus := eventbus.New(eventbus.WithTopicConfig("player:*", eventbus.TopicConfig{Dispatcher: byPlayer}))
+type KeyedDispatcher struct {
+	key     func(Event) string
+	seed    maphash.Seed
+	lanes   []chan func()
+	running sync.WaitGroup
+	once    sync.Once
+}
+
+// NewKeyedDispatcher starts workers goroutines, each with a queue of
+// queueSize deliveries, and routes events to them by key. Workers and
+// queue size are raised to at least 1. Dispatch blocks while the queue
+// of a key is full.
+func NewKeyedDispatcher(workers, queueSize int, key func(Event) string) *KeyedDispatcher {
+	d := &KeyedDispatcher{
+		key:   key,
+		seed:  maphash.MakeSeed(),
+		lanes: make([]chan func(), max(workers, 1)),
+	}
+	d.running.Add(len(d.lanes))
+	for i := range d.lanes {
+		lane := make(chan func(), max(queueSize, 1))
+		d.lanes[i] = lane
+		go func() {
+			defer d.running.Done()
+			for deliver := range lane {
+				deliver()
+			}
+		}()
+	}
+	return d
+}
+
+// Dispatch queues deliver on the goroutine of the event's key. It returns
+// the context error if ctx is done while the queue is full.
+func (d *KeyedDispatcher) Dispatch(ctx context.Context, event Event, deliver func()) error {
+	lane := d.lanes[maphash.String(d.seed, d.key(event))%uint64(len(d.lanes))]
+	select {
+	case lane <- deliver:
+		return nil
+	case <-ctx.Done():
+		return contextError(ctx)
+	}
+}
+
+// Len returns the number of queued deliveries.
+func (d *KeyedDispatcher) Len() int {
+	n := 0
+	for _, lane := range d.lanes {
+		n += len(lane)
+	}
+	return n
+}
+
+// Close lets the goroutines finish the queued deliveries and waits for
+// them to exit. No deliveries may be dispatched after Close.
+func (d *KeyedDispatcher) Close() {
+	d.once.Do(func() {
+		for _, lane := range d.lanes {
+			close(lane)
+		}
+	})
+	d.running.Wait()
+}
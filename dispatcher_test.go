@@ -0,0 +1,182 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestFrameDispatcher verifies that deliveries wait for Flush
+func TestFrameDispatcher(t *testing.T) {
+	frame := NewFrameDispatcher()
+	bus := New(WithTopicConfig("input:*", TopicConfig{Dispatcher: frame}))
+	defer bus.Close()
+
+	var received []string
+	bus.Subscribe("input:key", func(event Event) {
+		received = append(received, event.(testEvent).data)
+	})
+
+	bus.Publish(testEvent{eventType: "input:key", data: "a"})
+	bus.Publish(testEvent{eventType: "input:key", data: "b"})
+	if len(received) != 0 {
+		t.Fatalf("Expected no deliveries before Flush, got %v", received)
+	}
+	if stats := bus.Stats(); stats.Queued != 2 {
+		t.Errorf("Expected 2 queued deliveries, got %d", stats.Queued)
+	}
+
+	if n := frame.Flush(); n != 2 {
+		t.Errorf("Expected 2 flushed deliveries, got %d", n)
+	}
+	if len(received) != 2 || received[0] != "a" || received[1] != "b" {
+		t.Errorf("Expected [a b], got %v", received)
+	}
+}
+
+// TestFrameDispatcherClose verifies that Close runs the queued deliveries
+func TestFrameDispatcherClose(t *testing.T) {
+	frame := NewFrameDispatcher()
+	bus := New(WithTopicConfig("input:*", TopicConfig{Dispatcher: frame}))
+
+	count := 0
+	bus.Subscribe("input:key", func(event Event) { count++ })
+	bus.Publish(testEvent{eventType: "input:key"})
+	bus.Close()
+
+	if count != 1 {
+		t.Errorf("Expected Close to run the queued delivery, got %d", count)
+	}
+}
+
+// TestFrameDispatcherBudget verifies that budgeted flushes take turns
+// between topics and carry postponed topics into the next frame
+func TestFrameDispatcherBudget(t *testing.T) {
+	frame := NewFrameDispatcher()
+	bus := New(WithTopicConfig("*", TopicConfig{Dispatcher: frame}))
+	defer bus.Close()
+
+	var received []string
+	record := func(event Event) { received = append(received, event.(testEvent).data) }
+	bus.Subscribe("input:key", record)
+	bus.Subscribe("net:packet", record)
+
+	for _, data := range []string{"k1", "k2", "k3"} {
+		bus.Publish(testEvent{eventType: "input:key", data: data})
+	}
+	bus.Publish(testEvent{eventType: "net:packet", data: "p1"})
+
+	// A zero budget runs a single delivery per frame.
+	for frameNumber := 1; frameNumber <= 3; frameNumber++ {
+		if n := frame.FlushBudget(0); n != 1 {
+			t.Errorf("Expected 1 delivery in frame %d, got %d", frameNumber, n)
+		}
+	}
+	want := []string{"k1", "p1", "k2"}
+	if !slices.Equal(received, want) {
+		t.Errorf("Expected %v, got %v", want, received)
+	}
+	if frame.Len() != 1 {
+		t.Errorf("Expected 1 postponed delivery, got %d", frame.Len())
+	}
+
+	received = nil
+	bus.Publish(testEvent{eventType: "input:key", data: "k4"})
+	if n := frame.FlushBudget(time.Minute); n != 2 {
+		t.Errorf("Expected 2 deliveries within the budget, got %d", n)
+	}
+	if want := []string{"k3", "k4"}; !slices.Equal(received, want) {
+		t.Errorf("Expected %v, got %v", want, received)
+	}
+}
+
+// TestKeyedDispatcher verifies that events with the same key keep their order
+func TestKeyedDispatcher(t *testing.T) {
+	keyed := NewKeyedDispatcher(4, 16, func(event Event) string {
+		return event.(testEvent).data[:1]
+	})
+	bus := New(WithTopicConfig("player:*", TopicConfig{Dispatcher: keyed}))
+
+	var mutex sync.Mutex
+	received := map[string][]string{}
+	bus.Subscribe("player:moved", func(event Event) {
+		data := event.(testEvent).data
+		mutex.Lock()
+		defer mutex.Unlock()
+		received[data[:1]] = append(received[data[:1]], data)
+	})
+
+	for i := 0; i < 10; i++ {
+		for _, player := range []string{"a", "b", "c"} {
+			bus.Publish(testEvent{eventType: "player:moved", data: player + string(rune('0'+i))})
+		}
+	}
+	bus.Close()
+
+	for _, player := range []string{"a", "b", "c"} {
+		events := received[player]
+		if len(events) != 10 {
+			t.Fatalf("Expected 10 events for %s, got %v", player, events)
+		}
+		for i, data := range events {
+			if data != player+string(rune('0'+i)) {
+				t.Errorf("Expected %s events in order, got %v", player, events)
+				break
+			}
+		}
+	}
+}
+
+// TestInlineDispatcher verifies that listeners on an inline topic can publish nested events
+func TestInlineDispatcher(t *testing.T) {
+	bus := New(WithTopicConfig("request:*", TopicConfig{Dispatcher: InlineDispatcher{}}))
+	defer bus.Close()
+
+	nested := false
+	bus.Subscribe("request:sent", func(event Event) {
+		bus.Publish(testEvent{eventType: "response:sent"})
+	})
+	bus.Subscribe("response:sent", func(event Event) { nested = true })
+
+	if err := bus.PublishAndWait(context.Background(), testEvent{eventType: "request:sent"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !nested {
+		t.Error("Expected the nested event to be delivered")
+	}
+}
+
+// refusingDispatcher refuses every delivery.
+type refusingDispatcher struct{}
+
+var errRefused = errors.New("refused")
+
+func (refusingDispatcher) Dispatch(ctx context.Context, event Event, deliver func()) error {
+	return errRefused
+}
+
+func (refusingDispatcher) Close() {}
+
+// TestDispatcherError verifies that refused deliveries are dropped and reported
+func TestDispatcherError(t *testing.T) {
+	var reasons []DropReason
+	bus := New(
+		WithTopicConfig("test:*", TopicConfig{Dispatcher: refusingDispatcher{}}),
+		WithOnDrop(func(reason DropReason, event Event) { reasons = append(reasons, reason) }),
+	)
+	defer bus.Close()
+
+	called := false
+	bus.Subscribe("test:event", func(event Event) { called = true })
+
+	_, err := bus.PublishDetailed(context.Background(), testEvent{eventType: "test:event"})
+	if !errors.Is(err, errRefused) {
+		t.Errorf("Expected the dispatcher error, got %v", err)
+	}
+	if called || len(reasons) != 1 || reasons[0] != DropQueueFull {
+		t.Errorf("Expected the delivery to be dropped, got called=%v reasons=%v", called, reasons)
+	}
+}
@@ -0,0 +1,57 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+// KeyFunc extracts a key from an event, such as an entity ID.
+// Keys must be comparable values since they are used as map keys.
+type KeyFunc func(Event) any
+
+// WithDistinct only invokes the listener when an event differs from the
+// previous event with the same key. It suits listeners that react to state
+// changes rather than to every update, such as a health bar that only
+// repaints when the hit points actually change.
+//
+// keyFn groups events; if nil, all events share a single key.
+// equalFn compares the previous and current event; if nil, events are
+// compared with ==, which requires comparable event types.
+//
+// Example:
+//
+//	bus.Subscribe("player:health", repaintHealthBar, eventbus.WithDistinct(
+//	    func(e eventbus.Event) any { return e.(HealthChanged).PlayerID },
+//	    func(a, b eventbus.Event) bool { return a.(HealthChanged).HP == b.(HealthChanged).HP },
+//	))
+func WithDistinct(keyFn KeyFunc, equalFn func(previous, current Event) bool) SubscribeOption {
+	if equalFn == nil {
+		equalFn = func(previous, current Event) bool { return previous == current }
+	}
+
+	return func(config *subscribeConfig) {
+		// Every subscription gets its own state, so cloned subscriptions
+		// start without previous events.
+		var mutex sync.Mutex
+		last := make(map[any]Event)
+
+		filter := func(ctx context.Context, event Event) bool {
+			var key any
+			if keyFn != nil {
+				key = keyFn(event)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if previous, ok := last[key]; ok && equalFn(previous, event) {
+				return false
+			}
+			last[key] = event
+			return true
+		}
+
+		config.filters = append(config.filters, filter)
+		config.options = append(config.options, "distinct")
+	}
+}
@@ -0,0 +1,59 @@
+package eventbus
+
+import "testing"
+
+type healthEvent struct {
+	playerID string
+	hp       int
+}
+
+func (e healthEvent) GetType() EventType {
+	return "player:health"
+}
+
+// TestWithDistinct verifies that repeated equal events are suppressed per key
+func TestWithDistinct(t *testing.T) {
+	bus := New()
+	var received []healthEvent
+
+	bus.Subscribe("player:health", func(event Event) {
+		received = append(received, event.(healthEvent))
+	}, WithDistinct(
+		func(event Event) any { return event.(healthEvent).playerID },
+		func(previous, current Event) bool {
+			return previous.(healthEvent).hp == current.(healthEvent).hp
+		},
+	))
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 100})
+	bus.Publish(healthEvent{playerID: "p1", hp: 100})
+	bus.Publish(healthEvent{playerID: "p2", hp: 100})
+	bus.Publish(healthEvent{playerID: "p1", hp: 90})
+	bus.Publish(healthEvent{playerID: "p2", hp: 100})
+
+	if len(received) != 3 {
+		t.Fatalf("Expected 3 deliveries, got %d: %v", len(received), received)
+	}
+	if received[2].playerID != "p1" || received[2].hp != 90 {
+		t.Errorf("Expected p1 at 90 HP, got %+v", received[2])
+	}
+}
+
+// TestWithDistinctDefaults verifies the single-key and == comparison defaults
+func TestWithDistinctDefaults(t *testing.T) {
+	bus := New()
+	count := 0
+
+	bus.Subscribe("player:health", func(event Event) {
+		count++
+	}, WithDistinct(nil, nil))
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 100})
+	bus.Publish(healthEvent{playerID: "p1", hp: 100})
+	bus.Publish(healthEvent{playerID: "p2", hp: 100})
+	bus.Publish(healthEvent{playerID: "p1", hp: 100})
+
+	if count != 3 {
+		t.Errorf("Expected 3 deliveries, got %d", count)
+	}
+}
@@ -0,0 +1,22 @@
+// Package dombridge connects an event bus running in a WebAssembly module
+// (GOOS=js GOARCH=wasm) with DOM EventTarget objects, so a browser client
+// can share the event model of the server.
+//
+// Bus events are dispatched as CustomEvent values whose type is the event
+// type and whose detail is the JSON-encoded event payload. DOM custom events
+// are published on the bus as eventbus.RawEvent values carrying their
+// detail as JSON.
+//
+// Example:
+//
+//	bridge := dombridge.New(bus, js.Global().Get("document"))
+//	defer bridge.Close()
+//
+//	// Let the UI react to game events
+//	bridge.Forward("player:scored")
+//
+//	// Let the game react to UI events
+//	bridge.Listen("ui:pause")
+//
+// The package only builds for js/wasm.
+package dombridge
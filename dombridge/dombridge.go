@@ -0,0 +1,138 @@
+//go:build js && wasm
+
+package dombridge
+
+import (
+	"encoding/json"
+	"sync"
+	"syscall/js"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// Bridge maps events between an event bus and a DOM EventTarget.
+type Bridge struct {
+	bus    eventbus.EventBus
+	target js.Value
+
+	// listeners holds the DOM listeners added by Listen, so Close can
+	// remove them and release their functions.
+	listeners map[eventbus.EventType]js.Func
+	// subscriptions holds the bus subscriptions added by Forward, so
+	// Close can cancel them.
+	subscriptions []*eventbus.Subscription
+	// dispatching holds the event types currently being dispatched by
+	// Forward, so Listen does not publish them back onto the bus.
+	dispatching map[eventbus.EventType]bool
+	closed      bool
+	mutex       sync.Mutex
+}
+
+// New creates a bridge between bus and target, which can be any
+// EventTarget such as document, window, or a DOM element.
+func New(bus eventbus.EventBus, target js.Value) *Bridge {
+	return &Bridge{
+		bus:         bus,
+		target:      target,
+		listeners:   make(map[eventbus.EventType]js.Func),
+		dispatching: make(map[eventbus.EventType]bool),
+	}
+}
+
+// Forward dispatches every bus event of eventType on the target as a
+// CustomEvent. The event's JSON encoding is parsed into the detail, so
+// only exported fields are visible to JavaScript. Events that cannot be
+// encoded are skipped.
+func (b *Bridge) Forward(eventType eventbus.EventType) {
+	sub := b.bus.Subscribe(eventType, func(event eventbus.Event) {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+
+		b.mutex.Lock()
+		if b.closed {
+			b.mutex.Unlock()
+			return
+		}
+		b.dispatching[eventType] = true
+		b.mutex.Unlock()
+
+		defer func() {
+			b.mutex.Lock()
+			delete(b.dispatching, eventType)
+			b.mutex.Unlock()
+		}()
+
+		detail := js.Global().Get("JSON").Call("parse", string(payload))
+		init := js.Global().Get("Object").New()
+		init.Set("detail", detail)
+		b.target.Call("dispatchEvent", js.Global().Get("CustomEvent").New(string(eventType), init))
+	})
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		sub.Cancel()
+		return
+	}
+	b.subscriptions = append(b.subscriptions, sub)
+}
+
+// Listen publishes DOM events named eventType that reach the target on the
+// bus. Each is published as an eventbus.RawEvent whose payload is the
+// JSON encoding of the event's detail, which can be decoded with
+// eventbus.Payload or RawEvent.Decode.
+//
+// Events dispatched by Forward for the same type are not published back,
+// so a type can be forwarded and listened to without looping.
+func (b *Bridge) Listen(eventType eventbus.EventType) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+	if _, ok := b.listeners[eventType]; ok {
+		return
+	}
+
+	listener := js.FuncOf(func(this js.Value, args []js.Value) any {
+		b.mutex.Lock()
+		forwarded := b.dispatching[eventType]
+		b.mutex.Unlock()
+		if forwarded || len(args) == 0 {
+			return nil
+		}
+
+		payload := "null"
+		if detail := args[0].Get("detail"); !detail.IsUndefined() {
+			payload = js.Global().Get("JSON").Call("stringify", detail).String()
+		}
+		b.bus.Publish(eventbus.RawEvent{Type: eventType, Payload: json.RawMessage(payload)})
+		return nil
+	})
+	b.target.Call("addEventListener", string(eventType), listener)
+	b.listeners[eventType] = listener
+}
+
+// Close removes the DOM listeners and cancels the bus subscriptions of
+// Forward.
+func (b *Bridge) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for eventType, listener := range b.listeners {
+		b.target.Call("removeEventListener", string(eventType), listener)
+		listener.Release()
+	}
+	b.listeners = nil
+	for _, sub := range b.subscriptions {
+		sub.Cancel()
+	}
+	b.subscriptions = nil
+}
@@ -0,0 +1,119 @@
+//go:build js && wasm
+
+package dombridge
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+)
+
+type scoreEvent struct {
+	Player string `json:"player"`
+	Points int    `json:"points"`
+}
+
+func (e scoreEvent) GetType() eventbus.EventType {
+	return "player:scored"
+}
+
+// newTarget creates a standalone EventTarget for a test
+func newTarget() js.Value {
+	return js.Global().Get("EventTarget").New()
+}
+
+// TestForward verifies that bus events are dispatched as custom events
+func TestForward(t *testing.T) {
+	bus := eventbus.New()
+	target := newTarget()
+	bridge := New(bus, target)
+	defer bridge.Close()
+
+	var player string
+	var points int
+	listener := js.FuncOf(func(this js.Value, args []js.Value) any {
+		detail := args[0].Get("detail")
+		player = detail.Get("player").String()
+		points = detail.Get("points").Int()
+		return nil
+	})
+	defer listener.Release()
+	target.Call("addEventListener", "player:scored", listener)
+
+	bridge.Forward("player:scored")
+	bus.Publish(scoreEvent{Player: "alice", Points: 3})
+
+	if player != "alice" || points != 3 {
+		t.Errorf("Expected detail alice/3, got %s/%d", player, points)
+	}
+}
+
+// TestListen verifies that custom events are published on the bus
+func TestListen(t *testing.T) {
+	bus := eventbus.New()
+	target := newTarget()
+	bridge := New(bus, target)
+
+	var received scoreEvent
+	bus.Subscribe("player:scored", func(event eventbus.Event) {
+		received, _ = eventbus.Payload[scoreEvent](event)
+	})
+	bridge.Listen("player:scored")
+
+	dispatch := func() {
+		detail := js.Global().Get("JSON").Call("parse", `{"player":"bob","points":5}`)
+		init := js.Global().Get("Object").New()
+		init.Set("detail", detail)
+		target.Call("dispatchEvent", js.Global().Get("CustomEvent").New("player:scored", init))
+	}
+
+	dispatch()
+	if received.Player != "bob" || received.Points != 5 {
+		t.Errorf("Expected bob/5, got %+v", received)
+	}
+
+	bridge.Close()
+	received = scoreEvent{}
+	dispatch()
+	if received.Player != "" {
+		t.Errorf("Expected no delivery after Close, got %+v", received)
+	}
+}
+
+// TestForwardAndListenNoLoop verifies that forwarded events are not published back
+func TestForwardAndListenNoLoop(t *testing.T) {
+	bus := eventbus.New()
+	bridge := New(bus, newTarget())
+	defer bridge.Close()
+
+	count := 0
+	bus.Subscribe("player:scored", func(event eventbus.Event) {
+		count++
+	})
+	bridge.Forward("player:scored")
+	bridge.Listen("player:scored")
+
+	bus.Publish(scoreEvent{Player: "carol", Points: 1})
+
+	if count != 1 {
+		t.Errorf("Expected 1 delivery, got %d", count)
+	}
+}
+
+// TestCloseCancelsForward verifies that Close cancels the bus subscriptions of Forward
+func TestCloseCancelsForward(t *testing.T) {
+	bus := eventbus.New()
+	bridge := New(bus, newTarget())
+	bridge.Forward("player:scored")
+	bridge.Forward("player:jumped")
+
+	bridge.Close()
+	if subscriptions := bus.Stats().Subscriptions; subscriptions != 0 {
+		t.Errorf("Expected no subscriptions after Close, got %d", subscriptions)
+	}
+	bridge.Forward("player:died")
+	if subscriptions := bus.Stats().Subscriptions; subscriptions != 0 {
+		t.Errorf("Expected Forward after Close to subscribe nothing, got %d", subscriptions)
+	}
+}
@@ -0,0 +1,102 @@
+package eventbus
+
+import "sync/atomic"
+
+// DropReason tells why the bus discarded an event.
+type DropReason int
+
+const (
+	// DropQueueFull marks deliveries discarded by the OverflowDropNewest
+	// or OverflowDropOldest policy of an asynchronous topic.
+	DropQueueFull DropReason = iota
+	// DropCancelled marks deliveries discarded because the publisher's
+	// context was done while it waited for room in a full queue. Their
+	// Delivery.Err is the context's error, with ErrTimeout for a deadline.
+	DropCancelled
+	// DropBufferFull marks events discarded by a SendBuffer created for
+	// the bus.
+	DropBufferFull
+	// DropExpired marks deliveries skipped by a listener subscribed with
+	// WithMaxAge because the event was too old.
+	DropExpired
+)
+
+// String returns the name of the reason, for logs and metric labels.
+func (r DropReason) String() string {
+	switch r {
+	case DropQueueFull:
+		return "queue_full"
+	case DropCancelled:
+		return "cancelled"
+	case DropBufferFull:
+		return "buffer_full"
+	case DropExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// WithOnDrop calls onDrop whenever the bus discards an event, so data loss
+// is observable instead of silent. The drops are also counted per reason in
+// Stats.
+//
+// onDrop runs on the goroutine that dropped the event, often a publisher
+// holding the bus lock, so it must return quickly and must not publish on
+// the bus. An event dropped for several listeners of an asynchronous topic
+// is reported once per listener.
+//
+// Example:
+//
+//	bus := eventbus.New(
+//	    eventbus.WithAsync(4, 1024),
+//	    eventbus.WithOnDrop(func(reason eventbus.DropReason, event eventbus.Event) {
+//	        metrics.Counter("eventbus.dropped", "reason", reason.String(), "type", string(event.GetType())).Inc()
+//	    }),
+//	)
+func WithOnDrop(onDrop func(reason DropReason, event Event)) Option {
+	return func(bus *eventBusImpl) {
+		bus.drops.onDrop = onDrop
+	}
+}
+
+// dropCounter counts the drops of a bus per reason and reports them to
+// the WithOnDrop callback.
+type dropCounter struct {
+	queueFull  atomic.Uint64
+	cancelled  atomic.Uint64
+	bufferFull atomic.Uint64
+	expired    atomic.Uint64
+	onDrop     func(DropReason, Event)
+	// hook is Hooks.OnDrop.
+	hook func(DropReason, Event)
+}
+
+// record counts n drops of event for reason. It does nothing on a nil
+// counter.
+func (d *dropCounter) record(reason DropReason, event Event, n uint64) {
+	if d == nil {
+		return
+	}
+
+	switch reason {
+	case DropQueueFull:
+		d.queueFull.Add(n)
+	case DropCancelled:
+		d.cancelled.Add(n)
+	case DropBufferFull:
+		d.bufferFull.Add(n)
+	case DropExpired:
+		d.expired.Add(n)
+	}
+	if d.onDrop != nil {
+		for range n {
+			d.onDrop(reason, event)
+		}
+	}
+	if d.hook != nil {
+		for range n {
+			d.hook(reason, event)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// dropRecorder collects the drops reported to WithOnDrop.
+type dropRecorder struct {
+	reasons []DropReason
+	events  []Event
+	mutex   sync.Mutex
+}
+
+func (r *dropRecorder) record(reason DropReason, event Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.reasons = append(r.reasons, reason)
+	r.events = append(r.events, event)
+}
+
+// TestOnDropQueueFull verifies that overflowing deliveries are reported and counted
+func TestOnDropQueueFull(t *testing.T) {
+	var drops dropRecorder
+	bus := New(WithOnDrop(drops.record), WithTopicConfig("telemetry:*", TopicConfig{
+		Async:     true,
+		Workers:   1,
+		QueueSize: 1,
+		Overflow:  OverflowDropNewest,
+	}))
+	defer bus.Close()
+
+	running := make(chan struct{})
+	release := make(chan struct{})
+	bus.Subscribe("telemetry:frame", func(event Event) {
+		running <- struct{}{}
+		<-release
+	})
+
+	bus.Publish(testEvent{eventType: "telemetry:frame", data: "1"})
+	<-running
+	bus.Publish(testEvent{eventType: "telemetry:frame", data: "2"})
+	bus.Publish(testEvent{eventType: "telemetry:frame", data: "3"})
+
+	drops.mutex.Lock()
+	if len(drops.reasons) != 1 || drops.reasons[0] != DropQueueFull || drops.events[0].(testEvent).data != "3" {
+		t.Errorf("Expected event 3 dropped for queue_full, got %v %v", drops.reasons, drops.events)
+	}
+	drops.mutex.Unlock()
+
+	stats := bus.Stats()
+	if stats.DroppedQueueFull != 1 || stats.Dropped != 1 {
+		t.Errorf("Expected 1 queue_full drop, got %+v", stats)
+	}
+
+	close(release)
+	<-running
+}
+
+// TestOnDropCancelled verifies that deliveries abandoned by the publisher are reported as cancelled with the context error
+func TestOnDropCancelled(t *testing.T) {
+	var drops dropRecorder
+	bus := New(WithOnDrop(drops.record), WithAsync(1, 1))
+	defer bus.Close()
+
+	running := make(chan struct{})
+	release := make(chan struct{})
+	bus.Subscribe("test:event", func(event Event) {
+		running <- struct{}{}
+		<-release
+	})
+
+	bus.Publish(testEvent{eventType: "test:event"})
+	<-running
+	bus.Publish(testEvent{eventType: "test:event"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	receipt, err := bus.PublishDetailed(ctx, testEvent{eventType: "test:event"})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+	if len(receipt.Deliveries) != 1 || !errors.Is(receipt.Deliveries[0].Err, context.DeadlineExceeded) || errors.Is(receipt.Deliveries[0].Err, ErrQueueFull) {
+		t.Errorf("Expected the delivery to report the deadline, got %+v", receipt.Deliveries)
+	}
+
+	drops.mutex.Lock()
+	if len(drops.reasons) != 1 || drops.reasons[0] != DropCancelled {
+		t.Errorf("Expected a cancelled drop, got %v", drops.reasons)
+	}
+	drops.mutex.Unlock()
+	if stats := bus.Stats(); stats.DroppedCancelled != 1 || stats.Dropped != 1 {
+		t.Errorf("Expected 1 cancelled drop, got %+v", stats)
+	}
+
+	close(release)
+	<-running
+}
+
+// TestOnDropBufferFull verifies that send buffer drops are reported on the bus
+func TestOnDropBufferFull(t *testing.T) {
+	var drops dropRecorder
+	bus := New(WithOnDrop(drops.record))
+
+	release := make(chan struct{})
+	sent := make(chan struct{}, 1)
+	buffer := NewSendBuffer(bus, "test", BufferConfig{Size: 1, Overflow: OverflowDropOldest}, func(event Event) {
+		sent <- struct{}{}
+		<-release
+	})
+
+	buffer.Push(context.Background(), testEvent{eventType: "test:event", data: "1"})
+	<-sent
+	buffer.Push(context.Background(), testEvent{eventType: "test:event", data: "2"})
+	buffer.Push(context.Background(), testEvent{eventType: "test:event", data: "3"})
+
+	drops.mutex.Lock()
+	if len(drops.reasons) != 1 || drops.reasons[0] != DropBufferFull || drops.events[0].(testEvent).data != "2" {
+		t.Errorf("Expected event 2 dropped for buffer_full, got %v %v", drops.reasons, drops.events)
+	}
+	drops.mutex.Unlock()
+	if stats := bus.Stats(); stats.BufferDropped != 1 || stats.Dropped != 0 {
+		t.Errorf("Expected 1 buffer drop outside Dropped, got %+v", stats)
+	}
+
+	close(release)
+	buffer.Close()
+}
+
+// TestDropReasonString verifies the names of the drop reasons
+func TestDropReasonString(t *testing.T) {
+	tests := map[DropReason]string{
+		DropQueueFull:  "queue_full",
+		DropCancelled:  "cancelled",
+		DropBufferFull: "buffer_full",
+		DropExpired:    "expired",
+		DropReason(99): "unknown",
+	}
+	for reason, want := range tests {
+		if got := reason.String(); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
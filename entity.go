@@ -0,0 +1,178 @@
+package eventbus
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// EntityEvent is an event concerning one entity, delivered by Entities
+// only to the listeners of that entity.
+type EntityEvent[K comparable] interface {
+	Event
+	// Entity returns the ID of the entity the event concerns.
+	Entity() K
+}
+
+// ScopedTopic is an event type narrowed to one entity, created with
+// Scoped. It is comparable, so routing an event to it needs no string
+// building.
+type ScopedTopic[K comparable] struct {
+	Type   EventType
+	Entity K
+}
+
+// Scoped returns the topic of the events of eventType concerning entity.
+func Scoped[K comparable](eventType EventType, entity K) ScopedTopic[K] {
+	return ScopedTopic[K]{Type: eventType, Entity: entity}
+}
+
+// Entities routes the events of entity-scoped topics, the dominant pattern
+// of entity component systems. It subscribes once per event type and
+// looks up the listeners of the entity named by each EntityEvent, so a
+// publish costs one map lookup however many entities listen, and Despawn
+// removes all listeners of an entity at once. Events that are not
+// EntityEvents with key type K are ignored. It is safe for concurrent use.
+//
+// Example:
+//
+//	entities := eventbus.NewEntities[EntityID](bus)
+//
+//	entities.Subscribe(eventbus.Scoped("player:damaged", player.ID), player.OnDamaged)
+//	bus.Publish(PlayerDamaged{Target: player.ID, Amount: 10})
+//
+//	// When the player despawns
+//	entities.Despawn(player.ID)
+type Entities[K comparable] struct {
+	bus   EventBus
+	opts  []SubscribeOption
+	mutex sync.RWMutex
+	// topics holds the bus subscription of every event type with scoped
+	// listeners.
+	topics map[EventType]*entityTopic
+	// listeners is never modified in place, since deliveries may hold
+	// its slices.
+	listeners map[ScopedTopic[K]][]*ScopedSubscription
+	byEntity  map[K][]*ScopedSubscription
+}
+
+// entityTopic is the bus subscription of one event type.
+type entityTopic struct {
+	sub       *Subscription
+	listeners int
+}
+
+// ScopedSubscription is a listener subscribed to a ScopedTopic.
+type ScopedSubscription struct {
+	cancel    func()
+	listener  ContextListener
+	cancelled atomic.Bool
+}
+
+// NewEntities creates a router for entity-scoped topics on bus. opts are
+// applied to the subscription of every event type, so WithName or
+// WithStage place all scoped listeners of the type together.
+func NewEntities[K comparable](bus EventBus, opts ...SubscribeOption) *Entities[K] {
+	return &Entities[K]{
+		bus:       bus,
+		opts:      opts,
+		topics:    make(map[EventType]*entityTopic),
+		listeners: make(map[ScopedTopic[K]][]*ScopedSubscription),
+		byEntity:  make(map[K][]*ScopedSubscription),
+	}
+}
+
+// Subscribe registers listener for the events of topic.
+func (e *Entities[K]) Subscribe(topic ScopedTopic[K], listener EventListener) *ScopedSubscription {
+	return e.SubscribeContext(topic, func(ctx context.Context, event Event) {
+		listener(event)
+	})
+}
+
+// SubscribeContext registers listener for the events of topic, passing
+// the delivery context.
+func (e *Entities[K]) SubscribeContext(topic ScopedTopic[K], listener ContextListener) *ScopedSubscription {
+	s := &ScopedSubscription{listener: listener}
+	s.cancel = func() { e.remove(topic, s) }
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	t, ok := e.topics[topic.Type]
+	if !ok {
+		t = &entityTopic{sub: e.bus.SubscribeContext(topic.Type, e.deliver, e.opts...)}
+		e.topics[topic.Type] = t
+	}
+	t.listeners++
+	e.listeners[topic] = append(slices.Clip(e.listeners[topic]), s)
+	e.byEntity[topic.Entity] = append(e.byEntity[topic.Entity], s)
+	return s
+}
+
+// Despawn cancels every listener of entity.
+func (e *Entities[K]) Despawn(entity K) {
+	e.mutex.RLock()
+	subscriptions := slices.Clone(e.byEntity[entity])
+	e.mutex.RUnlock()
+	for _, s := range subscriptions {
+		s.Cancel()
+	}
+}
+
+// Len returns the number of listeners of entity.
+func (e *Entities[K]) Len(entity K) int {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return len(e.byEntity[entity])
+}
+
+// deliver calls the listeners of the entity of event.
+func (e *Entities[K]) deliver(ctx context.Context, event Event) {
+	scoped, ok := event.(EntityEvent[K])
+	if !ok {
+		return
+	}
+	e.mutex.RLock()
+	listeners := e.listeners[ScopedTopic[K]{Type: event.GetType(), Entity: scoped.Entity()}]
+	e.mutex.RUnlock()
+	for _, s := range listeners {
+		if !s.cancelled.Load() {
+			s.listener(ctx, event)
+		}
+	}
+}
+
+// remove unregisters s from topic, cancelling the bus subscription of the
+// event type once it has no listeners left.
+func (e *Entities[K]) remove(topic ScopedTopic[K], s *ScopedSubscription) {
+	isThis := func(other *ScopedSubscription) bool { return other == s }
+
+	e.mutex.Lock()
+	e.listeners[topic] = slices.DeleteFunc(slices.Clone(e.listeners[topic]), isThis)
+	if len(e.listeners[topic]) == 0 {
+		delete(e.listeners, topic)
+	}
+	e.byEntity[topic.Entity] = slices.DeleteFunc(e.byEntity[topic.Entity], isThis)
+	if len(e.byEntity[topic.Entity]) == 0 {
+		delete(e.byEntity, topic.Entity)
+	}
+	var unsubscribe *Subscription
+	t := e.topics[topic.Type]
+	if t.listeners--; t.listeners == 0 {
+		unsubscribe = t.sub
+		delete(e.topics, topic.Type)
+	}
+	e.mutex.Unlock()
+
+	if unsubscribe != nil {
+		unsubscribe.Cancel()
+	}
+}
+
+// Cancel unsubscribes the listener. It may be called from within the
+// listener and more than once.
+func (s *ScopedSubscription) Cancel() {
+	if s.cancelled.CompareAndSwap(false, true) {
+		s.cancel()
+	}
+}
@@ -0,0 +1,96 @@
+package eventbus
+
+import "testing"
+
+// hitEvent is an EntityEvent keyed by entity number.
+type hitEvent struct {
+	target uint64
+	amount int
+}
+
+func (e hitEvent) GetType() EventType {
+	return "player:hit"
+}
+
+func (e hitEvent) Entity() uint64 {
+	return e.target
+}
+
+// TestEntitiesRouting verifies that scoped listeners receive only the
+// events of their entity
+func TestEntitiesRouting(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	entities := NewEntities[uint64](bus)
+	received := map[uint64]int{}
+
+	for _, id := range []uint64{1, 2} {
+		entities.Subscribe(Scoped[uint64]("player:hit", id), func(event Event) {
+			received[id] += event.(hitEvent).amount
+		})
+	}
+	bus.Publish(hitEvent{target: 1, amount: 10})
+	bus.Publish(hitEvent{target: 2, amount: 3})
+	bus.Publish(hitEvent{target: 3, amount: 7})
+	bus.Publish(testEvent{eventType: "player:hit"})
+
+	if received[1] != 10 || received[2] != 3 {
+		t.Errorf("Expected 10 damage to 1 and 3 to 2, got %v", received)
+	}
+	if stats := bus.Stats(); stats.Subscriptions != 1 {
+		t.Errorf("Expected 1 bus subscription for the event type, got %d", stats.Subscriptions)
+	}
+}
+
+// TestEntitiesDespawn verifies that Despawn removes every listener of an
+// entity and the bus subscription once no listener is left
+func TestEntitiesDespawn(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	entities := NewEntities[uint64](bus)
+	count := 0
+
+	entities.Subscribe(Scoped[uint64]("player:hit", 1), func(event Event) { count++ })
+	entities.Subscribe(Scoped[uint64]("player:hit", 1), func(event Event) { count++ })
+	entities.Subscribe(Scoped[uint64]("player:healed", 1), func(event Event) { count++ })
+	other := entities.Subscribe(Scoped[uint64]("player:hit", 2), func(event Event) { count++ })
+	if entities.Len(1) != 3 {
+		t.Errorf("Expected 3 listeners of entity 1, got %d", entities.Len(1))
+	}
+
+	entities.Despawn(1)
+	bus.Publish(hitEvent{target: 1, amount: 10})
+	if count != 0 {
+		t.Errorf("Expected no deliveries after despawning, got %d", count)
+	}
+	if entities.Len(1) != 0 {
+		t.Errorf("Expected 0 listeners of entity 1, got %d", entities.Len(1))
+	}
+
+	other.Cancel()
+	other.Cancel()
+	if stats := bus.Stats(); stats.Subscriptions != 0 {
+		t.Errorf("Expected 0 bus subscriptions, got %d", stats.Subscriptions)
+	}
+}
+
+// TestEntitiesCancelDuringDelivery verifies that a listener may despawn
+// its entity while being called
+func TestEntitiesCancelDuringDelivery(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	entities := NewEntities[uint64](bus)
+	count := 0
+
+	entities.Subscribe(Scoped[uint64]("player:hit", 1), func(event Event) {
+		count++
+		entities.Despawn(1)
+	})
+	entities.Subscribe(Scoped[uint64]("player:hit", 1), func(event Event) { count++ })
+	bus.Publish(hitEvent{target: 1, amount: 10})
+	bus.Publish(hitEvent{target: 1, amount: 10})
+
+	if count != 1 {
+		t.Errorf("Expected 1 delivery before the entity despawned, got %d", count)
+	}
+}
@@ -0,0 +1,77 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// Envelope wraps a published event with the metadata recorded
+// when it was published.
+type Envelope struct {
+	// Sequence is the position of the event in its stream, starting at 1.
+	Sequence uint64
+	// BusSequence is the position of the event among the events published
+	// on its bus, starting at 1. It is assigned even without a store, so
+	// listeners can re-establish the publish order of events delivered
+	// concurrently.
+	BusSequence uint64
+	// Time is the wall-clock time at which the event was recorded.
+	Time time.Time
+	// OriginTime is the time at which the event was first published. It
+	// equals Time unless the event was published with WithOriginTime, as
+	// bridges and replays do, and is what WithMaxAge compares against.
+	OriginTime time.Time
+	// Monotonic is the monotonic clock reading at which the event was
+	// published, as the time elapsed since the bus was created. Unlike
+	// Time, it never jumps, but it is only comparable between events of
+	// the same bus.
+	Monotonic time.Duration
+	// CorrelationID links related events, such as a request and its
+	// responses. It is taken from events implementing Correlated.
+	CorrelationID string
+	// Metadata holds the context values recorded with WithContextFields.
+	Metadata map[string]string
+	// Publisher is the component of the Publisher handle that published
+	// the event, or "" if it was published on the bus directly.
+	Publisher string
+	// Event is the published event.
+	Event Event
+}
+
+// envelopeKey is the context key of the envelope of the event being
+// delivered.
+type envelopeKey struct{}
+
+// EnvelopeFromContext returns the envelope of the event being delivered to
+// a listener subscribed with SubscribeContext. Sequence is 0 if the bus
+// has no store. Queueing latency can be measured with time.Since(envelope.Time),
+// which uses the monotonic clock.
+//
+// Example:
+//
+//	bus.SubscribeContext("order:placed", func(ctx context.Context, event eventbus.Event) {
+//	    envelope, _ := eventbus.EnvelopeFromContext(ctx)
+//	    queueLatency.Observe(time.Since(envelope.Time).Seconds())
+//	})
+func EnvelopeFromContext(ctx context.Context) (Envelope, bool) {
+	envelope, ok := ctx.Value(envelopeKey{}).(*Envelope)
+	if !ok {
+		return Envelope{}, false
+	}
+	return *envelope, true
+}
+
+// Correlated is implemented by events that carry a correlation ID.
+// The ID is copied into the envelope when the event is recorded so that
+// related events can be found together.
+type Correlated interface {
+	CorrelationID() string
+}
+
+// correlationID returns the correlation ID of event, if it has one.
+func correlationID(event Event) string {
+	if c, ok := event.(Correlated); ok {
+		return c.CorrelationID()
+	}
+	return ""
+}
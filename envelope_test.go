@@ -0,0 +1,88 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestEnvelopeFromContext verifies that listeners see monotonic bus sequences and timestamps
+func TestEnvelopeFromContext(t *testing.T) {
+	bus := New(WithAsync(4, 64))
+
+	var mutex sync.Mutex
+	var envelopes []Envelope
+	for _, eventType := range []EventType{"order:placed", "order:shipped"} {
+		bus.SubscribeContext(eventType, func(ctx context.Context, event Event) {
+			envelope, ok := EnvelopeFromContext(ctx)
+			if !ok {
+				t.Error("Expected an envelope in the listener context")
+			}
+			mutex.Lock()
+			envelopes = append(envelopes, envelope)
+			mutex.Unlock()
+		})
+	}
+
+	for i := 0; i < 10; i++ {
+		eventType := EventType("order:placed")
+		if i%2 == 1 {
+			eventType = "order:shipped"
+		}
+		bus.Publish(testEvent{eventType: eventType})
+	}
+	bus.Close()
+
+	if len(envelopes) != 10 {
+		t.Fatalf("Expected 10 envelopes, got %d", len(envelopes))
+	}
+	bySequence := make(map[uint64]Envelope)
+	for _, envelope := range envelopes {
+		bySequence[envelope.BusSequence] = envelope
+		if envelope.Sequence != 0 {
+			t.Errorf("Expected no store sequence without a store, got %d", envelope.Sequence)
+		}
+	}
+	for sequence := uint64(1); sequence <= 10; sequence++ {
+		envelope, ok := bySequence[sequence]
+		if !ok {
+			t.Fatalf("Expected bus sequence %d, got %v", sequence, bySequence)
+		}
+		if previous, ok := bySequence[sequence-1]; ok && envelope.Monotonic < previous.Monotonic {
+			t.Errorf("Expected monotonic timestamps to follow bus sequences, got %v after %v", envelope.Monotonic, previous.Monotonic)
+		}
+		if envelope.Time.IsZero() || time.Since(envelope.Time) < 0 {
+			t.Errorf("Expected a wall-clock time, got %v", envelope.Time)
+		}
+	}
+}
+
+// TestEnvelopeStored verifies that the store keeps the stamps assigned by the bus
+func TestEnvelopeStored(t *testing.T) {
+	store := NewMemoryStore()
+	bus := New(WithStore(store))
+
+	var delivered Envelope
+	bus.SubscribeContext("order:placed", func(ctx context.Context, event Event) {
+		delivered, _ = EnvelopeFromContext(ctx)
+	})
+	bus.Publish(testEvent{eventType: "order:shipped"})
+	bus.Publish(testEvent{eventType: "order:placed"})
+
+	var stored []Envelope
+	store.Read(1, func(envelope Envelope) error {
+		stored = append(stored, envelope)
+		return nil
+	})
+
+	if len(stored) != 2 {
+		t.Fatalf("Expected 2 stored envelopes, got %d", len(stored))
+	}
+	if stored[1].BusSequence != 2 || stored[1].Sequence != 2 {
+		t.Errorf("Expected sequence 2 and bus sequence 2, got %d and %d", stored[1].Sequence, stored[1].BusSequence)
+	}
+	if delivered.Sequence != stored[1].Sequence || delivered.Monotonic != stored[1].Monotonic || !delivered.Time.Equal(stored[1].Time) {
+		t.Errorf("Expected the delivered envelope to match the stored one, got %+v and %+v", delivered, stored[1])
+	}
+}
@@ -0,0 +1,95 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// Sentinel errors returned by the bus. Use errors.Is to test for them,
+// since they are usually wrapped with more context.
+var (
+	// ErrBusClosed is returned when publishing to a closed bus.
+	ErrBusClosed = errors.New("eventbus: bus is closed")
+
+	// ErrNoSubscribers is returned by PublishAndWait when no listener
+	// is registered for the event type. The event is still recorded.
+	ErrNoSubscribers = errors.New("eventbus: no subscribers")
+
+	// ErrQueueFull is reported for deliveries dropped because an
+	// asynchronous topic's queue was full.
+	ErrQueueFull = errors.New("eventbus: queue full")
+
+	// ErrTimeout is returned when the context deadline expires before
+	// all listeners have completed.
+	ErrTimeout = errors.New("eventbus: timed out")
+
+	// ErrHandlerPanic is matched by errors caused by a panicking listener.
+	ErrHandlerPanic = errors.New("eventbus: handler panicked")
+
+	// ErrBufferClosed is returned when pushing to a closed SendBuffer.
+	ErrBufferClosed = errors.New("eventbus: send buffer is closed")
+
+	// ErrStoreAppend is matched by errors caused by a store that failed
+	// to append an event. The event is not delivered.
+	ErrStoreAppend = errors.New("eventbus: store append failed")
+)
+
+// HandlerError describes the failure of a single listener.
+// Use errors.As to retrieve it from errors returned by the bus.
+//
+// Example:
+//
+//	var handlerErr *eventbus.HandlerError
+//	if errors.As(err, &handlerErr) {
+//	    log.Printf("%s failed handling %s: %v", handlerErr.Handler, handlerErr.EventType, handlerErr.Err)
+//	}
+type HandlerError struct {
+	// EventType is the type of the event being handled.
+	EventType EventType
+	// Handler is the label of the listener, if it has one.
+	Handler string
+	// Err is the underlying failure.
+	Err error
+}
+
+// Error returns a description including the event type and handler label.
+func (e *HandlerError) Error() string {
+	if e.Handler == "" {
+		return fmt.Sprintf("eventbus: listener for %q: %v", e.EventType, e.Err)
+	}
+	return fmt.Sprintf("eventbus: listener %s for %q: %v", e.Handler, e.EventType, e.Err)
+}
+
+// Unwrap returns the underlying failure.
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError holds the value recovered from a panicking listener.
+// It matches ErrHandlerPanic, and the recovered value too if it is an error.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+// newPanicError captures the current stack for a recovered value.
+func newPanicError(value any) *PanicError {
+	return &PanicError{Value: value, Stack: debug.Stack()}
+}
+
+// Error describes the recovered value.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns ErrHandlerPanic and, if the recovered value is an error,
+// that error.
+func (e *PanicError) Unwrap() []error {
+	if err, ok := e.Value.(error); ok {
+		return []error{ErrHandlerPanic, err}
+	}
+	return []error{ErrHandlerPanic}
+}
@@ -0,0 +1,95 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// TestErrHandlerPanic verifies that listener panics can be inspected with errors.Is/As
+func TestErrHandlerPanic(t *testing.T) {
+	bus := New()
+	bus.Subscribe("panic:test", func(event Event) {
+		panic(io.ErrUnexpectedEOF)
+	})
+
+	err := bus.PublishAndWait(context.Background(), testEvent{eventType: "panic:test"})
+
+	if !errors.Is(err, ErrHandlerPanic) {
+		t.Errorf("Expected ErrHandlerPanic, got %v", err)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected the panic value to be unwrapped, got %v", err)
+	}
+
+	var handlerErr *HandlerError
+	if !errors.As(err, &handlerErr) {
+		t.Fatalf("Expected a HandlerError, got %T", err)
+	}
+	if handlerErr.EventType != "panic:test" {
+		t.Errorf("Expected event type panic:test, got %q", handlerErr.EventType)
+	}
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || len(panicErr.Stack) == 0 {
+		t.Error("Expected a PanicError with a stack trace")
+	}
+}
+
+// TestErrNoSubscribers verifies the error for events nobody listens to
+func TestErrNoSubscribers(t *testing.T) {
+	bus := New()
+
+	err := bus.PublishAndWait(context.Background(), testEvent{eventType: "nobody:home"})
+	if !errors.Is(err, ErrNoSubscribers) {
+		t.Errorf("Expected ErrNoSubscribers, got %v", err)
+	}
+}
+
+// TestErrBusClosed verifies the error for publishing to a closed bus
+func TestErrBusClosed(t *testing.T) {
+	bus := New()
+	bus.Close()
+
+	err := bus.PublishAndWait(context.Background(), testEvent{eventType: "closed:test"})
+	if !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Expected ErrBusClosed, got %v", err)
+	}
+}
+
+// TestErrTimeout verifies that expired deadlines match both ErrTimeout and the context error
+func TestErrTimeout(t *testing.T) {
+	bus := New(WithAsync(1, 1))
+	release := make(chan struct{})
+	bus.Subscribe("slow:event", func(event Event) {
+		<-release
+	})
+	defer bus.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := bus.PublishAndWait(ctx, testEvent{eventType: "slow:event"})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// TestHandlerErrorMessage verifies that the handler label appears in the message
+func TestHandlerErrorMessage(t *testing.T) {
+	err := &HandlerError{EventType: "player:died", Handler: "AudioSystem", Err: ErrQueueFull}
+
+	expected := `eventbus: listener AudioSystem for "player:died": eventbus: queue full`
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+	if !errors.Is(err, ErrQueueFull) {
+		t.Error("Expected HandlerError to unwrap to ErrQueueFull")
+	}
+}
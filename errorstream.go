@@ -0,0 +1,143 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrorsBuffer is the number of delivery errors the channel returned by
+// Errors holds. Once it is full, the oldest error is dropped for each new
+// one.
+const ErrorsBuffer = 256
+
+// DeliveryError describes a failed delivery reported on the channel
+// returned by Errors.
+type DeliveryError struct {
+	// EventType is the type of the event being delivered.
+	EventType EventType
+	// EventID is the bus sequence of the event, see Envelope.BusSequence.
+	EventID uint64
+	// Handler is the label of the listener, if it has one.
+	Handler string
+	// Err is the failure: a *PanicError for panics, ErrTimeout for
+	// deliveries PublishAndWait gave up on, or ErrBreakerOpen for skipped
+	// deliveries.
+	Err error
+	// Time is when the failure was observed.
+	Time time.Time
+}
+
+// Error describes the failed delivery.
+func (e DeliveryError) Error() string {
+	if e.Handler == "" {
+		return fmt.Sprintf("eventbus: delivering %q: %v", e.EventType, e.Err)
+	}
+	return fmt.Sprintf("eventbus: delivering %q to %s: %v", e.EventType, e.Handler, e.Err)
+}
+
+// Unwrap returns the underlying failure.
+func (e DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// errorStream is the bounded channel behind Errors.
+type errorStream struct {
+	errors chan DeliveryError
+	mutex  sync.Mutex
+	closed bool
+}
+
+// Errors returns a channel reporting failed deliveries: listener panics
+// and breaker rejections the bus recovers, and deliveries still running
+// when PublishAndWait or PublishDetailed time out. It is an alternative
+// to callback options for applications that prefer a single place to
+// consume failures. Every call returns the same channel.
+//
+// Failures are reported from the first call on. The channel holds
+// ErrorsBuffer errors and drops the oldest once full, so a slow consumer
+// never blocks delivery. It is closed by Close.
+//
+// Example:
+//
+//	go func() {
+//	    for failure := range bus.Errors() {
+//	        log.Println(failure)
+//	    }
+//	}()
+func (bus *eventBusImpl) Errors() <-chan DeliveryError {
+	bus.errors.CompareAndSwap(nil, &errorStream{errors: make(chan DeliveryError, ErrorsBuffer)})
+	stream := bus.errors.Load()
+	bus.mutex.Lock()
+	closed := bus.closed
+	bus.mutex.Unlock()
+	if closed {
+		// Created after Close, so shutdown did not close it.
+		stream.close()
+	}
+	return stream.errors
+}
+
+// reportError sends a failed delivery of event to the Errors channel, if
+// there is one, and to Hooks.OnError.
+func (bus *eventBusImpl) reportError(ctx context.Context, event Event, handler string, err error) {
+	stream := bus.errors.Load()
+	if stream == nil && bus.hooks.OnError == nil {
+		return
+	}
+	failure := DeliveryError{EventType: event.GetType(), Handler: handler, Err: err, Time: time.Now()}
+	if envelope, ok := ctx.Value(envelopeKey{}).(*Envelope); ok {
+		failure.EventID = envelope.BusSequence
+	}
+	if bus.hooks.OnError != nil {
+		bus.hooks.OnError(failure)
+	}
+	if stream != nil {
+		stream.send(failure)
+	}
+}
+
+// reportTimeouts reports the deliveries of receipt that had not completed
+// when PublishDetailed gave up.
+func (bus *eventBusImpl) reportTimeouts(ctx context.Context, event Event, receipt *Receipt, err error) {
+	if !errors.Is(err, ErrTimeout) {
+		return
+	}
+	for _, delivery := range receipt.Deliveries {
+		if !delivery.Completed {
+			bus.reportError(ctx, event, delivery.Handler, err)
+		}
+	}
+}
+
+// send queues failure, dropping the oldest queued errors to make room.
+func (s *errorStream) send(failure DeliveryError) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	for {
+		select {
+		case s.errors <- failure:
+			return
+		default:
+		}
+		select {
+		case <-s.errors:
+		default:
+		}
+	}
+}
+
+// close closes the channel once; later failures are discarded.
+func (s *errorStream) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.errors)
+	}
+}
@@ -0,0 +1,114 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestErrorsPanic verifies that recovered panics are reported on the channel
+func TestErrorsPanic(t *testing.T) {
+	bus := New(WithAsync(2, 16))
+	defer bus.Close()
+	failures := bus.Errors()
+
+	bus.Subscribe("player:health", func(event Event) {
+		panic("boom")
+	}, WithName("hud"))
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+
+	select {
+	case failure := <-failures:
+		if failure.EventType != "player:health" || failure.Handler != "hud" || failure.EventID == 0 {
+			t.Errorf("Expected a hud failure on player:health, got %+v", failure)
+		}
+		if !errors.Is(failure, ErrHandlerPanic) {
+			t.Errorf("Expected ErrHandlerPanic, got %v", failure.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a delivery error")
+	}
+}
+
+// TestErrorsTimeout verifies that deliveries still running when
+// PublishAndWait times out are reported
+func TestErrorsTimeout(t *testing.T) {
+	bus := New(WithAsync(2, 16))
+	defer bus.Close()
+	failures := bus.Errors()
+	release := make(chan struct{})
+	defer close(release)
+
+	bus.Subscribe("player:health", func(event Event) { <-release }, WithName("slow"))
+	bus.Subscribe("player:health", func(event Event) {}, WithName("fast"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.PublishAndWait(ctx, healthEvent{playerID: "p1", hp: 10}); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+
+	select {
+	case failure := <-failures:
+		if failure.Handler != "slow" || !errors.Is(failure, ErrTimeout) {
+			t.Errorf("Expected a timeout of slow, got %+v", failure)
+		}
+	default:
+		t.Fatal("Expected a delivery error")
+	}
+	if len(failures) != 0 {
+		t.Errorf("Expected 1 delivery error, got %d more", len(failures))
+	}
+}
+
+// TestErrorsDropOldest verifies that a full channel drops its oldest errors
+func TestErrorsDropOldest(t *testing.T) {
+	bus := New()
+	failures := bus.Errors()
+
+	bus.Subscribe("player:health", func(event Event) {
+		panic(event.(healthEvent).hp)
+	})
+	for hp := range ErrorsBuffer + 2 {
+		bus.PublishAndWait(context.Background(), healthEvent{playerID: "p1", hp: hp})
+	}
+
+	if len(failures) != ErrorsBuffer {
+		t.Fatalf("Expected %d errors, got %d", ErrorsBuffer, len(failures))
+	}
+	var panicErr *PanicError
+	if failure := <-failures; !errors.As(failure, &panicErr) || panicErr.Value != 2 {
+		t.Errorf("Expected the oldest error to be for hp 2, got %v", failure)
+	}
+}
+
+// TestErrorsClosed verifies that the channel is closed by Close and that
+// cancelled subscriptions are not reported
+func TestErrorsClosed(t *testing.T) {
+	bus := New(WithAsync(1, 16))
+	failures := bus.Errors()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var sub *Subscription
+	sub = bus.Subscribe("player:health", func(event Event) {
+		if event.(healthEvent).hp == 1 {
+			close(started)
+			<-release
+		}
+	})
+	bus.Publish(healthEvent{playerID: "p1", hp: 1})
+	<-started
+	bus.Publish(healthEvent{playerID: "p1", hp: 2})
+	sub.Cancel()
+	close(release)
+	bus.Close()
+
+	for failure := range failures {
+		t.Errorf("Expected no delivery errors, got %v", failure)
+	}
+	if _, open := <-bus.Errors(); open {
+		t.Error("Expected the channel to stay closed")
+	}
+}
@@ -40,25 +40,30 @@
 //	})
 package eventbus
 
-import "sync"
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Papiermond/eventbus/internal/core"
+)
 
 // EventType represents the type identifier for an event.
 // It's used to match events with their subscribers.
-type EventType string
+type EventType = core.EventType
 
 // Event is the interface that all events must implement.
 // Events should be immutable value types for thread safety.
-type Event interface {
-	// GetType returns the event's type identifier.
-	// This is used to route events to the appropriate subscribers.
-	GetType() EventType
-}
+// The events of package tinybus are the same type.
+type Event = core.Event
 
 // EventListener is a function that handles an event.
 // Listeners are called synchronously when an event is published.
 // Listeners should not block for long periods as they will delay
 // other listeners and the publisher.
-type EventListener func(Event)
+type EventListener = core.EventListener
 
 // EventBus provides thread-safe publish-subscribe functionality
 // for event-driven communication between components.
@@ -66,12 +71,23 @@ type EventBus interface {
 	// Subscribe registers a listener for a specific event type.
 	// Multiple listeners can subscribe to the same event type.
 	// Listeners are called in the order they were registered.
+	// Options can be passed to customize how the listener is invoked.
+	// The returned Subscription cancels the listener.
 	//
 	// Example:
 	//   bus.Subscribe("user:login", func(event Event) {
 	//       fmt.Println("User logged in:", event)
 	//   })
-	Subscribe(eventType EventType, listener EventListener)
+	Subscribe(eventType EventType, listener EventListener, opts ...SubscribeOption) *Subscription
+
+	// SubscribeContext registers a listener receiving a context with the
+	// values selected with WithContextFields, as recorded at publish time.
+	//
+	// Example:
+	//   bus.SubscribeContext("order:placed", func(ctx context.Context, event Event) {
+	//       log.Println("request", ctx.Value(requestIDKey{}))
+	//   })
+	SubscribeContext(eventType EventType, listener ContextListener, opts ...SubscribeOption) *Subscription
 
 	// Publish sends an event to all registered listeners for that event type.
 	// Listeners are called synchronously in registration order.
@@ -80,43 +96,624 @@ type EventBus interface {
 	// Example:
 	//   bus.Publish(UserLoginEvent{UserID: "123"})
 	Publish(event Event)
+
+	// PublishContext sends an event like Publish and records the values of
+	// ctx selected with WithContextFields, so listeners subscribed with
+	// SubscribeContext receive them even on another goroutine.
+	//
+	// Example:
+	//   bus.PublishContext(r.Context(), OrderPlaced{ID: "o-1"})
+	PublishContext(ctx context.Context, event Event)
+
+	// PublishAndWait sends an event to all registered listeners and returns
+	// once every listener has completed or ctx is done. Listener failures are
+	// returned as *HandlerError values joined with errors.Join; panics match
+	// ErrHandlerPanic. It returns ErrNoSubscribers if nobody listens for the
+	// event, ErrTimeout if the deadline expires, and ErrBusClosed after Close.
+	// On an asynchronous bus this waits for the worker goroutines; on a
+	// synchronous bus the listeners run on the calling goroutine.
+	//
+	// Example:
+	//   if err := bus.PublishAndWait(ctx, GameSaved{Slot: 1}); err != nil {
+	//       log.Println("save handlers failed:", err)
+	//   }
+	PublishAndWait(ctx context.Context, event Event) error
+
+	// PublishDetailed publishes like PublishAndWait but returns a Receipt
+	// describing the outcome of every listener, so critical publishers can
+	// log exactly which consumer failed. The error is only set when the
+	// event could not be published or ctx ended before all listeners
+	// completed; listener failures are reported in the receipt.
+	//
+	// Example:
+	//   receipt, err := bus.PublishDetailed(ctx, PaymentCaptured{ID: "p-1"})
+	//   for _, failed := range receipt.Failed() {
+	//       log.Printf("%s failed after %v: %v", failed.Handler, failed.Duration, failed.Err)
+	//   }
+	PublishDetailed(ctx context.Context, event Event) (*Receipt, error)
+
+	// Close stops accepting events, waits for queued events to be delivered,
+	// and releases the bus's worker goroutines. Events published after
+	// Close are dropped.
+	Close()
+
+	// Latest returns the most recent event published for eventType under key.
+	// It only reports events for topics configured with WithLastValueCache.
+	//
+	// Example:
+	//   if event, ok := bus.Latest("player:moved", "player-1"); ok {
+	//       fmt.Println("Last position:", event)
+	//   }
+	Latest(eventType EventType, key any) (Event, bool)
+
+	// ClearLatest removes the values cached for eventType under keys, or
+	// all of them without keys, once the state they describe is no
+	// longer valid.
+	//
+	// Example:
+	//   bus.ClearLatest("level:loaded")
+	ClearLatest(eventType EventType, keys ...any)
+
+	// History returns the recorded events of the bus for export and import.
+	// It requires a store configured with WithStore.
+	//
+	// Example:
+	//   bus.History().Export(file)
+	History() *History
+
+	// Snapshot returns an immutable description of the current
+	// subscriptions, which can re-register them on another bus.
+	//
+	// Example:
+	//   bus.Snapshot().CloneInto(newBus)
+	Snapshot() *Snapshot
+
+	// Stats returns counters describing the activity and load of the bus.
+	//
+	// Example:
+	//   stats := bus.Stats()
+	//   log.Printf("%d events published, %d deliveries dropped", stats.Published, stats.Dropped)
+	Stats() Stats
+
+	// WaitForCorrelated waits for the next event of eventType whose
+	// envelope carries correlationID, for request/response exchanges where
+	// responses to different requests interleave.
+	//
+	// Example:
+	//   response, err := bus.WaitForCorrelated(ctx, requestID, "quote:calculated")
+	WaitForCorrelated(ctx context.Context, correlationID string, eventType EventType) (Event, error)
+
+	// Begin starts a transaction whose staged events are only published
+	// on Commit and are discarded on Rollback.
+	//
+	// Example:
+	//   tx := bus.Begin()
+	//   defer tx.Rollback()
+	//   tx.Publish(OrderPlaced{ID: id})
+	//   return tx.Commit()
+	Begin() *Tx
+
+	// Mute suppresses the delivery of topics matching pattern until
+	// Unmute is called with the same pattern.
+	//
+	// Example:
+	//   bus.Mute("physics:*")
+	Mute(pattern EventType)
+
+	// Unmute removes a pattern added with Mute.
+	Unmute(pattern EventType)
+
+	// Solo suppresses the delivery of every topic not matching a soloed
+	// pattern until Unsolo is called with the same pattern.
+	//
+	// Example:
+	//   bus.Solo("audio:*")
+	Solo(pattern EventType)
+
+	// Unsolo removes a pattern added with Solo.
+	Unsolo(pattern EventType)
+
+	// Describe returns the documentation recorded with Document for
+	// eventType together with its Go type and current subscriptions.
+	//
+	// Example:
+	//   d := bus.Describe("player:died")
+	//   fmt.Println(d.Description, d.Owner, d.Producers, d.Consumers)
+	Describe(eventType EventType) TopicDescription
+
+	// DeclarePublisher records that component publishes topics, for
+	// Validate, Graph, and Describe.
+	//
+	// Example:
+	//   bus.DeclarePublisher("combat", "player:damaged", "player:died")
+	DeclarePublisher(component string, topics ...EventType)
+
+	// Validate reports declared topics nobody subscribes to and, once
+	// publishers are declared, subscribed topics nobody declared
+	// publishing, as a *WiringError.
+	//
+	// Example:
+	//   if err := bus.Validate(); err != nil {
+	//       t.Fatal(err)
+	//   }
+	Validate() error
+
+	// Graph returns the producer/consumer graph of the declared
+	// publishers and the subscriptions, for architecture documentation.
+	//
+	// Example:
+	//   for _, edge := range bus.Graph().Edges() {
+	//       fmt.Println(edge.Publisher, edge.EventType, edge.Handler)
+	//   }
+	Graph() FlowGraph
+
+	// Errors returns a channel reporting failed deliveries, bounded and
+	// dropping the oldest once full, and closed by Close.
+	//
+	// Example:
+	//   go func() {
+	//       for failure := range bus.Errors() {
+	//           log.Println(failure)
+	//       }
+	//   }()
+	Errors() <-chan DeliveryError
+
+	// ReadOnly returns a view of the bus that can subscribe but not
+	// publish, for code that must only observe events.
+	//
+	// Example:
+	//   overlay.Attach(bus.ReadOnly())
+	ReadOnly() EventBus
+
+	// Publisher returns a handle that can only publish, stamping
+	// component into the envelopes of its events.
+	//
+	// Example:
+	//   orders := NewOrderService(bus.Publisher("orders"))
+	Publisher(component string) *Publisher
+
+	// Restricted returns a view of the bus limited to the event types
+	// allowed by rules, for subsystems that must only use their own
+	// topics.
+	//
+	// Example:
+	//   billing.Start(bus.Restricted(Restriction{Allow: []EventType{"invoice:*"}}))
+	Restricted(rules Restriction) EventBus
+
+	// Reconfigure changes the options of the running bus that support it,
+	// such as the muted topics and the health thresholds.
+	//
+	// Example:
+	//   err := bus.Reconfigure(WithMuted("telemetry:*"))
+	Reconfigure(opts ...Option) error
+
+	// Healthy returns nil if the bus can accept and deliver events, and
+	// otherwise an error matching ErrUnhealthy describing every problem,
+	// for readiness and liveness probes.
+	//
+	// Example:
+	//   if err := bus.Healthy(); err != nil { w.WriteHeader(http.StatusServiceUnavailable) }
+	Healthy() error
+
+	// AddHealthCheck adds a check run by Healthy and returns a function
+	// removing it.
+	//
+	// Example:
+	//   remove := bus.AddHealthCheck("database", db.Ping)
+	AddHealthCheck(name string, check func() error) func()
 }
 
 // eventBusImpl is the internal implementation of EventBus.
 // It uses a mutex to ensure thread-safe access to the listeners map.
 type eventBusImpl struct {
-	listeners map[EventType][]EventListener
-	mutex     sync.Mutex
+	// listeners and subscriptions are guarded by subscribersMutex rather
+	// than mutex, so listeners can subscribe and cancel during delivery.
+	listeners map[EventType][]*Subscription
+	// router maps event types to subscribed topics; nil matches exactly.
+	router Router
+	// subscriptions lists the active subscriptions in order for Snapshot.
+	subscriptions []*Subscription
+	// dependent counts the subscriptions per topic declaring After or
+	// Before; topics without any skip dependency ordering.
+	dependent map[EventType]int
+	// publishers holds the components declared with DeclarePublisher per
+	// topic. It is also guarded by subscribersMutex.
+	publishers       map[EventType][]string
+	subscribersMutex sync.RWMutex
+	latest           map[EventType]*lastValueCache
+	store            EventStore
+	dispatch         *dispatchTable
+	serial           map[EventType]*serialTopic
+	contextFields    []ContextField
+	audit            *leakAudit
+	copyOnPublish    bool
+	inheritPriority  bool
+	immutability     *immutabilityCheck
+	knownTopics      *knownTopics
+	closed           bool
+	// types documents event types for Describe; nil uses DefaultTypes.
+	types *TypeRegistry
+	// scheduler runs deliveries after every publish; see
+	// WithSeededScheduling.
+	scheduler *SeededDispatcher
+	// retry calls panicking listeners again; see WithRetry.
+	retry RetryPolicy
+	// maxDepth limits nested publishing; see WithMaxPublishDepth.
+	maxDepth int
+	// maxDepthSet tells Reconfigure that maxDepth was given.
+	maxDepthSet bool
+	// holder describes the synchronous delivery holding mutex when
+	// maxDepth is set, so listeners publishing on the bus fail instead of
+	// deadlocking.
+	holder atomic.Pointer[lockHolder]
+	// panicEvents publishes recovered panics; see WithPanicEvents.
+	panicEvents bool
+	// panicReports admits the goroutines publishing them to sending.
+	panicReports panicReports
+	// memory bounds the last-value caches; see WithMemoryBudget.
+	memory *MemoryBudget
+	// hooks are the instrumentation callbacks; see WithHooks.
+	hooks Hooks
+	// errors is created by the first call to Errors.
+	errors atomic.Pointer[errorStream]
+	// trace reports listener invocations; see WithHandlerTrace.
+	trace func(HandlerTrace)
+	// health publishes subscription health transitions; see WithHealth.
+	health *healthMonitor
+	// started is the creation time of the bus, from which envelopes
+	// measure their monotonic timestamps.
+	started time.Time
+	// published and drops are counters reported by Stats. published
+	// also assigns bus sequences.
+	published atomic.Uint64
+	drops     dropCounter
+	// muting suppresses deliveries; see Mute and Solo.
+	muting muting
+	// latency records delivery latencies; see WithLatencyTracking.
+	latency *latencyTracker
+	// costs measures handler resource usage; see WithHandlerCosts.
+	costs *costTracker
+	// owners cancels subscriptions made with WithOwner.
+	owners ownerWatch
+	// checks are run by Healthy; see AddHealthCheck.
+	checks healthChecks
+	// storeFailure is the error of the last failed append to store,
+	// cleared by the next successful one.
+	storeFailure atomic.Pointer[error]
+	// periodic publishes heartbeats and stats until Close.
+	periodic []*periodic
+	// sending tracks Publish calls that are still enqueueing deliveries,
+	// so Close can wait for them before shutting the worker pool down.
+	sending sync.WaitGroup
+	mutex   sync.Mutex
 }
 
 // New creates a new event bus instance.
 // Each event bus is independent and maintains its own set of subscribers.
+// Options can be passed to enable optional features.
 //
 // Example:
 //
 //	bus := eventbus.New()
-func New() EventBus {
-	return &eventBusImpl{
-		listeners: make(map[EventType][]EventListener),
+func New(opts ...Option) EventBus {
+	bus := &eventBusImpl{
+		listeners: make(map[EventType][]*Subscription),
+		dependent: make(map[EventType]int),
+		latest:    make(map[EventType]*lastValueCache),
+		dispatch:  newDispatchTable(),
+		serial:    make(map[EventType]*serialTopic),
+		// Enabled in debug builds; replaced by WithImmutabilityCheck.
+		immutability: newImmutabilityCheck(),
+		// Enabled in debug builds; replaced by WithHandlerCosts.
+		costs:   newDebugCostTracker(),
+		started: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(bus)
+	}
+	for _, cache := range bus.latest {
+		cache.budget = bus.memory
 	}
+	bus.dispatch.onScale = func(event WorkersScaled) {
+		bus.publish(context.Background(), event, nil)
+	}
+	bus.dispatch.start(bus.audit)
+	if bus.scheduler != nil {
+		bus.publish(context.Background(), SchedulerSeeded{Seed: bus.scheduler.Seed()}, nil)
+	}
+	for _, p := range bus.periodic {
+		p.start(bus)
+	}
+	if bus.health != nil {
+		bus.health.start(bus)
+	}
+	return bus
 }
 
 // Subscribe registers a listener for a specific event type.
-func (bus *eventBusImpl) Subscribe(eventType EventType, listener EventListener) {
-	bus.mutex.Lock()
-	defer bus.mutex.Unlock()
+func (bus *eventBusImpl) Subscribe(eventType EventType, listener EventListener, opts ...SubscribeOption) *Subscription {
+	sub, err := bus.subscribe(eventType, func(ctx context.Context, event Event) {
+		listener(event)
+	}, opts, false)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
 
-	bus.listeners[eventType] = append(bus.listeners[eventType], listener)
+// subscribe registers listener with the subscribe options applied.
+// If usesContext is set, the listener's context is also cancelled when
+// the subscription is. It fails for unknown topics on a bus rejecting
+// them and for dependency cycles.
+func (bus *eventBusImpl) subscribe(eventType EventType, listener ContextListener, opts []SubscribeOption, usesContext bool) (*Subscription, error) {
+	if err := bus.knownTopics.check(eventType, "subscribe"); err != nil {
+		return nil, err
+	}
+	config := newSubscribeConfig(opts)
+	sub := &Subscription{
+		bus:       bus,
+		eventType: eventType,
+		opts:      slices.Clone(opts),
+		options:   config.options,
+		stage:     config.stage,
+		staged:    config.staged,
+		name:      config.name,
+		after:     config.after,
+		before:    config.before,
+		priority:  config.priority,
+	}
+	sub.ctx, sub.cancel = context.WithCancel(context.Background())
+	if bus.health != nil {
+		sub.health = newHealth(bus.health.settings())
+	}
+
+	sub.handler.Store(&handlerVersion{listener: listener})
+	sub.deliver = owned(expiring(config.wrap(sub.cancelling(sub.limiting(retrying(sub.handle, bus.retry, sub.ctx.Done()), config.maxConcurrency), config.until)), config.maxAge, &bus.drops), config.owner)
+	if usesContext {
+		wrapped := sub.deliver
+		sub.deliver = func(ctx context.Context, event Event) {
+			wrapped(cancelContext{Context: ctx, cancellation: sub.ctx}, event)
+		}
+	}
+	if bus.copyOnPublish {
+		sub.deliver = copying(sub.deliver)
+	}
+
+	bus.subscribersMutex.Lock()
+	listeners := insertListener(bus.listeners[eventType], sub)
+	if sub.hasDependencies() || bus.dependent[eventType] > 0 {
+		ordered, err := orderListeners(listeners)
+		if err != nil {
+			bus.subscribersMutex.Unlock()
+			sub.cancel()
+			return nil, err
+		}
+		listeners = ordered
+	}
+	if sub.hasDependencies() {
+		bus.dependent[eventType]++
+	}
+	if bus.router != nil && len(bus.listeners[eventType]) == 0 {
+		bus.router.Add(eventType)
+	}
+	bus.listeners[eventType] = listeners
+	bus.subscriptions = append(bus.subscriptions, sub)
+	bus.subscribersMutex.Unlock()
+	bus.owners.watch(bus, sub, config.owner)
+
+	if bus.hooks.OnSubscribe != nil {
+		bus.hooks.OnSubscribe(sub.info(sub.Health()))
+	}
+	return sub, nil
 }
 
 // Publish sends an event to all registered listeners for that event type.
 func (bus *eventBusImpl) Publish(event Event) {
-	bus.mutex.Lock()
-	defer bus.mutex.Unlock()
+	bus.PublishContext(context.Background(), event)
+}
+
+// publish records event and hands it to the dispatch route of its topic.
+// Synchronous routes deliver while holding the bus mutex, which keeps
+// deliveries of concurrent publishers from interleaving. If waiter is set,
+// every delivery reports its outcome to it.
+func (bus *eventBusImpl) publish(ctx context.Context, event Event, waiter *deliveryWaiter) error {
+	if err := bus.lock(ctx, event); err != nil {
+		return err
+	}
+	if err := bus.checkPublish(ctx, event); err != nil {
+		bus.mutex.Unlock()
+		return err
+	}
+	if bus.copyOnPublish {
+		// Listeners receive copies of this copy, so the publisher's
+		// event is never shared with them.
+		event = deepCopy(event)
+	}
+	parent := causeOf(ctx)
+	id := bus.published.Add(1)
+	metadata := bus.extractMetadata(ctx)
+	priority := priorityOf(ctx, event)
+	var origin *cause
+	if bus.inheritPriority {
+		priority, metadata, origin = inherit(ctx, event, id, priority, metadata)
+	} else if bus.maxDepth > 0 {
+		origin = &cause{id: id, eventType: event.GetType(), priority: priority, parent: parent, depth: depthOf(parent)}
+	}
+	now := time.Now()
+	envelope := &Envelope{
+		BusSequence:   id,
+		Time:          now,
+		OriginTime:    originTime(ctx, now),
+		Monotonic:     now.Sub(bus.started),
+		CorrelationID: correlationID(event),
+		Metadata:      metadata,
+		Publisher:     publisherOf(ctx),
+		Event:         event,
+	}
+	if envelope.Publisher != "" {
+		bus.notePublisher(envelope.Publisher, event.GetType())
+	}
+	if err := bus.record(envelope); err != nil {
+		bus.mutex.Unlock()
+		return err
+	}
+	if bus.hooks.OnPublish != nil {
+		bus.hooks.OnPublish(ctx, *envelope)
+	}
+	bus.subscribersMutex.RLock()
+	listeners := bus.matchListeners(event.GetType())
+	bus.subscribersMutex.RUnlock()
+	if len(listeners) > 0 && bus.muting.suppresses(event.GetType()) {
+		bus.muting.suppressed.Add(uint64(len(listeners)))
+		listeners = nil
+	}
 
-	if listeners, ok := bus.listeners[event.GetType()]; ok {
-		for _, listener := range listeners {
-			listener(event)
+	job := asyncJob{
+		event:     event,
+		listeners: listeners,
+		ctx:       bus.listenerContext(metadata),
+		waiter:    waiter,
+		serial:    bus.serial[event.GetType()],
+		priority:  priority,
+		drops:     &bus.drops,
+	}
+	if origin != nil {
+		job.ctx = context.WithValue(job.ctx, causeKey{}, origin)
+	}
+	job.ctx = context.WithValue(job.ctx, envelopeKey{}, envelope)
+	route := bus.dispatch.route(event.GetType())
+	job.listeners = orderDelivery(route.config.Order, job.listeners)
+
+	if route.pool == nil {
+		defer bus.mutex.Unlock()
+		if origin != nil && bus.maxDepth > 0 {
+			bus.holder.Store(&lockHolder{goroutine: goroutineID(), cause: origin, max: bus.maxDepth})
+			defer bus.holder.Store(nil)
 		}
+		return bus.deliverSync(ctx, job)
+	}
+
+	job.watermark = route.watermark
+	bus.sending.Add(1)
+	bus.mutex.Unlock()
+	defer bus.sending.Done()
+
+	if bus.scheduler != nil {
+		defer bus.scheduler.Run()
+	}
+	return submit(ctx, route.pool, job)
+}
+
+// checkPublish returns the error publish rejects event with before
+// recording it, if any. The caller must hold bus.mutex.
+func (bus *eventBusImpl) checkPublish(ctx context.Context, event Event) error {
+	if bus.closed {
+		return ErrBusClosed
+	}
+	if !bus.copyOnPublish {
+		if err := bus.immutability.check(event); err != nil {
+			return err
+		}
+	}
+	if err := bus.knownTopics.check(event.GetType(), "publish"); err != nil {
+		return err
+	}
+	return bus.checkDepth(causeOf(ctx), event)
+}
+
+// deliverSync calls the listeners of job on the current goroutine, running
+// the listeners of a stage in parallel. Without a waiter, listener panics
+// propagate to the publisher as they always have. It stops early if ctx
+// is done, and defers the remaining listeners once the latency budget of
+// ctx is spent.
+func (bus *eventBusImpl) deliverSync(ctx context.Context, job asyncJob) error {
+	budget := latencyBudget(ctx)
+	begin := time.Now()
+	for start := 0; start < len(job.listeners); {
+		if budget > 0 && start > 0 {
+			if elapsed := time.Since(begin); elapsed > budget {
+				bus.deferListeners(job, start, budget, elapsed)
+				return nil
+			}
+		}
+		end := stageEnd(job.listeners, start)
+		if job.waiter == nil {
+			runParallel(start, end, func(i int) {
+				job.listeners[i].call(job.ctx, job.event)
+			})
+			start = end
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
+		// Slots of one call to add are consecutive.
+		first := job.waiter.add(start, job.listeners[start])
+		for i := start + 1; i < end; i++ {
+			job.waiter.add(i, job.listeners[i])
+		}
+		runParallel(start, end, func(i int) {
+			begin := time.Now()
+			err := invoke(job.ctx, job.listeners[i], job.event)
+			job.waiter.report(first+i-start, time.Since(begin), err)
+		})
+		start = end
+	}
+	return nil
+}
+
+// record persists envelope, setting its store sequence, and updates the
+// last-value cache. The caller must hold bus.mutex.
+func (bus *eventBusImpl) record(envelope *Envelope) error {
+	if err := bus.persist(envelope); err != nil {
+		return err
+	}
+	if cache, ok := bus.latest[envelope.Event.GetType()]; ok {
+		cache.store(envelope)
+	}
+	return nil
+}
+
+// Close stops accepting events and shuts down the worker pool.
+// With WithLeakAudit, it waits at most the grace period and reports
+// the resources still alive.
+func (bus *eventBusImpl) Close() {
+	bus.mutex.Lock()
+	if bus.closed {
+		bus.mutex.Unlock()
+		return
+	}
+	bus.closed = true
+	bus.mutex.Unlock()
+
+	if bus.audit == nil {
+		bus.shutdown()
+		return
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		bus.shutdown()
+	}()
+	bus.audit.check(stopped)
+}
+
+// shutdown stops the periodic publishers, waits for pending publishes, and
+// drains and stops the worker pools.
+func (bus *eventBusImpl) shutdown() {
+	for _, p := range bus.periodic {
+		p.stop()
+	}
+	bus.panicReports.close()
+	bus.sending.Wait()
+	bus.dispatch.stop()
+	bus.owners.stop()
+	if bus.health != nil {
+		bus.health.stop()
+	}
+	if stream := bus.errors.Load(); stream != nil {
+		stream.close()
 	}
 }
@@ -0,0 +1,77 @@
+package eventbustest
+
+import (
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// OrderOption configures AssertHappensBefore.
+type OrderOption func(*orderConfig)
+
+type orderConfig struct {
+	key func(eventbus.Envelope) string
+}
+
+// Correlated checks the order separately for every key, so an event is
+// only matched by events with the same key, such as the same player.
+//
+// Example:
+//
+//	eventbustest.AssertHappensBefore(t, bus.Recorder, "player:died", "player:respawned",
+//	    eventbustest.Correlated(func(envelope eventbus.Envelope) string {
+//	        return envelope.Metadata["player"]
+//	    }))
+func Correlated(key func(eventbus.Envelope) string) OrderOption {
+	return func(config *orderConfig) {
+		config.key = key
+	}
+}
+
+// ByCorrelationID checks the order separately for every correlation ID.
+func ByCorrelationID() OrderOption {
+	return Correlated(func(envelope eventbus.Envelope) string {
+		return envelope.CorrelationID
+	})
+}
+
+// AssertHappensBefore checks that every recorded after event is preceded
+// by its own before event: the n-th after event of a key must follow at
+// least n before events of that key. Every violation is reported as a
+// test error. It returns whether the order holds.
+//
+// Example:
+//
+//	bus := eventbustest.Sandbox(t)
+//	game.Register(bus)
+//	// ...
+//	eventbustest.AssertHappensBefore(t, bus.Recorder, "player:died", "player:respawned")
+func AssertHappensBefore(t testing.TB, rec *Recorder, before, after eventbus.EventType, opts ...OrderOption) bool {
+	t.Helper()
+	config := orderConfig{key: func(eventbus.Envelope) string { return "" }}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	ok := true
+	unmatched := make(map[string]int)
+	for _, envelope := range rec.Envelopes() {
+		key := config.key(envelope)
+		switch envelope.Event.GetType() {
+		case before:
+			unmatched[key]++
+		case after:
+			if unmatched[key] > 0 {
+				unmatched[key]--
+				continue
+			}
+			ok = false
+			if key == "" {
+				t.Errorf("eventbustest: %s (sequence %d) without a preceding %s", after, envelope.Sequence, before)
+			} else {
+				t.Errorf("eventbustest: %s (sequence %d, key %q) without a preceding %s", after, envelope.Sequence, key, before)
+			}
+		}
+	}
+	return ok
+}
@@ -0,0 +1,84 @@
+package eventbustest
+
+import (
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+)
+
+type diedEvent struct {
+	playerID string
+}
+
+func (e diedEvent) GetType() eventbus.EventType {
+	return "player:died"
+}
+
+type respawnedEvent struct {
+	playerID string
+}
+
+func (e respawnedEvent) GetType() eventbus.EventType {
+	return "player:respawned"
+}
+
+// byPlayer correlates the events of one player.
+var byPlayer = Correlated(func(envelope eventbus.Envelope) string {
+	switch event := envelope.Event.(type) {
+	case diedEvent:
+		return event.playerID
+	case respawnedEvent:
+		return event.playerID
+	}
+	return ""
+})
+
+// TestAssertHappensBefore verifies that ordered events pass
+func TestAssertHappensBefore(t *testing.T) {
+	bus := Sandbox(t)
+	bus.Publish(diedEvent{playerID: "p1"})
+	bus.Publish(respawnedEvent{playerID: "p1"})
+	bus.Publish(diedEvent{playerID: "p1"})
+	bus.Publish(respawnedEvent{playerID: "p1"})
+
+	if !AssertHappensBefore(t, bus.Recorder, "player:died", "player:respawned") {
+		t.Error("Expected the order to hold")
+	}
+}
+
+// TestAssertHappensBeforeViolation verifies that every unmatched event is
+// reported
+func TestAssertHappensBeforeViolation(t *testing.T) {
+	bus := Sandbox(t)
+	bus.Publish(respawnedEvent{playerID: "p1"})
+	bus.Publish(diedEvent{playerID: "p1"})
+	bus.Publish(respawnedEvent{playerID: "p1"})
+	bus.Publish(respawnedEvent{playerID: "p1"})
+
+	fake := &fakeT{}
+	if AssertHappensBefore(fake, bus.Recorder, "player:died", "player:respawned") {
+		t.Error("Expected the order to fail")
+	}
+	if len(fake.errors) != 2 {
+		t.Errorf("Expected 2 errors, got %v", fake.errors)
+	}
+}
+
+// TestAssertHappensBeforeCorrelated verifies that events only match events
+// with the same key
+func TestAssertHappensBeforeCorrelated(t *testing.T) {
+	bus := Sandbox(t)
+	bus.Publish(diedEvent{playerID: "p1"})
+	bus.Publish(respawnedEvent{playerID: "p2"})
+
+	if !AssertHappensBefore(&fakeT{}, bus.Recorder, "player:died", "player:respawned") {
+		t.Error("Expected the uncorrelated order to hold")
+	}
+	fake := &fakeT{}
+	if AssertHappensBefore(fake, bus.Recorder, "player:died", "player:respawned", byPlayer) {
+		t.Error("Expected the correlated order to fail")
+	}
+	if len(fake.errors) != 1 {
+		t.Errorf("Expected 1 error, got %v", fake.errors)
+	}
+}
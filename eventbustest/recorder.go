@@ -0,0 +1,49 @@
+package eventbustest
+
+import (
+	"slices"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// Recorder is an event store keeping every published event for
+// assertions. It can be given to any bus with eventbus.WithStore.
+type Recorder struct {
+	*eventbus.MemoryStore
+}
+
+// NewRecorder creates an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{MemoryStore: eventbus.NewMemoryStore()}
+}
+
+// Envelopes returns the recorded envelopes in publish order.
+func (r *Recorder) Envelopes() []eventbus.Envelope {
+	var envelopes []eventbus.Envelope
+	r.Read(1, func(envelope eventbus.Envelope) error {
+		envelopes = append(envelopes, envelope)
+		return nil
+	})
+	return envelopes
+}
+
+// Events returns the recorded events of the given types in publish
+// order, or every recorded event if no type is given.
+func (r *Recorder) Events(types ...eventbus.EventType) []eventbus.Event {
+	var events []eventbus.Event
+	for _, envelope := range r.Envelopes() {
+		if len(types) == 0 || slices.Contains(types, envelope.Event.GetType()) {
+			events = append(events, envelope.Event)
+		}
+	}
+	return events
+}
+
+// Types returns the types of the recorded events in publish order.
+func (r *Recorder) Types() []eventbus.EventType {
+	var types []eventbus.EventType
+	for _, envelope := range r.Envelopes() {
+		types = append(types, envelope.Event.GetType())
+	}
+	return types
+}
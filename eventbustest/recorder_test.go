@@ -0,0 +1,29 @@
+package eventbustest
+
+import (
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// TestRecorderEvents verifies that events are filtered by type in publish order
+func TestRecorderEvents(t *testing.T) {
+	recorder := NewRecorder()
+	bus := eventbus.New(eventbus.WithStore(recorder))
+	defer bus.Close()
+
+	bus.Publish(scoredEvent{points: 10})
+	bus.Publish(rankedEvent{rank: 1})
+	bus.Publish(scoredEvent{points: 20})
+
+	scored := recorder.Events("player:scored")
+	if len(scored) != 2 || scored[0].(scoredEvent).points != 10 || scored[1].(scoredEvent).points != 20 {
+		t.Errorf("Expected the scored events in order, got %v", scored)
+	}
+	if all := recorder.Events(); len(all) != 3 {
+		t.Errorf("Expected 3 events, got %d", len(all))
+	}
+	if envelopes := recorder.Envelopes(); len(envelopes) != 3 || envelopes[2].Sequence != 3 {
+		t.Errorf("Expected 3 envelopes ending at sequence 3, got %v", envelopes)
+	}
+}
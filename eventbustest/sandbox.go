@@ -0,0 +1,91 @@
+// Package eventbustest provides helpers for testing code that uses an
+// event bus: a sandbox bus that records every event, delivers only when
+// the test pumps it, and fails the test if it leaks goroutines.
+//
+// Example:
+//
+//	func TestScoring(t *testing.T) {
+//	    bus := eventbustest.Sandbox(t)
+//	    scoring.Register(bus)
+//
+//	    bus.Publish(PlayerScored{PlayerID: "p1", Points: 10})
+//	    bus.Pump()
+//
+//	    if got := len(bus.Recorder.Events("score:changed")); got != 1 {
+//	        t.Errorf("Expected 1 score change, got %d", got)
+//	    }
+//	}
+package eventbustest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// LeakGrace is how long closing a sandbox waits for the bus to shut down
+// before reporting the goroutines, timers, and channels still alive.
+const LeakGrace = time.Second
+
+// Bus is a sandbox bus created by Sandbox.
+type Bus struct {
+	eventbus.EventBus
+	// Recorder holds every event published on the bus, including the
+	// eventbus:* events of the bus itself.
+	Recorder *Recorder
+
+	frame *eventbus.FrameDispatcher
+}
+
+// Sandbox creates a bus for the test t that records every event, queues
+// deliveries until Pump, and is closed when the test ends, reporting
+// leaked resources as test errors.
+//
+// opts are applied after the sandbox defaults. Topic configurations among
+// them take precedence over manual pumping for the topics they match, so
+// a test can keep some topics synchronous or asynchronous. Since
+// deliveries wait for Pump, PublishAndWait on a pumped topic blocks
+// forever.
+func Sandbox(t testing.TB, opts ...eventbus.Option) *Bus {
+	t.Helper()
+	recorder := NewRecorder()
+	frame := eventbus.NewFrameDispatcher()
+
+	options := []eventbus.Option{
+		eventbus.WithStore(recorder),
+		eventbus.WithLeakAudit(LeakGrace, func(leaks []eventbus.AuditResource) {
+			for _, leak := range leaks {
+				t.Errorf("eventbustest: leaked %s", leak)
+			}
+		}),
+	}
+	options = append(options, opts...)
+	options = append(options, eventbus.WithTopicConfig("*", eventbus.TopicConfig{Dispatcher: frame}))
+
+	bus := &Bus{
+		EventBus: eventbus.New(options...),
+		Recorder: recorder,
+		frame:    frame,
+	}
+	t.Cleanup(bus.Close)
+	return bus
+}
+
+// Pump runs the queued deliveries, including those of events published by
+// the listeners it runs, until none are left, and returns how many ran.
+func (b *Bus) Pump() int {
+	total := 0
+	for {
+		ran := b.frame.Flush()
+		if ran == 0 {
+			return total
+		}
+		total += ran
+	}
+}
+
+// Pending returns the number of queued deliveries.
+func (b *Bus) Pending() int {
+	return b.frame.Len()
+}
@@ -0,0 +1,119 @@
+package eventbustest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+)
+
+type scoredEvent struct {
+	points int
+}
+
+func (e scoredEvent) GetType() eventbus.EventType {
+	return "player:scored"
+}
+
+type rankedEvent struct {
+	rank int
+}
+
+func (e rankedEvent) GetType() eventbus.EventType {
+	return "player:ranked"
+}
+
+// fakeT collects the errors and cleanups of a sandbox.
+type fakeT struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, format)
+}
+
+func (t *fakeT) Cleanup(fn func()) {
+	t.cleanups = append(t.cleanups, fn)
+}
+
+func (t *fakeT) cleanup() {
+	for _, fn := range t.cleanups {
+		fn()
+	}
+}
+
+// TestSandboxPump verifies that deliveries wait for Pump, including
+// events published by listeners
+func TestSandboxPump(t *testing.T) {
+	bus := Sandbox(t)
+	var ranks []int
+
+	bus.Subscribe("player:scored", func(event eventbus.Event) {
+		bus.Publish(rankedEvent{rank: event.(scoredEvent).points / 10})
+	})
+	bus.Subscribe("player:ranked", func(event eventbus.Event) {
+		ranks = append(ranks, event.(rankedEvent).rank)
+	})
+
+	bus.Publish(scoredEvent{points: 20})
+	if bus.Pending() != 1 || len(ranks) != 0 {
+		t.Fatalf("Expected 1 pending delivery, got %d pending and ranks %v", bus.Pending(), ranks)
+	}
+	if ran := bus.Pump(); ran != 2 {
+		t.Errorf("Expected 2 deliveries, got %d", ran)
+	}
+	if len(ranks) != 1 || ranks[0] != 2 {
+		t.Errorf("Expected ranks [2], got %v", ranks)
+	}
+}
+
+// TestSandboxRecords verifies that published events are recorded
+func TestSandboxRecords(t *testing.T) {
+	bus := Sandbox(t)
+
+	bus.Publish(scoredEvent{points: 10})
+	bus.Publish(rankedEvent{rank: 1})
+
+	types := bus.Recorder.Types()
+	if len(types) != 2 || types[0] != "player:scored" || types[1] != "player:ranked" {
+		t.Errorf("Expected [player:scored player:ranked], got %v", types)
+	}
+}
+
+// TestSandboxTopicConfig verifies that topic configurations in the options
+// take precedence over pumping
+func TestSandboxTopicConfig(t *testing.T) {
+	bus := Sandbox(t, eventbus.WithTopicConfig("player:scored", eventbus.TopicConfig{}))
+	count := 0
+
+	bus.Subscribe("player:scored", func(event eventbus.Event) { count++ })
+	bus.Publish(scoredEvent{points: 10})
+
+	if count != 1 {
+		t.Errorf("Expected a synchronous delivery, got %d", count)
+	}
+}
+
+// TestSandboxCleanup verifies that the bus is closed when the test ends
+// and leaked goroutines are reported
+func TestSandboxCleanup(t *testing.T) {
+	fake := &fakeT{}
+	bus := Sandbox(fake, eventbus.WithTopicConfig("player:scored", eventbus.TopicConfig{Async: true, Workers: 1, QueueSize: 1}))
+	block := make(chan struct{})
+	defer close(block)
+
+	bus.Subscribe("player:scored", func(event eventbus.Event) { <-block })
+	bus.Publish(scoredEvent{points: 10})
+	if len(fake.cleanups) != 1 {
+		t.Fatalf("Expected 1 cleanup, got %d", len(fake.cleanups))
+	}
+	fake.cleanup()
+
+	if len(fake.errors) == 0 || !strings.HasPrefix(fake.errors[0], "eventbustest: leaked") {
+		t.Errorf("Expected a leak error, got %v", fake.errors)
+	}
+}
@@ -0,0 +1,241 @@
+package eventbus
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// FlowGraph describes which components publish each topic of a bus and
+// which subscriptions handle it, for architecture documentation. Build
+// one with Graph.
+type FlowGraph struct {
+	// Topics holds the declared and subscribed topics, sorted by event
+	// type.
+	Topics []TopicFlow
+}
+
+// TopicFlow is a topic of a FlowGraph.
+type TopicFlow struct {
+	EventType EventType
+	// Description and Owner are documented with Document.
+	Description string
+	Owner       string
+	// Publishers are the components declared with DeclarePublisher.
+	Publishers []string
+	// Subscriptions describes the subscriptions receiving the topic, in
+	// delivery order, including those subscribed to matching patterns
+	// with a WildcardRouter.
+	Subscriptions []SubscriptionInfo
+}
+
+// FlowEdge connects a publishing component to a handler through a topic.
+type FlowEdge struct {
+	Publisher string
+	EventType EventType
+	// Handler is the label given with WithName, or "" if there is none.
+	Handler string
+}
+
+// Graph returns the producer/consumer graph of the bus: every topic
+// declared with DeclarePublisher, with the subscriptions it reaches, and
+// every subscribed topic no declared topic reaches.
+//
+// Example:
+//
+//	for _, edge := range bus.Graph().Edges() {
+//	    fmt.Printf("%s --%s--> %s\n", edge.Publisher, edge.EventType, edge.Handler)
+//	}
+func (bus *eventBusImpl) Graph() FlowGraph {
+	registry := bus.types
+	if registry == nil {
+		registry = DefaultTypes
+	}
+
+	bus.subscribersMutex.RLock()
+	topics := make(map[EventType]*TopicFlow)
+	reached := make(map[*Subscription]bool)
+	for topic, publishers := range bus.publishers {
+		flow := &TopicFlow{EventType: topic, Publishers: slices.Clone(publishers)}
+		for _, sub := range bus.matchListeners(topic) {
+			flow.Subscriptions = append(flow.Subscriptions, sub.info(sub.Health()))
+			reached[sub] = true
+		}
+		topics[topic] = flow
+	}
+	for _, sub := range bus.subscriptions {
+		if reached[sub] {
+			continue
+		}
+		flow, ok := topics[sub.eventType]
+		if !ok {
+			flow = &TopicFlow{EventType: sub.eventType}
+			topics[sub.eventType] = flow
+		}
+		flow.Subscriptions = append(flow.Subscriptions, sub.info(sub.Health()))
+	}
+	bus.subscribersMutex.RUnlock()
+
+	graph := FlowGraph{Topics: make([]TopicFlow, 0, len(topics))}
+	for _, topic := range slices.Sorted(maps.Keys(topics)) {
+		flow := topics[topic]
+		if info, ok := registry.Documentation(topic); ok {
+			flow.Description, flow.Owner = info.Description, info.Owner
+		}
+		graph.Topics = append(graph.Topics, *flow)
+	}
+	return graph
+}
+
+// Edges returns an edge from every publisher of a topic to every
+// subscription receiving it, in topic order.
+func (g FlowGraph) Edges() []FlowEdge {
+	var edges []FlowEdge
+	for _, topic := range g.Topics {
+		for _, publisher := range topic.Publishers {
+			for _, sub := range topic.Subscriptions {
+				edges = append(edges, FlowEdge{Publisher: publisher, EventType: topic.EventType, Handler: sub.Handler})
+			}
+		}
+	}
+	return edges
+}
+
+// Topic returns the flow of eventType, and false if the graph does not
+// contain it.
+func (g FlowGraph) Topic(eventType EventType) (TopicFlow, bool) {
+	i, found := slices.BinarySearchFunc(g.Topics, eventType, func(flow TopicFlow, eventType EventType) int {
+		return cmp.Compare(flow.EventType, eventType)
+	})
+	if !found {
+		return TopicFlow{}, false
+	}
+	return g.Topics[i], true
+}
+
+// flowDocument is the JSON representation of a FlowGraph.
+type flowDocument struct {
+	Topics []flowTopic `json:"topics"`
+	Edges  []flowEdge  `json:"edges"`
+}
+
+// flowTopic is the JSON representation of a TopicFlow.
+type flowTopic struct {
+	Type          EventType          `json:"type"`
+	Description   string             `json:"description,omitempty"`
+	Owner         string             `json:"owner,omitempty"`
+	Publishers    []string           `json:"publishers"`
+	Subscriptions []flowSubscription `json:"subscriptions"`
+}
+
+// flowSubscription is the JSON representation of a SubscriptionInfo.
+type flowSubscription struct {
+	Pattern EventType `json:"pattern"`
+	Handler string    `json:"handler,omitempty"`
+	Options []string  `json:"options,omitempty"`
+	Health  string    `json:"health"`
+}
+
+// flowEdge is the JSON representation of a FlowEdge.
+type flowEdge struct {
+	Publisher string    `json:"publisher"`
+	Type      EventType `json:"type"`
+	Handler   string    `json:"handler,omitempty"`
+}
+
+// WriteJSON writes the graph as a JSON document with a "topics" array,
+// listing the publishers and subscriptions of each topic, and an "edges"
+// array connecting publishers to handlers, for developer portals that
+// render the event architecture.
+//
+// Example:
+//
+//	http.HandleFunc("/events/graph.json", func(w http.ResponseWriter, r *http.Request) {
+//	    w.Header().Set("Content-Type", "application/json")
+//	    bus.Graph().WriteJSON(w)
+//	})
+func (g FlowGraph) WriteJSON(w io.Writer) error {
+	document := flowDocument{Topics: make([]flowTopic, 0, len(g.Topics)), Edges: []flowEdge{}}
+	for _, topic := range g.Topics {
+		flow := flowTopic{
+			Type:          topic.EventType,
+			Description:   topic.Description,
+			Owner:         topic.Owner,
+			Publishers:    topic.Publishers,
+			Subscriptions: make([]flowSubscription, 0, len(topic.Subscriptions)),
+		}
+		if flow.Publishers == nil {
+			flow.Publishers = []string{}
+		}
+		for _, sub := range topic.Subscriptions {
+			flow.Subscriptions = append(flow.Subscriptions, flowSubscription{
+				Pattern: sub.EventType,
+				Handler: sub.Handler,
+				Options: sub.Options,
+				Health:  sub.Health.String(),
+			})
+		}
+		document.Topics = append(document.Topics, flow)
+	}
+	for _, edge := range g.Edges() {
+		document.Edges = append(document.Edges, flowEdge{Publisher: edge.Publisher, Type: edge.EventType, Handler: edge.Handler})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language, with publishers
+// and handlers as boxes and topics as ellipses, for rendering with dot.
+// Unnamed handlers are drawn as separate "(unnamed)" boxes.
+//
+// Example:
+//
+//	bus.Graph().WriteDOT(file) // dot -Tsvg events.dot -o events.svg
+func (g FlowGraph) WriteDOT(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "digraph events {")
+	fmt.Fprintln(b, "  rankdir=LR;")
+
+	components := make(map[string]bool)
+	component := func(name string) string {
+		id := strconv.Quote("component:" + name)
+		if !components[name] {
+			components[name] = true
+			fmt.Fprintf(b, "  %s [shape=box, label=%s];\n", id, strconv.Quote(name))
+		}
+		return id
+	}
+	unnamed := 0
+	for _, topic := range g.Topics {
+		id := strconv.Quote("topic:" + string(topic.EventType))
+		label := string(topic.EventType)
+		if topic.Owner != "" {
+			label += "\n(" + topic.Owner + ")"
+		}
+		fmt.Fprintf(b, "  %s [shape=ellipse, label=%s];\n", id, strconv.Quote(label))
+		for _, publisher := range topic.Publishers {
+			fmt.Fprintf(b, "  %s -> %s;\n", component(publisher), id)
+		}
+		for _, sub := range topic.Subscriptions {
+			handler := ""
+			if sub.Handler != "" {
+				handler = component(sub.Handler)
+			} else {
+				unnamed++
+				handler = strconv.Quote(fmt.Sprintf("unnamed:%d", unnamed))
+				fmt.Fprintf(b, "  %s [shape=box, style=dashed, label=\"(unnamed)\"];\n", handler)
+			}
+			fmt.Fprintf(b, "  %s -> %s;\n", id, handler)
+		}
+	}
+
+	fmt.Fprintln(b, "}")
+	return b.Flush()
+}
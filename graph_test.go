@@ -0,0 +1,137 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestGraph verifies that declared publishers and subscriptions form the flow graph
+func TestGraph(t *testing.T) {
+	types := NewTypeRegistry()
+	types.Document("player:died", TopicInfo{Description: "A player's health reached zero.", Owner: "gameplay"})
+	bus := New(WithTypeRegistry(types))
+	defer bus.Close()
+
+	bus.DeclarePublisher("combat", "player:damaged", "player:died")
+	bus.DeclarePublisher("traps", "player:died")
+	bus.Subscribe("player:died", func(event Event) {}, WithName("scoreboard"))
+	bus.Subscribe("player:died", func(event Event) {}, WithName("respawn"))
+	bus.Subscribe("player:moved", func(event Event) {}, WithName("physics"))
+
+	graph := bus.Graph()
+	var topics []EventType
+	for _, flow := range graph.Topics {
+		topics = append(topics, flow.EventType)
+	}
+	if !slices.Equal(topics, []EventType{"player:damaged", "player:died", "player:moved"}) {
+		t.Fatalf("Expected the declared and subscribed topics in order, got %v", topics)
+	}
+
+	died, ok := graph.Topic("player:died")
+	if !ok || died.Owner != "gameplay" || died.Description == "" {
+		t.Errorf("Expected the documentation of player:died, got %+v", died)
+	}
+	if !slices.Equal(died.Publishers, []string{"combat", "traps"}) || len(died.Subscriptions) != 2 {
+		t.Errorf("Expected 2 publishers and 2 subscriptions, got %+v", died)
+	}
+	if moved, _ := graph.Topic("player:moved"); moved.Publishers != nil || len(moved.Subscriptions) != 1 {
+		t.Errorf("Expected player:moved without publishers, got %+v", moved)
+	}
+	if _, ok := graph.Topic("player:jumped"); ok {
+		t.Error("Expected no flow for an unknown topic")
+	}
+
+	edges := graph.Edges()
+	expected := []FlowEdge{
+		{Publisher: "combat", EventType: "player:died", Handler: "scoreboard"},
+		{Publisher: "combat", EventType: "player:died", Handler: "respawn"},
+		{Publisher: "traps", EventType: "player:died", Handler: "scoreboard"},
+		{Publisher: "traps", EventType: "player:died", Handler: "respawn"},
+	}
+	if !slices.Equal(edges, expected) {
+		t.Errorf("Expected %v, got %v", expected, edges)
+	}
+
+	if publishers := bus.Describe("player:died").Publishers; !slices.Equal(publishers, []string{"combat", "traps"}) {
+		t.Errorf("Expected Describe to name the publishers, got %v", publishers)
+	}
+}
+
+// TestGraphWildcards verifies that pattern subscriptions are listed under the declared topics they match
+func TestGraphWildcards(t *testing.T) {
+	bus := New(WithRouter(NewWildcardRouter()))
+	defer bus.Close()
+
+	bus.DeclarePublisher("combat", "player:died")
+	bus.Subscribe("player:*", func(event Event) {}, WithName("logger"))
+
+	graph := bus.Graph()
+	if len(graph.Topics) != 1 || graph.Topics[0].EventType != "player:died" || len(graph.Topics[0].Subscriptions) != 1 {
+		t.Errorf("Expected the pattern subscription under player:died, got %+v", graph.Topics)
+	}
+}
+
+// TestGraphWriteJSON verifies the JSON document of the flow graph
+func TestGraphWriteJSON(t *testing.T) {
+	graph := FlowGraph{Topics: []TopicFlow{
+		{EventType: "player:died", Owner: "gameplay", Publishers: []string{"combat"}, Subscriptions: []SubscriptionInfo{{EventType: "player:died", Handler: "scoreboard"}}},
+		{EventType: "player:moved", Subscriptions: []SubscriptionInfo{{EventType: "player:moved"}}},
+	}}
+	var b strings.Builder
+	if err := graph.WriteJSON(&b); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var document struct {
+		Topics []struct {
+			Type          string
+			Owner         string
+			Publishers    []string
+			Subscriptions []struct{ Pattern, Handler, Health string }
+		}
+		Edges []struct{ Publisher, Type, Handler string }
+	}
+	if err := json.Unmarshal([]byte(b.String()), &document); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if len(document.Topics) != 2 || document.Topics[0].Owner != "gameplay" || document.Topics[1].Publishers == nil {
+		t.Errorf("Expected 2 topics with publisher arrays, got %+v", document.Topics)
+	}
+	if sub := document.Topics[0].Subscriptions[0]; sub.Handler != "scoreboard" || sub.Health != "ok" {
+		t.Errorf("Expected the scoreboard subscription, got %+v", sub)
+	}
+	if len(document.Edges) != 1 || document.Edges[0].Publisher != "combat" || document.Edges[0].Handler != "scoreboard" {
+		t.Errorf("Expected an edge from combat to scoreboard, got %+v", document.Edges)
+	}
+}
+
+// TestGraphWriteDOT verifies the Graphviz rendering of the flow graph
+func TestGraphWriteDOT(t *testing.T) {
+	graph := FlowGraph{Topics: []TopicFlow{
+		{EventType: "player:died", Owner: "gameplay", Publishers: []string{"combat"}, Subscriptions: []SubscriptionInfo{{Handler: "scoreboard"}, {}}},
+		{EventType: "player:damaged", Publishers: []string{"combat"}},
+	}}
+	var b strings.Builder
+	if err := graph.WriteDOT(&b); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `digraph events {
+  rankdir=LR;
+  "topic:player:died" [shape=ellipse, label="player:died\n(gameplay)"];
+  "component:combat" [shape=box, label="combat"];
+  "component:combat" -> "topic:player:died";
+  "component:scoreboard" [shape=box, label="scoreboard"];
+  "topic:player:died" -> "component:scoreboard";
+  "unnamed:1" [shape=box, style=dashed, label="(unnamed)"];
+  "topic:player:died" -> "unnamed:1";
+  "topic:player:damaged" [shape=ellipse, label="player:damaged"];
+  "component:combat" -> "topic:player:damaged";
+}
+`
+	if b.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, b.String())
+	}
+}
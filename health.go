@@ -0,0 +1,335 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBreakerOpen is reported for deliveries skipped because the circuit
+// breaker of the subscription is open.
+var ErrBreakerOpen = errors.New("eventbus: circuit breaker open")
+
+// HealthState describes how a subscription has been doing recently.
+type HealthState int
+
+const (
+	// HealthOK means recent invocations were fast and succeeded.
+	HealthOK HealthState = iota
+	// HealthSlow means recent invocations took longer than the slow
+	// threshold on average.
+	HealthSlow
+	// HealthErroring means too many recent invocations panicked.
+	HealthErroring
+	// HealthBreakerOpen means the listener failed repeatedly and its
+	// deliveries are skipped until the cooldown ends.
+	HealthBreakerOpen
+)
+
+// String returns the name of the state.
+func (s HealthState) String() string {
+	switch s {
+	case HealthOK:
+		return "ok"
+	case HealthSlow:
+		return "slow"
+	case HealthErroring:
+		return "erroring"
+	case HealthBreakerOpen:
+		return "breaker_open"
+	default:
+		return "unknown"
+	}
+}
+
+// HealthConfig configures WithHealth.
+type HealthConfig struct {
+	// Window is the number of recent invocations the state is derived
+	// from. Defaults to 20.
+	Window int
+	// SlowThreshold is the average invocation time at which a
+	// subscription is slow. Zero disables the slow state.
+	SlowThreshold time.Duration
+	// ErrorRatio is the share of failed invocations in the window at
+	// which a subscription is erroring. Defaults to 0.5.
+	ErrorRatio float64
+	// BreakerFailures is the number of consecutive failures that open the
+	// circuit breaker. Zero disables the breaker.
+	BreakerFailures int
+	// BreakerCooldown is how long an open breaker skips deliveries before
+	// letting a single trial delivery through. Defaults to 10 seconds.
+	BreakerCooldown time.Duration
+}
+
+// HealthChangedType is the event type of the events published when the
+// health of a subscription changes.
+const HealthChangedType EventType = "eventbus:health_changed"
+
+// HealthChanged reports a transition of a subscription's health.
+type HealthChanged struct {
+	// EventType is the subscribed event type.
+	EventType EventType
+	// Handler is the label of the listener, if it has one.
+	Handler string
+	// From and To are the states before and after the transition.
+	From HealthState
+	To   HealthState
+	// Time is when the transition happened.
+	Time time.Time
+}
+
+// GetType returns HealthChangedType.
+func (h HealthChanged) GetType() EventType {
+	return HealthChangedType
+}
+
+// WithHealth tracks the health of every subscription from the outcomes of
+// its recent invocations, where a failure is a panicking listener. The
+// current state is reported by Subscription.Health, counted in Stats, and
+// listed in Snapshot; transitions are published as HealthChanged events
+// from a separate goroutine.
+//
+// With BreakerFailures set, a listener failing that many times in a row is
+// not called until BreakerCooldown has passed; its deliveries are reported
+// with ErrBreakerOpen. A successful trial delivery after the cooldown
+// closes the breaker again.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithHealth(eventbus.HealthConfig{
+//	    SlowThreshold:   10 * time.Millisecond,
+//	    BreakerFailures: 5,
+//	    BreakerCooldown: 30 * time.Second,
+//	}))
+//
+//	bus.Subscribe(eventbus.HealthChangedType, func(event eventbus.Event) {
+//	    change := event.(eventbus.HealthChanged)
+//	    log.Printf("%s for %s: %s -> %s", change.Handler, change.EventType, change.From, change.To)
+//	})
+func WithHealth(config HealthConfig) Option {
+	return func(bus *eventBusImpl) {
+		if config.Window <= 0 {
+			config.Window = 20
+		}
+		if config.ErrorRatio <= 0 {
+			config.ErrorRatio = 0.5
+		}
+		if config.BreakerCooldown <= 0 {
+			config.BreakerCooldown = 10 * time.Second
+		}
+		bus.health = &healthMonitor{
+			config:  config,
+			changes: make(chan HealthChanged, 64),
+		}
+	}
+}
+
+// Health returns the current health of the subscription. It is HealthOK
+// on a bus without WithHealth.
+func (s *Subscription) Health() HealthState {
+	if s.health == nil {
+		return HealthOK
+	}
+	return s.health.current()
+}
+
+// healthMonitor publishes the health transitions of a bus's subscriptions
+// on its own goroutine, since they happen during deliveries that may hold
+// the bus lock.
+type healthMonitor struct {
+	// config is guarded by mutex, since Reconfigure replaces it.
+	config  HealthConfig
+	mutex   sync.Mutex
+	changes chan HealthChanged
+	done    chan struct{}
+	running sync.WaitGroup
+}
+
+// start launches the publishing goroutine, tracking it in audit.
+func (m *healthMonitor) start(bus *eventBusImpl) {
+	m.done = make(chan struct{})
+	m.running.Add(1)
+	release := bus.audit.track("goroutine", "health monitor")
+	go func() {
+		defer m.running.Done()
+		defer release()
+		for {
+			select {
+			case change := <-m.changes:
+				bus.publish(context.Background(), change, nil)
+			case <-m.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop ends the goroutine and waits for it to exit. Pending transitions
+// are discarded.
+func (m *healthMonitor) stop() {
+	close(m.done)
+	m.running.Wait()
+}
+
+// report queues a transition for publishing, discarding it if the queue
+// is full.
+func (m *healthMonitor) report(change HealthChanged) {
+	select {
+	case m.changes <- change:
+	default:
+	}
+}
+
+// settings returns the configuration of new subscriptions.
+func (m *healthMonitor) settings() HealthConfig {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.config
+}
+
+// health tracks the recent outcomes of one subscription.
+type health struct {
+	config HealthConfig
+	// durations and failed are ring buffers of the last outcomes.
+	durations []time.Duration
+	failed    []bool
+	next      int
+	filled    int
+	// consecutive counts the failures since the last success.
+	consecutive int
+	// open marks an open breaker, which lets a trial delivery through
+	// after openUntil.
+	open      bool
+	openUntil time.Time
+	trial     bool
+	state     HealthState
+	mutex     sync.Mutex
+}
+
+// newHealth creates the tracker of one subscription.
+func newHealth(config HealthConfig) *health {
+	return &health{
+		config:    config,
+		durations: make([]time.Duration, config.Window),
+		failed:    make([]bool, config.Window),
+	}
+}
+
+// reconfigure replaces the configuration, clearing the recorded outcomes
+// if the window changes. The state is kept until the next invocation.
+func (h *health) reconfigure(config HealthConfig) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if config.Window != h.config.Window {
+		h.durations = make([]time.Duration, config.Window)
+		h.failed = make([]bool, config.Window)
+		h.next, h.filled = 0, 0
+	}
+	h.config = config
+}
+
+// allow reports whether an invocation may start at now. While the breaker
+// is open, only a single trial is let through once the cooldown has
+// ended.
+func (h *health) allow(now time.Time) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.open {
+		return true
+	}
+	if h.trial || now.Before(h.openUntil) {
+		return false
+	}
+	h.trial = true
+	return true
+}
+
+// record adds the outcome of an invocation finished at now and returns
+// the previous and the new state.
+func (h *health) record(now time.Time, duration time.Duration, failed bool) (from, to HealthState) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.durations[h.next] = duration
+	h.failed[h.next] = failed
+	h.next = (h.next + 1) % len(h.durations)
+	h.filled = min(h.filled+1, len(h.durations))
+
+	h.trial = false
+	if failed {
+		h.consecutive++
+		if h.config.BreakerFailures > 0 && (h.open || h.consecutive >= h.config.BreakerFailures) {
+			h.open = true
+			h.openUntil = now.Add(h.config.BreakerCooldown)
+		}
+	} else {
+		h.consecutive = 0
+		h.open = false
+	}
+
+	from = h.state
+	h.state = h.derive()
+	return from, h.state
+}
+
+// derive computes the state from the recorded outcomes.
+func (h *health) derive() HealthState {
+	if h.open {
+		return HealthBreakerOpen
+	}
+
+	failures := 0
+	var total time.Duration
+	for i := 0; i < h.filled; i++ {
+		if h.failed[i] {
+			failures++
+		}
+		total += h.durations[i]
+	}
+	switch {
+	case failures > 0 && float64(failures) >= h.config.ErrorRatio*float64(h.filled):
+		return HealthErroring
+	case h.config.SlowThreshold > 0 && total/time.Duration(h.filled) >= h.config.SlowThreshold:
+		return HealthSlow
+	default:
+		return HealthOK
+	}
+}
+
+// current returns the state after the last invocation.
+func (h *health) current() HealthState {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.state
+}
+
+// observe runs deliver as an invocation of s, recording its outcome and
+// reporting transitions. A panic counts as a failure and propagates.
+func (s *Subscription) observe(deliver func()) error {
+	if !s.health.allow(time.Now()) {
+		return ErrBreakerOpen
+	}
+
+	begin := time.Now()
+	completed := false
+	defer func() {
+		now := time.Now()
+		from, to := s.health.record(now, now.Sub(begin), !completed)
+		if from != to {
+			s.bus.health.report(HealthChanged{
+				EventType: s.eventType,
+				Handler:   s.name,
+				From:      from,
+				To:        to,
+				Time:      now,
+			})
+		}
+	}()
+
+	deliver()
+	completed = true
+	return nil
+}
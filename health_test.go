@@ -0,0 +1,140 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestHealthErroring verifies that panicking listeners become erroring and recover
+func TestHealthErroring(t *testing.T) {
+	bus := New(WithHealth(HealthConfig{Window: 4}))
+	defer bus.Close()
+
+	fail := true
+	sub := bus.Subscribe("test:event", func(event Event) {
+		if fail {
+			panic("boom")
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		bus.PublishAndWait(context.Background(), testEvent{eventType: "test:event"})
+	}
+	if sub.Health() != HealthErroring {
+		t.Errorf("Expected erroring, got %s", sub.Health())
+	}
+	if stats := bus.Stats(); stats.Erroring != 1 {
+		t.Errorf("Expected 1 erroring subscription, got %+v", stats)
+	}
+
+	fail = false
+	for i := 0; i < 3; i++ {
+		bus.PublishAndWait(context.Background(), testEvent{eventType: "test:event"})
+	}
+	if sub.Health() != HealthOK {
+		t.Errorf("Expected ok after successes, got %s", sub.Health())
+	}
+}
+
+// TestHealthSlow verifies that listeners over the slow threshold are reported in snapshots
+func TestHealthSlow(t *testing.T) {
+	bus := New(WithHealth(HealthConfig{Window: 2, SlowThreshold: 2 * time.Millisecond}))
+	defer bus.Close()
+
+	bus.Subscribe("test:event", func(event Event) { time.Sleep(5 * time.Millisecond) }, WithName("slow"))
+	bus.Subscribe("test:event", func(event Event) {}, WithName("fast"))
+	bus.Publish(testEvent{eventType: "test:event"})
+
+	infos := bus.Snapshot().Subscriptions()
+	if infos[0].Health != HealthSlow || infos[1].Health != HealthOK {
+		t.Errorf("Expected slow and ok, got %s and %s", infos[0].Health, infos[1].Health)
+	}
+	if stats := bus.Stats(); stats.Slow != 1 {
+		t.Errorf("Expected 1 slow subscription, got %+v", stats)
+	}
+}
+
+// TestHealthBreaker verifies that the breaker skips deliveries and closes after a successful trial
+func TestHealthBreaker(t *testing.T) {
+	bus := New(WithHealth(HealthConfig{BreakerFailures: 2, BreakerCooldown: 20 * time.Millisecond}))
+	defer bus.Close()
+
+	fail := true
+	calls := 0
+	sub := bus.Subscribe("test:event", func(event Event) {
+		calls++
+		if fail {
+			panic("boom")
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		bus.PublishAndWait(context.Background(), testEvent{eventType: "test:event"})
+	}
+	if sub.Health() != HealthBreakerOpen {
+		t.Fatalf("Expected breaker_open, got %s", sub.Health())
+	}
+
+	err := bus.PublishAndWait(context.Background(), testEvent{eventType: "test:event"})
+	if !errors.Is(err, ErrBreakerOpen) || calls != 2 {
+		t.Errorf("Expected the delivery to be skipped with ErrBreakerOpen, got %v after %d calls", err, calls)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	fail = false
+	if err := bus.PublishAndWait(context.Background(), testEvent{eventType: "test:event"}); err != nil {
+		t.Errorf("Expected the trial to succeed, got %v", err)
+	}
+	if sub.Health() == HealthBreakerOpen || calls != 3 {
+		t.Errorf("Expected the breaker to close after the trial, got %s after %d calls", sub.Health(), calls)
+	}
+}
+
+// TestHealthChanged verifies that transitions are published as events
+func TestHealthChanged(t *testing.T) {
+	bus := New(WithHealth(HealthConfig{Window: 1}))
+	defer bus.Close()
+
+	changes := make(chan HealthChanged, 4)
+	bus.Subscribe(HealthChangedType, func(event Event) { changes <- event.(HealthChanged) })
+	fail := true
+	bus.Subscribe("test:event", func(event Event) {
+		if fail {
+			panic("boom")
+		}
+	}, WithName("flaky"))
+
+	bus.PublishAndWait(context.Background(), testEvent{eventType: "test:event"})
+	fail = false
+	bus.PublishAndWait(context.Background(), testEvent{eventType: "test:event"})
+
+	want := []HealthState{HealthErroring, HealthOK}
+	for i, to := range want {
+		select {
+		case change := <-changes:
+			if change.Handler != "flaky" || change.EventType != "test:event" || change.To != to {
+				t.Errorf("Expected transition %d of flaky to %s, got %+v", i, to, change)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected transition %d to %s", i, to)
+		}
+	}
+}
+
+// TestHealthStateString verifies the names of the health states
+func TestHealthStateString(t *testing.T) {
+	tests := map[HealthState]string{
+		HealthOK:          "ok",
+		HealthSlow:        "slow",
+		HealthErroring:    "erroring",
+		HealthBreakerOpen: "breaker_open",
+		HealthState(99):   "unknown",
+	}
+	for state, want := range tests {
+		if got := state.String(); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
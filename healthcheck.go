@@ -0,0 +1,130 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrUnhealthy is returned by Healthy when the bus or one of its
+// dependencies cannot currently do its work. The problems found are
+// joined to it, so errors.Is also matches them.
+var ErrUnhealthy = errors.New("eventbus: bus is unhealthy")
+
+// HealthChecker is implemented by stores and bridge transports that can
+// report whether their backend is reachable. Healthy returns nil when it
+// is, and otherwise an error describing the problem.
+type HealthChecker interface {
+	Healthy() error
+}
+
+// healthChecks holds the checks added with AddHealthCheck.
+type healthChecks struct {
+	mutex  sync.Mutex
+	checks []*healthCheck
+}
+
+// healthCheck is a named check added with AddHealthCheck.
+type healthCheck struct {
+	name  string
+	check func() error
+}
+
+// AddHealthCheck adds check to those run by Healthy, for components such
+// as bridges that the bus depends on without owning them. name prefixes
+// the error check returns. The returned function removes the check; it
+// is safe to call more than once.
+//
+// Bridges created with the bridge package add their own check.
+//
+// Example:
+//
+//	remove := bus.AddHealthCheck("database", db.Ping)
+//	defer remove()
+func (bus *eventBusImpl) AddHealthCheck(name string, check func() error) func() {
+	added := &healthCheck{name: name, check: check}
+	bus.checks.mutex.Lock()
+	bus.checks.checks = append(bus.checks.checks, added)
+	bus.checks.mutex.Unlock()
+
+	return func() {
+		bus.checks.mutex.Lock()
+		defer bus.checks.mutex.Unlock()
+		bus.checks.checks = slices.DeleteFunc(bus.checks.checks, func(c *healthCheck) bool {
+			return c == added
+		})
+	}
+}
+
+// Healthy returns nil if the bus can accept and deliver events, and
+// otherwise ErrUnhealthy joined with every problem found, so it can back
+// the readiness and liveness probes of an orchestrator. It reports:
+//
+//   - a closed bus, with ErrBusClosed;
+//   - a store whose last append failed, or which implements
+//     HealthChecker and reports a problem;
+//   - every asynchronous queue that is saturated: above its high
+//     watermark with WithQueueWatermarks, and full otherwise, with
+//     ErrQueueFull;
+//   - the checks added with AddHealthCheck, including those of bridges.
+//
+// The checks run on the calling goroutine and should return quickly.
+//
+// Example:
+//
+//	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+//	    if err := bus.Healthy(); err != nil {
+//	        http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//	    }
+//	})
+func (bus *eventBusImpl) Healthy() error {
+	var problems []error
+
+	bus.mutex.Lock()
+	closed := bus.closed
+	bus.mutex.Unlock()
+	if closed {
+		problems = append(problems, ErrBusClosed)
+	}
+
+	if failure := bus.storeFailure.Load(); failure != nil {
+		problems = append(problems, fmt.Errorf("store: %w", *failure))
+	}
+	if checker, ok := bus.store.(HealthChecker); ok {
+		if err := checker.Healthy(); err != nil {
+			problems = append(problems, fmt.Errorf("store: %w", err))
+		}
+	}
+
+	// The routes and their pools do not change after New.
+	for _, route := range bus.dispatch.all() {
+		if route.pool == nil || route.config.Dispatcher != nil {
+			// Custom dispatchers have no queue of the bus.
+			continue
+		}
+		queued := route.pool.queued()
+		switch {
+		case route.watermark != nil:
+			if route.watermark.raised() {
+				problems = append(problems, fmt.Errorf("queue of %q above its high watermark with %d jobs: %w", route.pattern, queued, ErrQueueFull))
+			}
+		case queued >= max(route.config.QueueSize, 1):
+			problems = append(problems, fmt.Errorf("queue of %q holds %d jobs: %w", route.pattern, queued, ErrQueueFull))
+		}
+	}
+
+	bus.checks.mutex.Lock()
+	checks := slices.Clone(bus.checks.checks)
+	bus.checks.mutex.Unlock()
+	for _, c := range checks {
+		if err := c.check(); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", c.name, err))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrUnhealthy, errors.Join(problems...))
+}
@@ -0,0 +1,135 @@
+package eventbus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// flakyStore fails appends while down is set
+type flakyStore struct {
+	MemoryStore
+	down bool
+}
+
+func (s *flakyStore) AppendEnvelope(envelope Envelope) (Envelope, error) {
+	if s.down {
+		return Envelope{}, errors.New("connection refused")
+	}
+	return s.MemoryStore.AppendEnvelope(envelope)
+}
+
+// pingStore reports the result of its ping as its health
+type pingStore struct {
+	MemoryStore
+	ping error
+}
+
+func (s *pingStore) Healthy() error {
+	return s.ping
+}
+
+// TestHealthy verifies that a fresh bus is healthy and a closed one is not
+func TestHealthy(t *testing.T) {
+	bus := New(WithAsync(2, 16))
+	if err := bus.Healthy(); err != nil {
+		t.Errorf("Expected a healthy bus, got %v", err)
+	}
+
+	bus.Close()
+	err := bus.Healthy()
+	if !errors.Is(err, ErrUnhealthy) || !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Expected ErrUnhealthy and ErrBusClosed, got %v", err)
+	}
+}
+
+// TestHealthyStore verifies that failed appends and store health checks are reported until they recover
+func TestHealthyStore(t *testing.T) {
+	store := &flakyStore{}
+	bus := New(WithStore(store))
+	defer bus.Close()
+
+	store.down = true
+	func() {
+		defer func() { recover() }()
+		bus.Publish(Of("store:test", 1))
+	}()
+	err := bus.Healthy()
+	if !errors.Is(err, ErrUnhealthy) || !strings.Contains(err.Error(), "store: connection refused") {
+		t.Errorf("Expected the failed append, got %v", err)
+	}
+
+	store.down = false
+	bus.Publish(Of("store:test", 2))
+	if err := bus.Healthy(); err != nil {
+		t.Errorf("Expected a healthy bus after a successful append, got %v", err)
+	}
+
+	pinged := &pingStore{ping: errors.New("replica lagging")}
+	other := New(WithStore(pinged))
+	defer other.Close()
+	if err := other.Healthy(); err == nil || !strings.Contains(err.Error(), "store: replica lagging") {
+		t.Errorf("Expected the store health check, got %v", err)
+	}
+}
+
+// TestHealthyQueues verifies that full queues and queues above their high watermark are reported
+func TestHealthyQueues(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		opts []Option
+		jobs int
+	}{
+		{"full", []Option{WithAsync(1, 4)}, 4},
+		{"watermark", []Option{WithAsync(1, 10), WithQueueWatermarks(Watermarks{})}, 8},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			bus := New(test.opts...)
+			started := make(chan struct{})
+			release := make(chan struct{})
+			bus.Subscribe("job:queued", func(event Event) {
+				if n, _ := Payload[int](event); n == 0 {
+					close(started)
+					<-release
+				}
+			})
+			bus.Publish(Of("job:queued", 0))
+			<-started
+
+			for i := 1; i < test.jobs; i++ {
+				bus.Publish(Of("job:queued", i))
+			}
+			if err := bus.Healthy(); err != nil {
+				t.Errorf("Expected a healthy bus below saturation, got %v", err)
+			}
+			bus.Publish(Of("job:queued", test.jobs))
+			if err := bus.Healthy(); !errors.Is(err, ErrQueueFull) {
+				t.Errorf("Expected ErrQueueFull, got %v", err)
+			}
+
+			close(release)
+			bus.Close()
+		})
+	}
+}
+
+// TestAddHealthCheck verifies that added checks are reported under their name until removed
+func TestAddHealthCheck(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	remove := bus.AddHealthCheck("database", func() error {
+		return errors.New("ping timed out")
+	})
+	bus.AddHealthCheck("cache", func() error { return nil })
+	err := bus.Healthy()
+	if err == nil || !strings.Contains(err.Error(), "database: ping timed out") || strings.Contains(err.Error(), "cache") {
+		t.Errorf("Expected only the database check to fail, got %v", err)
+	}
+
+	remove()
+	remove()
+	if err := bus.Healthy(); err != nil {
+		t.Errorf("Expected a healthy bus after removing the check, got %v", err)
+	}
+}
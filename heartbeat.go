@@ -0,0 +1,89 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// HeartbeatType is the event type of the events published by WithHeartbeat.
+const HeartbeatType EventType = "eventbus:heartbeat"
+
+// Heartbeat is published periodically by a bus configured with WithHeartbeat.
+type Heartbeat struct {
+	// Time is when the heartbeat was published.
+	Time time.Time
+	// Stats are the bus counters at that time.
+	Stats Stats
+}
+
+// GetType returns HeartbeatType.
+func (h Heartbeat) GetType() EventType {
+	return HeartbeatType
+}
+
+// WithHeartbeat publishes a Heartbeat event carrying the current Stats every
+// interval, until the bus is closed. Monitoring subscribers, and anything
+// the bus is bridged to, can use it to verify that the bus is alive end to
+// end; a Watchdog expecting HeartbeatType reports when it is not.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithHeartbeat(10 * time.Second))
+//
+//	bus.Subscribe(eventbus.HeartbeatType, func(event eventbus.Event) {
+//	    stats := event.(eventbus.Heartbeat).Stats
+//	    metrics.Gauge("eventbus.queued", stats.Queued)
+//	})
+func WithHeartbeat(interval time.Duration) Option {
+	return func(bus *eventBusImpl) {
+		bus.periodic = append(bus.periodic, &periodic{
+			name:     "heartbeat",
+			interval: interval,
+			publish: func(now time.Time) {
+				bus.Publish(Heartbeat{Time: now, Stats: bus.Stats()})
+			},
+		})
+	}
+}
+
+// periodic runs a goroutine calling publish every interval until the bus
+// is closed.
+type periodic struct {
+	name     string
+	interval time.Duration
+	publish  func(now time.Time)
+	done     chan struct{}
+	running  sync.WaitGroup
+}
+
+// start launches the goroutine, tracking it and its ticker in the audit
+// of bus. Ticks are run through bus.fireTimer.
+func (p *periodic) start(bus *eventBusImpl) {
+	p.done = make(chan struct{})
+	p.running.Add(1)
+	release := bus.audit.track("goroutine", p.name)
+	releaseTicker := bus.audit.track("timer", p.name+" ticker")
+	go func() {
+		defer p.running.Done()
+		defer release()
+
+		ticker := time.NewTicker(p.interval)
+		defer releaseTicker()
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C:
+				bus.fireTimer(func() { p.publish(now) })
+			case <-p.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop ends the goroutine and waits for it to exit.
+func (p *periodic) stop() {
+	close(p.done)
+	p.running.Wait()
+}
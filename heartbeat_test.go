@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+// TestHeartbeat verifies that WithHeartbeat periodically publishes the bus stats
+func TestHeartbeat(t *testing.T) {
+	bus := New(WithHeartbeat(10 * time.Millisecond))
+
+	heartbeats := make(chan Heartbeat, 100)
+	bus.Subscribe(HeartbeatType, func(event Event) {
+		heartbeats <- event.(Heartbeat)
+	})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case heartbeat := <-heartbeats:
+			if heartbeat.Stats.Subscriptions != 1 {
+				t.Errorf("Expected 1 subscription, got %d", heartbeat.Stats.Subscriptions)
+			}
+			if heartbeat.Time.IsZero() {
+				t.Error("Expected the heartbeat time to be set")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected a heartbeat")
+		}
+	}
+
+	bus.Close()
+	for len(heartbeats) > 0 {
+		<-heartbeats
+	}
+	time.Sleep(30 * time.Millisecond)
+	if len(heartbeats) != 0 {
+		t.Errorf("Expected no heartbeats after Close, got %d", len(heartbeats))
+	}
+}
+
+// TestHeartbeatWatchdog verifies that a Watchdog can expect the heartbeat
+func TestHeartbeatWatchdog(t *testing.T) {
+	bus := New(WithHeartbeat(5 * time.Millisecond))
+
+	alerts := make(chan WatchdogAlert, 10)
+	watchdog := NewWatchdog(bus, func(alert WatchdogAlert) {
+		alerts <- alert
+	})
+	defer watchdog.Stop()
+	watchdog.Expect(HeartbeatType, 50*time.Millisecond)
+
+	select {
+	case alert := <-alerts:
+		t.Fatalf("Expected no alert while the bus is alive, got %+v", alert)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	bus.Close()
+	select {
+	case <-alerts:
+	case <-time.After(time.Second):
+		t.Fatal("Expected an alert after the bus closed")
+	}
+}
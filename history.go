@@ -0,0 +1,258 @@
+package eventbus
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// errNoStore is returned by History operations on a bus without a store.
+var errNoStore = errors.New("eventbus: bus has no event store")
+
+// Restorer is implemented by stores that can load envelopes with their
+// original sequence numbers and timestamps, as needed by History.Import.
+type Restorer interface {
+	// Restore appends envelope to the stream unchanged.
+	Restore(envelope Envelope) error
+}
+
+// SparseRestorer is implemented by stores that can restore envelopes
+// whose sequences have holes, as left in a store by a MemoryBudget or
+// Truncater.
+type SparseRestorer interface {
+	// RestoreSparse appends envelope to the stream unchanged. Its
+	// sequence must be greater than that of the last envelope.
+	RestoreSparse(envelope Envelope) error
+}
+
+// RawEvent is an event whose payload has not been decoded into a Go type.
+// Imported events are represented as RawEvent, since the recorded type
+// string alone does not identify the original Go struct.
+type RawEvent struct {
+	Type    EventType
+	Payload json.RawMessage
+}
+
+// GetType returns the recorded event type.
+func (e RawEvent) GetType() EventType {
+	return e.Type
+}
+
+// Decode unmarshals the payload into v, which should be a pointer
+// to the original event struct.
+func (e RawEvent) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// MarshalJSON returns the payload unchanged so that raw events
+// survive repeated export and import.
+func (e RawEvent) MarshalJSON() ([]byte, error) {
+	if e.Payload == nil {
+		return []byte("null"), nil
+	}
+	return e.Payload, nil
+}
+
+// historyRecord is the JSON Lines representation of an envelope.
+type historyRecord struct {
+	Sequence    uint64    `json:"sequence"`
+	BusSequence uint64    `json:"busSequence,omitempty"`
+	Time        time.Time `json:"time"`
+	// OriginTime is set if it differs from Time.
+	OriginTime    *time.Time        `json:"originTime,omitempty"`
+	Monotonic     time.Duration     `json:"monotonic,omitempty"`
+	CorrelationID string            `json:"correlationId,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	Publisher     string            `json:"publisher,omitempty"`
+	Type          EventType         `json:"type"`
+	// Encoding is EncodingGzip if Payload holds the base64 encoding of the
+	// compressed JSON payload, and empty if it holds the JSON itself.
+	Encoding string          `json:"encoding,omitempty"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// History gives access to the recorded events of a bus for sharing between
+// developers, for example by attaching a recorded session to a bug report.
+type History struct {
+	store EventStore
+	// compressAbove is the payload size above which Export compresses.
+	compressAbove int
+	// readOnly is returned by Import if set, for the history of a bus
+	// view that must not write to the store.
+	readOnly error
+}
+
+// History returns the recorded history of the bus, backed by the store
+// configured with WithStore.
+func (bus *eventBusImpl) History() *History {
+	return &History{store: bus.store}
+}
+
+// Export writes every recorded envelope to w in JSON Lines format, one
+// envelope per line. Payloads are encoded with encoding/json, so only
+// exported event fields are written.
+//
+// Example:
+//
+//	file, _ := os.Create("session.jsonl")
+//	defer file.Close()
+//	bus.History().Export(file)
+func (h *History) Export(w io.Writer) error {
+	if h.store == nil {
+		return errNoStore
+	}
+
+	return h.store.Read(1, func(envelope Envelope) error {
+		return writeEnvelope(w, envelope, h.compressAbove)
+	})
+}
+
+// Compress returns a History whose Export compresses payloads longer than
+// threshold bytes with gzip, recording the encoding in each line. Small
+// payloads are written as plain JSON, so sessions made of many tiny events
+// cost no compression work. Import reads both.
+//
+// Example:
+//
+//	bus.History().Compress(4096).Export(file)
+func (h *History) Compress(threshold int) *History {
+	return &History{store: h.store, compressAbove: threshold, readOnly: h.readOnly}
+}
+
+// Import reads JSON Lines written by Export and restores the envelopes,
+// with their original sequence numbers and timestamps, into the bus store.
+// The store must implement SparseRestorer, like MemoryStore, or Restorer,
+// which cannot import the export of a store with holes, such as one with
+// a memory budget. Events are restored as RawEvent values. Imported
+// events are not published; use ReplayUntil to feed them to a bus.
+// The history of a view returned by ReadOnly or Restricted cannot import.
+func (h *History) Import(r io.Reader) error {
+	if h.readOnly != nil {
+		return h.readOnly
+	}
+	if h.store == nil {
+		return errNoStore
+	}
+	switch restorer := h.store.(type) {
+	case SparseRestorer:
+		return readEnvelopes(r, restorer.RestoreSparse)
+	case Restorer:
+		return readEnvelopes(r, restorer.Restore)
+	}
+	return errors.New("eventbus: store does not support restoring envelopes")
+}
+
+// MarshalEnvelope encodes envelope as one line of the JSON Lines format
+// written by History.Export, without the newline, so envelopes can be
+// shipped one at a time, for example by bridge.Replicator. Payloads
+// longer than compressAbove bytes are compressed if it is positive.
+func MarshalEnvelope(envelope Envelope, compressAbove int) ([]byte, error) {
+	payload, err := json.Marshal(envelope.Event)
+	if err != nil {
+		return nil, fmt.Errorf("eventbus: encoding event %d: %w", envelope.Sequence, err)
+	}
+	compressed, encoding, err := CompressPayload(payload, compressAbove)
+	if err != nil {
+		return nil, fmt.Errorf("eventbus: compressing event %d: %w", envelope.Sequence, err)
+	}
+	if encoding != "" {
+		// The compressed bytes are written as a base64 JSON string.
+		payload, _ = json.Marshal(compressed)
+	}
+
+	var origin *time.Time
+	if !envelope.OriginTime.IsZero() && !envelope.OriginTime.Equal(envelope.Time) {
+		origin = &envelope.OriginTime
+	}
+	line, err := json.Marshal(historyRecord{
+		Sequence:      envelope.Sequence,
+		BusSequence:   envelope.BusSequence,
+		Time:          envelope.Time,
+		OriginTime:    origin,
+		Monotonic:     envelope.Monotonic,
+		CorrelationID: envelope.CorrelationID,
+		Metadata:      envelope.Metadata,
+		Publisher:     envelope.Publisher,
+		Type:          envelope.Event.GetType(),
+		Encoding:      encoding,
+		Payload:       payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("eventbus: encoding event %d: %w", envelope.Sequence, err)
+	}
+	return line, nil
+}
+
+// UnmarshalEnvelope decodes a line written by MarshalEnvelope or
+// History.Export. The event is decoded as a RawEvent.
+func UnmarshalEnvelope(data []byte) (Envelope, error) {
+	var record historyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return Envelope{}, err
+	}
+	if record.Encoding != "" {
+		var compressed []byte
+		if err := json.Unmarshal(record.Payload, &compressed); err != nil {
+			return Envelope{}, err
+		}
+		payload, err := DecompressPayload(compressed, record.Encoding)
+		if err == nil && !json.Valid(payload) {
+			err = errors.New("invalid JSON payload")
+		}
+		if err != nil {
+			return Envelope{}, err
+		}
+		record.Payload = payload
+	}
+
+	origin := record.Time
+	if record.OriginTime != nil {
+		origin = *record.OriginTime
+	}
+	return Envelope{
+		Sequence:      record.Sequence,
+		BusSequence:   record.BusSequence,
+		Time:          record.Time,
+		OriginTime:    origin,
+		Monotonic:     record.Monotonic,
+		CorrelationID: record.CorrelationID,
+		Metadata:      record.Metadata,
+		Publisher:     record.Publisher,
+		Event:         RawEvent{Type: record.Type, Payload: record.Payload},
+	}, nil
+}
+
+// writeEnvelope writes envelope to w as a single JSON line, compressing
+// payloads longer than compressAbove bytes if it is positive.
+func writeEnvelope(w io.Writer, envelope Envelope, compressAbove int) error {
+	line, err := MarshalEnvelope(envelope, compressAbove)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+// readEnvelopes decodes JSON lines written by writeEnvelope and calls fn
+// for each envelope. Events are decoded as RawEvent values.
+func readEnvelopes(r io.Reader, fn func(Envelope) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		envelope, err := UnmarshalEnvelope(scanner.Bytes())
+		if err != nil {
+			return fmt.Errorf("eventbus: decoding history line %d: %w", line, err)
+		}
+		if err := fn(envelope); err != nil {
+			return fmt.Errorf("eventbus: history line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
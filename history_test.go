@@ -0,0 +1,217 @@
+package eventbus
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type scoreEvent struct {
+	Player string `json:"player"`
+	Points int    `json:"points"`
+}
+
+func (e scoreEvent) GetType() EventType {
+	return "score:changed"
+}
+
+// TestHistoryExportImport verifies that envelopes round-trip through JSON Lines
+func TestHistoryExportImport(t *testing.T) {
+	bus := New(WithStore(NewMemoryStore()))
+	bus.Publish(scoreEvent{Player: "alice", Points: 10})
+	bus.Publish(orderEvent{orderID: "o1"})
+
+	var buf bytes.Buffer
+	if err := bus.History().Export(&buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Fatalf("Expected 2 lines, got %d:\n%s", lines, buf.String())
+	}
+
+	store := NewMemoryStore()
+	imported := New(WithStore(store))
+	if err := imported.History().Import(&buf); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	var envelopes []Envelope
+	store.Read(1, func(envelope Envelope) error {
+		envelopes = append(envelopes, envelope)
+		return nil
+	})
+
+	if len(envelopes) != 2 {
+		t.Fatalf("Expected 2 envelopes, got %d", len(envelopes))
+	}
+	if envelopes[1].CorrelationID != "o1" || envelopes[1].Sequence != 2 {
+		t.Errorf("Envelope metadata not preserved: %+v", envelopes[1])
+	}
+
+	raw, ok := envelopes[0].Event.(RawEvent)
+	if !ok || raw.GetType() != "score:changed" {
+		t.Fatalf("Expected a raw score:changed event, got %#v", envelopes[0].Event)
+	}
+	var score scoreEvent
+	if err := raw.Decode(&score); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if score.Player != "alice" || score.Points != 10 {
+		t.Errorf("Unexpected payload: %+v", score)
+	}
+}
+
+// TestHistoryImportSparse verifies that the export of a store with evicted envelopes imports with its holes
+func TestHistoryImportSparse(t *testing.T) {
+	budget := NewMemoryBudget(0)
+	budget.LimitTopic("noise:*", 1)
+	bus := New(WithStore(NewMemoryStoreWithBudget(budget)))
+	bus.Publish(scoreEvent{Player: "alice", Points: 10})
+	bus.Publish(testEvent{eventType: "noise:tick"})
+	bus.Publish(scoreEvent{Player: "bob", Points: 20})
+
+	var buf bytes.Buffer
+	bus.History().Export(&buf)
+	store := NewMemoryStore()
+	if err := New(WithStore(store)).History().Import(&buf); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	var sequences []uint64
+	store.Read(1, func(envelope Envelope) error {
+		sequences = append(sequences, envelope.Sequence)
+		return nil
+	})
+	if len(sequences) != 2 || sequences[0] != 1 || sequences[1] != 3 {
+		t.Errorf("Expected sequences 1 and 3, got %v", sequences)
+	}
+}
+
+// TestHistoryWithoutStore verifies that a bus without a store reports an error
+func TestHistoryWithoutStore(t *testing.T) {
+	bus := New()
+
+	if err := bus.History().Export(&bytes.Buffer{}); err == nil {
+		t.Error("Expected Export to fail without a store")
+	}
+	if err := bus.History().Import(strings.NewReader("")); err == nil {
+		t.Error("Expected Import to fail without a store")
+	}
+}
+
+// TestHistoryImportInvalid verifies that malformed lines are reported
+func TestHistoryImportInvalid(t *testing.T) {
+	bus := New(WithStore(NewMemoryStore()))
+
+	err := bus.History().Import(strings.NewReader("{not json}\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected a line 1 decoding error, got %v", err)
+	}
+}
+
+// FuzzReadEnvelopes verifies that reading arbitrary history lines never
+// panics and that decoded envelopes survive another export
+func FuzzReadEnvelopes(f *testing.F) {
+	f.Add([]byte(`{"sequence":1,"time":"2024-01-01T00:00:00Z","type":"player:jumped","payload":{"height":2}}` + "\n"))
+	f.Add([]byte(`{"sequence":2,"type":"x","payload":null,"metadata":{"trace-id":"t"}}` + "\n\n"))
+	f.Add([]byte(`{"sequence":"not a number"}`))
+	f.Add([]byte("\n\n\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var envelopes []Envelope
+		err := readEnvelopes(bytes.NewReader(data), func(envelope Envelope) error {
+			envelopes = append(envelopes, envelope)
+			return nil
+		})
+		if err != nil {
+			return
+		}
+
+		var buf bytes.Buffer
+		for _, envelope := range envelopes {
+			if err := writeEnvelope(&buf, envelope, 16); err != nil {
+				// Payloads are kept as read, so invalid JSON inside a
+				// valid line cannot occur; anything else is a bug.
+				t.Fatalf("Expected envelope %d to export, got %v", envelope.Sequence, err)
+			}
+		}
+		count := 0
+		if err := readEnvelopes(&buf, func(Envelope) error { count++; return nil }); err != nil {
+			t.Fatalf("Expected exported envelopes to read back, got %v", err)
+		}
+		if count != len(envelopes) {
+			t.Fatalf("Expected %d envelopes, got %d", len(envelopes), count)
+		}
+	})
+}
+
+// TestHistoryCompress verifies that only large payloads are compressed and both read back
+func TestHistoryCompress(t *testing.T) {
+	bus := New(WithStore(NewMemoryStore()))
+	bus.Publish(scoreEvent{Player: "alice", Points: 10})
+	bus.Publish(scoreEvent{Player: strings.Repeat("bob", 100), Points: 20})
+
+	var buf bytes.Buffer
+	if err := bus.History().Compress(64).Export(&buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || strings.Contains(lines[0], `"encoding"`) || !strings.Contains(lines[1], `"encoding":"gzip"`) {
+		t.Fatalf("Expected only the second line to be compressed, got:\n%s", buf.String())
+	}
+	if strings.Contains(lines[1], "bobbob") {
+		t.Error("Expected the large payload not to be written in plain text")
+	}
+
+	store := NewMemoryStore()
+	if err := New(WithStore(store)).History().Import(&buf); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	var scores []scoreEvent
+	store.Read(1, func(envelope Envelope) error {
+		var score scoreEvent
+		envelope.Event.(RawEvent).Decode(&score)
+		scores = append(scores, score)
+		return nil
+	})
+	if len(scores) != 2 || scores[0].Player != "alice" || scores[1].Points != 20 || len(scores[1].Player) != 300 {
+		t.Errorf("Expected both payloads to read back, got %+v", scores)
+	}
+}
+
+// TestMarshalEnvelope verifies that a single envelope round-trips, compressed or not
+func TestMarshalEnvelope(t *testing.T) {
+	envelope := Envelope{
+		Sequence:      7,
+		Time:          time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		CorrelationID: "match-1",
+		Event:         scoreEvent{Player: "bobbobbobbob", Points: 3},
+	}
+	envelope.OriginTime = envelope.Time
+	for _, threshold := range []int{0, 8} {
+		line, err := MarshalEnvelope(envelope, threshold)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if bytes.ContainsRune(line, '\n') {
+			t.Errorf("Expected a single line, got %q", line)
+		}
+		decoded, err := UnmarshalEnvelope(line)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var score scoreEvent
+		raw := decoded.Event.(RawEvent)
+		if err := raw.Decode(&score); err != nil || score.Player != "bobbobbobbob" {
+			t.Errorf("Expected the score decoded, got %+v and %v", score, err)
+		}
+		if decoded.Sequence != 7 || decoded.CorrelationID != "match-1" || !decoded.OriginTime.Equal(envelope.Time) {
+			t.Errorf("Expected the envelope fields kept, got %+v", decoded)
+		}
+	}
+
+	if _, err := UnmarshalEnvelope([]byte("{")); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,63 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks are callbacks the bus calls at the points metrics, tracing, and
+// logging integrations need, so they can plug in without the bus
+// importing an observability library. Every field is optional.
+//
+// The hooks run on the goroutine doing the work, often a publisher
+// holding the bus lock or a worker, and listeners running in parallel
+// call them concurrently. They must be safe for concurrent use, return
+// quickly, and must not publish on the bus.
+type Hooks struct {
+	// OnPublish is called for every event the bus accepts, after it is
+	// recorded and before it is delivered, with the publisher's context.
+	OnPublish func(ctx context.Context, envelope Envelope)
+	// OnDeliver is called after every listener invocation, including
+	// those that panic, with the listener's context and the time the
+	// invocation took.
+	OnDeliver func(ctx context.Context, event Event, handler string, elapsed time.Duration)
+	// OnError is called for every failed delivery reported by Errors.
+	OnError func(failure DeliveryError)
+	// OnSubscribe is called after a listener is subscribed.
+	OnSubscribe func(subscription SubscriptionInfo)
+	// OnUnsubscribe is called after a subscription is cancelled.
+	OnUnsubscribe func(subscription SubscriptionInfo)
+	// OnDrop is called whenever the bus discards an event, like the
+	// callback of WithOnDrop.
+	OnDrop func(reason DropReason, event Event)
+}
+
+// WithHooks installs instrumentation hooks. A later WithHooks replaces
+// the hooks of an earlier one; an integration needing several sets of
+// hooks combines them itself.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithHooks(eventbus.Hooks{
+//	    OnPublish: func(ctx context.Context, envelope eventbus.Envelope) {
+//	        published.WithLabelValues(string(envelope.Event.GetType())).Inc()
+//	    },
+//	    OnDeliver: func(ctx context.Context, event eventbus.Event, handler string, elapsed time.Duration) {
+//	        handlerSeconds.WithLabelValues(string(event.GetType())).Observe(elapsed.Seconds())
+//	    },
+//	}))
+func WithHooks(hooks Hooks) Option {
+	return func(bus *eventBusImpl) {
+		bus.hooks = hooks
+		bus.drops.hook = hooks.OnDrop
+	}
+}
+
+// observeDelivery returns the function reporting an invocation of s for
+// event to OnDeliver.
+func (bus *eventBusImpl) observeDelivery(ctx context.Context, s *Subscription, event Event) func() {
+	start := time.Now()
+	return func() {
+		bus.hooks.OnDeliver(ctx, event, s.name, time.Since(start))
+	}
+}
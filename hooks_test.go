@@ -0,0 +1,86 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWithHooks verifies that the hooks see publishes, deliveries,
+// subscriptions, and cancellations
+func TestWithHooks(t *testing.T) {
+	var calls []string
+	bus := New(WithHooks(Hooks{
+		OnPublish: func(ctx context.Context, envelope Envelope) {
+			calls = append(calls, "publish "+string(envelope.Event.GetType()))
+		},
+		OnDeliver: func(ctx context.Context, event Event, handler string, elapsed time.Duration) {
+			calls = append(calls, "deliver "+handler)
+		},
+		OnSubscribe: func(subscription SubscriptionInfo) {
+			calls = append(calls, "subscribe "+subscription.Handler)
+		},
+		OnUnsubscribe: func(subscription SubscriptionInfo) {
+			calls = append(calls, "unsubscribe "+subscription.Handler)
+		},
+	}))
+
+	sub := bus.Subscribe("player:health", func(event Event) {}, WithName("hud"))
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	sub.Cancel()
+	sub.Cancel()
+
+	want := []string{"subscribe hud", "publish player:health", "deliver hud", "unsubscribe hud"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("Expected %v, got %v", want, calls)
+	}
+}
+
+// TestWithHooksErrors verifies that failed deliveries and drops reach the
+// hooks
+func TestWithHooksErrors(t *testing.T) {
+	var mutex sync.Mutex
+	var failures []DeliveryError
+	var drops []DropReason
+	bus := New(
+		WithTopicConfig("player:health", TopicConfig{Async: true, Workers: 1, QueueSize: 1, Overflow: OverflowDropNewest}),
+		WithHooks(Hooks{
+			OnError: func(failure DeliveryError) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				failures = append(failures, failure)
+			},
+			OnDrop: func(reason DropReason, event Event) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				drops = append(drops, reason)
+			},
+		}),
+	)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	bus.Subscribe("player:health", func(event Event) {
+		if event.(healthEvent).hp == 1 {
+			close(started)
+			<-release
+			panic("boom")
+		}
+	}, WithName("hud"))
+	bus.Publish(healthEvent{playerID: "p1", hp: 1})
+	<-started
+	bus.Publish(healthEvent{playerID: "p1", hp: 2})
+	bus.Publish(healthEvent{playerID: "p1", hp: 3})
+	close(release)
+	bus.Close()
+
+	if len(failures) != 1 || failures[0].Handler != "hud" || !errors.Is(failures[0], ErrHandlerPanic) {
+		t.Errorf("Expected a hud panic, got %v", failures)
+	}
+	if len(drops) != 1 || drops[0] != DropQueueFull {
+		t.Errorf("Expected 1 queue_full drop, got %v", drops)
+	}
+}
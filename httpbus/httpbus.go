@@ -0,0 +1,171 @@
+// Package httpbus publishes the lifecycle of HTTP requests on an event bus,
+// so access logging, metrics, and auditing can be written as ordinary
+// subscribers.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithContextFields(httpbus.RequestIDField()))
+//	bus.Subscribe(httpbus.RequestFinishedType, func(event eventbus.Event) {
+//	    e := event.(httpbus.RequestFinished)
+//	    log.Printf("%s %s %d %v", e.Method, e.Path, e.Status, e.Duration)
+//	})
+//
+//	http.ListenAndServe(":8080", httpbus.Middleware(bus)(mux))
+package httpbus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// Event types published by Middleware.
+const (
+	RequestStartedType  eventbus.EventType = "http:request_started"
+	RequestFinishedType eventbus.EventType = "http:request_finished"
+)
+
+// RequestIDHeader is the header carrying the request ID. Middleware reuses
+// the ID of incoming requests and sets it on responses.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestStarted is published before the wrapped handler runs.
+type RequestStarted struct {
+	Method    string
+	Path      string
+	RequestID string
+	Time      time.Time
+}
+
+// GetType returns RequestStartedType.
+func (e RequestStarted) GetType() eventbus.EventType {
+	return RequestStartedType
+}
+
+// RequestFinished is published after the wrapped handler returns, or
+// panics, in which case Status is 500.
+type RequestFinished struct {
+	Method    string
+	Path      string
+	RequestID string
+	// Status is the response status code.
+	Status int
+	// Bytes is the number of response body bytes written.
+	Bytes    int64
+	Duration time.Duration
+}
+
+// GetType returns RequestFinishedType.
+func (e RequestFinished) GetType() eventbus.EventType {
+	return RequestFinishedType
+}
+
+// requestIDKey is the context key of the request ID.
+type requestIDKey struct{}
+
+// RequestID returns the request ID stored in ctx by Middleware,
+// or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDField returns a context field propagating the request ID to
+// listeners of events published with the request context, for use with
+// eventbus.WithContextFields.
+func RequestIDField() eventbus.ContextField {
+	return eventbus.ContextValue("request-id", requestIDKey{})
+}
+
+// Middleware returns a middleware publishing a RequestStarted and a
+// RequestFinished event on bus for every request. Both are published with
+// the request context, which carries the request ID.
+func Middleware(bus eventbus.EventBus) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if id == "" {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+			r = r.WithContext(ctx)
+
+			begin := time.Now()
+			bus.PublishContext(ctx, RequestStarted{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				RequestID: id,
+				Time:      begin,
+			})
+
+			recorder := &statusRecorder{ResponseWriter: w}
+			defer func() {
+				status := recorder.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				recovered := recover()
+				if recovered != nil {
+					status = http.StatusInternalServerError
+				}
+
+				bus.PublishContext(ctx, RequestFinished{
+					Method:    r.Method,
+					Path:      r.URL.Path,
+					RequestID: id,
+					Status:    status,
+					Bytes:     recorder.bytes,
+					Duration:  time.Since(begin),
+				})
+
+				if recovered != nil {
+					panic(recovered)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// newRequestID returns a random 16-character hex ID.
+func newRequestID() string {
+	var id [8]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// statusRecorder records the status code and body size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records status and passes it on.
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body size and passes b on.
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
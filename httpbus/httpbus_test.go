@@ -0,0 +1,99 @@
+package httpbus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// TestMiddleware verifies that requests publish started and finished events
+func TestMiddleware(t *testing.T) {
+	bus := eventbus.New(eventbus.WithContextFields(RequestIDField()))
+	defer bus.Close()
+
+	var started RequestStarted
+	var finished RequestFinished
+	var listenerID string
+	bus.Subscribe(RequestStartedType, func(event eventbus.Event) {
+		started = event.(RequestStarted)
+	})
+	bus.SubscribeContext(RequestFinishedType, func(ctx context.Context, event eventbus.Event) {
+		finished = event.(RequestFinished)
+		listenerID = RequestID(ctx)
+	})
+
+	var handlerID string
+	handler := Middleware(bus)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerID = RequestID(r.Context())
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	request.Header.Set(RequestIDHeader, "req-1")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	if started.Method != http.MethodPost || started.Path != "/orders" || started.RequestID != "req-1" {
+		t.Errorf("Expected POST /orders req-1, got %+v", started)
+	}
+	if finished.Status != http.StatusCreated || finished.Bytes != 7 || finished.RequestID != "req-1" {
+		t.Errorf("Expected status 201, 7 bytes, req-1, got %+v", finished)
+	}
+	if handlerID != "req-1" || listenerID != "req-1" {
+		t.Errorf("Expected request ID req-1 in handler and listener, got %q and %q", handlerID, listenerID)
+	}
+	if response.Header().Get(RequestIDHeader) != "req-1" {
+		t.Errorf("Expected response header req-1, got %q", response.Header().Get(RequestIDHeader))
+	}
+}
+
+// TestMiddlewareGeneratesID verifies that requests without an ID get one and default to status 200
+func TestMiddlewareGeneratesID(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+
+	var finished RequestFinished
+	bus.Subscribe(RequestFinishedType, func(event eventbus.Event) {
+		finished = event.(RequestFinished)
+	})
+
+	handler := Middleware(bus)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if finished.Status != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", finished.Status)
+	}
+	if len(finished.RequestID) != 16 || response.Header().Get(RequestIDHeader) != finished.RequestID {
+		t.Errorf("Expected a generated request ID, got %q", finished.RequestID)
+	}
+}
+
+// TestMiddlewarePanic verifies that a panicking handler is reported with status 500
+func TestMiddlewarePanic(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+
+	var finished RequestFinished
+	bus.Subscribe(RequestFinishedType, func(event eventbus.Event) {
+		finished = event.(RequestFinished)
+	})
+
+	handler := Middleware(bus)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected the panic to propagate")
+		}
+		if finished.Status != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", finished.Status)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,116 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+)
+
+// ErrMutableEvent is returned when an event with exported pointer, map, or
+// slice fields is published on a bus rejecting mutable events.
+var ErrMutableEvent = errors.New("eventbus: mutable event")
+
+// WithImmutabilityCheck enforces the rule that events should be immutable
+// value types. The first time an event type is published, its exported
+// fields are inspected; pointers, maps, and slices, including those in
+// nested structs and pointer events themselves, are reported.
+//
+// Without reject, a warning naming the fields is written to the standard
+// logger. With reject, the event is not published: PublishAndWait and
+// PublishDetailed return an error matching ErrMutableEvent and Publish
+// panics with it.
+//
+// Building with the eventbus_debug tag enables warnings on every bus.
+// Buses using WithCopyOnPublish are not checked, since their listeners
+// never share an event. The bus's own "eventbus:*" events, such as
+// Heartbeat and StatsReport carrying Stats, are not checked either.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithImmutabilityCheck(true))
+func WithImmutabilityCheck(reject bool) Option {
+	return func(bus *eventBusImpl) {
+		bus.immutability = &immutabilityCheck{
+			reject:  reject,
+			checked: make(map[reflect.Type]error),
+		}
+	}
+}
+
+// immutabilityCheck remembers the verdict for every published event type.
+// It is guarded by the bus mutex.
+type immutabilityCheck struct {
+	reject  bool
+	checked map[reflect.Type]error
+}
+
+// newImmutabilityCheck returns the default check, which only warns in
+// debug builds.
+func newImmutabilityCheck() *immutabilityCheck {
+	if !debugBuild {
+		return nil
+	}
+	return &immutabilityCheck{checked: make(map[reflect.Type]error)}
+}
+
+// check returns an error if event is mutable and mutable events are
+// rejected. Warnings are logged once per type.
+func (c *immutabilityCheck) check(event Event) error {
+	if c == nil || matchPattern("eventbus:*", event.GetType()) {
+		return nil
+	}
+
+	t := reflect.TypeOf(event)
+	err, ok := c.checked[t]
+	if !ok {
+		if fields := mutableFields(t); len(fields) > 0 {
+			err = fmt.Errorf("%w: %s (%s) has mutable fields %s", ErrMutableEvent,
+				event.GetType(), t, strings.Join(fields, ", "))
+			if !c.reject {
+				log.Printf("%v; events should be immutable value types", err)
+			}
+		}
+		c.checked[t] = err
+	}
+
+	if c.reject {
+		return err
+	}
+	return nil
+}
+
+// mutableFields returns the paths of exported fields of t holding pointers,
+// maps, or slices. A pointer type itself is reported as "*".
+func mutableFields(t reflect.Type) []string {
+	if t.Kind() == reflect.Pointer {
+		return []string{"*"}
+	}
+	var fields []string
+	collectMutableFields(t, "", make(map[reflect.Type]bool), &fields)
+	return fields
+}
+
+// collectMutableFields appends the mutable exported fields of the struct
+// type t to fields, prefixing their names with prefix.
+func collectMutableFields(t reflect.Type, prefix string, visiting map[reflect.Type]bool, fields *[]string) {
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice:
+			*fields = append(*fields, prefix+field.Name)
+		case reflect.Struct:
+			collectMutableFields(field.Type, prefix+field.Name+".", visiting, fields)
+		}
+	}
+}
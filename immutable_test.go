@@ -0,0 +1,137 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mutableEvent struct {
+	Tags     []string
+	Position struct {
+		X, Y  int
+		Trail *int
+	}
+	hidden map[string]int
+}
+
+func (e mutableEvent) GetType() EventType {
+	return "mutable:event"
+}
+
+type pointerEvent struct {
+	Name string
+}
+
+func (e *pointerEvent) GetType() EventType {
+	return "pointer:event"
+}
+
+// TestMutableFields verifies detection of exported pointer, map and slice fields
+func TestMutableFields(t *testing.T) {
+	fields := mutableFields(reflect.TypeOf(mutableEvent{}))
+	if strings.Join(fields, ",") != "Tags,Position.Trail" {
+		t.Errorf("Expected [Tags Position.Trail], got %v", fields)
+	}
+
+	if fields := mutableFields(reflect.TypeOf(testEvent{})); len(fields) != 0 {
+		t.Errorf("Expected testEvent to be immutable, got %v", fields)
+	}
+	if fields := mutableFields(reflect.TypeOf(&pointerEvent{})); len(fields) != 1 {
+		t.Errorf("Expected a pointer event to be reported, got %v", fields)
+	}
+}
+
+// TestImmutabilityCheckWarns verifies that mutable events are logged once and still delivered
+func TestImmutabilityCheckWarns(t *testing.T) {
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+
+	bus := New(WithImmutabilityCheck(false))
+	count := 0
+	bus.Subscribe("mutable:event", func(event Event) { count++ })
+
+	bus.Publish(mutableEvent{})
+	bus.Publish(mutableEvent{})
+
+	if count != 2 {
+		t.Errorf("Expected 2 deliveries, got %d", count)
+	}
+	if strings.Count(logged.String(), "mutable fields Tags, Position.Trail") != 1 {
+		t.Errorf("Expected a single warning, got %q", logged.String())
+	}
+}
+
+// TestImmutabilityCheckRejects verifies that mutable events can be rejected
+func TestImmutabilityCheckRejects(t *testing.T) {
+	bus := New(WithImmutabilityCheck(true))
+	count := 0
+	bus.Subscribe("mutable:event", func(event Event) { count++ })
+	bus.Subscribe("test:event", func(event Event) { count++ })
+
+	err := bus.PublishAndWait(context.Background(), mutableEvent{})
+	if !errors.Is(err, ErrMutableEvent) {
+		t.Errorf("Expected ErrMutableEvent, got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Expected Publish to panic for a mutable event")
+			}
+		}()
+		bus.Publish(mutableEvent{})
+	}()
+
+	bus.Publish(testEvent{eventType: "test:event"})
+	if count != 1 {
+		t.Errorf("Expected only the immutable event to be delivered, got %d", count)
+	}
+}
+
+// TestImmutabilityCheckSkippedWithCopy verifies that copied events are not checked
+func TestImmutabilityCheckSkippedWithCopy(t *testing.T) {
+	bus := New(WithImmutabilityCheck(true), WithCopyOnPublish())
+
+	if err := bus.PublishAndWait(context.Background(), mutableEvent{}); !errors.Is(err, ErrNoSubscribers) {
+		t.Errorf("Expected the event to be published, got %v", err)
+	}
+}
+
+// TestImmutabilityCheckSkipsBusEvents verifies that the bus's own periodic events are published on a rejecting bus
+func TestImmutabilityCheckSkipsBusEvents(t *testing.T) {
+	bus := New(
+		WithImmutabilityCheck(true),
+		WithHeartbeat(5*time.Millisecond),
+		WithStatsInterval(5*time.Millisecond),
+	)
+	heartbeats := make(chan struct{}, 1)
+	reports := make(chan struct{}, 1)
+	bus.Subscribe(HeartbeatType, func(event Event) {
+		select {
+		case heartbeats <- struct{}{}:
+		default:
+		}
+	})
+	bus.Subscribe(StatsType, func(event Event) {
+		select {
+		case reports <- struct{}{}:
+		default:
+		}
+	})
+
+	for _, received := range []chan struct{}{heartbeats, reports} {
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Error("Expected the periodic events to be published")
+		}
+	}
+	bus.Close()
+}
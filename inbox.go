@@ -0,0 +1,359 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNoEventID is returned by Inbox.Handle for events without an ID.
+var ErrNoEventID = errors.New("eventbus: event has no ID")
+
+// Identified is implemented by events that carry a stable ID, such as the
+// message ID assigned by the producer of a remote event.
+type Identified interface {
+	EventID() string
+}
+
+// InboxStatus is the processing state of an event ID.
+type InboxStatus string
+
+const (
+	// InboxProcessing marks an ID whose handler is running.
+	InboxProcessing InboxStatus = "processing"
+	// InboxDone marks an ID whose handler has succeeded.
+	InboxDone InboxStatus = "done"
+	// InboxFailed marks an ID whose handler has failed. It is handled
+	// again when the event is redelivered.
+	InboxFailed InboxStatus = "failed"
+)
+
+// InboxRecord is the state of an event ID recorded by an inbox.
+type InboxRecord struct {
+	ID      string      `json:"id"`
+	Status  InboxStatus `json:"status"`
+	Updated time.Time   `json:"updated"`
+	// Error is the handler error of a failed ID.
+	Error string `json:"error,omitempty"`
+}
+
+// InboxStore records the processing state of event IDs. Implementations
+// must be safe for concurrent use.
+type InboxStore interface {
+	// Begin marks id as processing and reports true, unless it is already
+	// processing or done.
+	Begin(id string) (bool, error)
+	// Complete marks id as done.
+	Complete(id string) error
+	// Fail marks id as failed with the handler error, so it can be
+	// handled again.
+	Fail(id string, cause error) error
+	// Lookup returns the record of id.
+	Lookup(id string) (InboxRecord, bool, error)
+}
+
+// Inbox invokes handlers once per event ID, so events redelivered by a
+// broker or a bridge are not processed twice. With a durable store such as
+// FileInboxStore, this holds across process restarts.
+//
+// An ID left processing by a crash is handled again after restart, since
+// the handler may not have completed; handlers whose side effects must
+// not repeat should record them in the same transaction as Complete.
+//
+// Example:
+//
+//	store, err := eventbus.OpenFileInboxStore("payments.inbox")
+//	inbox := eventbus.NewInbox(store, nil)
+//	bus.SubscribeContext("payment:settled", inbox.Listener(func(ctx context.Context, event eventbus.Event) error {
+//	    return ledger.Credit(ctx, event.(PaymentSettled))
+//	}))
+type Inbox struct {
+	store InboxStore
+	id    func(Event) (string, bool)
+}
+
+// NewInbox creates an inbox recording IDs in store. id extracts the ID of
+// an event; if nil, events implementing Identified are handled by their
+// EventID.
+func NewInbox(store InboxStore, id func(Event) (string, bool)) *Inbox {
+	if id == nil {
+		id = func(event Event) (string, bool) {
+			identified, ok := event.(Identified)
+			if !ok || identified.EventID() == "" {
+				return "", false
+			}
+			return identified.EventID(), true
+		}
+	}
+	return &Inbox{store: store, id: id}
+}
+
+// Handle calls handler with event unless its ID is processing or done, and
+// records the outcome. It reports whether handler was called, and returns
+// the handler's or the store's error. Events without an ID are refused
+// with ErrNoEventID. If handler panics, the ID is marked failed with a
+// *PanicError before the panic continues, so a redelivery is handled again.
+func (i *Inbox) Handle(ctx context.Context, event Event, handler func(ctx context.Context, event Event) error) (bool, error) {
+	id, ok := i.id(event)
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrNoEventID, event.GetType())
+	}
+
+	begun, err := i.store.Begin(id)
+	if err != nil || !begun {
+		return false, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			i.store.Fail(id, newPanicError(r))
+			panic(r)
+		}
+	}()
+	if err := handler(ctx, event); err != nil {
+		if failErr := i.store.Fail(id, err); failErr != nil {
+			return true, errors.Join(err, failErr)
+		}
+		return true, err
+	}
+	return true, i.store.Complete(id)
+}
+
+// Listener returns a listener handling events through the inbox. Handler
+// errors are only recorded in the store, where Lookup finds them; use
+// Handle to act on them.
+func (i *Inbox) Listener(handler func(ctx context.Context, event Event) error) ContextListener {
+	return func(ctx context.Context, event Event) {
+		i.Handle(ctx, event, handler)
+	}
+}
+
+// MemoryInboxStore is an InboxStore keeping records in memory. It
+// deduplicates within a process only.
+type MemoryInboxStore struct {
+	records map[string]InboxRecord
+	mutex   sync.Mutex
+	// budget bounds the memory of finished records, charged by entries;
+	// see NewMemoryInboxStoreWithBudget.
+	budget  *MemoryBudget
+	entries map[string]*budgetEntry
+}
+
+// NewMemoryInboxStore creates an empty in-memory inbox store.
+func NewMemoryInboxStore() *MemoryInboxStore {
+	return &MemoryInboxStore{records: make(map[string]InboxRecord)}
+}
+
+// NewMemoryInboxStoreWithBudget creates an empty in-memory inbox store
+// whose done and failed records are charged to the total of budget. Once
+// it is exceeded, the least recently seen records are evicted, so a
+// redelivery of their IDs is handled again. Records being processed are
+// never evicted.
+//
+// Example:
+//
+//	inbox := eventbus.NewInbox(eventbus.NewMemoryInboxStoreWithBudget(budget), nil)
+func NewMemoryInboxStoreWithBudget(budget *MemoryBudget) *MemoryInboxStore {
+	return &MemoryInboxStore{
+		records: make(map[string]InboxRecord),
+		budget:  budget,
+		entries: make(map[string]*budgetEntry),
+	}
+}
+
+// Begin marks id as processing unless it is processing or done.
+func (s *MemoryInboxStore) Begin(id string) (bool, error) {
+	_, _, begun := s.begin(id)
+	return begun, nil
+}
+
+// begin marks id as processing unless it is processing or done, and
+// returns the record it replaced, if any, for rollback.
+func (s *MemoryInboxStore) begin(id string) (InboxRecord, bool, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, existed := s.records[id]
+	if existed && previous.Status != InboxFailed {
+		s.budget.touch(s.entries[id])
+		return previous, existed, false
+	}
+	s.release(id)
+	s.records[id] = InboxRecord{ID: id, Status: InboxProcessing, Updated: time.Now()}
+	return previous, existed, true
+}
+
+// rollback undoes a begin of id, restoring the record it replaced.
+func (s *MemoryInboxStore) rollback(id string, previous InboxRecord, existed bool) {
+	if existed {
+		s.set(previous)
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.records, id)
+}
+
+// Complete marks id as done.
+func (s *MemoryInboxStore) Complete(id string) error {
+	s.set(InboxRecord{ID: id, Status: InboxDone, Updated: time.Now()})
+	return nil
+}
+
+// Fail marks id as failed.
+func (s *MemoryInboxStore) Fail(id string, cause error) error {
+	s.set(InboxRecord{ID: id, Status: InboxFailed, Updated: time.Now(), Error: cause.Error()})
+	return nil
+}
+
+// Lookup returns the record of id.
+func (s *MemoryInboxStore) Lookup(id string) (InboxRecord, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	record, ok := s.records[id]
+	if ok {
+		s.budget.touch(s.entries[id])
+	}
+	return record, ok, nil
+}
+
+// set stores record, charging it to the budget.
+func (s *MemoryInboxStore) set(record InboxRecord) {
+	s.mutex.Lock()
+	s.release(record.ID)
+	s.records[record.ID] = record
+	var victims map[budgetOwner][]*budgetEntry
+	if s.budget != nil {
+		entry := &budgetEntry{owner: s, key: record.ID, size: sizeOf(record)}
+		s.entries[record.ID] = entry
+		victims = s.budget.charge(entry)
+	}
+	s.mutex.Unlock()
+	evictAll(victims)
+}
+
+// release stops charging the record of id to the budget. The caller must
+// hold s.mutex.
+func (s *MemoryInboxStore) release(id string) {
+	if entry, ok := s.entries[id]; ok {
+		s.budget.release(entry)
+		delete(s.entries, id)
+	}
+}
+
+// evict removes the records of entries still stored.
+func (s *MemoryInboxStore) evict(entries []*budgetEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, entry := range entries {
+		id := entry.key.(string)
+		if s.entries[id] == entry {
+			delete(s.entries, id)
+			delete(s.records, id)
+		}
+	}
+}
+
+// FileInboxStore is an InboxStore appending records to a file as JSON
+// Lines, so processed IDs survive restarts. Completions are synced to disk
+// before Complete returns. The whole history is kept in the file and
+// indexed in memory when it is opened.
+type FileInboxStore struct {
+	memory *MemoryInboxStore
+	file   *os.File
+	mutex  sync.Mutex
+}
+
+// OpenFileInboxStore opens or creates the inbox file at path. IDs left
+// processing by a previous run are considered failed, so their events are
+// handled again when redelivered.
+func OpenFileInboxStore(path string) (*FileInboxStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	memory := NewMemoryInboxStore()
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var record InboxRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("eventbus: decoding inbox line %d: %w", line, err)
+		}
+		if record.Status == InboxProcessing {
+			record.Status = InboxFailed
+			record.Error = "interrupted"
+		}
+		memory.records[record.ID] = record
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &FileInboxStore{memory: memory, file: file}, nil
+}
+
+// Begin marks id as processing unless it is processing or done. If the
+// record cannot be written, id keeps its previous state.
+func (s *FileInboxStore) Begin(id string) (bool, error) {
+	previous, existed, begun := s.memory.begin(id)
+	if !begun {
+		return false, nil
+	}
+	if err := s.append(InboxRecord{ID: id, Status: InboxProcessing, Updated: time.Now()}, false); err != nil {
+		s.memory.rollback(id, previous, existed)
+		return false, err
+	}
+	return true, nil
+}
+
+// Complete marks id as done and syncs the file.
+func (s *FileInboxStore) Complete(id string) error {
+	s.memory.Complete(id)
+	return s.append(InboxRecord{ID: id, Status: InboxDone, Updated: time.Now()}, true)
+}
+
+// Fail marks id as failed.
+func (s *FileInboxStore) Fail(id string, cause error) error {
+	s.memory.Fail(id, cause)
+	return s.append(InboxRecord{ID: id, Status: InboxFailed, Updated: time.Now(), Error: cause.Error()}, false)
+}
+
+// Lookup returns the record of id.
+func (s *FileInboxStore) Lookup(id string) (InboxRecord, bool, error) {
+	return s.memory.Lookup(id)
+}
+
+// Close closes the file.
+func (s *FileInboxStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.file.Close()
+}
+
+// append writes record to the file, syncing it if sync is set.
+func (s *FileInboxStore) append(record InboxRecord, sync bool) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if sync {
+		return s.file.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// paymentEvent is an event carrying a stable ID
+type paymentEvent struct {
+	id string
+}
+
+func (e paymentEvent) GetType() EventType {
+	return "payment:settled"
+}
+
+func (e paymentEvent) EventID() string {
+	return e.id
+}
+
+// TestInboxHandlesOnce verifies that redelivered events are handled once
+func TestInboxHandlesOnce(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	inbox := NewInbox(NewMemoryInboxStore(), nil)
+
+	var handled []string
+	bus.SubscribeContext("payment:settled", inbox.Listener(func(ctx context.Context, event Event) error {
+		handled = append(handled, event.(paymentEvent).id)
+		return nil
+	}))
+
+	for _, id := range []string{"p1", "p2", "p1", "p2", "p3"} {
+		bus.Publish(paymentEvent{id: id})
+	}
+
+	if len(handled) != 3 || handled[0] != "p1" || handled[1] != "p2" || handled[2] != "p3" {
+		t.Errorf("Expected [p1 p2 p3], got %v", handled)
+	}
+}
+
+// TestInboxRetriesFailures verifies that failed events are handled again and recorded
+func TestInboxRetriesFailures(t *testing.T) {
+	store := NewMemoryInboxStore()
+	inbox := NewInbox(store, nil)
+	failure := errors.New("ledger unavailable")
+
+	calls := 0
+	handler := func(ctx context.Context, event Event) error {
+		calls++
+		if calls == 1 {
+			return failure
+		}
+		return nil
+	}
+
+	handled, err := inbox.Handle(context.Background(), paymentEvent{id: "p1"}, handler)
+	if !handled || !errors.Is(err, failure) {
+		t.Fatalf("Expected the handler error, got %v and %v", handled, err)
+	}
+	if record, ok, _ := store.Lookup("p1"); !ok || record.Status != InboxFailed || record.Error != failure.Error() {
+		t.Errorf("Expected a failed record, got %+v", record)
+	}
+
+	if handled, err := inbox.Handle(context.Background(), paymentEvent{id: "p1"}, handler); !handled || err != nil {
+		t.Errorf("Expected the retry to succeed, got %v and %v", handled, err)
+	}
+	if handled, _ := inbox.Handle(context.Background(), paymentEvent{id: "p1"}, handler); handled {
+		t.Error("Expected a completed ID not to be handled again")
+	}
+
+	if _, err := inbox.Handle(context.Background(), testEvent{eventType: "payment:settled"}, handler); !errors.Is(err, ErrNoEventID) {
+		t.Errorf("Expected ErrNoEventID, got %v", err)
+	}
+}
+
+// TestInboxHandlerPanic verifies that a panicking handler leaves its ID failed so a redelivery is handled again
+func TestInboxHandlerPanic(t *testing.T) {
+	store := NewMemoryInboxStore()
+	inbox := NewInbox(store, nil)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected the panic to continue")
+			}
+		}()
+		inbox.Handle(context.Background(), paymentEvent{id: "p1"}, func(ctx context.Context, event Event) error {
+			panic("ledger unavailable")
+		})
+	}()
+	if record, ok, _ := store.Lookup("p1"); !ok || record.Status != InboxFailed {
+		t.Errorf("Expected a failed record, got %+v", record)
+	}
+
+	handled, err := inbox.Handle(context.Background(), paymentEvent{id: "p1"}, func(ctx context.Context, event Event) error {
+		return nil
+	})
+	if !handled || err != nil {
+		t.Errorf("Expected the redelivery to be handled, got %v and %v", handled, err)
+	}
+}
+
+// TestFileInboxStoreBeginWriteError verifies that an ID whose processing record cannot be written keeps its previous state
+func TestFileInboxStoreBeginWriteError(t *testing.T) {
+	store, err := OpenFileInboxStore(filepath.Join(t.TempDir(), "payments.inbox"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	store.Begin("failed")
+	store.Fail("failed", errors.New("ledger unavailable"))
+	store.Close()
+
+	if begun, err := store.Begin("new"); begun || err == nil {
+		t.Errorf("Expected the write error, got %v and %v", begun, err)
+	}
+	if record, ok, _ := store.Lookup("new"); ok {
+		t.Errorf("Expected no record for the new ID, got %+v", record)
+	}
+	if begun, err := store.Begin("failed"); begun || err == nil {
+		t.Errorf("Expected the write error, got %v and %v", begun, err)
+	}
+	if record, _, _ := store.Lookup("failed"); record.Status != InboxFailed || record.Error != "ledger unavailable" {
+		t.Errorf("Expected the failed record kept, got %+v", record)
+	}
+}
+
+// TestFileInboxStoreRestart verifies that processed IDs survive reopening the store
+func TestFileInboxStoreRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payments.inbox")
+
+	store, err := OpenFileInboxStore(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	store.Begin("done")
+	store.Complete("done")
+	store.Begin("interrupted")
+	store.Close()
+
+	store, err = OpenFileInboxStore(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+
+	if begun, _ := store.Begin("done"); begun {
+		t.Error("Expected a completed ID to stay done after restart")
+	}
+	if record, _, _ := store.Lookup("interrupted"); record.Status != InboxFailed {
+		t.Errorf("Expected an interrupted ID to be failed after restart, got %+v", record)
+	}
+	if begun, _ := store.Begin("interrupted"); !begun {
+		t.Error("Expected an interrupted ID to be handled again")
+	}
+}
@@ -0,0 +1,17 @@
+// Package core declares the event types shared by package eventbus and
+// package tinybus, so events and listeners written for one bus work with
+// the other. It imports nothing, keeping tinybus free of the dependencies
+// of eventbus.
+package core
+
+// EventType identifies the kind of an event.
+type EventType string
+
+// Event is the interface that all events must implement.
+type Event interface {
+	// GetType returns the event's type identifier.
+	GetType() EventType
+}
+
+// EventListener is a function that handles an event.
+type EventListener func(Event)
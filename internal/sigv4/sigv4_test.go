@@ -0,0 +1,25 @@
+package sigv4
+
+import (
+	"net/url"
+	"testing"
+)
+
+// TestCanonicalQuery verifies that query parameters are sorted and strictly encoded
+func TestCanonicalQuery(t *testing.T) {
+	query := url.Values{"prefix": {"a b/c"}, "list-type": {"2"}, "marker": {"z", "y"}}
+	expected := "list-type=2&marker=y&marker=z&prefix=a%20b%2Fc"
+	if got := CanonicalQuery(query); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestURIEncode verifies that slashes are kept in paths only
+func TestURIEncode(t *testing.T) {
+	if got := URIEncode("/bucket/a+b~c", false); got != "/bucket/a%2Bb~c" {
+		t.Errorf("Expected %q, got %q", "/bucket/a%2Bb~c", got)
+	}
+	if got := URIEncode("a/b", true); got != "a%2Fb" {
+		t.Errorf("Expected %q, got %q", "a%2Fb", got)
+	}
+}
@@ -0,0 +1,189 @@
+package eventbus
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the buckets of latency
+// histograms. Latencies above the last bound fall into an overflow bucket.
+var LatencyBuckets = []time.Duration{
+	10 * time.Microsecond,
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Histogram is a snapshot of a latency distribution.
+type Histogram struct {
+	// Bounds are the upper bounds of the buckets, LatencyBuckets.
+	Bounds []time.Duration
+	// Counts holds the number of observations per bucket, with one more
+	// entry than Bounds for the observations above the last bound.
+	Counts []uint64
+	// Count is the number of observations.
+	Count uint64
+	// Sum is the total of the observations.
+	Sum time.Duration
+}
+
+// Mean returns the average observation, or 0 without observations.
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile returns the upper bound of the bucket holding the q-quantile,
+// for q between 0 and 1, such as 0.99 for the 99th percentile. It returns
+// 0 without observations, and the last bound if the quantile lies in the
+// overflow bucket.
+func (h Histogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 || len(h.Bounds) == 0 {
+		return 0
+	}
+	rank := max(uint64(math.Ceil(q*float64(h.Count))), 1)
+	var seen uint64
+	for i, count := range h.Counts {
+		seen += count
+		if seen >= rank {
+			return h.Bounds[min(i, len(h.Bounds)-1)]
+		}
+	}
+	return h.Bounds[len(h.Bounds)-1]
+}
+
+// LatencyRecorder collects observations into a Histogram. It is safe for
+// concurrent use. Create one with NewLatencyRecorder.
+type LatencyRecorder struct {
+	counts []atomic.Uint64
+	sum    atomic.Int64
+}
+
+// NewLatencyRecorder creates a recorder with LatencyBuckets.
+func NewLatencyRecorder() *LatencyRecorder {
+	return &LatencyRecorder{counts: make([]atomic.Uint64, len(LatencyBuckets)+1)}
+}
+
+// Observe records a latency. Negative latencies, which clock skew between
+// hosts can cause, count as zero.
+func (r *LatencyRecorder) Observe(latency time.Duration) {
+	latency = max(latency, 0)
+	bucket, _ := slices.BinarySearch(LatencyBuckets, latency)
+	r.counts[bucket].Add(1)
+	r.sum.Add(int64(latency))
+}
+
+// Histogram returns the observations recorded so far.
+func (r *LatencyRecorder) Histogram() Histogram {
+	h := Histogram{
+		Bounds: LatencyBuckets,
+		Counts: make([]uint64, len(r.counts)),
+		Sum:    time.Duration(r.sum.Load()),
+	}
+	for i := range r.counts {
+		h.Counts[i] = r.counts[i].Load()
+		h.Count += h.Counts[i]
+	}
+	return h
+}
+
+// TopicLatency describes where the delivery time of a topic is spent.
+type TopicLatency struct {
+	// Queue is the time from publishing an event to a listener starting
+	// to handle it, including the time spent in asynchronous queues and
+	// waiting for earlier listeners.
+	Queue Histogram
+	// Handler is the time listeners take to handle an event.
+	Handler Histogram
+}
+
+// WithLatencyTracking records, per topic, how long events wait before
+// each listener starts and how long listeners take, reported in
+// Stats.Latency. It costs two clock readings per delivery.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithAsync(8, 1024), eventbus.WithLatencyTracking())
+//
+//	for topic, latency := range bus.Stats().Latency {
+//	    log.Printf("%s: p99 queue %v, p99 handler %v", topic,
+//	        latency.Queue.Quantile(0.99), latency.Handler.Quantile(0.99))
+//	}
+func WithLatencyTracking() Option {
+	return func(bus *eventBusImpl) {
+		bus.latency = &latencyTracker{topics: make(map[EventType]*topicRecorders)}
+	}
+}
+
+// latencyTracker holds the recorders of every topic delivered to.
+type latencyTracker struct {
+	mutex  sync.RWMutex
+	topics map[EventType]*topicRecorders
+}
+
+// topicRecorders records the latencies of one topic.
+type topicRecorders struct {
+	queue   *LatencyRecorder
+	handler *LatencyRecorder
+}
+
+// topic returns the recorders of eventType, creating them on first use.
+func (t *latencyTracker) topic(eventType EventType) *topicRecorders {
+	t.mutex.RLock()
+	recorders, ok := t.topics[eventType]
+	t.mutex.RUnlock()
+	if ok {
+		return recorders
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if recorders, ok = t.topics[eventType]; !ok {
+		recorders = &topicRecorders{queue: NewLatencyRecorder(), handler: NewLatencyRecorder()}
+		t.topics[eventType] = recorders
+	}
+	return recorders
+}
+
+// observe records the queueing latency of a delivery starting now and
+// returns a function recording the handler duration.
+func (t *latencyTracker) observe(ctx context.Context, event Event) func() {
+	start := time.Now()
+	recorders := t.topic(event.GetType())
+	if envelope, ok := ctx.Value(envelopeKey{}).(*Envelope); ok {
+		recorders.queue.Observe(start.Sub(envelope.Time))
+	}
+	return func() {
+		recorders.handler.Observe(time.Since(start))
+	}
+}
+
+// snapshot returns the histograms of every topic.
+func (t *latencyTracker) snapshot() map[EventType]TopicLatency {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	latency := make(map[EventType]TopicLatency, len(t.topics))
+	for eventType, recorders := range t.topics {
+		latency[eventType] = TopicLatency{
+			Queue:   recorders.queue.Histogram(),
+			Handler: recorders.handler.Histogram(),
+		}
+	}
+	return latency
+}
@@ -0,0 +1,66 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLatencyTracking verifies that queueing and handler latencies are recorded per topic
+func TestLatencyTracking(t *testing.T) {
+	bus := New(WithAsync(1, 16), WithLatencyTracking())
+	defer bus.Close()
+
+	bus.Subscribe("render:frame", func(event Event) {
+		time.Sleep(2 * time.Millisecond)
+	})
+	bus.Subscribe("input:key", func(event Event) {})
+	for range 3 {
+		bus.Publish(Of("render:frame", 1))
+	}
+	bus.Publish(Of("input:key", 1))
+	bus.Close()
+
+	latency := bus.Stats().Latency
+	frames := latency["render:frame"]
+	if frames.Handler.Count != 3 || frames.Handler.Quantile(0.5) < 2*time.Millisecond || frames.Handler.Mean() < 2*time.Millisecond {
+		t.Errorf("Expected 3 handler latencies of at least 2ms, got %+v", frames.Handler)
+	}
+	if frames.Queue.Count != 3 {
+		t.Errorf("Expected 3 queue latencies, got %d", frames.Queue.Count)
+	}
+	// The key event waited for the three frames on the single worker.
+	if keys := latency["input:key"]; keys.Queue.Count != 1 || keys.Queue.Quantile(1) < 5*time.Millisecond {
+		t.Errorf("Expected the key event to have queued behind the frames, got %+v", keys.Queue)
+	}
+}
+
+// TestLatencyTrackingDisabled verifies that Stats has no latencies without WithLatencyTracking
+func TestLatencyTrackingDisabled(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	bus.Subscribe("input:key", func(event Event) {})
+	bus.Publish(Of("input:key", 1))
+
+	if latency := bus.Stats().Latency; latency != nil {
+		t.Errorf("Expected no latencies, got %v", latency)
+	}
+}
+
+// TestHistogramQuantile verifies quantiles and the overflow bucket
+func TestHistogramQuantile(t *testing.T) {
+	recorder := NewLatencyRecorder()
+	for _, latency := range []time.Duration{time.Microsecond, 20 * time.Microsecond, 20 * time.Microsecond, time.Minute} {
+		recorder.Observe(latency)
+	}
+	h := recorder.Histogram()
+
+	cases := map[float64]time.Duration{0: 10 * time.Microsecond, 0.25: 10 * time.Microsecond, 0.5: 50 * time.Microsecond, 1: 10 * time.Second}
+	for q, expected := range cases {
+		if got := h.Quantile(q); got != expected {
+			t.Errorf("Expected quantile %v to be %v, got %v", q, expected, got)
+		}
+	}
+	if (Histogram{}).Quantile(0.5) != 0 || (Histogram{}).Mean() != 0 {
+		t.Error("Expected an empty histogram to report zero")
+	}
+}
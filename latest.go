@@ -0,0 +1,166 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// lastValueCache remembers the latest event per key for a single topic.
+// Its configuration is guarded by the bus mutex and its values by its own
+// mutex, so a memory budget can evict them at any time.
+type lastValueCache struct {
+	keyFn KeyFunc
+	// ttl is how long values stay valid, if not 0.
+	ttl time.Duration
+	// budget bounds the memory of the values; see WithMemoryBudget.
+	budget *MemoryBudget
+	values map[any]lastValue
+	mutex  sync.Mutex
+}
+
+// lastValue is a cached event with the time it was published.
+type lastValue struct {
+	event Event
+	time  time.Time
+	// entry is the charge of the value to the budget, if there is one.
+	entry *budgetEntry
+}
+
+// store records the event of envelope as the latest value for its key.
+func (cache *lastValueCache) store(envelope *Envelope) {
+	var key any
+	if cache.keyFn != nil {
+		key = cache.keyFn(envelope.Event)
+	}
+	value := lastValue{event: envelope.Event, time: envelope.OriginTime}
+
+	cache.mutex.Lock()
+	if cache.budget != nil {
+		value.entry = &budgetEntry{owner: cache, key: key, topic: envelope.Event.GetType(), size: sizeOf(envelope.Event)}
+		cache.budget.release(cache.values[key].entry)
+	}
+	cache.values[key] = value
+	victims := cache.budget.charge(value.entry)
+	cache.mutex.Unlock()
+	evictAll(victims)
+}
+
+// load returns the value for key, removing it if it has expired.
+func (cache *lastValueCache) load(key any, now time.Time) (Event, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	value, ok := cache.values[key]
+	if !ok {
+		return nil, false
+	}
+	if cache.ttl > 0 && now.Sub(value.time) > cache.ttl {
+		cache.budget.release(value.entry)
+		delete(cache.values, key)
+		return nil, false
+	}
+	cache.budget.touch(value.entry)
+	return value.event, true
+}
+
+// remove deletes the values under keys, or all values without keys.
+func (cache *lastValueCache) remove(keys ...any) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if len(keys) == 0 {
+		for _, value := range cache.values {
+			cache.budget.release(value.entry)
+		}
+		clear(cache.values)
+		return
+	}
+	for _, key := range keys {
+		cache.budget.release(cache.values[key].entry)
+		delete(cache.values, key)
+	}
+}
+
+// evict removes the values of entries still cached.
+func (cache *lastValueCache) evict(entries []*budgetEntry) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, entry := range entries {
+		if value, ok := cache.values[entry.key]; ok && value.entry == entry {
+			delete(cache.values, entry.key)
+		}
+	}
+}
+
+// lastValueCache returns the cache of eventType, creating it on first use.
+func (bus *eventBusImpl) lastValueCache(eventType EventType) *lastValueCache {
+	cache, ok := bus.latest[eventType]
+	if !ok {
+		cache = &lastValueCache{values: make(map[any]lastValue)}
+		bus.latest[eventType] = cache
+	}
+	return cache
+}
+
+// WithLastValueCache keeps the most recent event published for eventType,
+// keyed by keyFn, so it can be queried later with Latest. This lets systems
+// ask for current state, such as the last known position of an entity,
+// without maintaining their own maps.
+//
+// If keyFn is nil, only the single latest event is kept and it is retrieved
+// with a nil key.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithLastValueCache("player:moved",
+//	    func(e eventbus.Event) any { return e.(PlayerMoved).PlayerID }))
+func WithLastValueCache(eventType EventType, keyFn KeyFunc) Option {
+	return func(bus *eventBusImpl) {
+		bus.lastValueCache(eventType).keyFn = keyFn
+	}
+}
+
+// WithLastValueTTL expires the cached values of eventType ttl after they
+// were published, so Latest stops reporting state that is no longer
+// valid, such as the last level loaded. The age is measured from the
+// original publish time of bridged events; see WithOriginTime. Without
+// WithLastValueCache for eventType, the single latest event is kept.
+//
+// Example:
+//
+//	bus := eventbus.New(
+//	    eventbus.WithLastValueCache("lobby:status", nil),
+//	    eventbus.WithLastValueTTL("lobby:status", 30*time.Second),
+//	)
+func WithLastValueTTL(eventType EventType, ttl time.Duration) Option {
+	return func(bus *eventBusImpl) {
+		bus.lastValueCache(eventType).ttl = ttl
+	}
+}
+
+// Latest returns the most recent event published for eventType under key.
+func (bus *eventBusImpl) Latest(eventType EventType, key any) (Event, bool) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	cache, ok := bus.latest[eventType]
+	if !ok {
+		return nil, false
+	}
+	return cache.load(key, time.Now())
+}
+
+// ClearLatest removes the cached values of eventType under keys, or all
+// of them if no keys are given, so Latest stops reporting them until the
+// next event is published.
+func (bus *eventBusImpl) ClearLatest(eventType EventType, keys ...any) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	cache, ok := bus.latest[eventType]
+	if !ok {
+		return
+	}
+	cache.remove(keys...)
+}
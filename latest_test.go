@@ -0,0 +1,104 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestLatest verifies that the last event per key is cached
+func TestLatest(t *testing.T) {
+	bus := New(WithLastValueCache("player:health", func(event Event) any {
+		return event.(healthEvent).playerID
+	}))
+
+	if _, ok := bus.Latest("player:health", "p1"); ok {
+		t.Fatal("Expected no cached value before publishing")
+	}
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 100})
+	bus.Publish(healthEvent{playerID: "p2", hp: 80})
+	bus.Publish(healthEvent{playerID: "p1", hp: 70})
+
+	event, ok := bus.Latest("player:health", "p1")
+	if !ok {
+		t.Fatal("Expected a cached value for p1")
+	}
+	if hp := event.(healthEvent).hp; hp != 70 {
+		t.Errorf("Expected 70 HP, got %d", hp)
+	}
+
+	event, ok = bus.Latest("player:health", "p2")
+	if !ok || event.(healthEvent).hp != 80 {
+		t.Errorf("Expected p2 at 80 HP, got %v", event)
+	}
+}
+
+// TestLatestUncachedTopic verifies that topics without a cache report nothing
+func TestLatestUncachedTopic(t *testing.T) {
+	bus := New(WithLastValueCache("config:changed", nil))
+
+	bus.Publish(testEvent{eventType: "other:topic", data: "test"})
+	if _, ok := bus.Latest("other:topic", nil); ok {
+		t.Error("Expected no value for a topic without a cache")
+	}
+
+	bus.Publish(testEvent{eventType: "config:changed", data: "v1"})
+	bus.Publish(testEvent{eventType: "config:changed", data: "v2"})
+
+	event, ok := bus.Latest("config:changed", nil)
+	if !ok || event.(testEvent).data != "v2" {
+		t.Errorf("Expected latest config v2, got %v", event)
+	}
+}
+
+// TestClearLatest verifies that cached values can be removed per key and per topic
+func TestClearLatest(t *testing.T) {
+	bus := New(WithLastValueCache("player:health", func(event Event) any {
+		return event.(healthEvent).playerID
+	}))
+	bus.Publish(healthEvent{playerID: "p1", hp: 100})
+	bus.Publish(healthEvent{playerID: "p2", hp: 80})
+	bus.Publish(healthEvent{playerID: "p3", hp: 60})
+
+	bus.ClearLatest("player:health", "p1")
+	if _, ok := bus.Latest("player:health", "p1"); ok {
+		t.Error("Expected p1 to be cleared")
+	}
+	if _, ok := bus.Latest("player:health", "p2"); !ok {
+		t.Error("Expected p2 to be kept")
+	}
+
+	bus.ClearLatest("player:health")
+	if _, ok := bus.Latest("player:health", "p3"); ok {
+		t.Error("Expected every value to be cleared")
+	}
+	bus.ClearLatest("other:topic")
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 50})
+	if event, ok := bus.Latest("player:health", "p1"); !ok || event.(healthEvent).hp != 50 {
+		t.Errorf("Expected the next event to be cached, got %v", event)
+	}
+}
+
+// TestLastValueTTL verifies that cached values expire
+func TestLastValueTTL(t *testing.T) {
+	bus := New(WithLastValueTTL("level:loaded", 20*time.Millisecond))
+	bus.Publish(Of("level:loaded", "forest"))
+
+	if event, ok := bus.Latest("level:loaded", nil); !ok {
+		t.Fatal("Expected the fresh level")
+	} else if level, _ := Payload[string](event); level != "forest" {
+		t.Fatalf("Expected forest, got %q", level)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := bus.Latest("level:loaded", nil); ok {
+		t.Error("Expected the level to expire")
+	}
+
+	old := time.Now().Add(-time.Minute)
+	bus.PublishContext(WithOriginTime(context.Background(), old), Of("level:loaded", "cave"))
+	if _, ok := bus.Latest("level:loaded", nil); ok {
+		t.Error("Expected a bridged event published long ago to be expired")
+	}
+}
@@ -0,0 +1,242 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Matcher selects the events delivered to a listener subscribed with
+// SubscribeMatching by type, event fields, and envelope, so routing
+// conditions are declared once instead of being checked by every
+// listener. An event must satisfy every condition set.
+type Matcher struct {
+	// Types are the event types to subscribe to. Patterns such as
+	// "order:*" require a bus created with a WildcardRouter.
+	Types []EventType
+	// Fields maps field paths of the event to the values they must equal.
+	// A path names an exported struct field, or nested fields separated
+	// by dots such as "Customer.Country", of the event or of the payload
+	// of events created with Of. Pointers are followed. Events without
+	// the field do not match.
+	Fields map[string]any
+	// Metadata maps envelope metadata keys, such as the tenant or source
+	// recorded with WithContextFields, to the values they must equal.
+	Metadata map[string]string
+	// Where is called last, with the event and its envelope, for any
+	// other condition.
+	Where func(event Event, envelope Envelope) bool
+}
+
+// MatchSubscription holds the subscriptions of a listener subscribed with
+// SubscribeMatching, one per matched type.
+type MatchSubscription struct {
+	subscriptions []*Subscription
+}
+
+// Cancel cancels every subscription.
+func (m *MatchSubscription) Cancel() {
+	for _, sub := range m.subscriptions {
+		sub.Cancel()
+	}
+}
+
+// Subscriptions returns the subscriptions, in the order of Matcher.Types.
+func (m *MatchSubscription) Subscriptions() []*Subscription {
+	return m.subscriptions
+}
+
+// SubscribeMatching subscribes listener to every type of matcher,
+// delivering only the events matching its other conditions. The field
+// paths are parsed once, and resolved once per Go type of the events
+// received. It returns an error if matcher has no types or an invalid
+// field path. Other options apply to the matching events only.
+//
+// Example:
+//
+//	eventbus.SubscribeMatching(bus, eventbus.Matcher{
+//	    Types:    []eventbus.EventType{"order:placed", "order:cancelled"},
+//	    Fields:   map[string]any{"Customer.Country": "DE"},
+//	    Metadata: map[string]string{"tenant": "acme"},
+//	}, func(ctx context.Context, event eventbus.Event) {
+//	    notifyGermanSales(event)
+//	})
+func SubscribeMatching(bus EventBus, matcher Matcher, listener ContextListener, opts ...SubscribeOption) (*MatchSubscription, error) {
+	if len(matcher.Types) == 0 {
+		return nil, errors.New("eventbus: matcher has no event types")
+	}
+	compiled, err := matcher.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]SubscribeOption{compiled.option()}, opts...)
+	subscription := &MatchSubscription{}
+	for _, eventType := range matcher.Types {
+		subscription.subscriptions = append(subscription.subscriptions, bus.SubscribeContext(eventType, listener, opts...))
+	}
+	return subscription, nil
+}
+
+// compiledMatcher is a Matcher with parsed field paths.
+type compiledMatcher struct {
+	fields   []fieldCondition
+	metadata map[string]string
+	where    func(event Event, envelope Envelope) bool
+}
+
+// fieldCondition requires the field at path to equal want.
+type fieldCondition struct {
+	path []string
+	want any
+	// resolved caches the field index path per Go type, nil for types
+	// without the field.
+	resolved sync.Map
+}
+
+// compile parses the field paths of m.
+func (m Matcher) compile() (*compiledMatcher, error) {
+	compiled := &compiledMatcher{metadata: m.Metadata, where: m.Where}
+	for path, want := range m.Fields {
+		parts := strings.Split(path, ".")
+		for _, part := range parts {
+			if !token.IsExported(part) {
+				return nil, fmt.Errorf("eventbus: invalid matcher field path %q", path)
+			}
+		}
+		compiled.fields = append(compiled.fields, fieldCondition{path: parts, want: want})
+	}
+	return compiled, nil
+}
+
+// option returns the subscribe option filtering by the matcher.
+func (c *compiledMatcher) option() SubscribeOption {
+	return func(config *subscribeConfig) {
+		config.filters = append(config.filters, c.matches)
+		config.options = append(config.options, "match")
+	}
+}
+
+// matches reports whether event, delivered with ctx, satisfies every
+// condition.
+func (c *compiledMatcher) matches(ctx context.Context, event Event) bool {
+	var value reflect.Value
+	if len(c.fields) > 0 {
+		var payload any = event
+		if wrapped, ok := event.(interface{ payloadValue() any }); ok {
+			payload = wrapped.payloadValue()
+		}
+		value = reflect.ValueOf(payload)
+	}
+	for i := range c.fields {
+		if !c.fields[i].matches(value) {
+			return false
+		}
+	}
+
+	if len(c.metadata) == 0 && c.where == nil {
+		return true
+	}
+	envelope, ok := EnvelopeFromContext(ctx)
+	if !ok {
+		envelope = Envelope{Event: event}
+	}
+	for key, want := range c.metadata {
+		if got, ok := envelope.Metadata[key]; !ok || got != want {
+			return false
+		}
+	}
+	return c.where == nil || c.where(event, envelope)
+}
+
+// matches reports whether the field of value equals the wanted value.
+func (f *fieldCondition) matches(value reflect.Value) bool {
+	if !value.IsValid() {
+		return false
+	}
+	index, ok := f.resolved.Load(value.Type())
+	if !ok {
+		index, _ = f.resolved.LoadOrStore(value.Type(), resolveField(value.Type(), f.path))
+	}
+	if index == nil {
+		return false
+	}
+
+	for _, i := range index.([][]int) {
+		for value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return false
+			}
+			value = value.Elem()
+		}
+		var err error
+		if value, err = value.FieldByIndexErr(i); err != nil {
+			return false
+		}
+	}
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return f.want == nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() || !value.Type().Comparable() {
+		return false
+	}
+	want := reflect.ValueOf(f.want)
+	if !want.IsValid() {
+		return false
+	}
+	if want.Type() != value.Type() {
+		converted, ok := convertExactly(want, value.Type())
+		if !ok {
+			return false
+		}
+		want = converted
+	}
+	return value.Equal(want)
+}
+
+// convertExactly converts value to type t if both are numbers, or both
+// have the same kind, such as a string and a named string type, and the
+// value survives the conversion unchanged, so the int 3 matches a uint8
+// field holding 3 but 256 does not, and an int never matches a string.
+func convertExactly(value reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	number := func(kind reflect.Kind) bool {
+		return kind >= reflect.Int && kind <= reflect.Float64
+	}
+	from, to := value.Kind(), t.Kind()
+	if (from != to && !(number(from) && number(to))) || !value.CanConvert(t) {
+		return reflect.Value{}, false
+	}
+	converted := value.Convert(t)
+	if !converted.Convert(value.Type()).Equal(value) {
+		return reflect.Value{}, false
+	}
+	return converted, true
+}
+
+// resolveField returns the index paths of the fields named by path in
+// type t, one per path element, or nil if t has no such field.
+func resolveField(t reflect.Type, path []string) any {
+	indexes := make([][]int, 0, len(path))
+	for _, name := range path {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		field, ok := t.FieldByName(name)
+		if !ok || !field.IsExported() {
+			return nil
+		}
+		indexes = append(indexes, field.Index)
+		t = field.Type
+	}
+	return indexes
+}
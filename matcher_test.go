@@ -0,0 +1,110 @@
+package eventbus
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// matchedCustomer is a nested field of matchedOrder.
+type matchedCustomer struct {
+	Country string
+}
+
+// matchedOrder is an order event matched by field.
+type matchedOrder struct {
+	eventType EventType
+	ID        string
+	Amount    int
+	Customer  *matchedCustomer
+}
+
+func (e matchedOrder) GetType() EventType {
+	return e.eventType
+}
+
+// tenantKey is the context key of the tenant recorded in metadata.
+type tenantKey struct{}
+
+// TestSubscribeMatching verifies that types, fields, metadata, and Where are all required
+func TestSubscribeMatching(t *testing.T) {
+	bus := New(WithContextFields(ContextValue("tenant", tenantKey{})))
+	defer bus.Close()
+
+	var received []string
+	subscription, err := SubscribeMatching(bus, Matcher{
+		Types:    []EventType{"order:placed", "order:cancelled"},
+		Fields:   map[string]any{"Customer.Country": "DE"},
+		Metadata: map[string]string{"tenant": "acme"},
+		Where: func(event Event, envelope Envelope) bool {
+			return event.(matchedOrder).Amount > 10
+		},
+	}, func(ctx context.Context, event Event) {
+		received = append(received, event.(matchedOrder).ID)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+	bus.PublishContext(acme, matchedOrder{eventType: "order:placed", ID: "1", Amount: 20, Customer: &matchedCustomer{Country: "DE"}})
+	bus.PublishContext(acme, matchedOrder{eventType: "order:cancelled", ID: "2", Amount: 20, Customer: &matchedCustomer{Country: "DE"}})
+	bus.PublishContext(acme, matchedOrder{eventType: "order:shipped", ID: "3", Amount: 20, Customer: &matchedCustomer{Country: "DE"}})
+	bus.PublishContext(acme, matchedOrder{eventType: "order:placed", ID: "4", Amount: 20, Customer: &matchedCustomer{Country: "FR"}})
+	bus.PublishContext(acme, matchedOrder{eventType: "order:placed", ID: "5", Amount: 20})
+	bus.PublishContext(acme, matchedOrder{eventType: "order:placed", ID: "6", Amount: 5, Customer: &matchedCustomer{Country: "DE"}})
+	bus.Publish(matchedOrder{eventType: "order:placed", ID: "7", Amount: 20, Customer: &matchedCustomer{Country: "DE"}})
+
+	if !slices.Equal(received, []string{"1", "2"}) {
+		t.Errorf("Expected orders 1 and 2, got %v", received)
+	}
+	if n := len(subscription.Subscriptions()); n != 2 {
+		t.Errorf("Expected 2 subscriptions, got %d", n)
+	}
+	subscription.Cancel()
+	if n := len(bus.Snapshot().Subscriptions()); n != 0 {
+		t.Errorf("Expected every subscription to be cancelled, got %d", n)
+	}
+}
+
+// TestSubscribeMatchingPayloads verifies that fields of Of payloads are matched with converted values
+func TestSubscribeMatchingPayloads(t *testing.T) {
+	type level struct {
+		Name       string
+		Difficulty uint8
+	}
+	bus := New()
+	defer bus.Close()
+
+	var received []string
+	SubscribeMatching(bus, Matcher{
+		Types:  []EventType{"level:loaded"},
+		Fields: map[string]any{"Difficulty": 3},
+	}, func(ctx context.Context, event Event) {
+		payload, _ := Payload[level](event)
+		received = append(received, payload.Name)
+	})
+	bus.Publish(Of("level:loaded", level{Name: "forest", Difficulty: 3}))
+	bus.Publish(Of("level:loaded", level{Name: "cave", Difficulty: 5}))
+	bus.Publish(Of("level:loaded", "not a struct"))
+
+	if !slices.Equal(received, []string{"forest"}) {
+		t.Errorf("Expected only the forest, got %v", received)
+	}
+}
+
+// TestSubscribeMatchingErrors verifies that invalid matchers are rejected
+func TestSubscribeMatchingErrors(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	listener := func(ctx context.Context, event Event) {}
+
+	if _, err := SubscribeMatching(bus, Matcher{}, listener); err == nil {
+		t.Error("Expected an error without types")
+	}
+	for _, path := range []string{"", "Customer.", "customer"} {
+		if _, err := SubscribeMatching(bus, Matcher{Types: []EventType{"order:placed"}, Fields: map[string]any{path: 1}}, listener); err == nil {
+			t.Errorf("Expected an error for the path %q", path)
+		}
+	}
+}
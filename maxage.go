@@ -0,0 +1,62 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// originTimeKey is the context key of the original publish time.
+type originTimeKey struct{}
+
+// WithOriginTime returns a context publishing events with t as their
+// Envelope.OriginTime, for events first published earlier elsewhere, such
+// as events received through a bridge or redelivered from a store.
+//
+// Example:
+//
+//	ctx := eventbus.WithOriginTime(context.Background(), message.PublishTime)
+//	bus.PublishContext(ctx, event)
+func WithOriginTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, originTimeKey{}, t)
+}
+
+// originTime returns the original publish time carried by ctx, or now.
+func originTime(ctx context.Context, now time.Time) time.Time {
+	if t, ok := ctx.Value(originTimeKey{}).(time.Time); ok && !t.IsZero() {
+		return t
+	}
+	return now
+}
+
+// WithMaxAge skips events whose Envelope.OriginTime is more than maxAge
+// before their delivery, so events replayed or redelivered after downtime
+// do not re-trigger time-sensitive side effects such as notifications.
+// Events published directly on the bus are as old as their queueing delay.
+// Skipped deliveries are dropped with DropExpired.
+//
+// Example:
+//
+//	// Do not page anyone for alerts that are more than a minute old
+//	bus.Subscribe("alert:raised", pageOnCall, eventbus.WithMaxAge(time.Minute))
+func WithMaxAge(maxAge time.Duration) SubscribeOption {
+	return func(config *subscribeConfig) {
+		config.maxAge = maxAge
+		config.options = append(config.options, "maxAge")
+	}
+}
+
+// expiring returns a listener that calls listener only with events at
+// most maxAge old, recording the others in drops.
+func expiring(listener ContextListener, maxAge time.Duration, drops *dropCounter) ContextListener {
+	if maxAge <= 0 {
+		return listener
+	}
+
+	return func(ctx context.Context, event Event) {
+		if envelope, ok := EnvelopeFromContext(ctx); ok && time.Since(envelope.OriginTime) > maxAge {
+			drops.record(DropExpired, event, 1)
+			return
+		}
+		listener(ctx, event)
+	}
+}
@@ -0,0 +1,96 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestWithMaxAge verifies that events published long ago are skipped
+func TestWithMaxAge(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	var fresh, all []string
+	bus.Subscribe("alert:raised", func(event Event) {
+		text, _ := Payload[string](event)
+		fresh = append(fresh, text)
+	}, WithMaxAge(time.Minute))
+	bus.Subscribe("alert:raised", func(event Event) {
+		text, _ := Payload[string](event)
+		all = append(all, text)
+	})
+
+	bus.Publish(Of("alert:raised", "now"))
+	bus.PublishContext(WithOriginTime(context.Background(), time.Now().Add(-time.Hour)), Of("alert:raised", "stale"))
+	bus.PublishContext(WithOriginTime(context.Background(), time.Now().Add(-time.Second)), Of("alert:raised", "recent"))
+
+	if len(fresh) != 2 || fresh[0] != "now" || fresh[1] != "recent" {
+		t.Errorf("Expected the stale alert to be skipped, got %q", fresh)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected 3 alerts without a max age, got %q", all)
+	}
+}
+
+// TestWithMaxAgeOnDrop verifies that skipped stale events are reported and counted as expired drops
+func TestWithMaxAgeOnDrop(t *testing.T) {
+	var drops dropRecorder
+	bus := New(WithOnDrop(drops.record))
+	defer bus.Close()
+	bus.Subscribe("alert:raised", func(event Event) {}, WithMaxAge(time.Minute))
+
+	bus.Publish(Of("alert:raised", "now"))
+	bus.PublishContext(WithOriginTime(context.Background(), time.Now().Add(-time.Hour)), Of("alert:raised", "stale"))
+
+	drops.mutex.Lock()
+	if len(drops.reasons) != 1 || drops.reasons[0] != DropExpired {
+		t.Errorf("Expected an expired drop, got %v", drops.reasons)
+	}
+	drops.mutex.Unlock()
+	if stats := bus.Stats(); stats.DroppedExpired != 1 || stats.Dropped != 0 {
+		t.Errorf("Expected 1 expired drop outside Dropped, got %+v", stats)
+	}
+}
+
+// TestOriginTime verifies that the origin time defaults to the publish time and survives replay and history
+func TestOriginTime(t *testing.T) {
+	store := NewMemoryStore()
+	bus := New(WithStore(store))
+	var envelope Envelope
+	bus.SubscribeContext("alert:raised", func(ctx context.Context, event Event) {
+		envelope, _ = EnvelopeFromContext(ctx)
+	})
+	bus.Publish(Of("alert:raised", "a"))
+	if !envelope.OriginTime.Equal(envelope.Time) {
+		t.Errorf("Expected the origin time %v to equal the time %v", envelope.OriginTime, envelope.Time)
+	}
+
+	origin := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	bus.PublishContext(WithOriginTime(context.Background(), origin), Of("alert:raised", "b"))
+	bus.Close()
+
+	var buf bytes.Buffer
+	if err := (&History{store: store}).Export(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	imported := NewMemoryStore()
+	if err := (&History{store: imported}).Import(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	replayBus := New()
+	defer replayBus.Close()
+	var origins []time.Time
+	replayBus.SubscribeContext("alert:raised", func(ctx context.Context, event Event) {
+		envelope, _ := EnvelopeFromContext(ctx)
+		origins = append(origins, envelope.OriginTime)
+	})
+	if err := ReplayUntil(imported, time.Now(), replayBus); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(origins) != 2 || !origins[1].Equal(origin) || time.Since(origins[0]) > time.Minute {
+		t.Errorf("Expected the recorded origin times, got %v", origins)
+	}
+}
@@ -0,0 +1,339 @@
+package eventbus
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// MemoryBudget bounds the memory kept by the last-value caches of a bus,
+// MemoryStore, and MemoryInboxStore, evicting the least recently used
+// entries once a limit is exceeded, so these features are safe to enable
+// on memory-constrained devices. One budget can be shared by several of
+// them, bounding their total. Sizes are estimates of the retained Go
+// values, not exact heap usage. It is safe for concurrent use.
+//
+// Example:
+//
+//	budget := eventbus.NewMemoryBudget(8 << 20)
+//	budget.LimitTopic("telemetry:*", 1<<20)
+//
+//	bus := eventbus.New(
+//	    eventbus.WithStore(eventbus.NewMemoryStoreWithBudget(budget)),
+//	    eventbus.WithLastValueCache("player:moved", byPlayer),
+//	    eventbus.WithMemoryBudget(budget),
+//	)
+type MemoryBudget struct {
+	mutex  sync.Mutex
+	total  int64
+	limits []topicLimit
+	used   int64
+	// lru orders every entry, most recently used first; topics orders
+	// the entries of each topic the same way.
+	lru          list.List
+	topics       map[EventType]*topicUsage
+	evictions    uint64
+	evictedBytes uint64
+}
+
+// topicLimit is a per-topic limit set with LimitTopic.
+type topicLimit struct {
+	pattern EventType
+	bytes   int64
+}
+
+// topicUsage is the memory of one topic.
+type topicUsage struct {
+	used      int64
+	evictions uint64
+	lru       list.List
+}
+
+// MemoryStats describes the memory accounted by a MemoryBudget.
+type MemoryStats struct {
+	// Limit is the total budget in bytes, 0 if unlimited.
+	Limit int64
+	// Used is the estimated size of the retained entries in bytes.
+	Used int64
+	// Entries is the number of retained entries.
+	Entries int
+	// Evictions is the number of entries evicted so far, and
+	// EvictedBytes their estimated size.
+	Evictions    uint64
+	EvictedBytes uint64
+	// Topics describes the memory of every topic with retained or
+	// evicted entries. Dedup records, which have no topic, are not
+	// included.
+	Topics map[EventType]TopicMemory
+}
+
+// TopicMemory describes the memory of one topic in MemoryStats.
+type TopicMemory struct {
+	// Limit is the limit set with LimitTopic, 0 if there is none.
+	Limit int64
+	// Used is the estimated size of the retained entries in bytes.
+	Used int64
+	// Evictions is the number of entries of the topic evicted so far.
+	Evictions uint64
+}
+
+// budgetEntry is one retained value charged to a budget.
+type budgetEntry struct {
+	owner budgetOwner
+	key   any
+	topic EventType
+	size  int64
+
+	global, local *list.Element
+	evicted       bool
+}
+
+// budgetOwner is a cache or store keeping entries charged to a budget.
+type budgetOwner interface {
+	// evict removes the given entries if they are still retained. It is
+	// called without any lock of the budget or other owners held.
+	evict(entries []*budgetEntry)
+}
+
+// NewMemoryBudget creates a budget of total bytes; 0 limits only the
+// topics given to LimitTopic.
+func NewMemoryBudget(total int64) *MemoryBudget {
+	return &MemoryBudget{total: total, topics: make(map[EventType]*topicUsage)}
+}
+
+// LimitTopic limits each topic matching pattern to bytes, evicting its
+// least recently used entries beyond. Patterns use "*" wildcards as in
+// WithTopicConfig, and the first matching pattern applies.
+func (b *MemoryBudget) LimitTopic(pattern EventType, bytes int64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.limits = append(b.limits, topicLimit{pattern: pattern, bytes: bytes})
+}
+
+// Stats returns the current usage and eviction counters.
+func (b *MemoryBudget) Stats() MemoryStats {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	stats := MemoryStats{
+		Limit:        b.total,
+		Used:         b.used,
+		Entries:      b.lru.Len(),
+		Evictions:    b.evictions,
+		EvictedBytes: b.evictedBytes,
+		Topics:       make(map[EventType]TopicMemory, len(b.topics)),
+	}
+	for topic, usage := range b.topics {
+		if topic != "" {
+			stats.Topics[topic] = TopicMemory{Limit: b.limit(topic), Used: usage.used, Evictions: usage.evictions}
+		}
+	}
+	return stats
+}
+
+// charge accounts entry as most recently used and returns the entries
+// evicted to stay within the limits, possibly including entry itself. The
+// caller must evict them once it has released its own lock. It does
+// nothing on a nil budget.
+func (b *MemoryBudget) charge(entry *budgetEntry) map[budgetOwner][]*budgetEntry {
+	if b == nil {
+		return nil
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	usage := b.usage(entry.topic)
+	entry.global = b.lru.PushFront(entry)
+	entry.local = usage.lru.PushFront(entry)
+	b.used += entry.size
+	usage.used += entry.size
+
+	var victims map[budgetOwner][]*budgetEntry
+	evict := func(victim *budgetEntry) {
+		b.remove(victim)
+		victim.evicted = true
+		b.evictions++
+		b.evictedBytes += uint64(victim.size)
+		b.topics[victim.topic].evictions++
+		if victims == nil {
+			victims = make(map[budgetOwner][]*budgetEntry)
+		}
+		victims[victim.owner] = append(victims[victim.owner], victim)
+	}
+	if limit := b.limit(entry.topic); limit > 0 {
+		for usage.used > limit {
+			evict(usage.lru.Back().Value.(*budgetEntry))
+		}
+	}
+	if b.total > 0 {
+		for b.used > b.total {
+			evict(b.lru.Back().Value.(*budgetEntry))
+		}
+	}
+	return victims
+}
+
+// touch marks entry as most recently used. It does nothing on a nil
+// budget or an evicted entry.
+func (b *MemoryBudget) touch(entry *budgetEntry) {
+	if b == nil || entry == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !entry.evicted {
+		b.lru.MoveToFront(entry.global)
+		b.topics[entry.topic].lru.MoveToFront(entry.local)
+	}
+}
+
+// release stops accounting entries their owner removed. It does nothing
+// on a nil budget and for evicted entries.
+func (b *MemoryBudget) release(entries ...*budgetEntry) {
+	if b == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, entry := range entries {
+		if entry != nil && !entry.evicted {
+			b.remove(entry)
+			entry.evicted = true
+		}
+	}
+}
+
+// remove unlinks entry and subtracts its size. The caller must hold
+// b.mutex.
+func (b *MemoryBudget) remove(entry *budgetEntry) {
+	usage := b.topics[entry.topic]
+	b.lru.Remove(entry.global)
+	usage.lru.Remove(entry.local)
+	b.used -= entry.size
+	usage.used -= entry.size
+}
+
+// usage returns the usage of topic, creating it on first use. The caller
+// must hold b.mutex.
+func (b *MemoryBudget) usage(topic EventType) *topicUsage {
+	usage, ok := b.topics[topic]
+	if !ok {
+		usage = &topicUsage{}
+		b.topics[topic] = usage
+	}
+	return usage
+}
+
+// limit returns the limit of topic, 0 if there is none. The caller must
+// hold b.mutex.
+func (b *MemoryBudget) limit(topic EventType) int64 {
+	if topic == "" {
+		return 0
+	}
+	for _, limit := range b.limits {
+		if matchPattern(limit.pattern, topic) {
+			return limit.bytes
+		}
+	}
+	return 0
+}
+
+// evictAll hands the victims of charge to their owners.
+func evictAll(victims map[budgetOwner][]*budgetEntry) {
+	for owner, entries := range victims {
+		owner.evict(entries)
+	}
+}
+
+// WithMemoryBudget charges the values of the last-value caches of the bus
+// to budget, evicting the least recently read or written ones beyond its
+// limits, so Latest stops reporting them. Pass the same budget to
+// NewMemoryStoreWithBudget and NewMemoryInboxStoreWithBudget to bound
+// them together. The usage is reported in Stats.Memory.
+//
+// Example:
+//
+//	budget := eventbus.NewMemoryBudget(4 << 20)
+//	bus := eventbus.New(
+//	    eventbus.WithLastValueCache("entity:moved", byEntity),
+//	    eventbus.WithMemoryBudget(budget),
+//	)
+func WithMemoryBudget(budget *MemoryBudget) Option {
+	return func(bus *eventBusImpl) {
+		bus.memory = budget
+	}
+}
+
+// timeType is the type of time.Time.
+var timeType = reflect.TypeFor[time.Time]()
+
+// sizeOf estimates the memory retained by v, following pointers, slices,
+// maps, and interfaces once each.
+func sizeOf(v any) int64 {
+	if v == nil {
+		return 0
+	}
+	value := reflect.ValueOf(v)
+	return int64(value.Type().Size()) + indirectSize(value, make(map[uintptr]bool))
+}
+
+// indirectSize estimates the memory value refers to beyond its own size.
+func indirectSize(value reflect.Value, seen map[uintptr]bool) int64 {
+	switch value.Kind() {
+	case reflect.String:
+		return int64(value.Len())
+	case reflect.Pointer:
+		if value.IsNil() || seen[value.Pointer()] {
+			return 0
+		}
+		seen[value.Pointer()] = true
+		return int64(value.Type().Elem().Size()) + indirectSize(value.Elem(), seen)
+	case reflect.Interface:
+		if value.IsNil() {
+			return 0
+		}
+		elem := value.Elem()
+		return int64(elem.Type().Size()) + indirectSize(elem, seen)
+	case reflect.Slice:
+		if value.IsNil() || seen[value.Pointer()] {
+			return 0
+		}
+		seen[value.Pointer()] = true
+		size := int64(value.Cap()) * int64(value.Type().Elem().Size())
+		for i := range value.Len() {
+			size += indirectSize(value.Index(i), seen)
+		}
+		return size
+	case reflect.Array:
+		var size int64
+		for i := range value.Len() {
+			size += indirectSize(value.Index(i), seen)
+		}
+		return size
+	case reflect.Struct:
+		if value.Type() == timeType {
+			// The location of a time is shared.
+			return 0
+		}
+		var size int64
+		for i := range value.NumField() {
+			size += indirectSize(value.Field(i), seen)
+		}
+		return size
+	case reflect.Map:
+		if value.IsNil() || seen[value.Pointer()] {
+			return 0
+		}
+		seen[value.Pointer()] = true
+		entry := int64(value.Type().Key().Size() + value.Type().Elem().Size())
+		size := int64(value.Len()) * entry
+		iter := value.MapRange()
+		for iter.Next() {
+			size += indirectSize(iter.Key(), seen) + indirectSize(iter.Value(), seen)
+		}
+		return size
+	default:
+		return 0
+	}
+}
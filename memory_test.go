@@ -0,0 +1,183 @@
+package eventbus
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// byPlayer keys health events by player.
+func byPlayer(event Event) any {
+	return event.(healthEvent).playerID
+}
+
+// TestMemoryBudgetLatest verifies that the least recently used cached
+// values are evicted beyond the total
+func TestMemoryBudgetLatest(t *testing.T) {
+	size := sizeOf(healthEvent{playerID: "p1", hp: 1})
+	budget := NewMemoryBudget(2 * size)
+	bus := New(WithLastValueCache("player:health", byPlayer), WithMemoryBudget(budget))
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 1})
+	bus.Publish(healthEvent{playerID: "p2", hp: 2})
+	bus.Latest("player:health", "p1")
+	bus.Publish(healthEvent{playerID: "p3", hp: 3})
+
+	if _, ok := bus.Latest("player:health", "p2"); ok {
+		t.Error("Expected p2 to be evicted")
+	}
+	for _, player := range []string{"p1", "p3"} {
+		if _, ok := bus.Latest("player:health", player); !ok {
+			t.Errorf("Expected %s to be cached", player)
+		}
+	}
+
+	stats := bus.Stats().Memory
+	if stats == nil {
+		t.Fatal("Expected memory stats")
+	}
+	if stats.Used != 2*size || stats.Entries != 2 || stats.Evictions != 1 || stats.EvictedBytes != uint64(size) {
+		t.Errorf("Expected 2 entries of %d bytes and 1 eviction, got %+v", size, stats)
+	}
+	if topic := stats.Topics["player:health"]; topic.Used != 2*size || topic.Evictions != 1 {
+		t.Errorf("Expected the topic to hold 2 entries after 1 eviction, got %+v", topic)
+	}
+}
+
+// TestMemoryBudgetClearLatest verifies that cleared values are no longer
+// charged
+func TestMemoryBudgetClearLatest(t *testing.T) {
+	budget := NewMemoryBudget(0)
+	bus := New(WithLastValueCache("player:health", byPlayer), WithMemoryBudget(budget))
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 1})
+	bus.Publish(healthEvent{playerID: "p1", hp: 2})
+	bus.Publish(healthEvent{playerID: "p2", hp: 3})
+	if used := budget.Stats().Entries; used != 2 {
+		t.Errorf("Expected 2 entries, got %d", used)
+	}
+	bus.ClearLatest("player:health")
+	if stats := budget.Stats(); stats.Used != 0 || stats.Entries != 0 {
+		t.Errorf("Expected nothing charged, got %+v", stats)
+	}
+}
+
+// TestMemoryBudgetStore verifies that per-topic limits evict the oldest
+// envelopes of the topic only
+func TestMemoryBudgetStore(t *testing.T) {
+	budget := NewMemoryBudget(0)
+	store := NewMemoryStoreWithBudget(budget)
+	envelope, _ := NewMemoryStore().Append(healthEvent{playerID: "p1", hp: 1})
+	budget.LimitTopic("player:*", 2*sizeOf(envelope))
+	bus := New(WithStore(store))
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 1})
+	bus.Publish(counterEvent{value: 1})
+	bus.Publish(healthEvent{playerID: "p1", hp: 2})
+	bus.Publish(healthEvent{playerID: "p1", hp: 3})
+
+	var sequences []uint64
+	store.Read(1, func(envelope Envelope) error {
+		sequences = append(sequences, envelope.Sequence)
+		return nil
+	})
+	if len(sequences) != 3 || sequences[0] != 2 || sequences[1] != 3 || sequences[2] != 4 {
+		t.Errorf("Expected sequences [2 3 4], got %v", sequences)
+	}
+
+	store.TruncateBefore(4)
+	if stats := budget.Stats(); stats.Entries != 1 {
+		t.Errorf("Expected 1 entry after truncating, got %d", stats.Entries)
+	}
+	if err := store.Restore(Envelope{Sequence: 5, Event: counterEvent{value: 2}}); err != nil {
+		t.Errorf("Expected the store to continue at 5, got %v", err)
+	}
+}
+
+// TestMemoryBudgetStoreCompaction verifies that evicted envelopes amid
+// live ones are skipped and compacted away
+func TestMemoryBudgetStoreCompaction(t *testing.T) {
+	budget := NewMemoryBudget(0)
+	budget.LimitTopic("noise:*", 1)
+	store := NewMemoryStoreWithBudget(budget)
+	for i := 0; i < 100; i++ {
+		store.Append(counterEvent{value: i})
+		store.Append(testEvent{eventType: "noise:tick"})
+	}
+
+	count := 0
+	store.Read(1, func(envelope Envelope) error {
+		if envelope.Sequence%2 == 0 {
+			t.Fatalf("Expected evicted envelope %d skipped", envelope.Sequence)
+		}
+		count++
+		return nil
+	})
+	if count != 100 {
+		t.Errorf("Expected 100 envelopes, got %d", count)
+	}
+	if len(store.envelopes) > 2*count {
+		t.Errorf("Expected evicted envelopes compacted, got %d kept for %d", len(store.envelopes), count)
+	}
+}
+
+// TestMemoryBudgetInbox verifies that finished inbox records are evicted
+// while records being processed are kept
+func TestMemoryBudgetInbox(t *testing.T) {
+	size := sizeOf(InboxRecord{ID: "a", Status: InboxDone})
+	store := NewMemoryInboxStoreWithBudget(NewMemoryBudget(size + 1))
+
+	store.Begin("a")
+	store.Complete("a")
+	store.Begin("b")
+	store.Complete("b")
+	store.Begin("c")
+
+	if _, ok, _ := store.Lookup("a"); ok {
+		t.Error("Expected a to be evicted")
+	}
+	if first, _ := store.Begin("a"); !first {
+		t.Error("Expected an evicted ID to be handled again")
+	}
+	if record, ok, _ := store.Lookup("c"); !ok || record.Status != InboxProcessing {
+		t.Errorf("Expected c to be processing, got %+v", record)
+	}
+}
+
+// TestMemoryBudgetPrometheus verifies that the memory counters are exported
+func TestMemoryBudgetPrometheus(t *testing.T) {
+	bus := New(WithLastValueCache("player:health", byPlayer), WithMemoryBudget(NewMemoryBudget(1)))
+	bus.Publish(healthEvent{playerID: "p1", hp: 1})
+
+	var out bytes.Buffer
+	bus.Stats().WritePrometheus(&out)
+	if !strings.Contains(out.String(), "eventbus_memory_evictions_total 1\n") {
+		t.Errorf("Expected 1 eviction in the output, got:\n%s", out.String())
+	}
+}
+
+// TestSizeOf verifies that sizes include referenced strings, slices, and maps
+func TestSizeOf(t *testing.T) {
+	type payload struct {
+		name  string
+		tags  []string
+		attrs map[string]int
+	}
+	empty := sizeOf(payload{})
+	full := sizeOf(payload{name: "abcd", tags: []string{"xy"}, attrs: map[string]int{"k": 1}})
+	if full <= empty+4+2+1 {
+		t.Errorf("Expected referenced data to be counted, got %d for %d empty", full, empty)
+	}
+	if sizeOf(nil) != 0 {
+		t.Errorf("Expected 0 for nil, got %d", sizeOf(nil))
+	}
+}
+
+// TestSizeOfTime verifies that the shared location of times is not counted
+func TestSizeOfTime(t *testing.T) {
+	if size := sizeOf(Envelope{Time: time.Now()}); size != int64(reflect.TypeFor[Envelope]().Size()) {
+		t.Errorf("Expected the size of an envelope, got %d", size)
+	}
+}
@@ -0,0 +1,109 @@
+package eventbus
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// muting holds the topics muted or soloed at runtime. The patterns are
+// replaced rather than modified, so publishers read them without locking.
+type muting struct {
+	state atomic.Pointer[muteState]
+	// suppressed counts the deliveries skipped, reported by Stats.
+	suppressed atomic.Uint64
+	mutex      sync.Mutex
+}
+
+// muteState is an immutable set of muted and soloed patterns.
+type muteState struct {
+	muted []EventType
+	solo  []EventType
+}
+
+// Mute suppresses the delivery of events whose type matches pattern,
+// which may contain "*" wildcards as in WithTopicConfig. Muted events are
+// still recorded in the store and counted in Stats.Muted. Muting is meant
+// for isolating noisy systems while debugging, for example from the debug
+// console, and can be undone with Unmute.
+//
+// Example:
+//
+//	bus.Mute("physics:*")
+//	defer bus.Unmute("physics:*")
+func (bus *eventBusImpl) Mute(pattern EventType) {
+	bus.muting.update(func(state *muteState) {
+		if !slices.Contains(state.muted, pattern) {
+			state.muted = append(state.muted, pattern)
+		}
+	})
+}
+
+// Unmute removes a pattern added with Mute. Other patterns muting the
+// same topics stay in effect.
+func (bus *eventBusImpl) Unmute(pattern EventType) {
+	bus.muting.update(func(state *muteState) {
+		state.muted = slices.DeleteFunc(state.muted, func(p EventType) bool { return p == pattern })
+	})
+}
+
+// Solo suppresses the delivery of every event whose type does not match
+// a soloed pattern. Several patterns can be soloed at once. The bus's own
+// "eventbus:*" events are never suppressed by Solo, and Mute takes
+// precedence over it. Suppressed deliveries are counted in Stats.Muted.
+//
+// Example:
+//
+//	bus.Solo("audio:*")
+//	defer bus.Unsolo("audio:*")
+func (bus *eventBusImpl) Solo(pattern EventType) {
+	bus.muting.update(func(state *muteState) {
+		if !slices.Contains(state.solo, pattern) {
+			state.solo = append(state.solo, pattern)
+		}
+	})
+}
+
+// Unsolo removes a pattern added with Solo. Once no pattern is soloed,
+// every topic that is not muted is delivered again.
+func (bus *eventBusImpl) Unsolo(pattern EventType) {
+	bus.muting.update(func(state *muteState) {
+		state.solo = slices.DeleteFunc(state.solo, func(p EventType) bool { return p == pattern })
+	})
+}
+
+// update replaces the state with a copy changed by fn.
+func (m *muting) update(fn func(state *muteState)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state := &muteState{}
+	if current := m.state.Load(); current != nil {
+		state.muted = slices.Clone(current.muted)
+		state.solo = slices.Clone(current.solo)
+	}
+	fn(state)
+	m.state.Store(state)
+}
+
+// suppresses reports whether delivery of eventType is suppressed.
+func (m *muting) suppresses(eventType EventType) bool {
+	state := m.state.Load()
+	if state == nil {
+		return false
+	}
+	for _, pattern := range state.muted {
+		if matchPattern(pattern, eventType) {
+			return true
+		}
+	}
+	if len(state.solo) == 0 || matchPattern("eventbus:*", eventType) {
+		return false
+	}
+	for _, pattern := range state.solo {
+		if matchPattern(pattern, eventType) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestMute verifies that muted topics are not delivered but counted
+func TestMute(t *testing.T) {
+	store := NewMemoryStore()
+	bus := New(WithStore(store))
+	moved, died := 0, 0
+	bus.Subscribe("player:moved", func(event Event) { moved++ })
+	bus.Subscribe("player:moved", func(event Event) { moved++ })
+	bus.Subscribe("player:died", func(event Event) { died++ })
+
+	bus.Mute("player:mov*")
+	bus.Publish(testEvent{eventType: "player:moved"})
+	bus.Publish(testEvent{eventType: "player:died"})
+
+	if moved != 0 || died != 1 {
+		t.Errorf("Expected only player:died delivered, got moved %d, died %d", moved, died)
+	}
+	if stats := bus.Stats(); stats.Muted != 2 {
+		t.Errorf("Expected 2 muted deliveries, got %d", stats.Muted)
+	}
+	if len(store.envelopes) != 2 {
+		t.Errorf("Expected muted events to be recorded, got %d envelopes", len(store.envelopes))
+	}
+
+	bus.Unmute("player:mov*")
+	bus.Publish(testEvent{eventType: "player:moved"})
+	if moved != 2 {
+		t.Errorf("Expected delivery after unmuting, got %d", moved)
+	}
+}
+
+// TestSolo verifies that only soloed topics and bus events are delivered
+func TestSolo(t *testing.T) {
+	bus := New()
+	delivered := map[EventType]int{}
+	for _, eventType := range []EventType{"audio:played", "audio:stopped", "physics:step", "eventbus:stats"} {
+		bus.Subscribe(eventType, func(event Event) { delivered[event.GetType()]++ })
+	}
+
+	bus.Solo("audio:*")
+	bus.Mute("audio:stopped")
+	for _, eventType := range []EventType{"audio:played", "audio:stopped", "physics:step", "eventbus:stats"} {
+		bus.Publish(testEvent{eventType: eventType})
+	}
+
+	if delivered["audio:played"] != 1 || delivered["eventbus:stats"] != 1 {
+		t.Errorf("Expected audio:played and eventbus:stats delivered, got %v", delivered)
+	}
+	if delivered["audio:stopped"] != 0 || delivered["physics:step"] != 0 {
+		t.Errorf("Expected audio:stopped and physics:step suppressed, got %v", delivered)
+	}
+
+	bus.Unsolo("audio:*")
+	bus.Publish(testEvent{eventType: "physics:step"})
+	if delivered["physics:step"] != 1 {
+		t.Errorf("Expected delivery after unsolo, got %v", delivered)
+	}
+}
+
+// TestMutePublishAndWait verifies that waiting on a muted topic reports no subscribers
+func TestMutePublishAndWait(t *testing.T) {
+	bus := New(WithAsync(2, 8))
+	defer bus.Close()
+	bus.Subscribe("player:moved", func(event Event) {})
+
+	bus.Mute("player:moved")
+	err := bus.PublishAndWait(context.Background(), testEvent{eventType: "player:moved"})
+	if !errors.Is(err, ErrNoSubscribers) {
+		t.Errorf("Expected ErrNoSubscribers, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures an event bus at construction time.
+// Options are passed to New.
+type Option func(*eventBusImpl)
+
+// SubscribeOption configures a single subscription.
+// Options are passed as trailing arguments to Subscribe.
+type SubscribeOption func(*subscribeConfig)
+
+// subscribeConfig collects the effect of all options given to Subscribe.
+type subscribeConfig struct {
+	// filters decide whether an event reaches the listener.
+	// All filters must accept the event for it to be delivered.
+	filters []func(ctx context.Context, event Event) bool
+	// until cancels the subscription after delivering an event
+	// any of them accepts.
+	until []func(Event) bool
+	// stage is the delivery stage of the listener, if staged is set.
+	stage  int
+	staged bool
+	// name labels the listener; after and before order it relative
+	// to other labels.
+	name   string
+	after  []string
+	before []string
+	// maxConcurrency limits the invocations in flight, if not 0.
+	maxConcurrency int
+	// maxAge skips events published longer ago, if not 0.
+	maxAge time.Duration
+	// priority orders the listener on OrderPriority topics.
+	priority int
+	// owner ends the subscription when closed, if not nil.
+	owner <-chan struct{}
+	// options names the applied options for Snapshot.
+	options []string
+}
+
+// newSubscribeConfig applies opts to an empty configuration.
+func newSubscribeConfig(opts []SubscribeOption) *subscribeConfig {
+	config := &subscribeConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// wrap returns a listener that applies the configuration around listener.
+func (config *subscribeConfig) wrap(listener ContextListener) ContextListener {
+	if len(config.filters) == 0 {
+		return listener
+	}
+
+	filters := config.filters
+	return func(ctx context.Context, event Event) {
+		for _, filter := range filters {
+			if !filter(ctx, event) {
+				return
+			}
+		}
+		listener(ctx, event)
+	}
+}
@@ -0,0 +1,142 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrDependencyCycle is the panic value of Subscribe when After and Before
+// constraints, together with delivery stages, cannot all be satisfied.
+var ErrDependencyCycle = errors.New("eventbus: dependency cycle")
+
+// WithName labels the listener for diagnostics and ordering. The label is
+// reported as the Handler of HandlerError values, receipt deliveries, and
+// snapshots, and names the listener in After and Before constraints.
+// Several listeners may share a label.
+//
+// Example:
+//
+//	bus.Subscribe("player:jumped", audio.onPlayerJumped, eventbus.WithName("AudioSystem.onPlayerJumped"))
+func WithName(name string) SubscribeOption {
+	return func(config *subscribeConfig) {
+		config.name = name
+	}
+}
+
+// After delivers events to the listener only after every listener labeled
+// with one of labels. Labels without listeners are ignored until such a
+// listener subscribes, so packages can subscribe in any order.
+//
+// Subscribe panics with an error matching ErrDependencyCycle if the
+// constraint contradicts existing ones or the listeners' stages.
+//
+// Example:
+//
+//	bus.Subscribe("frame:tick", camera.Follow, eventbus.After("physics"), eventbus.Before("render"))
+func After(labels ...string) SubscribeOption {
+	return func(config *subscribeConfig) {
+		config.after = append(config.after, labels...)
+		config.options = append(config.options, "after")
+	}
+}
+
+// Before delivers events to the listener before every listener labeled
+// with one of labels. See After.
+func Before(labels ...string) SubscribeOption {
+	return func(config *subscribeConfig) {
+		config.before = append(config.before, labels...)
+		config.options = append(config.options, "before")
+	}
+}
+
+// hasDependencies reports whether the subscription declared After or Before.
+func (s *Subscription) hasDependencies() bool {
+	return len(s.after) > 0 || len(s.before) > 0
+}
+
+// runsBefore reports whether a must be delivered before b.
+func runsBefore(a, b *Subscription) bool {
+	if a == b {
+		return false
+	}
+	return b.name != "" && slices.Contains(a.before, b.name) ||
+		a.name != "" && slices.Contains(b.after, a.name)
+}
+
+// orderListeners returns listeners sorted for delivery: listeners without
+// a stage in an order satisfying their dependencies, keeping the given
+// order where possible, followed by staged listeners by stage. It returns an
+// error matching ErrDependencyCycle if no such order exists.
+func orderListeners(listeners []*Subscription) ([]*Subscription, error) {
+	var unstaged, staged []*Subscription
+	for _, sub := range listeners {
+		if sub.staged {
+			staged = append(staged, sub)
+		} else {
+			unstaged = append(unstaged, sub)
+		}
+	}
+	slices.SortStableFunc(staged, func(a, b *Subscription) int {
+		return a.stage - b.stage
+	})
+
+	// Staged listeners run after unstaged ones and in stage order, so
+	// dependencies pointing the other way can never be satisfied.
+	for _, a := range staged {
+		for _, b := range listeners {
+			if !runsBefore(a, b) {
+				continue
+			}
+			if !b.staged {
+				return nil, dependencyError(a, b, "staged listeners run after unstaged ones")
+			}
+			if a.stage >= b.stage {
+				return nil, dependencyError(a, b, "its stage does not run earlier")
+			}
+		}
+	}
+
+	ordered := make([]*Subscription, 0, len(listeners))
+	done := make(map[*Subscription]bool, len(unstaged))
+	for len(ordered) < len(unstaged) {
+		// Pick the earliest registered listener whose predecessors are done.
+		next := slices.IndexFunc(unstaged, func(candidate *Subscription) bool {
+			return !done[candidate] && !slices.ContainsFunc(unstaged, func(other *Subscription) bool {
+				return !done[other] && runsBefore(other, candidate)
+			})
+		})
+		if next < 0 {
+			return nil, cycleError(unstaged, done)
+		}
+		done[unstaged[next]] = true
+		ordered = append(ordered, unstaged[next])
+	}
+	return append(ordered, staged...), nil
+}
+
+// dependencyError describes a constraint that first must run before then
+// which cannot be satisfied.
+func dependencyError(first, then *Subscription, reason string) error {
+	return fmt.Errorf("%w: %s must run before %s, but %s", ErrDependencyCycle, label(first), label(then), reason)
+}
+
+// cycleError describes the listeners left over when sorting got stuck.
+func cycleError(listeners []*Subscription, done map[*Subscription]bool) error {
+	var names []string
+	for _, sub := range listeners {
+		if !done[sub] {
+			names = append(names, label(sub))
+		}
+	}
+	return fmt.Errorf("%w between %s", ErrDependencyCycle, strings.Join(names, ", "))
+}
+
+// label returns the name of sub, or a placeholder for unnamed listeners.
+func label(sub *Subscription) string {
+	if sub.name == "" {
+		return "unnamed listener"
+	}
+	return sub.name
+}
@@ -0,0 +1,144 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// recordingListener appends name to order when called
+func recordingListener(order *[]string, name string) EventListener {
+	return func(event Event) {
+		*order = append(*order, name)
+	}
+}
+
+// TestDependencyOrdering verifies that After and Before reorder listeners
+func TestDependencyOrdering(t *testing.T) {
+	bus := New()
+	var order []string
+
+	bus.Subscribe("frame:tick", recordingListener(&order, "render"), WithName("render"))
+	bus.Subscribe("frame:tick", recordingListener(&order, "camera"), WithName("camera"), After("physics"), Before("render"))
+	bus.Subscribe("frame:tick", recordingListener(&order, "physics"), WithName("physics"))
+	bus.Subscribe("frame:tick", recordingListener(&order, "input"), Before("physics"))
+
+	bus.Publish(testEvent{eventType: "frame:tick"})
+
+	if strings.Join(order, ",") != "input,physics,camera,render" {
+		t.Errorf("Expected input,physics,camera,render, got %v", order)
+	}
+}
+
+// TestDependencySharedLabel verifies that a label can name several listeners
+func TestDependencySharedLabel(t *testing.T) {
+	bus := New()
+	var order []string
+
+	bus.Subscribe("frame:tick", recordingListener(&order, "hud"), After("world"))
+	bus.Subscribe("frame:tick", recordingListener(&order, "terrain"), WithName("world"))
+	bus.Subscribe("frame:tick", recordingListener(&order, "sky"), WithName("world"))
+
+	bus.Publish(testEvent{eventType: "frame:tick"})
+
+	if strings.Join(order, ",") != "terrain,sky,hud" {
+		t.Errorf("Expected terrain,sky,hud, got %v", order)
+	}
+}
+
+// TestDependencyCycle verifies that cycles are rejected at subscribe time
+func TestDependencyCycle(t *testing.T) {
+	bus := New()
+	bus.Subscribe("frame:tick", func(event Event) {}, WithName("physics"), After("render"))
+
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.Is(err, ErrDependencyCycle) {
+			t.Fatalf("Expected an ErrDependencyCycle panic, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "physics") || !strings.Contains(err.Error(), "render") {
+			t.Errorf("Expected the cycle to name both listeners, got %v", err)
+		}
+		if len(bus.Snapshot().Subscriptions()) != 1 {
+			t.Error("Expected the rejected subscription not to be registered")
+		}
+	}()
+	bus.Subscribe("frame:tick", func(event Event) {}, WithName("render"), After("physics"))
+}
+
+// TestDependencyStageConflict verifies that constraints contradicting stages are rejected
+func TestDependencyStageConflict(t *testing.T) {
+	bus := New()
+	bus.Subscribe("frame:tick", func(event Event) {}, WithName("render"), WithStage(1))
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrDependencyCycle) {
+			t.Errorf("Expected an ErrDependencyCycle panic, got %v", err)
+		}
+	}()
+	bus.Subscribe("frame:tick", func(event Event) {}, WithName("physics"), WithStage(2), Before("render"))
+}
+
+// TestDependencyAfterCancel verifies that ordering survives removing listeners
+func TestDependencyAfterCancel(t *testing.T) {
+	bus := New()
+	var order []string
+
+	bus.Subscribe("frame:tick", recordingListener(&order, "b"), After("a"))
+	sub := bus.Subscribe("frame:tick", recordingListener(&order, "a"), WithName("a"))
+	bus.Subscribe("frame:tick", recordingListener(&order, "c"))
+	sub.Cancel()
+	bus.Subscribe("frame:tick", recordingListener(&order, "a2"), WithName("a"))
+
+	bus.Publish(testEvent{eventType: "frame:tick"})
+
+	if strings.Join(order, ",") != "c,a2,b" {
+		t.Errorf("Expected c,a2,b, got %v", order)
+	}
+}
+
+// TestNamedHandlerDiagnostics verifies that the WithName label is reported in
+// handler errors, receipts, and snapshots
+func TestNamedHandlerDiagnostics(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	sub := bus.Subscribe("player:jumped", func(event Event) {
+		panic("out of voices")
+	}, WithName("AudioSystem.onPlayerJumped"))
+	bus.Subscribe("player:jumped", func(event Event) {})
+
+	if sub.Name() != "AudioSystem.onPlayerJumped" {
+		t.Errorf("Expected name AudioSystem.onPlayerJumped, got %q", sub.Name())
+	}
+
+	receipt, err := bus.PublishDetailed(context.Background(), testEvent{eventType: "player:jumped"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	err = receipt.Err()
+	var handlerErr *HandlerError
+	if !errors.As(err, &handlerErr) {
+		t.Fatalf("Expected a HandlerError, got %v", err)
+	}
+	if handlerErr.Handler != "AudioSystem.onPlayerJumped" {
+		t.Errorf("Expected handler AudioSystem.onPlayerJumped, got %q", handlerErr.Handler)
+	}
+	if !strings.Contains(err.Error(), "AudioSystem.onPlayerJumped") {
+		t.Errorf("Expected the error to name the handler, got %q", err.Error())
+	}
+
+	deliveries := receipt.Deliveries
+	if len(deliveries) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", len(deliveries))
+	}
+	if deliveries[0].Handler != "AudioSystem.onPlayerJumped" || deliveries[1].Handler != "" {
+		t.Errorf("Expected handlers AudioSystem.onPlayerJumped and \"\", got %q and %q", deliveries[0].Handler, deliveries[1].Handler)
+	}
+
+	infos := bus.Snapshot().Subscriptions()
+	if len(infos) != 2 || infos[0].Handler != "AudioSystem.onPlayerJumped" {
+		t.Errorf("Expected the snapshot to name the handler, got %+v", infos)
+	}
+}
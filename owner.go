@@ -0,0 +1,198 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Owner is an object whose lifetime bounds subscriptions made with
+// WithOwner. Its Done channel is closed when it is destroyed; a
+// context.Context is an Owner.
+type Owner interface {
+	Done() <-chan struct{}
+}
+
+// WithOwner binds the subscription to owner: it stops receiving events as
+// soon as owner's Done channel is closed and is cancelled shortly after,
+// so destroyed game entities need no explicit Cancel calls in their
+// destructors. The owners of a bus are watched by a shared goroutine, so
+// owned subscriptions cost no goroutine each. A later WithOwner replaces
+// an earlier one. See
+// SubscribeWeak for owners that are simply garbage collected.
+//
+// Example:
+//
+//	type Enemy struct {
+//	    done chan struct{}
+//	}
+//
+//	func (e *Enemy) Done() <-chan struct{} { return e.done }
+//	func (e *Enemy) Destroy()              { close(e.done) }
+//
+//	bus.Subscribe("player:moved", enemy.chase, eventbus.WithOwner(enemy))
+func WithOwner(owner Owner) SubscribeOption {
+	done := owner.Done()
+	return func(config *subscribeConfig) {
+		config.owner = done
+		config.options = append(config.options, "owner")
+	}
+}
+
+// owned returns a listener that calls listener until done is closed.
+func owned(listener ContextListener, done <-chan struct{}) ContextListener {
+	if done == nil {
+		return listener
+	}
+
+	return func(ctx context.Context, event Event) {
+		select {
+		case <-done:
+		default:
+			listener(ctx, event)
+		}
+	}
+}
+
+// ownersPerWatcher is the number of owners one goroutine waits for.
+// reflect.Select takes at most 65536 cases, two of which are the stopping
+// and wake channels.
+const ownersPerWatcher = 1<<16 - 2
+
+// ownerWatch cancels subscriptions whose owner is done, waiting for the
+// owners of a bus from one goroutine per ownersPerWatcher owners rather
+// than one per subscription, and stops the goroutines on Close.
+type ownerWatch struct {
+	mutex    sync.Mutex
+	watchers []*ownerWatcher
+	stopping chan struct{}
+	stopped  bool
+	running  sync.WaitGroup
+}
+
+// ownerWatcher is a goroutine of ownerWatch and the owners it waits for,
+// guarded by the mutex of ownerWatch.
+type ownerWatcher struct {
+	// owners maps the Done channel of every owner to its subscriptions.
+	owners map[<-chan struct{}]map[*Subscription]struct{}
+	// wake makes the goroutine wait for the current owners.
+	wake chan struct{}
+}
+
+// watch cancels sub once done is closed, unless the bus is closed first.
+func (w *ownerWatch) watch(bus *eventBusImpl, sub *Subscription, done <-chan struct{}) {
+	if done == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.stopped {
+		return
+	}
+
+	watcher := w.watcher(bus, done)
+	if watcher.owners[done] == nil {
+		watcher.owners[done] = make(map[*Subscription]struct{})
+		watcher.signal()
+	}
+	watcher.owners[done][sub] = struct{}{}
+	context.AfterFunc(sub.ctx, func() {
+		w.forget(watcher, sub, done)
+	})
+}
+
+// watcher returns the watcher of done, or one with room for it, starting
+// a new one if all are full. w.mutex must be held.
+func (w *ownerWatch) watcher(bus *eventBusImpl, done <-chan struct{}) *ownerWatcher {
+	for _, watcher := range w.watchers {
+		if _, ok := watcher.owners[done]; ok || len(watcher.owners) < ownersPerWatcher {
+			return watcher
+		}
+	}
+
+	if w.stopping == nil {
+		w.stopping = make(chan struct{})
+	}
+	watcher := &ownerWatcher{
+		owners: make(map[<-chan struct{}]map[*Subscription]struct{}),
+		wake:   make(chan struct{}, 1),
+	}
+	w.watchers = append(w.watchers, watcher)
+	w.running.Add(1)
+	release := bus.audit.track("goroutine", "owner watcher")
+	go w.run(watcher, release)
+	return watcher
+}
+
+// run cancels the subscriptions of every owner of watcher that is done,
+// until the watch stops.
+func (w *ownerWatch) run(watcher *ownerWatcher, release func()) {
+	defer w.running.Done()
+	defer release()
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(w.stopping)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(watcher.wake)},
+	}
+	var owners []<-chan struct{}
+	for {
+		cases, owners = cases[:2], owners[:0]
+		w.mutex.Lock()
+		for done := range watcher.owners {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)})
+			owners = append(owners, done)
+		}
+		w.mutex.Unlock()
+
+		chosen, _, _ := reflect.Select(cases)
+		switch chosen {
+		case 0:
+			return
+		case 1:
+			continue
+		}
+		done := owners[chosen-2]
+		w.mutex.Lock()
+		subs := watcher.owners[done]
+		delete(watcher.owners, done)
+		w.mutex.Unlock()
+		for sub := range subs {
+			sub.Cancel()
+		}
+	}
+}
+
+// forget stops watching sub after it was cancelled.
+func (w *ownerWatch) forget(watcher *ownerWatcher, sub *Subscription, done <-chan struct{}) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	subs := watcher.owners[done]
+	if subs == nil {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(watcher.owners, done)
+		watcher.signal()
+	}
+}
+
+// signal wakes the goroutine of watcher without blocking.
+func (watcher *ownerWatcher) signal() {
+	select {
+	case watcher.wake <- struct{}{}:
+	default:
+	}
+}
+
+// stop ends the goroutines and waits for them to exit.
+func (w *ownerWatch) stop() {
+	w.mutex.Lock()
+	w.stopped = true
+	if w.stopping != nil {
+		close(w.stopping)
+	}
+	w.mutex.Unlock()
+	w.running.Wait()
+}
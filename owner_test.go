@@ -0,0 +1,96 @@
+package eventbus
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// entity is an Owner destroyed by closing done.
+type entity struct {
+	done chan struct{}
+}
+
+func (e *entity) Done() <-chan struct{} {
+	return e.done
+}
+
+// TestWithOwner verifies that a subscription ends when its owner is done
+func TestWithOwner(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	owner := &entity{done: make(chan struct{})}
+	count := 0
+	sub := bus.Subscribe("player:health", func(event Event) { count++ }, WithOwner(owner))
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	close(owner.done)
+	bus.Publish(healthEvent{playerID: "p1", hp: 5})
+	if count != 1 {
+		t.Errorf("Expected 1 delivery before the owner was done, got %d", count)
+	}
+
+	select {
+	case <-sub.ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the subscription to be cancelled")
+	}
+	if stats := bus.Stats(); stats.Subscriptions != 0 {
+		t.Errorf("Expected 0 subscriptions, got %d", stats.Subscriptions)
+	}
+}
+
+// TestWithOwnerContext verifies that a context can own a subscription and
+// that Close stops watching owners
+func TestWithOwnerContext(t *testing.T) {
+	var leaks []AuditResource
+	bus := New(WithLeakAudit(time.Second, func(resources []AuditResource) { leaks = resources }))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := bus.Subscribe("player:health", func(event Event) {}, WithOwner(ctx))
+	bus.Close()
+	if len(leaks) != 0 {
+		t.Errorf("Expected no leaked goroutines, got %v", leaks)
+	}
+	if sub.ctx.Err() != nil {
+		t.Error("Expected the subscription to outlive the watch")
+	}
+}
+
+// TestWithOwnerSharedWatcher verifies that owned subscriptions share one
+// watching goroutine
+func TestWithOwnerSharedWatcher(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	owners := make([]*entity, 500)
+	for i := range owners {
+		owners[i] = &entity{done: make(chan struct{})}
+	}
+
+	bus.Subscribe("player:health", func(event Event) {}, WithOwner(owners[0]))
+	before := runtime.NumGoroutine()
+	subs := make([]*Subscription, len(owners))
+	for i, owner := range owners {
+		subs[i] = bus.Subscribe("player:health", func(event Event) {}, WithOwner(owner))
+	}
+	if after := runtime.NumGoroutine(); after-before > 5 {
+		t.Errorf("Expected a flat goroutine count, got %d goroutines after %d", after, before)
+	}
+
+	subs[1].Cancel()
+	for _, owner := range owners[2:] {
+		close(owner.done)
+	}
+	for _, sub := range subs[2:] {
+		select {
+		case <-sub.ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("Expected the subscription to be cancelled")
+		}
+	}
+	if stats := bus.Stats(); stats.Subscriptions != 2 {
+		t.Errorf("Expected 2 subscriptions, got %d", stats.Subscriptions)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build go1.24
+
+package eventbus
+
+import (
+	"runtime"
+	"weak"
+)
+
+// SubscribeWeak subscribes listener to eventType on behalf of owner
+// without keeping owner reachable: the subscription is cancelled once
+// owner is garbage collected, so entities dropped without a destructor
+// stop receiving events. listener receives owner with each event and must
+// not reference it otherwise, or owner is never collected; a method
+// expression such as (*Enemy).OnMoved is a good fit.
+//
+// Events published after owner became unreachable but before the
+// collector ran are not delivered. It requires Go 1.24 or later.
+//
+// Example:
+//
+//	eventbus.SubscribeWeak(bus, "player:moved", enemy, (*Enemy).OnMoved)
+func SubscribeWeak[T any](bus EventBus, eventType EventType, owner *T, listener func(owner *T, event Event), opts ...SubscribeOption) *Subscription {
+	ref := weak.Make(owner)
+	sub := bus.Subscribe(eventType, func(event Event) {
+		if owner := ref.Value(); owner != nil {
+			listener(owner, event)
+		}
+	}, opts...)
+	runtime.AddCleanup(owner, (*Subscription).Cancel, sub)
+	return sub
+}
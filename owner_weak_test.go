@@ -0,0 +1,45 @@
+//go:build go1.24
+
+package eventbus
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// enemy is an owner for SubscribeWeak.
+type enemy struct {
+	hits int
+}
+
+func (e *enemy) onHealth(event Event) {
+	e.hits++
+}
+
+// TestSubscribeWeak verifies that a weak subscription delivers to its
+// owner and is cancelled once the owner is collected
+func TestSubscribeWeak(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	owner := &enemy{}
+	sub := SubscribeWeak(bus, "player:health", owner, (*enemy).onHealth)
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	if owner.hits != 1 {
+		t.Errorf("Expected 1 delivery to the owner, got %d", owner.hits)
+	}
+
+	owner = nil // drop the only reference
+	deadline := time.Now().Add(5 * time.Second)
+	for sub.ctx.Err() == nil && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if sub.ctx.Err() == nil {
+		t.Fatal("Expected the subscription to be cancelled after the owner was collected")
+	}
+	if stats := bus.Stats(); stats.Subscriptions != 0 {
+		t.Errorf("Expected 0 subscriptions, got %d", stats.Subscriptions)
+	}
+}
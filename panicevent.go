@@ -0,0 +1,120 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// HandlerPanickedType is the event type of the events published for
+// recovered listener panics.
+const HandlerPanickedType EventType = "eventbus:panic"
+
+// HandlerPanicked is published when the bus recovers a panicking listener
+// and panic events are enabled with WithPanicEvents.
+type HandlerPanicked struct {
+	// EventType is the type of the event being handled.
+	EventType EventType
+	// EventID is the bus sequence of the event, see Envelope.BusSequence.
+	EventID uint64
+	// Handler is the label of the listener, if it has one.
+	Handler string
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack string
+}
+
+// GetType returns HandlerPanickedType.
+func (p HandlerPanicked) GetType() EventType {
+	return HandlerPanickedType
+}
+
+// WithPanicEvents publishes a HandlerPanicked event for every listener
+// panic the bus recovers, so a crash reporting subscriber sees them all
+// in one place instead of only the PublishAndWait callers. The bus
+// recovers the panics of asynchronous and dispatched deliveries and of
+// PublishAndWait and PublishDetailed; the panics of synchronous Publish
+// calls still propagate to the publisher.
+//
+// The events are published on a separate goroutine, since the panic may
+// be recovered while the bus is locked, so they can arrive after later
+// events. Close waits for these goroutines, but like any publish, the
+// events not yet published when Close starts are rejected. Panics of HandlerPanicked listeners, and
+// errors publishing the events, are reported to Errors and Hooks.OnError
+// instead of being published again.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithAsync(4, 256), eventbus.WithPanicEvents())
+//	bus.Subscribe(eventbus.HandlerPanickedType, func(event eventbus.Event) {
+//	    crash := event.(eventbus.HandlerPanicked)
+//	    reporter.Capture(crash.Handler, crash.EventType, crash.Value, crash.Stack)
+//	})
+func WithPanicEvents() Option {
+	return func(bus *eventBusImpl) {
+		bus.panicEvents = true
+	}
+}
+
+// reportPanic publishes a HandlerPanicked event for a panic recovered
+// from listener, if panic events are enabled.
+func (bus *eventBusImpl) reportPanic(ctx context.Context, listener *Subscription, event Event, err *PanicError) {
+	if !bus.panicEvents || event.GetType() == HandlerPanickedType {
+		return
+	}
+	report := HandlerPanicked{
+		EventType: event.GetType(),
+		Handler:   listener.name,
+		Value:     err.Value,
+		Stack:     string(err.Stack),
+	}
+	if envelope, ok := ctx.Value(envelopeKey{}).(*Envelope); ok {
+		report.EventID = envelope.BusSequence
+	}
+	if !bus.panicReports.add(&bus.sending) {
+		return
+	}
+	go func() {
+		defer bus.sending.Done()
+		defer func() {
+			// Synchronous HandlerPanicked listeners would otherwise panic
+			// on a goroutine nobody recovers.
+			if r := recover(); r != nil {
+				bus.reportError(context.Background(), report, "", newPanicError(r))
+			}
+		}()
+		if err := bus.publish(context.Background(), report, nil); err != nil && !errors.Is(err, ErrBusClosed) {
+			bus.reportError(ctx, event, listener.name, fmt.Errorf("eventbus: publishing %s: %w", HandlerPanickedType, err))
+		}
+	}()
+}
+
+// panicReports admits the goroutines publishing HandlerPanicked events to
+// the sending group of the bus until shutdown closes it. Reports can be
+// made while the bus mutex is held, so they cannot check bus.closed.
+type panicReports struct {
+	mutex  sync.Mutex
+	closed bool
+}
+
+// add adds a goroutine to sending and reports whether it did, which it
+// does not once close was called.
+func (r *panicReports) add(sending *sync.WaitGroup) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		return false
+	}
+	sending.Add(1)
+	return true
+}
+
+// close stops admitting goroutines; it must be called before waiting for
+// sending.
+func (r *panicReports) close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.closed = true
+}
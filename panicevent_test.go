@@ -0,0 +1,170 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestWithPanicEvents verifies that recovered panics are published with
+// the handler, event type, value, and stack
+func TestWithPanicEvents(t *testing.T) {
+	bus := New(WithAsync(2, 16), WithPanicEvents())
+	defer bus.Close()
+	reports := make(chan HandlerPanicked, 1)
+
+	bus.Subscribe(HandlerPanickedType, func(event Event) {
+		reports <- event.(HandlerPanicked)
+	})
+	bus.Subscribe("player:health", func(event Event) {
+		panic("boom")
+	}, WithName("hud"))
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+
+	select {
+	case report := <-reports:
+		if report.EventType != "player:health" || report.Handler != "hud" || report.Value != "boom" {
+			t.Errorf("Expected a hud panic on player:health, got %+v", report)
+		}
+		if report.EventID == 0 || len(report.Stack) == 0 {
+			t.Errorf("Expected an event ID and a stack, got %d and %q", report.EventID, report.Stack)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a HandlerPanicked event")
+	}
+}
+
+// TestWithPanicEventsPublishAndWait verifies that panics recovered for
+// PublishAndWait on synchronous topics are published too
+func TestWithPanicEventsPublishAndWait(t *testing.T) {
+	bus := New(WithPanicEvents())
+	defer bus.Close()
+	reports := make(chan HandlerPanicked, 1)
+
+	bus.Subscribe(HandlerPanickedType, func(event Event) {
+		reports <- event.(HandlerPanicked)
+	})
+	bus.Subscribe("player:health", func(event Event) {
+		panic(errors.New("boom"))
+	})
+
+	err := bus.PublishAndWait(context.Background(), healthEvent{playerID: "p1", hp: 10})
+	if !errors.Is(err, ErrHandlerPanic) {
+		t.Errorf("Expected ErrHandlerPanic, got %v", err)
+	}
+	select {
+	case report := <-reports:
+		if err, ok := report.Value.(error); !ok || err.Error() != "boom" {
+			t.Errorf("Expected the boom error, got %v", report.Value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a HandlerPanicked event")
+	}
+}
+
+// TestWithPanicEventsNoRecursion verifies that panicking HandlerPanicked
+// listeners are not reported again
+func TestWithPanicEventsNoRecursion(t *testing.T) {
+	bus := New(WithAsync(2, 16), WithPanicEvents())
+	defer bus.Close()
+	calls := make(chan struct{}, 8)
+
+	bus.Subscribe(HandlerPanickedType, func(event Event) {
+		calls <- struct{}{}
+		panic("again")
+	})
+	bus.Subscribe("player:health", func(event Event) {
+		panic("boom")
+	})
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+
+	<-calls
+	time.Sleep(20 * time.Millisecond)
+	if len(calls) != 0 {
+		t.Errorf("Expected 1 HandlerPanicked event, got %d more", len(calls))
+	}
+}
+
+// TestPanicEventsDisabled verifies that no events are published by default
+func TestPanicEventsDisabled(t *testing.T) {
+	bus := New(WithAsync(2, 16))
+	defer bus.Close()
+	reports := make(chan HandlerPanicked, 1)
+
+	bus.Subscribe(HandlerPanickedType, func(event Event) {
+		reports <- event.(HandlerPanicked)
+	})
+	bus.Subscribe("player:health", func(event Event) {
+		panic("boom")
+	})
+	bus.PublishAndWait(context.Background(), healthEvent{playerID: "p1", hp: 10})
+
+	select {
+	case report := <-reports:
+		t.Errorf("Expected no HandlerPanicked event, got %+v", report)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+// TestWithPanicEventsReportsListenerPanics verifies that panics of
+// synchronous HandlerPanicked listeners are reported to Errors
+func TestWithPanicEventsReportsListenerPanics(t *testing.T) {
+	bus := New(WithPanicEvents())
+	defer bus.Close()
+	failures := bus.Errors()
+
+	bus.Subscribe(HandlerPanickedType, func(event Event) {
+		panic("again")
+	})
+	bus.Subscribe("player:health", func(event Event) {
+		panic("boom")
+	})
+	bus.PublishAndWait(context.Background(), healthEvent{playerID: "p1", hp: 10})
+
+	for {
+		select {
+		case failure := <-failures:
+			if failure.EventType != HandlerPanickedType {
+				continue
+			}
+			if !errors.Is(failure.Err, ErrHandlerPanic) {
+				t.Errorf("Expected ErrHandlerPanic, got %v", failure.Err)
+			}
+			return
+		case <-time.After(time.Second):
+			t.Fatal("Expected the HandlerPanicked listener panic reported")
+		}
+	}
+}
+
+// TestWithPanicEventsImmutabilityCheck verifies that panic events pass the
+// immutability check
+func TestWithPanicEventsImmutabilityCheck(t *testing.T) {
+	bus := New(WithPanicEvents(), WithImmutabilityCheck(true))
+	defer bus.Close()
+	failures := bus.Errors()
+	reports := make(chan HandlerPanicked, 1)
+
+	bus.Subscribe(HandlerPanickedType, func(event Event) {
+		reports <- event.(HandlerPanicked)
+	})
+	bus.Subscribe("player:health", func(event Event) {
+		panic("boom")
+	})
+	bus.PublishAndWait(context.Background(), healthEvent{playerID: "p1", hp: 10})
+
+	select {
+	case report := <-reports:
+		if report.Value != "boom" {
+			t.Errorf("Expected the boom panic, got %v", report.Value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a HandlerPanicked event")
+	}
+	for len(failures) > 0 {
+		if failure := <-failures; failure.EventType == HandlerPanickedType || errors.Is(failure.Err, ErrMutableEvent) {
+			t.Errorf("Expected no error publishing the event, got %v", failure.Err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package eventbus
+
+// Partitioned is implemented by events that belong to an entity whose
+// events must stay in order, such as a player or an order. Bridges use the
+// key to route the events of one entity to the same broker partition.
+//
+// Example:
+//
+//	func (e PlayerMoved) PartitionKey() string { return e.PlayerID }
+type Partitioned interface {
+	PartitionKey() string
+}
+
+// PartitionKey returns the partition key of event, or "" if it does not
+// implement Partitioned.
+func PartitionKey(event Event) string {
+	if p, ok := event.(Partitioned); ok {
+		return p.PartitionKey()
+	}
+	return ""
+}
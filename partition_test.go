@@ -0,0 +1,26 @@
+package eventbus
+
+import "testing"
+
+// playerMoved is an event partitioned by player
+type playerMoved struct {
+	PlayerID string
+}
+
+func (e playerMoved) GetType() EventType {
+	return "player:moved"
+}
+
+func (e playerMoved) PartitionKey() string {
+	return e.PlayerID
+}
+
+// TestPartitionKey verifies that partition keys are read from events implementing Partitioned
+func TestPartitionKey(t *testing.T) {
+	if key := PartitionKey(playerMoved{PlayerID: "p1"}); key != "p1" {
+		t.Errorf("Expected p1, got %q", key)
+	}
+	if key := PartitionKey(Of("chat:message", "hi")); key != "" {
+		t.Errorf("Expected no key, got %q", key)
+	}
+}
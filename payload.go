@@ -0,0 +1,64 @@
+package eventbus
+
+import "encoding/json"
+
+// payloadEvent is the event created by Of.
+type payloadEvent[T any] struct {
+	eventType EventType
+	payload   T
+}
+
+// GetType returns the event type given to Of.
+func (e payloadEvent[T]) GetType() EventType {
+	return e.eventType
+}
+
+// payloadValue returns the payload, for matching its fields.
+func (e payloadEvent[T]) payloadValue() any {
+	return e.payload
+}
+
+// MarshalJSON encodes only the payload, so exported history contains the
+// same JSON as for a hand-written event struct.
+func (e payloadEvent[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.payload)
+}
+
+// Of wraps payload in an event of the given type, so small messages don't
+// need a dedicated struct with a GetType method.
+//
+// Example:
+//
+//	bus.Publish(eventbus.Of("score:changed", 42))
+func Of[T any](eventType EventType, payload T) Event {
+	return payloadEvent[T]{eventType: eventType, payload: payload}
+}
+
+// Payload extracts a value of type T from event. It accepts events created
+// with Of, events that are themselves of type T, and imported RawEvent
+// values whose JSON payload decodes into T.
+//
+// Example:
+//
+//	bus.Subscribe("score:changed", func(event eventbus.Event) {
+//	    if score, ok := eventbus.Payload[int](event); ok {
+//	        fmt.Println("New score:", score)
+//	    }
+//	})
+func Payload[T any](event Event) (T, bool) {
+	switch e := event.(type) {
+	case payloadEvent[T]:
+		return e.payload, true
+	case T:
+		return e, true
+	case RawEvent:
+		var payload T
+		if err := e.Decode(&payload); err != nil {
+			return payload, false
+		}
+		return payload, true
+	}
+
+	var zero T
+	return zero, false
+}
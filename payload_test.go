@@ -0,0 +1,66 @@
+package eventbus
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestOfAndPayload verifies wrapping and unwrapping simple payloads
+func TestOfAndPayload(t *testing.T) {
+	bus := New()
+	var score int
+	var ok bool
+
+	bus.Subscribe("score:changed", func(event Event) {
+		score, ok = Payload[int](event)
+	})
+
+	bus.Publish(Of("score:changed", 42))
+
+	if !ok || score != 42 {
+		t.Errorf("Expected payload 42, got %d (ok=%v)", score, ok)
+	}
+}
+
+// TestPayloadWrongType verifies that mismatched payload types are reported
+func TestPayloadWrongType(t *testing.T) {
+	event := Of("score:changed", 42)
+
+	if _, ok := Payload[string](event); ok {
+		t.Error("Expected a string payload lookup to fail")
+	}
+	if event.GetType() != "score:changed" {
+		t.Errorf("Expected type score:changed, got %q", event.GetType())
+	}
+}
+
+// TestPayloadOfEventStruct verifies that regular events can be extracted too
+func TestPayloadOfEventStruct(t *testing.T) {
+	event, ok := Payload[scoreEvent](scoreEvent{Player: "alice", Points: 3})
+	if !ok || event.Player != "alice" {
+		t.Errorf("Expected the event itself, got %+v (ok=%v)", event, ok)
+	}
+}
+
+// TestPayloadAfterImport verifies that payloads survive a history round trip
+func TestPayloadAfterImport(t *testing.T) {
+	bus := New(WithStore(NewMemoryStore()))
+	bus.Publish(Of("level:loaded", "forest"))
+
+	var buf bytes.Buffer
+	bus.History().Export(&buf)
+
+	store := NewMemoryStore()
+	New(WithStore(store)).History().Import(&buf)
+
+	var level string
+	var ok bool
+	store.Read(1, func(envelope Envelope) error {
+		level, ok = Payload[string](envelope.Event)
+		return nil
+	})
+
+	if !ok || level != "forest" {
+		t.Errorf("Expected imported payload 'forest', got %q (ok=%v)", level, ok)
+	}
+}
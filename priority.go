@@ -0,0 +1,138 @@
+package eventbus
+
+import (
+	"context"
+	"strconv"
+)
+
+// Priority orders deliveries on asynchronous topics. Higher values are
+// more urgent.
+type Priority int
+
+const (
+	// PriorityLow marks bulk traffic such as telemetry.
+	PriorityLow Priority = -1
+	// PriorityNormal is the priority of events that declare none.
+	PriorityNormal Priority = 0
+	// PriorityHigh marks urgent events. On asynchronous topics, they are
+	// delivered before queued events of lower priority.
+	PriorityHigh Priority = 1
+)
+
+// urgent reports whether job goes to the urgent lane of a pool: jobs above
+// normal priority, except on serial topics, where jobs must stay in order.
+func (job asyncJob) urgent() bool {
+	return job.priority > PriorityNormal && job.serial == nil
+}
+
+// Metadata keys recorded by WithPriorityInheritance.
+const (
+	// MetadataEventID is the bus-assigned ID of the event.
+	MetadataEventID = "event-id"
+	// MetadataCausationID is the event ID of the event whose listener
+	// published the event.
+	MetadataCausationID = "causation-id"
+	// MetadataPriority is the priority of the event, if not normal.
+	MetadataPriority = "priority"
+)
+
+// Prioritized is implemented by events that declare their priority.
+type Prioritized interface {
+	Priority() Priority
+}
+
+// priorityKey is the context key of the priority set by WithPriority.
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx publishing events with priority p,
+// overriding the priority the events declare.
+//
+// Example:
+//
+//	bus.PublishContext(eventbus.WithPriority(ctx, eventbus.PriorityHigh), PlayerDamaged{ID: "p-1"})
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// WithPriorityInheritance makes events published from within a listener
+// inherit the priority of the event being handled, if it is higher than
+// their own, so urgent chains such as damage, death, and respawn are not
+// delayed behind bulk traffic. The listener must publish with the context
+// it received, so it must be subscribed with SubscribeContext.
+//
+// Every event is also given a bus-assigned ID, recorded in its metadata
+// under MetadataEventID. Events published from within a listener record
+// the ID of the event being handled under MetadataCausationID.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithAsync(4, 1024), eventbus.WithPriorityInheritance())
+//
+//	bus.SubscribeContext("player:damaged", func(ctx context.Context, event eventbus.Event) {
+//	    if event.(PlayerDamaged).HP <= 0 {
+//	        // Inherits the priority of player:damaged
+//	        bus.PublishContext(ctx, PlayerDied{ID: event.(PlayerDamaged).ID})
+//	    }
+//	})
+func WithPriorityInheritance() Option {
+	return func(bus *eventBusImpl) {
+		bus.inheritPriority = true
+	}
+}
+
+// cause describes the event being handled by a listener. It is passed in
+// the listener context when priority inheritance is enabled.
+type cause struct {
+	id        uint64
+	eventType EventType
+	priority  Priority
+	parent    *cause
+	// depth is the number of ancestors; see WithMaxPublishDepth.
+	depth int
+}
+
+// causeKey is the context key of the cause.
+type causeKey struct{}
+
+// causeOf returns the cause stored in ctx, or nil.
+func causeOf(ctx context.Context) *cause {
+	c, _ := ctx.Value(causeKey{}).(*cause)
+	return c
+}
+
+// priorityOf returns the priority of event published with ctx: the one set
+// with WithPriority, the one event declares, or PriorityNormal.
+func priorityOf(ctx context.Context, event Event) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	if prioritized, ok := event.(Prioritized); ok {
+		return prioritized.Priority()
+	}
+	return PriorityNormal
+}
+
+// inherit applies priority inheritance to event with the given ID and
+// priority, published with ctx. It returns the inherited priority, the
+// metadata extended with the event and causation IDs, and the cause to
+// pass to the listeners.
+func inherit(ctx context.Context, event Event, id uint64, priority Priority, metadata map[string]string) (Priority, map[string]string, *cause) {
+	parent := causeOf(ctx)
+	if parent != nil && parent.priority > priority {
+		priority = parent.priority
+	}
+
+	extended := make(map[string]string, len(metadata)+3)
+	for key, value := range metadata {
+		extended[key] = value
+	}
+	extended[MetadataEventID] = strconv.FormatUint(id, 10)
+	if parent != nil {
+		extended[MetadataCausationID] = strconv.FormatUint(parent.id, 10)
+	}
+	if priority != PriorityNormal {
+		extended[MetadataPriority] = strconv.Itoa(int(priority))
+	}
+
+	return priority, extended, &cause{id: id, eventType: event.GetType(), priority: priority, parent: parent, depth: depthOf(parent)}
+}
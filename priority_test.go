@@ -0,0 +1,194 @@
+package eventbus
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type urgentEvent struct{}
+
+func (e urgentEvent) GetType() EventType {
+	return "combat:urgent"
+}
+
+func (e urgentEvent) Priority() Priority {
+	return PriorityHigh
+}
+
+// testPriorityLane verifies that urgent jobs overtake queued normal jobs
+func testPriorityLane(t *testing.T, config TopicConfig) {
+	bus := New(WithTopicConfig("*", config))
+
+	running := make(chan struct{})
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	var order []string
+	record := func(name string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		order = append(order, name)
+	}
+
+	bus.Subscribe("block", func(event Event) {
+		close(running)
+		<-release
+	})
+	bus.Subscribe("telemetry:fps", func(event Event) {
+		record(event.(testEvent).data)
+	})
+	bus.Subscribe("combat:urgent", func(event Event) {
+		record("declared")
+	})
+
+	bus.Publish(testEvent{eventType: "block"})
+	<-running
+	bus.Publish(testEvent{eventType: "telemetry:fps", data: "a"})
+	bus.Publish(testEvent{eventType: "telemetry:fps", data: "b"})
+	bus.PublishContext(WithPriority(context.Background(), PriorityHigh), testEvent{eventType: "telemetry:fps", data: "explicit"})
+	bus.Publish(urgentEvent{})
+	close(release)
+	bus.Close()
+
+	got := strings.Join(order, ",")
+	if !strings.HasPrefix(got, "explicit,declared") && !strings.HasPrefix(got, "declared,explicit") {
+		t.Errorf("Expected urgent events first, got %s", got)
+	}
+	if !strings.HasSuffix(got, "a,b") {
+		t.Errorf("Expected normal events in order after urgent ones, got %s", got)
+	}
+}
+
+// TestPriorityLane verifies that the shared-queue pool delivers urgent events first
+func TestPriorityLane(t *testing.T) {
+	testPriorityLane(t, TopicConfig{Async: true, Workers: 1, QueueSize: 16})
+}
+
+// TestPriorityLaneWorkStealing verifies that the work-stealing pool delivers urgent events first
+func TestPriorityLaneWorkStealing(t *testing.T) {
+	testPriorityLane(t, TopicConfig{Async: true, Workers: 1, QueueSize: 16, WorkStealing: true})
+}
+
+// testPriorityAging verifies that normal events kept waiting by urgent
+// ones for the aging delay are delivered first, each aging on its own
+func testPriorityAging(t *testing.T, config TopicConfig) {
+	config.Aging = 10 * time.Millisecond
+	bus := New(WithTopicConfig("*", config))
+
+	running := make(chan struct{})
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	var order []string
+	record := func(name string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		order = append(order, name)
+	}
+
+	bus.Subscribe("block", func(event Event) {
+		close(running)
+		<-release
+	})
+	bus.Subscribe("telemetry:fps", func(event Event) {
+		record(event.(testEvent).data)
+	})
+	bus.Subscribe("combat:urgent", func(event Event) {
+		record("urgent")
+	})
+
+	bus.Publish(testEvent{eventType: "block"})
+	<-running
+	bus.Publish(testEvent{eventType: "telemetry:fps", data: "a"})
+	bus.Publish(testEvent{eventType: "telemetry:fps", data: "b"})
+	bus.Publish(urgentEvent{})
+	bus.Publish(urgentEvent{})
+	time.Sleep(2 * config.Aging)
+	close(release)
+	bus.Close()
+
+	if got := strings.Join(order, ","); got != "a,b,urgent,urgent" {
+		t.Errorf("Expected the aged events first, got %s", got)
+	}
+}
+
+// TestPriorityAging verifies aging on the shared-queue pool
+func TestPriorityAging(t *testing.T) {
+	testPriorityAging(t, TopicConfig{Async: true, Workers: 1, QueueSize: 16})
+}
+
+// TestPriorityAgingWorkStealing verifies aging on the work-stealing pool
+func TestPriorityAgingWorkStealing(t *testing.T) {
+	testPriorityAging(t, TopicConfig{Async: true, Workers: 1, QueueSize: 16, WorkStealing: true})
+}
+
+type damagedEvent struct{}
+
+func (e damagedEvent) GetType() EventType {
+	return "player:damaged"
+}
+
+func (e damagedEvent) Priority() Priority {
+	return PriorityHigh
+}
+
+// TestPriorityInheritance verifies that nested publishes inherit priority and record causation
+func TestPriorityInheritance(t *testing.T) {
+	store := NewMemoryStore()
+	bus := New(WithAsync(1, 16), WithStore(store), WithPriorityInheritance())
+
+	bus.SubscribeContext("player:damaged", func(ctx context.Context, event Event) {
+		bus.PublishContext(ctx, testEvent{eventType: "player:died"})
+	})
+	var inherited Priority
+	done := make(chan struct{})
+	bus.SubscribeContext("player:died", func(ctx context.Context, event Event) {
+		inherited = causeOf(ctx).priority
+		close(done)
+	})
+
+	bus.Publish(damagedEvent{})
+	<-done
+	bus.Close()
+
+	if inherited != PriorityHigh {
+		t.Errorf("Expected the follow-up to inherit PriorityHigh, got %d", inherited)
+	}
+
+	var envelopes []Envelope
+	store.Read(1, func(envelope Envelope) error {
+		envelopes = append(envelopes, envelope)
+		return nil
+	})
+	if len(envelopes) != 2 {
+		t.Fatalf("Expected 2 envelopes, got %d", len(envelopes))
+	}
+	damaged, died := envelopes[0].Metadata, envelopes[1].Metadata
+	if damaged[MetadataEventID] == "" || died[MetadataCausationID] != damaged[MetadataEventID] {
+		t.Errorf("Expected the causation ID to be %q, got %q", damaged[MetadataEventID], died[MetadataCausationID])
+	}
+	if died[MetadataPriority] != "1" {
+		t.Errorf("Expected priority 1 in the metadata, got %q", died[MetadataPriority])
+	}
+}
+
+// TestPriorityNotInherited verifies that follow-ups keep their own priority without inheritance
+func TestPriorityNotInherited(t *testing.T) {
+	bus := New(WithAsync(1, 16))
+
+	var follow Priority = -2
+	done := make(chan struct{})
+	bus.SubscribeContext("player:damaged", func(ctx context.Context, event Event) {
+		follow = priorityOf(ctx, testEvent{eventType: "player:died"})
+		close(done)
+	})
+
+	bus.Publish(damagedEvent{})
+	<-done
+	bus.Close()
+
+	if follow != PriorityNormal {
+		t.Errorf("Expected PriorityNormal, got %d", follow)
+	}
+}
@@ -0,0 +1,112 @@
+package eventbus
+
+import "sync"
+
+// Projection builds a read model from a stream of persisted events.
+// It applies envelopes to a user-defined state, remembers the sequence of
+// the last applied envelope as its checkpoint, and can be rebuilt from
+// scratch at any time. Together with an EventStore this gives CQRS-style
+// read models driven directly by the bus.
+//
+// A Projection is safe for concurrent use.
+//
+// Example:
+//
+//	scores := eventbus.NewProjection(
+//	    func() map[string]int { return map[string]int{} },
+//	    func(state map[string]int, envelope eventbus.Envelope) map[string]int {
+//	        if e, ok := envelope.Event.(PointsScored); ok {
+//	            state[e.PlayerID] += e.Points
+//	        }
+//	        return state
+//	    },
+//	)
+//
+//	// Apply everything persisted since the last checkpoint
+//	scores.CatchUp(store)
+type Projection[S any] struct {
+	initial    func() S
+	apply      func(S, Envelope) S
+	state      S
+	checkpoint uint64
+	mutex      sync.Mutex
+}
+
+// NewProjection creates a projection whose state starts as initial() and is
+// advanced by apply for every envelope.
+func NewProjection[S any](initial func() S, apply func(state S, envelope Envelope) S) *Projection[S] {
+	return &Projection[S]{
+		initial: initial,
+		apply:   apply,
+		state:   initial(),
+	}
+}
+
+// Apply advances the projection with a single envelope. Envelopes at or
+// before the checkpoint have already been applied and are ignored, so the
+// same envelope can safely be delivered more than once.
+func (p *Projection[S]) Apply(envelope Envelope) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.applyLocked(envelope)
+}
+
+// CatchUp applies every envelope in store after the current checkpoint.
+func (p *Projection[S]) CatchUp(store EventStore) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.catchUpLocked(store)
+}
+
+// Rebuild discards the current state and replays store from the beginning.
+// Use it after changing the projection logic. Envelopes applied
+// concurrently wait for the rebuild, so State never returns a partially
+// rebuilt state.
+func (p *Projection[S]) Rebuild(store EventStore) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.state = p.initial()
+	p.checkpoint = 0
+	return p.catchUpLocked(store)
+}
+
+// State returns the current state of the projection.
+// Reference types such as maps are shared with the projection and must not
+// be modified by the caller.
+func (p *Projection[S]) State() S {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.state
+}
+
+// Checkpoint returns the sequence of the last applied envelope,
+// or 0 if nothing has been applied yet.
+func (p *Projection[S]) Checkpoint() uint64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.checkpoint
+}
+
+// catchUpLocked applies every envelope in store after the checkpoint.
+// The caller must hold p.mutex.
+func (p *Projection[S]) catchUpLocked(store EventStore) error {
+	return store.Read(p.checkpoint+1, func(envelope Envelope) error {
+		p.applyLocked(envelope)
+		return nil
+	})
+}
+
+// applyLocked applies envelope if it is newer than the checkpoint.
+// The caller must hold p.mutex.
+func (p *Projection[S]) applyLocked(envelope Envelope) {
+	if envelope.Sequence <= p.checkpoint {
+		return
+	}
+	p.state = p.apply(p.state, envelope)
+	p.checkpoint = envelope.Sequence
+}
@@ -0,0 +1,94 @@
+package eventbus
+
+import "testing"
+
+func newCountingProjection() *Projection[map[EventType]int] {
+	return NewProjection(
+		func() map[EventType]int { return make(map[EventType]int) },
+		func(state map[EventType]int, envelope Envelope) map[EventType]int {
+			state[envelope.Event.GetType()]++
+			return state
+		},
+	)
+}
+
+// TestProjectionCatchUp verifies that a projection applies new events from its checkpoint
+func TestProjectionCatchUp(t *testing.T) {
+	store := NewMemoryStore()
+	bus := New(WithStore(store))
+	projection := newCountingProjection()
+
+	bus.Publish(testEvent{eventType: "item:added"})
+	bus.Publish(testEvent{eventType: "item:added"})
+
+	if err := projection.CatchUp(store); err != nil {
+		t.Fatalf("CatchUp failed: %v", err)
+	}
+	if projection.Checkpoint() != 2 {
+		t.Errorf("Expected checkpoint 2, got %d", projection.Checkpoint())
+	}
+
+	bus.Publish(testEvent{eventType: "item:removed"})
+	projection.CatchUp(store)
+
+	state := projection.State()
+	if state["item:added"] != 2 || state["item:removed"] != 1 {
+		t.Errorf("Unexpected state: %v", state)
+	}
+	if projection.Checkpoint() != 3 {
+		t.Errorf("Expected checkpoint 3, got %d", projection.Checkpoint())
+	}
+}
+
+// TestProjectionApplyIdempotent verifies that already applied envelopes are ignored
+func TestProjectionApplyIdempotent(t *testing.T) {
+	projection := newCountingProjection()
+	envelope := Envelope{Sequence: 1, Event: testEvent{eventType: "item:added"}}
+
+	projection.Apply(envelope)
+	projection.Apply(envelope)
+
+	if count := projection.State()["item:added"]; count != 1 {
+		t.Errorf("Expected 1 application, got %d", count)
+	}
+}
+
+// TestProjectionRebuild verifies that a rebuild starts from scratch
+func TestProjectionRebuild(t *testing.T) {
+	store := NewMemoryStore()
+	store.Append(testEvent{eventType: "item:added"})
+	store.Append(testEvent{eventType: "item:added"})
+
+	projection := newCountingProjection()
+	projection.CatchUp(store)
+
+	if err := projection.Rebuild(store); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	if count := projection.State()["item:added"]; count != 2 {
+		t.Errorf("Expected 2 after rebuild, got %d", count)
+	}
+}
+
+// TestProjectionRebuildConcurrentApply verifies that envelopes applied during a rebuild wait for it instead of being lost
+func TestProjectionRebuildConcurrentApply(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 100; i++ {
+		store.Append(testEvent{eventType: "item:added"})
+	}
+	projection := newCountingProjection()
+	projection.CatchUp(store)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		projection.Apply(Envelope{Sequence: 101, Event: testEvent{eventType: "item:added"}})
+	}()
+	projection.Rebuild(store)
+	<-done
+
+	if count := projection.State()["item:added"]; count != 101 {
+		t.Errorf("Expected 101 after rebuild, got %d", count)
+	}
+}
@@ -0,0 +1,111 @@
+package eventbus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WritePrometheus writes the stats in the Prometheus text exposition
+// format, so they can be scraped without a client library. Latency
+// histograms are written with a topic label, in seconds.
+//
+// Example:
+//
+//	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+//	    w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+//	    bus.Stats().WritePrometheus(w)
+//	})
+func (s Stats) WritePrometheus(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	metric := func(name, kind, help string, samples ...string) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, sample := range samples {
+			fmt.Fprintf(b, "%s%s\n", name, sample)
+		}
+	}
+
+	metric("eventbus_published_total", "counter", "Events accepted by the bus.",
+		fmt.Sprintf(" %d", s.Published))
+	metric("eventbus_dropped_total", "counter", "Deliveries dropped by asynchronous queues.",
+		fmt.Sprintf(`{reason="queue_full"} %d`, s.DroppedQueueFull),
+		fmt.Sprintf(`{reason="cancelled"} %d`, s.DroppedCancelled))
+	metric("eventbus_buffer_dropped_total", "counter", "Events dropped by send buffers.",
+		fmt.Sprintf(" %d", s.BufferDropped))
+	metric("eventbus_expired_total", "counter", "Deliveries skipped for their age by WithMaxAge.",
+		fmt.Sprintf(" %d", s.DroppedExpired))
+	metric("eventbus_muted_total", "counter", "Deliveries suppressed by Mute or Solo.",
+		fmt.Sprintf(" %d", s.Muted))
+	metric("eventbus_subscriptions", "gauge", "Active subscriptions.",
+		fmt.Sprintf(" %d", s.Subscriptions))
+	metric("eventbus_queued", "gauge", "Jobs waiting in asynchronous queues.",
+		fmt.Sprintf(" %d", s.Queued))
+
+	if s.Memory != nil {
+		metric("eventbus_memory_used_bytes", "gauge", "Estimated memory of budgeted caches and stores.",
+			fmt.Sprintf(" %d", s.Memory.Used))
+		metric("eventbus_memory_evictions_total", "counter", "Entries evicted by the memory budget.",
+			fmt.Sprintf(" %d", s.Memory.Evictions))
+		metric("eventbus_memory_evicted_bytes_total", "counter", "Estimated memory evicted by the memory budget.",
+			fmt.Sprintf(" %d", s.Memory.EvictedBytes))
+	}
+	if s.Latency != nil {
+		topics := slices.Sorted(maps.Keys(s.Latency))
+		for _, histogram := range []struct {
+			name, help string
+			get        func(TopicLatency) Histogram
+		}{
+			{"eventbus_delivery_queue_seconds", "Time from publishing an event to a listener starting.", func(l TopicLatency) Histogram { return l.Queue }},
+			{"eventbus_handler_seconds", "Time listeners take to handle an event.", func(l TopicLatency) Histogram { return l.Handler }},
+		} {
+			fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", histogram.name, histogram.help, histogram.name)
+			for _, topic := range topics {
+				writeHistogram(b, histogram.name, `topic="`+escapeLabel(string(topic))+`"`, histogram.get(s.Latency[topic]))
+			}
+		}
+	}
+	return b.Flush()
+}
+
+// WritePrometheusHistogram writes h as a Prometheus histogram named name,
+// in seconds, with HELP and TYPE comments. labels are added to every
+// sample, for example to expose the latency of a bridge.
+func WritePrometheusHistogram(w io.Writer, name, help string, labels map[string]string, h Histogram) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	var pairs []string
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, key+`="`+escapeLabel(labels[key])+`"`)
+	}
+	writeHistogram(b, name, strings.Join(pairs, ","), h)
+	return b.Flush()
+}
+
+// writeHistogram writes the samples of h with the given label pairs.
+func writeHistogram(w io.Writer, name, labels string, h Histogram) {
+	prefix := labels
+	if prefix != "" {
+		prefix += ","
+	}
+	var cumulative uint64
+	for i, bound := range h.Bounds {
+		cumulative += h.Counts[i]
+		fmt.Fprintf(w, "%s_bucket{%sle=\"%s\"} %d\n", name, prefix, strconv.FormatFloat(bound.Seconds(), 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", name, prefix, h.Count)
+	braced := ""
+	if labels != "" {
+		braced = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, braced, strconv.FormatFloat(h.Sum.Seconds(), 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, braced, h.Count)
+}
+
+// escapeLabel escapes a Prometheus label value.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,54 @@
+package eventbus
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWritePrometheus verifies the exposition of counters and latency histograms
+func TestWritePrometheus(t *testing.T) {
+	recorder := NewLatencyRecorder()
+	recorder.Observe(3 * time.Millisecond)
+	stats := Stats{
+		Published:        7,
+		DroppedQueueFull: 2,
+		Subscriptions:    3,
+		Latency: map[EventType]TopicLatency{
+			`odd"topic`: {Queue: recorder.Histogram(), Handler: NewLatencyRecorder().Histogram()},
+		},
+	}
+
+	var out strings.Builder
+	if err := stats.WritePrometheus(&out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, expected := range []string{
+		"# TYPE eventbus_published_total counter\neventbus_published_total 7\n",
+		`eventbus_dropped_total{reason="queue_full"} 2`,
+		"eventbus_subscriptions 3\n",
+		"# TYPE eventbus_delivery_queue_seconds histogram\n",
+		`eventbus_delivery_queue_seconds_bucket{topic="odd\"topic",le="0.001"} 0`,
+		`eventbus_delivery_queue_seconds_bucket{topic="odd\"topic",le="0.005"} 1`,
+		`eventbus_delivery_queue_seconds_bucket{topic="odd\"topic",le="+Inf"} 1`,
+		`eventbus_delivery_queue_seconds_sum{topic="odd\"topic"} 0.003`,
+		`eventbus_handler_seconds_count{topic="odd\"topic"} 0`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+}
+
+// TestWritePrometheusHistogram verifies that standalone histograms carry their labels
+func TestWritePrometheusHistogram(t *testing.T) {
+	recorder := NewLatencyRecorder()
+	recorder.Observe(20 * time.Millisecond)
+
+	var out strings.Builder
+	WritePrometheusHistogram(&out, "bridge_latency_seconds", "Network latency.", map[string]string{"bridge": "nats"}, recorder.Histogram())
+	if !strings.Contains(out.String(), `bridge_latency_seconds_bucket{bridge="nats",le="0.05"} 1`) ||
+		!strings.Contains(out.String(), `bridge_latency_seconds_count{bridge="nats"} 1`) {
+		t.Errorf("Expected labelled samples, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,68 @@
+package eventbus
+
+import (
+	"context"
+	"slices"
+)
+
+// Publisher is a handle that can only publish, returned by
+// EventBus.Publisher. Handing a component a Publisher instead of the bus
+// keeps it from subscribing, enforcing architectural boundaries at the
+// type level. Every event it publishes carries its component in
+// Envelope.Publisher, and the component is recorded as a publisher of the
+// topic for Validate, Graph, and Describe, as if declared with
+// DeclarePublisher.
+type Publisher struct {
+	bus       EventBus
+	component string
+}
+
+// publisherKey is the context key of the component publishing an event.
+type publisherKey struct{}
+
+// Publisher returns a handle publishing on the bus as component.
+func (bus *eventBusImpl) Publisher(component string) *Publisher {
+	return &Publisher{bus: bus, component: component}
+}
+
+// Component returns the name the handle publishes as.
+func (p *Publisher) Component() string {
+	return p.component
+}
+
+// Publish sends an event like EventBus.Publish.
+func (p *Publisher) Publish(event Event) {
+	p.PublishContext(context.Background(), event)
+}
+
+// PublishContext sends an event like EventBus.PublishContext.
+func (p *Publisher) PublishContext(ctx context.Context, event Event) {
+	p.bus.PublishContext(p.context(ctx), event)
+}
+
+// PublishAndWait sends an event like EventBus.PublishAndWait.
+func (p *Publisher) PublishAndWait(ctx context.Context, event Event) error {
+	return p.bus.PublishAndWait(p.context(ctx), event)
+}
+
+// context returns ctx carrying the component of the handle.
+func (p *Publisher) context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, publisherKey{}, p.component)
+}
+
+// publisherOf returns the component publishing with ctx, or "".
+func publisherOf(ctx context.Context) string {
+	component, _ := ctx.Value(publisherKey{}).(string)
+	return component
+}
+
+// notePublisher records component as a publisher of eventType unless it
+// is declared already.
+func (bus *eventBusImpl) notePublisher(component string, eventType EventType) {
+	bus.subscribersMutex.RLock()
+	known := slices.Contains(bus.publishers[eventType], component)
+	bus.subscribersMutex.RUnlock()
+	if !known {
+		bus.DeclarePublisher(component, eventType)
+	}
+}
@@ -0,0 +1,81 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+)
+
+// TestPublisher verifies that events published through a handle carry its
+// component
+func TestPublisher(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	var publishers []string
+
+	bus.SubscribeContext("player:health", func(ctx context.Context, event Event) {
+		envelope, _ := EnvelopeFromContext(ctx)
+		publishers = append(publishers, envelope.Publisher)
+	})
+	combat := bus.Publisher("combat")
+	combat.Publish(healthEvent{playerID: "p1", hp: 10})
+	bus.Publish(healthEvent{playerID: "p1", hp: 5})
+	if err := combat.PublishAndWait(context.Background(), healthEvent{playerID: "p1", hp: 0}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if want := []string{"combat", "", "combat"}; !slices.Equal(publishers, want) {
+		t.Errorf("Expected %v, got %v", want, publishers)
+	}
+	if combat.Component() != "combat" {
+		t.Errorf("Expected component combat, got %q", combat.Component())
+	}
+}
+
+// TestPublisherDeclares verifies that handles are recorded as publishers
+func TestPublisherDeclares(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	bus.Publisher("combat").Publish(healthEvent{playerID: "p1", hp: 10})
+	bus.Publisher("combat").Publish(healthEvent{playerID: "p1", hp: 5})
+
+	if publishers := bus.Describe("player:health").Publishers; !slices.Equal(publishers, []string{"combat"}) {
+		t.Errorf("Expected [combat], got %v", publishers)
+	}
+}
+
+// TestPublisherHistory verifies that the component survives export and
+// import
+func TestPublisherHistory(t *testing.T) {
+	bus := New(WithStore(NewMemoryStore()))
+	defer bus.Close()
+	bus.Publisher("combat").Publish(healthEvent{playerID: "p1", hp: 10})
+
+	var exported bytes.Buffer
+	if err := bus.History().Export(&exported); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	store := NewMemoryStore()
+	imported := New(WithStore(store))
+	defer imported.Close()
+	if err := imported.History().Import(&exported); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var publishers []string
+	store.Read(1, func(envelope Envelope) error {
+		publishers = append(publishers, envelope.Publisher)
+		return nil
+	})
+	if !slices.Equal(publishers, []string{"combat"}) {
+		t.Errorf("Expected [combat], got %v", publishers)
+	}
+}
+
+// TestPublisherReadOnly verifies that read-only views hand out no publishers
+func TestPublisherReadOnly(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	expectPanic(t, ErrReadOnly, func() { bus.ReadOnly().Publisher("overlay") })
+}
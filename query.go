@@ -0,0 +1,90 @@
+package eventbus
+
+import (
+	"errors"
+	"iter"
+	"time"
+)
+
+// Query selects envelopes from an EventStore. Zero-valued fields do not
+// filter, so the zero Query matches every envelope.
+//
+// Example:
+//
+//	query := eventbus.Query{
+//	    Types: []eventbus.EventType{"payment:failed"},
+//	    Since: incidentStart,
+//	    Until: incidentEnd,
+//	    Match: func(e eventbus.Event) bool { return e.(PaymentFailed).Amount > 1000 },
+//	}
+//	for envelope, err := range eventbus.Find(store, query) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(envelope.Sequence, envelope.Event)
+//	}
+type Query struct {
+	// Types restricts results to the listed event types.
+	Types []EventType
+	// Since excludes envelopes recorded before this time.
+	Since time.Time
+	// Until excludes envelopes recorded at or after this time.
+	Until time.Time
+	// CorrelationID restricts results to envelopes with this correlation ID.
+	CorrelationID string
+	// Match is an optional predicate over the event payload.
+	Match func(Event) bool
+}
+
+// Matches reports whether envelope satisfies every condition of the query.
+func (q Query) Matches(envelope Envelope) bool {
+	if len(q.Types) > 0 && !q.hasType(envelope.Event.GetType()) {
+		return false
+	}
+	if !q.Since.IsZero() && envelope.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !envelope.Time.Before(q.Until) {
+		return false
+	}
+	if q.CorrelationID != "" && envelope.CorrelationID != q.CorrelationID {
+		return false
+	}
+	if q.Match != nil && !q.Match(envelope.Event) {
+		return false
+	}
+	return true
+}
+
+// hasType reports whether eventType is one of the queried types.
+func (q Query) hasType(eventType EventType) bool {
+	for _, t := range q.Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// errStopQuery ends a store scan when the consumer stops iterating.
+var errStopQuery = errors.New("eventbus: query stopped")
+
+// Find returns an iterator over the envelopes in store that match query,
+// in sequence order. If reading the store fails, the iterator yields the
+// error once and stops.
+func Find(store EventStore, query Query) iter.Seq2[Envelope, error] {
+	return func(yield func(Envelope, error) bool) {
+		err := store.Read(1, func(envelope Envelope) error {
+			if !query.Matches(envelope) {
+				return nil
+			}
+			if !yield(envelope, nil) {
+				return errStopQuery
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopQuery) {
+			yield(Envelope{}, err)
+		}
+	}
+}
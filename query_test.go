@@ -0,0 +1,106 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type orderEvent struct {
+	orderID string
+	amount  int
+}
+
+func (e orderEvent) GetType() EventType {
+	return "order:placed"
+}
+
+func (e orderEvent) CorrelationID() string {
+	return e.orderID
+}
+
+// TestFind verifies filtering by type, correlation ID and payload
+func TestFind(t *testing.T) {
+	store := NewMemoryStore()
+	store.Append(orderEvent{orderID: "o1", amount: 10})
+	store.Append(testEvent{eventType: "other:event"})
+	store.Append(orderEvent{orderID: "o2", amount: 500})
+	store.Append(orderEvent{orderID: "o1", amount: 700})
+
+	query := Query{
+		Types:         []EventType{"order:placed"},
+		CorrelationID: "o1",
+		Match:         func(e Event) bool { return e.(orderEvent).amount > 100 },
+	}
+
+	var found []uint64
+	for envelope, err := range Find(store, query) {
+		if err != nil {
+			t.Fatalf("Find failed: %v", err)
+		}
+		found = append(found, envelope.Sequence)
+	}
+
+	if len(found) != 1 || found[0] != 4 {
+		t.Errorf("Expected only sequence 4, got %v", found)
+	}
+}
+
+// TestFindTimeRange verifies filtering by recording time
+func TestFindTimeRange(t *testing.T) {
+	store := NewMemoryStore()
+	store.Append(testEvent{eventType: "time:test", data: "before"})
+	time.Sleep(5 * time.Millisecond)
+	since := time.Now()
+	store.Append(testEvent{eventType: "time:test", data: "during"})
+	until := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	store.Append(testEvent{eventType: "time:test", data: "after"})
+
+	var found []string
+	for envelope := range Find(store, Query{Since: since, Until: until.Add(time.Nanosecond)}) {
+		found = append(found, envelope.Event.(testEvent).data)
+	}
+
+	if len(found) != 1 || found[0] != "during" {
+		t.Errorf("Expected only 'during', got %v", found)
+	}
+}
+
+// TestFindBreak verifies that stopping iteration early is supported
+func TestFindBreak(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		store.Append(testEvent{eventType: "break:test"})
+	}
+
+	count := 0
+	for range Find(store, Query{}) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+
+	if count != 2 {
+		t.Errorf("Expected to stop after 2 envelopes, got %d", count)
+	}
+}
+
+type brokenStore struct{ MemoryStore }
+
+func (s *brokenStore) Read(from uint64, fn func(Envelope) error) error {
+	return errors.New("store unavailable")
+}
+
+// TestFindError verifies that store errors are yielded to the caller
+func TestFindError(t *testing.T) {
+	var got error
+	for _, err := range Find(&brokenStore{}, Query{}) {
+		got = err
+	}
+
+	if got == nil {
+		t.Error("Expected the store error to be yielded")
+	}
+}
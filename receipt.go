@@ -0,0 +1,135 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Delivery is the outcome of delivering an event to one listener.
+type Delivery struct {
+	// Index is the position of the listener in delivery order, which is
+	// registration order unless WithStage reorders listeners.
+	Index int
+	// Handler is the label of the listener, if it has one.
+	Handler string
+	// Duration is how long the listener ran.
+	Duration time.Duration
+	// Err is nil on success. Failures are *HandlerError values.
+	Err error
+	// Completed is false if the publisher stopped waiting before the
+	// listener finished.
+	Completed bool
+}
+
+// Panicked reports whether the listener panicked.
+func (d Delivery) Panicked() bool {
+	return errors.Is(d.Err, ErrHandlerPanic)
+}
+
+// Receipt describes how a published event was delivered.
+type Receipt struct {
+	// EventType is the type of the published event.
+	EventType EventType
+	// Deliveries holds one entry per listener, in delivery order.
+	Deliveries []Delivery
+}
+
+// Failed returns the deliveries that completed with an error.
+func (r *Receipt) Failed() []Delivery {
+	var failed []Delivery
+	for _, delivery := range r.Deliveries {
+		if delivery.Err != nil {
+			failed = append(failed, delivery)
+		}
+	}
+	return failed
+}
+
+// Err joins the errors of all failed deliveries, or returns nil.
+func (r *Receipt) Err() error {
+	var errs []error
+	for _, delivery := range r.Failed() {
+		errs = append(errs, delivery.Err)
+	}
+	return errors.Join(errs...)
+}
+
+// PublishDetailed sends an event and returns a receipt of every delivery.
+func (bus *eventBusImpl) PublishDetailed(ctx context.Context, event Event) (*Receipt, error) {
+	waiter := &deliveryWaiter{done: make(chan struct{})}
+	if err := bus.publish(ctx, event, waiter); err != nil {
+		return waiter.receipt(event.GetType()), err
+	}
+	err := waiter.wait(ctx)
+	receipt := waiter.receipt(event.GetType())
+	bus.reportTimeouts(ctx, event, receipt, err)
+	return receipt, err
+}
+
+// deliveryWaiter collects the outcome of every delivery of one event.
+type deliveryWaiter struct {
+	pending    sync.WaitGroup
+	mutex      sync.Mutex
+	deliveries []Delivery
+	done       chan struct{}
+}
+
+// add registers the delivery to listener at index and returns
+// the slot the delivery reports to. It does nothing on a nil waiter.
+func (w *deliveryWaiter) add(index int, listener *Subscription) int {
+	if w == nil {
+		return 0
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.pending.Add(1)
+	w.deliveries = append(w.deliveries, Delivery{Index: index, Handler: listener.name})
+	return len(w.deliveries) - 1
+}
+
+// report records the outcome of the delivery in slot.
+// It does nothing on a nil waiter.
+func (w *deliveryWaiter) report(slot int, duration time.Duration, err error) {
+	if w == nil {
+		return
+	}
+
+	w.mutex.Lock()
+	delivery := &w.deliveries[slot]
+	delivery.Duration = duration
+	delivery.Err = err
+	delivery.Completed = true
+	w.mutex.Unlock()
+
+	w.pending.Done()
+}
+
+// wait blocks until every delivery has been reported or ctx is done.
+func (w *deliveryWaiter) wait(ctx context.Context) error {
+	go func() {
+		w.pending.Wait()
+		close(w.done)
+	}()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return contextError(ctx)
+	}
+}
+
+// receipt returns a snapshot of the deliveries reported so far.
+func (w *deliveryWaiter) receipt(eventType EventType) *Receipt {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return &Receipt{
+		EventType:  eventType,
+		Deliveries: append([]Delivery(nil), w.deliveries...),
+	}
+}
@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestPublishDetailed verifies per-listener outcomes in the receipt
+func TestPublishDetailed(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithAsync(3, 8)}} {
+		bus := New(opts...)
+
+		bus.Subscribe("payment:captured", func(event Event) {
+			time.Sleep(5 * time.Millisecond)
+		})
+		bus.Subscribe("payment:captured", func(event Event) {
+			panic("ledger offline")
+		})
+		bus.Subscribe("payment:captured", func(event Event) {})
+
+		receipt, err := bus.PublishDetailed(context.Background(), testEvent{eventType: "payment:captured"})
+		if err != nil {
+			t.Fatalf("PublishDetailed failed: %v", err)
+		}
+
+		if receipt.EventType != "payment:captured" {
+			t.Errorf("Expected event type payment:captured, got %q", receipt.EventType)
+		}
+		if len(receipt.Deliveries) != 3 {
+			t.Fatalf("Expected 3 deliveries, got %d", len(receipt.Deliveries))
+		}
+		for i, delivery := range receipt.Deliveries {
+			if delivery.Index != i || !delivery.Completed {
+				t.Errorf("Unexpected delivery %d: %+v", i, delivery)
+			}
+		}
+		if receipt.Deliveries[0].Duration < 5*time.Millisecond {
+			t.Errorf("Expected the first listener to take at least 5ms, got %v", receipt.Deliveries[0].Duration)
+		}
+
+		failed := receipt.Failed()
+		if len(failed) != 1 || failed[0].Index != 1 || !failed[0].Panicked() {
+			t.Errorf("Expected only listener 1 to fail with a panic, got %+v", failed)
+		}
+		if !errors.Is(receipt.Err(), ErrHandlerPanic) {
+			t.Errorf("Expected receipt error to match ErrHandlerPanic, got %v", receipt.Err())
+		}
+
+		bus.Close()
+	}
+}
+
+// TestPublishDetailedTimeout verifies that unfinished deliveries are marked incomplete
+func TestPublishDetailedTimeout(t *testing.T) {
+	bus := New(WithAsync(2, 4))
+	release := make(chan struct{})
+	bus.Subscribe("slow:event", func(event Event) {})
+	bus.Subscribe("slow:event", func(event Event) {
+		<-release
+	})
+	defer bus.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	receipt, err := bus.PublishDetailed(ctx, testEvent{eventType: "slow:event"})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+	if !receipt.Deliveries[0].Completed || receipt.Deliveries[1].Completed {
+		t.Errorf("Expected only the first delivery to complete, got %+v", receipt.Deliveries)
+	}
+}
@@ -0,0 +1,162 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// ErrTypeConflict is returned when an event type is registered for two
+// different Go types.
+var ErrTypeConflict = errors.New("eventbus: event type registered for different Go types")
+
+// TypeRegistry maps event types to the Go types carrying them. It catches
+// two packages accidentally using the same event type string for different
+// structs, and lets codecs decode events into their original type.
+//
+// A TypeRegistry is safe for concurrent use.
+type TypeRegistry struct {
+	types map[EventType]reflect.Type
+	// docs holds the documentation recorded with Document.
+	docs  map[EventType]TopicInfo
+	mutex sync.RWMutex
+}
+
+// NewTypeRegistry creates an empty registry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{types: make(map[EventType]reflect.Type)}
+}
+
+// DefaultTypes is the registry used by Register and SubscribeTyped.
+var DefaultTypes = NewTypeRegistry()
+
+// Register records the event type of T in DefaultTypes. It returns an
+// error matching ErrTypeConflict if the event type is already registered
+// for a different Go type. Registering the same type twice is allowed.
+//
+// The event type is read from the zero value of T, so GetType must not
+// depend on the event's fields.
+//
+// Example:
+//
+//	func init() {
+//	    if err := eventbus.Register[PlayerDied](); err != nil {
+//	        panic(err)
+//	    }
+//	}
+func Register[T Event]() error {
+	return RegisterIn[T](DefaultTypes)
+}
+
+// RegisterIn records the event type of T in registry, like Register.
+func RegisterIn[T Event](registry *TypeRegistry) error {
+	return registry.Add(sample[T]())
+}
+
+// sample returns a value of T whose GetType can be called: the zero value,
+// or a pointer to a zero value if T is a pointer type.
+func sample[T Event]() Event {
+	var zero T
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Pointer {
+		return reflect.New(t.Elem()).Interface().(Event)
+	}
+	return zero
+}
+
+// Add records the type of event under event.GetType(). It returns an error
+// matching ErrTypeConflict if the event type is already registered for a
+// different Go type.
+func (r *TypeRegistry) Add(event Event) error {
+	eventType, goType := event.GetType(), reflect.TypeOf(event)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, ok := r.types[eventType]; ok && existing != goType {
+		return fmt.Errorf("%w: %q is %s, not %s", ErrTypeConflict, eventType, existing, goType)
+	}
+	r.types[eventType] = goType
+	return nil
+}
+
+// Lookup returns the Go type registered for eventType.
+func (r *TypeRegistry) Lookup(eventType EventType) (reflect.Type, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	t, ok := r.types[eventType]
+	return t, ok
+}
+
+// EventTypes returns the registered event types in sorted order.
+func (r *TypeRegistry) EventTypes() []EventType {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	eventTypes := make([]EventType, 0, len(r.types))
+	for eventType := range r.types {
+		eventTypes = append(eventTypes, eventType)
+	}
+	slices.Sort(eventTypes)
+	return eventTypes
+}
+
+// Encode returns the JSON encoding of event, making the registry a Codec.
+func (r *TypeRegistry) Encode(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// Decode unmarshals data into a new value of the Go type registered for
+// eventType. Unregistered event types are decoded as RawEvent, like
+// JSONCodec does.
+//
+// Example:
+//
+//	codecs := eventbus.NewCodecRegistry(eventbus.DefaultTypes)
+func (r *TypeRegistry) Decode(eventType EventType, data []byte) (Event, error) {
+	goType, ok := r.Lookup(eventType)
+	if !ok {
+		return JSONCodec.Decode(eventType, data)
+	}
+
+	target := goType
+	if goType.Kind() == reflect.Pointer {
+		target = goType.Elem()
+	}
+	value := reflect.New(target)
+	if err := json.Unmarshal(data, value.Interface()); err != nil {
+		return nil, err
+	}
+	if goType.Kind() == reflect.Pointer {
+		return value.Interface().(Event), nil
+	}
+	return value.Elem().Interface().(Event), nil
+}
+
+// SubscribeTyped registers T in DefaultTypes and subscribes listener to its
+// event type. The listener receives T values; events of other Go types
+// published under the same event type are decoded into T if they are
+// RawEvent values and skipped otherwise. It panics with an error matching
+// ErrTypeConflict if the event type is registered for another Go type.
+//
+// Example:
+//
+//	eventbus.SubscribeTyped(bus, func(ctx context.Context, e PlayerDied) {
+//	    fmt.Println(e.PlayerID, "died")
+//	})
+func SubscribeTyped[T Event](bus EventBus, listener func(ctx context.Context, event T), opts ...SubscribeOption) *Subscription {
+	if err := Register[T](); err != nil {
+		panic(err)
+	}
+
+	return bus.SubscribeContext(sample[T]().GetType(), func(ctx context.Context, event Event) {
+		if typed, ok := Payload[T](event); ok {
+			listener(ctx, typed)
+		}
+	}, opts...)
+}
@@ -0,0 +1,133 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type playerDied struct {
+	PlayerID string
+}
+
+func (e playerDied) GetType() EventType {
+	return "player:died"
+}
+
+type otherPlayerDied struct {
+	Name string
+}
+
+func (e otherPlayerDied) GetType() EventType {
+	return "player:died"
+}
+
+type itemDropped struct {
+	Item string
+}
+
+func (e *itemDropped) GetType() EventType {
+	return "item:dropped"
+}
+
+// TestRegisterConflict verifies that an event type cannot map to two Go types
+func TestRegisterConflict(t *testing.T) {
+	registry := NewTypeRegistry()
+
+	if err := RegisterIn[playerDied](registry); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := RegisterIn[playerDied](registry); err != nil {
+		t.Errorf("Expected registering the same type twice to succeed, got %v", err)
+	}
+	if err := RegisterIn[otherPlayerDied](registry); !errors.Is(err, ErrTypeConflict) {
+		t.Errorf("Expected ErrTypeConflict, got %v", err)
+	}
+	if err := RegisterIn[*itemDropped](registry); err != nil {
+		t.Errorf("Expected pointer types to register, got %v", err)
+	}
+
+	eventTypes := registry.EventTypes()
+	if len(eventTypes) != 2 || eventTypes[0] != "item:dropped" || eventTypes[1] != "player:died" {
+		t.Errorf("Expected [item:dropped player:died], got %v", eventTypes)
+	}
+}
+
+// TestTypeRegistryCodec verifies that registered types are decoded into their Go type
+func TestTypeRegistryCodec(t *testing.T) {
+	registry := NewTypeRegistry()
+	RegisterIn[playerDied](registry)
+	RegisterIn[*itemDropped](registry)
+	codecs := NewCodecRegistry(registry)
+
+	data, err := codecs.Encode(playerDied{PlayerID: "p-1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	event, err := codecs.Decode("player:died", data)
+	if err != nil || event != (playerDied{PlayerID: "p-1"}) {
+		t.Errorf("Expected playerDied p-1, got %#v (%v)", event, err)
+	}
+
+	event, err = codecs.Decode("item:dropped", []byte(`{"Item":"sword"}`))
+	if dropped, ok := event.(*itemDropped); !ok || dropped.Item != "sword" {
+		t.Errorf("Expected *itemDropped sword, got %#v (%v)", event, err)
+	}
+
+	event, err = codecs.Decode("unknown:event", []byte(`{}`))
+	if _, ok := event.(RawEvent); !ok {
+		t.Errorf("Expected a RawEvent for unregistered types, got %#v (%v)", event, err)
+	}
+}
+
+// TestSubscribeTyped verifies that typed listeners receive decoded events and conflicts panic
+func TestSubscribeTyped(t *testing.T) {
+	bus := New()
+
+	var received []string
+	SubscribeTyped(bus, func(ctx context.Context, event playerDied) {
+		received = append(received, event.PlayerID)
+	})
+
+	bus.Publish(playerDied{PlayerID: "p-1"})
+	bus.Publish(RawEvent{Type: "player:died", Payload: []byte(`{"PlayerID":"p-2"}`)})
+
+	if len(received) != 2 || received[0] != "p-1" || received[1] != "p-2" {
+		t.Errorf("Expected [p-1 p-2], got %v", received)
+	}
+
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrTypeConflict) {
+			t.Errorf("Expected a panic with ErrTypeConflict, got %v", err)
+		}
+	}()
+	SubscribeTyped(bus, func(ctx context.Context, event otherPlayerDied) {})
+}
+
+// FuzzTypeRegistryDecode verifies that decoding arbitrary payloads into
+// registered types never panics
+func FuzzTypeRegistryDecode(f *testing.F) {
+	registry := NewTypeRegistry()
+	if err := registry.Add(playerDied{}); err != nil {
+		f.Fatal(err)
+	}
+	if err := registry.Add(&itemDropped{}); err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add("player:died", []byte(`{"PlayerID": "p-1"}`))
+	f.Add("item:dropped", []byte(`{"Item": 42}`))
+	f.Add("item:dropped", []byte(`null`))
+	f.Add("unknown", []byte(`{}`))
+
+	f.Fuzz(func(t *testing.T, eventType string, data []byte) {
+		event, err := registry.Decode(EventType(eventType), data)
+		if err != nil {
+			return
+		}
+		if event == nil || event.GetType() != EventType(eventType) {
+			t.Fatalf("Expected an event of type %q, got %#v", eventType, event)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// ErrNotReloadable is returned by Reconfigure for options that cannot be
+// changed on a running bus.
+var ErrNotReloadable = errors.New("eventbus: option cannot be changed at runtime")
+
+// reloadableFields are the fields of eventBusImpl that Reconfigure copies
+// from the options it is given.
+var reloadableFields = []string{"maxDepth", "maxDepthSet", "health", "muting"}
+
+// Reconfigure changes options of the running bus, so operators can tune a
+// live server without restarting it. It accepts:
+//
+//   - WithMuted, replacing the muted patterns, including those added
+//     with Mute;
+//   - WithHealth, replacing the thresholds of every subscription, on a
+//     bus created with WithHealth; a new Window clears the recorded
+//     outcomes;
+//   - WithMaxPublishDepth.
+//
+// Later options replace earlier ones, as with New. If any option cannot
+// be changed at runtime, Reconfigure returns ErrNotReloadable and changes
+// nothing; the error names the first such option. It is safe for
+// concurrent use.
+//
+// Example:
+//
+//	err := bus.Reconfigure(
+//	    eventbus.WithMuted("telemetry:*"),
+//	    eventbus.WithHealth(eventbus.HealthConfig{SlowThreshold: 50 * time.Millisecond}),
+//	)
+func (bus *eventBusImpl) Reconfigure(opts ...Option) error {
+	// Each option is first applied to an empty bus of its own, so the
+	// error can name the option that is not reloadable.
+	for i, opt := range opts {
+		if probe := (&eventBusImpl{}); !applies(probe, opt) || !reloadable(probe) {
+			return fmt.Errorf("%w: %s (option %d)", ErrNotReloadable, optionName(opt), i+1)
+		}
+	}
+	scratch := &eventBusImpl{}
+	for _, opt := range opts {
+		opt(scratch)
+	}
+	if scratch.health != nil && bus.health == nil {
+		return fmt.Errorf("%w: WithHealth on a bus created without it", ErrNotReloadable)
+	}
+
+	if scratch.maxDepthSet {
+		bus.mutex.Lock()
+		bus.maxDepth = scratch.maxDepth
+		bus.mutex.Unlock()
+	}
+	if scratch.health != nil {
+		bus.reconfigureHealth(scratch.health.settings())
+	}
+	if state := scratch.muting.state.Load(); state != nil {
+		bus.muting.update(func(current *muteState) {
+			current.muted = state.muted
+		})
+	}
+	return nil
+}
+
+// reloadable reports whether scratch only sets reloadableFields.
+func reloadable(scratch *eventBusImpl) bool {
+	value := reflect.ValueOf(scratch).Elem()
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if !slices.Contains(reloadableFields, field.Name) && !value.Field(i).IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// optionName returns the name of the function that created opt, such as
+// "WithStore".
+func optionName(opt Option) string {
+	name := runtime.FuncForPC(reflect.ValueOf(opt).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	name = strings.TrimPrefix(name, "eventbus.")
+	name, _, _ = strings.Cut(name, ".func")
+	name, _, _ = strings.Cut(name, "[")
+	return name
+}
+
+// applies applies opt to scratch, reporting false if it panics, as
+// options setting up state a scratch bus lacks do.
+func applies(scratch *eventBusImpl, opt Option) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	opt(scratch)
+	return true
+}
+
+// WithMuted mutes the topics matching patterns, as Mute does, replacing
+// the patterns muted so far. Given to Reconfigure, it replaces the muted
+// patterns of a running bus; WithMuted() unmutes every topic.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithMuted("physics:*", "telemetry:*"))
+func WithMuted(patterns ...EventType) Option {
+	return func(bus *eventBusImpl) {
+		bus.muting.update(func(state *muteState) {
+			state.muted = slices.Clone(patterns)
+		})
+	}
+}
+
+// reconfigureHealth gives config to the monitor and every subscription.
+func (bus *eventBusImpl) reconfigureHealth(config HealthConfig) {
+	bus.health.mutex.Lock()
+	bus.health.config = config
+	bus.health.mutex.Unlock()
+
+	bus.subscribersMutex.RLock()
+	defer bus.subscribersMutex.RUnlock()
+	for _, sub := range bus.subscriptions {
+		sub.health.reconfigure(config)
+	}
+}
@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestReconfigure verifies that reloadable options change a running bus
+func TestReconfigure(t *testing.T) {
+	bus := New(WithMuted("player:*"), WithHealth(HealthConfig{}))
+	defer bus.Close()
+	count := 0
+	bus.Subscribe("player:health", func(event Event) { count++ })
+	sub := bus.Subscribe("telemetry:fps", func(event Event) { time.Sleep(2 * time.Millisecond) })
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	if count != 0 {
+		t.Errorf("Expected the muted event to be suppressed, got %d deliveries", count)
+	}
+
+	err := bus.Reconfigure(
+		WithMuted(),
+		WithHealth(HealthConfig{SlowThreshold: time.Millisecond}),
+		WithMaxPublishDepth(4),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	if count != 1 {
+		t.Errorf("Expected 1 delivery after unmuting, got %d", count)
+	}
+	bus.Publish(testEvent{eventType: "telemetry:fps"})
+	if sub.Health() != HealthSlow {
+		t.Errorf("Expected the new slow threshold to apply, got %s", sub.Health())
+	}
+}
+
+// TestReconfigureNotReloadable verifies that Reconfigure rejects options
+// it cannot apply at runtime and then changes nothing
+func TestReconfigureNotReloadable(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	tests := map[string][]Option{
+		"flag":   {WithMuted("player:*"), WithCopyOnPublish()},
+		"topics": {WithMuted("player:*"), WithTopicConfig("*", TopicConfig{Async: true})},
+		"health": {WithMuted("player:*"), WithHealth(HealthConfig{})},
+	}
+	for name, opts := range tests {
+		if err := bus.Reconfigure(opts...); !errors.Is(err, ErrNotReloadable) {
+			t.Errorf("%s: Expected ErrNotReloadable, got %v", name, err)
+		}
+	}
+
+	count := 0
+	bus.Subscribe("player:health", func(event Event) { count++ })
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	if count != 1 {
+		t.Errorf("Expected a rejected Reconfigure to change nothing, got %d deliveries", count)
+	}
+	if err := bus.ReadOnly().Reconfigure(WithMuted()); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
+
+// TestReconfigureMaxPublishDepth verifies that any depth limit, including a disabling one, is applied
+func TestReconfigureMaxPublishDepth(t *testing.T) {
+	bus := New(WithMaxPublishDepth(4))
+	defer bus.Close()
+	impl := bus.(*eventBusImpl)
+
+	if err := bus.Reconfigure(WithMuted()); err != nil || impl.maxDepth != 4 {
+		t.Errorf("Expected the limit kept by other options, got %d and %v", impl.maxDepth, err)
+	}
+	for _, n := range []int{-1, 0, 8} {
+		if err := bus.Reconfigure(WithMaxPublishDepth(n)); err != nil || impl.maxDepth != n {
+			t.Errorf("Expected the limit %d, got %d and %v", n, impl.maxDepth, err)
+		}
+	}
+}
+
+// TestReconfigureNamesOption verifies that the error names the option that cannot be changed
+func TestReconfigureNamesOption(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	for option, opts := range map[string][]Option{
+		"WithHeartbeat (option 2)":      {WithMuted(), WithHeartbeat(time.Second)},
+		"WithStore (option 1)":          {WithStore(NewMemoryStore())},
+		"WithLastValueCache (option 1)": {WithLastValueCache("player:health", func(event Event) any { return nil })},
+	} {
+		err := bus.Reconfigure(opts...)
+		if !errors.Is(err, ErrNotReloadable) || !strings.Contains(err.Error(), option) {
+			t.Errorf("Expected ErrNotReloadable naming %s, got %v", option, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ReplayUntil publishes the events in store recorded at or before t into
+// bus, in sequence order. Replaying into a fresh bus with the same systems
+// subscribed reconstructs the state of the world at that moment, which is
+// useful for post-mortem analysis.
+//
+// Replayed events keep their recorded time as Envelope.OriginTime, so
+// listeners subscribed with WithMaxAge skip them.
+//
+// Replay stops at the first envelope recorded after t. The target bus should
+// not persist to the same store, otherwise the replayed events would be
+// appended again.
+//
+// Example:
+//
+//	replayBus := eventbus.New()
+//	world := NewWorldState(replayBus)
+//	err := eventbus.ReplayUntil(store, incidentTime, replayBus)
+func ReplayUntil(store EventStore, t time.Time, bus EventBus) error {
+	return readUntil(store, t, func(envelope Envelope) {
+		bus.PublishContext(WithOriginTime(context.Background(), envelope.OriginTime), envelope.Event)
+	})
+}
+
+// RebuildUntil discards the current state of the projection and replays
+// store up to and including t. The checkpoint is left at the last applied
+// envelope, so a later CatchUp continues from that point in time.
+func (p *Projection[S]) RebuildUntil(store EventStore, t time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.state = p.initial()
+	p.checkpoint = 0
+
+	return readUntil(store, t, p.applyLocked)
+}
+
+// readUntil calls fn for each envelope in store until one is recorded after t.
+func readUntil(store EventStore, t time.Time, fn func(Envelope)) error {
+	err := store.Read(1, func(envelope Envelope) error {
+		if envelope.Time.After(t) {
+			return errStopQuery
+		}
+		fn(envelope)
+		return nil
+	})
+	if errors.Is(err, errStopQuery) {
+		return nil
+	}
+	return err
+}
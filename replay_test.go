@@ -0,0 +1,57 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+// TestReplayUntil verifies that only events up to the given time are replayed
+func TestReplayUntil(t *testing.T) {
+	store := NewMemoryStore()
+	store.Append(testEvent{eventType: "replay:test", data: "a"})
+	store.Append(testEvent{eventType: "replay:test", data: "b"})
+	cutoff := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	store.Append(testEvent{eventType: "replay:test", data: "c"})
+
+	bus := New()
+	var replayed string
+	bus.Subscribe("replay:test", func(event Event) {
+		replayed += event.(testEvent).data
+	})
+
+	if err := ReplayUntil(store, cutoff, bus); err != nil {
+		t.Fatalf("ReplayUntil failed: %v", err)
+	}
+
+	if replayed != "ab" {
+		t.Errorf("Expected 'ab' to be replayed, got '%s'", replayed)
+	}
+}
+
+// TestProjectionRebuildUntil verifies point-in-time projection state
+func TestProjectionRebuildUntil(t *testing.T) {
+	store := NewMemoryStore()
+	store.Append(testEvent{eventType: "item:added"})
+	cutoff := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	store.Append(testEvent{eventType: "item:added"})
+
+	projection := newCountingProjection()
+	projection.CatchUp(store)
+
+	if err := projection.RebuildUntil(store, cutoff); err != nil {
+		t.Fatalf("RebuildUntil failed: %v", err)
+	}
+	if count := projection.State()["item:added"]; count != 1 {
+		t.Errorf("Expected 1 event at cutoff, got %d", count)
+	}
+	if projection.Checkpoint() != 1 {
+		t.Errorf("Expected checkpoint 1, got %d", projection.Checkpoint())
+	}
+
+	projection.CatchUp(store)
+	if count := projection.State()["item:added"]; count != 2 {
+		t.Errorf("Expected 2 events after catching up, got %d", count)
+	}
+}
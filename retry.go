@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy tells WithRetry how to call panicking listeners again.
+type RetryPolicy struct {
+	// Attempts is the number of times a panicking listener is called
+	// again with the same event. Zero disables retries.
+	Attempts int
+	// Backoff is the pause before the first retry, doubled before each
+	// following one. Zero retries at once.
+	Backoff time.Duration
+}
+
+// WithRetry calls listeners that panic again with the same event, up to
+// policy.Attempts more times, so transient failures such as a dropped
+// database connection do not lose the event. If the last attempt panics
+// too, the panic is handled like any listener panic, and only then counts
+// as a failure for WithHealth.
+//
+// The pauses block the delivering goroutine: the publisher, holding the
+// bus lock, on synchronous topics, and a worker on asynchronous ones.
+// Retries stop when the subscription is cancelled.
+//
+// Example:
+//
+//	bus := eventbus.New(
+//	    eventbus.WithAsync(4, 1024),
+//	    eventbus.WithRetry(eventbus.RetryPolicy{Attempts: 3, Backoff: 100 * time.Millisecond}),
+//	)
+func WithRetry(policy RetryPolicy) Option {
+	return func(bus *eventBusImpl) {
+		bus.retry = policy
+	}
+}
+
+// retrying returns a listener calling listener again after a panic, as
+// policy allows, until done is closed.
+func retrying(listener ContextListener, policy RetryPolicy, done <-chan struct{}) ContextListener {
+	if policy.Attempts <= 0 {
+		return listener
+	}
+
+	return func(ctx context.Context, event Event) {
+		backoff := policy.Backoff
+		for range policy.Attempts {
+			if !panics(func() { listener(ctx, event) }) {
+				return
+			}
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-done:
+				timer.Stop()
+				return
+			}
+			backoff *= 2
+		}
+		// The last attempt panics to the caller.
+		listener(ctx, event)
+	}
+}
+
+// panics calls fn and reports whether it panicked.
+func panics(fn func()) (panicked bool) {
+	defer func() {
+		if recover() != nil {
+			panicked = true
+		}
+	}()
+	fn()
+	return false
+}
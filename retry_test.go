@@ -0,0 +1,39 @@
+package eventbus
+
+import "testing"
+
+// TestWithRetry verifies that panicking listeners are called again until
+// they succeed or run out of attempts
+func TestWithRetry(t *testing.T) {
+	bus := New(WithRetry(RetryPolicy{Attempts: 2}))
+	defer bus.Close()
+
+	calls := 0
+	bus.Subscribe("flaky", func(event Event) {
+		calls++
+		if calls < 3 {
+			panic("transient")
+		}
+	})
+	bus.Publish(testEvent{eventType: "flaky"})
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+
+	failures := 0
+	bus.Subscribe("broken", func(event Event) {
+		failures++
+		panic("permanent")
+	})
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected the last attempt to panic")
+			}
+		}()
+		bus.Publish(testEvent{eventType: "broken"})
+	}()
+	if failures != 3 {
+		t.Errorf("Expected 3 calls, got %d", failures)
+	}
+}
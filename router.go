@@ -0,0 +1,155 @@
+package eventbus
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Router maps published event types to the topics listeners subscribed
+// to, so advanced users can plug in their own matching, such as a trie of
+// wildcard patterns or a perfect hash for a fixed set of topics. Configure
+// one with WithRouter; without it, events only reach listeners subscribed
+// to their exact type.
+//
+// The bus calls Add and Remove while holding its subscriber lock for
+// writing, and Match while holding it for reading, so Match may run
+// concurrently with other Match calls but never with Add or Remove.
+type Router interface {
+	// Add is called when topic gets its first subscription.
+	Add(topic EventType)
+	// Remove is called when the last subscription of topic is cancelled.
+	Remove(topic EventType)
+	// Match returns the subscribed topics whose listeners receive events
+	// of eventType. The bus does not modify the returned slice.
+	Match(eventType EventType) []EventType
+}
+
+// WithRouter routes published events to subscribed topics with router.
+// When an event matches several topics, their listeners are combined
+// keeping the order of Match, with unstaged listeners first and staged
+// ones by stage. After and Before only order listeners of the same topic.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithRouter(eventbus.NewWildcardRouter()))
+//	bus.Subscribe("player:*", logPlayerActivity)
+func WithRouter(router Router) Option {
+	return func(bus *eventBusImpl) {
+		bus.router = router
+	}
+}
+
+// matchListeners returns the listeners receiving events of eventType.
+// The caller must hold subscribersMutex.
+func (bus *eventBusImpl) matchListeners(eventType EventType) []*Subscription {
+	if bus.router == nil {
+		return bus.listeners[eventType]
+	}
+
+	topics := bus.router.Match(eventType)
+	switch len(topics) {
+	case 0:
+		return nil
+	case 1:
+		return bus.listeners[topics[0]]
+	}
+
+	var listeners []*Subscription
+	for _, topic := range topics {
+		listeners = append(listeners, bus.listeners[topic]...)
+	}
+	slices.SortStableFunc(listeners, func(a, b *Subscription) int {
+		if a.staged != b.staged {
+			if a.staged {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(a.stage, b.stage)
+	})
+	return listeners
+}
+
+// ExactRouter matches events to the topic equal to their type. It behaves
+// like a bus without a router.
+type ExactRouter struct {
+	topics map[EventType]bool
+}
+
+// NewExactRouter creates a router without topics.
+func NewExactRouter() *ExactRouter {
+	return &ExactRouter{topics: make(map[EventType]bool)}
+}
+
+// Add registers topic.
+func (r *ExactRouter) Add(topic EventType) {
+	r.topics[topic] = true
+}
+
+// Remove unregisters topic.
+func (r *ExactRouter) Remove(topic EventType) {
+	delete(r.topics, topic)
+}
+
+// Match returns eventType if it is registered.
+func (r *ExactRouter) Match(eventType EventType) []EventType {
+	if r.topics[eventType] {
+		return []EventType{eventType}
+	}
+	return nil
+}
+
+// WildcardRouter matches events to the topic equal to their type and to
+// topics containing "*" wildcards, using the pattern syntax of
+// WithTopicConfig. Listeners of the exact topic come first, followed by
+// those of the matching patterns in the order the patterns were first
+// subscribed to.
+type WildcardRouter struct {
+	exact    map[EventType]bool
+	patterns []EventType
+}
+
+// NewWildcardRouter creates a router without topics.
+func NewWildcardRouter() *WildcardRouter {
+	return &WildcardRouter{exact: make(map[EventType]bool)}
+}
+
+// Add registers topic as a pattern if it contains "*", and as an exact
+// topic otherwise.
+func (r *WildcardRouter) Add(topic EventType) {
+	if isPattern(topic) {
+		r.patterns = append(r.patterns, topic)
+	} else {
+		r.exact[topic] = true
+	}
+}
+
+// Remove unregisters topic.
+func (r *WildcardRouter) Remove(topic EventType) {
+	if isPattern(topic) {
+		r.patterns = slices.DeleteFunc(r.patterns, func(p EventType) bool { return p == topic })
+	} else {
+		delete(r.exact, topic)
+	}
+}
+
+// Match returns the registered exact topic and patterns matching
+// eventType.
+func (r *WildcardRouter) Match(eventType EventType) []EventType {
+	var topics []EventType
+	if r.exact[eventType] {
+		topics = append(topics, eventType)
+	}
+	for _, pattern := range r.patterns {
+		if matchPattern(pattern, eventType) {
+			topics = append(topics, pattern)
+		}
+	}
+	return topics
+}
+
+// isPattern reports whether topic contains a wildcard.
+func isPattern(topic EventType) bool {
+	return strings.Contains(string(topic), "*")
+}
@@ -0,0 +1,77 @@
+package eventbus
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestWildcardRouter verifies that pattern subscriptions receive matching events after exact ones
+func TestWildcardRouter(t *testing.T) {
+	bus := New(WithRouter(NewWildcardRouter()))
+
+	var received []string
+	bus.Subscribe("player:*", func(event Event) { received = append(received, "pattern "+string(event.GetType())) })
+	bus.Subscribe("player:jumped", func(event Event) { received = append(received, "exact") })
+
+	bus.Publish(testEvent{eventType: "player:jumped"})
+	bus.Publish(testEvent{eventType: "player:died"})
+	bus.Publish(testEvent{eventType: "enemy:died"})
+
+	want := []string{"exact", "pattern player:jumped", "pattern player:died"}
+	if !slices.Equal(received, want) {
+		t.Errorf("Expected %v, got %v", want, received)
+	}
+}
+
+// TestWildcardRouterStages verifies that combined listeners keep stage order
+func TestWildcardRouterStages(t *testing.T) {
+	bus := New(WithRouter(NewWildcardRouter()))
+
+	var received []string
+	bus.Subscribe("frame:tick", func(event Event) { received = append(received, "draw") }, WithStage(1))
+	bus.Subscribe("frame:*", func(event Event) { received = append(received, "log") })
+	bus.Subscribe("frame:*", func(event Event) { received = append(received, "step") }, WithStage(0))
+
+	bus.Publish(testEvent{eventType: "frame:tick"})
+
+	want := []string{"log", "step", "draw"}
+	if !slices.Equal(received, want) {
+		t.Errorf("Expected %v, got %v", want, received)
+	}
+}
+
+// TestRouterRemove verifies that cancelling the last subscription removes the topic
+func TestRouterRemove(t *testing.T) {
+	router := NewWildcardRouter()
+	bus := New(WithRouter(router))
+
+	first := bus.Subscribe("player:*", func(event Event) {})
+	second := bus.Subscribe("player:*", func(event Event) {})
+	exact := bus.Subscribe("player:jumped", func(event Event) {})
+
+	first.Cancel()
+	if topics := router.Match("player:jumped"); len(topics) != 2 {
+		t.Errorf("Expected both topics to stay registered, got %v", topics)
+	}
+
+	second.Cancel()
+	exact.Cancel()
+	exact.Cancel()
+	if topics := router.Match("player:jumped"); len(topics) != 0 {
+		t.Errorf("Expected no topics, got %v", topics)
+	}
+}
+
+// TestExactRouter verifies that the exact router behaves like a bus without a router
+func TestExactRouter(t *testing.T) {
+	bus := New(WithRouter(NewExactRouter()))
+
+	count := 0
+	bus.Subscribe("player:*", func(event Event) { count += 10 })
+	bus.Subscribe("player:jumped", func(event Event) { count++ })
+
+	bus.Publish(testEvent{eventType: "player:jumped"})
+	if count != 1 {
+		t.Errorf("Expected only the exact listener, got %d", count)
+	}
+}
@@ -0,0 +1,158 @@
+// Package s3blob implements eventbus.BlobStore on top of S3-compatible
+// object storage such as AWS S3, MinIO, or Cloudflare R2.
+//
+// It talks to the storage service directly over HTTP with AWS Signature
+// Version 4, so it adds no dependencies beyond the Go standard library.
+//
+// Example:
+//
+//	blobs := s3blob.New("https://s3.eu-central-1.amazonaws.com", "event-archive",
+//	    "eu-central-1", os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"))
+//	archiver := eventbus.NewArchiver(store, blobs, 24*time.Hour)
+package s3blob
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Papiermond/eventbus/internal/sigv4"
+)
+
+// Store is a BlobStore backed by a bucket in S3-compatible storage.
+// Objects are addressed path-style: Endpoint/Bucket/name.
+type Store struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+
+	// HTTPClient is used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	now func() time.Time
+}
+
+// New creates a store for bucket at endpoint using static credentials.
+func New(endpoint, bucket, region, accessKey, secretKey string) *Store {
+	return &Store{
+		Endpoint:  strings.TrimRight(endpoint, "/"),
+		Bucket:    bucket,
+		Region:    region,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	}
+}
+
+// Put uploads the content of r as object name.
+func (s *Store) Put(ctx context.Context, name string, r io.Reader) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, name, nil, body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get downloads object name. The caller must close the returned reader.
+func (s *Store) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// listResult is the subset of a ListObjectsV2 response used by List.
+type listResult struct {
+	Contents []struct {
+		Key string
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List returns the names of all objects starting with prefix.
+func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result listResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3blob: decoding list response: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			names = append(names, object.Key)
+		}
+		if !result.IsTruncated {
+			return names, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// do sends a signed request and returns the response if it succeeded.
+func (s *Store) do(ctx context.Context, method, name string, query url.Values, body []byte) (*http.Response, error) {
+	target := s.Endpoint + "/" + sigv4.URIEncode(s.Bucket, false)
+	if name != "" {
+		target += "/" + sigv4.URIEncode(name, false)
+	}
+	if len(query) > 0 {
+		target += "?" + sigv4.CanonicalQuery(query)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	s.sign(req, body, now())
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3blob: %s %s: %s: %s", method, name, resp.Status, bytes.TrimSpace(message))
+	}
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req. Every header already
+// present on the request is signed, together with the host.
+func (s *Store) sign(req *http.Request, body []byte, now time.Time) {
+	sigv4.Sign(req, body, now, s.Region, "s3", sigv4.Credentials{AccessKey: s.AccessKey, SecretKey: s.SecretKey})
+}
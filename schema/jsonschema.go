@@ -0,0 +1,188 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// JSONSchema is a JSON Schema limited to the keywords that describe the
+// shape of event payloads: type, properties, required, items, enum, and
+// additionalProperties. Other keywords are ignored.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// ParseJSONSchema parses a JSON Schema document.
+func ParseJSONSchema(data []byte) (*JSONSchema, error) {
+	var s JSONSchema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("schema: parsing JSON Schema: %w", err)
+	}
+	return &s, nil
+}
+
+// Format returns "json-schema".
+func (s *JSONSchema) Format() string {
+	return "json-schema"
+}
+
+// CheckBackward returns an error matching ErrIncompatible if a payload
+// valid under previous may be invalid under s: a type changes, a field
+// becomes required, an enum loses values, or a field is removed while
+// additional properties are forbidden. Adding optional fields and widening
+// integer to number are allowed.
+func (s *JSONSchema) CheckBackward(previous Schema) error {
+	old, ok := previous.(*JSONSchema)
+	if !ok {
+		return fmt.Errorf("%w: previous schema is %T", ErrIncompatible, previous)
+	}
+	return s.checkBackward(old, "$")
+}
+
+// checkBackward compares s with old at the given path.
+func (s *JSONSchema) checkBackward(old *JSONSchema, path string) error {
+	if s.Type != "" && s.Type != old.Type && !(s.Type == "number" && old.Type == "integer") {
+		if old.Type == "" {
+			return fmt.Errorf("%w: %s is restricted to %s", ErrIncompatible, path, s.Type)
+		}
+		return fmt.Errorf("%w: %s changes type from %s to %s", ErrIncompatible, path, old.Type, s.Type)
+	}
+
+	for _, name := range s.Required {
+		if !slices.Contains(old.Required, name) {
+			return fmt.Errorf("%w: %s.%s becomes required", ErrIncompatible, path, name)
+		}
+	}
+
+	if s.Enum != nil {
+		if old.Enum == nil {
+			return fmt.Errorf("%w: %s is restricted to an enum", ErrIncompatible, path)
+		}
+		for _, value := range old.Enum {
+			if !containsValue(s.Enum, value) {
+				return fmt.Errorf("%w: %s no longer allows %v", ErrIncompatible, path, value)
+			}
+		}
+	}
+
+	closed := s.AdditionalProperties != nil && !*s.AdditionalProperties
+	wasClosed := old.AdditionalProperties != nil && !*old.AdditionalProperties
+	if closed && !wasClosed {
+		return fmt.Errorf("%w: %s forbids additional properties", ErrIncompatible, path)
+	}
+	for _, name := range sortedKeys(old.Properties) {
+		property, ok := s.Properties[name]
+		if !ok {
+			if closed {
+				return fmt.Errorf("%w: %s.%s is removed", ErrIncompatible, path, name)
+			}
+			continue
+		}
+		if err := property.checkBackward(old.Properties[name], path+"."+name); err != nil {
+			return err
+		}
+	}
+
+	if s.Items != nil {
+		if old.Items == nil {
+			return s.Items.checkBackward(&JSONSchema{}, path+"[]")
+		}
+		return s.Items.checkBackward(old.Items, path+"[]")
+	}
+	return nil
+}
+
+// Validate returns an error matching ErrInvalid if data is not JSON or
+// does not match s.
+func (s *JSONSchema) Validate(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return s.validate(value, "$")
+}
+
+// validate checks a decoded JSON value at the given path.
+func (s *JSONSchema) validate(value any, path string) error {
+	if s.Type != "" && !hasType(value, s.Type) {
+		return fmt.Errorf("%w: %s is not of type %s", ErrInvalid, path, s.Type)
+	}
+	if s.Enum != nil && !containsValue(s.Enum, value) {
+		return fmt.Errorf("%w: %s is not one of %v", ErrInvalid, path, s.Enum)
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				return fmt.Errorf("%w: %s.%s is required", ErrInvalid, path, name)
+			}
+		}
+		for _, name := range sortedKeys(value) {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fmt.Errorf("%w: %s.%s is not allowed", ErrInvalid, path, name)
+				}
+				continue
+			}
+			if err := property.validate(value[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range value {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasType reports whether a decoded JSON value has the JSON Schema type t.
+func hasType(value any, t string) bool {
+	switch value := value.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case float64:
+		return t == "number" || t == "integer" && value == math.Trunc(value)
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
+
+// containsValue reports whether values contains value, comparing decoded
+// JSON values.
+func containsValue(values []any, value any) bool {
+	return slices.ContainsFunc(values, func(v any) bool {
+		return reflect.DeepEqual(v, value)
+	})
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestJSONSchemaCheckBackward verifies the compatibility rules
+func TestJSONSchemaCheckBackward(t *testing.T) {
+	tests := []struct {
+		name       string
+		old, new   string
+		compatible bool
+	}{
+		{"add optional field", `{"type": "object", "properties": {"id": {"type": "string"}}}`, `{"type": "object", "properties": {"id": {"type": "string"}, "note": {"type": "string"}}}`, true},
+		{"drop required", `{"type": "object", "required": ["id"]}`, `{"type": "object"}`, true},
+		{"widen integer", `{"type": "object", "properties": {"n": {"type": "integer"}}}`, `{"type": "object", "properties": {"n": {"type": "number"}}}`, true},
+		{"extend enum", `{"type": "string", "enum": ["a"]}`, `{"type": "string", "enum": ["a", "b"]}`, true},
+		{"remove open field", `{"type": "object", "properties": {"id": {"type": "string"}}}`, `{"type": "object"}`, true},
+		{"change type", `{"type": "object", "properties": {"id": {"type": "string"}}}`, `{"type": "object", "properties": {"id": {"type": "integer"}}}`, false},
+		{"narrow number", `{"type": "number"}`, `{"type": "integer"}`, false},
+		{"new required", `{"type": "object"}`, `{"type": "object", "required": ["id"]}`, false},
+		{"shrink enum", `{"type": "string", "enum": ["a", "b"]}`, `{"type": "string", "enum": ["a"]}`, false},
+		{"close object", `{"type": "object"}`, `{"type": "object", "additionalProperties": false}`, false},
+		{"remove closed field", `{"type": "object", "properties": {"id": {"type": "string"}}, "additionalProperties": false}`, `{"type": "object", "additionalProperties": false}`, false},
+		{"change item type", `{"type": "array", "items": {"type": "string"}}`, `{"type": "array", "items": {"type": "number"}}`, false},
+		{"nested required", `{"type": "object", "properties": {"a": {"type": "object"}}}`, `{"type": "object", "properties": {"a": {"type": "object", "required": ["b"]}}}`, false},
+	}
+
+	for _, test := range tests {
+		old, _ := ParseJSONSchema([]byte(test.old))
+		updated, _ := ParseJSONSchema([]byte(test.new))
+		err := updated.CheckBackward(old)
+		if test.compatible && err != nil {
+			t.Errorf("Expected %s to be compatible, got %v", test.name, err)
+		}
+		if !test.compatible && !errors.Is(err, ErrIncompatible) {
+			t.Errorf("Expected %s to be incompatible, got %v", test.name, err)
+		}
+	}
+}
+
+// TestJSONSchemaValidate verifies that payloads are checked against the schema
+func TestJSONSchemaValidate(t *testing.T) {
+	s, err := ParseJSONSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string"},
+			"count": {"type": "integer"},
+			"status": {"enum": ["open", "closed"]},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["id"],
+		"additionalProperties": false
+	}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	valid := []string{
+		`{"id": "a"}`,
+		`{"id": "a", "count": 2, "status": "open", "tags": ["x", "y"]}`,
+	}
+	for _, data := range valid {
+		if err := s.Validate([]byte(data)); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", data, err)
+		}
+	}
+
+	invalid := []string{
+		`not json`,
+		`[]`,
+		`{}`,
+		`{"id": 1}`,
+		`{"id": "a", "count": 1.5}`,
+		`{"id": "a", "status": "lost"}`,
+		`{"id": "a", "tags": ["x", 2]}`,
+		`{"id": "a", "extra": true}`,
+	}
+	for _, data := range invalid {
+		if err := s.Validate([]byte(data)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Expected %s to be invalid, got %v", data, err)
+		}
+	}
+}
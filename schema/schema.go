@@ -0,0 +1,186 @@
+// Package schema keeps versioned schemas of event payloads and refuses new
+// versions that would break existing consumers, so producers in different
+// services cannot silently change an event's shape.
+//
+// Schemas implement the Schema interface. JSONSchema supports the commonly
+// used subset of JSON Schema; other languages, such as protobuf
+// descriptors, can be added by implementing Schema.
+//
+// Example:
+//
+//	registry := schema.NewRegistry()
+//	v1, _ := schema.ParseJSONSchema([]byte(`{
+//	    "type": "object",
+//	    "properties": {"id": {"type": "string"}, "total": {"type": "number"}},
+//	    "required": ["id"]
+//	}`))
+//	registry.Register("order:placed", v1)
+//
+//	// Adding a required field breaks consumers of old events.
+//	v2, _ := schema.ParseJSONSchema([]byte(`{"type": "object", "required": ["id", "currency"]}`))
+//	_, err := registry.Register("order:placed", v2) // errors.Is(err, schema.ErrIncompatible)
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// Errors returned by the registry.
+var (
+	// ErrIncompatible is matched by errors rejecting a schema that cannot
+	// read data written with the previous version.
+	ErrIncompatible = errors.New("schema: incompatible with previous version")
+
+	// ErrNotFound is returned for event types without a schema.
+	ErrNotFound = errors.New("schema: no schema registered")
+
+	// ErrInvalid is matched by errors reporting data that does not match
+	// its schema.
+	ErrInvalid = errors.New("schema: invalid data")
+)
+
+// Schema describes the payload of an event type.
+type Schema interface {
+	// Format names the schema language, such as "json-schema".
+	Format() string
+	// CheckBackward returns an error matching ErrIncompatible if data
+	// written with previous cannot be read with this schema. previous has
+	// the same format.
+	CheckBackward(previous Schema) error
+}
+
+// Validator is implemented by schemas that can check encoded payloads.
+type Validator interface {
+	// Validate returns an error matching ErrInvalid if data does not
+	// match the schema.
+	Validate(data []byte) error
+}
+
+// Version is a registered schema.
+type Version struct {
+	// EventType is the event type the schema describes.
+	EventType eventbus.EventType
+	// Version counts the schemas of the event type, starting at 1.
+	Version int
+	// Schema is the schema.
+	Schema Schema
+	// Registered is when the version was registered.
+	Registered time.Time
+}
+
+// Registry stores the schema versions of event types. It is safe for
+// concurrent use.
+type Registry struct {
+	versions map[eventbus.EventType][]Version
+	mutex    sync.RWMutex
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{versions: make(map[eventbus.EventType][]Version)}
+}
+
+// Register adds schema as the next version of eventType, unless it is
+// equal to the latest version, which is then returned unchanged. It returns
+// an error matching ErrIncompatible if schema uses another format than the
+// latest version or cannot read data written with it.
+func (r *Registry) Register(eventType eventbus.EventType, schema Schema) (Version, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	versions := r.versions[eventType]
+	if len(versions) > 0 {
+		latest := versions[len(versions)-1]
+		if reflect.DeepEqual(latest.Schema, schema) {
+			return latest, nil
+		}
+		if latest.Schema.Format() != schema.Format() {
+			return Version{}, fmt.Errorf("%w: %q changes format from %s to %s",
+				ErrIncompatible, eventType, latest.Schema.Format(), schema.Format())
+		}
+		if err := schema.CheckBackward(latest.Schema); err != nil {
+			return Version{}, fmt.Errorf("%q version %d: %w", eventType, latest.Version+1, err)
+		}
+	}
+
+	version := Version{
+		EventType:  eventType,
+		Version:    len(versions) + 1,
+		Schema:     schema,
+		Registered: time.Now(),
+	}
+	r.versions[eventType] = append(versions, version)
+	return version, nil
+}
+
+// Latest returns the latest version of eventType.
+func (r *Registry) Latest(eventType eventbus.EventType) (Version, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	versions := r.versions[eventType]
+	if len(versions) == 0 {
+		return Version{}, false
+	}
+	return versions[len(versions)-1], true
+}
+
+// Version returns version n of eventType.
+func (r *Registry) Version(eventType eventbus.EventType, n int) (Version, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	versions := r.versions[eventType]
+	if n < 1 || n > len(versions) {
+		return Version{}, false
+	}
+	return versions[n-1], true
+}
+
+// Versions returns the versions of eventType, oldest first.
+func (r *Registry) Versions(eventType eventbus.EventType) []Version {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return slices.Clone(r.versions[eventType])
+}
+
+// EventTypes returns the event types with a schema in sorted order.
+func (r *Registry) EventTypes() []eventbus.EventType {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	eventTypes := make([]eventbus.EventType, 0, len(r.versions))
+	for eventType := range r.versions {
+		eventTypes = append(eventTypes, eventType)
+	}
+	slices.Sort(eventTypes)
+	return eventTypes
+}
+
+// Validate checks data against the latest schema of eventType. It returns
+// ErrNotFound if eventType has no schema, and nil if the schema does not
+// implement Validator.
+//
+// Example:
+//
+//	data, _ := codecs.Encode(event)
+//	if err := registry.Validate(event.GetType(), data); err != nil {
+//	    return err
+//	}
+func (r *Registry) Validate(eventType eventbus.EventType, data []byte) error {
+	latest, ok := r.Latest(eventType)
+	if !ok {
+		return fmt.Errorf("%w for %q", ErrNotFound, eventType)
+	}
+	if validator, ok := latest.Schema.(Validator); ok {
+		return validator.Validate(data)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+// mustParse parses a JSON Schema or fails the test
+func mustParse(t *testing.T, document string) *JSONSchema {
+	t.Helper()
+	s, err := ParseJSONSchema([]byte(document))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return s
+}
+
+// textSchema is a schema of another format
+type textSchema string
+
+func (textSchema) Format() string                      { return "text" }
+func (textSchema) CheckBackward(previous Schema) error { return nil }
+
+// TestRegistryVersions verifies that compatible schemas become new versions
+func TestRegistryVersions(t *testing.T) {
+	registry := NewRegistry()
+
+	v1 := mustParse(t, `{"type": "object", "properties": {"id": {"type": "string"}}, "required": ["id"]}`)
+	v2 := mustParse(t, `{"type": "object", "properties": {"id": {"type": "string"}, "note": {"type": "string"}}, "required": ["id"]}`)
+
+	first, err := registry.Register("order:placed", v1)
+	if err != nil || first.Version != 1 {
+		t.Fatalf("Expected version 1, got %d and %v", first.Version, err)
+	}
+	second, err := registry.Register("order:placed", v2)
+	if err != nil || second.Version != 2 {
+		t.Fatalf("Expected version 2, got %d and %v", second.Version, err)
+	}
+	again, err := registry.Register("order:placed", mustParse(t, `{"type": "object", "properties": {"id": {"type": "string"}, "note": {"type": "string"}}, "required": ["id"]}`))
+	if err != nil || again.Version != 2 {
+		t.Errorf("Expected re-registering the latest schema to return version 2, got %d and %v", again.Version, err)
+	}
+
+	if latest, ok := registry.Latest("order:placed"); !ok || latest.Schema != v2 {
+		t.Errorf("Expected the latest version to be v2, got %+v", latest)
+	}
+	if version, ok := registry.Version("order:placed", 1); !ok || version.Schema != v1 {
+		t.Errorf("Expected version 1 to be v1, got %+v", version)
+	}
+	if versions := registry.Versions("order:placed"); len(versions) != 2 {
+		t.Errorf("Expected 2 versions, got %d", len(versions))
+	}
+	if types := registry.EventTypes(); len(types) != 1 || types[0] != "order:placed" {
+		t.Errorf("Expected [order:placed], got %v", types)
+	}
+}
+
+// TestRegistryRejectsIncompatible verifies that breaking changes are refused
+func TestRegistryRejectsIncompatible(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("order:placed", mustParse(t, `{"type": "object", "properties": {"id": {"type": "string"}}, "required": ["id"]}`))
+
+	if _, err := registry.Register("order:placed", textSchema("id")); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("Expected ErrIncompatible changing format, got %v", err)
+	}
+	if _, err := registry.Register("order:placed", mustParse(t, `{"type": "object", "required": ["id", "currency"]}`)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("Expected ErrIncompatible adding a required field, got %v", err)
+	}
+	if versions := registry.Versions("order:placed"); len(versions) != 1 {
+		t.Errorf("Expected rejected schemas not to be registered, got %d versions", len(versions))
+	}
+}
+
+// TestRegistryValidate verifies that payloads are checked against the latest schema
+func TestRegistryValidate(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("order:placed", mustParse(t, `{"type": "object", "properties": {"id": {"type": "string"}}, "required": ["id"]}`))
+
+	if err := registry.Validate("order:placed", []byte(`{"id": "o-1"}`)); err != nil {
+		t.Errorf("Expected a valid payload, got %v", err)
+	}
+	if err := registry.Validate("order:placed", []byte(`{"id": 1}`)); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid, got %v", err)
+	}
+	if err := registry.Validate("order:shipped", []byte(`{}`)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+)
+
+// SchedulerSeededType is the event type of the event recorded by a bus
+// created with WithSeededScheduling.
+const SchedulerSeededType EventType = "eventbus:scheduler_seeded"
+
+// SchedulerSeeded records the seed of a bus created with
+// WithSeededScheduling. It is published when the bus is created, so it
+// is the first event in the bus's store.
+type SchedulerSeeded struct {
+	Seed uint64
+}
+
+// GetType returns SchedulerSeededType.
+func (e SchedulerSeeded) GetType() EventType {
+	return SchedulerSeededType
+}
+
+// SeededDispatcher queues deliveries until Run runs them one at a time in
+// an order chosen by a pseudo-random generator, so a race-dependent bug
+// found with one seed reproduces with the same seed. Deliveries dispatched
+// while Run is running, including nested events published by listeners,
+// join the pending set it picks from. The order is reproducible as long
+// as events enter the bus from a single goroutine, such as a TapePlayer.
+//
+// A bus created with WithSeededScheduling calls Run after every publish.
+// Listeners must not wait for the delivery of other events on the same
+// dispatcher, for example with PublishAndWait, since those only run
+// after the listener returns.
+type SeededDispatcher struct {
+	seed    uint64
+	random  *rand.Rand
+	pending []func()
+	running bool
+	// idle is signalled when running is cleared.
+	idle  *sync.Cond
+	mutex sync.Mutex
+}
+
+// NewSeededDispatcher creates a dispatcher whose order is driven by seed.
+func NewSeededDispatcher(seed uint64) *SeededDispatcher {
+	d := &SeededDispatcher{
+		seed:   seed,
+		random: rand.New(rand.NewPCG(seed, seed)),
+	}
+	d.idle = sync.NewCond(&d.mutex)
+	return d
+}
+
+// Seed returns the seed given to NewSeededDispatcher.
+func (d *SeededDispatcher) Seed() uint64 {
+	return d.seed
+}
+
+// Dispatch adds deliver to the pending deliveries.
+func (d *SeededDispatcher) Dispatch(ctx context.Context, event Event, deliver func()) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.pending = append(d.pending, deliver)
+	return nil
+}
+
+// Run runs pending deliveries in seeded order until none are left. If
+// another goroutine is already running them, it returns immediately and
+// that goroutine runs the new deliveries too.
+func (d *SeededDispatcher) Run() {
+	d.mutex.Lock()
+	if d.running {
+		d.mutex.Unlock()
+		return
+	}
+
+	d.running = true
+	for len(d.pending) > 0 {
+		i := d.random.IntN(len(d.pending))
+		next := d.pending[i]
+		// Keep the remaining order stable so later picks only depend
+		// on the seed and the dispatch order.
+		d.pending = append(d.pending[:i], d.pending[i+1:]...)
+		d.mutex.Unlock()
+		next()
+		d.mutex.Lock()
+	}
+	d.running = false
+	d.idle.Broadcast()
+	d.mutex.Unlock()
+}
+
+// Len returns the number of pending deliveries.
+func (d *SeededDispatcher) Len() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return len(d.pending)
+}
+
+// Close runs the pending deliveries and waits for a concurrent Run to
+// finish.
+func (d *SeededDispatcher) Close() {
+	d.Run()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for d.running {
+		d.idle.Wait()
+	}
+}
+
+// WithSeededScheduling delivers every topic without its own TopicConfig
+// through a SeededDispatcher, so the interleaving of deliveries can be
+// reproduced. The seed is recorded as a SchedulerSeeded event, which
+// RecordedSeed finds in a store or imported History.
+//
+// The timers of WithHeartbeat, WithStatsInterval, and Watchdogs of the
+// bus join the pending deliveries when they fire, so timers firing
+// together, and the deliveries pending at the time, run in seeded order.
+// When a timer fires is still a matter of wall-clock time, so the events
+// they publish are only reproducible when replayed from a store, with the
+// timers disabled on the replaying bus.
+//
+// Example:
+//
+//	// Replay a recorded session with the interleaving it had
+//	seed, _ := eventbus.RecordedSeed(store)
+//	bus := eventbus.New(eventbus.WithSeededScheduling(seed))
+//	eventbus.NewTapePlayer(store, bus).Run(ctx)
+func WithSeededScheduling(seed uint64) Option {
+	return func(bus *eventBusImpl) {
+		bus.scheduler = NewSeededDispatcher(seed)
+		bus.dispatch.fallback.config = TopicConfig{Dispatcher: bus.scheduler}
+	}
+}
+
+// fireTimer runs fire, the callback of a timer, or with
+// WithSeededScheduling adds it to the pending deliveries of the
+// scheduler and runs them.
+func (bus *eventBusImpl) fireTimer(fire func()) {
+	if bus.scheduler == nil {
+		fire()
+		return
+	}
+	bus.scheduler.Dispatch(context.Background(), nil, fire)
+	bus.scheduler.Run()
+}
+
+// errSeedFound stops reading the store once the seed is found.
+var errSeedFound = errors.New("eventbus: seed found")
+
+// RecordedSeed returns the seed of the first SchedulerSeeded event in
+// store, including one imported as a RawEvent.
+func RecordedSeed(store EventStore) (uint64, bool) {
+	var seed uint64
+	err := store.Read(1, func(envelope Envelope) error {
+		if envelope.Event.GetType() != SchedulerSeededType {
+			return nil
+		}
+		if seeded, ok := Payload[SchedulerSeeded](envelope.Event); ok {
+			seed = seeded.Seed
+			return errSeedFound
+		}
+		return nil
+	})
+	return seed, errors.Is(err, errSeedFound)
+}
@@ -0,0 +1,144 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+)
+
+// seededRun publishes a fixed sequence of events on a bus scheduled with
+// seed and returns the order in which listeners ran.
+func seededRun(seed uint64) []string {
+	bus := New(WithSeededScheduling(seed))
+	var order []string
+	for _, name := range []string{"physics", "audio", "score"} {
+		bus.Subscribe("player:moved", func(event Event) {
+			order = append(order, name)
+			if name == "physics" {
+				bus.Publish(testEvent{eventType: "player:landed"})
+			}
+		})
+	}
+	bus.Subscribe("player:landed", func(event Event) { order = append(order, "landed") })
+
+	for range 5 {
+		bus.Publish(testEvent{eventType: "player:moved"})
+	}
+	bus.Close()
+	return order
+}
+
+// TestSeededSchedulingReproducible verifies that a seed reproduces the delivery order
+func TestSeededSchedulingReproducible(t *testing.T) {
+	first := seededRun(1)
+	if len(first) != 20 {
+		t.Fatalf("Expected 20 deliveries, got %d", len(first))
+	}
+	for range 5 {
+		if again := seededRun(1); !slices.Equal(first, again) {
+			t.Fatalf("Expected the same order for the same seed, got %v and %v", first, again)
+		}
+	}
+
+	differs := false
+	for seed := uint64(2); seed < 20 && !differs; seed++ {
+		differs = !slices.Equal(first, seededRun(seed))
+	}
+	if !differs {
+		t.Error("Expected other seeds to produce other orders")
+	}
+}
+
+// seededTimers fires timers while a listener of a bus scheduled with seed
+// runs and returns the order in which they and the deliveries ran.
+func seededTimers(seed uint64) []string {
+	bus := New(WithSeededScheduling(seed))
+	impl := bus.(*eventBusImpl)
+	var order []string
+	bus.Subscribe("tick:due", func(event Event) {
+		for _, name := range []string{"heartbeat", "stats", "watchdog"} {
+			impl.fireTimer(func() { order = append(order, name) })
+		}
+		bus.Publish(testEvent{eventType: "tick:handled"})
+		order = append(order, "due")
+	})
+	bus.Subscribe("tick:handled", func(event Event) { order = append(order, "handled") })
+
+	bus.Publish(testEvent{eventType: "tick:due"})
+	bus.Close()
+	return order
+}
+
+// TestSeededSchedulingTimers verifies that timers fire through the scheduler in seeded order
+func TestSeededSchedulingTimers(t *testing.T) {
+	first := seededTimers(1)
+	if len(first) != 5 || first[0] != "due" {
+		t.Fatalf("Expected the timers to wait for the running delivery, got %v", first)
+	}
+	if again := seededTimers(1); !slices.Equal(first, again) {
+		t.Errorf("Expected the same order for the same seed, got %v and %v", first, again)
+	}
+
+	differs := false
+	for seed := uint64(2); seed < 20 && !differs; seed++ {
+		differs = !slices.Equal(first, seededTimers(seed))
+	}
+	if !differs {
+		t.Error("Expected other seeds to produce other orders")
+	}
+}
+
+// TestRecordedSeed verifies that the seed is recorded in the store and survives export
+func TestRecordedSeed(t *testing.T) {
+	store := NewMemoryStore()
+	bus := New(WithStore(store), WithSeededScheduling(42))
+	bus.Publish(testEvent{eventType: "player:moved"})
+	bus.Close()
+
+	if seed, ok := RecordedSeed(store); !ok || seed != 42 {
+		t.Errorf("Expected seed 42, got %d (%v)", seed, ok)
+	}
+
+	var exported bytes.Buffer
+	if err := bus.History().Export(&exported); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	imported := NewMemoryStore()
+	importer := New(WithStore(imported))
+	if err := importer.History().Import(&exported); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if seed, ok := RecordedSeed(imported); !ok || seed != 42 {
+		t.Errorf("Expected seed 42 after import, got %d (%v)", seed, ok)
+	}
+
+	if _, ok := RecordedSeed(NewMemoryStore()); ok {
+		t.Error("Expected no seed in an empty store")
+	}
+}
+
+// TestSeededDispatcherClose verifies that Close waits for running deliveries
+func TestSeededDispatcherClose(t *testing.T) {
+	d := NewSeededDispatcher(7)
+	started, release := make(chan struct{}), make(chan struct{})
+	ran := 0
+	d.Dispatch(context.Background(), nil, func() {
+		close(started)
+		<-release
+		ran++
+	})
+	go d.Run()
+	<-started
+	d.Dispatch(context.Background(), nil, func() { ran++ })
+	d.Run()
+	if d.Len() != 1 {
+		t.Errorf("Expected 1 pending delivery, got %d", d.Len())
+	}
+
+	close(release)
+	d.Close()
+	if ran != 2 || d.Seed() != 7 {
+		t.Errorf("Expected 2 deliveries with seed 7, got %d with seed %d", ran, d.Seed())
+	}
+}
@@ -0,0 +1,215 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BridgeLaggingType is the event type of the events published when a
+// bridge's send buffer fills up.
+const BridgeLaggingType EventType = "eventbus:bridge_lagging"
+
+// BridgeLagging reports that the remote side of a bridge is not keeping up.
+// It is published once each time the buffer reaches its lag threshold, and
+// again only after the buffer has drained to half the threshold.
+type BridgeLagging struct {
+	// Bridge names the bridge.
+	Bridge string
+	// Buffered is the number of events waiting to be sent.
+	Buffered int
+	// Capacity is the size of the buffer.
+	Capacity int
+	// Dropped is the number of events the buffer has dropped so far.
+	Dropped uint64
+	// Time is when the threshold was reached.
+	Time time.Time
+}
+
+// GetType returns BridgeLaggingType.
+func (e BridgeLagging) GetType() EventType {
+	return BridgeLaggingType
+}
+
+// BufferConfig configures a SendBuffer. The zero value buffers 1024 events,
+// blocks when full, and reports lagging at three quarters full.
+type BufferConfig struct {
+	// Size is the number of events the buffer holds.
+	Size int
+	// Overflow decides what happens when the buffer is full.
+	Overflow OverflowPolicy
+	// LagThreshold is the number of buffered events at which a
+	// BridgeLagging event is published.
+	LagThreshold int
+}
+
+// SendBuffer is a bounded queue of events waiting to be sent to the remote
+// side of a bridge. A goroutine takes events from the buffer and passes
+// them to the send function, so a slow remote consumer fills the buffer
+// instead of stalling the bridge's listeners, up to the configured size.
+//
+// Example:
+//
+//	buffer := eventbus.NewSendBuffer(bus, "nats", eventbus.BufferConfig{
+//	    Size:     4096,
+//	    Overflow: eventbus.OverflowDropOldest,
+//	}, func(event eventbus.Event) {
+//	    conn.Publish(subject, encode(event))
+//	})
+//	bus.Subscribe("player:moved", func(event eventbus.Event) {
+//	    buffer.Push(context.Background(), event)
+//	})
+type SendBuffer struct {
+	bus       EventBus
+	name      string
+	config    BufferConfig
+	send      func(Event)
+	queue     chan Event
+	dropped   atomic.Uint64
+	lagging   atomic.Bool
+	closed    bool
+	pushing   sync.WaitGroup
+	mutex     sync.Mutex
+	sending   sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewSendBuffer creates a buffer passing events to send on its own
+// goroutine, one at a time. BridgeLagging events name the buffer bridge
+// and are published on bus, unless bus is nil.
+func NewSendBuffer(bus EventBus, bridge string, config BufferConfig, send func(Event)) *SendBuffer {
+	if config.Size <= 0 {
+		config.Size = 1024
+	}
+	if config.LagThreshold <= 0 || config.LagThreshold > config.Size {
+		config.LagThreshold = max(config.Size*3/4, 1)
+	}
+
+	b := &SendBuffer{
+		bus:    bus,
+		name:   bridge,
+		config: config,
+		send:   send,
+		queue:  make(chan Event, config.Size),
+	}
+	b.sending.Add(1)
+	go b.run()
+	return b
+}
+
+// Push adds event to the buffer, applying the overflow policy when it is
+// full. With OverflowBlock it waits for room and returns the context error
+// if ctx is done first. Pushing after Close returns ErrBufferClosed.
+func (b *SendBuffer) Push(ctx context.Context, event Event) error {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return ErrBufferClosed
+	}
+	b.pushing.Add(1)
+	b.mutex.Unlock()
+	defer b.pushing.Done()
+
+	switch b.config.Overflow {
+	case OverflowDropNewest:
+		select {
+		case b.queue <- event:
+		default:
+			b.drop(event)
+		}
+
+	case OverflowDropOldest:
+		for queued := false; !queued; {
+			select {
+			case b.queue <- event:
+				queued = true
+			default:
+				select {
+				case oldest := <-b.queue:
+					b.drop(oldest)
+				default:
+				}
+			}
+		}
+
+	default:
+		select {
+		case b.queue <- event:
+		case <-ctx.Done():
+			b.drop(event)
+			b.checkLag()
+			return contextError(ctx)
+		}
+	}
+
+	b.checkLag()
+	return nil
+}
+
+// drop counts a discarded event, also in the Stats of the bus and its
+// WithOnDrop callback.
+func (b *SendBuffer) drop(event Event) {
+	b.dropped.Add(1)
+	if bus, ok := b.bus.(*eventBusImpl); ok {
+		bus.drops.record(DropBufferFull, event, 1)
+	}
+}
+
+// Len returns the number of buffered events.
+func (b *SendBuffer) Len() int {
+	return len(b.queue)
+}
+
+// Dropped returns the number of events dropped by the overflow policy.
+func (b *SendBuffer) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Close stops accepting events and waits until the buffered events have
+// been sent.
+func (b *SendBuffer) Close() {
+	b.closeOnce.Do(func() {
+		b.mutex.Lock()
+		b.closed = true
+		b.mutex.Unlock()
+
+		b.pushing.Wait()
+		close(b.queue)
+	})
+	b.sending.Wait()
+}
+
+// run sends buffered events until the buffer is closed and drained.
+func (b *SendBuffer) run() {
+	defer b.sending.Done()
+
+	for event := range b.queue {
+		b.send(event)
+		if b.lagging.Load() && len(b.queue) <= b.config.LagThreshold/2 {
+			b.lagging.Store(false)
+		}
+	}
+}
+
+// checkLag publishes a BridgeLagging event when the buffer reaches the lag
+// threshold and no lag is being reported yet. The event is published on
+// its own goroutine, since Push usually runs inside a listener.
+func (b *SendBuffer) checkLag() {
+	buffered := len(b.queue)
+	if buffered < b.config.LagThreshold || !b.lagging.CompareAndSwap(false, true) {
+		return
+	}
+	if b.bus == nil {
+		return
+	}
+
+	lagging := BridgeLagging{
+		Bridge:   b.name,
+		Buffered: buffered,
+		Capacity: b.config.Size,
+		Dropped:  b.dropped.Load(),
+		Time:     time.Now(),
+	}
+	go b.bus.Publish(lagging)
+}
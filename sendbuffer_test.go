@@ -0,0 +1,142 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSendBufferDelivers verifies that buffered events are sent in order and drained by Close
+func TestSendBufferDelivers(t *testing.T) {
+	var mutex sync.Mutex
+	var sent []string
+	buffer := NewSendBuffer(nil, "test", BufferConfig{Size: 4}, func(event Event) {
+		mutex.Lock()
+		sent = append(sent, event.(testEvent).data)
+		mutex.Unlock()
+	})
+
+	for i := 0; i < 10; i++ {
+		if err := buffer.Push(context.Background(), testEvent{eventType: "order:placed", data: strconv.Itoa(i)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	buffer.Close()
+
+	if len(sent) != 10 {
+		t.Fatalf("Expected 10 events sent, got %d", len(sent))
+	}
+	for i, value := range sent {
+		if value != strconv.Itoa(i) {
+			t.Errorf("Expected event %d at position %d, got %s", i, i, value)
+		}
+	}
+	if err := buffer.Push(context.Background(), testEvent{eventType: "order:placed"}); !errors.Is(err, ErrBufferClosed) {
+		t.Errorf("Expected ErrBufferClosed after Close, got %v", err)
+	}
+}
+
+// TestSendBufferOverflow verifies the drop policies when the remote side stalls
+func TestSendBufferOverflow(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest} {
+		release := make(chan struct{})
+		var mutex sync.Mutex
+		var sent []string
+		buffer := NewSendBuffer(nil, "test", BufferConfig{Size: 2, Overflow: policy}, func(event Event) {
+			<-release
+			mutex.Lock()
+			sent = append(sent, event.(testEvent).data)
+			mutex.Unlock()
+		})
+
+		// The first event is taken by the sender and blocks there.
+		buffer.Push(context.Background(), testEvent{eventType: "order:placed", data: "0"})
+		deadline := time.Now().Add(time.Second)
+		for buffer.Len() != 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		for i := 1; i <= 4; i++ {
+			buffer.Push(context.Background(), testEvent{eventType: "order:placed", data: strconv.Itoa(i)})
+		}
+		if buffer.Dropped() != 2 {
+			t.Errorf("Expected 2 dropped events with policy %v, got %d", policy, buffer.Dropped())
+		}
+		close(release)
+		buffer.Close()
+
+		expected := []string{"0", "1", "2"}
+		if policy == OverflowDropOldest {
+			expected = []string{"0", "3", "4"}
+		}
+		if len(sent) != len(expected) {
+			t.Fatalf("Expected %v sent with policy %v, got %v", expected, policy, sent)
+		}
+		for i := range expected {
+			if sent[i] != expected[i] {
+				t.Errorf("Expected %v sent with policy %v, got %v", expected, policy, sent)
+				break
+			}
+		}
+	}
+}
+
+// TestSendBufferBlockContext verifies that a blocked Push gives up when its context is done
+func TestSendBufferBlockContext(t *testing.T) {
+	release := make(chan struct{})
+	buffer := NewSendBuffer(nil, "test", BufferConfig{Size: 1}, func(event Event) {
+		<-release
+	})
+	defer buffer.Close()
+	defer close(release)
+
+	buffer.Push(context.Background(), testEvent{eventType: "order:placed"})
+	buffer.Push(context.Background(), testEvent{eventType: "order:placed"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := buffer.Push(ctx, testEvent{eventType: "order:placed"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if buffer.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped event, got %d", buffer.Dropped())
+	}
+}
+
+// TestSendBufferLagging verifies that BridgeLagging is published once when the threshold is reached
+func TestSendBufferLagging(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	lagging := make(chan BridgeLagging, 4)
+	bus.Subscribe(BridgeLaggingType, func(event Event) {
+		lagging <- event.(BridgeLagging)
+	})
+
+	release := make(chan struct{})
+	buffer := NewSendBuffer(bus, "remote", BufferConfig{Size: 4, Overflow: OverflowDropNewest, LagThreshold: 2}, func(event Event) {
+		<-release
+	})
+	for i := 0; i < 8; i++ {
+		buffer.Push(context.Background(), testEvent{eventType: "order:placed"})
+	}
+
+	select {
+	case report := <-lagging:
+		if report.Bridge != "remote" || report.Capacity != 4 || report.Buffered < 2 {
+			t.Errorf("Expected a report for remote with capacity 4 and at least 2 buffered, got %+v", report)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a BridgeLagging event")
+	}
+
+	close(release)
+	buffer.Close()
+	select {
+	case report := <-lagging:
+		t.Errorf("Expected one BridgeLagging event, got another: %+v", report)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,99 @@
+package eventbus
+
+import "sync"
+
+// WithSerialTopics guarantees that events of the listed topics are
+// processed one at a time and in publish order on asynchronous buses, even
+// when the worker pool runs other topics in parallel. All listeners of an
+// event run in registration order before the next event of the same topic
+// starts. Use it for subscribers that are not reentrant, such as state
+// machines.
+//
+// Synchronous topics are already processed this way, so the option only
+// affects topics dispatched asynchronously.
+//
+// Example:
+//
+//	bus := eventbus.New(
+//	    eventbus.WithAsync(8, 1024),
+//	    eventbus.WithSerialTopics("player:state", "match:phase"),
+//	)
+func WithSerialTopics(eventTypes ...EventType) Option {
+	return func(bus *eventBusImpl) {
+		for _, eventType := range eventTypes {
+			bus.serial[eventType] = newSerialTopic()
+		}
+	}
+}
+
+// serialTopic hands out tickets in publish order and lets jobs run only
+// when their ticket is next. Workers picking up jobs out of order wait
+// for their predecessors to finish.
+type serialTopic struct {
+	// submitting serializes taking a ticket and queueing its job.
+	submitting sync.Mutex
+
+	mutex   sync.Mutex
+	turn    *sync.Cond
+	issued  uint64
+	next    uint64
+	skipped map[uint64]bool
+}
+
+// newSerialTopic creates a serial topic with no outstanding jobs.
+func newSerialTopic() *serialTopic {
+	s := &serialTopic{skipped: make(map[uint64]bool)}
+	s.turn = sync.NewCond(&s.mutex)
+	return s
+}
+
+// take returns the ticket for the next job.
+func (s *serialTopic) take() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ticket := s.issued
+	s.issued++
+	return ticket
+}
+
+// await blocks until ticket is next in line.
+func (s *serialTopic) await(ticket uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for s.next != ticket {
+		s.turn.Wait()
+	}
+}
+
+// finish completes the current ticket and lets the next job run.
+func (s *serialTopic) finish() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.advance()
+}
+
+// skip gives up ticket for a job that was dropped before it ran.
+func (s *serialTopic) skip(ticket uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if ticket == s.next {
+		s.advance()
+		return
+	}
+	s.skipped[ticket] = true
+}
+
+// advance moves past the current ticket and any skipped ones after it.
+// The caller must hold s.mutex.
+func (s *serialTopic) advance() {
+	s.next++
+	for s.skipped[s.next] {
+		delete(s.skipped, s.next)
+		s.next++
+	}
+	s.turn.Broadcast()
+}
@@ -0,0 +1,97 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSerialTopics verifies one-at-a-time, in-order processing on a parallel pool
+func TestSerialTopics(t *testing.T) {
+	bus := New(WithAsync(8, 64), WithSerialTopics("player:state"))
+
+	var active atomic.Int32
+	var overlap atomic.Bool
+	var mu sync.Mutex
+	var order []string
+
+	for i := 0; i < 2; i++ {
+		bus.Subscribe("player:state", func(event Event) {
+			if active.Add(1) > 1 {
+				overlap.Store(true)
+			}
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			order = append(order, event.(testEvent).data)
+			mu.Unlock()
+			active.Add(-1)
+		})
+	}
+
+	for i := 0; i < 20; i++ {
+		bus.Publish(testEvent{eventType: "player:state", data: strconv.Itoa(i)})
+	}
+	bus.Close()
+
+	if overlap.Load() {
+		t.Error("Serial topic listeners ran concurrently")
+	}
+	if len(order) != 40 {
+		t.Fatalf("Expected 40 deliveries, got %d", len(order))
+	}
+	for i := 0; i < 20; i++ {
+		expected := strconv.Itoa(i)
+		if order[2*i] != expected || order[2*i+1] != expected {
+			t.Fatalf("Expected both listeners to see event %s before the next one, got %v", expected, order)
+		}
+	}
+}
+
+// TestSerialTopicsDrop verifies that dropped jobs do not stall the topic
+func TestSerialTopicsDrop(t *testing.T) {
+	bus := New(
+		WithTopicConfig("player:state", TopicConfig{
+			Async:     true,
+			Workers:   2,
+			QueueSize: 1,
+			Overflow:  OverflowDropNewest,
+		}),
+		WithSerialTopics("player:state"),
+	)
+	defer bus.Close()
+
+	release := make(chan struct{})
+	var count atomic.Int32
+	bus.Subscribe("player:state", func(event Event) {
+		if event.(testEvent).data == "block" {
+			<-release
+		}
+		count.Add(1)
+	})
+
+	bus.Publish(testEvent{eventType: "player:state", data: "block"})
+	for i := 0; i < 10; i++ {
+		bus.Publish(testEvent{eventType: "player:state", data: "burst"})
+	}
+	close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for {
+		err := bus.PublishAndWait(ctx, testEvent{eventType: "player:state", data: "last"})
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrQueueFull) {
+			t.Fatalf("Serial topic stalled after drops: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if count.Load() < 2 {
+		t.Errorf("Expected at least the first and last events, got %d", count.Load())
+	}
+}
@@ -0,0 +1,82 @@
+package eventbus
+
+import "slices"
+
+// SubscriptionInfo describes a single subscription in a Snapshot.
+type SubscriptionInfo struct {
+	// EventType is the subscribed event type.
+	EventType EventType
+	// Handler is the label of the listener, if it has one.
+	Handler string
+	// Options names the subscribe options applied to the listener,
+	// such as "distinct".
+	Options []string
+	// Health is the state of the subscription when Snapshot was called.
+	// It is HealthOK without WithHealth.
+	Health HealthState
+}
+
+// Snapshot is an immutable description of the subscriptions of a bus at
+// the time Snapshot was called. Subscriptions made later are not included.
+type Snapshot struct {
+	subscriptions []*Subscription
+	health        []HealthState
+}
+
+// Snapshot returns the current subscriptions of the bus.
+func (bus *eventBusImpl) Snapshot() *Snapshot {
+	bus.subscribersMutex.RLock()
+	defer bus.subscribersMutex.RUnlock()
+
+	health := make([]HealthState, len(bus.subscriptions))
+	for i, sub := range bus.subscriptions {
+		health[i] = sub.Health()
+	}
+	return &Snapshot{subscriptions: slices.Clone(bus.subscriptions), health: health}
+}
+
+// Subscriptions describes the subscriptions in registration order.
+func (s *Snapshot) Subscriptions() []SubscriptionInfo {
+	infos := make([]SubscriptionInfo, len(s.subscriptions))
+	for i, sub := range s.subscriptions {
+		infos[i] = sub.info(s.health[i])
+	}
+	return infos
+}
+
+// info describes the subscription in the given health state.
+func (s *Subscription) info(health HealthState) SubscriptionInfo {
+	return SubscriptionInfo{
+		EventType: s.eventType,
+		Handler:   s.name,
+		Options:   slices.Clone(s.options),
+		Health:    health,
+	}
+}
+
+// Subscription returns the i-th subscription in registration order, for
+// example to cancel a misbehaving listener found by inspecting the
+// snapshot. It returns nil if i is out of range.
+func (s *Snapshot) Subscription(i int) *Subscription {
+	if i < 0 || i >= len(s.subscriptions) {
+		return nil
+	}
+	return s.subscriptions[i]
+}
+
+// CloneInto subscribes every listener of the snapshot to bus, in the
+// original order and with the original options. Options keeping state,
+// such as WithDistinct, start afresh on the new bus.
+//
+// Example:
+//
+//	// Rebuild a scene with identical wiring
+//	snapshot := scene.Bus.Snapshot()
+//	scene.Bus.Close()
+//	scene.Bus = eventbus.New()
+//	snapshot.CloneInto(scene.Bus)
+func (s *Snapshot) CloneInto(bus EventBus) {
+	for _, sub := range s.subscriptions {
+		bus.SubscribeContext(sub.eventType, sub.handler.Load().listener, sub.opts...)
+	}
+}
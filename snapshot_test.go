@@ -0,0 +1,79 @@
+package eventbus
+
+import "testing"
+
+// TestSnapshot verifies that subscriptions are described in registration order
+func TestSnapshot(t *testing.T) {
+	bus := New()
+	bus.Subscribe("player:health", func(event Event) {}, WithDistinct(nil, nil))
+	bus.Subscribe("player:moved", func(event Event) {})
+
+	snapshot := bus.Snapshot()
+	bus.Subscribe("player:died", func(event Event) {})
+
+	infos := snapshot.Subscriptions()
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 subscriptions, got %d", len(infos))
+	}
+	if infos[0].EventType != "player:health" || len(infos[0].Options) != 1 || infos[0].Options[0] != "distinct" {
+		t.Errorf("Unexpected first subscription %+v", infos[0])
+	}
+	if infos[1].EventType != "player:moved" || len(infos[1].Options) != 0 {
+		t.Errorf("Unexpected second subscription %+v", infos[1])
+	}
+
+	infos[0].Options[0] = "changed"
+	if snapshot.Subscriptions()[0].Options[0] != "distinct" {
+		t.Error("Expected the snapshot to be unaffected by changes to returned infos")
+	}
+}
+
+// TestCloneInto verifies that a clone receives events like the original
+func TestCloneInto(t *testing.T) {
+	original := New()
+	var order []string
+	original.Subscribe("scene:tick", func(event Event) { order = append(order, "physics") })
+	original.Subscribe("scene:tick", func(event Event) { order = append(order, "render") })
+
+	clone := New()
+	original.Snapshot().CloneInto(clone)
+	clone.Publish(testEvent{eventType: "scene:tick"})
+
+	if len(order) != 2 || order[0] != "physics" || order[1] != "render" {
+		t.Errorf("Expected [physics render], got %v", order)
+	}
+}
+
+// TestCloneIntoFreshOptionState verifies that stateful options start over on the clone
+func TestCloneIntoFreshOptionState(t *testing.T) {
+	original := New()
+	count := 0
+	original.Subscribe("player:health", func(event Event) { count++ }, WithDistinct(nil, nil))
+	original.Publish(healthEvent{playerID: "p1", hp: 100})
+
+	clone := New()
+	original.Snapshot().CloneInto(clone)
+	clone.Publish(healthEvent{playerID: "p1", hp: 100})
+
+	if count != 2 {
+		t.Errorf("Expected the clone to deliver the first event, got %d deliveries", count)
+	}
+}
+
+// TestSnapshotSubscription verifies that subscriptions can be cancelled through a snapshot
+func TestSnapshotSubscription(t *testing.T) {
+	bus := New()
+	count := 0
+	bus.Subscribe("player:moved", func(event Event) { count++ })
+
+	snapshot := bus.Snapshot()
+	if snapshot.Subscription(1) != nil || snapshot.Subscription(-1) != nil {
+		t.Error("Expected nil for out-of-range indexes")
+	}
+	snapshot.Subscription(0).Cancel()
+	bus.Publish(testEvent{eventType: "player:moved"})
+
+	if count != 0 {
+		t.Errorf("Expected no deliveries after cancelling, got %d", count)
+	}
+}
@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"slices"
+	"sync"
+)
+
+// WithStage places the listener in a delivery stage. Listeners of the same
+// stage run in parallel, and each stage waits for the previous one to
+// finish, which gives deterministic phase ordering with parallelism inside
+// a phase. Stages run in ascending order after the listeners without a
+// stage, which keep running one after another in registration order.
+//
+// On asynchronous topics, an event with staged listeners is delivered as a
+// single job, so its stages keep their order as well.
+//
+// Example:
+//
+//	const (
+//	    Simulation = iota
+//	    Presentation
+//	)
+//
+//	bus.Subscribe("frame:tick", physics.Step, eventbus.WithStage(Simulation))
+//	bus.Subscribe("frame:tick", ai.Think, eventbus.WithStage(Simulation))
+//	bus.Subscribe("frame:tick", renderer.Draw, eventbus.WithStage(Presentation))
+func WithStage(stage int) SubscribeOption {
+	return func(config *subscribeConfig) {
+		config.stage = stage
+		config.staged = true
+		config.options = append(config.options, "stage")
+	}
+}
+
+// insertListener returns listeners with sub inserted after the unstaged
+// listeners and the listeners of earlier or equal stages. Publishers may
+// still hold listeners, so it is only appended to, never modified.
+func insertListener(listeners []*Subscription, sub *Subscription) []*Subscription {
+	pos := len(listeners)
+	for pos > 0 && listeners[pos-1].staged && (!sub.staged || listeners[pos-1].stage > sub.stage) {
+		pos--
+	}
+	if pos == len(listeners) {
+		return append(listeners, sub)
+	}
+	return slices.Insert(slices.Clone(listeners), pos, sub)
+}
+
+// hasStages reports whether any of listeners has a stage. Staged listeners
+// are sorted last, so only the last one needs checking.
+func hasStages(listeners []*Subscription) bool {
+	return len(listeners) > 0 && listeners[len(listeners)-1].staged
+}
+
+// stageEnd returns the end of the group of listeners starting at start:
+// a single unstaged listener, or every listener of one stage.
+func stageEnd(listeners []*Subscription, start int) int {
+	end := start + 1
+	if !listeners[start].staged {
+		return end
+	}
+	for end < len(listeners) && listeners[end].stage == listeners[start].stage {
+		end++
+	}
+	return end
+}
+
+// runParallel calls fn for every index from start to end in parallel and
+// waits for all of them. A panic is re-raised on the calling goroutine
+// once every call has returned.
+func runParallel(start, end int, fn func(i int)) {
+	if end-start == 1 {
+		fn(start)
+		return
+	}
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var panicked any
+	call := func(i int) {
+		defer wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				once.Do(func() { panicked = r })
+			}
+		}()
+		fn(i)
+	}
+
+	wg.Add(end - start)
+	for i := start + 1; i < end; i++ {
+		go call(i)
+	}
+	call(start)
+	wg.Wait()
+
+	if panicked != nil {
+		panic(panicked)
+	}
+}
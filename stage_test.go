@@ -0,0 +1,115 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestStagesRunInOrder verifies that unstaged listeners run first and stages run in ascending order
+func TestStagesRunInOrder(t *testing.T) {
+	bus := New()
+	var order []string
+	var mu sync.Mutex
+	record := func(name string) EventListener {
+		return func(event Event) {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+
+	bus.Subscribe("frame:tick", record("render"), WithStage(2))
+	bus.Subscribe("frame:tick", record("physics"), WithStage(1))
+	bus.Subscribe("frame:tick", record("input"))
+	bus.Subscribe("frame:tick", record("audio"), WithStage(2))
+
+	bus.Publish(testEvent{eventType: "frame:tick"})
+
+	if len(order) != 4 || order[0] != "input" || order[1] != "physics" {
+		t.Fatalf("Expected input then physics first, got %v", order)
+	}
+	if (order[2] != "render" || order[3] != "audio") && (order[2] != "audio" || order[3] != "render") {
+		t.Errorf("Expected render and audio last, got %v", order)
+	}
+}
+
+// TestStageRunsInParallel verifies that listeners of one stage run concurrently
+func TestStageRunsInParallel(t *testing.T) {
+	bus := New()
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	done := 0
+	var mu sync.Mutex
+
+	rendezvous := func(event Event) {
+		arrived.Done()
+		waited := make(chan struct{})
+		go func() {
+			arrived.Wait()
+			close(waited)
+		}()
+		select {
+		case <-waited:
+			mu.Lock()
+			done++
+			mu.Unlock()
+		case <-time.After(2 * time.Second):
+		}
+	}
+	bus.Subscribe("frame:tick", rendezvous, WithStage(1))
+	bus.Subscribe("frame:tick", rendezvous, WithStage(1))
+
+	bus.Publish(testEvent{eventType: "frame:tick"})
+
+	if done != 2 {
+		t.Errorf("Expected both listeners to meet, got %d", done)
+	}
+}
+
+// TestStagePanicPropagates verifies that a panic in a parallel stage reaches the publisher
+func TestStagePanicPropagates(t *testing.T) {
+	bus := New()
+	bus.Subscribe("frame:tick", func(event Event) {}, WithStage(1))
+	bus.Subscribe("frame:tick", func(event Event) { panic("broken system") }, WithStage(1))
+
+	defer func() {
+		if r := recover(); r != "broken system" {
+			t.Errorf("Expected the listener panic to propagate, got %v", r)
+		}
+	}()
+	bus.Publish(testEvent{eventType: "frame:tick"})
+}
+
+// TestStagesAsync verifies that async delivery honors stages and reports every delivery
+func TestStagesAsync(t *testing.T) {
+	bus := New(WithAsync(4, 16))
+	defer bus.Close()
+
+	var mu sync.Mutex
+	var order []int
+	for _, stage := range []int{3, 1, 2, 1} {
+		bus.Subscribe("frame:tick", func(event Event) {
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			order = append(order, stage)
+			mu.Unlock()
+		}, WithStage(stage))
+	}
+
+	receipt, err := bus.PublishDetailed(context.Background(), testEvent{eventType: "frame:tick"})
+	if err != nil {
+		t.Fatalf("PublishDetailed failed: %v", err)
+	}
+
+	if len(receipt.Deliveries) != 4 {
+		t.Fatalf("Expected 4 deliveries, got %d", len(receipt.Deliveries))
+	}
+	for i := 1; i < len(order); i++ {
+		if order[i] < order[i-1] {
+			t.Errorf("Expected stages in ascending order, got %v", order)
+			break
+		}
+	}
+}
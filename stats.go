@@ -0,0 +1,156 @@
+package eventbus
+
+import (
+	"slices"
+	"time"
+)
+
+// Stats describes the activity and load of a bus.
+type Stats struct {
+	// Published is the number of events accepted by the bus.
+	Published uint64
+	// Dropped is the number of deliveries discarded because an asynchronous
+	// queue was full or the publisher stopped waiting for room. It is the
+	// sum of DroppedQueueFull and DroppedCancelled.
+	Dropped uint64
+	// DroppedQueueFull is the number of deliveries dropped for
+	// DropQueueFull.
+	DroppedQueueFull uint64
+	// DroppedCancelled is the number of deliveries dropped for
+	// DropCancelled.
+	DroppedCancelled uint64
+	// BufferDropped is the number of events dropped by the send buffers
+	// of the bus. It is not part of Dropped.
+	BufferDropped uint64
+	// DroppedExpired is the number of deliveries dropped for DropExpired.
+	// It is not part of Dropped.
+	DroppedExpired uint64
+	// Muted is the number of deliveries suppressed by Mute or Solo.
+	Muted uint64
+	// Subscriptions is the number of active subscriptions.
+	Subscriptions int
+	// Slow, Erroring, and BreakerOpen count the subscriptions in the
+	// corresponding HealthState. They are zero without WithHealth.
+	Slow        int
+	Erroring    int
+	BreakerOpen int
+	// Queued is the number of jobs waiting in asynchronous queues.
+	Queued int
+	// Latency holds the delivery latencies of every topic delivered to.
+	// It is nil without WithLatencyTracking.
+	Latency map[EventType]TopicLatency
+	// HandlerCosts holds the resource usage of every handler invoked,
+	// most expensive first. It is nil without WithHandlerCosts.
+	HandlerCosts []HandlerCost
+	// Memory describes the budget given to WithMemoryBudget. It is nil
+	// without one.
+	Memory *MemoryStats
+}
+
+// Stats returns the current counters of the bus. It does not take the bus
+// mutex, so it can be called from listeners.
+func (bus *eventBusImpl) Stats() Stats {
+	bus.subscribersMutex.RLock()
+	subscriptions := slices.Clone(bus.subscriptions)
+	bus.subscribersMutex.RUnlock()
+
+	stats := Stats{
+		Published:        bus.published.Load(),
+		DroppedQueueFull: bus.drops.queueFull.Load(),
+		DroppedCancelled: bus.drops.cancelled.Load(),
+		BufferDropped:    bus.drops.bufferFull.Load(),
+		DroppedExpired:   bus.drops.expired.Load(),
+		Muted:            bus.muting.suppressed.Load(),
+		Subscriptions:    len(subscriptions),
+	}
+	stats.Dropped = stats.DroppedQueueFull + stats.DroppedCancelled
+	if bus.latency != nil {
+		stats.Latency = bus.latency.snapshot()
+	}
+	if bus.costs != nil {
+		stats.HandlerCosts = bus.costs.snapshot()
+	}
+	if bus.memory != nil {
+		memory := bus.memory.Stats()
+		stats.Memory = &memory
+	}
+	for _, sub := range subscriptions {
+		switch sub.Health() {
+		case HealthSlow:
+			stats.Slow++
+		case HealthErroring:
+			stats.Erroring++
+		case HealthBreakerOpen:
+			stats.BreakerOpen++
+		}
+	}
+	// The routes and their pools do not change after New.
+	for _, route := range bus.dispatch.all() {
+		if route.pool != nil {
+			stats.Queued += route.pool.queued()
+		}
+	}
+	return stats
+}
+
+// StatsType is the event type of the events published by WithStatsInterval.
+const StatsType EventType = "eventbus:stats"
+
+// StatsReport is published periodically by a bus configured with
+// WithStatsInterval.
+type StatsReport struct {
+	// Time is when the report was published.
+	Time time.Time
+	// Interval is the time since the previous report, or since the bus
+	// was created for the first one.
+	Interval time.Duration
+	// Stats are the bus counters at Time.
+	Stats Stats
+	// PublishRate is the number of events published per second during
+	// Interval.
+	PublishRate float64
+	// DropRate is the number of deliveries dropped per second during
+	// Interval.
+	DropRate float64
+}
+
+// GetType returns StatsType.
+func (r StatsReport) GetType() EventType {
+	return StatsType
+}
+
+// WithStatsInterval publishes a StatsReport every interval, until the bus is
+// closed, so dashboards built on the bus itself can graph publish rates and
+// queue depths without polling. Reports are counted in Stats.Published.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithStatsInterval(time.Second))
+//
+//	bus.Subscribe(eventbus.StatsType, func(event eventbus.Event) {
+//	    report := event.(eventbus.StatsReport)
+//	    dashboard.Plot("publish rate", report.PublishRate)
+//	    dashboard.Plot("queued", float64(report.Stats.Queued))
+//	})
+func WithStatsInterval(interval time.Duration) Option {
+	return func(bus *eventBusImpl) {
+		var previous Stats
+		since := time.Now()
+
+		bus.periodic = append(bus.periodic, &periodic{
+			name:     "stats",
+			interval: interval,
+			publish: func(now time.Time) {
+				stats := bus.Stats()
+				elapsed := now.Sub(since)
+				report := StatsReport{Time: now, Interval: elapsed, Stats: stats}
+				if seconds := elapsed.Seconds(); seconds > 0 {
+					report.PublishRate = float64(stats.Published-previous.Published) / seconds
+					report.DropRate = float64(stats.Dropped-previous.Dropped) / seconds
+				}
+				previous, since = stats, now
+				bus.Publish(report)
+			},
+		})
+	}
+}
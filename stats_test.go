@@ -0,0 +1,91 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestStats verifies that Stats counts published events, dropped deliveries,
+// subscriptions, and queued jobs
+func TestStats(t *testing.T) {
+	bus := New(WithTopicConfig("telemetry:*", TopicConfig{
+		Async:     true,
+		Workers:   1,
+		QueueSize: 1,
+		Overflow:  OverflowDropNewest,
+	}))
+	defer bus.Close()
+
+	running := make(chan struct{})
+	release := make(chan struct{})
+	bus.Subscribe("telemetry:frame", func(event Event) {
+		running <- struct{}{}
+		<-release
+	})
+	bus.Subscribe("app:start", func(event Event) {})
+
+	bus.Publish(testEvent{eventType: "app:start"})
+	bus.Publish(testEvent{eventType: "telemetry:frame"})
+	<-running
+	bus.Publish(testEvent{eventType: "telemetry:frame"})
+	bus.Publish(testEvent{eventType: "telemetry:frame"})
+
+	stats := bus.Stats()
+	if stats.Published != 4 {
+		t.Errorf("Expected 4 published events, got %d", stats.Published)
+	}
+	if stats.Dropped != 1 {
+		t.Errorf("Expected 1 dropped delivery, got %d", stats.Dropped)
+	}
+	if stats.Subscriptions != 2 {
+		t.Errorf("Expected 2 subscriptions, got %d", stats.Subscriptions)
+	}
+	if stats.Queued != 1 {
+		t.Errorf("Expected 1 queued job, got %d", stats.Queued)
+	}
+
+	close(release)
+	<-running
+}
+
+// TestStatsFromListener verifies that Stats can be called during a synchronous delivery
+func TestStatsFromListener(t *testing.T) {
+	bus := New()
+	var stats Stats
+	bus.SubscribeContext("app:start", func(ctx context.Context, event Event) {
+		stats = bus.Stats()
+	})
+
+	bus.Publish(testEvent{eventType: "app:start"})
+
+	if stats.Published != 1 || stats.Subscriptions != 1 {
+		t.Errorf("Expected 1 published event and 1 subscription, got %+v", stats)
+	}
+}
+
+// TestStatsInterval verifies that WithStatsInterval publishes reports with publish rates
+func TestStatsInterval(t *testing.T) {
+	bus := New(WithStatsInterval(20 * time.Millisecond))
+	defer bus.Close()
+
+	reports := make(chan StatsReport, 100)
+	bus.Subscribe(StatsType, func(event Event) {
+		reports <- event.(StatsReport)
+	})
+	for i := 0; i < 5; i++ {
+		bus.Publish(testEvent{eventType: "app:tick"})
+	}
+
+	select {
+	case report := <-reports:
+		if report.Stats.Published < 5 {
+			t.Errorf("Expected at least 5 published events, got %d", report.Stats.Published)
+		}
+		if report.Interval <= 0 || report.PublishRate <= 0 {
+			t.Errorf("Expected a positive interval and publish rate, got %v and %v", report.Interval, report.PublishRate)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a stats report")
+	}
+}
@@ -0,0 +1,296 @@
+// Package stdiobridge runs a child process and exchanges events with it over
+// its standard input and output, so plugins and external tools written in
+// any language can subscribe to and publish on an event bus.
+//
+// Both directions carry frames: a 4-byte big-endian length followed by a
+// JSON-encoded Message. The child sends "subscribe" and "unsubscribe"
+// messages to choose the event types it receives as "event" messages, and
+// "publish" messages to publish on the bus. Event data is encoded with the
+// codec registered for the event type, JSON by default, and travels base64
+// encoded in the Data field.
+//
+// Example:
+//
+//	bridge, err := stdiobridge.Start(bus, exec.Command("python3", "plugin.py"), nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer bridge.Close()
+package stdiobridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// MaxFrameSize is the largest frame accepted, in bytes.
+const MaxFrameSize = 16 << 20
+
+// ErrFrameTooLarge is returned for frames longer than MaxFrameSize.
+var ErrFrameTooLarge = errors.New("stdiobridge: frame too large")
+
+// Message kinds.
+const (
+	// KindEvent delivers a bus event to the child.
+	KindEvent = "event"
+	// KindPublish publishes an event from the child on the bus.
+	KindPublish = "publish"
+	// KindSubscribe asks for events of Type to be delivered to the child.
+	KindSubscribe = "subscribe"
+	// KindUnsubscribe stops the delivery of events of Type to the child.
+	KindUnsubscribe = "unsubscribe"
+)
+
+// Message is the content of a frame.
+type Message struct {
+	Kind string             `json:"kind"`
+	Type eventbus.EventType `json:"type"`
+	// Data is the encoded event of an "event" or "publish" message.
+	Data []byte `json:"data,omitempty"`
+}
+
+// WriteFrame writes message to w as a length-prefixed frame.
+func WriteFrame(w io.Writer, message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if len(body) > MaxFrameSize {
+		return ErrFrameTooLarge
+	}
+
+	frame := make([]byte, 4+len(body))
+	binary.BigEndian.PutUint32(frame, uint32(len(body)))
+	copy(frame[4:], body)
+	_, err = w.Write(frame)
+	return err
+}
+
+// ReadFrame reads a length-prefixed frame from r. It returns io.EOF if r
+// ends before a frame starts.
+func ReadFrame(r io.Reader) (Message, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Message{}, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return Message{}, ErrFrameTooLarge
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return Message{}, io.ErrUnexpectedEOF
+	}
+	var message Message
+	if err := json.Unmarshal(body, &message); err != nil {
+		return Message{}, fmt.Errorf("stdiobridge: invalid frame: %w", err)
+	}
+	return message, nil
+}
+
+// Bridge connects a bus with a running child process.
+type Bridge struct {
+	bus    eventbus.EventBus
+	codecs *eventbus.CodecRegistry
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	// buffer holds the events waiting to be written to the child.
+	buffer       *eventbus.SendBuffer
+	bufferConfig eventbus.BufferConfig
+
+	// subscriptions holds the subscriptions requested by the child.
+	subscriptions map[eventbus.EventType]*eventbus.Subscription
+	closed        bool
+	mutex         sync.Mutex
+
+	// reading is closed when the child's output ends.
+	reading chan struct{}
+	// err is the first protocol or encoding error, reported by Close.
+	err error
+}
+
+// Option configures a Bridge.
+type Option func(*Bridge)
+
+// WithBuffer configures the buffer of events waiting to be written to the
+// child. By default up to 1024 events are buffered, after which publishers
+// of the events the child subscribed to block.
+func WithBuffer(config eventbus.BufferConfig) Option {
+	return func(b *Bridge) {
+		b.bufferConfig = config
+	}
+}
+
+// Start runs cmd and bridges its standard input and output with bus.
+// cmd must not have Stdin or Stdout set. Its standard error is left as
+// configured. Events are encoded and decoded with codecs, or with
+// eventbus.JSONCodec if codecs is nil; decoded events are published as is,
+// so with JSONCodec listeners receive eventbus.RawEvent values.
+//
+// Events for the child pass through a bounded buffer. If the child falls
+// behind, an eventbus.BridgeLagging event naming the command is published.
+func Start(bus eventbus.EventBus, cmd *exec.Cmd, codecs *eventbus.CodecRegistry, opts ...Option) (*Bridge, error) {
+	if codecs == nil {
+		codecs = eventbus.NewCodecRegistry(nil)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	b := &Bridge{
+		bus:           bus,
+		codecs:        codecs,
+		cmd:           cmd,
+		stdin:         stdin,
+		subscriptions: make(map[eventbus.EventType]*eventbus.Subscription),
+		reading:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.buffer = eventbus.NewSendBuffer(bus, cmd.Path, b.bufferConfig, b.send)
+	go b.read(bufio.NewReader(stdout))
+	return b, nil
+}
+
+// Done returns a channel that is closed when the child closes its output,
+// usually because it exited.
+func (b *Bridge) Done() <-chan struct{} {
+	return b.reading
+}
+
+// Close cancels the child's subscriptions, writes the buffered events,
+// closes the child's standard input, and waits for it to exit. It returns the first protocol error, or the error
+// of the child's exit.
+func (b *Bridge) Close() error {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return nil
+	}
+	b.closed = true
+	for eventType, sub := range b.subscriptions {
+		sub.Cancel()
+		delete(b.subscriptions, eventType)
+	}
+	b.mutex.Unlock()
+
+	b.buffer.Close()
+	b.stdin.Close()
+
+	<-b.reading
+	waitErr := b.cmd.Wait()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	return waitErr
+}
+
+// read handles the frames sent by the child until its output ends.
+func (b *Bridge) read(r io.Reader) {
+	defer close(b.reading)
+
+	for {
+		message, err := ReadFrame(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				b.fail(err)
+			}
+			return
+		}
+
+		switch message.Kind {
+		case KindPublish:
+			event, err := b.codecs.Decode(message.Type, message.Data)
+			if err != nil {
+				b.fail(err)
+				continue
+			}
+			b.bus.Publish(event)
+
+		case KindSubscribe:
+			b.subscribe(message.Type)
+
+		case KindUnsubscribe:
+			b.unsubscribe(message.Type)
+
+		default:
+			b.fail(fmt.Errorf("stdiobridge: unknown message kind %q", message.Kind))
+		}
+	}
+}
+
+// subscribe forwards events of eventType to the child.
+func (b *Bridge) subscribe(eventType eventbus.EventType) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed || b.subscriptions[eventType] != nil {
+		return
+	}
+	b.subscriptions[eventType] = b.bus.Subscribe(eventType, func(event eventbus.Event) {
+		b.buffer.Push(context.Background(), event)
+	})
+}
+
+// unsubscribe stops forwarding events of eventType to the child.
+func (b *Bridge) unsubscribe(eventType eventbus.EventType) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if sub, ok := b.subscriptions[eventType]; ok {
+		sub.Cancel()
+		delete(b.subscriptions, eventType)
+	}
+}
+
+// send writes event to the child. It is called by the buffer, one event
+// at a time. Events sent after the child closed its output are dropped.
+func (b *Bridge) send(event eventbus.Event) {
+	select {
+	case <-b.reading:
+		return
+	default:
+	}
+
+	data, err := b.codecs.Encode(event)
+	if err != nil {
+		b.fail(err)
+		return
+	}
+	if err := WriteFrame(b.stdin, Message{Kind: KindEvent, Type: event.GetType(), Data: data}); err != nil {
+		b.fail(err)
+	}
+}
+
+// fail records err unless an error was already recorded.
+func (b *Bridge) fail(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.err == nil {
+		b.err = err
+	}
+}
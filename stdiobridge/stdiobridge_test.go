@@ -0,0 +1,121 @@
+package stdiobridge
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// TestHelperProcess is run as the child process by the other tests. It
+// subscribes to "ping" and publishes a "pong" carrying the data of every
+// ping it receives.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("STDIOBRIDGE_HELPER") != "1" {
+		return
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	WriteFrame(out, Message{Kind: KindSubscribe, Type: "ping"})
+	WriteFrame(out, Message{Kind: KindPublish, Type: "ready", Data: []byte(`{}`)})
+	out.Flush()
+
+	in := bufio.NewReader(os.Stdin)
+	for {
+		message, err := ReadFrame(in)
+		if err != nil {
+			os.Exit(0)
+		}
+		WriteFrame(out, Message{Kind: KindPublish, Type: "pong", Data: message.Data})
+		out.Flush()
+	}
+}
+
+// helperCommand returns a command running TestHelperProcess.
+func helperCommand() *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), "STDIOBRIDGE_HELPER=1")
+	return cmd
+}
+
+// TestBridge verifies that a child process can subscribe to and publish events
+func TestBridge(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+
+	ready := make(chan struct{})
+	pongs := make(chan eventbus.Event, 1)
+	bus.Subscribe("ready", func(event eventbus.Event) {
+		close(ready)
+	})
+	bus.Subscribe("pong", func(event eventbus.Event) {
+		pongs <- event
+	})
+
+	bridge, err := Start(bus, helperCommand(), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the child to publish ready")
+	}
+
+	bus.Publish(eventbus.Of("ping", map[string]int{"n": 7}))
+
+	select {
+	case event := <-pongs:
+		payload, ok := eventbus.Payload[map[string]int](event)
+		if !ok || payload["n"] != 7 {
+			t.Errorf("Expected pong with n=7, got %#v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a pong")
+	}
+
+	if err := bridge.Close(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	select {
+	case <-bridge.Done():
+	default:
+		t.Error("Expected Done to be closed after Close")
+	}
+}
+
+// TestFrameRoundTrip verifies that frames survive writing and reading
+func TestFrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	sent := Message{Kind: KindPublish, Type: "player:scored", Data: []byte(`{"points":3}`)}
+	if err := WriteFrame(&buf, sent); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	received, err := ReadFrame(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if received.Kind != sent.Kind || received.Type != sent.Type || string(received.Data) != string(sent.Data) {
+		t.Errorf("Expected %+v, got %+v", sent, received)
+	}
+
+	if _, err := ReadFrame(&buf); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+// TestFrameTooLarge verifies that oversized frames are rejected
+func TestFrameTooLarge(t *testing.T) {
+	header := []byte{0xff, 0xff, 0xff, 0xff}
+	if _, err := ReadFrame(bytes.NewReader(header)); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("Expected ErrFrameTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+)
+
+// jobDeque is a worker's FIFO of jobs. The owning worker and thieves both
+// take from the front, so jobs of one topic leave a deque in the order
+// they entered it.
+type jobDeque struct {
+	mutex sync.Mutex
+	jobs  []asyncJob
+	head  int
+	// urgent is the number of urgent jobs at the front.
+	urgent int
+}
+
+// push appends job at the back.
+func (d *jobDeque) push(job asyncJob) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.head > 0 && d.head == len(d.jobs) {
+		d.jobs, d.head = d.jobs[:0], 0
+	}
+	d.jobs = append(d.jobs, job)
+}
+
+// pushFront inserts job at the front, ahead of the queued jobs.
+func (d *jobDeque) pushFront(job asyncJob) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.urgent++
+	if d.head > 0 {
+		d.head--
+		d.jobs[d.head] = job
+		return
+	}
+	d.jobs = append(d.jobs, asyncJob{})
+	copy(d.jobs[1:], d.jobs)
+	d.jobs[0] = job
+}
+
+// pop removes the job at the front.
+func (d *jobDeque) pop() (asyncJob, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.head == len(d.jobs) {
+		return asyncJob{}, false
+	}
+	job := d.jobs[d.head]
+	d.jobs[d.head] = asyncJob{}
+	d.head++
+	if d.urgent > 0 {
+		d.urgent--
+	}
+	return job, true
+}
+
+// popAged removes the first job behind the urgent ones, if the deque
+// holds both kinds.
+func (d *jobDeque) popAged() (asyncJob, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	first := d.head + d.urgent
+	if d.urgent == 0 || first == len(d.jobs) {
+		return asyncJob{}, false
+	}
+	job := d.jobs[first]
+	copy(d.jobs[d.head+1:first+1], d.jobs[d.head:first])
+	d.jobs[d.head] = asyncJob{}
+	d.head++
+	return job, true
+}
+
+// stealingPool runs jobs on workers that each own a deque. Jobs are
+// placed on the deque of the worker their topic hashes to, which keeps
+// a topic's jobs together; idle workers steal from the others so a burst
+// on one topic does not leave the rest of the pool idle.
+type stealingPool struct {
+	deques   []*jobDeque
+	overflow OverflowPolicy
+	// slots holds one token per queued job and bounds the queue size.
+	slots chan struct{}
+	// wake signals idle workers that a job was queued. It is closed by stop.
+	wake   chan struct{}
+	seed   maphash.Seed
+	victim atomic.Uint32
+	// aging promotes the jobs behind urgent ones; see TopicConfig.Aging.
+	aging   *laneAging
+	running sync.WaitGroup
+	// release marks the wake channel as closed in the leak audit.
+	release func()
+}
+
+// newStealingPool creates a work-stealing pool for an asynchronous topic
+// configuration. Workers and queue size are raised to at least 1.
+func newStealingPool(config TopicConfig) *stealingPool {
+	workers := max(config.Workers, 1)
+	pool := &stealingPool{
+		deques:   make([]*jobDeque, workers),
+		overflow: config.Overflow,
+		slots:    make(chan struct{}, max(config.QueueSize, 1)),
+		wake:     make(chan struct{}, workers),
+		seed:     maphash.MakeSeed(),
+		aging:    newLaneAging(config.Aging),
+	}
+	for i := range pool.deques {
+		pool.deques[i] = &jobDeque{}
+	}
+	return pool
+}
+
+// start launches one goroutine per deque.
+func (pool *stealingPool) start(audit *leakAudit, pattern EventType) {
+	pool.release = audit.track("channel", fmt.Sprintf("wake channel for %s", pattern))
+	pool.running.Add(len(pool.deques))
+	for i := range pool.deques {
+		go pool.work(i, audit.track("goroutine", fmt.Sprintf("worker %d for %s", i, pattern)))
+	}
+}
+
+// stop wakes every worker and waits for them to drain the deques.
+func (pool *stealingPool) stop() {
+	close(pool.wake)
+	pool.release()
+	pool.running.Wait()
+}
+
+// work runs jobs from the worker's own deque, stealing when it is empty.
+// release is called when the worker exits.
+func (pool *stealingPool) work(self int, release func()) {
+	defer pool.running.Done()
+	defer release()
+
+	for {
+		if job, ok := pool.take(self); ok {
+			<-pool.slots
+			job.run()
+			continue
+		}
+		if _, open := <-pool.wake; !open {
+			// Stopped: finish whatever is left, then exit.
+			for job, ok := pool.take(self); ok; job, ok = pool.take(self) {
+				<-pool.slots
+				job.run()
+			}
+			return
+		}
+	}
+}
+
+// take pops from the worker's own deque or steals from another one,
+// starting with the jobs waiting behind urgent ones if they are due for
+// aging.
+func (pool *stealingPool) take(self int) (asyncJob, bool) {
+	if pool.aging.due() {
+		if job, ok := pool.takeFrom(self, (*jobDeque).popAged); ok {
+			return job, true
+		}
+	}
+	return pool.takeFrom(self, (*jobDeque).pop)
+}
+
+// takeFrom takes a job with pop from the worker's own deque or another one.
+func (pool *stealingPool) takeFrom(self int, pop func(*jobDeque) (asyncJob, bool)) (asyncJob, bool) {
+	n := len(pool.deques)
+	for i := range n {
+		if job, ok := pop(pool.deques[(self+i)%n]); ok {
+			pool.taken(job)
+			return job, true
+		}
+	}
+	return asyncJob{}, false
+}
+
+// taken accounts for job leaving its deque.
+func (pool *stealingPool) taken(job asyncJob) {
+	pool.aging.remove(job.aged)
+}
+
+// enqueue places job on the deque its topic hashes to.
+func (pool *stealingPool) enqueue(ctx context.Context, job asyncJob) error {
+	switch pool.overflow {
+	case OverflowDropNewest:
+		select {
+		case pool.slots <- struct{}{}:
+		default:
+			job.drop(DropQueueFull, ErrQueueFull)
+			return nil
+		}
+
+	case OverflowDropOldest:
+		for acquired := false; !acquired; {
+			select {
+			case pool.slots <- struct{}{}:
+				acquired = true
+			default:
+				pool.dropOldest()
+			}
+		}
+
+	default:
+		select {
+		case pool.slots <- struct{}{}:
+		case <-ctx.Done():
+			err := contextError(ctx)
+			job.drop(DropCancelled, err)
+			return err
+		}
+	}
+
+	deque := pool.deques[pool.home(job.event.GetType())]
+	if job.urgent() {
+		// Urgent jobs skip the queue; serial jobs must stay in order.
+		deque.pushFront(job)
+	} else {
+		job.aged = pool.aging.add()
+		deque.push(job)
+	}
+	select {
+	case pool.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// dropOldest discards the front job of some deque to make room.
+func (pool *stealingPool) dropOldest() {
+	start := int(pool.victim.Add(1))
+	for i := range pool.deques {
+		if job, ok := pool.deques[(start+i)%len(pool.deques)].pop(); ok {
+			pool.taken(job)
+			<-pool.slots
+			job.drop(DropQueueFull, ErrQueueFull)
+			return
+		}
+	}
+}
+
+// queued returns the number of jobs waiting in the deques.
+func (pool *stealingPool) queued() int {
+	return len(pool.slots)
+}
+
+// home returns the index of the deque that jobs of eventType are placed on.
+func (pool *stealingPool) home(eventType EventType) int {
+	return int(maphash.String(pool.seed, string(eventType)) % uint64(len(pool.deques)))
+}
@@ -0,0 +1,175 @@
+package eventbus
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWorkStealingDelivers verifies that every delivery runs exactly once
+func TestWorkStealingDelivers(t *testing.T) {
+	bus := New(WithTopicConfig("*", TopicConfig{
+		Async:        true,
+		Workers:      4,
+		QueueSize:    16,
+		WorkStealing: true,
+	}))
+	var count atomic.Int32
+
+	for i := 0; i < 5; i++ {
+		topic := EventType("topic:" + strconv.Itoa(i))
+		bus.Subscribe(topic, func(event Event) {
+			count.Add(1)
+		})
+	}
+
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				bus.Publish(testEvent{eventType: EventType("topic:" + strconv.Itoa(i%5))})
+			}
+		}()
+	}
+	wg.Wait()
+	bus.Close()
+
+	if count.Load() != 1000 {
+		t.Errorf("Expected 1000 deliveries, got %d", count.Load())
+	}
+}
+
+// TestWorkStealingSpreadsBurst verifies that idle workers help with a single busy topic
+func TestWorkStealingSpreadsBurst(t *testing.T) {
+	bus := New(WithTopicConfig("*", TopicConfig{
+		Async:        true,
+		Workers:      4,
+		QueueSize:    64,
+		WorkStealing: true,
+	}))
+
+	var active, peak atomic.Int32
+	bus.Subscribe("burst:topic", func(event Event) {
+		n := active.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		active.Add(-1)
+	})
+
+	for i := 0; i < 32; i++ {
+		bus.Publish(testEvent{eventType: "burst:topic"})
+	}
+	bus.Close()
+
+	if peak.Load() < 2 {
+		t.Errorf("Expected stolen work to run in parallel, peak concurrency was %d", peak.Load())
+	}
+}
+
+// TestWorkStealingSerialTopic verifies that serial topics stay ordered
+func TestWorkStealingSerialTopic(t *testing.T) {
+	bus := New(
+		WithTopicConfig("*", TopicConfig{Async: true, Workers: 4, QueueSize: 4, WorkStealing: true}),
+		WithSerialTopics("player:state"),
+	)
+
+	var order []string
+	bus.Subscribe("player:state", func(event Event) {
+		order = append(order, event.(testEvent).data)
+	})
+
+	for i := 0; i < 50; i++ {
+		bus.Publish(testEvent{eventType: "player:state", data: strconv.Itoa(i)})
+	}
+	bus.Close()
+
+	for i, data := range order {
+		if data != strconv.Itoa(i) {
+			t.Fatalf("Expected in-order delivery, got %v", order)
+		}
+	}
+	if len(order) != 50 {
+		t.Errorf("Expected 50 deliveries, got %d", len(order))
+	}
+}
+
+// TestWorkStealingDropNewest verifies overflow handling
+func TestWorkStealingDropNewest(t *testing.T) {
+	bus := New(WithTopicConfig("*", TopicConfig{
+		Async:        true,
+		Workers:      1,
+		QueueSize:    1,
+		Overflow:     OverflowDropNewest,
+		WorkStealing: true,
+	}))
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var count atomic.Int32
+	bus.Subscribe("telemetry:fps", func(event Event) {
+		select {
+		case started <- struct{}{}:
+			<-release
+		default:
+		}
+		count.Add(1)
+	})
+
+	bus.Publish(testEvent{eventType: "telemetry:fps"})
+	<-started
+	for i := 0; i < 5; i++ {
+		bus.Publish(testEvent{eventType: "telemetry:fps"})
+	}
+	close(release)
+	bus.Close()
+
+	if count.Load() != 2 {
+		t.Errorf("Expected 2 deliveries with the rest dropped, got %d", count.Load())
+	}
+}
+
+// benchmarkAsyncBurst publishes bursts on a few hot topics from parallel
+// publishers, with listeners doing a small amount of work.
+func benchmarkAsyncBurst(b *testing.B, config TopicConfig) {
+	bus := New(WithTopicConfig("*", config))
+	defer bus.Close()
+
+	topics := []EventType{"burst:0", "burst:1", "burst:2"}
+	for _, topic := range topics {
+		bus.Subscribe(topic, func(event Event) {
+			sum := 0
+			for i := 0; i < 200; i++ {
+				sum += i
+			}
+			_ = sum
+		})
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			bus.Publish(testEvent{eventType: topics[i%len(topics)], data: "benchmark"})
+			i++
+		}
+	})
+}
+
+// BenchmarkAsyncSharedQueue benchmarks the shared-queue worker pool
+func BenchmarkAsyncSharedQueue(b *testing.B) {
+	benchmarkAsyncBurst(b, TopicConfig{Async: true, Workers: 8, QueueSize: 1024})
+}
+
+// BenchmarkAsyncWorkStealing benchmarks the work-stealing worker pool
+func BenchmarkAsyncWorkStealing(b *testing.B) {
+	benchmarkAsyncBurst(b, TopicConfig{Async: true, Workers: 8, QueueSize: 1024, WorkStealing: true})
+}
@@ -0,0 +1,348 @@
+package eventbus
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventStore persists published events as an ordered stream of envelopes.
+// Implementations must be safe for concurrent use.
+type EventStore interface {
+	// Append records event at the end of the stream and returns its envelope.
+	Append(event Event) (Envelope, error)
+
+	// Read calls fn for every envelope whose sequence is at least from,
+	// in sequence order. Reading stops at the first error returned by fn,
+	// which is then returned by Read.
+	Read(from uint64, fn func(Envelope) error) error
+}
+
+// Truncater is implemented by stores that can discard old envelopes,
+// for example after they have been archived.
+type Truncater interface {
+	// TruncateBefore removes every envelope whose sequence is below sequence.
+	TruncateBefore(sequence uint64) error
+}
+
+// MetadataAppender is implemented by stores that can record the envelope
+// metadata collected with WithContextFields.
+type MetadataAppender interface {
+	// AppendMetadata records event with metadata at the end of the stream.
+	AppendMetadata(event Event, metadata map[string]string) (Envelope, error)
+}
+
+// EnvelopeAppender is implemented by stores that keep the envelope
+// assigned by the bus, including its bus sequence and timestamps.
+// It is preferred over MetadataAppender.
+type EnvelopeAppender interface {
+	// AppendEnvelope records envelope at the end of the stream, assigning
+	// its Sequence, and returns the recorded envelope.
+	AppendEnvelope(envelope Envelope) (Envelope, error)
+}
+
+// MemoryStore is an EventStore that keeps all envelopes in memory.
+// It is useful for tests and for processes that rebuild read models
+// from the events seen since startup.
+type MemoryStore struct {
+	// envelopes are in sequence order. Sequences have gaps where a
+	// memory budget evicted envelopes.
+	envelopes []Envelope
+	// evicted flags the envelopes a memory budget evicted, by position in
+	// envelopes, until compact removes them; Read skips them. It is only
+	// kept with a budget.
+	evicted []atomic.Bool
+	// dead is the number of envelopes flagged in evicted.
+	dead int
+	// last is the sequence of the last envelope appended or restored,
+	// even if it has been removed since.
+	last  uint64
+	mutex sync.RWMutex
+	// budget bounds the memory of the envelopes, charged by entries;
+	// see NewMemoryStoreWithBudget.
+	budget  *MemoryBudget
+	entries map[uint64]*budgetEntry
+}
+
+// NewMemoryStore creates an empty in-memory event store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// NewMemoryStoreWithBudget creates an empty in-memory event store whose
+// envelopes are charged to budget, per topic and in total. Once a limit is
+// exceeded, the oldest envelopes of the topic or of all topics are
+// evicted, leaving gaps in the sequence numbers Read reports.
+//
+// Example:
+//
+//	budget := eventbus.NewMemoryBudget(16 << 20)
+//	budget.LimitTopic("input:*", 1<<20)
+//	bus := eventbus.New(eventbus.WithStore(eventbus.NewMemoryStoreWithBudget(budget)))
+func NewMemoryStoreWithBudget(budget *MemoryBudget) *MemoryStore {
+	return &MemoryStore{budget: budget, entries: make(map[uint64]*budgetEntry)}
+}
+
+// Append records event at the end of the stream.
+func (store *MemoryStore) Append(event Event) (Envelope, error) {
+	return store.AppendMetadata(event, nil)
+}
+
+// AppendMetadata records event with metadata at the end of the stream.
+func (store *MemoryStore) AppendMetadata(event Event, metadata map[string]string) (Envelope, error) {
+	return store.AppendEnvelope(Envelope{
+		CorrelationID: correlationID(event),
+		Metadata:      metadata,
+		Event:         event,
+	})
+}
+
+// AppendEnvelope records envelope at the end of the stream, assigning its
+// sequence, and its times if it has none.
+func (store *MemoryStore) AppendEnvelope(envelope Envelope) (Envelope, error) {
+	store.mutex.Lock()
+	envelope.Sequence = store.last + 1
+	if envelope.Time.IsZero() {
+		envelope.Time = time.Now()
+	}
+	if envelope.OriginTime.IsZero() {
+		envelope.OriginTime = envelope.Time
+	}
+	victims := store.add(envelope)
+	store.mutex.Unlock()
+	evictAll(victims)
+	return envelope, nil
+}
+
+// Restore appends envelope with its original metadata. Its sequence must
+// follow the last envelope in the store.
+func (store *MemoryStore) Restore(envelope Envelope) error {
+	store.mutex.Lock()
+	if len(store.envelopes) == 0 && store.last == 0 && envelope.Sequence > 0 {
+		// An empty store can start from a truncated history.
+		store.last = envelope.Sequence - 1
+	}
+	if next := store.last + 1; envelope.Sequence != next {
+		store.mutex.Unlock()
+		return fmt.Errorf("eventbus: restoring sequence %d, expected %d", envelope.Sequence, next)
+	}
+	victims := store.add(envelope)
+	store.mutex.Unlock()
+	evictAll(victims)
+	return nil
+}
+
+// RestoreSparse appends envelope with its original metadata, like
+// Restore, but accepts any sequence greater than that of the last
+// envelope, so histories with the holes left by memory budgets and
+// truncation can be restored.
+func (store *MemoryStore) RestoreSparse(envelope Envelope) error {
+	store.mutex.Lock()
+	if envelope.Sequence <= store.last {
+		store.mutex.Unlock()
+		return fmt.Errorf("eventbus: restoring sequence %d after %d", envelope.Sequence, store.last)
+	}
+	victims := store.add(envelope)
+	store.mutex.Unlock()
+	evictAll(victims)
+	return nil
+}
+
+// add appends envelope, charging it to the budget, and returns the
+// entries to evict. The caller must hold store.mutex.
+func (store *MemoryStore) add(envelope Envelope) map[budgetOwner][]*budgetEntry {
+	store.envelopes = append(store.envelopes, envelope)
+	store.last = envelope.Sequence
+	if store.budget == nil {
+		return nil
+	}
+	store.evicted = append(store.evicted, atomic.Bool{})
+	entry := &budgetEntry{
+		owner: store,
+		key:   envelope.Sequence,
+		topic: envelope.Event.GetType(),
+		size:  sizeOf(envelope),
+	}
+	store.entries[envelope.Sequence] = entry
+	return store.budget.charge(entry)
+}
+
+// TruncateBefore removes every envelope whose sequence is below sequence.
+// Sequence numbers of the remaining envelopes are unchanged.
+func (store *MemoryStore) TruncateBefore(sequence uint64) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	drop := store.index(sequence)
+	for i, envelope := range store.envelopes[:drop] {
+		if store.evicted != nil && store.evicted[i].Load() {
+			store.dead--
+			continue
+		}
+		store.release(envelope.Sequence)
+	}
+	// Readers may still hold the old slices, so they are resliced rather
+	// than modified.
+	store.envelopes = store.envelopes[drop:]
+	if store.evicted != nil {
+		store.evicted = store.evicted[drop:]
+	}
+	return nil
+}
+
+// Read calls fn for every envelope whose sequence is at least from.
+func (store *MemoryStore) Read(from uint64, fn func(Envelope) error) error {
+	store.mutex.RLock()
+	start := store.index(from)
+	envelopes := store.envelopes[start:]
+	var evicted []atomic.Bool
+	if store.evicted != nil {
+		evicted = store.evicted[start:]
+	}
+	store.mutex.RUnlock()
+
+	for i, envelope := range envelopes {
+		if evicted != nil && evicted[i].Load() {
+			continue
+		}
+		if err := fn(envelope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// index returns the position of the first envelope whose sequence is at
+// least sequence. The caller must hold store.mutex.
+func (store *MemoryStore) index(sequence uint64) int {
+	if len(store.envelopes) == 0 || sequence <= store.envelopes[0].Sequence {
+		return 0
+	}
+	i, _ := slices.BinarySearchFunc(store.envelopes, sequence, func(envelope Envelope, sequence uint64) int {
+		return cmp.Compare(envelope.Sequence, sequence)
+	})
+	return i
+}
+
+// release stops charging the envelope with sequence to the budget. The
+// caller must hold store.mutex.
+func (store *MemoryStore) release(sequence uint64) {
+	if entry, ok := store.entries[sequence]; ok {
+		store.budget.release(entry)
+		delete(store.entries, sequence)
+	}
+}
+
+// evict removes the envelopes of entries still stored.
+func (store *MemoryStore) evict(entries []*budgetEntry) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for _, entry := range entries {
+		sequence := entry.key.(uint64)
+		if store.entries[sequence] != entry {
+			continue
+		}
+		delete(store.entries, sequence)
+		// Readers may still hold the slices, so the envelope is only
+		// flagged.
+		store.evicted[store.index(sequence)].Store(true)
+		store.dead++
+	}
+	store.compact()
+}
+
+// compact drops the evicted envelopes at the start of the store, and
+// copies the others once evicted envelopes make up half of it, so an
+// eviction takes amortized constant time instead of copying the store.
+// The caller must hold store.mutex.
+func (store *MemoryStore) compact() {
+	for len(store.evicted) > 0 && store.evicted[0].Load() {
+		store.envelopes = store.envelopes[1:]
+		store.evicted = store.evicted[1:]
+		store.dead--
+	}
+	if store.dead == 0 || store.dead*2 < len(store.envelopes) {
+		return
+	}
+	// Readers may still hold the old slices, so they are not modified.
+	envelopes := make([]Envelope, 0, len(store.envelopes)-store.dead)
+	for i, envelope := range store.envelopes {
+		if !store.evicted[i].Load() {
+			envelopes = append(envelopes, envelope)
+		}
+	}
+	store.envelopes = envelopes
+	store.evicted = make([]atomic.Bool, len(envelopes))
+	store.dead = 0
+}
+
+// WithStore persists every published event to store before it is delivered.
+// Persisting first guarantees that the store's order matches delivery order
+// and that no listener sees an event the store does not have.
+//
+// If the store fails to append an event, the event is not delivered and
+// the store's error, matching ErrStoreAppend, is returned by the
+// publishing methods that return errors, such as PublishAndWait. Publish
+// and PublishContext cannot return it, so they panic with it.
+//
+// Example:
+//
+//	store := eventbus.NewMemoryStore()
+//	bus := eventbus.New(eventbus.WithStore(store))
+func WithStore(store EventStore) Option {
+	return func(bus *eventBusImpl) {
+		bus.store = store
+	}
+}
+
+// SequenceFromContext returns the store sequence of the event being
+// delivered to a listener subscribed with SubscribeContext. It reports
+// false if the bus has no store.
+//
+// Example:
+//
+//	bus.SubscribeContext("order:placed", func(ctx context.Context, event eventbus.Event) {
+//	    if sequence, ok := eventbus.SequenceFromContext(ctx); ok {
+//	        checkpoint.Save(sequence)
+//	    }
+//	})
+func SequenceFromContext(ctx context.Context) (uint64, bool) {
+	envelope, ok := ctx.Value(envelopeKey{}).(*Envelope)
+	if !ok || envelope.Sequence == 0 {
+		return 0, false
+	}
+	return envelope.Sequence, true
+}
+
+// persist appends envelope to the configured store, if any, and sets its
+// sequence. Stores implementing EnvelopeAppender keep the whole envelope;
+// metadata is otherwise only kept by stores implementing MetadataAppender.
+// A failure is remembered for Healthy until the next successful append.
+func (bus *eventBusImpl) persist(envelope *Envelope) error {
+	if bus.store == nil {
+		return nil
+	}
+	var stored Envelope
+	var err error
+	if appender, ok := bus.store.(EnvelopeAppender); ok {
+		stored, err = appender.AppendEnvelope(*envelope)
+	} else if appender, ok := bus.store.(MetadataAppender); ok && envelope.Metadata != nil {
+		stored, err = appender.AppendMetadata(envelope.Event, envelope.Metadata)
+	} else {
+		stored, err = bus.store.Append(envelope.Event)
+	}
+	if err != nil {
+		bus.storeFailure.Store(&err)
+		return fmt.Errorf("%w for %q: %w", ErrStoreAppend, envelope.Event.GetType(), err)
+	}
+	if bus.storeFailure.Load() != nil {
+		bus.storeFailure.Store(nil)
+	}
+	envelope.Sequence = stored.Sequence
+	return nil
+}
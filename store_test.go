@@ -0,0 +1,176 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestMemoryStore verifies that appended events are read back in order
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+
+	for _, data := range []string{"a", "b", "c"} {
+		if _, err := store.Append(testEvent{eventType: "store:test", data: data}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	var sequences []uint64
+	var data string
+	err := store.Read(2, func(envelope Envelope) error {
+		sequences = append(sequences, envelope.Sequence)
+		data += envelope.Event.(testEvent).data
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	if data != "bc" {
+		t.Errorf("Expected events 'bc', got '%s'", data)
+	}
+	if len(sequences) != 2 || sequences[0] != 2 || sequences[1] != 3 {
+		t.Errorf("Expected sequences [2 3], got %v", sequences)
+	}
+}
+
+// TestMemoryStoreReadStops verifies that Read returns the callback's error
+func TestMemoryStoreReadStops(t *testing.T) {
+	store := NewMemoryStore()
+	store.Append(testEvent{eventType: "store:test"})
+	store.Append(testEvent{eventType: "store:test"})
+
+	stop := errors.New("stop")
+	calls := 0
+	err := store.Read(0, func(envelope Envelope) error {
+		calls++
+		return stop
+	})
+
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected stop error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+// TestWithStore verifies that published events are persisted
+func TestWithStore(t *testing.T) {
+	store := NewMemoryStore()
+	bus := New(WithStore(store))
+
+	bus.Publish(testEvent{eventType: "store:one", data: "1"})
+	bus.Publish(testEvent{eventType: "store:two", data: "2"})
+
+	var types []EventType
+	store.Read(1, func(envelope Envelope) error {
+		types = append(types, envelope.Event.GetType())
+		return nil
+	})
+
+	if len(types) != 2 || types[0] != "store:one" || types[1] != "store:two" {
+		t.Errorf("Expected both events persisted in order, got %v", types)
+	}
+}
+
+// TestSequenceFromContext verifies that listeners see the store sequence of the event
+func TestSequenceFromContext(t *testing.T) {
+	bus := New(WithStore(NewMemoryStore()))
+
+	var sequences []uint64
+	bus.SubscribeContext("store:two", func(ctx context.Context, event Event) {
+		sequence, ok := SequenceFromContext(ctx)
+		if !ok {
+			t.Error("Expected a sequence in the listener context")
+		}
+		sequences = append(sequences, sequence)
+	})
+	bus.Publish(testEvent{eventType: "store:one"})
+	bus.Publish(testEvent{eventType: "store:two"})
+	bus.Publish(testEvent{eventType: "store:two"})
+
+	if len(sequences) != 2 || sequences[0] != 2 || sequences[1] != 3 {
+		t.Errorf("Expected sequences [2 3], got %v", sequences)
+	}
+
+	plain := New()
+	plain.SubscribeContext("store:one", func(ctx context.Context, event Event) {
+		if _, ok := SequenceFromContext(ctx); ok {
+			t.Error("Expected no sequence without a store")
+		}
+	})
+	plain.Publish(testEvent{eventType: "store:one"})
+}
+
+type failingStore struct{ MemoryStore }
+
+func (s *failingStore) Append(event Event) (Envelope, error) {
+	return Envelope{}, errors.New("disk full")
+}
+
+func (s *failingStore) AppendEnvelope(envelope Envelope) (Envelope, error) {
+	return s.Append(envelope.Event)
+}
+
+// TestWithStoreFailure verifies that events are not delivered when persisting fails
+func TestWithStoreFailure(t *testing.T) {
+	bus := New(WithStore(&failingStore{}))
+	delivered := false
+
+	bus.Subscribe("store:test", func(event Event) {
+		delivered = true
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Publish to panic")
+		}
+		if delivered {
+			t.Error("Event should not have been delivered")
+		}
+	}()
+
+	bus.Publish(testEvent{eventType: "store:test"})
+}
+
+// TestWithStoreFailureReturned verifies that the publishing methods returning errors report a failed append instead of panicking
+func TestWithStoreFailureReturned(t *testing.T) {
+	bus := New(WithStore(&failingStore{}))
+	defer bus.Close()
+	bus.Subscribe("store:test", func(event Event) {
+		t.Error("Event should not have been delivered")
+	})
+
+	if err := bus.PublishAndWait(context.Background(), testEvent{eventType: "store:test"}); !errors.Is(err, ErrStoreAppend) {
+		t.Errorf("Expected ErrStoreAppend, got %v", err)
+	}
+	if _, err := bus.PublishDetailed(context.Background(), testEvent{eventType: "store:test"}); !errors.Is(err, ErrStoreAppend) {
+		t.Errorf("Expected ErrStoreAppend from PublishDetailed, got %v", err)
+	}
+}
+
+// TestMemoryStoreRestoreSparse verifies that increasing sequences with holes are restored and others rejected
+func TestMemoryStoreRestoreSparse(t *testing.T) {
+	store := NewMemoryStore()
+	for _, sequence := range []uint64{2, 5, 9} {
+		if err := store.RestoreSparse(Envelope{Sequence: sequence, Event: testEvent{eventType: "store:test"}}); err != nil {
+			t.Fatalf("Expected sequence %d restored, got %v", sequence, err)
+		}
+	}
+	if err := store.RestoreSparse(Envelope{Sequence: 9, Event: testEvent{eventType: "store:test"}}); err == nil {
+		t.Error("Expected a repeated sequence to be rejected")
+	}
+	var sequences []uint64
+	store.Read(1, func(envelope Envelope) error {
+		sequences = append(sequences, envelope.Sequence)
+		return nil
+	})
+	if len(sequences) != 3 || sequences[1] != 5 {
+		t.Errorf("Expected sequences 2, 5, and 9, got %v", sequences)
+	}
+	if envelope, _ := store.Append(testEvent{eventType: "store:test"}); envelope.Sequence != 10 {
+		t.Errorf("Expected appends to continue at 10, got %d", envelope.Sequence)
+	}
+}
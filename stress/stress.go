@@ -0,0 +1,281 @@
+// Package stress runs configurable publisher and subscriber topologies
+// against an event bus and reports throughput, delivery latency, and drops.
+// It is meant for soak tests and for validating dispatch configurations
+// before adopting them.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithAsync(8, 1024))
+//	defer bus.Close()
+//
+//	report, err := stress.Run(ctx, bus, stress.Config{
+//	    Topics:         16,
+//	    Publishers:     4,
+//	    Subscribers:    3,
+//	    Rate:           10000,
+//	    PayloadSize:    256,
+//	    HandlerLatency: 50 * time.Microsecond,
+//	    Duration:       30 * time.Second,
+//	})
+//	fmt.Println(report)
+package stress
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// maxSamples bounds the number of latency samples kept for percentiles.
+const maxSamples = 100_000
+
+// Config describes a stress topology. Zero fields use the defaults noted.
+type Config struct {
+	// Topics is the number of topics events are spread over. Default 1.
+	Topics int
+	// Publishers is the number of concurrent publishing goroutines.
+	// Default 1.
+	Publishers int
+	// Subscribers is the number of listeners per topic. Default 1.
+	Subscribers int
+	// Rate is the number of events each publisher publishes per second.
+	// Zero publishes as fast as possible.
+	Rate float64
+	// PayloadSize is the size in bytes of each event's payload.
+	PayloadSize int
+	// HandlerLatency is how long each listener works on an event.
+	HandlerLatency time.Duration
+	// Duration is how long publishers run.
+	Duration time.Duration
+	// Events is the number of events each publisher publishes. If both
+	// Duration and Events are set, publishers stop at whichever comes
+	// first; if neither is, Events defaults to 1000.
+	Events int
+	// Drain is how long Run waits after publishing for queued deliveries
+	// to complete. Default 10 seconds.
+	Drain time.Duration
+}
+
+// Event is the event published by Run.
+type Event struct {
+	Topic   eventbus.EventType
+	Sent    time.Time
+	Payload []byte
+}
+
+// GetType returns the topic of the event.
+func (e Event) GetType() eventbus.EventType {
+	return e.Topic
+}
+
+// Latency summarizes the time from publishing an event to a listener
+// receiving it.
+type Latency struct {
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	// Published is the number of events published.
+	Published uint64
+	// Delivered is the number of listener invocations.
+	Delivered uint64
+	// Dropped is the number of deliveries the bus dropped during the run.
+	Dropped uint64
+	// Elapsed is the time from the first publish to the last delivery.
+	Elapsed time.Duration
+	// Throughput is the number of deliveries per second.
+	Throughput float64
+	// Latency summarizes the delivery latencies.
+	Latency Latency
+}
+
+// String formats the report on one line.
+func (r Report) String() string {
+	return fmt.Sprintf("published=%d delivered=%d dropped=%d elapsed=%v throughput=%.0f/s p50=%v p90=%v p99=%v max=%v",
+		r.Published, r.Delivered, r.Dropped, r.Elapsed, r.Throughput,
+		r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.Max)
+}
+
+// Run subscribes the configured listeners on bus, publishes until the
+// configured duration or event count is reached, and waits for the
+// deliveries to complete. Publishing stops early if ctx is done. The
+// listeners are cancelled before Run returns.
+//
+// Dropped deliveries are counted from bus.Stats, so other traffic on the
+// bus during the run is included.
+func Run(ctx context.Context, bus eventbus.EventBus, config Config) (Report, error) {
+	config = withDefaults(config)
+
+	topics := make([]eventbus.EventType, config.Topics)
+	for i := range topics {
+		topics[i] = eventbus.EventType(fmt.Sprintf("stress:%d", i))
+	}
+
+	run := &run{samples: make([]time.Duration, 0, 1024)}
+	var subscriptions []*eventbus.Subscription
+	for _, topic := range topics {
+		for i := 0; i < config.Subscribers; i++ {
+			subscriptions = append(subscriptions, bus.Subscribe(topic, func(event eventbus.Event) {
+				run.record(time.Since(event.(Event).Sent))
+				if config.HandlerLatency > 0 {
+					time.Sleep(config.HandlerLatency)
+				}
+				run.delivered.Add(1)
+			}))
+		}
+	}
+	defer func() {
+		for _, sub := range subscriptions {
+			sub.Cancel()
+		}
+	}()
+
+	before := bus.Stats()
+	begin := time.Now()
+
+	publishCtx := ctx
+	if config.Duration > 0 {
+		var cancel context.CancelFunc
+		publishCtx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	var publishers sync.WaitGroup
+	for p := 0; p < config.Publishers; p++ {
+		publishers.Add(1)
+		go func() {
+			defer publishers.Done()
+			run.publish(publishCtx, bus, config, topics, p)
+		}()
+	}
+	publishers.Wait()
+
+	published := run.published.Load()
+	expected := published * uint64(config.Subscribers)
+	deadline := time.Now().Add(config.Drain)
+	for {
+		dropped := bus.Stats().Dropped - before.Dropped
+		if run.delivered.Load()+dropped >= expected || time.Now().After(deadline) || ctx.Err() != nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	elapsed := time.Since(begin)
+
+	report := Report{
+		Published: published,
+		Delivered: run.delivered.Load(),
+		Dropped:   bus.Stats().Dropped - before.Dropped,
+		Elapsed:   elapsed,
+		Latency:   run.latency(),
+	}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		report.Throughput = float64(report.Delivered) / seconds
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// withDefaults fills in the zero fields of config.
+func withDefaults(config Config) Config {
+	config.Topics = max(config.Topics, 1)
+	config.Publishers = max(config.Publishers, 1)
+	config.Subscribers = max(config.Subscribers, 1)
+	if config.Duration <= 0 && config.Events <= 0 {
+		config.Events = 1000
+	}
+	if config.Drain <= 0 {
+		config.Drain = 10 * time.Second
+	}
+	return config
+}
+
+// run holds the counters and latency samples of one Run.
+type run struct {
+	published atomic.Uint64
+	delivered atomic.Uint64
+
+	// samples is a uniform reservoir sample of the latencies.
+	samples []time.Duration
+	seen    uint64
+	max     time.Duration
+	mutex   sync.Mutex
+}
+
+// publish publishes events round-robin over topics at the configured rate
+// until the event count is reached or ctx is done.
+func (r *run) publish(ctx context.Context, bus eventbus.EventBus, config Config, topics []eventbus.EventType, publisher int) {
+	var interval time.Duration
+	if config.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / config.Rate)
+	}
+
+	begin := time.Now()
+	for i := 0; config.Events <= 0 || i < config.Events; i++ {
+		if ctx.Err() != nil {
+			return
+		}
+		if interval > 0 {
+			if wait := time.Until(begin.Add(time.Duration(i) * interval)); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		topic := topics[(publisher+i)%len(topics)]
+		bus.PublishContext(ctx, Event{Topic: topic, Sent: time.Now(), Payload: make([]byte, config.PayloadSize)})
+		r.published.Add(1)
+	}
+}
+
+// record adds a latency sample.
+func (r *run) record(latency time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.seen++
+	r.max = max(r.max, latency)
+	if len(r.samples) < maxSamples {
+		r.samples = append(r.samples, latency)
+		return
+	}
+	if i := rand.Uint64N(r.seen); i < maxSamples {
+		r.samples[i] = latency
+	}
+}
+
+// latency returns the percentiles of the recorded samples.
+func (r *run) latency() Latency {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.samples) == 0 {
+		return Latency{}
+	}
+	sorted := slices.Clone(r.samples)
+	slices.Sort(sorted)
+	percentile := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+	return Latency{
+		P50: percentile(0.50),
+		P90: percentile(0.90),
+		P99: percentile(0.99),
+		Max: r.max,
+	}
+}
@@ -0,0 +1,87 @@
+package stress
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Papiermond/eventbus"
+)
+
+// TestRunSync verifies that every event is delivered to every subscriber on a synchronous bus
+func TestRunSync(t *testing.T) {
+	bus := eventbus.New()
+	defer bus.Close()
+
+	report, err := Run(context.Background(), bus, Config{
+		Topics:      4,
+		Publishers:  2,
+		Subscribers: 3,
+		PayloadSize: 64,
+		Events:      100,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Published != 200 {
+		t.Errorf("Expected 200 published events, got %d", report.Published)
+	}
+	if report.Delivered != 600 {
+		t.Errorf("Expected 600 deliveries, got %d", report.Delivered)
+	}
+	if report.Dropped != 0 || report.Throughput <= 0 {
+		t.Errorf("Expected no drops and positive throughput, got %v", report)
+	}
+	if report.Latency.Max < report.Latency.P50 {
+		t.Errorf("Expected max latency to be at least p50, got %v", report.Latency)
+	}
+	if count := len(bus.Snapshot().Subscriptions()); count != 0 {
+		t.Errorf("Expected listeners to be cancelled, got %d", count)
+	}
+}
+
+// TestRunDrops verifies that deliveries dropped by a full queue are reported
+func TestRunDrops(t *testing.T) {
+	bus := eventbus.New(eventbus.WithTopicConfig("stress:*", eventbus.TopicConfig{
+		Async:     true,
+		Workers:   1,
+		QueueSize: 1,
+		Overflow:  eventbus.OverflowDropNewest,
+	}))
+	defer bus.Close()
+
+	report, err := Run(context.Background(), bus, Config{
+		HandlerLatency: time.Millisecond,
+		Events:         50,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Dropped == 0 {
+		t.Errorf("Expected dropped deliveries, got %v", report)
+	}
+	if report.Delivered+report.Dropped != report.Published {
+		t.Errorf("Expected delivered and dropped to add up to published, got %v", report)
+	}
+}
+
+// TestRunDuration verifies that publishers stop after the configured duration at the configured rate
+func TestRunDuration(t *testing.T) {
+	bus := eventbus.New(eventbus.WithAsync(2, 64))
+	defer bus.Close()
+
+	report, err := Run(context.Background(), bus, Config{
+		Rate:     1000,
+		Duration: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Published == 0 || report.Published > 60 {
+		t.Errorf("Expected about 50 published events, got %d", report.Published)
+	}
+	if !strings.Contains(report.String(), "published=") {
+		t.Errorf("Expected a formatted report, got %q", report.String())
+	}
+}
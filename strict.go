@@ -0,0 +1,83 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// ErrUnknownTopic is returned when an event type missing from the known
+// topics is published on a bus rejecting unknown topics.
+var ErrUnknownTopic = errors.New("eventbus: unknown topic")
+
+// WithKnownTopics catches misspelled topics by checking every published
+// and subscribed event type against topics, which may contain "*"
+// wildcards as in WithTopicConfig. The bus's own "eventbus:*" topics are
+// always known. A generated topics package provides the list; see the
+// eventbus-topics command.
+//
+// Without reject, a warning is written to the standard logger the first
+// time an unknown event type is used. With reject, publishing an unknown
+// event type fails: PublishAndWait and PublishDetailed return an error
+// matching ErrUnknownTopic and Publish panics with it, and so does
+// Subscribe, so typos surface when listeners are registered at startup.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithKnownTopics(true, topics.All...))
+func WithKnownTopics(reject bool, topics ...EventType) Option {
+	return func(bus *eventBusImpl) {
+		bus.knownTopics = &knownTopics{
+			reject:   reject,
+			patterns: append([]EventType{"eventbus:*"}, topics...),
+			checked:  make(map[EventType]error),
+		}
+	}
+}
+
+// knownTopics remembers the verdict for every used event type.
+type knownTopics struct {
+	reject   bool
+	patterns []EventType
+	checked  map[EventType]error
+	mutex    sync.Mutex
+}
+
+// check returns an error if eventType is unknown and unknown topics are
+// rejected. action describes the use for the error message. Warnings are
+// logged once per event type.
+func (k *knownTopics) check(eventType EventType, action string) error {
+	if k == nil {
+		return nil
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	err, ok := k.checked[eventType]
+	if !ok {
+		if !k.known(eventType) {
+			err = fmt.Errorf("%w: %q", ErrUnknownTopic, eventType)
+			if !k.reject {
+				log.Printf("%v used in %s; add it to the known topics", err, action)
+			}
+		}
+		k.checked[eventType] = err
+	}
+
+	if k.reject && err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	return nil
+}
+
+// known reports whether eventType matches any known topic.
+func (k *knownTopics) known(eventType EventType) bool {
+	for _, pattern := range k.patterns {
+		if matchPattern(pattern, eventType) {
+			return true
+		}
+	}
+	return false
+}
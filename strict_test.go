@@ -0,0 +1,95 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestKnownTopicsWarns verifies that unknown topics are logged once and still delivered
+func TestKnownTopicsWarns(t *testing.T) {
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+
+	bus := New(WithKnownTopics(false, "player:jumped"))
+	count := 0
+	bus.Subscribe("player:jump", func(event Event) { count++ })
+	bus.Subscribe("player:jumped", func(event Event) { count++ })
+
+	bus.Publish(testEvent{eventType: "player:jump"})
+	bus.Publish(testEvent{eventType: "player:jump"})
+	bus.Publish(testEvent{eventType: "player:jumped"})
+
+	if count != 3 {
+		t.Errorf("Expected 3 deliveries, got %d", count)
+	}
+	if strings.Count(logged.String(), `"player:jump"`) != 1 {
+		t.Errorf("Expected a single warning, got %q", logged.String())
+	}
+	if strings.Contains(logged.String(), `"player:jumped"`) {
+		t.Errorf("Expected no warning for a known topic, got %q", logged.String())
+	}
+}
+
+// TestKnownTopicsRejects verifies that unknown topics fail to publish and subscribe
+func TestKnownTopicsRejects(t *testing.T) {
+	bus := New(WithKnownTopics(true, "player:jumped", "telemetry:*"))
+	count := 0
+	bus.Subscribe("player:jumped", func(event Event) { count++ })
+	bus.Subscribe("telemetry:fps", func(event Event) { count++ })
+
+	err := bus.PublishAndWait(context.Background(), testEvent{eventType: "player:jump"})
+	if !errors.Is(err, ErrUnknownTopic) {
+		t.Errorf("Expected ErrUnknownTopic, got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Expected Publish to panic for an unknown topic")
+			}
+		}()
+		bus.Publish(testEvent{eventType: "player:jump"})
+	}()
+
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			if !errors.Is(err, ErrUnknownTopic) {
+				t.Errorf("Expected Subscribe to panic with ErrUnknownTopic, got %v", err)
+			}
+		}()
+		bus.Subscribe("player:jump", func(event Event) {})
+	}()
+
+	bus.Publish(testEvent{eventType: "player:jumped"})
+	bus.Publish(testEvent{eventType: "telemetry:fps"})
+	if count != 2 {
+		t.Errorf("Expected the known topics to be delivered, got %d", count)
+	}
+}
+
+// TestKnownTopicsBusEvents verifies that the bus's own topics are always known
+func TestKnownTopicsBusEvents(t *testing.T) {
+	bus := New(WithKnownTopics(true), WithHeartbeat(time.Millisecond))
+	defer bus.Close()
+
+	received := make(chan Event, 1)
+	bus.Subscribe(HeartbeatType, func(event Event) {
+		select {
+		case received <- event:
+		default:
+		}
+	})
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Error("Expected a heartbeat on a bus with known topics")
+	}
+}
@@ -0,0 +1,128 @@
+package eventbus
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Subscription is a listener registered with Subscribe or SubscribeContext.
+// It can be cancelled to stop the listener.
+type Subscription struct {
+	bus       *eventBusImpl
+	eventType EventType
+	// opts are the options given to Subscribe, kept for Snapshot and
+	// CloneInto.
+	opts    []SubscribeOption
+	options []string
+	// stage orders the listener's delivery; see WithStage.
+	stage  int
+	staged bool
+	// name, after, and before order the listener; see After.
+	name   string
+	after  []string
+	before []string
+	// priority orders the listener; see WithListenerPriority.
+	priority int
+	// handler holds the current listener; see Swap.
+	handler  atomic.Pointer[handlerVersion]
+	swapping sync.Mutex
+	// deliver calls the listener with the subscribe options applied.
+	deliver ContextListener
+	// ctx is cancelled by Cancel.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// health tracks recent outcomes; see WithHealth.
+	health *health
+}
+
+// Cancel unsubscribes the listener and cancels the context of its
+// in-flight invocations, so long-running listeners subscribed with
+// SubscribeContext can stop promptly. Deliveries still waiting in an
+// asynchronous queue are skipped. Cancel may be called from within the
+// listener and more than once.
+//
+// Example:
+//
+//	sub := bus.SubscribeContext("frame:captured", func(ctx context.Context, event eventbus.Event) {
+//	    encodeStream(ctx, event.(FrameCaptured))
+//	})
+//	defer sub.Cancel()
+func (s *Subscription) Cancel() {
+	s.cancel()
+
+	bus := s.bus
+	bus.subscribersMutex.Lock()
+
+	// Publishers may still hold the old slices, so they are not modified.
+	isThis := func(other *Subscription) bool { return other == s }
+	before := len(bus.listeners[s.eventType])
+	bus.listeners[s.eventType] = slices.DeleteFunc(slices.Clone(bus.listeners[s.eventType]), isThis)
+	if len(bus.listeners[s.eventType]) < before && s.hasDependencies() {
+		bus.dependent[s.eventType]--
+	}
+	if bus.router != nil && before > 0 && len(bus.listeners[s.eventType]) == 0 {
+		bus.router.Remove(s.eventType)
+	}
+	subscribed := len(bus.subscriptions)
+	bus.subscriptions = slices.DeleteFunc(slices.Clone(bus.subscriptions), isThis)
+	removed := len(bus.subscriptions) < subscribed
+	bus.subscribersMutex.Unlock()
+
+	if removed && bus.hooks.OnUnsubscribe != nil {
+		bus.hooks.OnUnsubscribe(s.info(s.Health()))
+	}
+}
+
+// Name returns the label given with WithName, or "" if there is none.
+func (s *Subscription) Name() string {
+	return s.name
+}
+
+// call delivers event unless the subscription has been cancelled.
+func (s *Subscription) call(ctx context.Context, event Event) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if s.bus.trace != nil {
+		defer s.bus.traceHandler(ctx, s, event)()
+	}
+	if s.bus.latency != nil {
+		defer s.bus.latency.observe(ctx, event)()
+	}
+	if s.bus.costs != nil {
+		defer s.bus.costs.end(s, s.bus.costs.begin())
+	}
+	if s.bus.hooks.OnDeliver != nil {
+		defer s.bus.observeDelivery(ctx, s, event)()
+	}
+	if s.health != nil {
+		return s.observe(func() { s.deliver(ctx, event) })
+	}
+	s.deliver(ctx, event)
+	return nil
+}
+
+// cancelContext carries the values of one context and the cancellation
+// of another.
+type cancelContext struct {
+	context.Context
+	cancellation context.Context
+}
+
+// Deadline returns the deadline of the cancelling context.
+func (c cancelContext) Deadline() (time.Time, bool) {
+	return c.cancellation.Deadline()
+}
+
+// Done returns the done channel of the cancelling context.
+func (c cancelContext) Done() <-chan struct{} {
+	return c.cancellation.Done()
+}
+
+// Err returns the error of the cancelling context.
+func (c cancelContext) Err() error {
+	return c.cancellation.Err()
+}
@@ -0,0 +1,112 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSubscriptionCancel verifies that a cancelled listener no longer receives events
+func TestSubscriptionCancel(t *testing.T) {
+	bus := New()
+	first, second := 0, 0
+
+	sub := bus.Subscribe("cancel:test", func(event Event) { first++ })
+	bus.Subscribe("cancel:test", func(event Event) { second++ })
+
+	bus.Publish(testEvent{eventType: "cancel:test"})
+	sub.Cancel()
+	sub.Cancel()
+	bus.Publish(testEvent{eventType: "cancel:test"})
+
+	if first != 1 || second != 2 {
+		t.Errorf("Expected 1 and 2 deliveries, got %d and %d", first, second)
+	}
+	if len(bus.Snapshot().Subscriptions()) != 1 {
+		t.Error("Expected the cancelled subscription to be removed from snapshots")
+	}
+}
+
+// TestSubscriptionCancelFromListener verifies that listeners can cancel themselves during delivery
+func TestSubscriptionCancelFromListener(t *testing.T) {
+	bus := New()
+	count := 0
+
+	var sub *Subscription
+	sub = bus.Subscribe("cancel:test", func(event Event) {
+		count++
+		sub.Cancel()
+	})
+
+	bus.Publish(testEvent{eventType: "cancel:test"})
+	bus.Publish(testEvent{eventType: "cancel:test"})
+
+	if count != 1 {
+		t.Errorf("Expected 1 delivery, got %d", count)
+	}
+}
+
+// TestSubscriptionCancelInFlight verifies that Cancel stops a running async handler
+func TestSubscriptionCancelInFlight(t *testing.T) {
+	bus := New(WithAsync(1, 4))
+	defer bus.Close()
+
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	sub := bus.SubscribeContext("stream:frame", func(ctx context.Context, event Event) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			stopped <- ctx.Err()
+		case <-time.After(5 * time.Second):
+			stopped <- nil
+		}
+	})
+
+	bus.Publish(testEvent{eventType: "stream:frame"})
+	<-started
+	sub.Cancel()
+
+	if err := <-stopped; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the handler context to be cancelled, got %v", err)
+	}
+}
+
+// TestSubscriptionCancelSkipsQueued verifies that queued deliveries of a cancelled subscription are skipped
+func TestSubscriptionCancelSkipsQueued(t *testing.T) {
+	bus := New(WithAsync(1, 4))
+	defer bus.Close()
+
+	block := make(chan struct{})
+	blocking := make(chan struct{})
+	bus.Subscribe("blocker", func(event Event) {
+		close(blocking)
+		<-block
+	})
+	count := 0
+	sub := bus.Subscribe("cancel:test", func(event Event) { count++ })
+
+	bus.Publish(testEvent{eventType: "blocker"})
+	<-blocking
+	done := make(chan *Receipt)
+	go func() {
+		receipt, _ := bus.PublishDetailed(context.Background(), testEvent{eventType: "cancel:test"})
+		done <- receipt
+	}()
+
+	// Wait until the delivery is queued behind the blocker
+	for len(bus.(*eventBusImpl).dispatch.fallback.pool.(*workerPool).queue) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	sub.Cancel()
+	close(block)
+
+	receipt := <-done
+	if count != 0 {
+		t.Errorf("Expected no delivery after Cancel, got %d", count)
+	}
+	if !errors.Is(receipt.Err(), context.Canceled) {
+		t.Errorf("Expected the skipped delivery to report context.Canceled, got %v", receipt.Err())
+	}
+}
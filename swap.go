@@ -0,0 +1,158 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// handlerVersion is one listener of a subscription. Swap replaces the
+// version of a subscription and waits for the calls of the old one.
+type handlerVersion struct {
+	listener ContextListener
+	// buffer holds the events delivered while Swap waits for the old
+	// version; nil once the new listener is live.
+	buffer *swapBuffer
+
+	calls   atomic.Int64
+	retired atomic.Bool
+	drained chan struct{}
+	once    sync.Once
+}
+
+// swapBuffer queues deliveries in order until Swap flushes it.
+type swapBuffer struct {
+	mutex   sync.Mutex
+	pending []bufferedDelivery
+	flushed bool
+}
+
+// bufferedDelivery is a delivery held back by a swapBuffer.
+type bufferedDelivery struct {
+	ctx   context.Context
+	event Event
+}
+
+// add queues a delivery and reports whether it did; once the buffer is
+// flushed, deliveries go to the listener directly.
+func (b *swapBuffer) add(ctx context.Context, event Event) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.flushed {
+		return false
+	}
+	b.pending = append(b.pending, bufferedDelivery{ctx: ctx, event: event})
+	return true
+}
+
+// take returns the queued deliveries, marking the buffer flushed if there
+// are none.
+func (b *swapBuffer) take() []bufferedDelivery {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	pending := b.pending
+	b.pending = nil
+	if len(pending) == 0 {
+		b.flushed = true
+	}
+	return pending
+}
+
+// Swap replaces the listener of the subscription without dropping or
+// reordering events, for reloading handlers in a running application.
+// Deliveries arriving during the swap are buffered while the calls of the
+// old listener finish; the buffered events are then handed to the new
+// listener in order, on the goroutine calling Swap, before it takes over.
+// The subscription keeps its position, name, and options, including the
+// state of filters such as Distinct.
+//
+// Panics of the new listener while the buffer is flushed are recovered
+// and reported like those of deliveries, to Errors and WithPanicEvents,
+// and the remaining buffered events are still delivered. PublishAndWait
+// and PublishDetailed count a buffered event as delivered when it is
+// buffered, not when the new listener handles it.
+//
+// Swap blocks until the old listener has returned from every call, so it
+// must not be called from within that listener. Concurrent swaps of one
+// subscription are serialized.
+//
+// Example:
+//
+//	sub := bus.SubscribeContext("order:placed", pricing.Handle)
+//
+//	// On reload
+//	sub.Swap(reloaded.Handle)
+func (s *Subscription) Swap(listener ContextListener) {
+	s.swapping.Lock()
+	defer s.swapping.Unlock()
+
+	buffer := &swapBuffer{}
+	old := s.handler.Swap(&handlerVersion{listener: listener, buffer: buffer})
+	// The new listener takes over even if flushing fails.
+	defer s.handler.Store(&handlerVersion{listener: listener})
+	<-old.retire()
+
+	for pending := buffer.take(); len(pending) > 0; pending = buffer.take() {
+		for _, delivery := range pending {
+			s.flush(listener, delivery)
+		}
+	}
+}
+
+// flush hands a buffered delivery to listener, recovering and reporting
+// a panic like invoke. The delivery already went through the filters and
+// limits of the subscription, so it is not delivered through call.
+func (s *Subscription) flush(listener ContextListener, delivery bufferedDelivery) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := newPanicError(r)
+			s.bus.reportPanic(delivery.ctx, s, delivery.event, panicErr)
+			s.bus.reportError(delivery.ctx, delivery.event, s.name, panicErr)
+		}
+	}()
+	listener(delivery.ctx, delivery.event)
+}
+
+// handle calls the current listener, or buffers the event while a swap
+// waits for the previous one.
+func (s *Subscription) handle(ctx context.Context, event Event) {
+	version := s.acquire()
+	defer version.release()
+
+	if version.buffer != nil && version.buffer.add(ctx, event) {
+		return
+	}
+	version.listener(ctx, event)
+}
+
+// acquire returns the current version, counting the call so that Swap
+// can wait for it.
+func (s *Subscription) acquire() *handlerVersion {
+	for {
+		version := s.handler.Load()
+		version.calls.Add(1)
+		if s.handler.Load() == version {
+			return version
+		}
+		// Swapped in between; the call belongs to the new version.
+		version.release()
+	}
+}
+
+// release ends a call counted by acquire.
+func (v *handlerVersion) release() {
+	if v.calls.Add(-1) == 0 && v.retired.Load() {
+		v.once.Do(func() { close(v.drained) })
+	}
+}
+
+// retire marks the version replaced and returns a channel closed once its
+// calls have returned.
+func (v *handlerVersion) retire() <-chan struct{} {
+	v.drained = make(chan struct{})
+	v.retired.Store(true)
+	if v.calls.Load() == 0 {
+		v.once.Do(func() { close(v.drained) })
+	}
+	return v.drained
+}
@@ -0,0 +1,164 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSwap verifies that events after a swap go to the new listener
+func TestSwap(t *testing.T) {
+	bus := New()
+	var old, current []int
+
+	sub := bus.Subscribe("player:health", func(event Event) {
+		old = append(old, event.(healthEvent).hp)
+	})
+	bus.Publish(healthEvent{playerID: "p1", hp: 1})
+	sub.Swap(func(ctx context.Context, event Event) {
+		current = append(current, event.(healthEvent).hp)
+	})
+	bus.Publish(healthEvent{playerID: "p1", hp: 2})
+
+	if !slices.Equal(old, []int{1}) {
+		t.Errorf("Expected [1] for the old listener, got %v", old)
+	}
+	if !slices.Equal(current, []int{2}) {
+		t.Errorf("Expected [2] for the new listener, got %v", current)
+	}
+}
+
+// TestSwapBuffersWhileDraining verifies that events arriving while the old
+// listener finishes reach the new listener in order
+func TestSwapBuffersWhileDraining(t *testing.T) {
+	bus := New(WithAsync(1, 64))
+	var mutex sync.Mutex
+	var seen []int
+	record := func(hp int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		seen = append(seen, hp)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	sub := bus.Subscribe("player:health", func(event Event) {
+		if event.(healthEvent).hp == 1 {
+			close(started)
+			<-release
+		}
+		record(event.(healthEvent).hp)
+	})
+	bus.Publish(healthEvent{playerID: "p1", hp: 1})
+	<-started
+
+	swapped := make(chan struct{})
+	go func() {
+		sub.Swap(func(ctx context.Context, event Event) {
+			record(-event.(healthEvent).hp)
+		})
+		close(swapped)
+	}()
+	for sub.handler.Load().buffer == nil {
+		time.Sleep(time.Millisecond)
+	}
+	for hp := 2; hp <= 4; hp++ {
+		bus.Publish(healthEvent{playerID: "p1", hp: hp})
+	}
+
+	select {
+	case <-swapped:
+		t.Fatal("Expected Swap to wait for the old listener")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-swapped
+	bus.Publish(healthEvent{playerID: "p1", hp: 5})
+	bus.Close()
+
+	if want := []int{1, -2, -3, -4, -5}; !slices.Equal(seen, want) {
+		t.Errorf("Expected %v, got %v", want, seen)
+	}
+}
+
+// TestSwapKeepsOptions verifies that filter state and snapshots carry over
+// to the new listener
+func TestSwapKeepsOptions(t *testing.T) {
+	bus := New()
+	count := 0
+
+	sub := bus.Subscribe("player:health", func(event Event) {}, WithDistinct(nil, nil), WithName("hud"))
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	sub.Swap(func(ctx context.Context, event Event) {
+		count++
+	})
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	bus.Publish(healthEvent{playerID: "p1", hp: 5})
+
+	if count != 1 {
+		t.Errorf("Expected 1 delivery, got %d", count)
+	}
+	if sub.Name() != "hud" {
+		t.Errorf("Expected name hud, got %q", sub.Name())
+	}
+
+	clone := New()
+	bus.Snapshot().CloneInto(clone)
+	clone.Publish(healthEvent{playerID: "p1", hp: 1})
+	if count != 2 {
+		t.Errorf("Expected the clone to use the new listener, got %d deliveries", count)
+	}
+}
+
+// TestSwapFlushPanic verifies that a panic while flushing the buffer is reported and the new listener still takes over
+func TestSwapFlushPanic(t *testing.T) {
+	bus := New(WithAsync(1, 64))
+	errs := bus.Errors()
+	var mutex sync.Mutex
+	var seen []int
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	sub := bus.Subscribe("player:health", func(event Event) {
+		close(started)
+		<-release
+	})
+	bus.Publish(healthEvent{playerID: "p1", hp: 1})
+	<-started
+
+	swapped := make(chan struct{})
+	go func() {
+		sub.Swap(func(ctx context.Context, event Event) {
+			hp := event.(healthEvent).hp
+			if hp == 2 {
+				panic("reload failed")
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			seen = append(seen, hp)
+		})
+		close(swapped)
+	}()
+	for sub.handler.Load().buffer == nil {
+		time.Sleep(time.Millisecond)
+	}
+	bus.Publish(healthEvent{playerID: "p1", hp: 2})
+	bus.Publish(healthEvent{playerID: "p1", hp: 3})
+	close(release)
+	<-swapped
+
+	for hp := 4; hp <= 8; hp++ {
+		bus.Publish(healthEvent{playerID: "p1", hp: hp})
+	}
+	bus.Close()
+
+	if want := []int{3, 4, 5, 6, 7, 8}; !slices.Equal(seen, want) {
+		t.Errorf("Expected %v, got %v", want, seen)
+	}
+	if failure, ok := <-errs; !ok || !errors.Is(failure, ErrHandlerPanic) {
+		t.Errorf("Expected the flush panic reported, got %v", failure)
+	}
+}
@@ -0,0 +1,255 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TapePlayer replays a recorded session from an EventStore into a bus with
+// debugger-like controls: playback speed, pause, single steps, and
+// breakpoints on event types or predicates. It lets developers replay a
+// session and stop right before the event that triggers a bug, inspect the
+// state of their systems, and step on from there.
+//
+// All methods are safe for concurrent use, so the controls can be driven
+// from a debug UI while Run plays on another goroutine.
+//
+// Example:
+//
+//	player := eventbus.NewTapePlayer(recording, replayBus)
+//	player.SetSpeed(4)
+//	player.BreakOn("player:died")
+//	player.OnBreak(func(envelope eventbus.Envelope) {
+//	    log.Printf("paused before %s #%d", envelope.Event.GetType(), envelope.Sequence)
+//	    dumpWorld()
+//	    player.Step() // publish the breaking event and pause again
+//	})
+//	err := player.Run(ctx)
+type TapePlayer struct {
+	store       EventStore
+	bus         EventBus
+	speed       float64
+	breakpoints []func(Envelope) bool
+	onBreak     func(Envelope)
+	paused      bool
+	// steps counts the events that may be published while paused.
+	steps int
+	// position is the sequence of the last published envelope, and
+	// brokeAt that of the last envelope a breakpoint paused before.
+	position uint64
+	brokeAt  uint64
+	wake     chan struct{}
+	mutex    sync.Mutex
+}
+
+// NewTapePlayer creates a player publishing the envelopes of store into
+// bus. It plays as fast as possible until SetSpeed is called. The target
+// bus should not persist to store, otherwise the replayed events would be
+// appended to the recording.
+func NewTapePlayer(store EventStore, bus EventBus) *TapePlayer {
+	return &TapePlayer{
+		store: store,
+		bus:   bus,
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// SetSpeed sets the playback speed relative to the recorded timing: 1
+// replays in real time, 2 twice as fast, 0.5 at half speed. Zero or less
+// plays as fast as possible.
+func (p *TapePlayer) SetSpeed(speed float64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.speed = speed
+}
+
+// BreakOn pauses playback before publishing an event of eventType.
+func (p *TapePlayer) BreakOn(eventType EventType) {
+	p.BreakWhen(func(envelope Envelope) bool {
+		return envelope.Event.GetType() == eventType
+	})
+}
+
+// BreakWhen pauses playback before publishing an envelope matching
+// predicate.
+func (p *TapePlayer) BreakWhen(predicate func(Envelope) bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.breakpoints = append(p.breakpoints, predicate)
+}
+
+// ClearBreakpoints removes every breakpoint.
+func (p *TapePlayer) ClearBreakpoints() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.breakpoints = nil
+}
+
+// OnBreak calls fn with the pending envelope whenever a breakpoint pauses
+// playback. fn runs on the goroutine of Run and may call the controls.
+func (p *TapePlayer) OnBreak(fn func(Envelope)) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.onBreak = fn
+}
+
+// Pause stops playback before the next event.
+func (p *TapePlayer) Pause() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.paused = true
+	p.steps = 0
+}
+
+// Resume continues playback after a pause or breakpoint.
+func (p *TapePlayer) Resume() {
+	p.mutex.Lock()
+	p.paused = false
+	p.steps = 0
+	p.mutex.Unlock()
+	p.notify()
+}
+
+// Step publishes the next event while paused, then stays paused. It has
+// no effect while playing.
+func (p *TapePlayer) Step() {
+	p.mutex.Lock()
+	if p.paused {
+		p.steps++
+	}
+	p.mutex.Unlock()
+	p.notify()
+}
+
+// Paused reports whether playback is paused.
+func (p *TapePlayer) Paused() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.paused
+}
+
+// Position returns the sequence of the last published envelope, or 0 if
+// none has been published yet.
+func (p *TapePlayer) Position() uint64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.position
+}
+
+// Run plays the recording from the current position to its end, blocking
+// while paused. It returns the context error if ctx is done first; a later
+// Run continues where it stopped.
+func (p *TapePlayer) Run(ctx context.Context) error {
+	var previous *Envelope
+	return p.store.Read(p.Position()+1, func(envelope Envelope) error {
+		if previous != nil {
+			if err := p.sleep(ctx, recordedGap(*previous, envelope)); err != nil {
+				return err
+			}
+		}
+		previous = &envelope
+
+		p.checkBreakpoints(envelope)
+		if err := p.await(ctx); err != nil {
+			return err
+		}
+
+		p.bus.Publish(envelope.Event)
+		p.mutex.Lock()
+		p.position = envelope.Sequence
+		p.mutex.Unlock()
+		return nil
+	})
+}
+
+// checkBreakpoints pauses before envelope if a breakpoint matches it and
+// reports the break. An envelope breaks only once, so resuming or
+// stepping publishes it.
+func (p *TapePlayer) checkBreakpoints(envelope Envelope) {
+	p.mutex.Lock()
+	if p.brokeAt == envelope.Sequence {
+		p.mutex.Unlock()
+		return
+	}
+	hit := false
+	for _, breakpoint := range p.breakpoints {
+		if breakpoint(envelope) {
+			hit = true
+			break
+		}
+	}
+	if !hit {
+		p.mutex.Unlock()
+		return
+	}
+	p.paused = true
+	p.steps = 0
+	p.brokeAt = envelope.Sequence
+	onBreak := p.onBreak
+	p.mutex.Unlock()
+
+	if onBreak != nil {
+		onBreak(envelope)
+	}
+}
+
+// await blocks while playback is paused, consuming a step if one was
+// requested.
+func (p *TapePlayer) await(ctx context.Context) error {
+	for {
+		p.mutex.Lock()
+		switch {
+		case !p.paused:
+			p.mutex.Unlock()
+			return nil
+		case p.steps > 0:
+			p.steps--
+			p.mutex.Unlock()
+			return nil
+		}
+		p.mutex.Unlock()
+
+		select {
+		case <-p.wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// sleep waits for the recorded gap scaled by the speed.
+func (p *TapePlayer) sleep(ctx context.Context, gap time.Duration) error {
+	p.mutex.Lock()
+	speed := p.speed
+	p.mutex.Unlock()
+	if speed <= 0 || gap <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(float64(gap) / speed))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// notify wakes a paused Run to re-check the controls.
+func (p *TapePlayer) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// recordedGap returns the time between two recorded envelopes, preferring
+// the monotonic timestamps when both have them.
+func recordedGap(previous, next Envelope) time.Duration {
+	if previous.Monotonic > 0 && next.Monotonic > 0 {
+		return next.Monotonic - previous.Monotonic
+	}
+	return next.Time.Sub(previous.Time)
+}
@@ -0,0 +1,155 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordSession publishes events of the given types into a new store.
+func recordSession(t *testing.T, eventTypes ...EventType) *MemoryStore {
+	t.Helper()
+	store := NewMemoryStore()
+	bus := New(WithStore(store))
+	for _, eventType := range eventTypes {
+		bus.Publish(testEvent{eventType: eventType})
+	}
+	bus.Close()
+	return store
+}
+
+// tapeRecorder collects the event types published on a bus.
+type tapeRecorder struct {
+	types []EventType
+	mutex sync.Mutex
+}
+
+func (r *tapeRecorder) listen(bus EventBus, eventTypes ...EventType) {
+	for _, eventType := range eventTypes {
+		bus.Subscribe(eventType, func(event Event) {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			r.types = append(r.types, event.GetType())
+		})
+	}
+}
+
+func (r *tapeRecorder) count() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.types)
+}
+
+// TestTapePlayerRun verifies that the recording is replayed in order
+func TestTapePlayerRun(t *testing.T) {
+	store := recordSession(t, "a", "b", "c")
+	bus := New()
+	var recorder tapeRecorder
+	recorder.listen(bus, "a", "b", "c")
+
+	player := NewTapePlayer(store, bus)
+	if err := player.Run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if recorder.count() != 3 || recorder.types[0] != "a" || recorder.types[2] != "c" {
+		t.Errorf("Expected [a b c], got %v", recorder.types)
+	}
+	if player.Position() != 3 {
+		t.Errorf("Expected position 3, got %d", player.Position())
+	}
+}
+
+// TestTapePlayerBreakpoint verifies that playback pauses before a matching event and steps on
+func TestTapePlayerBreakpoint(t *testing.T) {
+	store := recordSession(t, "player:moved", "player:moved", "player:died", "game:over")
+	bus := New()
+	var recorder tapeRecorder
+	recorder.listen(bus, "player:moved", "player:died", "game:over")
+
+	player := NewTapePlayer(store, bus)
+	player.BreakOn("player:died")
+	breaks := make(chan Envelope, 1)
+	player.OnBreak(func(envelope Envelope) { breaks <- envelope })
+
+	done := make(chan error, 1)
+	go func() { done <- player.Run(context.Background()) }()
+
+	select {
+	case envelope := <-breaks:
+		if envelope.Sequence != 3 {
+			t.Errorf("Expected to break before sequence 3, got %d", envelope.Sequence)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a break")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if recorder.count() != 2 || !player.Paused() {
+		t.Fatalf("Expected to pause after 2 events, got %d (paused %v)", recorder.count(), player.Paused())
+	}
+
+	player.Step()
+	deadline := time.Now().Add(time.Second)
+	for recorder.count() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if recorder.count() != 3 || recorder.types[2] != "player:died" {
+		t.Fatalf("Expected a single step to publish player:died, got %v", recorder.types)
+	}
+
+	player.Resume()
+	if err := <-done; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if recorder.count() != 4 {
+		t.Errorf("Expected all 4 events, got %v", recorder.types)
+	}
+}
+
+// TestTapePlayerPauseContext verifies that a paused Run ends with its context and can continue
+func TestTapePlayerPauseContext(t *testing.T) {
+	store := recordSession(t, "a", "b")
+	bus := New()
+	var recorder tapeRecorder
+	recorder.listen(bus, "a", "b")
+
+	player := NewTapePlayer(store, bus)
+	player.Pause()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := player.Run(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if recorder.count() != 0 {
+		t.Errorf("Expected nothing published while paused, got %v", recorder.types)
+	}
+
+	player.Resume()
+	if err := player.Run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if recorder.count() != 2 {
+		t.Errorf("Expected both events after resuming, got %v", recorder.types)
+	}
+}
+
+// TestTapePlayerSpeed verifies that recorded gaps are scaled by the speed
+func TestTapePlayerSpeed(t *testing.T) {
+	store := NewMemoryStore()
+	recordBus := New(WithStore(store))
+	recordBus.Publish(testEvent{eventType: "a"})
+	time.Sleep(40 * time.Millisecond)
+	recordBus.Publish(testEvent{eventType: "b"})
+	recordBus.Close()
+
+	player := NewTapePlayer(store, New())
+	player.SetSpeed(2)
+	begin := time.Now()
+	if err := player.Run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed < 15*time.Millisecond || elapsed > 35*time.Millisecond {
+		t.Errorf("Expected about 20ms at double speed, got %v", elapsed)
+	}
+}
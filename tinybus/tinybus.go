@@ -0,0 +1,104 @@
+// Package tinybus is a minimal event bus for TinyGo and other constrained
+// targets such as microcontrollers. It offers the Subscribe and Publish API
+// of package eventbus without its optional features, and avoids the parts
+// of Go that are costly or unavailable there:
+//
+//   - no reflection, encoding, or network packages are imported
+//   - listener storage is allocated once by New and never grows
+//   - Publish does not allocate
+//
+// The package compiles with the standard Go toolchain as well, so firmware
+// logic can be tested on the host. Event, EventType, and EventListener are
+// the types of package eventbus, so events and listeners move between the
+// two buses unchanged. Bus does not implement eventbus.EventBus, whose
+// optional features it leaves out; components meant to run on both should
+// depend on a narrow interface such as
+//
+//	type Publisher interface {
+//	    Publish(event eventbus.Event)
+//	}
+//
+// which both buses satisfy.
+//
+// Example:
+//
+//	bus := tinybus.New(8)
+//	bus.Subscribe("button:pressed", func(event tinybus.Event) {
+//	    led.High()
+//	})
+//	bus.Publish(ButtonPressed{Pin: 2})
+package tinybus
+
+import (
+	"sync"
+
+	"github.com/Papiermond/eventbus/internal/core"
+)
+
+// EventType identifies the kind of an event. It is eventbus.EventType.
+type EventType = core.EventType
+
+// Event is the interface that all events must implement. It is
+// eventbus.Event.
+type Event = core.Event
+
+// EventListener is a function that handles an event. It is
+// eventbus.EventListener.
+type EventListener = core.EventListener
+
+// subscription is a listener registered for one event type.
+type subscription struct {
+	eventType EventType
+	listener  EventListener
+}
+
+// Bus is a fixed-capacity event bus. Listeners are called synchronously in
+// registration order. Unlike eventbus, listeners may publish nested events,
+// since Publish does not hold the lock while delivering.
+type Bus struct {
+	subscriptions []subscription
+	mutex         sync.Mutex
+}
+
+// New creates a bus with room for maxListeners subscriptions in total.
+func New(maxListeners int) *Bus {
+	return &Bus{subscriptions: make([]subscription, 0, maxListeners)}
+}
+
+// Subscribe registers a listener for eventType. It reports false if the bus
+// already holds its maximum number of listeners.
+func (bus *Bus) Subscribe(eventType EventType, listener EventListener) bool {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	if len(bus.subscriptions) == cap(bus.subscriptions) {
+		return false
+	}
+	bus.subscriptions = append(bus.subscriptions, subscription{eventType: eventType, listener: listener})
+	return true
+}
+
+// Publish calls every listener registered for the event's type. Listeners
+// subscribed while the event is being delivered do not receive it.
+func (bus *Bus) Publish(event Event) {
+	// Subscriptions are only ever appended within the preallocated array,
+	// so the prefix seen here stays valid without holding the lock.
+	bus.mutex.Lock()
+	subscriptions := bus.subscriptions
+	bus.mutex.Unlock()
+
+	eventType := event.GetType()
+	for i := range subscriptions {
+		if subscriptions[i].eventType == eventType {
+			subscriptions[i].listener(event)
+		}
+	}
+}
+
+// Len returns the number of registered listeners.
+func (bus *Bus) Len() int {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	return len(bus.subscriptions)
+}
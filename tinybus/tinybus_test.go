@@ -0,0 +1,127 @@
+package tinybus
+
+import (
+	"testing"
+
+	"github.com/Papiermond/eventbus"
+)
+
+type buttonEvent struct {
+	pin int
+}
+
+func (e buttonEvent) GetType() EventType {
+	return "button:pressed"
+}
+
+// TestSubscribeAndPublish verifies routing by event type in registration order
+func TestSubscribeAndPublish(t *testing.T) {
+	bus := New(4)
+	var order []int
+
+	bus.Subscribe("button:pressed", func(event Event) { order = append(order, 1) })
+	bus.Subscribe("button:released", func(event Event) { order = append(order, 2) })
+	bus.Subscribe("button:pressed", func(event Event) { order = append(order, 3) })
+
+	bus.Publish(buttonEvent{pin: 2})
+
+	if len(order) != 2 || order[0] != 1 || order[1] != 3 {
+		t.Errorf("Expected listeners [1 3], got %v", order)
+	}
+}
+
+// TestSubscribeCapacity verifies that subscriptions beyond capacity are rejected
+func TestSubscribeCapacity(t *testing.T) {
+	bus := New(1)
+
+	if !bus.Subscribe("button:pressed", func(event Event) {}) {
+		t.Fatal("Expected the first subscription to succeed")
+	}
+	if bus.Subscribe("button:pressed", func(event Event) {}) {
+		t.Error("Expected a subscription beyond capacity to fail")
+	}
+	if bus.Len() != 1 {
+		t.Errorf("Expected 1 listener, got %d", bus.Len())
+	}
+}
+
+// TestNestedPublish verifies that listeners can publish further events
+func TestNestedPublish(t *testing.T) {
+	bus := New(2)
+	released := false
+
+	bus.Subscribe("button:pressed", func(event Event) {
+		bus.Publish(releaseEvent{})
+	})
+	bus.Subscribe("button:released", func(event Event) {
+		released = true
+	})
+
+	bus.Publish(buttonEvent{pin: 2})
+
+	if !released {
+		t.Error("Nested event was not delivered")
+	}
+}
+
+type releaseEvent struct{}
+
+func (e releaseEvent) GetType() EventType {
+	return "button:released"
+}
+
+// TestSharedTypes verifies that events and listeners written for eventbus
+// work on both buses
+func TestSharedTypes(t *testing.T) {
+	type publisher interface {
+		Publish(event eventbus.Event)
+	}
+	received := 0
+	var listener eventbus.EventListener = func(event eventbus.Event) {
+		if event.(buttonEvent).pin == 2 {
+			received++
+		}
+	}
+
+	tiny := New(1)
+	tiny.Subscribe("button:pressed", listener)
+	full := eventbus.New()
+	defer full.Close()
+	full.Subscribe("button:pressed", listener)
+
+	for _, bus := range []publisher{tiny, full} {
+		bus.Publish(buttonEvent{pin: 2})
+	}
+	if received != 2 {
+		t.Errorf("Expected 2 deliveries, got %d", received)
+	}
+}
+
+// TestPublishDoesNotAllocate verifies that publishing is allocation free
+func TestPublishDoesNotAllocate(t *testing.T) {
+	bus := New(2)
+	count := 0
+	bus.Subscribe("button:pressed", func(event Event) { count++ })
+
+	var event Event = buttonEvent{pin: 2}
+	allocs := testing.AllocsPerRun(100, func() {
+		bus.Publish(event)
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected 0 allocations per publish, got %v", allocs)
+	}
+}
+
+// BenchmarkPublish benchmarks event publishing performance
+func BenchmarkPublish(b *testing.B) {
+	bus := New(1)
+	bus.Subscribe("button:pressed", func(event Event) {})
+
+	var event Event = buttonEvent{pin: 2}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bus.Publish(event)
+	}
+}
@@ -0,0 +1,145 @@
+package topicgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// ParseGo reads a manifest written as Go source and checks it with
+// Validate. The source declares a variable initialized with a Manifest
+// composite literal, whose fields are string literals and nested
+// literals, so the manifest is type checked by the editor and read
+// without compiling it:
+//
+//	//go:build ignore
+//
+//	package topics
+//
+//	import "github.com/Papiermond/eventbus/topicgen"
+//
+//	var manifest = topicgen.Manifest{
+//	    Package: "topics",
+//	    Namespaces: []topicgen.Namespace{{
+//	        Name: "player",
+//	        Topics: []topicgen.Topic{
+//	            {Name: "jumped", Producers: []string{"physics"}, Consumers: []string{"audio"}},
+//	        },
+//	    }},
+//	}
+//
+// The first such variable is read. Other expressions, such as constants
+// or function calls, are rejected.
+func ParseGo(r io.Reader) (Manifest, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return Manifest{}, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "manifest.go", src, parser.SkipObjectResolution)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+
+	literal := manifestLiteral(file)
+	if literal == nil {
+		return Manifest{}, fmt.Errorf("%w: no variable initialized with a Manifest literal", ErrInvalidManifest)
+	}
+	var manifest Manifest
+	if err := decodeLiteral(fset, literal, reflect.ValueOf(&manifest).Elem()); err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	return manifest, manifest.Validate()
+}
+
+// manifestLiteral returns the first Manifest or topicgen.Manifest
+// composite literal initializing a package variable of file.
+func manifestLiteral(file *ast.File) *ast.CompositeLit {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, value := range spec.(*ast.ValueSpec).Values {
+				literal, ok := value.(*ast.CompositeLit)
+				if ok && isManifestType(literal.Type) {
+					return literal
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isManifestType reports whether expr names the Manifest type.
+func isManifestType(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name == "Manifest"
+	case *ast.SelectorExpr:
+		return expr.Sel.Name == "Manifest"
+	}
+	return false
+}
+
+// decodeLiteral stores the value of expr in v, which is a string, a
+// slice, or a struct of the manifest types.
+func decodeLiteral(fset *token.FileSet, expr ast.Expr, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.String:
+		literal, ok := expr.(*ast.BasicLit)
+		if !ok || literal.Kind != token.STRING {
+			return fmt.Errorf("%s: expected a string literal", fset.Position(expr.Pos()))
+		}
+		value, err := strconv.Unquote(literal.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fset.Position(expr.Pos()), err)
+		}
+		v.SetString(value)
+		return nil
+
+	case reflect.Slice:
+		literal, ok := expr.(*ast.CompositeLit)
+		if !ok {
+			return fmt.Errorf("%s: expected a %s literal", fset.Position(expr.Pos()), v.Type())
+		}
+		slice := reflect.MakeSlice(v.Type(), len(literal.Elts), len(literal.Elts))
+		for i, element := range literal.Elts {
+			if err := decodeLiteral(fset, element, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+
+	case reflect.Struct:
+		literal, ok := expr.(*ast.CompositeLit)
+		if !ok {
+			return fmt.Errorf("%s: expected a %s literal", fset.Position(expr.Pos()), v.Type().Name())
+		}
+		for _, element := range literal.Elts {
+			pair, ok := element.(*ast.KeyValueExpr)
+			if !ok {
+				return fmt.Errorf("%s: expected keyed fields in %s", fset.Position(element.Pos()), v.Type().Name())
+			}
+			key, ok := pair.Key.(*ast.Ident)
+			var field reflect.Value
+			if ok {
+				field = v.FieldByName(key.Name)
+			}
+			if !field.IsValid() {
+				return fmt.Errorf("%s: unknown field in %s", fset.Position(pair.Key.Pos()), v.Type().Name())
+			}
+			if err := decodeLiteral(fset, pair.Value, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: unsupported %s value", fset.Position(expr.Pos()), v.Type())
+}
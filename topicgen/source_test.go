@@ -0,0 +1,73 @@
+package topicgen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testSourceManifest = `//go:build ignore
+
+package gametopics
+
+import "github.com/Papiermond/eventbus/topicgen"
+
+var manifest = topicgen.Manifest{
+	Package: "gametopics",
+	Namespaces: []topicgen.Namespace{{
+		Name: "player",
+		Doc:  "Player topics are published by the simulation.",
+		Topics: []topicgen.Topic{
+			{Name: "jumped", Producers: []string{"physics"}, Consumers: []string{"audio", "animation"}},
+			{Name: ` + "`health_changed`" + `},
+		},
+	}, {
+		Name:   "level",
+		Topics: []topicgen.Topic{{Name: "loaded", Const: "LevelReady"}},
+	}},
+}
+`
+
+// TestParseGo verifies that a Manifest literal in Go source is read like the JSON manifest
+func TestParseGo(t *testing.T) {
+	manifest, err := ParseGo(strings.NewReader(testSourceManifest))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if manifest.Package != "gametopics" || len(manifest.Namespaces) != 2 {
+		t.Fatalf("Expected 2 namespaces in gametopics, got %+v", manifest)
+	}
+	player := manifest.Namespaces[0]
+	if len(player.Topics) != 2 || player.Topics[1].Name != "health_changed" {
+		t.Errorf("Expected the player topics, got %+v", player.Topics)
+	}
+	if consumers := player.Topics[0].Consumers; len(consumers) != 2 || consumers[1] != "animation" {
+		t.Errorf("Expected audio and animation, got %v", consumers)
+	}
+	if manifest.Namespaces[1].Topics[0].Const != "LevelReady" {
+		t.Errorf("Expected the LevelReady constant, got %+v", manifest.Namespaces[1].Topics[0])
+	}
+}
+
+// TestParseGoInvalid verifies that sources without a readable Manifest literal are rejected
+func TestParseGoInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"syntax error", "package topics\nvar manifest = topicgen.Manifest{"},
+		{"no manifest", "package topics\nvar topics = []string{\"player:jumped\"}"},
+		{"unknown field", "package topics\nvar manifest = topicgen.Manifest{Namespace: nil}"},
+		{"constant", "package topics\nconst pkg = \"topics\"\nvar manifest = topicgen.Manifest{Package: pkg}"},
+		{"unkeyed", "package topics\nvar manifest = topicgen.Manifest{\"topics\"}"},
+		{"duplicate topic", `package topics
+var manifest = topicgen.Manifest{Namespaces: []topicgen.Namespace{{Name: "player", Topics: []topicgen.Topic{{Name: "jumped"}, {Name: "jumped"}}}}}`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseGo(strings.NewReader(test.source))
+		if !errors.Is(err, ErrInvalidManifest) {
+			t.Errorf("%s: Expected ErrInvalidManifest, got %v", test.name, err)
+		}
+	}
+}
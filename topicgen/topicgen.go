@@ -0,0 +1,274 @@
+// Package topicgen generates EventType constants and producer/consumer
+// documentation from a topics manifest, so topic strings are written once
+// and typos such as "player:jump" for "player:jumped" fail to compile.
+//
+// A manifest is a JSON document listing the topics by namespace, or the
+// same structure as YAML read with ParseWith, or as a Manifest literal in
+// Go source read with ParseGo:
+//
+//	{
+//	    "package": "topics",
+//	    "namespaces": [{
+//	        "name": "player",
+//	        "doc": "Player topics are published by the game simulation.",
+//	        "topics": [{
+//	            "name": "jumped",
+//	            "doc": "A player left the ground.",
+//	            "producers": ["physics"],
+//	            "consumers": ["audio", "animation"]
+//	        }]
+//	    }]
+//	}
+//
+// Generate turns it into a Go file declaring
+//
+//	PlayerJumped eventbus.EventType = "player:jumped"
+//
+// and an All slice with every topic, and Markdown renders a table of
+// producers and consumers. The eventbus-topics command wraps both for use
+// with go:generate.
+package topicgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidManifest is returned when a manifest cannot be generated,
+// for example because two topics share a name.
+var ErrInvalidManifest = errors.New("topicgen: invalid manifest")
+
+// Manifest lists the topics of an application.
+type Manifest struct {
+	// Package is the name of the generated package. Defaults to "topics".
+	Package string `json:"package,omitempty" yaml:"package,omitempty"`
+	// Separator joins namespace and topic names. Defaults to ":".
+	Separator  string      `json:"separator,omitempty" yaml:"separator,omitempty"`
+	Namespaces []Namespace `json:"namespaces" yaml:"namespaces"`
+}
+
+// Namespace groups the topics sharing a prefix, such as "player".
+type Namespace struct {
+	Name   string  `json:"name" yaml:"name"`
+	Doc    string  `json:"doc,omitempty" yaml:"doc,omitempty"`
+	Topics []Topic `json:"topics" yaml:"topics"`
+}
+
+// Topic describes a single event type and who publishes and handles it.
+type Topic struct {
+	Name      string   `json:"name" yaml:"name"`
+	Doc       string   `json:"doc,omitempty" yaml:"doc,omitempty"`
+	Producers []string `json:"producers,omitempty" yaml:"producers,omitempty"`
+	Consumers []string `json:"consumers,omitempty" yaml:"consumers,omitempty"`
+	// Const overrides the generated constant name.
+	Const string `json:"const,omitempty" yaml:"const,omitempty"`
+}
+
+// Parse reads a JSON manifest and checks it with Validate. Unknown fields
+// are rejected.
+func Parse(r io.Reader) (Manifest, error) {
+	var manifest Manifest
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&manifest); err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	return manifest, manifest.Validate()
+}
+
+// ParseWith reads a manifest decoded with decode and checks it with
+// Validate; pass yaml.Unmarshal to read YAML, which honors the yaml tags
+// of Manifest.
+//
+// Example:
+//
+//	manifest, err := topicgen.ParseWith(file, yaml.Unmarshal)
+func ParseWith(r io.Reader, decode func(data []byte, v any) error) (Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Manifest{}, err
+	}
+	var manifest Manifest
+	if err := decode(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	return manifest, manifest.Validate()
+}
+
+// Validate checks that every namespace and topic is named, that no event
+// type is listed twice, and that the constant names are distinct Go
+// identifiers.
+func (m Manifest) Validate() error {
+	if !token.IsIdentifier(m.pkg()) {
+		return fmt.Errorf("%w: package %q is not a Go identifier", ErrInvalidManifest, m.pkg())
+	}
+
+	types := map[string]bool{}
+	consts := map[string]string{}
+	for _, namespace := range m.Namespaces {
+		if namespace.Name == "" {
+			return fmt.Errorf("%w: namespace without a name", ErrInvalidManifest)
+		}
+		for _, topic := range namespace.Topics {
+			if topic.Name == "" {
+				return fmt.Errorf("%w: topic without a name in namespace %q", ErrInvalidManifest, namespace.Name)
+			}
+
+			eventType := m.eventType(namespace, topic)
+			if types[eventType] {
+				return fmt.Errorf("%w: topic %q is listed twice", ErrInvalidManifest, eventType)
+			}
+			types[eventType] = true
+
+			name := constName(namespace, topic)
+			if !token.IsIdentifier(name) || !token.IsExported(name) {
+				return fmt.Errorf("%w: constant %q for %q is not an exported Go identifier", ErrInvalidManifest, name, eventType)
+			}
+			if other, ok := consts[name]; ok {
+				return fmt.Errorf("%w: %q and %q both generate constant %s", ErrInvalidManifest, other, eventType, name)
+			}
+			consts[name] = eventType
+		}
+	}
+	return nil
+}
+
+// Generate returns the formatted Go source declaring a constant for every
+// topic and an All slice listing them in manifest order.
+func (m Manifest) Generate() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by eventbus-topics; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", m.pkg())
+	fmt.Fprintf(&buf, "import \"github.com/Papiermond/eventbus\"\n")
+
+	for _, namespace := range m.Namespaces {
+		buf.WriteString("\n")
+		writeComment(&buf, "", namespace.Doc)
+		buf.WriteString("const (\n")
+		for i, topic := range namespace.Topics {
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			name := constName(namespace, topic)
+			doc := topic.Doc
+			if doc == "" {
+				doc = fmt.Sprintf("%s is the %q topic.", name, m.eventType(namespace, topic))
+			}
+			writeComment(&buf, "\t", doc)
+			if len(topic.Producers) > 0 || len(topic.Consumers) > 0 {
+				buf.WriteString("\t//\n")
+			}
+			if len(topic.Producers) > 0 {
+				fmt.Fprintf(&buf, "\t// Producers: %s\n", strings.Join(topic.Producers, ", "))
+			}
+			if len(topic.Consumers) > 0 {
+				fmt.Fprintf(&buf, "\t// Consumers: %s\n", strings.Join(topic.Consumers, ", "))
+			}
+			fmt.Fprintf(&buf, "\t%s eventbus.EventType = %q\n", name, m.eventType(namespace, topic))
+		}
+		buf.WriteString(")\n")
+	}
+
+	buf.WriteString("\n// All lists every topic of the manifest.\n")
+	buf.WriteString("var All = []eventbus.EventType{\n")
+	for _, namespace := range m.Namespaces {
+		for _, topic := range namespace.Topics {
+			fmt.Fprintf(&buf, "\t%s,\n", constName(namespace, topic))
+		}
+	}
+	buf.WriteString("}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+// Markdown returns a document listing every topic with its producers and
+// consumers, one table per namespace.
+func (m Manifest) Markdown() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Topics\n\n")
+	buf.WriteString("<!-- Code generated by eventbus-topics; DO NOT EDIT. -->\n")
+	for _, namespace := range m.Namespaces {
+		fmt.Fprintf(&buf, "\n## %s\n\n", namespace.Name)
+		if namespace.Doc != "" {
+			fmt.Fprintf(&buf, "%s\n\n", namespace.Doc)
+		}
+		buf.WriteString("| Topic | Constant | Producers | Consumers | Description |\n")
+		buf.WriteString("|-------|----------|-----------|-----------|-------------|\n")
+		for _, topic := range namespace.Topics {
+			fmt.Fprintf(&buf, "| `%s` | `%s` | %s | %s | %s |\n",
+				m.eventType(namespace, topic),
+				constName(namespace, topic),
+				strings.Join(topic.Producers, ", "),
+				strings.Join(topic.Consumers, ", "),
+				strings.ReplaceAll(topic.Doc, "|", "\\|"))
+		}
+	}
+	return buf.Bytes()
+}
+
+// pkg returns the generated package name.
+func (m Manifest) pkg() string {
+	if m.Package == "" {
+		return "topics"
+	}
+	return m.Package
+}
+
+// eventType returns the topic string of topic in namespace.
+func (m Manifest) eventType(namespace Namespace, topic Topic) string {
+	separator := m.Separator
+	if separator == "" {
+		separator = ":"
+	}
+	return namespace.Name + separator + topic.Name
+}
+
+// constName returns the constant name of topic, joining the camel-cased
+// namespace and topic names unless the topic overrides it.
+func constName(namespace Namespace, topic Topic) string {
+	if topic.Const != "" {
+		return topic.Const
+	}
+	return camel(namespace.Name) + camel(topic.Name)
+}
+
+// camel converts a name such as "health_changed" or "level-up" to
+// "HealthChanged" and "LevelUp", starting a new word at every character
+// that is neither a letter nor a digit.
+func camel(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// writeComment writes text as a line comment with the given indentation.
+func writeComment(buf *bytes.Buffer, indent, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(buf, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
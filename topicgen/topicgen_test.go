@@ -0,0 +1,131 @@
+package topicgen
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const testManifest = `{
+	"package": "gametopics",
+	"namespaces": [{
+		"name": "player",
+		"doc": "Player topics are published by the simulation.",
+		"topics": [
+			{"name": "jumped", "doc": "PlayerJumped is published when a player leaves the ground.", "producers": ["physics"], "consumers": ["audio", "animation"]},
+			{"name": "health_changed"}
+		]
+	}, {
+		"name": "level",
+		"topics": [{"name": "loaded", "const": "LevelReady"}]
+	}]
+}`
+
+// TestGenerate verifies that the generated source declares every constant and parses as Go
+func TestGenerate(t *testing.T) {
+	manifest, err := Parse(strings.NewReader(testManifest))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	source, err := manifest.Generate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "topics_gen.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Expected valid Go source, got %v\n%s", err, source)
+	}
+	if file.Name.Name != "gametopics" {
+		t.Errorf("Expected package gametopics, got %s", file.Name.Name)
+	}
+
+	for _, want := range []string{
+		`PlayerJumped eventbus.EventType = "player:jumped"`,
+		`PlayerHealthChanged eventbus.EventType = "player:health_changed"`,
+		`LevelReady eventbus.EventType = "level:loaded"`,
+		"// Producers: physics",
+		"// Consumers: audio, animation",
+		"// Player topics are published by the simulation.",
+		"var All = []eventbus.EventType{",
+	} {
+		if !strings.Contains(string(source), want) {
+			t.Errorf("Expected source to contain %q, got\n%s", want, source)
+		}
+	}
+}
+
+// TestMarkdown verifies that the overview lists producers and consumers per topic
+func TestMarkdown(t *testing.T) {
+	manifest, err := Parse(strings.NewReader(testManifest))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	doc := string(manifest.Markdown())
+	want := "| `player:jumped` | `PlayerJumped` | physics | audio, animation |"
+	if !strings.Contains(doc, want) {
+		t.Errorf("Expected overview to contain %q, got\n%s", want, doc)
+	}
+	if !strings.Contains(doc, "## level") {
+		t.Errorf("Expected a section per namespace, got\n%s", doc)
+	}
+}
+
+// TestValidate verifies that ambiguous manifests are rejected
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{"duplicate topic", `{"namespaces": [{"name": "player", "topics": [{"name": "jumped"}, {"name": "jumped"}]}]}`},
+		{"duplicate constant", `{"namespaces": [{"name": "player", "topics": [{"name": "level-up"}, {"name": "level_up"}]}]}`},
+		{"unnamed topic", `{"namespaces": [{"name": "player", "topics": [{"doc": "?"}]}]}`},
+		{"invalid constant", `{"namespaces": [{"name": "player", "topics": [{"name": "jumped", "const": "jumped"}]}]}`},
+		{"invalid package", `{"package": "game-topics", "namespaces": []}`},
+		{"unknown field", `{"namespace": []}`},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.manifest))
+		if !errors.Is(err, ErrInvalidManifest) {
+			t.Errorf("%s: Expected ErrInvalidManifest, got %v", test.name, err)
+		}
+	}
+}
+
+// TestCamel verifies the conversion of topic names to identifiers
+func TestCamel(t *testing.T) {
+	tests := map[string]string{
+		"jumped":         "Jumped",
+		"health_changed": "HealthChanged",
+		"level-up":       "LevelUp",
+		"http.request":   "HttpRequest",
+		"item2":          "Item2",
+	}
+	for name, want := range tests {
+		if got := camel(name); got != want {
+			t.Errorf("Expected %s for %q, got %s", want, name, got)
+		}
+	}
+}
+
+// TestParseWith verifies that manifests are read with the given decoder and validated
+func TestParseWith(t *testing.T) {
+	decode := func(data []byte, v any) error {
+		if string(data) != "player" {
+			return errors.New("unexpected document")
+		}
+		v.(*Manifest).Namespaces = []Namespace{{Name: "player", Topics: []Topic{{Name: "jumped"}}}}
+		return nil
+	}
+	manifest, err := ParseWith(strings.NewReader("player"), decode)
+	if err != nil || len(manifest.Namespaces) != 1 {
+		t.Errorf("Expected the decoded manifest, got %+v and %v", manifest, err)
+	}
+	if _, err := ParseWith(strings.NewReader("other"), decode); !errors.Is(err, ErrInvalidManifest) {
+		t.Errorf("Expected ErrInvalidManifest, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package eventbus
+
+import "time"
+
+// OverflowPolicy decides what happens when an asynchronous topic's queue
+// is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the publisher wait until the queue has room.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the delivery that did not fit.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest queued delivery to make room.
+	OverflowDropOldest
+)
+
+// TopicConfig describes how events of a topic are dispatched.
+// The zero value delivers synchronously on the publishing goroutine.
+type TopicConfig struct {
+	// Async delivers events on a dedicated worker pool.
+	Async bool
+	// Workers is the number of worker goroutines for an async topic.
+	Workers int
+	// QueueSize is the number of deliveries that can wait for a worker.
+	QueueSize int
+	// Overflow decides what happens when the queue is full.
+	Overflow OverflowPolicy
+	// WorkStealing gives every worker its own queue and lets idle workers
+	// steal from busy ones, instead of sharing a single queue. It scales
+	// better with many workers and bursty topics.
+	WorkStealing bool
+	// Dispatcher runs the deliveries instead of the bus. It takes
+	// precedence over Async and the pool settings.
+	Dispatcher Dispatcher
+	// MaxWorkers enables autoscaling when above Workers: the pool adds
+	// workers, up to MaxWorkers, while its queue would take longer than
+	// ScaleInterval to drain at the recent handler time, and retires them
+	// one per interval, down to Workers, while idle. Every change is
+	// published as a WorkersScaled event. It is ignored with WorkStealing.
+	MaxWorkers int
+	// ScaleInterval is how often an autoscaling pool checks its load.
+	// DefaultScaleInterval if zero.
+	ScaleInterval time.Duration
+	// Order decides the order in which listeners receive each event.
+	// Listeners with a delivery stage keep their stage order, and topics
+	// with listeners ordered by After or Before keep registration order.
+	// On asynchronous topics, it orders the jobs of each event.
+	Order DeliveryOrder
+	// Aging keeps urgent events from starving the others on an
+	// asynchronous topic: once events of normal or low priority have been
+	// kept waiting by urgent ones for Aging, the next free worker takes
+	// the oldest of them first, so telemetry still flows under sustained
+	// high-priority load. Each event ages from the time it was queued.
+	// Zero disables aging.
+	Aging time.Duration
+}
+
+// WithTopicConfig configures dispatch for the topics matching pattern,
+// overriding the bus-wide default. A pattern is either an exact event type
+// or contains "*" wildcards matching any sequence of characters, such as
+// "telemetry:*". Exact matches take precedence; among wildcard patterns the
+// first one registered wins.
+//
+// Every asynchronous configuration gets its own worker pool, shared by all
+// topics matching the pattern.
+//
+// Example:
+//
+//	bus := eventbus.New(
+//	    eventbus.WithAsync(4, 256),
+//	    eventbus.WithTopicConfig("app:quit", eventbus.TopicConfig{}),
+//	    eventbus.WithTopicConfig("telemetry:*", eventbus.TopicConfig{
+//	        Async:     true,
+//	        Workers:   1,
+//	        QueueSize: 1024,
+//	        Overflow:  eventbus.OverflowDropOldest,
+//	    }),
+//	)
+func WithTopicConfig(pattern EventType, config TopicConfig) Option {
+	return func(bus *eventBusImpl) {
+		bus.dispatch.routes = append(bus.dispatch.routes, &dispatchRoute{
+			pattern: pattern,
+			config:  config,
+		})
+	}
+}
+
+// dispatchRoute binds a topic pattern to its dispatch configuration.
+type dispatchRoute struct {
+	pattern EventType
+	config  TopicConfig
+	// pool is nil for synchronous routes.
+	pool executor
+	// watermark watches the queue of pool; see WithQueueWatermarks.
+	watermark *watermark
+}
+
+// dispatchTable resolves the dispatch route for each topic.
+// It is guarded by the bus mutex.
+type dispatchTable struct {
+	fallback *dispatchRoute
+	routes   []*dispatchRoute
+	resolved map[EventType]*dispatchRoute
+	// watermarks is set by WithQueueWatermarks.
+	watermarks *Watermarks
+	// onScale reports the changes of autoscaling pools.
+	onScale func(WorkersScaled)
+}
+
+// newDispatchTable creates a table delivering every topic synchronously.
+func newDispatchTable() *dispatchTable {
+	return &dispatchTable{
+		fallback: &dispatchRoute{pattern: "*"},
+		resolved: make(map[EventType]*dispatchRoute),
+	}
+}
+
+// route returns the dispatch route for eventType.
+func (table *dispatchTable) route(eventType EventType) *dispatchRoute {
+	if route, ok := table.resolved[eventType]; ok {
+		return route
+	}
+
+	route := table.fallback
+	for _, candidate := range table.routes {
+		if candidate.pattern == eventType {
+			route = candidate
+			break
+		}
+		if route == table.fallback && matchPattern(candidate.pattern, eventType) {
+			route = candidate
+		}
+	}
+	table.resolved[eventType] = route
+	return route
+}
+
+// start creates and starts the worker pools of asynchronous routes.
+func (table *dispatchTable) start(audit *leakAudit) {
+	for _, route := range table.all() {
+		if route.config.Async || route.config.Dispatcher != nil {
+			route.pool = newExecutor(route.config)
+			if pool, ok := route.pool.(*workerPool); ok && pool.scaler != nil {
+				pool.scaler.onScale = table.onScale
+			}
+			route.pool.start(audit, route.pattern)
+			if table.watermarks != nil && route.config.Dispatcher == nil {
+				route.watermark = newWatermark(table.watermarks, route)
+			}
+		}
+	}
+}
+
+// stop drains and stops every worker pool.
+func (table *dispatchTable) stop() {
+	for _, route := range table.all() {
+		if route.pool != nil {
+			route.pool.stop()
+		}
+	}
+}
+
+// all returns the fallback route followed by the configured routes.
+func (table *dispatchTable) all() []*dispatchRoute {
+	return append([]*dispatchRoute{table.fallback}, table.routes...)
+}
+
+// MatchPattern reports whether eventType matches pattern, using the
+// wildcard syntax of WithTopicConfig.
+//
+// Example:
+//
+//	eventbus.MatchPattern("tenant-a:*", "tenant-a:order_placed") // true
+func MatchPattern(pattern, eventType EventType) bool {
+	return matchPattern(pattern, eventType)
+}
+
+// matchPattern reports whether eventType matches pattern, where "*"
+// matches any sequence of characters, including none.
+func matchPattern(pattern, eventType EventType) bool {
+	p, s := string(pattern), string(eventType)
+	// Position to resume from after the last "*", for backtracking.
+	star, resume := -1, 0
+	i, j := 0, 0
+	for j < len(s) {
+		switch {
+		case i < len(p) && p[i] == '*':
+			star, resume = i, j
+			i++
+		case i < len(p) && p[i] == s[j]:
+			i++
+			j++
+		case star >= 0:
+			resume++
+			i, j = star+1, resume
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
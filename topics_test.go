@@ -0,0 +1,183 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestMatchPattern verifies wildcard topic matching
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern   EventType
+		eventType EventType
+		expected  bool
+	}{
+		{"app:quit", "app:quit", true},
+		{"app:quit", "app:quitting", false},
+		{"telemetry:*", "telemetry:fps", true},
+		{"telemetry:*", "telemetry:", true},
+		{"telemetry:*", "physics:tick", false},
+		{"*:died", "player:died", true},
+		{"*:died", "player:died:twice", false},
+		{"player:*:*", "player:1:moved", true},
+		{"*", "anything", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+	}
+
+	for _, test := range tests {
+		if got := MatchPattern(test.pattern, test.eventType); got != test.expected {
+			t.Errorf("MatchPattern(%q, %q) = %v, expected %v", test.pattern, test.eventType, got, test.expected)
+		}
+	}
+}
+
+// TestTopicConfigSyncOverride verifies that a topic can stay synchronous on an async bus
+func TestTopicConfigSyncOverride(t *testing.T) {
+	bus := New(
+		WithAsync(2, 8),
+		WithTopicConfig("app:quit", TopicConfig{}),
+	)
+	defer bus.Close()
+
+	quit := false
+	bus.Subscribe("app:quit", func(event Event) {
+		quit = true
+	})
+
+	bus.Publish(testEvent{eventType: "app:quit"})
+
+	if !quit {
+		t.Error("Expected app:quit to be delivered before Publish returned")
+	}
+}
+
+// TestTopicConfigDropNewest verifies that overflowing deliveries are dropped
+func TestTopicConfigDropNewest(t *testing.T) {
+	bus := New(WithTopicConfig("telemetry:*", TopicConfig{
+		Async:     true,
+		Workers:   1,
+		QueueSize: 1,
+		Overflow:  OverflowDropNewest,
+	}))
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var mu sync.Mutex
+	var received []string
+
+	bus.Subscribe("telemetry:fps", func(event Event) {
+		select {
+		case started <- struct{}{}:
+			<-release
+		default:
+		}
+		mu.Lock()
+		received = append(received, event.(testEvent).data)
+		mu.Unlock()
+	})
+
+	bus.Publish(testEvent{eventType: "telemetry:fps", data: "1"})
+	<-started
+	bus.Publish(testEvent{eventType: "telemetry:fps", data: "2"})
+
+	err := bus.PublishAndWait(context.Background(), testEvent{eventType: "telemetry:fps", data: "3"})
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected a queue full error, got %v", err)
+	}
+
+	close(release)
+	bus.Close()
+
+	if len(received) != 2 || received[1] != "2" {
+		t.Errorf("Expected events 1 and 2, got %v", received)
+	}
+}
+
+// TestTopicConfigDropOldest verifies that the oldest queued delivery makes room
+func TestTopicConfigDropOldest(t *testing.T) {
+	bus := New(WithTopicConfig("telemetry:*", TopicConfig{
+		Async:     true,
+		Workers:   1,
+		QueueSize: 1,
+		Overflow:  OverflowDropOldest,
+	}))
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var mu sync.Mutex
+	var received []string
+
+	bus.Subscribe("telemetry:fps", func(event Event) {
+		select {
+		case started <- struct{}{}:
+			<-release
+		default:
+		}
+		mu.Lock()
+		received = append(received, event.(testEvent).data)
+		mu.Unlock()
+	})
+
+	bus.Publish(testEvent{eventType: "telemetry:fps", data: "1"})
+	<-started
+	bus.Publish(testEvent{eventType: "telemetry:fps", data: "2"})
+	bus.Publish(testEvent{eventType: "telemetry:fps", data: "3"})
+
+	close(release)
+	bus.Close()
+
+	if len(received) != 2 || received[1] != "3" {
+		t.Errorf("Expected events 1 and 3, got %v", received)
+	}
+}
+
+// TestTopicConfigExactBeatsPattern verifies route precedence
+func TestTopicConfigExactBeatsPattern(t *testing.T) {
+	bus := New(
+		WithTopicConfig("player:*", TopicConfig{Async: true}),
+		WithTopicConfig("player:state", TopicConfig{}),
+	)
+	defer bus.Close()
+
+	impl := bus.(*eventBusImpl)
+	if impl.dispatch.route("player:state").pool != nil {
+		t.Error("Expected player:state to be synchronous")
+	}
+	if impl.dispatch.route("player:moved").pool == nil {
+		t.Error("Expected player:moved to be asynchronous")
+	}
+	if impl.dispatch.route("world:tick").pool != nil {
+		t.Error("Expected unmatched topics to use the synchronous default")
+	}
+}
+
+// FuzzMatchPattern verifies the wildcard matcher against a regular expression
+// built from the same pattern
+func FuzzMatchPattern(f *testing.F) {
+	f.Add("player:*", "player:jumped")
+	f.Add("*:died", "player:died")
+	f.Add("a*b*c", "aXbYbZc")
+	f.Add("**", "")
+	f.Add("*a*a*a*a*a*b", "aaaaaaaaaaaaaaaaaaaaaaaa")
+
+	f.Fuzz(func(t *testing.T, pattern, eventType string) {
+		if !utf8.ValidString(pattern) || !utf8.ValidString(eventType) {
+			return
+		}
+		parts := strings.Split(pattern, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		want := regexp.MustCompile("^(?s:" + strings.Join(parts, ".*") + ")$").MatchString(eventType)
+
+		if got := MatchPattern(EventType(pattern), EventType(eventType)); got != want {
+			t.Fatalf("Expected MatchPattern(%q, %q) to be %v, got %v", pattern, eventType, want, got)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// Event types of the HandlerTrace events produced by WithHandlerTrace.
+const (
+	HandlerStartType EventType = "eventbus:handler_start"
+	HandlerEndType   EventType = "eventbus:handler_end"
+)
+
+// HandlerTrace describes the start or end of a single listener invocation.
+// It is an event, so traces can be published on another bus.
+type HandlerTrace struct {
+	// Type is HandlerStartType or HandlerEndType.
+	Type EventType
+	// EventType is the type of the event being handled.
+	EventType EventType
+	// Handler is the label given with WithName, or "" if there is none.
+	Handler string
+	// EventID is the bus sequence of the event being handled, see
+	// Envelope.BusSequence.
+	EventID uint64
+	// Time is when the invocation started or ended.
+	Time time.Time
+	// Duration is the time the invocation took. It is zero at the start.
+	Duration time.Duration
+}
+
+// GetType returns HandlerStartType or HandlerEndType.
+func (t HandlerTrace) GetType() EventType {
+	return t.Type
+}
+
+// WithHandlerTrace calls trace before and after every listener invocation,
+// for profilers and timeline views. The end trace is reported even if the
+// listener panics. Listeners running in parallel report concurrently, so
+// trace must be safe for concurrent use and should return quickly.
+//
+// trace runs on the delivering goroutine, which holds the bus lock for
+// synchronous topics; forward traces to another bus rather than the traced
+// one.
+//
+// Example:
+//
+//	bus := eventbus.New(eventbus.WithHandlerTrace(func(t eventbus.HandlerTrace) {
+//	    editorBus.Publish(t)
+//	}))
+func WithHandlerTrace(trace func(HandlerTrace)) Option {
+	return func(bus *eventBusImpl) {
+		bus.trace = trace
+	}
+}
+
+// traceHandler reports the start of an invocation of s for event and
+// returns the function reporting its end.
+func (bus *eventBusImpl) traceHandler(ctx context.Context, s *Subscription, event Event) func() {
+	span := HandlerTrace{
+		Type:      HandlerStartType,
+		EventType: event.GetType(),
+		Handler:   s.name,
+		Time:      time.Now(),
+	}
+	if envelope, ok := ctx.Value(envelopeKey{}).(*Envelope); ok {
+		span.EventID = envelope.BusSequence
+	}
+	bus.trace(span)
+
+	return func() {
+		end := span
+		end.Type = HandlerEndType
+		end.Time = time.Now()
+		end.Duration = end.Time.Sub(span.Time)
+		bus.trace(end)
+	}
+}
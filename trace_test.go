@@ -0,0 +1,93 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestHandlerTrace verifies that every invocation is reported with its label and event ID
+func TestHandlerTrace(t *testing.T) {
+	var traces []HandlerTrace
+	bus := New(WithHandlerTrace(func(trace HandlerTrace) {
+		traces = append(traces, trace)
+	}))
+	bus.Subscribe("test:event", func(event Event) {}, WithName("first"))
+	bus.Subscribe("test:event", func(event Event) {}, WithName("second"))
+
+	bus.Publish(testEvent{eventType: "test:event"})
+	bus.Publish(testEvent{eventType: "test:event"})
+
+	if len(traces) != 8 {
+		t.Fatalf("Expected 8 traces, got %d", len(traces))
+	}
+	want := []struct {
+		traceType EventType
+		handler   string
+		eventID   uint64
+	}{
+		{HandlerStartType, "first", 1},
+		{HandlerEndType, "first", 1},
+		{HandlerStartType, "second", 1},
+		{HandlerEndType, "second", 1},
+		{HandlerStartType, "first", 2},
+	}
+	for i, w := range want {
+		trace := traces[i]
+		if trace.GetType() != w.traceType || trace.Handler != w.handler || trace.EventID != w.eventID {
+			t.Errorf("Expected %s of %s for event %d, got %+v", w.traceType, w.handler, w.eventID, trace)
+		}
+		if trace.EventType != "test:event" {
+			t.Errorf("Expected event type test:event, got %s", trace.EventType)
+		}
+	}
+	if traces[1].Duration < 0 || traces[1].Time.Before(traces[0].Time) {
+		t.Errorf("Expected the end to follow the start, got %+v and %+v", traces[0], traces[1])
+	}
+}
+
+// TestHandlerTracePanic verifies that the end is reported when a listener panics
+func TestHandlerTracePanic(t *testing.T) {
+	var mutex sync.Mutex
+	var traces []HandlerTrace
+	bus := New(WithHandlerTrace(func(trace HandlerTrace) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		traces = append(traces, trace)
+	}))
+	bus.Subscribe("test:event", func(event Event) { panic("boom") }, WithName("panicking"))
+
+	func() {
+		defer func() { recover() }()
+		bus.Publish(testEvent{eventType: "test:event"})
+	}()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(traces) != 2 || traces[1].GetType() != HandlerEndType {
+		t.Errorf("Expected a start and an end trace, got %+v", traces)
+	}
+}
+
+// TestHandlerTraceAsync verifies that asynchronous deliveries are traced
+func TestHandlerTraceAsync(t *testing.T) {
+	var mutex sync.Mutex
+	ends := 0
+	bus := New(WithAsync(2, 16), WithHandlerTrace(func(trace HandlerTrace) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if trace.GetType() == HandlerEndType {
+			ends++
+		}
+	}))
+	bus.Subscribe("test:event", func(event Event) {})
+	bus.Subscribe("test:event", func(event Event) {})
+
+	bus.Publish(testEvent{eventType: "test:event"})
+	bus.Close()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if ends != 2 {
+		t.Errorf("Expected 2 end traces, got %d", ends)
+	}
+}
@@ -0,0 +1,133 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrTxDone is returned when committing a transaction that has already
+// been committed or rolled back.
+var ErrTxDone = errors.New("eventbus: transaction already committed or rolled back")
+
+// Tx stages events for publishing, so a multi-step operation emits either
+// all of its events or none. Create one with EventBus.Begin. A Tx is safe
+// for concurrent use.
+type Tx struct {
+	bus    *eventBusImpl
+	staged []stagedEvent
+	// check rejects staged event types before Commit publishes, for
+	// transactions begun on a view returned by Restricted.
+	check func(EventType) error
+	done  bool
+	mutex sync.Mutex
+}
+
+// stagedEvent is an event waiting for Commit with its publishing context.
+type stagedEvent struct {
+	ctx   context.Context
+	event Event
+}
+
+// Begin starts a transaction on the bus.
+func (bus *eventBusImpl) Begin() *Tx {
+	return &Tx{bus: bus}
+}
+
+// Publish stages event. It is published with context.Background on Commit.
+func (tx *Tx) Publish(event Event) {
+	tx.PublishContext(context.Background(), event)
+}
+
+// PublishContext stages event, to be published with ctx on Commit.
+// Staging after Commit or Rollback has no effect.
+func (tx *Tx) PublishContext(ctx context.Context, event Event) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if !tx.done {
+		tx.staged = append(tx.staged, stagedEvent{ctx: ctx, event: event})
+	}
+}
+
+// Len returns the number of staged events.
+func (tx *Tx) Len() int {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	return len(tx.staged)
+}
+
+// Commit publishes the staged events in the order they were staged, as
+// they are at the time of the call. Events published by others may be
+// delivered between them.
+//
+// Before publishing any event, Commit checks all of them as publishing
+// does, and returns the first error, publishing nothing: ErrBusClosed,
+// ErrMutableEvent, ErrUnknownTopic, ErrPublishDepth, or, for a transaction
+// begun on a view returned by Restricted, ErrRestricted. Only two failures
+// can still cut a commit short, discarding the events after the failing
+// one: the store failing to append an event, with ErrStoreAppend, and a
+// concurrent Close, with ErrBusClosed. Listener failures are reported to
+// the Errors stream and do not stop the commit.
+//
+// Example:
+//
+//	tx := bus.Begin()
+//	tx.Publish(OrderPlaced{ID: id})
+//	if err := reserveStock(id); err != nil {
+//	    tx.Rollback()
+//	    return err
+//	}
+//	tx.Publish(StockReserved{OrderID: id})
+//	return tx.Commit()
+func (tx *Tx) Commit() error {
+	tx.mutex.Lock()
+	if tx.done {
+		tx.mutex.Unlock()
+		return ErrTxDone
+	}
+	tx.done = true
+	staged := tx.staged
+	tx.staged = nil
+	tx.mutex.Unlock()
+
+	if len(staged) == 0 {
+		return nil
+	}
+	if tx.check != nil {
+		for _, s := range staged {
+			if err := tx.check(s.event.GetType()); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.bus.lock(staged[0].ctx, staged[0].event); err != nil {
+		return err
+	}
+	for _, s := range staged {
+		if err := tx.bus.checkPublish(s.ctx, s.event); err != nil {
+			tx.bus.mutex.Unlock()
+			return err
+		}
+	}
+	tx.bus.mutex.Unlock()
+
+	for _, s := range staged {
+		// The waiter recovers the panics of synchronous listeners, which
+		// would otherwise end the commit.
+		if err := tx.bus.publish(s.ctx, s.event, &deliveryWaiter{done: make(chan struct{})}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback discards the staged events. It has no effect after Commit, so
+// it can be deferred.
+func (tx *Tx) Rollback() {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	tx.done = true
+	tx.staged = nil
+}
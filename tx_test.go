@@ -0,0 +1,130 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestTxCommit verifies that staged events are only delivered on Commit, in order
+func TestTxCommit(t *testing.T) {
+	bus := New()
+	var received []string
+	bus.Subscribe("order:placed", func(event Event) {
+		received = append(received, event.(orderEvent).orderID)
+	})
+
+	tx := bus.Begin()
+	tx.Publish(orderEvent{orderID: "o1"})
+	tx.Publish(orderEvent{orderID: "o2"})
+	if len(received) != 0 {
+		t.Fatalf("Expected no deliveries before Commit, got %v", received)
+	}
+	if tx.Len() != 2 {
+		t.Errorf("Expected 2 staged events, got %d", tx.Len())
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(received) != 2 || received[0] != "o1" || received[1] != "o2" {
+		t.Errorf("Expected [o1 o2], got %v", received)
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTxDone) {
+		t.Errorf("Expected ErrTxDone, got %v", err)
+	}
+}
+
+// TestTxRollback verifies that rolled back events are never delivered
+func TestTxRollback(t *testing.T) {
+	bus := New()
+	count := 0
+	bus.Subscribe("order:placed", func(event Event) { count++ })
+
+	tx := bus.Begin()
+	tx.Publish(orderEvent{orderID: "o1"})
+	tx.Rollback()
+	tx.Publish(orderEvent{orderID: "o2"})
+
+	if err := tx.Commit(); !errors.Is(err, ErrTxDone) {
+		t.Errorf("Expected ErrTxDone, got %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no deliveries, got %d", count)
+	}
+	if tx.Len() != 0 {
+		t.Errorf("Expected no staged events, got %d", tx.Len())
+	}
+}
+
+// TestTxRollbackAfterCommit verifies that a deferred Rollback leaves a committed transaction alone
+func TestTxRollbackAfterCommit(t *testing.T) {
+	bus := New()
+	count := 0
+	bus.Subscribe("order:placed", func(event Event) { count++ })
+
+	func() {
+		tx := bus.Begin()
+		defer tx.Rollback()
+		tx.Publish(orderEvent{orderID: "o1"})
+		tx.Commit()
+	}()
+
+	if count != 1 {
+		t.Errorf("Expected 1 delivery, got %d", count)
+	}
+}
+
+// TestTxCommitClosed verifies that committing on a closed bus reports ErrBusClosed
+func TestTxCommitClosed(t *testing.T) {
+	bus := New()
+	tx := bus.Begin()
+	tx.Publish(orderEvent{orderID: "o1"})
+	bus.Close()
+
+	if err := tx.Commit(); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Expected ErrBusClosed, got %v", err)
+	}
+}
+
+// TestTxCommitChecksFirst verifies that an event publishing would reject fails the commit before any event is emitted
+func TestTxCommitChecksFirst(t *testing.T) {
+	bus := New(WithKnownTopics(true, "order:placed"))
+	defer bus.Close()
+	count := 0
+	bus.Subscribe("order:placed", func(event Event) {
+		count++
+	})
+
+	tx := bus.Begin()
+	tx.Publish(orderEvent{orderID: "o1"})
+	tx.Publish(testEvent{eventType: "order:plcaed"})
+	if err := tx.Commit(); !errors.Is(err, ErrUnknownTopic) {
+		t.Errorf("Expected ErrUnknownTopic, got %v", err)
+	}
+	if count != 0 || bus.Stats().Published != 0 {
+		t.Errorf("Expected nothing emitted, got %d deliveries", count)
+	}
+}
+
+// TestTxCommitListenerPanic verifies that a panicking synchronous listener does not cut the commit short
+func TestTxCommitListenerPanic(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	var received []string
+	bus.Subscribe("order:placed", func(event Event) {
+		received = append(received, event.(orderEvent).orderID)
+		if len(received) == 1 {
+			panic("out of stock")
+		}
+	})
+
+	tx := bus.Begin()
+	tx.Publish(orderEvent{orderID: "o1"})
+	tx.Publish(orderEvent{orderID: "o2"})
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(received) != 2 {
+		t.Errorf("Expected both events delivered, got %v", received)
+	}
+}
@@ -0,0 +1,42 @@
+package eventbus
+
+import "context"
+
+// WithUntil cancels the subscription once the listener has received an
+// event satisfying done. That event is still delivered; later ones are not.
+// Only events reaching the listener, after filters such as WithDistinct,
+// are tested.
+//
+// On an asynchronous bus with several workers, events already being
+// delivered in parallel when done is satisfied may still reach the listener.
+//
+// Example:
+//
+//	// Wait for one specific player to respawn
+//	bus.Subscribe("player:respawned", showRespawnBanner, eventbus.WithUntil(func(e eventbus.Event) bool {
+//	    return e.(PlayerRespawned).PlayerID == playerID
+//	}))
+func WithUntil(done func(Event) bool) SubscribeOption {
+	return func(config *subscribeConfig) {
+		config.until = append(config.until, done)
+		config.options = append(config.options, "until")
+	}
+}
+
+// cancelling returns a listener that calls listener and then cancels the
+// subscription if any of until accepts the event.
+func (s *Subscription) cancelling(listener ContextListener, until []func(Event) bool) ContextListener {
+	if len(until) == 0 {
+		return listener
+	}
+
+	return func(ctx context.Context, event Event) {
+		listener(ctx, event)
+		for _, done := range until {
+			if done(event) {
+				s.Cancel()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package eventbus
+
+import "testing"
+
+type respawnEvent struct {
+	playerID string
+}
+
+func (e respawnEvent) GetType() EventType {
+	return "player:respawned"
+}
+
+// TestWithUntil verifies that the subscription ends after the matching event
+func TestWithUntil(t *testing.T) {
+	bus := New()
+	var seen []string
+
+	bus.Subscribe("player:respawned", func(event Event) {
+		seen = append(seen, event.(respawnEvent).playerID)
+	}, WithUntil(func(event Event) bool {
+		return event.(respawnEvent).playerID == "p2"
+	}))
+
+	for _, id := range []string{"p1", "p2", "p3"} {
+		bus.Publish(respawnEvent{playerID: id})
+	}
+
+	if len(seen) != 2 || seen[0] != "p1" || seen[1] != "p2" {
+		t.Errorf("Expected [p1 p2], got %v", seen)
+	}
+	if len(bus.Snapshot().Subscriptions()) != 0 {
+		t.Error("Expected the subscription to be removed")
+	}
+}
+
+// TestWithUntilAfterFilters verifies that filtered events do not end the subscription
+func TestWithUntilAfterFilters(t *testing.T) {
+	bus := New()
+	count := 0
+
+	bus.Subscribe("player:health", func(event Event) {
+		count++
+	}, WithDistinct(nil, nil), WithUntil(func(event Event) bool {
+		return event.(healthEvent).hp == 0
+	}))
+
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	bus.Publish(healthEvent{playerID: "p1", hp: 0})
+	bus.Publish(healthEvent{playerID: "p1", hp: 5})
+
+	if count != 2 {
+		t.Errorf("Expected 2 deliveries, got %d", count)
+	}
+}
+
+// TestWithUntilSnapshotOption verifies that the option is named in snapshots
+func TestWithUntilSnapshotOption(t *testing.T) {
+	bus := New()
+	bus.Subscribe("player:respawned", func(event Event) {}, WithUntil(func(Event) bool { return false }))
+
+	options := bus.Snapshot().Subscriptions()[0].Options
+	if len(options) != 1 || options[0] != "until" {
+		t.Errorf("Expected [until], got %v", options)
+	}
+}
@@ -0,0 +1,213 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// BusV2 is the core of an event bus as a small interface whose operations
+// take a context and return errors instead of panicking, so it can be
+// implemented and wrapped without following every addition to EventBus.
+// NewV2 creates one; AsV2 and V1 convert between the two interfaces, and
+// both views of a bus created with New or NewV2 share its state.
+//
+// Example:
+//
+//	bus := eventbus.NewV2(eventbus.WithAsync(4, 1024))
+//	defer bus.Close(context.Background())
+//
+//	sub, err := bus.Subscribe(ctx, "order:placed", func(ctx context.Context, event eventbus.Event) {
+//	    ship(event.(OrderPlaced))
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer sub.Cancel()
+//
+//	if err := bus.Publish(ctx, OrderPlaced{ID: "o-1"}, eventbus.Await()); err != nil {
+//	    log.Println("order handlers failed:", err)
+//	}
+type BusV2 interface {
+	// Publish sends event to the listeners of its type, recording the
+	// context values selected with WithContextFields. It returns the
+	// errors Publish panics with or ignores, such as ErrBusClosed, a
+	// failed store append, or the panics of listeners on synchronous
+	// topics, and with Await also the failures of the listeners.
+	Publish(ctx context.Context, event Event, opts ...PublishOption) error
+
+	// Subscribe registers listener for eventType until the returned
+	// subscription is cancelled or ctx is done. It returns an error for
+	// unknown topics on a bus created with WithKnownTopics rejecting them,
+	// and for dependency cycles between handlers.
+	Subscribe(ctx context.Context, eventType EventType, listener ContextListener, opts ...SubscribeOption) (*Subscription, error)
+
+	// Close stops accepting events and waits for the pending deliveries
+	// until ctx is done, in which case it returns the context error while
+	// the bus finishes closing in the background.
+	Close(ctx context.Context) error
+
+	// V1 returns the EventBus view of the bus, for APIs not available on
+	// BusV2.
+	V1() EventBus
+}
+
+// PublishOption configures a single BusV2.Publish call.
+type PublishOption func(*publishConfig)
+
+// publishConfig holds the settings of a BusV2.Publish call.
+type publishConfig struct {
+	await bool
+}
+
+// Await makes BusV2.Publish wait for every listener, like
+// EventBus.PublishAndWait, and return their failures.
+func Await() PublishOption {
+	return func(config *publishConfig) {
+		config.await = true
+	}
+}
+
+// NewV2 creates a bus like New and returns its BusV2 view.
+func NewV2(opts ...Option) BusV2 {
+	return AsV2(New(opts...))
+}
+
+// AsV2 returns the BusV2 view of bus. Buses created with New share their
+// state with the view; other EventBus implementations are adapted, with
+// their panics returned as errors.
+func AsV2(bus EventBus) BusV2 {
+	if impl, ok := bus.(*eventBusImpl); ok {
+		return busV2{impl}
+	}
+	return adaptedV2{bus}
+}
+
+// busV2 is the BusV2 view of a bus created with New.
+type busV2 struct {
+	bus *eventBusImpl
+}
+
+// Publish publishes event, waiting for the listeners with Await.
+// Synchronous topics deliver through a waiter even without Await, so
+// listener panics are recovered and returned instead of propagating.
+func (b busV2) Publish(ctx context.Context, event Event, opts ...PublishOption) error {
+	if newPublishConfig(opts).await {
+		return b.bus.PublishAndWait(ctx, event)
+	}
+	// The routes do not change after New.
+	if b.bus.dispatch.route(event.GetType()).pool != nil {
+		return b.bus.publish(ctx, event, nil)
+	}
+	waiter := &deliveryWaiter{done: make(chan struct{})}
+	if err := b.bus.publish(ctx, event, waiter); err != nil {
+		return err
+	}
+	var panics []error
+	for _, delivery := range waiter.receipt(event.GetType()).Failed() {
+		if delivery.Panicked() {
+			panics = append(panics, delivery.Err)
+		}
+	}
+	return errors.Join(panics...)
+}
+
+// Subscribe subscribes listener until the subscription is cancelled or
+// ctx is done.
+func (b busV2) Subscribe(ctx context.Context, eventType EventType, listener ContextListener, opts ...SubscribeOption) (*Subscription, error) {
+	sub, err := b.bus.subscribe(eventType, listener, opts, true)
+	if err != nil {
+		return nil, err
+	}
+	cancelWith(ctx, sub)
+	return sub, nil
+}
+
+// Close closes the bus, waiting until ctx is done.
+func (b busV2) Close(ctx context.Context) error {
+	return closeWithin(ctx, b.bus.Close)
+}
+
+// V1 returns the bus.
+func (b busV2) V1() EventBus {
+	return b.bus
+}
+
+// adaptedV2 is the BusV2 view of another EventBus implementation.
+type adaptedV2 struct {
+	bus EventBus
+}
+
+// Publish publishes event, waiting for the listeners with Await.
+func (a adaptedV2) Publish(ctx context.Context, event Event, opts ...PublishOption) (err error) {
+	if newPublishConfig(opts).await {
+		return a.bus.PublishAndWait(ctx, event)
+	}
+	defer recoverError(&err)
+	a.bus.PublishContext(ctx, event)
+	return nil
+}
+
+// Subscribe subscribes listener until the subscription is cancelled or
+// ctx is done.
+func (a adaptedV2) Subscribe(ctx context.Context, eventType EventType, listener ContextListener, opts ...SubscribeOption) (sub *Subscription, err error) {
+	defer recoverError(&err)
+	sub = a.bus.SubscribeContext(eventType, listener, opts...)
+	cancelWith(ctx, sub)
+	return sub, nil
+}
+
+// Close closes the bus, waiting until ctx is done.
+func (a adaptedV2) Close(ctx context.Context) error {
+	return closeWithin(ctx, a.bus.Close)
+}
+
+// V1 returns the adapted bus.
+func (a adaptedV2) V1() EventBus {
+	return a.bus
+}
+
+// newPublishConfig applies opts.
+func newPublishConfig(opts []PublishOption) publishConfig {
+	var config publishConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// cancelWith cancels sub when ctx is done.
+func cancelWith(ctx context.Context, sub *Subscription) {
+	if sub != nil && ctx.Done() != nil {
+		stop := context.AfterFunc(ctx, sub.Cancel)
+		context.AfterFunc(sub.ctx, func() { stop() })
+	}
+}
+
+// closeWithin runs closeBus and waits for it until ctx is done.
+func closeWithin(ctx context.Context, closeBus func()) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		closeBus()
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// recoverError turns a panic into an error stored in err.
+func recoverError(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if recovered, ok := r.(error); ok {
+		*err = recovered
+		return
+	}
+	*err = fmt.Errorf("eventbus: %v", r)
+}
@@ -0,0 +1,159 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestBusV2 verifies publishing, subscribing, and awaiting listeners through BusV2
+func TestBusV2(t *testing.T) {
+	bus := NewV2()
+	defer bus.Close(context.Background())
+
+	received := 0
+	if _, err := bus.Subscribe(context.Background(), "order:placed", func(ctx context.Context, event Event) {
+		received++
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus.Subscribe(context.Background(), "order:placed", func(ctx context.Context, event Event) {
+		panic("out of stock")
+	}, WithName("inventory"))
+
+	if err := bus.Publish(context.Background(), Of("order:placed", 1), Await()); !errors.Is(err, ErrHandlerPanic) {
+		t.Errorf("Expected the inventory panic, got %v", err)
+	}
+	if err := bus.Publish(context.Background(), Of("order:shipped", 1), Await()); !errors.Is(err, ErrNoSubscribers) {
+		t.Errorf("Expected ErrNoSubscribers, got %v", err)
+	}
+	if received != 1 {
+		t.Errorf("Expected 1 delivery, got %d", received)
+	}
+
+	if bus.V1().Stats().Published != 2 {
+		t.Errorf("Expected the V1 view to share the bus, got %d published", bus.V1().Stats().Published)
+	}
+	if err := bus.Close(context.Background()); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := bus.Publish(context.Background(), Of("order:placed", 1)); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Expected ErrBusClosed, got %v", err)
+	}
+}
+
+// TestBusV2Errors verifies that errors are returned instead of panicking
+func TestBusV2Errors(t *testing.T) {
+	bus := NewV2(WithKnownTopics(true, "order:*"))
+	defer bus.Close(context.Background())
+
+	if _, err := bus.Subscribe(context.Background(), "ordr:placed", func(ctx context.Context, event Event) {}); !errors.Is(err, ErrUnknownTopic) {
+		t.Errorf("Expected ErrUnknownTopic from Subscribe, got %v", err)
+	}
+	if err := bus.Publish(context.Background(), Of("ordr:placed", 1)); !errors.Is(err, ErrUnknownTopic) {
+		t.Errorf("Expected ErrUnknownTopic from Publish, got %v", err)
+	}
+}
+
+// TestBusV2SyncPanic verifies that a panicking listener on a synchronous topic is returned without stopping the others
+func TestBusV2SyncPanic(t *testing.T) {
+	bus := NewV2()
+	defer bus.Close(context.Background())
+
+	received := 0
+	bus.Subscribe(context.Background(), "order:placed", func(ctx context.Context, event Event) {
+		panic("out of stock")
+	}, WithName("inventory"))
+	bus.Subscribe(context.Background(), "order:placed", func(ctx context.Context, event Event) {
+		received++
+	})
+
+	err := bus.Publish(context.Background(), Of("order:placed", 1))
+	var handlerErr *HandlerError
+	if !errors.Is(err, ErrHandlerPanic) || !errors.As(err, &handlerErr) || handlerErr.Handler != "inventory" {
+		t.Errorf("Expected the inventory panic, got %v", err)
+	}
+	if received != 1 {
+		t.Errorf("Expected the other listener called, got %d deliveries", received)
+	}
+}
+
+// TestBusV2StoreFailure verifies that a failed store append is returned with and without Await
+func TestBusV2StoreFailure(t *testing.T) {
+	bus := NewV2(WithStore(&failingStore{}))
+	defer bus.Close(context.Background())
+	bus.Subscribe(context.Background(), "order:placed", func(ctx context.Context, event Event) {
+		t.Error("Event should not have been delivered")
+	})
+
+	if err := bus.Publish(context.Background(), Of("order:placed", 1)); !errors.Is(err, ErrStoreAppend) {
+		t.Errorf("Expected ErrStoreAppend, got %v", err)
+	}
+	if err := bus.Publish(context.Background(), Of("order:placed", 1), Await()); !errors.Is(err, ErrStoreAppend) {
+		t.Errorf("Expected ErrStoreAppend with Await, got %v", err)
+	}
+}
+
+// TestBusV2SubscribeContext verifies that subscriptions end with their context
+func TestBusV2SubscribeContext(t *testing.T) {
+	bus := NewV2()
+	defer bus.Close(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, _ := bus.Subscribe(ctx, "order:placed", func(ctx context.Context, event Event) {})
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for len(bus.V1().Snapshot().Subscriptions()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := len(bus.V1().Snapshot().Subscriptions()); n != 0 {
+		t.Errorf("Expected the subscription to be cancelled with its context, got %d", n)
+	}
+	sub.Cancel()
+}
+
+// TestBusV2Close verifies that Close gives up waiting when its context is done
+func TestBusV2Close(t *testing.T) {
+	bus := NewV2(WithAsync(1, 4))
+	release := make(chan struct{})
+	bus.Subscribe(context.Background(), "order:placed", func(ctx context.Context, event Event) {
+		<-release
+	})
+	bus.Publish(context.Background(), Of("order:placed", 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to expire, got %v", err)
+	}
+	close(release)
+}
+
+// panickingBus is an EventBus implementation whose publishing panics.
+type panickingBus struct {
+	EventBus
+}
+
+func (b panickingBus) PublishContext(ctx context.Context, event Event) {
+	panic(ErrBusClosed)
+}
+
+// TestAsV2 verifies the views of native and other EventBus implementations
+func TestAsV2(t *testing.T) {
+	native := New()
+	defer native.Close()
+	if AsV2(native).V1() != native {
+		t.Error("Expected the V2 view of a native bus to return it")
+	}
+
+	other := panickingBus{native}
+	adapted := AsV2(other)
+	if err := adapted.Publish(context.Background(), Of("order:placed", 1)); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Expected the panic as an error, got %v", err)
+	}
+	if adapted.V1() != EventBus(other) {
+		t.Error("Expected the adapted bus back")
+	}
+}
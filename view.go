@@ -0,0 +1,355 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnly is returned, or raised as a panic, when a read-only view
+// returned by ReadOnly is used to publish.
+var ErrReadOnly = errors.New("eventbus: bus view is read-only")
+
+// ReadOnly returns a view of the bus for plugins and UI layers that
+// observe events but must never emit them. Subscribing and querying work
+// as on the bus. PublishAndWait, PublishDetailed, Reconfigure, and the
+// Import of History return ErrReadOnly; Publish, PublishContext, Begin,
+// DeclarePublisher, Publisher, and Close panic with it, since they cannot
+// return an error.
+//
+// Example:
+//
+//	overlay.Attach(bus.ReadOnly())
+func (bus *eventBusImpl) ReadOnly() EventBus {
+	return readOnlyBus{bus}
+}
+
+// readOnlyBus is the view returned by ReadOnly.
+type readOnlyBus struct {
+	EventBus
+}
+
+// Publish panics with ErrReadOnly.
+func (view readOnlyBus) Publish(event Event) {
+	panic(readOnlyError("publish", event.GetType()))
+}
+
+// PublishContext panics with ErrReadOnly.
+func (view readOnlyBus) PublishContext(ctx context.Context, event Event) {
+	panic(readOnlyError("publish", event.GetType()))
+}
+
+// PublishAndWait returns ErrReadOnly.
+func (view readOnlyBus) PublishAndWait(ctx context.Context, event Event) error {
+	return readOnlyError("publish", event.GetType())
+}
+
+// PublishDetailed returns an empty receipt and ErrReadOnly.
+func (view readOnlyBus) PublishDetailed(ctx context.Context, event Event) (*Receipt, error) {
+	return &Receipt{EventType: event.GetType()}, readOnlyError("publish", event.GetType())
+}
+
+// Begin panics with ErrReadOnly.
+func (view readOnlyBus) Begin() *Tx {
+	panic(fmt.Errorf("%w: cannot begin a transaction", ErrReadOnly))
+}
+
+// DeclarePublisher panics with ErrReadOnly.
+func (view readOnlyBus) DeclarePublisher(component string, topics ...EventType) {
+	panic(fmt.Errorf("%w: cannot declare %s as a publisher", ErrReadOnly, component))
+}
+
+// Close panics with ErrReadOnly; the owner of the bus closes it.
+func (view readOnlyBus) Close() {
+	panic(fmt.Errorf("%w: cannot close the bus", ErrReadOnly))
+}
+
+// Publisher panics with ErrReadOnly.
+func (view readOnlyBus) Publisher(component string) *Publisher {
+	panic(fmt.Errorf("%w: cannot publish as %s", ErrReadOnly, component))
+}
+
+// ReadOnly returns the view itself.
+func (view readOnlyBus) ReadOnly() EventBus {
+	return view
+}
+
+// Reconfigure returns ErrReadOnly.
+func (view readOnlyBus) Reconfigure(opts ...Option) error {
+	return fmt.Errorf("%w: cannot reconfigure the bus", ErrReadOnly)
+}
+
+// History returns the history of the bus, whose Import returns
+// ErrReadOnly.
+func (view readOnlyBus) History() *History {
+	history := *view.EventBus.History()
+	history.readOnly = fmt.Errorf("%w: cannot import history", ErrReadOnly)
+	return &history
+}
+
+// Restricted returns a read-only view of the restricted bus.
+func (view readOnlyBus) Restricted(rules Restriction) EventBus {
+	return readOnlyBus{view.EventBus.Restricted(rules)}
+}
+
+// readOnlyError describes a rejected operation on eventType.
+func readOnlyError(operation string, eventType EventType) error {
+	return fmt.Errorf("%w: cannot %s %q", ErrReadOnly, operation, eventType)
+}
+
+// ErrRestricted is returned, or raised as a panic, when a view returned by
+// Restricted is used with an event type its rules do not allow.
+var ErrRestricted = errors.New("eventbus: event type not allowed by bus view")
+
+// Restriction lists the event types a view returned by Restricted may use.
+// Both lists hold patterns with "*" wildcards as in WithTopicConfig.
+type Restriction struct {
+	// Allow lists the permitted event types. An empty list permits every
+	// type not denied.
+	Allow []EventType
+	// Deny lists forbidden event types, taking precedence over Allow.
+	Deny []EventType
+}
+
+// allows reports whether rules permit eventType. A subscription pattern is
+// matched like an event type, so "order:*" is allowed by "order:*" but
+// "*" is not.
+func (rules Restriction) allows(eventType EventType) bool {
+	for _, pattern := range rules.Deny {
+		if matchPattern(pattern, eventType) {
+			return false
+		}
+	}
+	if len(rules.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range rules.Allow {
+		if matchPattern(pattern, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Restricted returns a view of the bus limited to the event types rules
+// allow, for handing to a subsystem that must only interact with its own
+// topics, preventing accidental coupling across domains. Subscribing,
+// publishing, reading the last-value caches, describing, muting, and
+// declaring publishers on other types are rejected: PublishAndWait,
+// PublishDetailed, and WaitForCorrelated return ErrRestricted, a
+// transaction begun on the view fails to commit with it before publishing
+// anything, and the other methods panic with it. Solo and Unsolo, which
+// affect every topic, and Close always panic, and Reconfigure and the
+// Import of History return ErrRestricted. Snapshot only holds the
+// subscriptions to allowed types. History, Stats, Graph, Validate, and
+// Errors describe the whole bus.
+//
+// Restricting a view again allows only the types both rules allow.
+//
+// Example:
+//
+//	billing.Start(bus.Restricted(eventbus.Restriction{
+//	    Allow: []eventbus.EventType{"invoice:*", "payment:*"},
+//	}))
+func (bus *eventBusImpl) Restricted(rules Restriction) EventBus {
+	return restrictedBus{EventBus: bus, rules: rules}
+}
+
+// restrictedBus is the view returned by Restricted.
+type restrictedBus struct {
+	EventBus
+	rules Restriction
+}
+
+// Subscribe subscribes like EventBus.Subscribe, panicking with
+// ErrRestricted if eventType is not allowed.
+func (view restrictedBus) Subscribe(eventType EventType, listener EventListener, opts ...SubscribeOption) *Subscription {
+	view.require("subscribe to", eventType)
+	return view.EventBus.Subscribe(eventType, listener, opts...)
+}
+
+// SubscribeContext subscribes like EventBus.SubscribeContext, panicking
+// with ErrRestricted if eventType is not allowed.
+func (view restrictedBus) SubscribeContext(eventType EventType, listener ContextListener, opts ...SubscribeOption) *Subscription {
+	view.require("subscribe to", eventType)
+	return view.EventBus.SubscribeContext(eventType, listener, opts...)
+}
+
+// Publish publishes like EventBus.Publish, panicking with ErrRestricted if
+// the event type is not allowed.
+func (view restrictedBus) Publish(event Event) {
+	view.require("publish", event.GetType())
+	view.EventBus.Publish(event)
+}
+
+// PublishContext publishes like EventBus.PublishContext, panicking with
+// ErrRestricted if the event type is not allowed.
+func (view restrictedBus) PublishContext(ctx context.Context, event Event) {
+	view.require("publish", event.GetType())
+	view.EventBus.PublishContext(ctx, event)
+}
+
+// PublishAndWait publishes like EventBus.PublishAndWait, returning
+// ErrRestricted if the event type is not allowed.
+func (view restrictedBus) PublishAndWait(ctx context.Context, event Event) error {
+	if err := view.check("publish", event.GetType()); err != nil {
+		return err
+	}
+	return view.EventBus.PublishAndWait(ctx, event)
+}
+
+// PublishDetailed publishes like EventBus.PublishDetailed, returning an
+// empty receipt and ErrRestricted if the event type is not allowed.
+func (view restrictedBus) PublishDetailed(ctx context.Context, event Event) (*Receipt, error) {
+	if err := view.check("publish", event.GetType()); err != nil {
+		return &Receipt{EventType: event.GetType()}, err
+	}
+	return view.EventBus.PublishDetailed(ctx, event)
+}
+
+// Close panics with ErrRestricted; the owner of the bus closes it.
+func (view restrictedBus) Close() {
+	panic(fmt.Errorf("%w: cannot close the bus", ErrRestricted))
+}
+
+// Latest reads the cache like EventBus.Latest, panicking with
+// ErrRestricted if eventType is not allowed.
+func (view restrictedBus) Latest(eventType EventType, key any) (Event, bool) {
+	view.require("read", eventType)
+	return view.EventBus.Latest(eventType, key)
+}
+
+// ClearLatest clears the cache like EventBus.ClearLatest, panicking with
+// ErrRestricted if eventType is not allowed.
+func (view restrictedBus) ClearLatest(eventType EventType, keys ...any) {
+	view.require("clear", eventType)
+	view.EventBus.ClearLatest(eventType, keys...)
+}
+
+// WaitForCorrelated waits like EventBus.WaitForCorrelated, returning
+// ErrRestricted if eventType is not allowed.
+func (view restrictedBus) WaitForCorrelated(ctx context.Context, correlationID string, eventType EventType) (Event, error) {
+	if err := view.check("wait for", eventType); err != nil {
+		return nil, err
+	}
+	return view.EventBus.WaitForCorrelated(ctx, correlationID, eventType)
+}
+
+// Begin starts a transaction whose Commit returns ErrRestricted, without
+// publishing anything, if a staged event type is not allowed.
+func (view restrictedBus) Begin() *Tx {
+	tx := view.EventBus.Begin()
+	outer := tx.check
+	tx.check = func(eventType EventType) error {
+		if outer != nil {
+			if err := outer(eventType); err != nil {
+				return err
+			}
+		}
+		return view.check("publish", eventType)
+	}
+	return tx
+}
+
+// Mute mutes like EventBus.Mute, panicking with ErrRestricted if pattern
+// is not allowed.
+func (view restrictedBus) Mute(pattern EventType) {
+	view.require("mute", pattern)
+	view.EventBus.Mute(pattern)
+}
+
+// Unmute unmutes like EventBus.Unmute, panicking with ErrRestricted if
+// pattern is not allowed.
+func (view restrictedBus) Unmute(pattern EventType) {
+	view.require("unmute", pattern)
+	view.EventBus.Unmute(pattern)
+}
+
+// Solo panics with ErrRestricted, since it silences every other topic.
+func (view restrictedBus) Solo(pattern EventType) {
+	panic(restrictedError("solo", pattern))
+}
+
+// Unsolo panics with ErrRestricted.
+func (view restrictedBus) Unsolo(pattern EventType) {
+	panic(restrictedError("unsolo", pattern))
+}
+
+// Describe describes the topic like EventBus.Describe, panicking with
+// ErrRestricted if eventType is not allowed.
+func (view restrictedBus) Describe(eventType EventType) TopicDescription {
+	view.require("describe", eventType)
+	return view.EventBus.Describe(eventType)
+}
+
+// DeclarePublisher declares like EventBus.DeclarePublisher, panicking with
+// ErrRestricted if one of topics is not allowed.
+func (view restrictedBus) DeclarePublisher(component string, topics ...EventType) {
+	for _, topic := range topics {
+		view.require("declare a publisher of", topic)
+	}
+	view.EventBus.DeclarePublisher(component, topics...)
+}
+
+// ReadOnly returns a read-only view of the restricted view.
+func (view restrictedBus) ReadOnly() EventBus {
+	return readOnlyBus{view}
+}
+
+// Publisher returns a handle publishing through the view, so it is
+// restricted the same way.
+func (view restrictedBus) Publisher(component string) *Publisher {
+	return &Publisher{bus: view, component: component}
+}
+
+// Reconfigure returns ErrRestricted, since options apply to every topic.
+func (view restrictedBus) Reconfigure(opts ...Option) error {
+	return fmt.Errorf("%w: cannot reconfigure the bus", ErrRestricted)
+}
+
+// History returns the history of the bus, whose Import returns
+// ErrRestricted, since it writes events of every type.
+func (view restrictedBus) History() *History {
+	history := *view.EventBus.History()
+	history.readOnly = fmt.Errorf("%w: cannot import history", ErrRestricted)
+	return &history
+}
+
+// Snapshot returns a snapshot of the subscriptions to allowed types, so
+// the view cannot reach the subscriptions of others.
+func (view restrictedBus) Snapshot() *Snapshot {
+	snapshot := view.EventBus.Snapshot()
+	allowed := &Snapshot{}
+	for i, sub := range snapshot.subscriptions {
+		if view.rules.allows(sub.eventType) {
+			allowed.subscriptions = append(allowed.subscriptions, sub)
+			allowed.health = append(allowed.health, snapshot.health[i])
+		}
+	}
+	return allowed
+}
+
+// Restricted restricts the view further.
+func (view restrictedBus) Restricted(rules Restriction) EventBus {
+	return restrictedBus{EventBus: view, rules: rules}
+}
+
+// check returns ErrRestricted if eventType is not allowed.
+func (view restrictedBus) check(operation string, eventType EventType) error {
+	if view.rules.allows(eventType) {
+		return nil
+	}
+	return restrictedError(operation, eventType)
+}
+
+// require panics with ErrRestricted if eventType is not allowed.
+func (view restrictedBus) require(operation string, eventType EventType) {
+	if err := view.check(operation, eventType); err != nil {
+		panic(err)
+	}
+}
+
+// restrictedError describes a rejected operation on eventType.
+func restrictedError(operation string, eventType EventType) error {
+	return fmt.Errorf("%w: cannot %s %q", ErrRestricted, operation, eventType)
+}
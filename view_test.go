@@ -0,0 +1,188 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+// expectPanic fails the test unless fn panics with an error matching target.
+func expectPanic(t *testing.T, target error, fn func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		err, _ := recover().(error)
+		if !errors.Is(err, target) {
+			t.Errorf("Expected a panic matching %v, got %v", target, err)
+		}
+	}()
+	fn()
+}
+
+// TestReadOnly verifies that a read-only view subscribes but cannot publish
+func TestReadOnly(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	view := bus.ReadOnly()
+	count := 0
+
+	view.Subscribe("player:health", func(event Event) { count++ })
+	bus.Publish(healthEvent{playerID: "p1", hp: 10})
+	if count != 1 {
+		t.Errorf("Expected 1 delivery through the view, got %d", count)
+	}
+
+	event := healthEvent{playerID: "p1", hp: 5}
+	expectPanic(t, ErrReadOnly, func() { view.Publish(event) })
+	expectPanic(t, ErrReadOnly, func() { view.PublishContext(context.Background(), event) })
+	expectPanic(t, ErrReadOnly, func() { view.Begin() })
+	expectPanic(t, ErrReadOnly, func() { view.DeclarePublisher("overlay", "player:health") })
+	expectPanic(t, ErrReadOnly, func() { view.Close() })
+	if err := view.PublishAndWait(context.Background(), event); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+	if _, err := view.PublishDetailed(context.Background(), event); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected no deliveries from the view, got %d", count-1)
+	}
+}
+
+// TestReadOnlyV2 verifies that the V2 adapter of a view reports ErrReadOnly
+func TestReadOnlyV2(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	err := AsV2(bus.ReadOnly()).Publish(context.Background(), healthEvent{playerID: "p1", hp: 10})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
+
+// TestRestricted verifies that a restricted view only uses allowed types
+func TestRestricted(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	view := bus.Restricted(Restriction{
+		Allow: []EventType{"invoice:*"},
+		Deny:  []EventType{"invoice:internal"},
+	})
+	count := 0
+
+	view.Subscribe("invoice:paid", func(event Event) { count++ })
+	view.Publish(testEvent{eventType: "invoice:paid"})
+	if count != 1 {
+		t.Errorf("Expected 1 delivery through the view, got %d", count)
+	}
+
+	foreign := testEvent{eventType: "order:placed"}
+	denied := testEvent{eventType: "invoice:internal"}
+	expectPanic(t, ErrRestricted, func() { view.Subscribe("order:placed", func(event Event) {}) })
+	expectPanic(t, ErrRestricted, func() { view.Subscribe("*", func(event Event) {}) })
+	expectPanic(t, ErrRestricted, func() { view.Publish(foreign) })
+	expectPanic(t, ErrRestricted, func() { view.PublishContext(context.Background(), denied) })
+	expectPanic(t, ErrRestricted, func() { view.Latest("order:placed", "o1") })
+	expectPanic(t, ErrRestricted, func() { view.Mute("order:*") })
+	expectPanic(t, ErrRestricted, func() { view.Solo("invoice:*") })
+	expectPanic(t, ErrRestricted, func() { view.DeclarePublisher("billing", "invoice:paid", "order:placed") })
+	expectPanic(t, ErrRestricted, func() { view.Close() })
+	if err := view.PublishAndWait(context.Background(), foreign); !errors.Is(err, ErrRestricted) {
+		t.Errorf("Expected ErrRestricted, got %v", err)
+	}
+	if _, err := view.PublishDetailed(context.Background(), denied); !errors.Is(err, ErrRestricted) {
+		t.Errorf("Expected ErrRestricted, got %v", err)
+	}
+	if _, err := view.WaitForCorrelated(context.Background(), "c1", "order:placed"); !errors.Is(err, ErrRestricted) {
+		t.Errorf("Expected ErrRestricted, got %v", err)
+	}
+	expectPanic(t, ErrRestricted, func() { view.Publisher("billing").Publish(foreign) })
+}
+
+// TestRestrictedTx verifies that a transaction on a restricted view
+// publishes nothing if a staged type is not allowed
+func TestRestrictedTx(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	view := bus.Restricted(Restriction{Allow: []EventType{"invoice:*"}})
+	count := 0
+	bus.Subscribe("invoice:paid", func(event Event) { count++ })
+
+	tx := view.Begin()
+	tx.Publish(testEvent{eventType: "invoice:paid"})
+	tx.Publish(testEvent{eventType: "order:placed"})
+	if err := tx.Commit(); !errors.Is(err, ErrRestricted) {
+		t.Errorf("Expected ErrRestricted, got %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no deliveries, got %d", count)
+	}
+}
+
+// TestRestrictedNested verifies that restricting a view again intersects
+// the rules and that read-only views stay read-only
+func TestRestrictedNested(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	view := bus.Restricted(Restriction{Allow: []EventType{"invoice:*"}}).
+		Restricted(Restriction{Allow: []EventType{"invoice:paid", "order:placed"}})
+
+	view.Publish(testEvent{eventType: "invoice:paid"})
+	expectPanic(t, ErrRestricted, func() { view.Publish(testEvent{eventType: "invoice:sent"}) })
+	expectPanic(t, ErrRestricted, func() { view.Publish(testEvent{eventType: "order:placed"}) })
+
+	observer := bus.ReadOnly().Restricted(Restriction{Allow: []EventType{"invoice:*"}})
+	observer.Subscribe("invoice:paid", func(event Event) {})
+	expectPanic(t, ErrReadOnly, func() { observer.Publish(testEvent{eventType: "invoice:paid"}) })
+	expectPanic(t, ErrReadOnly, func() { view.ReadOnly().Publish(testEvent{eventType: "invoice:paid"}) })
+}
+
+// TestRestrictedSnapshot verifies that a restricted view cannot reach or cancel the subscriptions to denied types
+func TestRestrictedSnapshot(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	count := 0
+	bus.Subscribe("order:placed", func(event Event) { count++ })
+	bus.Subscribe("invoice:paid", func(event Event) {})
+	view := bus.Restricted(Restriction{Allow: []EventType{"invoice:*"}})
+
+	snapshot := view.Snapshot()
+	if infos := snapshot.Subscriptions(); len(infos) != 1 || infos[0].EventType != "invoice:paid" {
+		t.Fatalf("Expected only the invoice subscription, got %+v", infos)
+	}
+	if sub := snapshot.Subscription(1); sub != nil {
+		t.Errorf("Expected no second subscription, got %v", sub.eventType)
+	}
+	snapshot.Subscription(0).Cancel()
+
+	bus.Publish(testEvent{eventType: "order:placed"})
+	if count != 1 {
+		t.Errorf("Expected the denied subscription to stay active, got %d deliveries", count)
+	}
+}
+
+// TestViewHistoryReadOnly verifies that the history of a view cannot import into the store
+func TestViewHistoryReadOnly(t *testing.T) {
+	store := NewMemoryStore()
+	bus := New(WithStore(store))
+	defer bus.Close()
+	bus.Publish(testEvent{eventType: "invoice:paid"})
+	var export bytes.Buffer
+	if err := bus.History().Export(&export); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	readOnly := bus.ReadOnly().History().Compress(64)
+	if err := readOnly.Import(bytes.NewReader(export.Bytes())); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+	restricted := bus.Restricted(Restriction{Allow: []EventType{"invoice:*"}}).History()
+	if err := restricted.Import(bytes.NewReader(export.Bytes())); !errors.Is(err, ErrRestricted) {
+		t.Errorf("Expected ErrRestricted, got %v", err)
+	}
+	var exported bytes.Buffer
+	if err := restricted.Export(&exported); err != nil || exported.Len() == 0 {
+		t.Errorf("Expected the view to export the history, got %v", err)
+	}
+}
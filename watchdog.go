@@ -0,0 +1,177 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// WatchdogAlertType is the event type of the alerts a Watchdog publishes.
+const WatchdogAlertType EventType = "eventbus:watchdog_silent"
+
+// WatchdogAlert reports that an expected periodic event stopped arriving.
+type WatchdogAlert struct {
+	// EventType is the type of the missing event.
+	EventType EventType
+	// Every is the expected interval between events.
+	Every time.Duration
+	// LastSeen is when the event last arrived. It is the zero time if the
+	// event never arrived since Expect was called.
+	LastSeen time.Time
+}
+
+// GetType returns WatchdogAlertType.
+func (a WatchdogAlert) GetType() EventType {
+	return WatchdogAlertType
+}
+
+// Watchdog raises an alert when an event that is expected periodically,
+// such as a heartbeat, stops arriving. It detects systems that silently
+// stopped emitting without failing.
+//
+// An alert is raised once per silence: after an alert, the watchdog waits
+// for the event to arrive again before watching for the next silence.
+//
+// A Watchdog is safe for concurrent use.
+//
+// Example:
+//
+//	watchdog := eventbus.NewWatchdog(bus, nil)
+//	watchdog.Expect("heartbeat:world", 5*time.Second)
+//
+//	bus.Subscribe(eventbus.WatchdogAlertType, func(event eventbus.Event) {
+//	    alert := event.(eventbus.WatchdogAlert)
+//	    log.Printf("%s silent since %v", alert.EventType, alert.LastSeen)
+//	})
+type Watchdog struct {
+	bus     EventBus
+	alert   func(WatchdogAlert)
+	watches map[EventType]*watch
+	stopped bool
+	mutex   sync.Mutex
+}
+
+// watch tracks one expected event type.
+type watch struct {
+	every    time.Duration
+	started  time.Time
+	lastSeen time.Time
+	alerted  bool
+	timer    *time.Timer
+	sub      *Subscription
+}
+
+// NewWatchdog creates a watchdog for events published on bus. Alerts are
+// passed to alert, or published on bus as WatchdogAlert events if alert
+// is nil.
+func NewWatchdog(bus EventBus, alert func(WatchdogAlert)) *Watchdog {
+	w := &Watchdog{
+		bus:     bus,
+		alert:   alert,
+		watches: make(map[EventType]*watch),
+	}
+	if w.alert == nil {
+		w.alert = func(a WatchdogAlert) {
+			bus.Publish(a)
+		}
+	}
+	return w
+}
+
+// Expect raises an alert whenever no event of eventType arrives for every.
+// The first interval starts now. Calling Expect again for the same event
+// type replaces the previous interval.
+func (w *Watchdog) Expect(eventType EventType, every time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.stopped {
+		return
+	}
+	if previous, ok := w.watches[eventType]; ok {
+		previous.stop()
+	}
+
+	watched := &watch{every: every, started: time.Now()}
+	watched.timer = time.AfterFunc(every, func() {
+		expire := func() { w.expire(eventType, watched) }
+		if bus, ok := w.bus.(*eventBusImpl); ok {
+			// Seeded scheduling orders the alert with other deliveries.
+			bus.fireTimer(expire)
+			return
+		}
+		expire()
+	})
+	watched.sub = w.bus.Subscribe(eventType, func(event Event) {
+		w.seen(watched)
+	})
+	w.watches[eventType] = watched
+}
+
+// Forget stops watching eventType.
+func (w *Watchdog) Forget(eventType EventType) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if watched, ok := w.watches[eventType]; ok {
+		watched.stop()
+		delete(w.watches, eventType)
+	}
+}
+
+// Stop stops watching every event type. No alerts are raised afterwards.
+func (w *Watchdog) Stop() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.stopped = true
+	for eventType, watched := range w.watches {
+		watched.stop()
+		delete(w.watches, eventType)
+	}
+}
+
+// seen records the arrival of a watched event and restarts its interval.
+func (w *Watchdog) seen(watched *watch) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.stopped || watched.sub == nil {
+		return
+	}
+	watched.lastSeen = time.Now()
+	watched.alerted = false
+	watched.timer.Reset(watched.every)
+}
+
+// expire raises an alert for watched unless an event arrived meanwhile.
+func (w *Watchdog) expire(eventType EventType, watched *watch) {
+	w.mutex.Lock()
+	if w.stopped || w.watches[eventType] != watched || watched.alerted {
+		w.mutex.Unlock()
+		return
+	}
+
+	// The timer may fire while seen is resetting it.
+	last := watched.lastSeen
+	if last.IsZero() {
+		last = watched.started
+	}
+	if remaining := watched.every - time.Since(last); remaining > 0 {
+		watched.timer.Reset(remaining)
+		w.mutex.Unlock()
+		return
+	}
+
+	watched.alerted = true
+	alert := WatchdogAlert{EventType: eventType, Every: watched.every, LastSeen: watched.lastSeen}
+	w.mutex.Unlock()
+
+	w.alert(alert)
+}
+
+// stop cancels the timer and subscription of the watch.
+func (watched *watch) stop() {
+	watched.timer.Stop()
+	watched.sub.Cancel()
+	watched.sub = nil
+}
@@ -0,0 +1,127 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWatchdogAlertsOnSilence verifies that a missing periodic event raises one alert
+func TestWatchdogAlertsOnSilence(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	alerts := make(chan WatchdogAlert, 10)
+	watchdog := NewWatchdog(bus, func(alert WatchdogAlert) {
+		alerts <- alert
+	})
+	defer watchdog.Stop()
+
+	watchdog.Expect("heartbeat:world", 20*time.Millisecond)
+	bus.Publish(testEvent{eventType: "heartbeat:world"})
+
+	select {
+	case alert := <-alerts:
+		if alert.EventType != "heartbeat:world" {
+			t.Errorf("Expected alert for heartbeat:world, got %s", alert.EventType)
+		}
+		if alert.LastSeen.IsZero() {
+			t.Error("Expected LastSeen to be set")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an alert")
+	}
+
+	select {
+	case alert := <-alerts:
+		t.Errorf("Expected a single alert per silence, got %+v", alert)
+	case <-time.After(60 * time.Millisecond):
+	}
+
+	// The event resuming re-arms the watchdog
+	bus.Publish(testEvent{eventType: "heartbeat:world"})
+	select {
+	case <-alerts:
+	case <-time.After(time.Second):
+		t.Fatal("Expected an alert after the event stopped again")
+	}
+}
+
+// TestWatchdogQuietWhileEventsArrive verifies that regular events prevent alerts
+func TestWatchdogQuietWhileEventsArrive(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	alerts := make(chan WatchdogAlert, 10)
+	watchdog := NewWatchdog(bus, func(alert WatchdogAlert) {
+		alerts <- alert
+	})
+	defer watchdog.Stop()
+
+	watchdog.Expect("heartbeat:world", 50*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		bus.Publish(testEvent{eventType: "heartbeat:world"})
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case alert := <-alerts:
+		t.Errorf("Expected no alert, got %+v", alert)
+	default:
+	}
+}
+
+// TestWatchdogPublishesAlert verifies that a nil callback publishes alerts on the bus,
+// even one rejecting unknown topics
+func TestWatchdogPublishesAlert(t *testing.T) {
+	bus := New(WithKnownTopics(true, "heartbeat:world"))
+	defer bus.Close()
+
+	alerts := make(chan Event, 10)
+	bus.Subscribe(WatchdogAlertType, func(event Event) {
+		alerts <- event
+	})
+
+	watchdog := NewWatchdog(bus, nil)
+	defer watchdog.Stop()
+	watchdog.Expect("heartbeat:world", 10*time.Millisecond)
+
+	select {
+	case event := <-alerts:
+		alert, ok := event.(WatchdogAlert)
+		if !ok {
+			t.Fatalf("Expected a WatchdogAlert, got %T", event)
+		}
+		if !alert.LastSeen.IsZero() {
+			t.Errorf("Expected zero LastSeen for an event never seen, got %v", alert.LastSeen)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an alert event")
+	}
+}
+
+// TestWatchdogStop verifies that no alerts are raised after Stop or Forget
+func TestWatchdogStop(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	alerts := make(chan WatchdogAlert, 10)
+	watchdog := NewWatchdog(bus, func(alert WatchdogAlert) {
+		alerts <- alert
+	})
+
+	watchdog.Expect("heartbeat:world", 10*time.Millisecond)
+	watchdog.Expect("heartbeat:audio", 10*time.Millisecond)
+	watchdog.Forget("heartbeat:audio")
+	watchdog.Stop()
+	watchdog.Expect("heartbeat:physics", 10*time.Millisecond)
+
+	select {
+	case alert := <-alerts:
+		t.Errorf("Expected no alert, got %+v", alert)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if count := len(bus.Snapshot().Subscriptions()); count != 0 {
+		t.Errorf("Expected watchdog subscriptions to be cancelled, got %d", count)
+	}
+}
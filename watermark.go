@@ -0,0 +1,129 @@
+package eventbus
+
+import (
+	"math"
+	"sync"
+)
+
+// Watermarks configures the queue depth callbacks of WithQueueWatermarks.
+// High and Low are fractions of the queue size of each asynchronous topic
+// configuration.
+type Watermarks struct {
+	// High is the fill ratio at which OnHigh is called. Defaults to 0.8.
+	High float64
+	// Low is the fill ratio at which OnLow is called once the queue drains
+	// after OnHigh. Defaults to 0.5.
+	Low float64
+	// OnHigh is called when a queue fills up to the high watermark, with
+	// the pattern of the topic configuration owning the queue, "*" for the
+	// WithAsync default, and the number of queued jobs.
+	OnHigh func(topic EventType, depth int)
+	// OnLow is called when a queue that reached the high watermark drains
+	// down to the low watermark.
+	OnLow func(topic EventType, depth int)
+}
+
+// WithQueueWatermarks calls watermarks.OnHigh when an asynchronous queue
+// fills up to its high watermark, and watermarks.OnLow once it has drained
+// to its low watermark again, so applications can shed load or add
+// capacity before the overflow policy starts dropping events. Each
+// callback is called once per crossing; the gap between the watermarks
+// keeps a queue hovering around one of them from calling back repeatedly.
+//
+// The callbacks run on the goroutine that queued or took the job, a
+// publisher or a worker, so they must return quickly. Topics with a
+// custom Dispatcher have no queue of the bus and are not watched.
+//
+// Example:
+//
+//	bus := eventbus.New(
+//	    eventbus.WithAsync(8, 1024),
+//	    eventbus.WithQueueWatermarks(eventbus.Watermarks{
+//	        OnHigh: func(topic eventbus.EventType, depth int) { shedding.Store(true) },
+//	        OnLow:  func(topic eventbus.EventType, depth int) { shedding.Store(false) },
+//	    }),
+//	)
+func WithQueueWatermarks(watermarks Watermarks) Option {
+	return func(bus *eventBusImpl) {
+		if watermarks.High <= 0 || watermarks.High > 1 {
+			watermarks.High = 0.8
+		}
+		if watermarks.Low <= 0 || watermarks.Low >= watermarks.High {
+			watermarks.Low = min(0.5, watermarks.High/2)
+		}
+		bus.dispatch.watermarks = &watermarks
+	}
+}
+
+// watermark tracks whether the queue of a route is above its high
+// watermark.
+type watermark struct {
+	config  *Watermarks
+	pattern EventType
+	pool    executor
+	high    int
+	low     int
+
+	mutex sync.Mutex
+	above bool
+}
+
+// newWatermark watches the queue of route, whose pool must be started.
+func newWatermark(config *Watermarks, route *dispatchRoute) *watermark {
+	size := max(route.config.QueueSize, 1)
+	return &watermark{
+		config:  config,
+		pattern: route.pattern,
+		pool:    route.pool,
+		high:    max(int(math.Ceil(config.High*float64(size))), 1),
+		low:     int(config.Low * float64(size)),
+	}
+}
+
+// rise checks the queue after a job was queued. It does nothing on a nil
+// watermark.
+func (w *watermark) rise() {
+	if w == nil {
+		return
+	}
+	depth := w.pool.queued()
+	if depth < w.high {
+		return
+	}
+	w.mutex.Lock()
+	crossed := !w.above
+	w.above = true
+	w.mutex.Unlock()
+	if crossed && w.config.OnHigh != nil {
+		w.config.OnHigh(w.pattern, depth)
+	}
+}
+
+// raised reports whether the queue is above its high watermark.
+func (w *watermark) raised() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.above
+}
+
+// fall checks the queue after a job was taken. It does nothing on a nil
+// watermark.
+func (w *watermark) fall() {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	if !w.above {
+		w.mutex.Unlock()
+		return
+	}
+	depth := w.pool.queued()
+	crossed := depth <= w.low
+	if crossed {
+		w.above = false
+	}
+	w.mutex.Unlock()
+	if crossed && w.config.OnLow != nil {
+		w.config.OnLow(w.pattern, depth)
+	}
+}
@@ -0,0 +1,68 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestQueueWatermarks verifies that crossing the watermarks calls back once each way
+func TestQueueWatermarks(t *testing.T) {
+	var mutex sync.Mutex
+	var calls []string
+	var highDepth int
+	bus := New(
+		WithAsync(1, 10),
+		WithQueueWatermarks(Watermarks{
+			OnHigh: func(topic EventType, depth int) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				calls = append(calls, "high:"+string(topic))
+				highDepth = depth
+			},
+			OnLow: func(topic EventType, depth int) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				calls = append(calls, "low:"+string(topic))
+				if depth > 5 {
+					t.Errorf("Expected the low watermark at depth 5 or less, got %d", depth)
+				}
+			},
+		}),
+	)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	bus.Subscribe("job:queued", func(event Event) {
+		if n, _ := Payload[int](event); n == 0 {
+			close(started)
+			<-release
+		}
+	})
+	bus.Publish(Of("job:queued", 0))
+	<-started
+	for i := 1; i <= 9; i++ {
+		bus.Publish(Of("job:queued", i))
+	}
+	close(release)
+	bus.Close()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(calls) != 2 || calls[0] != "high:*" || calls[1] != "low:*" {
+		t.Errorf("Expected one high and one low call, got %v", calls)
+	}
+	if highDepth != 8 {
+		t.Errorf("Expected the high watermark at depth 8, got %d", highDepth)
+	}
+}
+
+// TestQueueWatermarksDefaults verifies the default watermark ratios
+func TestQueueWatermarksDefaults(t *testing.T) {
+	bus := New(WithQueueWatermarks(Watermarks{High: 2, Low: 0.9}))
+	defer bus.Close()
+
+	watermarks := bus.(*eventBusImpl).dispatch.watermarks
+	if watermarks.High != 0.8 || watermarks.Low != 0.4 {
+		t.Errorf("Expected ratios 0.8 and 0.4, got %v and %v", watermarks.High, watermarks.Low)
+	}
+}
@@ -0,0 +1,141 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ErrWiring is matched by the errors returned by Validate.
+var ErrWiring = errors.New("eventbus: broken wiring")
+
+// DeclarePublisher records that component publishes topics, so Validate
+// can report declared topics nobody subscribes to, and Graph and Describe
+// can name their producers. Declaring a topic again adds the component to
+// its publishers.
+func (bus *eventBusImpl) DeclarePublisher(component string, topics ...EventType) {
+	bus.subscribersMutex.Lock()
+	defer bus.subscribersMutex.Unlock()
+
+	if bus.publishers == nil {
+		bus.publishers = make(map[EventType][]string)
+	}
+	for _, topic := range topics {
+		if !slices.Contains(bus.publishers[topic], component) {
+			bus.publishers[topic] = append(bus.publishers[topic], component)
+		}
+	}
+}
+
+// WiringIssue names a topic whose publishers or subscribers are missing.
+type WiringIssue struct {
+	EventType EventType
+	// Components are the publishers declaring the topic, or the handler
+	// labels of the subscriptions receiving it, "" for unnamed ones.
+	Components []string
+}
+
+// WiringError describes the topics failing Validate. It matches
+// ErrWiring.
+type WiringError struct {
+	// Unconsumed lists the topics declared with DeclarePublisher that no
+	// subscription receives, with their publishers.
+	Unconsumed []WiringIssue
+	// Unproduced lists the subscribed topics no publisher declared, with
+	// their handlers.
+	Unproduced []WiringIssue
+}
+
+// Error lists the issues, one per line.
+func (e *WiringError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrWiring.Error())
+	for _, issue := range e.Unconsumed {
+		fmt.Fprintf(&b, "\n  %s: published by %s, but nobody subscribes", issue.EventType, strings.Join(issue.Components, ", "))
+	}
+	for _, issue := range e.Unproduced {
+		fmt.Fprintf(&b, "\n  %s: handled by %s, but nobody declared publishing it", issue.EventType, handlerList(issue.Components))
+	}
+	return b.String()
+}
+
+// Is reports whether target is ErrWiring.
+func (e *WiringError) Is(target error) bool {
+	return target == ErrWiring
+}
+
+// handlerList joins handler labels, counting the unnamed ones.
+func handlerList(handlers []string) string {
+	var named []string
+	unnamed := 0
+	for _, handler := range handlers {
+		if handler == "" {
+			unnamed++
+			continue
+		}
+		named = append(named, handler)
+	}
+	switch unnamed {
+	case 0:
+	case 1:
+		named = append(named, "an unnamed handler")
+	default:
+		named = append(named, fmt.Sprintf("%d unnamed handlers", unnamed))
+	}
+	return strings.Join(named, ", ")
+}
+
+// Validate checks the wiring of the bus once the application has
+// subscribed its listeners and declared its publishers with
+// DeclarePublisher, for integration tests and startup checks. It returns
+// a WiringError listing the declared topics no subscription receives and,
+// once any publisher is declared, the subscribed topics no publisher
+// declared. The bus's own "eventbus:*" topics count as published.
+// Subscriptions to patterns of a WildcardRouter are published by the
+// declared topics they match.
+//
+// Example:
+//
+//	bus.DeclarePublisher("combat", "player:damaged", "player:died")
+//	bus.DeclarePublisher("input", "player:moved")
+//	if err := bus.Validate(); err != nil {
+//	    t.Fatal(err)
+//	}
+func (bus *eventBusImpl) Validate() error {
+	bus.subscribersMutex.RLock()
+	defer bus.subscribersMutex.RUnlock()
+
+	report := &WiringError{}
+	produced := make(map[*Subscription]bool)
+	for _, topic := range slices.Sorted(maps.Keys(bus.publishers)) {
+		listeners := bus.matchListeners(topic)
+		if len(listeners) == 0 {
+			report.Unconsumed = append(report.Unconsumed, WiringIssue{
+				EventType:  topic,
+				Components: slices.Clone(bus.publishers[topic]),
+			})
+		}
+		for _, sub := range listeners {
+			produced[sub] = true
+		}
+	}
+
+	if len(bus.publishers) > 0 {
+		unproduced := make(map[EventType][]string)
+		for _, sub := range bus.subscriptions {
+			if !produced[sub] && !matchPattern("eventbus:*", sub.eventType) {
+				unproduced[sub.eventType] = append(unproduced[sub.eventType], sub.name)
+			}
+		}
+		for _, topic := range slices.Sorted(maps.Keys(unproduced)) {
+			report.Unproduced = append(report.Unproduced, WiringIssue{EventType: topic, Components: unproduced[topic]})
+		}
+	}
+
+	if len(report.Unconsumed) == 0 && len(report.Unproduced) == 0 {
+		return nil
+	}
+	return report
+}
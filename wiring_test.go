@@ -0,0 +1,65 @@
+package eventbus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestValidate verifies that declared topics without subscribers and subscribed topics without publishers are reported
+func TestValidate(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	bus.DeclarePublisher("combat", "player:damaged", "player:died")
+	bus.DeclarePublisher("respawn", "player:spawned")
+	bus.DeclarePublisher("combat", "player:died")
+	bus.Subscribe("player:damaged", func(event Event) {}, WithName("hud"))
+	bus.Subscribe("player:spawned", func(event Event) {}, WithName("hud"))
+	bus.Subscribe("player:moved", func(event Event) {}, WithName("physics"))
+	bus.Subscribe("player:moved", func(event Event) {})
+	bus.Subscribe(HeartbeatType, func(event Event) {})
+
+	err := bus.Validate()
+	var wiring *WiringError
+	if !errors.Is(err, ErrWiring) || !errors.As(err, &wiring) {
+		t.Fatalf("Expected a WiringError, got %v", err)
+	}
+	if len(wiring.Unconsumed) != 1 || wiring.Unconsumed[0].EventType != "player:died" || strings.Join(wiring.Unconsumed[0].Components, ",") != "combat" {
+		t.Errorf("Expected player:died published by combat to be unconsumed, got %+v", wiring.Unconsumed)
+	}
+	if len(wiring.Unproduced) != 1 || wiring.Unproduced[0].EventType != "player:moved" || len(wiring.Unproduced[0].Components) != 2 {
+		t.Errorf("Expected player:moved with 2 handlers to be unproduced, got %+v", wiring.Unproduced)
+	}
+
+	expected := "eventbus: broken wiring\n" +
+		"  player:died: published by combat, but nobody subscribes\n" +
+		"  player:moved: handled by physics, an unnamed handler, but nobody declared publishing it"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+// TestValidateWithoutPublishers verifies that subscriptions are not reported before any publisher is declared
+func TestValidateWithoutPublishers(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+	bus.Subscribe("player:moved", func(event Event) {})
+
+	if err := bus.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+// TestValidateWildcards verifies that pattern subscriptions are produced by matching declared topics
+func TestValidateWildcards(t *testing.T) {
+	bus := New(WithRouter(NewWildcardRouter()))
+	defer bus.Close()
+
+	bus.DeclarePublisher("combat", "player:died")
+	bus.Subscribe("player:*", func(event Event) {}, WithName("logger"))
+
+	if err := bus.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}